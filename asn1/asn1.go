@@ -0,0 +1,84 @@
+// Package asn1 defines and registers Marshaler/Unmarshaler handling ASN.1 DER
+// content, by encoding/asn1, for custom structures of security protocols.
+//
+// Values are mapped to ASN.1 by encoding/asn1: struct fields are encoded in
+// order as a SEQUENCE, with their types adjusted by "asn1" tags, such as
+// "optional", "explicit,tag:0" or "utf8". Parameters applying to the top-level
+// value are set by the EncoderParams and DecoderParams options.
+//
+// Errors of malformed data and mismatched types are of SyntaxError and
+// StructuralError, and data left after the value is reported by
+// TrailingDataError, unless allowed by the AllowTrailingData option.
+package asn1
+
+import (
+	"context"
+	"encoding/asn1"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "asn1"
+
+// StructuralError is an error of data which is valid ASN.1 but does not match
+// the Go value, or of a Go value which can not be encoded.
+type StructuralError = asn1.StructuralError
+
+// SyntaxError is an error of malformed ASN.1 data.
+type SyntaxError = asn1.SyntaxError
+
+// TrailingDataError is an error of data left after the value.
+type TrailingDataError struct {
+	// Len is the length in bytes of the data left.
+	Len int
+}
+
+func (e *TrailingDataError) Error() string {
+	return fmt.Sprintf("asn1: %d bytes of trailing data", e.Len)
+}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	return asn1.MarshalWithParams(v, config.Params)
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("asn1: can not unmarshal to non-pointer or nil %T", v)
+	}
+	rest, err := asn1.UnmarshalWithParams(data, v, config.Params)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 && !config.AllowTrailingData {
+		return &TrailingDataError{Len: len(rest)}
+	}
+	return nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,121 @@
+package asn1
+
+import (
+	"context"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestRegister(t *testing.T) {
+	if encoding.GetMarshaler(Name) == nil || encoding.GetUnmarshaler(Name) == nil {
+		t.Errorf("codec is not registered as %q", Name)
+	}
+}
+
+type attribute struct {
+	Type  asn1.ObjectIdentifier
+	Value string `asn1:"utf8"`
+}
+
+type token struct {
+	Version    int `asn1:"optional,explicit,default:1,tag:0"`
+	Serial     *big.Int
+	Issued     time.Time   `asn1:"generalized"`
+	Attributes []attribute `asn1:"set"`
+	Signature  asn1.BitString
+}
+
+func TestCodec(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := token{
+		Version:    2,
+		Serial:     big.NewInt(1 << 40),
+		Issued:     time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC),
+		Attributes: []attribute{{Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Value: "例子"}},
+		Signature:  asn1.BitString{Bytes: []byte{0xf0}, BitLength: 4},
+	}
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out token
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestCodec_Vectors(t *testing.T) {
+	tests := []struct {
+		v   interface{}
+		hex string
+	}{
+		{true, "0101ff"},
+		{127, "02017f"},
+		{-129, "0202ff7f"},
+		{"hi", "13026869"},
+		{[]byte{1, 2}, "04020102"},
+		{asn1.ObjectIdentifier{1, 2, 840, 113549}, "06062a864886f70d"},
+		{struct{ A, B int }{1, 2}, "3006 020101 020102"},
+	}
+	c := &codec{}
+	for _, test := range tests {
+		got, err := c.Marshal(context.Background(), test.v)
+		if err != nil {
+			t.Errorf("%v: %v", test.v, err)
+			continue
+		}
+		if want := mustHex(test.hex); string(got) != string(want) {
+			t.Errorf("%v: got % x, want % x", test.v, got, want)
+		}
+		out := reflect.New(reflect.TypeOf(test.v))
+		if err := c.Unmarshal(context.Background(), got, out.Interface()); err != nil {
+			t.Errorf("%v: %v", test.v, err)
+		} else if !reflect.DeepEqual(out.Elem().Interface(), test.v) {
+			t.Errorf("got %v, want %v", out.Elem(), test.v)
+		}
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	c := &codec{}
+	ctx := context.Background()
+	var n int
+	if err := c.Unmarshal(ctx, mustHex("020101"), n); err == nil {
+		t.Error("expect error of non-pointer")
+	}
+	var syntaxErr SyntaxError
+	if err := c.Unmarshal(ctx, mustHex("0205 01"), &n); !errors.As(err, &syntaxErr) {
+		t.Errorf("got error %v, want SyntaxError", err)
+	}
+	var s string
+	var structuralErr StructuralError
+	if err := c.Unmarshal(ctx, mustHex("020101"), &s); !errors.As(err, &structuralErr) {
+		t.Errorf("got error %v, want StructuralError", err)
+	}
+	var trailingErr *TrailingDataError
+	if err := c.Unmarshal(ctx, mustHex("020101 0000"), &n); !errors.As(err, &trailingErr) || trailingErr.Len != 2 {
+		t.Errorf("got error %v, want TrailingDataError", err)
+	}
+	if _, err := c.Marshal(ctx, map[string]int{}); !errors.As(err, &structuralErr) {
+		t.Errorf("got error %v, want StructuralError", err)
+	}
+}
@@ -0,0 +1,31 @@
+package asn1
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, EncoderParams("explicit,tag:1"))
+	got, err := m.Marshal(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustHex("a103 020101"); string(got) != string(want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	var n int
+	u := WithDecoderOption(&codec{}, DecoderParams("explicit,tag:1"), AllowTrailingData())
+	if err := u.Unmarshal(context.Background(), mustHex("a103 020107 0000"), &n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 {
+		t.Errorf("got %d, want 7", n)
+	}
+	if err := (&codec{}).Unmarshal(context.Background(), mustHex("a103 020107"), &n); err == nil {
+		t.Error("expect error of unexpected tag")
+	}
+}
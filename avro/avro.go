@@ -0,0 +1,155 @@
+// Package avro defines and registers Marshaler/Unmarshaler handling Apache
+// Avro (https://avro.apache.org) binary encoded content, as ingested by data
+// lakes.
+//
+// Avro data carries no type information, so a parsed schema is required as
+// configuration, set by the EncoderSchema and DecoderSchema options. Data may
+// be decoded with a different reader schema, set by the ReaderSchema option,
+// which resolves it according to the schema resolution rules of the spec:
+// fields are matched by names and aliases, fields unknown to the reader are
+// skipped, missing fields take their defaults and numbers are promoted.
+//
+// Records are mapped from and to structs, with field names taken from the
+// "avro" tag or, in absence of it, the "json" tag, and maps with string keys.
+// Enums are strings of their symbols or integers of their indexes, fixed are
+// byte arrays or slices, and unions choose the first branch matching the Go
+// value best, null for nil values. Logical types map to time.Time for date
+// and timestamps, time.Duration for times of day and big.Rat for decimal.
+// Into interface{} values, records and maps are decoded as
+// map[string]interface{}, arrays as []interface{} and numbers as int32,
+// int64, float32 or float64.
+//
+// With the EncodeContainer and DecodeContainer options, a slice of values is
+// encoded as, and decoded from, an object container file, which carries its
+// schema and may compress its blocks with deflate. Writer and Reader stream
+// object container files.
+package avro
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "avro"
+
+var _tags = []string{"avro", "json"}
+
+var errSchemaRequired = errors.New("avro: schema is required")
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	if config.Schema == nil {
+		return nil, errSchemaRequired
+	}
+	if config.Container {
+		return marshalContainer(config, v)
+	}
+	e := &encoder{}
+	if err := e.value(config.Schema, reflect.ValueOf(v)); err != nil {
+		return nil, fmt.Errorf("avro: %w", err)
+	}
+	return e.buf, nil
+}
+
+// marshalContainer encodes the values of a slice or an array as an object
+// container file.
+func marshalContainer(config *EncoderConfig, v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("avro: can not marshal %T as an object container file", v)
+	}
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, config.Schema, config.Compression, config.BlockLength)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := w.Write(rv.Index(i).Interface()); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("avro: can not unmarshal to non-pointer or nil %T", v)
+	}
+	if config.Container {
+		return unmarshalContainer(config, data, rv.Elem())
+	}
+	if config.Schema == nil {
+		return errSchemaRequired
+	}
+	reader := config.ReaderSchema
+	if reader == nil {
+		reader = config.Schema
+	}
+	d := &decoder{data: data}
+	if err := d.decode(config.Schema, reader, v); err != nil {
+		return fmt.Errorf("avro: %w", err)
+	}
+	return nil
+}
+
+// unmarshalContainer decodes the values of an object container file into the
+// slice v.
+func unmarshalContainer(config *DecoderConfig, data []byte, v reflect.Value) error {
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("avro: can not unmarshal an object container file to %s", v.Type())
+	}
+	r, err := NewReader(bytes.NewReader(data), config.ReaderSchema)
+	if err != nil {
+		return err
+	}
+	values := reflect.MakeSlice(v.Type(), 0, 0)
+	for {
+		item := reflect.New(v.Type().Elem())
+		if err := r.Read(item.Interface()); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		values = reflect.Append(values, item.Elem())
+	}
+	v.Set(values)
+	return nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,130 @@
+package avro
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	if encoding.GetMarshaler(Name) == nil || encoding.GetUnmarshaler(Name) == nil {
+		t.Errorf("codec is not registered as %q", Name)
+	}
+}
+
+const _orderSchema = `{
+	"type": "record",
+	"name": "Order",
+	"namespace": "shop",
+	"fields": [
+		{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID", "SHIPPED"]}},
+		{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+		{"name": "created", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+		{"name": "items", "type": {"type": "array", "items": {"type": "record", "name": "Item", "fields": [
+			{"name": "sku", "type": "string"},
+			{"name": "count", "type": "int"}]}}},
+		{"name": "attrs", "type": {"type": "map", "values": "string"}},
+		{"name": "note", "type": ["null", "string"], "default": null}
+	]
+}`
+
+type item struct {
+	SKU   string `avro:"sku"`
+	Count int    `json:"count"`
+}
+
+type order struct {
+	ID      string            `avro:"id"`
+	Status  string            `avro:"status"`
+	Amount  *big.Rat          `avro:"amount"`
+	Created time.Time         `avro:"created"`
+	Items   []item            `avro:"items"`
+	Attrs   map[string]string `avro:"attrs"`
+	Note    *string           `avro:"note"`
+}
+
+func TestCodec_Struct(t *testing.T) {
+	s := MustParseSchema(_orderSchema)
+	m := WithEncoderOption(encoding.GetMarshaler(Name), EncoderSchema(s))
+	u := WithDecoderOption(encoding.GetUnmarshaler(Name), DecoderSchema(s))
+	note := "请尽快发货"
+	in := order{
+		ID:      "1b4e28ba-2fa1-11d2-883f-0016d3cca427",
+		Status:  "PAID",
+		Amount:  big.NewRat(102450, 100),
+		Created: time.Date(2021, 6, 1, 8, 30, 0, 123456e3, time.UTC),
+		Items:   []item{{SKU: "a-1", Count: 2}},
+		Attrs:   map[string]string{"channel": "web"},
+		Note:    &note,
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out order
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Amount.Cmp(in.Amount) != 0 {
+		t.Errorf("got amount %v, want %v", out.Amount, in.Amount)
+	}
+	out.Amount = in.Amount
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestCodec_Evolution(t *testing.T) {
+	writer := MustParseSchema(_orderSchema)
+	// The reader renamed the note, dropped the items and attributes, added a
+	// field with a default, and knows fewer statuses with a fallback.
+	reader := MustParseSchema(`{
+		"type": "record",
+		"name": "Order",
+		"fields": [
+			{"name": "id", "type": "string"},
+			{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID", "UNKNOWN"], "default": "UNKNOWN"}},
+			{"name": "remark", "aliases": ["note"], "type": ["null", "string"], "default": null},
+			{"name": "priority", "type": "int", "default": 3}
+		]
+	}`)
+	m := WithEncoderOption(&codec{}, EncoderSchema(writer))
+	note := "fragile"
+	data, err := m.Marshal(context.Background(), order{
+		ID: "x", Status: "SHIPPED", Amount: new(big.Rat), Created: time.Unix(0, 0), Note: &note,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		ID       string  `json:"id"`
+		Status   string  `json:"status"`
+		Remark   *string `json:"remark"`
+		Priority int     `json:"priority"`
+	}
+	u := WithDecoderOption(&codec{}, DecoderSchema(writer), ReaderSchema(reader))
+	if err := u.Unmarshal(context.Background(), data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "x" || got.Status != "UNKNOWN" || got.Remark == nil || *got.Remark != note || got.Priority != 3 {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	c := &codec{}
+	ctx := context.Background()
+	if err := c.Unmarshal(ctx, nil, order{}); err == nil || !strings.HasPrefix(err.Error(), "avro: ") {
+		t.Errorf("got error %v", err)
+	}
+	m := WithEncoderOption(c, EncoderSchema(MustParseSchema(_orderSchema)))
+	if _, err := m.Marshal(ctx, order{Status: "LOST"}); err == nil || !strings.HasPrefix(err.Error(), "avro: field status: ") {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+)
+
+// Compression codecs of object container files.
+const (
+	CompressionNull    = "null"
+	CompressionDeflate = "deflate"
+)
+
+// DefaultBlockLength is the default count of values of a block of an object
+// container file.
+const DefaultBlockLength = 100
+
+// Metadata keys of object container files.
+const (
+	metaSchema = "avro.schema"
+	metaCodec  = "avro.codec"
+)
+
+var _magic = []byte("Obj\x01")
+
+// Writer writes an object container file: a header carrying the schema,
+// followed by blocks of values, optionally compressed.
+type Writer struct {
+	w           io.Writer
+	schema      *Schema
+	compression string
+	blockLength int
+	sync        [16]byte
+	block       encoder
+	count       int
+	err         error
+}
+
+// NewWriter writes the header of an object container file of the schema to
+// w, and returns a Writer of values of the schema. The compression is
+// CompressionNull or CompressionDeflate, and blockLength is the count of
+// values per block, DefaultBlockLength if not positive.
+func NewWriter(w io.Writer, schema *Schema, compression string, blockLength int) (*Writer, error) {
+	if schema == nil {
+		return nil, errSchemaRequired
+	}
+	if compression == "" {
+		compression = CompressionNull
+	}
+	if compression != CompressionNull && compression != CompressionDeflate {
+		return nil, fmt.Errorf("avro: unsupported compression %q", compression)
+	}
+	if blockLength <= 0 {
+		blockLength = DefaultBlockLength
+	}
+	cw := &Writer{w: w, schema: schema, compression: compression, blockLength: blockLength}
+	if _, err := io.ReadFull(rand.Reader, cw.sync[:]); err != nil {
+		return nil, fmt.Errorf("avro: can not generate sync marker: %w", err)
+	}
+	header := &encoder{}
+	header.buf = append(header.buf, _magic...)
+	meta := map[string][]byte{
+		metaSchema: []byte(schema.String()),
+		metaCodec:  []byte(compression),
+	}
+	if err := header.value(_metaSchema, reflect.ValueOf(meta)); err != nil {
+		return nil, fmt.Errorf("avro: %w", err)
+	}
+	header.buf = append(header.buf, cw.sync[:]...)
+	if _, err := w.Write(header.buf); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// _metaSchema is the schema of the metadata of object container files.
+var _metaSchema = &Schema{Type: Map, Values: &Schema{Type: Bytes}}
+
+// Write appends the value v to the current block, which is written when it
+// holds the block length of values.
+func (w *Writer) Write(v interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.block.value(w.schema, reflect.ValueOf(v)); err != nil {
+		return fmt.Errorf("avro: %w", err)
+	}
+	if w.count++; w.count >= w.blockLength {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the current block if it holds any values.
+func (w *Writer) Flush() error {
+	if w.err != nil || w.count == 0 {
+		return w.err
+	}
+	data := w.block.buf
+	if w.compression == CompressionDeflate {
+		buf := &bytes.Buffer{}
+		fw, _ := flate.NewWriter(buf, flate.DefaultCompression)
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+		if err := fw.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	e := &encoder{}
+	e.long(int64(w.count))
+	e.bytes(data)
+	e.buf = append(e.buf, w.sync[:]...)
+	if _, err := w.w.Write(e.buf); err != nil {
+		w.err = err
+		return err
+	}
+	w.block.buf, w.count = w.block.buf[:0], 0
+	return nil
+}
+
+// Close flushes the current block. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.err == nil {
+		w.err = errors.New("avro: write to closed writer")
+	}
+	return err
+}
+
+// Reader reads values from an object container file.
+type Reader struct {
+	r           *bufio.Reader
+	writer      *Schema
+	reader      *Schema
+	compression string
+	sync        [16]byte
+	block       decoder
+	count       int64
+}
+
+// NewReader reads the header of an object container file from r, and returns
+// a Reader of its values. Values are resolved from the schema of the file into
+// the reader schema, or read as of the schema of the file if it is nil.
+func NewReader(r io.Reader, reader *Schema) (*Reader, error) {
+	cr := &Reader{r: bufio.NewReader(r)}
+	magic := make([]byte, len(_magic))
+	if _, err := io.ReadFull(cr.r, magic); err != nil {
+		return nil, fmt.Errorf("avro: can not read header: %w", err)
+	}
+	if !bytes.Equal(magic, _magic) {
+		return nil, errors.New("avro: not an object container file")
+	}
+	meta, err := cr.meta()
+	if err != nil {
+		return nil, fmt.Errorf("avro: can not read header: %w", err)
+	}
+	if cr.writer, err = ParseSchema(string(meta[metaSchema])); err != nil {
+		return nil, err
+	}
+	cr.reader = reader
+	if cr.reader == nil {
+		cr.reader = cr.writer
+	}
+	cr.compression = CompressionNull
+	if codec, ok := meta[metaCodec]; ok && len(codec) > 0 {
+		cr.compression = string(codec)
+	}
+	if cr.compression != CompressionNull && cr.compression != CompressionDeflate {
+		return nil, fmt.Errorf("avro: unsupported compression %q", cr.compression)
+	}
+	if _, err := io.ReadFull(cr.r, cr.sync[:]); err != nil {
+		return nil, fmt.Errorf("avro: can not read header: %w", err)
+	}
+	return cr, nil
+}
+
+// meta reads the metadata map of the header.
+func (r *Reader) meta() (map[string][]byte, error) {
+	meta := map[string][]byte{}
+	for {
+		n, err := binary.ReadVarint(r.r)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return meta, nil
+		}
+		if n < 0 {
+			if _, err := binary.ReadVarint(r.r); err != nil {
+				return nil, err
+			}
+			n = -n
+		}
+		for ; n > 0; n-- {
+			key, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			value, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			meta[string(key)] = value
+		}
+	}
+}
+
+func (r *Reader) bytes() ([]byte, error) {
+	n, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, errors.New("negative length")
+	}
+	// Read through a limited reader, so that a corrupt length does not
+	// allocate more than the data.
+	b, err := ioutil.ReadAll(io.LimitReader(r.r, n))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) != n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b, nil
+}
+
+// Schema returns the schema of the file.
+func (r *Reader) Schema() *Schema {
+	return r.writer
+}
+
+// Read reads the next value into v, which must be a non-nil pointer. It
+// returns io.EOF if there are no more values.
+func (r *Reader) Read(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("avro: can not unmarshal to non-pointer or nil %T", v)
+	}
+	for r.count == 0 {
+		if err := r.next(); err != nil {
+			return err
+		}
+	}
+	if err := r.block.value(r.writer, r.reader, rv.Elem()); err != nil {
+		return fmt.Errorf("avro: %w", err)
+	}
+	r.count--
+	return nil
+}
+
+// next reads the next block.
+func (r *Reader) next() error {
+	if r.block.pos != len(r.block.data) {
+		return errors.New("avro: unexpected data after values of block")
+	}
+	count, err := binary.ReadVarint(r.r)
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("avro: can not read block: %w", err)
+	}
+	if count < 0 {
+		return errors.New("avro: negative count of block")
+	}
+	data, err := r.bytes()
+	if err != nil {
+		return fmt.Errorf("avro: can not read block: %w", noEOF(err))
+	}
+	var sync [16]byte
+	if _, err := io.ReadFull(r.r, sync[:]); err != nil {
+		return fmt.Errorf("avro: can not read block: %w", noEOF(err))
+	}
+	if sync != r.sync {
+		return errors.New("avro: invalid sync marker")
+	}
+	if r.compression == CompressionDeflate {
+		if data, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(data))); err != nil {
+			return fmt.Errorf("avro: can not decompress block: %w", err)
+		}
+	}
+	r.block = decoder{data: data}
+	r.count = count
+	return nil
+}
+
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
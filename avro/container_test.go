@@ -0,0 +1,126 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+const _pointSchema = `{"type": "record", "name": "point", "fields": [
+	{"name": "x", "type": "int"}, {"name": "y", "type": "int"}]}`
+
+func TestWriter_Reader(t *testing.T) {
+	s := MustParseSchema(_pointSchema)
+	in := make([]point, 250)
+	for i := range in {
+		in[i] = point{X: int32(i), Y: int32(-i)}
+	}
+	for _, compression := range []string{"", CompressionNull, CompressionDeflate} {
+		buf := &bytes.Buffer{}
+		w, err := NewWriter(buf, s, compression, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range in {
+			if err := w.Write(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(point{}); err == nil {
+			t.Errorf("%q: expect error of write after close", compression)
+		}
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Schema().String() != s.String() {
+			t.Errorf("%q: got schema %s", compression, r.Schema())
+		}
+		var out []point
+		for {
+			var p point
+			if err := r.Read(&p); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%q: %v", compression, err)
+			}
+			out = append(out, p)
+		}
+		if !reflect.DeepEqual(out, in) {
+			t.Errorf("%q: got %d points, want %d", compression, len(out), len(in))
+		}
+	}
+}
+
+func TestReader_ReaderSchema(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, MustParseSchema(_pointSchema), CompressionDeflate, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(point{X: 1, Y: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader := MustParseSchema(`{"type": "record", "name": "point", "fields": [
+		{"name": "y", "type": "double"}, {"name": "z", "type": "string", "default": "-"}]}`)
+	r, err := NewReader(buf, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]interface{}
+	if err := r.Read(&v); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"y": float64(2), "z": "-"}; !reflect.DeepEqual(v, want) {
+		t.Errorf("got %v, want %v", v, want)
+	}
+	if err := r.Read(&v); err != io.EOF {
+		t.Errorf("got error %v, want io.EOF", err)
+	}
+}
+
+func TestReader_Invalid(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, MustParseSchema(`"int"`), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not a container", []byte("Obj\x02")},
+		{"truncated header", data[:6]},
+		{"truncated block", data[:len(data)-4]},
+		{"invalid sync marker", corrupt},
+	}
+	for _, test := range tests {
+		r, err := NewReader(bytes.NewReader(test.data), nil)
+		if err == nil {
+			var v int
+			err = r.Read(&v)
+		}
+		if err == nil || err == io.EOF {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, MustParseSchema(`"int"`), "snappy", 0); err == nil {
+		t.Error("expect error of unsupported compression")
+	}
+}
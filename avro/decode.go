@@ -0,0 +1,816 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+var errUnexpectedEnd = errors.New("unexpected end of data")
+
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// decode decodes a value of the writer schema w into v, which must be a
+// non-nil pointer, resolving it into the reader schema r.
+func (d *decoder) decode(w, r *Schema, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("can not unmarshal to non-pointer or nil %T", v)
+	}
+	if err := d.value(w, r, rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("unexpected data after value at offset %d", d.pos)
+	}
+	return nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errUnexpectedEnd
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) long() (int64, error) {
+	n, size := binary.Varint(d.data[d.pos:])
+	if size == 0 {
+		return 0, errUnexpectedEnd
+	}
+	if size < 0 {
+		return 0, fmt.Errorf("varint overflows long at offset %d", d.pos)
+	}
+	d.pos += size
+	return n, nil
+}
+
+func (d *decoder) int() (int64, error) {
+	pos := d.pos
+	n, err := d.long()
+	if err == nil && (n < math.MinInt32 || n > math.MaxInt32) {
+		return 0, fmt.Errorf("varint overflows int at offset %d", pos)
+	}
+	return n, err
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	pos := d.pos
+	n, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("negative length at offset %d", pos)
+	}
+	if n > int64(len(d.data)-d.pos) {
+		return nil, errUnexpectedEnd
+	}
+	return d.read(int(n))
+}
+
+func (d *decoder) string() (string, error) {
+	pos := d.pos
+	b, err := d.bytes()
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(b) {
+		return "", fmt.Errorf("invalid UTF-8 string at offset %d", pos)
+	}
+	return string(b), nil
+}
+
+// index reads the index of a union branch or an enum symbol, which must be
+// less than n.
+func (d *decoder) index(n int) (int, error) {
+	pos := d.pos
+	i, err := d.long()
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 || i >= int64(n) {
+		return 0, fmt.Errorf("index %d out of range at offset %d", i, pos)
+	}
+	return int(i), nil
+}
+
+// blockCount reads the count of items of a block of an array or a map. A
+// negative count is followed by the size of the block in bytes, which is
+// not used.
+func (d *decoder) blockCount() (int64, error) {
+	pos := d.pos
+	n, err := d.long()
+	if err != nil || n >= 0 {
+		return n, err
+	}
+	if n == math.MinInt64 {
+		return 0, fmt.Errorf("invalid block count at offset %d", pos)
+	}
+	if _, err = d.long(); err != nil {
+		return 0, err
+	}
+	return -n, nil
+}
+
+// resolvable reports whether data of the writer schema w can be read by the
+// reader schema r. Named schemas match by names, their contents are checked
+// when reading.
+func resolvable(w, r *Schema) bool {
+	if w.Type == Union {
+		for _, b := range w.Branches {
+			if resolvable(b, r) {
+				return true
+			}
+		}
+		return false
+	}
+	if r.Type == Union {
+		return readerBranch(w, r) >= 0
+	}
+	switch w.Type {
+	case Int:
+		if r.Type == Long || r.Type == Float || r.Type == Double {
+			return true
+		}
+	case Long:
+		if r.Type == Float || r.Type == Double {
+			return true
+		}
+	case Float:
+		if r.Type == Double {
+			return true
+		}
+	case String:
+		if r.Type == Bytes {
+			return true
+		}
+	case Bytes:
+		if r.Type == String {
+			return true
+		}
+	}
+	if w.Type != r.Type {
+		return false
+	}
+	switch w.Type {
+	case Record, Enum:
+		return sameName(w, r)
+	case Fixed:
+		return sameName(w, r) && w.Size == r.Size
+	case Array:
+		return resolvable(w.Items, r.Items)
+	case Map:
+		return resolvable(w.Values, r.Values)
+	}
+	return true
+}
+
+// sameName reports whether the named schemas w and r have the same
+// unqualified name, or the full name of w is an alias of r.
+func sameName(w, r *Schema) bool {
+	if shortName(w.Name) == shortName(r.Name) {
+		return true
+	}
+	for _, alias := range r.Aliases {
+		if alias == w.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// readerBranch returns the index of the branch of the reader union r which
+// data of the writer schema w is read by: the first branch of the same type,
+// or else the first resolvable branch, or -1.
+func readerBranch(w, r *Schema) int {
+	for i, b := range r.Branches {
+		if b.Type == w.Type && (b.Name == "" || sameName(w, b)) {
+			return i
+		}
+	}
+	for i, b := range r.Branches {
+		if resolvable(w, b) {
+			return i
+		}
+	}
+	return -1
+}
+
+// value reads a value of the writer schema w into v, resolving it into the
+// reader schema r.
+func (d *decoder) value(w, r *Schema, v reflect.Value) error {
+	if w.Type == Union {
+		i, err := d.index(len(w.Branches))
+		if err != nil {
+			return err
+		}
+		w = w.Branches[i]
+	}
+	if r.Type == Union {
+		i := readerBranch(w, r)
+		if i < 0 {
+			return fmt.Errorf("can not resolve %s with union", describe(w))
+		}
+		r = r.Branches[i]
+	}
+	if v.Kind() == reflect.Interface {
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("can not unmarshal %s into non-empty interface %s", describe(r), v.Type())
+		}
+		if w.Type == Null {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		nv := reflect.New(genericType(r)).Elem()
+		if err := d.value(w, r, nv); err != nil {
+			return err
+		}
+		v.Set(nv)
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if w.Type == Null {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.value(w, r, v.Elem())
+	}
+	if !resolvable(w, r) {
+		return fmt.Errorf("can not resolve %s with %s", describe(w), describe(r))
+	}
+	switch r.Type {
+	case Null:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case Enum:
+		return d.enum(w, r, v)
+	case Fixed:
+		pos := d.pos
+		b, err := d.read(w.Size)
+		if err != nil {
+			return err
+		}
+		return d.setBytes(r, b, v, pos)
+	case Array:
+		return d.array(w, r, v)
+	case Map:
+		return d.mapValue(w, r, v)
+	case Record:
+		return d.record(w, r, v)
+	}
+	pos := d.pos
+	x, err := d.scalar(w, r)
+	if err != nil {
+		return err
+	}
+	if b, ok := x.([]byte); ok {
+		return d.setBytes(r, b, v, pos)
+	}
+	return d.setScalar(r, x, v, pos)
+}
+
+// describe returns the type of a schema, with its name if it is named.
+func describe(s *Schema) string {
+	if s.Name != "" {
+		return string(s.Type) + " " + s.Name
+	}
+	return string(s.Type)
+}
+
+// genericType returns the type of values which a value of the schema s is
+// decoded as into interface{} values.
+func genericType(s *Schema) reflect.Type {
+	switch s.LogicalType {
+	case LogicalDate, LogicalTimestampMillis, LogicalTimestampMicros,
+		LogicalLocalTimestampMillis, LogicalLocalTimestampMicros:
+		return _timeType
+	case LogicalTimeMillis, LogicalTimeMicros:
+		return _durationType
+	case LogicalDecimal:
+		return reflect.PtrTo(_ratType)
+	}
+	switch s.Type {
+	case Boolean:
+		return reflect.TypeOf(false)
+	case Int:
+		return reflect.TypeOf(int32(0))
+	case Long:
+		return reflect.TypeOf(int64(0))
+	case Float:
+		return reflect.TypeOf(float32(0))
+	case Double:
+		return reflect.TypeOf(float64(0))
+	case Bytes, Fixed:
+		return reflect.TypeOf([]byte(nil))
+	case String, Enum:
+		return reflect.TypeOf("")
+	case Array:
+		return reflect.TypeOf([]interface{}(nil))
+	}
+	return reflect.TypeOf(map[string]interface{}(nil))
+}
+
+// scalar reads a primitive value of the writer schema w, promoted to the
+// reader schema r: a bool, an int64, a float64, a []byte or a string.
+func (d *decoder) scalar(w, r *Schema) (interface{}, error) {
+	pos := d.pos
+	switch w.Type {
+	case Boolean:
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] > 1 {
+			return nil, fmt.Errorf("invalid boolean at offset %d", d.pos-1)
+		}
+		return b[0] == 1, nil
+	case Int, Long:
+		var n int64
+		var err error
+		if w.Type == Int {
+			n, err = d.int()
+		} else {
+			n, err = d.long()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.Type == Float || r.Type == Double {
+			if r.Type == Float {
+				return float64(float32(n)), nil
+			}
+			return float64(n), nil
+		}
+		return n, nil
+	case Float:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), nil
+	case Double:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	}
+	b, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	if r.Type != String {
+		return b, nil
+	}
+	if !utf8.Valid(b) {
+		return nil, fmt.Errorf("invalid UTF-8 string at offset %d", pos)
+	}
+	return string(b), nil
+}
+
+func (d *decoder) typeError(r *Schema, t reflect.Type, pos int) error {
+	return fmt.Errorf("can not unmarshal %s into Go value of type %s at offset %d", describe(r), t, pos)
+}
+
+// setScalar sets v to the value x of the reader schema r, read at the offset
+// pos.
+func (d *decoder) setScalar(r *Schema, x interface{}, v reflect.Value, pos int) error {
+	switch v.Type() {
+	case _timeType:
+		n, ok := x.(int64)
+		if !ok {
+			break
+		}
+		var t time.Time
+		switch r.LogicalType {
+		case LogicalDate:
+			t = time.Unix(n*86400, 0)
+		case LogicalTimestampMillis, LogicalLocalTimestampMillis:
+			t = time.Unix(floorDiv(n, 1e3), floorMod(n, 1e3)*1e6)
+		case LogicalTimestampMicros, LogicalLocalTimestampMicros:
+			t = time.Unix(floorDiv(n, 1e6), floorMod(n, 1e6)*1e3)
+		default:
+			return d.typeError(r, v.Type(), pos)
+		}
+		v.Set(reflect.ValueOf(t.UTC()))
+		return nil
+	case _durationType:
+		n, ok := x.(int64)
+		if !ok {
+			break
+		}
+		switch r.LogicalType {
+		case LogicalTimeMillis:
+			v.SetInt(n * int64(time.Millisecond))
+			return nil
+		case LogicalTimeMicros:
+			v.SetInt(n * int64(time.Microsecond))
+			return nil
+		}
+	}
+	switch x := x.(type) {
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(x)
+			return nil
+		}
+	case int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.OverflowInt(x) {
+				return fmt.Errorf("%s %d overflows %s at offset %d", r.Type, x, v.Type(), pos)
+			}
+			v.SetInt(x)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if x < 0 || v.OverflowUint(uint64(x)) {
+				return fmt.Errorf("%s %d overflows %s at offset %d", r.Type, x, v.Type(), pos)
+			}
+			v.SetUint(uint64(x))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(x))
+			return nil
+		}
+	case float64:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			v.SetFloat(x)
+			return nil
+		}
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(x)
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(x))
+			return nil
+		}
+	}
+	return d.typeError(r, v.Type(), pos)
+}
+
+// setBytes sets v to the bytes b of a bytes or a fixed of the reader schema r,
+// read at the offset pos.
+func (d *decoder) setBytes(r *Schema, b []byte, v reflect.Value, pos int) error {
+	switch {
+	case v.Type() == _ratType && r.LogicalType == LogicalDecimal:
+		v.Set(reflect.ValueOf(*decimalRat(r, b)))
+		return nil
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(append([]byte(nil), b...))
+		return nil
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 && v.Len() == len(b):
+		reflect.Copy(v, reflect.ValueOf(b))
+		return nil
+	case v.Kind() == reflect.String && r.Type == Bytes:
+		v.SetString(string(b))
+		return nil
+	}
+	return d.typeError(r, v.Type(), pos)
+}
+
+// decimalRat returns the decimal whose unscaled value is the big-endian two's
+// complement b.
+func decimalRat(r *Schema, b []byte) *big.Rat {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(r.Scale)), nil)
+	return new(big.Rat).SetFrac(n, scale)
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b < 0 {
+		q--
+	}
+	return q
+}
+
+func floorMod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
+
+func (d *decoder) enum(w, r *Schema, v reflect.Value) error {
+	pos := d.pos
+	i, err := d.index(len(w.Symbols))
+	if err != nil {
+		return err
+	}
+	symbol := w.Symbols[i]
+	index := indexOf(r.Symbols, symbol)
+	if index < 0 {
+		if !r.HasEnumDefault {
+			return fmt.Errorf("unknown symbol %s of enum %s at offset %d", symbol, r.Name, pos)
+		}
+		symbol, index = r.EnumDefault, indexOf(r.Symbols, r.EnumDefault)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(symbol)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !v.OverflowInt(int64(index)) {
+			v.SetInt(int64(index))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !v.OverflowUint(uint64(index)) {
+			v.SetUint(uint64(index))
+			return nil
+		}
+	}
+	return d.typeError(r, v.Type(), pos)
+}
+
+func indexOf(symbols []string, symbol string) int {
+	for i, s := range symbols {
+		if s == symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *decoder) array(w, r *Schema, v reflect.Value) error {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return d.typeError(r, v.Type(), d.pos)
+	}
+	if d.depth++; d.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { d.depth-- }()
+	i := 0
+	if v.Kind() == reflect.Slice {
+		v.Set(v.Slice(0, 0))
+		if v.IsNil() {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		}
+	}
+	for {
+		n, err := d.blockCount()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		for ; n > 0; n-- {
+			var item reflect.Value
+			if v.Kind() == reflect.Slice {
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+				item = v.Index(i)
+			} else if i < v.Len() {
+				item = v.Index(i)
+			} else {
+				return fmt.Errorf("array overflows %s at offset %d", v.Type(), d.pos)
+			}
+			if err := d.value(w.Items, r.Items, item); err != nil {
+				return wrap(err, "item %d", i)
+			}
+			i++
+		}
+	}
+	if v.Kind() == reflect.Array {
+		for ; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	}
+	return nil
+}
+
+func (d *decoder) mapValue(w, r *Schema, v reflect.Value) error {
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return d.typeError(r, v.Type(), d.pos)
+	}
+	if d.depth++; d.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { d.depth-- }()
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	for {
+		n, err := d.blockCount()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		for ; n > 0; n-- {
+			key, err := d.string()
+			if err != nil {
+				return err
+			}
+			item := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(w.Values, r.Values, item); err != nil {
+				return wrap(err, "key %s", key)
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), item)
+		}
+	}
+}
+
+// record reads a record into a struct or a map with string keys. Fields of
+// the writer unknown to the reader are skipped, and fields of the reader
+// unknown to the writer are set to their defaults.
+func (d *decoder) record(w, r *Schema, v reflect.Value) error {
+	var target func(name string) (reflect.Value, func())
+	switch {
+	case v.Kind() == reflect.Struct:
+		fs := fields.Of(v.Type(), _tags...)
+		target = func(name string) (reflect.Value, func()) {
+			f := lookupField(fs, name)
+			if f == nil {
+				return reflect.Value{}, nil
+			}
+			fv, _ := fields.ByIndex(v, f.Index, true)
+			return fv, nil
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		target = func(name string) (reflect.Value, func()) {
+			fv := reflect.New(v.Type().Elem()).Elem()
+			return fv, func() { v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), fv) }
+		}
+	default:
+		return d.typeError(r, v.Type(), d.pos)
+	}
+	if d.depth++; d.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { d.depth-- }()
+	read := make(map[*Field]bool, len(r.Fields))
+	for _, wf := range w.Fields {
+		rf := readerField(r, wf)
+		if rf == nil {
+			if err := d.skip(wf.Type); err != nil {
+				return wrap(err, "field %s", wf.Name)
+			}
+			continue
+		}
+		read[rf] = true
+		if err := d.field(wf.Type, rf, target); err != nil {
+			return err
+		}
+	}
+	for _, rf := range r.Fields {
+		if read[rf] {
+			continue
+		}
+		if !rf.HasDefault {
+			return fmt.Errorf("missing field %s of %s without default", rf.Name, r.Name)
+		}
+		dd := &decoder{data: rf.defaultData, depth: d.depth}
+		if err := dd.field(rf.Type, rf, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field reads a value of the writer schema w into the target of the reader
+// field rf, or skips it if there is no target.
+func (d *decoder) field(w *Schema, rf *Field, target func(name string) (reflect.Value, func())) error {
+	fv, set := target(rf.Name)
+	if !fv.IsValid() {
+		if err := d.skip(w); err != nil {
+			return wrap(err, "field %s", rf.Name)
+		}
+		return nil
+	}
+	if err := d.value(w, rf.Type, fv); err != nil {
+		return wrap(err, "field %s", rf.Name)
+	}
+	if set != nil {
+		set()
+	}
+	return nil
+}
+
+// readerField returns the field of the reader record r which the writer
+// field wf is read into, matched by names and aliases.
+func readerField(r *Schema, wf *Field) *Field {
+	for _, rf := range r.Fields {
+		if rf.Name == wf.Name {
+			return rf
+		}
+	}
+	for _, rf := range r.Fields {
+		for _, alias := range rf.Aliases {
+			if alias == wf.Name {
+				return rf
+			}
+		}
+	}
+	return nil
+}
+
+// skip skips a value of the writer schema w.
+func (d *decoder) skip(w *Schema) error {
+	switch w.Type {
+	case Null:
+		return nil
+	case Boolean:
+		_, err := d.read(1)
+		return err
+	case Int, Long, Enum:
+		_, err := d.long()
+		return err
+	case Float:
+		_, err := d.read(4)
+		return err
+	case Double:
+		_, err := d.read(8)
+		return err
+	case Bytes, String:
+		_, err := d.bytes()
+		return err
+	case Fixed:
+		_, err := d.read(w.Size)
+		return err
+	case Union:
+		i, err := d.index(len(w.Branches))
+		if err != nil {
+			return err
+		}
+		return d.skip(w.Branches[i])
+	}
+	if d.depth++; d.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { d.depth-- }()
+	switch w.Type {
+	case Array, Map:
+		for {
+			pos := d.pos
+			n, err := d.long()
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return nil
+			}
+			if n < 0 {
+				// A negative count is followed by the size of the block.
+				size, err := d.long()
+				if err != nil {
+					return err
+				}
+				if n == math.MinInt64 || size < 0 {
+					return fmt.Errorf("invalid block at offset %d", pos)
+				}
+				if size > int64(len(d.data)-d.pos) {
+					return errUnexpectedEnd
+				}
+				d.pos += int(size)
+				continue
+			}
+			for ; n > 0; n-- {
+				if w.Type == Map {
+					if _, err := d.bytes(); err != nil {
+						return err
+					}
+					if err := d.skip(w.Values); err != nil {
+						return err
+					}
+				} else if err := d.skip(w.Items); err != nil {
+					return err
+				}
+			}
+		}
+	case Record:
+		for _, f := range w.Fields {
+			if err := d.skip(f.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package avro
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecoder_Vectors(t *testing.T) {
+	for _, test := range _vectors {
+		s := MustParseSchema(test.schema)
+		if test.value == nil {
+			continue
+		}
+		v := reflect.New(reflect.TypeOf(test.value))
+		d := &decoder{data: mustHex(test.hex)}
+		if err := d.decode(s, s, v.Interface()); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := v.Elem().Interface()
+		if r, ok := test.value.(*big.Rat); ok {
+			if got.(*big.Rat).Cmp(r) != 0 {
+				t.Errorf("%s: got %v, want %v", test.name, got, r)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, test.value) {
+			t.Errorf("%s: got %#v, want %#v", test.name, got, test.value)
+		}
+	}
+}
+
+func TestDecoder_Generic(t *testing.T) {
+	tests := []struct {
+		schema string
+		hex    string
+		want   interface{}
+	}{
+		{`"null"`, "", nil},
+		{`"int"`, "02", int32(1)},
+		{`"long"`, "02", int64(1)},
+		{`"float"`, "0000c03f", float32(1.5)},
+		{`"bytes"`, "02 61", []byte("a")},
+		{`{"type": "enum", "name": "e", "symbols": ["A", "B"]}`, "02", "B"},
+		{`{"type": "fixed", "name": "f", "size": 1}`, "ff", []byte{0xff}},
+		{`["null", "string"]`, "02 0261", "a"},
+		{`{"type": "array", "items": "int"}`, "01 02 02 00", []interface{}{int32(1)}},
+		{`{"type": "map", "values": "boolean"}`, "02 0261 01 00", map[string]interface{}{"a": true}},
+		{`{"type": "record", "name": "r", "fields": [{"name": "a", "type": "int"}]}`, "02", map[string]interface{}{"a": int32(1)}},
+		{`{"type": "int", "logicalType": "date"}`, "01", time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{`{"type": "long", "logicalType": "time-micros"}`, "02", time.Microsecond},
+	}
+	for _, test := range tests {
+		s := MustParseSchema(test.schema)
+		var v interface{}
+		if err := (&decoder{data: mustHex(test.hex)}).decode(s, s, &v); err != nil {
+			t.Errorf("%s: %v", test.schema, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, test.want) {
+			t.Errorf("%s: got %#v, want %#v", test.schema, v, test.want)
+		}
+	}
+	s := MustParseSchema(`{"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}`)
+	var v interface{}
+	if err := (&decoder{data: mustHex("04 ff9c")}).decode(s, s, &v); err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := v.(*big.Rat); !ok || r.Cmp(big.NewRat(-1, 1)) != 0 {
+		t.Errorf("got %v", v)
+	}
+}
+
+func TestDecoder_Resolution(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer string
+		reader string
+		hex    string
+		want   interface{}
+	}{
+		{"int to long", `"int"`, `"long"`, "02", int64(1)},
+		{"int to double", `"int"`, `"double"`, "02", float64(1)},
+		{"float to double", `"float"`, `"double"`, "0000c03f", 1.5},
+		{"string to bytes", `"string"`, `"bytes"`, "02 61", []byte("a")},
+		{"bytes to string", `"bytes"`, `"string"`, "02 61", "a"},
+		{"writer union", `["null", "int"]`, `"long"`, "02 02", int64(1)},
+		{"reader union", `"int"`, `["null", "long"]`, "02", int64(1)},
+		{"reader union same type first", `"long"`, `["double", "long"]`, "02", int64(1)},
+		{"array promotion", `{"type": "array", "items": "int"}`, `{"type": "array", "items": "double"}`, "02 02 00",
+			[]interface{}{float64(1)}},
+		{"enum default", `{"type": "enum", "name": "e", "symbols": ["A", "B", "C"]}`,
+			`{"type": "enum", "name": "e", "symbols": ["A", "B"], "default": "A"}`, "04", "A"},
+		{"record fields", `{"type": "record", "name": "ns.r", "fields": [
+				{"name": "a", "type": "int"},
+				{"name": "gone", "type": {"type": "array", "items": "string"}},
+				{"name": "old", "type": "string"}]}`,
+			`{"type": "record", "name": "r", "fields": [
+				{"name": "new", "aliases": ["old"], "type": "string"},
+				{"name": "a", "type": "long"},
+				{"name": "added", "type": ["null", "int"], "default": null},
+				{"name": "more", "type": "string", "default": "x"}]}`,
+			"02 02 02 62 00 02 63",
+			map[string]interface{}{"a": int64(1), "new": "c", "added": nil, "more": "x"}},
+		{"record alias", `{"type": "record", "name": "old", "fields": []}`,
+			`{"type": "record", "name": "new", "aliases": ["old"], "fields": []}`, "", map[string]interface{}{}},
+	}
+	for _, test := range tests {
+		w, r := MustParseSchema(test.writer), MustParseSchema(test.reader)
+		var v interface{}
+		if err := (&decoder{data: mustHex(test.hex)}).decode(w, r, &v); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, test.want) {
+			t.Errorf("%s: got %#v, want %#v", test.name, v, test.want)
+		}
+	}
+}
+
+func TestDecoder_Struct(t *testing.T) {
+	w := MustParseSchema(`{"type": "record", "name": "point", "fields": [
+		{"name": "x", "type": "int"}, {"name": "y", "type": "int"}, {"name": "z", "type": "int"}]}`)
+	r := MustParseSchema(`{"type": "record", "name": "point", "fields": [
+		{"name": "x", "type": "long"}, {"name": "y", "type": "int"}]}`)
+	var p point
+	if err := (&decoder{data: mustHex("02 04 06")}).decode(w, r, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p != (point{X: 1, Y: 2}) {
+		t.Errorf("got %+v", p)
+	}
+	var pp *point
+	if err := (&decoder{data: mustHex("02 04 06")}).decode(w, w, &pp); err != nil {
+		t.Fatal(err)
+	}
+	if pp == nil || *pp != (point{X: 1, Y: 2}) {
+		t.Errorf("got %+v", pp)
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer string
+		reader string
+		hex    string
+		v      interface{}
+	}{
+		{"unexpected end", `"string"`, `"string"`, "04 61", new(string)},
+		{"trailing data", `"int"`, `"int"`, "02 02", new(int)},
+		{"int overflow", `"int"`, `"int"`, "ffffffff1f", new(int64)},
+		{"target overflow", `"int"`, `"int"`, "8002", new(int8)},
+		{"invalid boolean", `"boolean"`, `"boolean"`, "02", new(bool)},
+		{"invalid UTF-8", `"string"`, `"string"`, "02 ff", new(string)},
+		{"negative length", `"bytes"`, `"bytes"`, "01", new([]byte)},
+		{"union index", `["null", "int"]`, `["null", "int"]`, "04", new(interface{})},
+		{"enum index", `{"type": "enum", "name": "e", "symbols": ["A"]}`, `{"type": "enum", "name": "e", "symbols": ["A"]}`, "02", new(string)},
+		{"unresolvable", `"long"`, `"int"`, "02", new(int)},
+		{"unknown symbol", `{"type": "enum", "name": "e", "symbols": ["A", "B"]}`, `{"type": "enum", "name": "e", "symbols": ["A"]}`, "02", new(string)},
+		{"missing field", `{"type": "record", "name": "r", "fields": []}`,
+			`{"type": "record", "name": "r", "fields": [{"name": "a", "type": "int"}]}`, "", new(interface{})},
+		{"type mismatch", `"string"`, `"string"`, "02 61", new(int)},
+		{"array overflow", `{"type": "array", "items": "int"}`, `{"type": "array", "items": "int"}`, "04 02 02 00", new([1]int)},
+	}
+	for _, test := range tests {
+		w, r := MustParseSchema(test.writer), MustParseSchema(test.reader)
+		if err := (&decoder{data: mustHex(test.hex)}).decode(w, r, test.v); err == nil {
+			t.Errorf("%s: expect error", test.name)
+		}
+	}
+	if err := (&decoder{}).decode(MustParseSchema(`"null"`), MustParseSchema(`"null"`), nil); err == nil {
+		t.Error("expect error of nil pointer")
+	}
+}
+
+func TestDecoder_Skip(t *testing.T) {
+	w := MustParseSchema(`{"type": "record", "name": "r", "fields": [
+		{"name": "a", "type": {"type": "array", "items": "long"}},
+		{"name": "m", "type": {"type": "map", "values": ["null", "string"]}},
+		{"name": "f", "type": {"type": "fixed", "name": "f", "size": 2}},
+		{"name": "d", "type": "double"},
+		{"name": "b", "type": "boolean"}]}`)
+	r := MustParseSchema(`{"type": "record", "name": "r", "fields": [{"name": "b", "type": "boolean"}]}`)
+	// The array is written as a block with its size.
+	data := mustHex("03 04 02 04 00 02 0261 02 0262 00 0102 0000000000000000 01")
+	var v map[string]bool
+	if err := (&decoder{data: data}).decode(w, r, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v["b"] {
+		t.Errorf("got %v", v)
+	}
+}
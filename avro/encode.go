@@ -0,0 +1,555 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+// maxDepth is the maximum nesting depth of records, arrays and maps.
+const maxDepth = 1000
+
+var errDepth = fmt.Errorf("exceeded max nesting depth %d", maxDepth)
+
+// wrap prefixes err with the location of the value it occurred at. errDepth is
+// not wrapped, to keep its message short.
+func wrap(err error, format string, args ...interface{}) error {
+	if err == errDepth {
+		return err
+	}
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+}
+
+var (
+	_timeType     = reflect.TypeOf(time.Time{})
+	_durationType = reflect.TypeOf(time.Duration(0))
+	_ratType      = reflect.TypeOf(big.Rat{})
+	_unionType    = reflect.TypeOf(unionValue{})
+)
+
+type encoder struct {
+	buf   []byte
+	depth int
+}
+
+func (e *encoder) long(n int64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutVarint(b[:], n)]...)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.long(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// value appends the value v of the schema s.
+func (e *encoder) value(s *Schema, v reflect.Value) error {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if s.Type == Union {
+		return e.union(s, v)
+	}
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if isNil(v) {
+		if s.Type == Null {
+			return nil
+		}
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Map {
+			return fmt.Errorf("can not encode nil as %s", s.Type)
+		}
+	}
+	if s.LogicalType != "" {
+		if ok, err := e.logical(s, v); ok || err != nil {
+			return err
+		}
+	}
+	switch s.Type {
+	case Boolean:
+		if v.Kind() == reflect.Bool {
+			b := byte(0)
+			if v.Bool() {
+				b = 1
+			}
+			e.buf = append(e.buf, b)
+			return nil
+		}
+	case Int, Long:
+		n, ok := intValue(v)
+		if !ok {
+			break
+		}
+		if s.Type == Int && (n < math.MinInt32 || n > math.MaxInt32) {
+			return fmt.Errorf("%s %v overflows int", v.Type(), v)
+		}
+		e.long(n)
+		return nil
+	case Float, Double:
+		var f float64
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = v.Float()
+		default:
+			n, ok := intValue(v)
+			if !ok {
+				return e.typeError(s, v)
+			}
+			f = float64(n)
+		}
+		if s.Type == Float {
+			var b [4]byte
+			binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(f)))
+			e.buf = append(e.buf, b[:]...)
+		} else {
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+			e.buf = append(e.buf, b[:]...)
+		}
+		return nil
+	case Bytes:
+		if b, ok := bytesValue(v); ok {
+			e.bytes(b)
+			return nil
+		}
+	case String:
+		if v.Kind() == reflect.String {
+			e.bytes([]byte(v.String()))
+			return nil
+		}
+	case Fixed:
+		if b, ok := bytesValue(v); ok {
+			if len(b) != s.Size {
+				return fmt.Errorf("can not encode %d bytes as fixed %s of size %d", len(b), s.Name, s.Size)
+			}
+			e.buf = append(e.buf, b...)
+			return nil
+		}
+	case Enum:
+		return e.enum(s, v)
+	case Array:
+		return e.array(s, v)
+	case Map:
+		return e.mapValue(s, v)
+	case Record:
+		return e.record(s, v)
+	}
+	return e.typeError(s, v)
+}
+
+func (e *encoder) typeError(s *Schema, v reflect.Value) error {
+	name := string(s.Type)
+	if s.Name != "" {
+		name += " " + s.Name
+	}
+	return fmt.Errorf("can not encode %s as %s", v.Type(), name)
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func intValue(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// bytesValue returns the bytes of a byte slice or a byte array.
+func bytesValue(v reflect.Value) ([]byte, bool) {
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return v.Bytes(), true
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b, true
+	}
+	return nil, false
+}
+
+// logical appends a value of a logical type, reporting false if v is not of
+// a Go type representing the logical type, in which case it is encoded as the
+// underlying type.
+func (e *encoder) logical(s *Schema, v reflect.Value) (bool, error) {
+	switch t := v.Type(); {
+	case t == _timeType:
+		tm := v.Interface().(time.Time)
+		switch s.LogicalType {
+		case LogicalDate:
+			sec := tm.Unix()
+			days := sec / 86400
+			if sec%86400 < 0 {
+				days--
+			}
+			if days < math.MinInt32 || days > math.MaxInt32 {
+				return true, fmt.Errorf("time %v overflows date", tm)
+			}
+			e.long(days)
+		case LogicalTimestampMillis, LogicalTimestampMicros,
+			LogicalLocalTimestampMillis, LogicalLocalTimestampMicros:
+			sec := tm.Unix()
+			if strings.HasPrefix(s.LogicalType, "local-") {
+				_, offset := tm.Zone()
+				sec += int64(offset)
+			}
+			if strings.HasSuffix(s.LogicalType, "millis") {
+				e.long(sec*1e3 + int64(tm.Nanosecond())/1e6)
+			} else {
+				e.long(sec*1e6 + int64(tm.Nanosecond())/1e3)
+			}
+		default:
+			return false, nil
+		}
+		return true, nil
+	case t == _durationType:
+		d := time.Duration(v.Int())
+		switch s.LogicalType {
+		case LogicalTimeMillis:
+			e.long(int64(d / time.Millisecond))
+		case LogicalTimeMicros:
+			e.long(int64(d / time.Microsecond))
+		default:
+			return false, nil
+		}
+		return true, nil
+	case t == _ratType && s.LogicalType == LogicalDecimal:
+		var r *big.Rat
+		if v.CanAddr() {
+			r = v.Addr().Interface().(*big.Rat)
+		} else {
+			c := v.Interface().(big.Rat)
+			r = &c
+		}
+		b, err := decimalBytes(s, r)
+		if err != nil {
+			return true, err
+		}
+		if s.Type == Bytes {
+			e.bytes(b)
+		} else {
+			e.buf = append(e.buf, b...)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// decimalBytes returns the unscaled value of a decimal in the big-endian
+// two's complement, of the size of s if it is a fixed.
+func decimalBytes(s *Schema, r *big.Rat) ([]byte, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(s.Scale)), nil)))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("decimal %s can not be represented at scale %d", r.RatString(), s.Scale)
+	}
+	n := scaled.Num()
+	if abs := new(big.Int).Abs(n); abs.Sign() != 0 && len(abs.String()) > s.Precision {
+		return nil, fmt.Errorf("decimal %s exceeds precision %d", r.RatString(), s.Precision)
+	}
+	size := s.Size
+	if s.Type == Bytes {
+		// The minimal size holding the sign bit.
+		if n.Sign() >= 0 {
+			size = n.BitLen()/8 + 1
+		} else {
+			size = new(big.Int).Not(n).BitLen()/8 + 1
+		}
+	}
+	b := make([]byte, size)
+	if n.Sign() >= 0 {
+		n.FillBytes(b)
+	} else {
+		// The two's complement of n is 2^(8*size) + n.
+		m := new(big.Int).Lsh(big.NewInt(1), uint(8*size))
+		m.Add(m, n).FillBytes(b)
+	}
+	return b, nil
+}
+
+func (e *encoder) union(s *Schema, v reflect.Value) error {
+	if v.IsValid() && v.Type() == _unionType {
+		u := v.Interface().(unionValue)
+		e.long(int64(u.index))
+		return e.value(s.Branches[u.index], reflect.ValueOf(u.value))
+	}
+	i := branch(s, v)
+	if i < 0 {
+		if !v.IsValid() {
+			return errors.New("can not encode nil as union without null")
+		}
+		return fmt.Errorf("can not encode %s as any branch of union", v.Type())
+	}
+	e.long(int64(i))
+	return e.value(s.Branches[i], v)
+}
+
+// branch returns the index of the branch of the union s chosen for v, which
+// is the first branch matching v best, or -1 if no branch matches.
+func branch(s *Schema, v reflect.Value) int {
+	best, index := 0, -1
+	for i, b := range s.Branches {
+		if m := matches(b, v); m > best {
+			best, index = m, i
+		}
+	}
+	return index
+}
+
+// Grades of matching of a value and a schema.
+const (
+	matchNone = iota
+	matchLoose
+	matchExact
+)
+
+// matches grades how v matches the schema s, a branch of a union.
+func matches(s *Schema, v reflect.Value) int {
+	if isNil(v) {
+		switch {
+		case s.Type == Null:
+			return matchExact
+		case v.Kind() == reflect.Slice && s.Type == Array, v.Kind() == reflect.Map && (s.Type == Map || s.Type == Record):
+			return matchLoose
+		}
+		return matchNone
+	}
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	t := v.Type()
+	switch {
+	case t == _timeType:
+		switch s.LogicalType {
+		case LogicalDate, LogicalTimestampMillis, LogicalTimestampMicros,
+			LogicalLocalTimestampMillis, LogicalLocalTimestampMicros:
+			return matchExact
+		}
+		return matchNone
+	case t == _durationType:
+		if s.LogicalType == LogicalTimeMillis || s.LogicalType == LogicalTimeMicros {
+			return matchExact
+		}
+	case t == _ratType:
+		if s.LogicalType == LogicalDecimal {
+			return matchExact
+		}
+		return matchNone
+	}
+	switch k := v.Kind(); s.Type {
+	case Boolean:
+		if k == reflect.Bool {
+			return matchExact
+		}
+	case Int:
+		switch k {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+			return matchExact
+		}
+	case Long:
+		switch k {
+		case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return matchExact
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+			return matchLoose
+		}
+	case Float:
+		if k == reflect.Float32 {
+			return matchExact
+		}
+	case Double:
+		switch k {
+		case reflect.Float64:
+			return matchExact
+		case reflect.Float32:
+			return matchLoose
+		}
+	case String:
+		if k == reflect.String {
+			return matchExact
+		}
+	case Enum:
+		if k == reflect.String {
+			for _, symbol := range s.Symbols {
+				if symbol == v.String() {
+					return matchExact
+				}
+			}
+		}
+	case Bytes:
+		if k == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return matchExact
+		}
+	case Fixed:
+		if k == reflect.Array && t.Elem().Kind() == reflect.Uint8 && t.Len() == s.Size {
+			return matchExact
+		}
+	case Array:
+		if (k == reflect.Slice || k == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+			return matchExact
+		}
+	case Map:
+		if k == reflect.Map && t.Key().Kind() == reflect.String {
+			return matchExact
+		}
+	case Record:
+		switch {
+		case k == reflect.Struct && t.Name() == shortName(s.Name):
+			return matchExact
+		case k == reflect.Struct, k == reflect.Map && t.Key().Kind() == reflect.String:
+			return matchLoose
+		}
+	}
+	return matchNone
+}
+
+// shortName returns the unqualified name of a full name.
+func shortName(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+func (e *encoder) enum(s *Schema, v reflect.Value) error {
+	if v.Kind() == reflect.String {
+		for i, symbol := range s.Symbols {
+			if symbol == v.String() {
+				e.long(int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown symbol %q of enum %s", v.String(), s.Name)
+	}
+	n, ok := intValue(v)
+	if !ok {
+		return e.typeError(s, v)
+	}
+	if n < 0 || n >= int64(len(s.Symbols)) {
+		return fmt.Errorf("index %d out of range of enum %s", n, s.Name)
+	}
+	e.long(n)
+	return nil
+}
+
+func (e *encoder) array(s *Schema, v reflect.Value) error {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return e.typeError(s, v)
+	}
+	if e.depth++; e.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { e.depth-- }()
+	if n := v.Len(); n > 0 {
+		e.long(int64(n))
+		for i := 0; i < n; i++ {
+			if err := e.value(s.Items, v.Index(i)); err != nil {
+				return wrap(err, "item %d", i)
+			}
+		}
+	}
+	e.long(0)
+	return nil
+}
+
+func (e *encoder) mapValue(s *Schema, v reflect.Value) error {
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return e.typeError(s, v)
+	}
+	if e.depth++; e.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { e.depth-- }()
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	if len(keys) > 0 {
+		e.long(int64(len(keys)))
+		for _, key := range keys {
+			e.bytes([]byte(key.String()))
+			if err := e.value(s.Values, v.MapIndex(key)); err != nil {
+				return wrap(err, "key %s", key.String())
+			}
+		}
+	}
+	e.long(0)
+	return nil
+}
+
+// record appends a record from a struct or a map with string keys. Fields
+// missing from the value are encoded as their defaults.
+func (e *encoder) record(s *Schema, v reflect.Value) error {
+	var lookup func(name string) (reflect.Value, bool)
+	switch {
+	case v.Kind() == reflect.Struct:
+		fs := fields.Of(v.Type(), _tags...)
+		lookup = func(name string) (reflect.Value, bool) {
+			f := lookupField(fs, name)
+			if f == nil {
+				return reflect.Value{}, false
+			}
+			return fields.ByIndex(v, f.Index, false)
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		lookup = func(name string) (reflect.Value, bool) {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			fv := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			return fv, fv.IsValid()
+		}
+	default:
+		return e.typeError(s, v)
+	}
+	if e.depth++; e.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { e.depth-- }()
+	for _, f := range s.Fields {
+		fv, ok := lookup(f.Name)
+		if !ok {
+			if !f.HasDefault {
+				return fmt.Errorf("missing field %s of %s", f.Name, s.Name)
+			}
+			e.buf = append(e.buf, f.defaultData...)
+			continue
+		}
+		if err := e.value(f.Type, fv); err != nil {
+			return wrap(err, "field %s", f.Name)
+		}
+	}
+	return nil
+}
+
+// lookupField returns the field of the name, or of the name in another case
+// if no field has the exact name.
+func lookupField(fs []fields.Field, name string) *fields.Field {
+	for i := range fs {
+		if fs[i].Name == name {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].Name, name) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
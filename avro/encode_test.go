@@ -0,0 +1,161 @@
+package avro
+
+import (
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+type point struct {
+	X int32 `avro:"x"`
+	Y int32 `json:"y"`
+}
+
+type node struct {
+	Value    string  `avro:"value"`
+	Children []*node `avro:"children"`
+}
+
+// _vectors are values which round trip through their schemas.
+var _vectors = []struct {
+	name   string
+	schema string
+	value  interface{}
+	hex    string
+}{
+	{"null", `"null"`, nil, ""},
+	{"true", `"boolean"`, true, "01"},
+	{"int", `"int"`, int32(-64), "7f"},
+	{"long", `"long"`, int64(64), "8001"},
+	{"float", `"float"`, float32(1.5), "0000c03f"},
+	{"double", `"double"`, 1.5, "000000000000f83f"},
+	{"bytes", `"bytes"`, []byte{1, 2}, "04 0102"},
+	{"string", `"string"`, "foo", "06 666f6f"},
+	{"enum", `{"type": "enum", "name": "e", "symbols": ["A", "B"]}`, "B", "02"},
+	{"fixed", `{"type": "fixed", "name": "f", "size": 2}`, [2]byte{1, 2}, "0102"},
+	{"array", `{"type": "array", "items": "long"}`, []int64{3, 27}, "04 06 36 00"},
+	{"empty array", `{"type": "array", "items": "long"}`, []int64{}, "00"},
+	{"map", `{"type": "map", "values": "int"}`, map[string]int32{"b": 2, "a": 1}, "04 0261 02 0262 04 00"},
+	{"union null", `["null", "string"]`, (*string)(nil), "00"},
+	{"union string", `["null", "string"]`, "a", "02 0261"},
+	{"record", `{"type": "record", "name": "point", "fields": [{"name": "x", "type": "int"}, {"name": "y", "type": "int"}]}`,
+		point{X: 1, Y: -1}, "02 01"},
+	{"recursive", `{"type": "record", "name": "node", "fields": [
+		{"name": "value", "type": "string"},
+		{"name": "children", "type": {"type": "array", "items": "node"}}]}`,
+		node{Value: "a", Children: []*node{{Value: "b", Children: []*node{}}}}, "02 61 02 02 62 00 00"},
+	{"date", `{"type": "int", "logicalType": "date"}`, time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), "02"},
+	{"timestamp-millis", `{"type": "long", "logicalType": "timestamp-millis"}`, time.Unix(1, 5e6).UTC(), "da0f"},
+	{"timestamp-micros before epoch", `{"type": "long", "logicalType": "timestamp-micros"}`, time.Unix(-1, 0).UTC(), "ff887a"},
+	{"time-millis", `{"type": "int", "logicalType": "time-millis"}`, 2 * time.Second, "a01f"},
+	{"decimal bytes", `{"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}`, big.NewRat(-1, 100), "02 ff"},
+	{"decimal fixed", `{"type": "fixed", "name": "d", "size": 2, "logicalType": "decimal", "precision": 4, "scale": 1}`, big.NewRat(256, 10), "0100"},
+}
+
+func TestEncoder_Vectors(t *testing.T) {
+	for _, test := range _vectors {
+		s := MustParseSchema(test.schema)
+		e := &encoder{}
+		if err := e.value(s, reflect.ValueOf(test.value)); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if want := mustHex(test.hex); string(e.buf) != string(want) {
+			t.Errorf("%s: got % x, want % x", test.name, e.buf, want)
+		}
+	}
+}
+
+func TestEncoder_Union(t *testing.T) {
+	tests := []struct {
+		schema string
+		value  interface{}
+		hex    string
+	}{
+		{`["int", "long"]`, int64(1), "02 02"},
+		{`["long", "int"]`, int32(1), "02 02"},
+		{`["long", "double"]`, 1.5, "02 000000000000f83f"},
+		{`["null", {"type": "array", "items": "int"}]`, []int32(nil), "00"},
+		{`["string", {"type": "long", "logicalType": "timestamp-millis"}]`, time.Unix(0, 0), "02 00"},
+		{`[{"type": "record", "name": "a", "fields": []}, {"type": "record", "name": "point", "fields": [{"name": "x", "type": "int"}]}]`,
+			point{X: 1}, "02 02"},
+		{`["null", {"type": "enum", "name": "e", "symbols": ["A"]}, "string"]`, "A", "02 00"},
+		{`["null", {"type": "enum", "name": "e", "symbols": ["A"]}, "string"]`, "B", "04 0242"},
+	}
+	for _, test := range tests {
+		e := &encoder{}
+		if err := e.value(MustParseSchema(test.schema), reflect.ValueOf(test.value)); err != nil {
+			t.Errorf("%s: %v", test.schema, err)
+			continue
+		}
+		if want := mustHex(test.hex); string(e.buf) != string(want) {
+			t.Errorf("%s: got % x, want % x", test.schema, e.buf, want)
+		}
+	}
+}
+
+func TestEncoder_Defaults(t *testing.T) {
+	s := MustParseSchema(`{"type": "record", "name": "r", "fields": [
+		{"name": "a", "type": "int"},
+		{"name": "b", "type": "string", "default": "x"},
+		{"name": "c", "type": ["null", "int"], "default": null}]}`)
+	e := &encoder{}
+	if err := e.value(s, reflect.ValueOf(map[string]interface{}{"a": 1})); err != nil {
+		t.Fatal(err)
+	}
+	if want := mustHex("02 0278 00"); string(e.buf) != string(want) {
+		t.Errorf("got % x, want % x", e.buf, want)
+	}
+	if err := (&encoder{}).value(s, reflect.ValueOf(map[string]interface{}{})); err == nil {
+		t.Error("expect error of missing field")
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	tests := []struct {
+		schema string
+		value  interface{}
+	}{
+		{`"int"`, int64(1) << 40},
+		{`"int"`, "1"},
+		{`"string"`, nil},
+		{`{"type": "enum", "name": "e", "symbols": ["A"]}`, "B"},
+		{`{"type": "enum", "name": "e", "symbols": ["A"]}`, 1},
+		{`{"type": "fixed", "name": "f", "size": 2}`, []byte{1}},
+		{`["null", "int"]`, "a"},
+		{`{"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 1}`, big.NewRat(1, 100)},
+		{`{"type": "bytes", "logicalType": "decimal", "precision": 2, "scale": 1}`, big.NewRat(100, 1)},
+		{`{"type": "record", "name": "r", "fields": [{"name": "p", "type": {"type": "record", "name": "point", "fields": [{"name": "z", "type": "int"}]}}]}`,
+			map[string]point{"p": {}}},
+	}
+	for _, test := range tests {
+		if err := (&encoder{}).value(MustParseSchema(test.schema), reflect.ValueOf(test.value)); err == nil {
+			t.Errorf("%s: expect error of %v", test.schema, test.value)
+		}
+	}
+}
+
+func TestEncoder_Depth(t *testing.T) {
+	s := MustParseSchema(`{"type": "record", "name": "n", "fields": [{"name": "next", "type": ["null", "n"]}]}`)
+	type n struct {
+		Next interface{} `avro:"next"`
+	}
+	var v interface{}
+	for i := 0; i <= maxDepth; i++ {
+		v = n{Next: v}
+	}
+	if err := (&encoder{}).value(s, reflect.ValueOf(v)); err != errDepth {
+		t.Errorf("got error %v, want %v", err, errDepth)
+	}
+}
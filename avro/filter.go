@@ -0,0 +1,169 @@
+package avro
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Schema is the schema of values, which is required.
+	Schema *Schema
+	// Container encodes a slice or an array of values as an object container
+	// file, instead of a single value.
+	Container bool
+	// Compression is the compression codec of blocks of object container
+	// files, CompressionNull if empty.
+	Compression string
+	// BlockLength is the count of values per block of object container files,
+	// DefaultBlockLength if not positive.
+	BlockLength int
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// Schema is the schema data was written with, which is required unless
+	// decoding an object container file, which carries its schema.
+	Schema *Schema
+	// ReaderSchema is the schema values are resolved into, which defaults to
+	// the writer schema.
+	ReaderSchema *Schema
+	// Container decodes an object container file into a pointer to a slice,
+	// instead of a single value.
+	Container bool
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// DecoderSchema produces a DecoderOption which sets the writer schema.
+func DecoderSchema(schema *Schema) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Schema = schema
+	}
+}
+
+// ReaderSchema produces a DecoderOption which sets the reader schema values
+// are resolved into.
+func ReaderSchema(schema *Schema) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.ReaderSchema = schema
+	}
+}
+
+// DecodeContainer produces a DecoderOption which decodes object container
+// files.
+func DecodeContainer() DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Container = true
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// EncoderSchema produces an EncoderOption which sets the schema of values.
+func EncoderSchema(schema *Schema) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Schema = schema
+	}
+}
+
+// EncodeContainer produces an EncoderOption which encodes object container
+// files.
+func EncodeContainer() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Container = true
+	}
+}
+
+// Compression produces an EncoderOption which sets the compression codec of
+// object container files.
+func Compression(name string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Compression = name
+	}
+}
+
+// BlockLength produces an EncoderOption which sets the count of values per
+// block of object container files.
+func BlockLength(n int) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.BlockLength = n
+	}
+}
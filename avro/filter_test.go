@@ -0,0 +1,59 @@
+package avro
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, EncoderSchema(MustParseSchema(`"long"`)))
+	got, err := m.Marshal(context.Background(), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustHex("8001"); string(got) != string(want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+	if _, err := (&codec{}).Marshal(context.Background(), 64); err != errSchemaRequired {
+		t.Errorf("got error %v, want %v", err, errSchemaRequired)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	u := WithDecoderOption(&codec{}, DecoderSchema(MustParseSchema(`"int"`)), ReaderSchema(MustParseSchema(`["null", "double"]`)))
+	var v interface{}
+	if err := u.Unmarshal(context.Background(), mustHex("02"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := float64(1); !reflect.DeepEqual(v, want) {
+		t.Errorf("got %#v, want %#v", v, want)
+	}
+	if err := (&codec{}).Unmarshal(context.Background(), mustHex("02"), &v); err != errSchemaRequired {
+		t.Errorf("got error %v, want %v", err, errSchemaRequired)
+	}
+}
+
+func TestContainerOptions(t *testing.T) {
+	s := MustParseSchema(_pointSchema)
+	in := []point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}}
+	m := WithEncoderOption(&codec{}, EncoderSchema(s), EncodeContainer(), Compression(CompressionDeflate), BlockLength(2))
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []point
+	if err := WithDecoderOption(&codec{}, DecodeContainer()).Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %v, want %v", out, in)
+	}
+	if _, err := m.Marshal(context.Background(), in[0]); err == nil {
+		t.Error("expect error of marshaling non-slice as container")
+	}
+	var p point
+	if err := WithDecoderOption(&codec{}, DecodeContainer()).Unmarshal(context.Background(), data, &p); err == nil {
+		t.Error("expect error of unmarshaling container to non-slice")
+	}
+}
@@ -0,0 +1,629 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Type is the type of a schema.
+type Type string
+
+// Types of schemas.
+const (
+	Null    Type = "null"
+	Boolean Type = "boolean"
+	Int     Type = "int"
+	Long    Type = "long"
+	Float   Type = "float"
+	Double  Type = "double"
+	Bytes   Type = "bytes"
+	String  Type = "string"
+	Record  Type = "record"
+	Enum    Type = "enum"
+	Array   Type = "array"
+	Map     Type = "map"
+	Union   Type = "union"
+	Fixed   Type = "fixed"
+)
+
+// Logical types.
+const (
+	LogicalDecimal              = "decimal"
+	LogicalUUID                 = "uuid"
+	LogicalDate                 = "date"
+	LogicalTimeMillis           = "time-millis"
+	LogicalTimeMicros           = "time-micros"
+	LogicalTimestampMillis      = "timestamp-millis"
+	LogicalTimestampMicros      = "timestamp-micros"
+	LogicalLocalTimestampMillis = "local-timestamp-millis"
+	LogicalLocalTimestampMicros = "local-timestamp-micros"
+)
+
+// _logicalTypes are the underlying types of logical types, other than
+// decimal.
+var _logicalTypes = map[string]Type{
+	LogicalUUID:                 String,
+	LogicalDate:                 Int,
+	LogicalTimeMillis:           Int,
+	LogicalTimeMicros:           Long,
+	LogicalTimestampMillis:      Long,
+	LogicalTimestampMicros:      Long,
+	LogicalLocalTimestampMillis: Long,
+	LogicalLocalTimestampMicros: Long,
+}
+
+// Schema is a parsed Avro schema. Which fields are meaningful depends on the
+// Type of the schema. Schemas must not be modified after parsing.
+type Schema struct {
+	Type Type
+	// Name is the full name of a record, an enum or a fixed.
+	Name string
+	// Aliases are the full names of aliases of a named schema.
+	Aliases []string
+	// Fields are the fields of a record.
+	Fields []*Field
+	// Symbols are the symbols of an enum.
+	Symbols []string
+	// EnumDefault is the symbol of an enum used by schema resolution for
+	// symbols unknown to the reader, if HasEnumDefault is set.
+	EnumDefault    string
+	HasEnumDefault bool
+	// Items is the schema of items of an array.
+	Items *Schema
+	// Values is the schema of values of a map.
+	Values *Schema
+	// Branches are the schemas of a union.
+	Branches []*Schema
+	// Size is the size in bytes of a fixed.
+	Size int
+	// LogicalType is the logical type annotating the schema, if valid.
+	LogicalType string
+	// Precision and Scale are the attributes of the decimal logical type.
+	Precision int
+	Scale     int
+}
+
+// Field is a field of a record.
+type Field struct {
+	Name    string
+	Aliases []string
+	Type    *Schema
+	// Default is the default value of the field, as decoded from JSON by
+	// encoding/json with numbers as json.Number, if HasDefault is set.
+	Default    interface{}
+	HasDefault bool
+	// defaultData is the default value encoded in the field type.
+	defaultData []byte
+}
+
+var _nameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseSchema parses a schema in JSON.
+func ParseSchema(text string) (*Schema, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var node interface{}
+	if err := dec.Decode(&node); err != nil {
+		return nil, fmt.Errorf("avro: invalid schema: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("avro: invalid schema: unexpected data after schema")
+	}
+	p := &parser{names: map[string]*Schema{}}
+	s, err := p.parse(node, "")
+	if err != nil {
+		return nil, fmt.Errorf("avro: invalid schema: %w", err)
+	}
+	for _, f := range p.defaults {
+		if f.defaultData, err = encodeDefault(f.Type, f.Default); err != nil {
+			return nil, fmt.Errorf("avro: invalid schema: default of field %s: %w", f.Name, err)
+		}
+	}
+	return s, nil
+}
+
+// MustParseSchema is like ParseSchema but panics if the schema is invalid.
+func MustParseSchema(text string) *Schema {
+	s, err := ParseSchema(text)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type parser struct {
+	names map[string]*Schema
+	// defaults are fields with defaults, which are encoded after parsing, when
+	// all named schemas they refer to are complete.
+	defaults []*Field
+}
+
+// fullName returns the full name and the namespace of a name in the
+// namespace ns.
+func fullName(name, ns string) (string, string, error) {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		ns = name[:i]
+		name = name[i+1:]
+	} else if ns != "" {
+		name = ns + "." + name
+		ns = name[:strings.LastIndexByte(name, '.')]
+		name = name[len(ns)+1:]
+	}
+	if !_nameRegexp.MatchString(name) {
+		return "", "", fmt.Errorf("invalid name %q", name)
+	}
+	if ns == "" {
+		return name, "", nil
+	}
+	for _, part := range strings.Split(ns, ".") {
+		if !_nameRegexp.MatchString(part) {
+			return "", "", fmt.Errorf("invalid namespace %q", ns)
+		}
+	}
+	return ns + "." + name, ns, nil
+}
+
+func (p *parser) parse(node interface{}, ns string) (*Schema, error) {
+	switch n := node.(type) {
+	case string:
+		switch t := Type(n); t {
+		case Null, Boolean, Int, Long, Float, Double, Bytes, String:
+			return &Schema{Type: t}, nil
+		}
+		if s, ok := p.names[n]; ok {
+			return s, nil
+		}
+		if name, _, err := fullName(n, ns); err == nil {
+			if s, ok := p.names[name]; ok {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown type %q", n)
+	case []interface{}:
+		s := &Schema{Type: Union}
+		seen := map[string]bool{}
+		for _, item := range n {
+			b, err := p.parse(item, ns)
+			if err != nil {
+				return nil, err
+			}
+			if b.Type == Union {
+				return nil, fmt.Errorf("union contains union")
+			}
+			key := string(b.Type)
+			if b.Name != "" {
+				key = b.Name
+			}
+			if seen[key] {
+				return nil, fmt.Errorf("union contains %s twice", key)
+			}
+			seen[key] = true
+			s.Branches = append(s.Branches, b)
+		}
+		return s, nil
+	case map[string]interface{}:
+		return p.complex(n, ns)
+	}
+	return nil, fmt.Errorf("invalid schema %v", node)
+}
+
+func (p *parser) complex(n map[string]interface{}, ns string) (*Schema, error) {
+	t, ok := n["type"].(string)
+	if !ok {
+		if inner, ok := n["type"]; ok {
+			// A schema whose type is a schema, e.g. {"type": {"type": "int"}}.
+			return p.parse(inner, ns)
+		}
+		return nil, fmt.Errorf("missing type")
+	}
+	var s *Schema
+	var err error
+	switch Type(t) {
+	case Record, Enum, Fixed, "error":
+		s, err = p.named(n, ns)
+	case Array:
+		s = &Schema{Type: Array}
+		s.Items, err = p.parse(n["items"], ns)
+	case Map:
+		s = &Schema{Type: Map}
+		s.Values, err = p.parse(n["values"], ns)
+	default:
+		if s, err = p.parse(t, ns); err != nil || s.Name != "" {
+			// References to named schemas are not annotated.
+			return s, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if logical, ok := n["logicalType"].(string); ok && s.LogicalType == "" {
+		annotate(s, logical, n)
+	}
+	return s, nil
+}
+
+// annotate sets the logical type of s if it is valid. Invalid logical types
+// are ignored, as the spec requires.
+func annotate(s *Schema, logical string, n map[string]interface{}) {
+	if logical == LogicalDecimal {
+		if s.Type != Bytes && s.Type != Fixed {
+			return
+		}
+		precision, ok1 := intOf(n["precision"])
+		scale, ok2 := intOf(n["scale"])
+		if !ok2 && n["scale"] == nil {
+			scale, ok2 = 0, true
+		}
+		if !ok1 || !ok2 || precision <= 0 || scale < 0 || scale > precision {
+			return
+		}
+		if s.Type == Fixed && precision > maxDigits(s.Size) {
+			return
+		}
+		s.LogicalType, s.Precision, s.Scale = logical, precision, scale
+		return
+	}
+	if t, ok := _logicalTypes[logical]; ok && t == s.Type {
+		s.LogicalType = logical
+	}
+}
+
+// maxDigits returns the number of decimal digits a signed integer of n bytes
+// can always hold.
+func maxDigits(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), uint(8*n-1))
+	return len(max.Sub(max, big.NewInt(1)).String()) - 1
+}
+
+func intOf(x interface{}) (int, bool) {
+	n, ok := x.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	return int(i), err == nil
+}
+
+func (p *parser) named(n map[string]interface{}, ns string) (*Schema, error) {
+	name, ok := n["name"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing name of %v", n["type"])
+	}
+	if space, ok := n["namespace"].(string); ok && !strings.Contains(name, ".") {
+		ns = space
+	}
+	full, ns, err := fullName(name, ns)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.names[full]; ok {
+		return nil, fmt.Errorf("type %s is defined twice", full)
+	}
+	t := Type(n["type"].(string))
+	if t == "error" {
+		t = Record
+	}
+	s := &Schema{Type: t, Name: full}
+	if aliases, ok := n["aliases"].([]interface{}); ok {
+		for _, a := range aliases {
+			alias, ok := a.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid alias %v of %s", a, full)
+			}
+			if alias, _, err = fullName(alias, ns); err != nil {
+				return nil, err
+			}
+			s.Aliases = append(s.Aliases, alias)
+		}
+	}
+	// Register the name before parsing fields, for recursive types.
+	p.names[full] = s
+	switch t {
+	case Record:
+		return s, p.fields(s, n["fields"], ns)
+	case Enum:
+		symbols, ok := n["symbols"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("missing symbols of %s", full)
+		}
+		seen := map[string]bool{}
+		for _, x := range symbols {
+			symbol, ok := x.(string)
+			if !ok || !_nameRegexp.MatchString(symbol) || seen[symbol] {
+				return nil, fmt.Errorf("invalid symbol %v of %s", x, full)
+			}
+			seen[symbol] = true
+			s.Symbols = append(s.Symbols, symbol)
+		}
+		if x, ok := n["default"]; ok {
+			symbol, ok := x.(string)
+			if !ok || !seen[symbol] {
+				return nil, fmt.Errorf("invalid default %v of %s", x, full)
+			}
+			s.EnumDefault, s.HasEnumDefault = symbol, true
+		}
+	case Fixed:
+		size, ok := intOf(n["size"])
+		if !ok || size < 0 {
+			return nil, fmt.Errorf("invalid size %v of %s", n["size"], full)
+		}
+		s.Size = size
+	}
+	return s, nil
+}
+
+func (p *parser) fields(s *Schema, node interface{}, ns string) error {
+	fields, ok := node.([]interface{})
+	if !ok {
+		return fmt.Errorf("missing fields of %s", s.Name)
+	}
+	seen := map[string]bool{}
+	for _, x := range fields {
+		n, ok := x.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid field %v of %s", x, s.Name)
+		}
+		name, ok := n["name"].(string)
+		if !ok || !_nameRegexp.MatchString(name) || seen[name] {
+			return fmt.Errorf("invalid field name %v of %s", n["name"], s.Name)
+		}
+		seen[name] = true
+		t, ok := n["type"]
+		if !ok {
+			return fmt.Errorf("missing type of field %s of %s", name, s.Name)
+		}
+		ft, err := p.parse(t, ns)
+		if err != nil {
+			return err
+		}
+		f := &Field{Name: name, Type: ft}
+		if aliases, ok := n["aliases"].([]interface{}); ok {
+			for _, a := range aliases {
+				alias, ok := a.(string)
+				if !ok || !_nameRegexp.MatchString(alias) {
+					return fmt.Errorf("invalid alias %v of field %s of %s", a, name, s.Name)
+				}
+				f.Aliases = append(f.Aliases, alias)
+			}
+		}
+		if def, ok := n["default"]; ok {
+			f.Default, f.HasDefault = def, true
+			p.defaults = append(p.defaults, f)
+		}
+		s.Fields = append(s.Fields, f)
+	}
+	return nil
+}
+
+// String returns the schema in JSON. Named schemas are defined at their first
+// occurrence, and referred to by their full names afterwards.
+func (s *Schema) String() string {
+	buf := &bytes.Buffer{}
+	s.write(buf, map[string]bool{})
+	return buf.String()
+}
+
+func writeJSON(buf *bytes.Buffer, x interface{}) {
+	b, _ := json.Marshal(x)
+	buf.Write(b)
+}
+
+func (s *Schema) write(buf *bytes.Buffer, defined map[string]bool) {
+	if s.Name != "" && defined[s.Name] {
+		writeJSON(buf, s.Name)
+		return
+	}
+	switch s.Type {
+	case Union:
+		buf.WriteByte('[')
+		for i, b := range s.Branches {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			b.write(buf, defined)
+		}
+		buf.WriteByte(']')
+		return
+	case Null, Boolean, Int, Long, Float, Double, Bytes, String:
+		if s.LogicalType == "" {
+			writeJSON(buf, s.Type)
+			return
+		}
+	}
+	buf.WriteString(`{"type":`)
+	writeJSON(buf, s.Type)
+	if s.Name != "" {
+		defined[s.Name] = true
+		buf.WriteString(`,"name":`)
+		writeJSON(buf, s.Name)
+		if len(s.Aliases) > 0 {
+			buf.WriteString(`,"aliases":`)
+			writeJSON(buf, s.Aliases)
+		}
+	}
+	switch s.Type {
+	case Record:
+		buf.WriteString(`,"fields":[`)
+		for i, f := range s.Fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`{"name":`)
+			writeJSON(buf, f.Name)
+			if len(f.Aliases) > 0 {
+				buf.WriteString(`,"aliases":`)
+				writeJSON(buf, f.Aliases)
+			}
+			buf.WriteString(`,"type":`)
+			f.Type.write(buf, defined)
+			if f.HasDefault {
+				buf.WriteString(`,"default":`)
+				writeJSON(buf, f.Default)
+			}
+			buf.WriteByte('}')
+		}
+		buf.WriteByte(']')
+	case Enum:
+		buf.WriteString(`,"symbols":`)
+		writeJSON(buf, s.Symbols)
+		if s.HasEnumDefault {
+			buf.WriteString(`,"default":`)
+			writeJSON(buf, s.EnumDefault)
+		}
+	case Array:
+		buf.WriteString(`,"items":`)
+		s.Items.write(buf, defined)
+	case Map:
+		buf.WriteString(`,"values":`)
+		s.Values.write(buf, defined)
+	case Fixed:
+		fmt.Fprintf(buf, `,"size":%d`, s.Size)
+	}
+	if s.LogicalType != "" {
+		buf.WriteString(`,"logicalType":`)
+		writeJSON(buf, s.LogicalType)
+		if s.LogicalType == LogicalDecimal {
+			fmt.Fprintf(buf, `,"precision":%d,"scale":%d`, s.Precision, s.Scale)
+		}
+	}
+	buf.WriteByte('}')
+}
+
+// defaultValue converts a default value decoded from JSON into a value the
+// encoder accepts for the schema s. Defaults of unions are of their first
+// branches, and defaults of bytes and fixed are strings whose code points are
+// the bytes.
+func defaultValue(s *Schema, x interface{}) (interface{}, error) {
+	invalid := fmt.Errorf("invalid default %v of %s", x, s.Type)
+	switch s.Type {
+	case Null:
+		if x != nil {
+			return nil, invalid
+		}
+		return nil, nil
+	case Boolean:
+		if _, ok := x.(bool); !ok {
+			return nil, invalid
+		}
+		return x, nil
+	case Int, Long:
+		n, ok := x.(json.Number)
+		if !ok {
+			return nil, invalid
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return nil, invalid
+		}
+		return i, nil
+	case Float, Double:
+		n, ok := x.(json.Number)
+		if !ok {
+			return nil, invalid
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, invalid
+		}
+		return f, nil
+	case String, Enum:
+		if _, ok := x.(string); !ok {
+			return nil, invalid
+		}
+		return x, nil
+	case Bytes, Fixed:
+		str, ok := x.(string)
+		if !ok {
+			return nil, invalid
+		}
+		b := make([]byte, 0, len(str))
+		for _, r := range str {
+			if r > 0xff {
+				return nil, invalid
+			}
+			b = append(b, byte(r))
+		}
+		return b, nil
+	case Array:
+		items, ok := x.([]interface{})
+		if !ok {
+			return nil, invalid
+		}
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := defaultValue(s.Items, item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	case Map:
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			return nil, invalid
+		}
+		values := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			v, err := defaultValue(s.Values, item)
+			if err != nil {
+				return nil, err
+			}
+			values[k] = v
+		}
+		return values, nil
+	case Record:
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			return nil, invalid
+		}
+		values := make(map[string]interface{}, len(s.Fields))
+		for _, f := range s.Fields {
+			item, ok := m[f.Name]
+			if !ok {
+				if !f.HasDefault {
+					return nil, fmt.Errorf("missing field %s in default of %s", f.Name, s.Name)
+				}
+				item = f.Default
+			}
+			v, err := defaultValue(f.Type, item)
+			if err != nil {
+				return nil, err
+			}
+			values[f.Name] = v
+		}
+		return values, nil
+	}
+	// The default of a union is of its first branch.
+	v, err := defaultValue(s.Branches[0], x)
+	if err != nil {
+		return nil, err
+	}
+	return unionValue{index: 0, value: v}, nil
+}
+
+// unionValue is a value of a union with the branch chosen.
+type unionValue struct {
+	index int
+	value interface{}
+}
+
+func encodeDefault(s *Schema, x interface{}) ([]byte, error) {
+	v, err := defaultValue(s, x)
+	if err != nil {
+		return nil, err
+	}
+	e := &encoder{}
+	if err := e.value(s, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
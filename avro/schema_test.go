@@ -0,0 +1,133 @@
+package avro
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const _userSchema = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "com.example",
+	"aliases": ["Person"],
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "name", "type": "string"},
+		{"name": "email", "type": ["null", "string"], "default": null},
+		{"name": "role", "type": {"type": "enum", "name": "Role", "symbols": ["ADMIN", "USER"], "default": "USER"}, "default": "USER"},
+		{"name": "tags", "type": {"type": "array", "items": "string"}, "default": []},
+		{"name": "scores", "type": {"type": "map", "values": "double"}, "default": {}},
+		{"name": "hash", "type": {"type": "fixed", "name": "MD5", "size": 16}, "default": "\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000"},
+		{"name": "friends", "type": {"type": "array", "items": "User"}, "default": []}
+	]
+}`
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema(_userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Type != Record || s.Name != "com.example.User" || !reflect.DeepEqual(s.Aliases, []string{"com.example.Person"}) {
+		t.Errorf("got %s %s %v", s.Type, s.Name, s.Aliases)
+	}
+	if len(s.Fields) != 8 {
+		t.Fatalf("got %d fields", len(s.Fields))
+	}
+	role := s.Fields[3].Type
+	if role.Name != "com.example.Role" || !role.HasEnumDefault || role.EnumDefault != "USER" {
+		t.Errorf("got enum %+v", role)
+	}
+	if hash := s.Fields[6].Type; hash.Name != "com.example.MD5" || hash.Size != 16 {
+		t.Errorf("got fixed %+v", hash)
+	}
+	if s.Fields[7].Type.Items != s {
+		t.Error("recursive reference is not resolved")
+	}
+	if email := s.Fields[2]; !email.HasDefault || email.Default != nil || string(email.defaultData) != "\x00" {
+		t.Errorf("got default %v % x", email.Default, email.defaultData)
+	}
+	if role := s.Fields[3]; string(role.defaultData) != "\x02" {
+		t.Errorf("got default % x", role.defaultData)
+	}
+}
+
+func TestParseSchema_LogicalTypes(t *testing.T) {
+	tests := []struct {
+		schema  string
+		logical string
+	}{
+		{`{"type": "int", "logicalType": "date"}`, LogicalDate},
+		{`{"type": "int", "logicalType": "time-millis"}`, LogicalTimeMillis},
+		{`{"type": "long", "logicalType": "time-micros"}`, LogicalTimeMicros},
+		{`{"type": "long", "logicalType": "timestamp-millis"}`, LogicalTimestampMillis},
+		{`{"type": "long", "logicalType": "local-timestamp-micros"}`, LogicalLocalTimestampMicros},
+		{`{"type": "string", "logicalType": "uuid"}`, LogicalUUID},
+		{`{"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}`, LogicalDecimal},
+		{`{"type": "fixed", "name": "d", "size": 2, "logicalType": "decimal", "precision": 4}`, LogicalDecimal},
+		// Invalid logical types are ignored.
+		{`{"type": "long", "logicalType": "date"}`, ""},
+		{`{"type": "string", "logicalType": "unknown"}`, ""},
+		{`{"type": "bytes", "logicalType": "decimal", "precision": 2, "scale": 3}`, ""},
+		{`{"type": "fixed", "name": "d", "size": 2, "logicalType": "decimal", "precision": 5}`, ""},
+	}
+	for _, test := range tests {
+		s, err := ParseSchema(test.schema)
+		if err != nil {
+			t.Errorf("%s: %v", test.schema, err)
+			continue
+		}
+		if s.LogicalType != test.logical {
+			t.Errorf("%s: got logical type %q, want %q", test.schema, s.LogicalType, test.logical)
+		}
+	}
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	tests := []string{
+		``,
+		`"unknown"`,
+		`{"type": "record", "fields": []}`,
+		`{"type": "record", "name": "1a", "fields": []}`,
+		`{"type": "record", "name": "a", "fields": [{"name": "x"}]}`,
+		`{"type": "record", "name": "a", "fields": [{"name": "x", "type": "int"}, {"name": "x", "type": "int"}]}`,
+		`{"type": "record", "name": "a", "fields": [{"name": "x", "type": "int", "default": "1"}]}`,
+		`{"type": "record", "name": "a", "fields": [{"name": "x", "type": ["null", "int"], "default": 1}]}`,
+		`{"type": "enum", "name": "e", "symbols": ["A", "A"]}`,
+		`{"type": "enum", "name": "e", "symbols": ["A"], "default": "B"}`,
+		`{"type": "fixed", "name": "f"}`,
+		`["int", "int"]`,
+		`["int", ["long"]]`,
+		`[{"type": "fixed", "name": "f", "size": 1}, {"type": "fixed", "name": "f", "size": 2}]`,
+		`"int" "int"`,
+	}
+	for _, test := range tests {
+		if _, err := ParseSchema(test); err == nil {
+			t.Errorf("%s: expect error", test)
+		} else if !strings.HasPrefix(err.Error(), "avro: ") {
+			t.Errorf("%s: got error %v without prefix", test, err)
+		}
+	}
+}
+
+func TestSchema_String(t *testing.T) {
+	s := MustParseSchema(_userSchema)
+	text := s.String()
+	if !json.Valid([]byte(text)) {
+		t.Fatalf("invalid JSON %s", text)
+	}
+	if !strings.Contains(text, `"items":"com.example.User"`) {
+		t.Errorf("recursive reference is not by name: %s", text)
+	}
+	again, err := ParseSchema(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != text {
+		t.Errorf("got %s, want %s", again.String(), text)
+	}
+	if got := MustParseSchema(`{"type": "int"}`).String(); got != `"int"` {
+		t.Errorf("got %s", got)
+	}
+}
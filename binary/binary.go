@@ -0,0 +1,85 @@
+// Package binary defines and registers Marshaler/Unmarshaler handling binary
+// frames of fixed layouts, based on encoding/binary, as used by device
+// protocols.
+//
+// Values are encoded in order without any framing: booleans as one byte,
+// fixed-size integers and floats in the byte order of the codec,
+// binary.BigEndian by default, arrays element by element and structs field by
+// field. Types without fixed size, such as int, are not supported. Fields are
+// configured by the "bin" tag, a comma separated list of:
+//
+//   - le, be: the byte order of the field, including its elements and nested
+//     fields without their own.
+//   - len=u8|u16|u32|u64: a string or a slice is prefixed with its length, in
+//     bytes or elements, as an unsigned integer of the size.
+//   - size=n: a string or a slice has n bytes or elements. Shorter ones are
+//     padded with zeros, and trailing zeros of strings and byte slices are
+//     removed when decoding.
+//   - bits=n: the field is a bitfield of n bits. Consecutive bitfields, which
+//     must take a multiple of 8 bits, are packed into bytes from the most
+//     significant bit of the first byte.
+//
+// Strings and slices other than the top-level value must have len or size;
+// a top-level one takes all data. Blank (_) fields are padding, which is
+// written as zeros and skipped when decoding. Errors of fields, including
+// short data, are reported as *FieldError with the path of the field.
+package binary
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "binary"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	e := &encoder{order: config.ByteOrder}
+	if e.order == nil {
+		e.order = binary.BigEndian
+	}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("binary: can not unmarshal to non-pointer or nil %T", v)
+	}
+	d := &decoder{order: config.ByteOrder, data: data}
+	if d.order == nil {
+		d.order = binary.BigEndian
+	}
+	return d.decode(rv.Elem())
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
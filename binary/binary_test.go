@@ -0,0 +1,42 @@
+package binary
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+type frame struct {
+	Type    uint8
+	Seq     uint32
+	Payload []byte `bin:"len=u16"`
+	CRC     uint16 `bin:"le"`
+}
+
+func TestCodec(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := frame{Type: 1, Seq: 2, Payload: []byte("hi"), CRC: 0xABCD}
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{1, 0, 0, 0, 2, 0, 2, 'h', 'i', 0xCD, 0xAB}; !bytes.Equal(data, want) {
+		t.Errorf("got % X, want % X", data, want)
+	}
+	var out frame
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	if err := u.Unmarshal(context.Background(), data, out); err == nil {
+		t.Error("want error of non-pointer")
+	}
+	if _, err := m.Marshal(context.Background(), map[string]int8{}); err == nil {
+		t.Error("want error of unsupported type")
+	}
+}
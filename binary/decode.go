@@ -0,0 +1,274 @@
+package binary
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+type decoder struct {
+	order binary.ByteOrder
+	data  []byte
+	off   int
+}
+
+func (d *decoder) decode(v reflect.Value) error {
+	top := options{size: -1, rest: true}
+	if err := check(v.Type(), top, map[reflect.Type]*plan{}); err != nil {
+		return fmt.Errorf("binary: %w", err)
+	}
+	if err := d.value(v, top, d.order, nil); err != nil {
+		return err
+	}
+	if n := len(d.data) - d.off; n > 0 {
+		return fmt.Errorf("binary: %d bytes of unexpected data after value", n)
+	}
+	return nil
+}
+
+// read returns the next n bytes.
+func (d *decoder) read(n int, path []string) ([]byte, error) {
+	if n > len(d.data)-d.off {
+		return nil, fieldError(path, io.ErrUnexpectedEOF)
+	}
+	b := d.data[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+func (d *decoder) uint(size int, order binary.ByteOrder, path []string) (uint64, error) {
+	b, err := d.read(size, path)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(order.Uint16(b)), nil
+	case 4:
+		return uint64(order.Uint32(b)), nil
+	}
+	return order.Uint64(b), nil
+}
+
+func (d *decoder) value(v reflect.Value, opts options, order binary.ByteOrder, path []string) error {
+	if opts.order != nil {
+		order = opts.order
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.value(v.Elem(), opts, order, path)
+	case reflect.Bool:
+		b, err := d.read(1, path)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b[0] != 0)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := d.uint(int(v.Type().Size()), order, path)
+		if err != nil {
+			return err
+		}
+		bits := 64 - uint(v.Type().Bits())
+		v.SetInt(int64(x<<bits) >> bits)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := d.uint(int(v.Type().Size()), order, path)
+		if err != nil {
+			return err
+		}
+		v.SetUint(x)
+	case reflect.Float32:
+		x, err := d.uint(4, order, path)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(float64(math.Float32frombits(uint32(x))))
+	case reflect.Float64:
+		x, err := d.uint(8, order, path)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(math.Float64frombits(x))
+	case reflect.String:
+		b, err := d.bytes(opts, order, path)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.bytes(opts, order, path)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append(make([]byte, 0, len(b)), b...))
+			return nil
+		}
+		return d.slice(v, opts, order, path)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := d.value(v.Index(i), options{size: -1}, order, appendIndex(path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return d.structure(v, order, path)
+	default:
+		return fieldError(path, fmt.Errorf("unsupported type %s", v.Type()))
+	}
+	return nil
+}
+
+// length reads the length prefix, or returns the fixed size. It returns -1
+// for the rest of data.
+func (d *decoder) length(opts options, order binary.ByteOrder, path []string) (int, error) {
+	switch {
+	case opts.size >= 0:
+		return opts.size, nil
+	case opts.prefix == 0:
+		return -1, nil
+	}
+	n, err := d.uint(opts.prefix, order, path)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)-d.off) {
+		return 0, fieldError(path, io.ErrUnexpectedEOF)
+	}
+	return int(n), nil
+}
+
+// bytes reads the bytes of a string or a byte slice. Trailing zeros of a
+// fixed size are padding.
+func (d *decoder) bytes(opts options, order binary.ByteOrder, path []string) ([]byte, error) {
+	n, err := d.length(opts, order, path)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		n = len(d.data) - d.off
+	}
+	b, err := d.read(n, path)
+	if err != nil {
+		return nil, err
+	}
+	if opts.size >= 0 {
+		for len(b) > 0 && b[len(b)-1] == 0 {
+			b = b[:len(b)-1]
+		}
+	}
+	return b, nil
+}
+
+func (d *decoder) slice(v reflect.Value, opts options, order binary.ByteOrder, path []string) error {
+	n, err := d.length(opts, order, path)
+	if err != nil {
+		return err
+	}
+	elemOpts := options{size: -1}
+	if n > 0 && v.Type().Elem().Size() == 0 {
+		// elements of zero size take no data and are all the same, so they
+		// are decoded once rather than as many times as the count, which
+		// could keep decoding busy for a few bytes of data.
+		if err := d.value(reflect.New(v.Type().Elem()).Elem(), elemOpts, order, appendIndex(path, 0)); err != nil {
+			return err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		return nil
+	}
+	if n >= 0 {
+		// the slice grows as elements are decoded rather than being allocated
+		// by the count, which is bounded only by the size of the rest of the
+		// data, not by the elements it can hold.
+		slice := reflect.MakeSlice(v.Type(), 0, 0)
+		for i := 0; i < n; i++ {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem, elemOpts, order, appendIndex(path, i)); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		v.Set(slice)
+		return nil
+	}
+	slice := reflect.MakeSlice(v.Type(), 0, 0)
+	for i := 0; d.off < len(d.data); i++ {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		off := d.off
+		if err := d.value(elem, elemOpts, order, appendIndex(path, i)); err != nil {
+			return err
+		}
+		if d.off == off {
+			return fieldError(path, fmt.Errorf("elements of %s take no data", v.Type()))
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	v.Set(slice)
+	return nil
+}
+
+func (d *decoder) structure(v reflect.Value, order binary.ByteOrder, path []string) error {
+	p, err := planOf(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, it := range p.items {
+		if it.group != nil {
+			if err := d.group(v, it, path); err != nil {
+				return err
+			}
+			continue
+		}
+		f := it.field
+		var fv reflect.Value
+		if f.blank {
+			fv = reflect.New(f.typ).Elem()
+		} else {
+			fv = v.Field(f.index)
+		}
+		if err := d.value(fv, f.opts, order, appendName(path, f.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// group unpacks bitfields from the most significant bit of the first byte.
+func (d *decoder) group(v reflect.Value, it item, path []string) error {
+	b, err := d.read(it.bytes, appendName(path, it.group[0].name))
+	if err != nil {
+		return err
+	}
+	var acc uint64
+	for _, c := range b {
+		acc = acc<<8 | uint64(c)
+	}
+	left := uint(8 * it.bytes)
+	for _, f := range it.group {
+		bits := uint(f.opts.bits)
+		left -= bits
+		x := acc >> left
+		if bits < 64 {
+			x &= 1<<bits - 1
+		}
+		if f.blank {
+			continue
+		}
+		fv := v.Field(f.index)
+		switch fv.Kind() {
+		case reflect.Bool:
+			fv.SetBool(x != 0)
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fv.SetInt(int64(x<<(64-bits)) >> (64 - bits))
+		default:
+			fv.SetUint(x)
+		}
+	}
+	return nil
+}
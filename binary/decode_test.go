@@ -0,0 +1,132 @@
+package binary
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func unmarshal(order binary.ByteOrder, data []byte, v interface{}) error {
+	d := &decoder{order: order, data: data}
+	return d.decode(reflect.ValueOf(v).Elem())
+}
+
+func TestDecoder_RoundTrip(t *testing.T) {
+	ptr := int8(-1)
+	values := []interface{}{
+		&header{
+			Magic:   [2]byte{'K', 'T'},
+			Length:  0x0102,
+			Flags:   flags{Version: 15, IHL: 0, More: true, Offset: -2048},
+			Ratio:   -1.5,
+			Signed:  -2,
+			Enabled: true,
+			Name:    "ab",
+			Code:    "xy",
+			Items:   []item8{{ID: 1, Tags: []int8{-1, 127}}, {ID: 2, Tags: []int8{}}},
+			Payload: []byte{9},
+			Ptr:     &ptr,
+		},
+		&tree{Value: 1, Children: []tree{{Value: 2, Children: []tree{}}, {Value: 3, Children: []tree{}}}},
+		&[]int16{1, -1},
+		&struct {
+			A uint64 `bin:"bits=64"`
+			X int8
+			B int64 `bin:"bits=63"`
+			C bool  `bin:"bits=1"`
+		}{A: 1<<64 - 1, X: -3, B: -1 << 62, C: true},
+	}
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		for _, v := range values {
+			data, err := marshal(order, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := reflect.New(reflect.TypeOf(v).Elem())
+			if err := unmarshal(order, data, got.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Interface(), v) {
+				t.Errorf("%s: got %+v, want %+v", order, got.Elem(), reflect.ValueOf(v).Elem())
+			}
+		}
+	}
+}
+
+func TestDecoder_Padding(t *testing.T) {
+	var v struct {
+		A string `bin:"size=4"`
+		_ [2]byte
+		B []byte `bin:"size=3"`
+	}
+	if err := unmarshal(binary.BigEndian, []byte{'a', 0, 'b', 0, 0xFF, 0xFF, 0, 1, 0}, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != "a\x00b" || string(v.B) != "\x00\x01" {
+		t.Errorf("got %q, %q", v.A, v.B)
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		v    interface{}
+		want string
+	}{
+		{name: "short", data: []byte{'K', 'T', 0}, v: &header{}, want: "binary: field Length: unexpected EOF"},
+		{name: "short bits", data: []byte{'K', 'T', 0, 0, 0x45}, v: &header{}, want: "binary: field Flags.Version: unexpected EOF"},
+		{name: "short nested", data: []byte{0, 0, 0, 1, 2, 0, 0, 0, 2, 0}, v: &tree{}, want: "binary: field Children[1].Value: unexpected EOF"},
+		{name: "length", data: []byte{0, 0, 0, 1, 200}, v: &tree{}, want: "binary: field Children: unexpected EOF"},
+		{name: "top-level", data: []byte{1}, v: new(uint16), want: "binary: unexpected EOF"},
+		{name: "trailing", data: []byte{1, 2, 3}, v: new(uint16), want: "binary: 1 bytes of unexpected data after value"},
+		{name: "rest", data: []byte{0, 1, 2}, v: &[]int16{}, want: "binary: field [1]: unexpected EOF"},
+		{name: "empty elements", data: []byte{0}, v: &[]struct{}{}, want: "binary: elements of []struct {} take no data"},
+		{name: "unsupported", data: nil, v: new(int), want: "binary: unsupported type int, which has no fixed size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unmarshal(binary.BigEndian, tt.data, tt.v)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+		})
+	}
+	err := unmarshal(binary.BigEndian, []byte{0}, &header{})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Magic[1]" || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %#v", err)
+	}
+}
+
+func TestDecoder_ZeroSizeElements(t *testing.T) {
+	var v struct {
+		Items []struct{} `bin:"len=u32"`
+		Fixed []struct{} `bin:"size=4000000000"`
+		_     [1 << 20]byte
+	}
+	data := make([]byte, 4+1<<20)
+	binary.BigEndian.PutUint32(data, 1<<20)
+	if err := unmarshal(binary.BigEndian, data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Items) != 1<<20 || len(v.Fixed) != 4000000000 {
+		t.Errorf("got %d and %d items", len(v.Items), len(v.Fixed))
+	}
+}
+
+func TestDecoder_LargeCount(t *testing.T) {
+	// the count allows 1<<20 elements of 64 KiB, while the data holds 16.
+	var v struct {
+		Items [][1 << 16]byte `bin:"len=u32"`
+	}
+	data := make([]byte, 4+1<<20)
+	binary.BigEndian.PutUint32(data, 1<<20)
+	err := unmarshal(binary.BigEndian, data, &v)
+	var fe *FieldError
+	if !errors.As(err, &fe) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v", err)
+	}
+}
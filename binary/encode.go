@@ -0,0 +1,224 @@
+package binary
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// FieldError is an error of encoding or decoding a field.
+type FieldError struct {
+	// Path is the path of the field, e.g. "Header.Items[2].Name". It is empty
+	// for the top-level value.
+	Path string
+	// Err is the underlying error, io.ErrUnexpectedEOF for a short buffer.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("binary: %v", e.Err)
+	}
+	return fmt.Sprintf("binary: field %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldError(path []string, err error) error {
+	return &FieldError{Path: strings.TrimPrefix(strings.Join(path, ""), "."), Err: err}
+}
+
+func appendName(path []string, name string) []string {
+	return append(path[:len(path):len(path)], "."+name)
+}
+
+func appendIndex(path []string, i int) []string {
+	return append(path[:len(path):len(path)], fmt.Sprintf("[%d]", i))
+}
+
+// putUint appends the low size bytes of x in order.
+func putUint(b []byte, x uint64, size int, order binary.ByteOrder) []byte {
+	var buf [8]byte
+	switch size {
+	case 1:
+		buf[0] = byte(x)
+	case 2:
+		order.PutUint16(buf[:], uint16(x))
+	case 4:
+		order.PutUint32(buf[:], uint32(x))
+	default:
+		order.PutUint64(buf[:], x)
+	}
+	return append(b, buf[:size]...)
+}
+
+type encoder struct {
+	order binary.ByteOrder
+	buf   []byte
+}
+
+func (e *encoder) encode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return fmt.Errorf("binary: unsupported nil value")
+	}
+	top := options{size: -1, rest: true}
+	if err := check(rv.Type(), top, map[reflect.Type]*plan{}); err != nil {
+		return fmt.Errorf("binary: %w", err)
+	}
+	return e.value(rv, top, e.order, nil)
+}
+
+func (e *encoder) value(v reflect.Value, opts options, order binary.ByteOrder, path []string) error {
+	if opts.order != nil {
+		order = opts.order
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return fieldError(path, fmt.Errorf("nil %s", v.Type()))
+		}
+		return e.value(v.Elem(), opts, order, path)
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf = putUint(e.buf, uint64(v.Int()), int(v.Type().Size()), order)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.buf = putUint(e.buf, v.Uint(), int(v.Type().Size()), order)
+	case reflect.Float32:
+		e.buf = putUint(e.buf, uint64(math.Float32bits(float32(v.Float()))), 4, order)
+	case reflect.Float64:
+		e.buf = putUint(e.buf, math.Float64bits(v.Float()), 8, order)
+	case reflect.String:
+		return e.bytes([]byte(v.String()), opts, order, path)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return e.bytes(v.Bytes(), opts, order, path)
+		}
+		n := v.Len()
+		if err := e.length(n, opts, order, path); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := e.value(v.Index(i), options{size: -1}, order, appendIndex(path, i)); err != nil {
+				return err
+			}
+		}
+		for i := n; i < opts.size; i++ {
+			if err := e.value(reflect.Zero(v.Type().Elem()), options{size: -1}, order, appendIndex(path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := e.value(v.Index(i), options{size: -1}, order, appendIndex(path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return e.structure(v, order, path)
+	default:
+		return fieldError(path, fmt.Errorf("unsupported type %s", v.Type()))
+	}
+	return nil
+}
+
+// length writes the length prefix of n elements, or checks n against the
+// fixed size. Nothing is written for the rest of data.
+func (e *encoder) length(n int, opts options, order binary.ByteOrder, path []string) error {
+	if opts.size >= 0 {
+		if n > opts.size {
+			return fieldError(path, fmt.Errorf("length %d overflows size %d", n, opts.size))
+		}
+		return nil
+	}
+	if opts.prefix == 0 {
+		return nil
+	}
+	if opts.prefix < 8 && uint64(n) >= 1<<(8*uint(opts.prefix)) {
+		return fieldError(path, fmt.Errorf("length %d overflows u%d", n, 8*opts.prefix))
+	}
+	e.buf = putUint(e.buf, uint64(n), opts.prefix, order)
+	return nil
+}
+
+func (e *encoder) bytes(b []byte, opts options, order binary.ByteOrder, path []string) error {
+	if err := e.length(len(b), opts, order, path); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, b...)
+	for i := len(b); i < opts.size; i++ {
+		e.buf = append(e.buf, 0)
+	}
+	return nil
+}
+
+func (e *encoder) structure(v reflect.Value, order binary.ByteOrder, path []string) error {
+	p, err := planOf(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, it := range p.items {
+		if it.group != nil {
+			if err := e.group(v, it, path); err != nil {
+				return err
+			}
+			continue
+		}
+		f := it.field
+		fv := v.Field(f.index)
+		if f.blank {
+			fv = reflect.Zero(f.typ)
+		}
+		if err := e.value(fv, f.opts, order, appendName(path, f.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// group packs bitfields from the most significant bit of the first byte.
+func (e *encoder) group(v reflect.Value, it item, path []string) error {
+	var acc uint64
+	for _, f := range it.group {
+		var x uint64
+		if !f.blank {
+			fv := v.Field(f.index)
+			switch fv.Kind() {
+			case reflect.Bool:
+				if fv.Bool() {
+					x = 1
+				}
+			case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				n := fv.Int()
+				if f.opts.bits < 64 && (n < -1<<(uint(f.opts.bits)-1) || n >= 1<<(uint(f.opts.bits)-1)) {
+					return fieldError(appendName(path, f.name), fmt.Errorf("%d overflows %d bits", n, f.opts.bits))
+				}
+				x = uint64(n)
+			default:
+				x = fv.Uint()
+				if f.opts.bits < 64 && x >= 1<<uint(f.opts.bits) {
+					return fieldError(appendName(path, f.name), fmt.Errorf("%d overflows %d bits", x, f.opts.bits))
+				}
+			}
+		}
+		if f.opts.bits < 64 {
+			acc = acc<<uint(f.opts.bits) | x&(1<<uint(f.opts.bits)-1)
+		} else {
+			acc = x
+		}
+	}
+	for i := it.bytes - 1; i >= 0; i-- {
+		e.buf = append(e.buf, byte(acc>>(8*uint(i))))
+	}
+	return nil
+}
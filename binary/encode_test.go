@@ -0,0 +1,137 @@
+package binary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+type header struct {
+	Magic   [2]byte
+	Length  uint16
+	Flags   flags
+	Ratio   float32 `bin:"le"`
+	Signed  int16
+	Enabled bool
+	_       [2]byte
+	Name    string  `bin:"len=u8"`
+	Code    string  `bin:"size=4"`
+	Items   []item8 `bin:"len=u16,le"`
+	Payload []byte  `bin:"size=3"`
+	Ptr     *int8
+}
+
+type item8 struct {
+	ID   uint16
+	Tags []int8 `bin:"len=u8"`
+}
+
+func marshal(order binary.ByteOrder, v interface{}) ([]byte, error) {
+	e := &encoder{order: order}
+	err := e.encode(v)
+	return e.buf, err
+}
+
+func TestEncoder(t *testing.T) {
+	ptr := int8(-1)
+	h := header{
+		Magic:   [2]byte{'K', 'T'},
+		Length:  0x0102,
+		Flags:   flags{Version: 4, IHL: 5, More: true, Offset: -2},
+		Ratio:   1,
+		Signed:  -2,
+		Enabled: true,
+		Name:    "ab",
+		Code:    "xy",
+		Items:   []item8{{ID: 1, Tags: []int8{-1}}, {ID: 2}},
+		Payload: []byte{9},
+		Ptr:     &ptr,
+	}
+	want := []byte{
+		'K', 'T',
+		0x01, 0x02,
+		0x45, 0x1F, 0xFE,
+		0x00, 0x00, 0x80, 0x3F,
+		0xFF, 0xFE,
+		0x01,
+		0x00, 0x00,
+		0x02, 'a', 'b',
+		'x', 'y', 0x00, 0x00,
+		0x02, 0x00,
+		0x01, 0x00, 0x01, 0xFF,
+		0x02, 0x00, 0x00,
+		0x09, 0x00, 0x00,
+		0xFF,
+	}
+	got, err := marshal(binary.BigEndian, &h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got  % X\nwant % X", got, want)
+	}
+}
+
+func TestEncoder_Values(t *testing.T) {
+	tests := []struct {
+		name  string
+		order binary.ByteOrder
+		v     interface{}
+		want  []byte
+	}{
+		{name: "little endian", order: binary.LittleEndian, v: uint32(0x01020304), want: []byte{4, 3, 2, 1}},
+		{name: "float64", order: binary.BigEndian, v: math.Inf(1), want: []byte{0x7F, 0xF0, 0, 0, 0, 0, 0, 0}},
+		{name: "top-level string", order: binary.BigEndian, v: "abc", want: []byte("abc")},
+		{name: "top-level slice", order: binary.BigEndian, v: []int16{1, -1}, want: []byte{0, 1, 0xFF, 0xFF}},
+		{name: "recursive", order: binary.BigEndian, v: tree{Value: 1, Children: []tree{{Value: 2}}},
+			want: []byte{0, 0, 0, 1, 1, 0, 0, 0, 2, 0}},
+		{name: "bits of 64", order: binary.BigEndian, v: struct {
+			A uint64 `bin:"bits=64"`
+		}{A: 0x0102030405060708}, want: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		{name: "padded slice", order: binary.BigEndian, v: struct {
+			A []int16 `bin:"size=2"`
+		}{A: []int16{1}}, want: []byte{0, 1, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshal(tt.order, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got % X, want % X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "nil", v: nil, want: "binary: unsupported nil value"},
+		{name: "unsupported", v: 1, want: "binary: unsupported type int, which has no fixed size"},
+		{name: "nil pointer", v: header{Items: []item8{{}}}, want: "binary: field Ptr: nil *int8"},
+		{name: "length", v: struct {
+			A []item8 `bin:"len=u8"`
+		}{A: make([]item8, 256)}, want: "binary: field A: length 256 overflows u8"},
+		{name: "size", v: struct {
+			A string `bin:"size=1"`
+		}{A: "ab"}, want: "binary: field A: length 2 overflows size 1"},
+		{name: "unsigned bits", v: flags{Version: 16}, want: "binary: field Version: 16 overflows 4 bits"},
+		{name: "signed bits", v: flags{Offset: 2048}, want: "binary: field Offset: 2048 overflows 12 bits"},
+		{name: "nested path", v: []tree{{}, {Children: []tree{{Children: make([]tree, 256)}}}},
+			want: "binary: field [1].Children[0].Children: length 256 overflows u8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := marshal(binary.BigEndian, tt.v)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package binary
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, EncoderByteOrder(binary.LittleEndian))
+	got, err := m.Marshal(context.Background(), frame{Seq: 2, CRC: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0, 2, 0, 0, 0, 0, 0, 1, 0}; !bytes.Equal(got, want) {
+		t.Errorf("got % X, want % X", got, want)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	u := WithDecoderOption(&codec{}, DecoderByteOrder(binary.LittleEndian))
+	var v frame
+	if err := u.Unmarshal(context.Background(), []byte{0, 2, 0, 0, 0, 0, 0, 1, 0}, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Seq != 2 || v.CRC != 1 {
+		t.Errorf("got %+v", v)
+	}
+}
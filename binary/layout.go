@@ -0,0 +1,224 @@
+package binary
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// options are the options of a field, from the "bin" tag.
+type options struct {
+	// order is the byte order of the field, nil for the one of the codec.
+	order binary.ByteOrder
+	// prefix is the size in bytes of the length prefix of a string or a
+	// slice, 0 for none.
+	prefix int
+	// size is the fixed number of bytes of a string, or elements of a slice.
+	// It is -1 if not fixed.
+	size int
+	// bits is the number of bits of a bitfield, 0 for none.
+	bits int
+	// rest reports whether a string or a slice without length prefix and
+	// size takes the rest of data, which is the case of the top-level value.
+	rest bool
+}
+
+// field is a field of a struct type.
+type field struct {
+	index int
+	name  string
+	typ   reflect.Type
+	opts  options
+	blank bool
+}
+
+// item is a field, or a group of bitfields packed into bytes.
+type item struct {
+	field *field
+	group []*field
+	// bytes is the size of a group in bytes.
+	bytes int
+}
+
+// plan is the layout of a struct type.
+type plan struct {
+	items []item
+}
+
+var (
+	_plans    sync.Map
+	_building sync.Mutex
+)
+
+// planOf returns the plan of the struct type t.
+func planOf(t reflect.Type) (*plan, error) {
+	if p, ok := _plans.Load(t); ok {
+		return p.(*plan), nil
+	}
+	_building.Lock()
+	defer _building.Unlock()
+	building := map[reflect.Type]*plan{}
+	p, err := build(t, building)
+	if err != nil {
+		return nil, fmt.Errorf("binary: %w", err)
+	}
+	for t, p := range building {
+		_plans.Store(t, p)
+	}
+	return p, nil
+}
+
+// build builds the plan of t, and of struct types it refers to, into
+// building. Recursive types refer to plans being built.
+func build(t reflect.Type, building map[reflect.Type]*plan) (*plan, error) {
+	if p, ok := _plans.Load(t); ok {
+		return p.(*plan), nil
+	}
+	if p, ok := building[t]; ok {
+		return p, nil
+	}
+	p := &plan{}
+	building[t] = p
+	var group []*field
+	bits := 0
+	flush := func() error {
+		if len(group) == 0 {
+			return nil
+		}
+		if bits%8 != 0 {
+			return fmt.Errorf("%s: bitfields %s to %s take %d bits, not a multiple of 8", t, group[0].name, group[len(group)-1].name, bits)
+		}
+		p.items = append(p.items, item{group: group, bytes: bits / 8})
+		group, bits = nil, 0
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("bin")
+		if tag == "-" || sf.PkgPath != "" && sf.Name != "_" {
+			continue
+		}
+		opts, err := parseOptions(sf.Type, tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s: field %s: %w", t, sf.Name, err)
+		}
+		f := &field{index: i, name: sf.Name, typ: sf.Type, opts: opts, blank: sf.Name == "_"}
+		if opts.bits > 0 {
+			if bits += opts.bits; bits > 64 {
+				return nil, fmt.Errorf("%s: bitfields to %s take more than 64 bits", t, sf.Name)
+			}
+			group = append(group, f)
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		if err := check(sf.Type, opts, building); err != nil {
+			return nil, fmt.Errorf("%s: field %s: %w", t, sf.Name, err)
+		}
+		p.items = append(p.items, item{field: f})
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseOptions parses a tag in the form of
+// "[le|be][,len=u8|u16|u32|u64][,size=n][,bits=n]".
+func parseOptions(t reflect.Type, tag string) (options, error) {
+	opts := options{size: -1}
+	if tag == "" {
+		return opts, nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		key, value := strings.TrimSpace(opt), ""
+		if i := strings.IndexByte(key, '='); i >= 0 {
+			key, value = strings.TrimSpace(key[:i]), strings.TrimSpace(key[i+1:])
+		}
+		switch key {
+		case "le":
+			opts.order = binary.LittleEndian
+		case "be":
+			opts.order = binary.BigEndian
+		case "len":
+			switch value {
+			case "u8":
+				opts.prefix = 1
+			case "u16":
+				opts.prefix = 2
+			case "u32":
+				opts.prefix = 4
+			case "u64":
+				opts.prefix = 8
+			default:
+				return opts, fmt.Errorf("invalid length prefix %q", value)
+			}
+		case "size":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return opts, fmt.Errorf("invalid size %q", value)
+			}
+			opts.size = n
+		case "bits":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 64 {
+				return opts, fmt.Errorf("invalid bits %q", value)
+			}
+			switch t.Kind() {
+			case reflect.Bool:
+				if n != 1 {
+					return opts, fmt.Errorf("%d bits of bool", n)
+				}
+			case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				if n > t.Bits() {
+					return opts, fmt.Errorf("%d bits overflow %s", n, t)
+				}
+			default:
+				return opts, fmt.Errorf("bits of type %s", t)
+			}
+			opts.bits = n
+		case "":
+		default:
+			return opts, fmt.Errorf("unknown option %q", opt)
+		}
+	}
+	if opts.prefix > 0 && opts.size >= 0 {
+		return opts, fmt.Errorf("both len and size")
+	}
+	return opts, nil
+}
+
+// check checks that values of type t with options can be encoded.
+func check(t reflect.Type, opts options, building map[reflect.Type]*plan) error {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	case reflect.String, reflect.Slice:
+		if opts.prefix == 0 && opts.size < 0 && !opts.rest {
+			return fmt.Errorf("%s needs len or size", t)
+		}
+		if t.Kind() == reflect.Slice {
+			return check(t.Elem(), options{order: opts.order, size: -1}, building)
+		}
+		return nil
+	case reflect.Array:
+		return check(t.Elem(), options{order: opts.order, size: -1}, building)
+	case reflect.Ptr:
+		return check(t.Elem(), opts, building)
+	case reflect.Struct:
+		_, err := build(t, building)
+		return err
+	default:
+		return fmt.Errorf("unsupported type %s, which has no fixed size", t)
+	}
+	if opts.prefix > 0 || opts.size >= 0 {
+		return fmt.Errorf("len or size of type %s", t)
+	}
+	return nil
+}
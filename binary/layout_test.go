@@ -0,0 +1,114 @@
+package binary
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+type flags struct {
+	Version uint8 `bin:"bits=4"`
+	IHL     uint8 `bin:"bits=4"`
+	_       uint8 `bin:"bits=3"`
+	More    bool  `bin:"bits=1"`
+	Offset  int16 `bin:"bits=12"`
+}
+
+type tree struct {
+	Value    int32
+	Children []tree `bin:"len=u8"`
+}
+
+func TestPlanOf(t *testing.T) {
+	p, err := planOf(reflect.TypeOf(flags{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.items) != 1 || p.items[0].bytes != 3 || len(p.items[0].group) != 5 {
+		t.Errorf("got plan %+v", p)
+	}
+	p, err = planOf(reflect.TypeOf(tree{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.items) != 2 || p.items[1].field.opts.prefix != 1 {
+		t.Errorf("got plan %+v", p)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		tag  string
+		typ  reflect.Type
+		want options
+	}{
+		{tag: "", typ: reflect.TypeOf(""), want: options{size: -1}},
+		{tag: "le,len=u16", typ: reflect.TypeOf(""), want: options{order: binary.LittleEndian, prefix: 2, size: -1}},
+		{tag: "be, size=4", typ: reflect.TypeOf([]byte{}), want: options{order: binary.BigEndian, size: 4}},
+		{tag: "bits=64", typ: reflect.TypeOf(uint64(0)), want: options{size: -1, bits: 64}},
+	}
+	for _, tt := range tests {
+		got, err := parseOptions(tt.typ, tt.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOptions(%q) = %+v, want %+v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestPlanOf_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "int", v: struct{ A int }{}},
+		{name: "map", v: struct{ A map[string]int8 }{}},
+		{name: "string", v: struct{ A string }{}},
+		{name: "slice", v: struct{ A []int8 }{}},
+		{name: "slice element", v: struct {
+			A []int `bin:"len=u8"`
+		}{}},
+		{name: "prefix", v: struct {
+			A string `bin:"len=u3"`
+		}{}},
+		{name: "size", v: struct {
+			A string `bin:"size=-1"`
+		}{}},
+		{name: "len and size", v: struct {
+			A string `bin:"len=u8,size=1"`
+		}{}},
+		{name: "len of scalar", v: struct {
+			A int8 `bin:"len=u8"`
+		}{}},
+		{name: "unknown", v: struct {
+			A int8 `bin:"little"`
+		}{}},
+		{name: "bits", v: struct {
+			A uint8 `bin:"bits=9"`
+		}{}},
+		{name: "bits of bool", v: struct {
+			A bool `bin:"bits=2"`
+		}{}},
+		{name: "bits of float", v: struct {
+			A float32 `bin:"bits=8"`
+		}{}},
+		{name: "partial byte", v: struct {
+			A uint8 `bin:"bits=3"`
+			B uint8
+		}{}},
+		{name: "more than 64 bits", v: struct {
+			A uint64 `bin:"bits=60"`
+			B uint8  `bin:"bits=8"`
+		}{}},
+		{name: "nested", v: struct{ A struct{ B int } }{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := planOf(reflect.TypeOf(tt.v)); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
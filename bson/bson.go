@@ -0,0 +1,67 @@
+// Package bson defines and registers Marshaler/Unmarshaler handling BSON
+// (https://bsonspec.org) content, as stored by document databases such as
+// MongoDB.
+//
+// Values are mapped to BSON the way encoding/json maps them to JSON: structs
+// are encoded as documents keyed by field names, taken from the "bson" tag or,
+// in absence of it, the "json" tag. Besides "omitempty", which also omits
+// values with an IsZero method reporting true, such as zero time.Time and
+// ObjectID, the "inline" option promotes fields of a struct field into the
+// document, or makes a map[string]T field hold the elements matching no other
+// field.
+//
+// The top-level value must be a document: a struct, a map with string keys,
+// or a D, which keeps the order of its elements. Integers of up to 16 bits
+// are encoded as int32, other integers as int64, []byte as generic binary
+// data and time.Time as a datetime in milliseconds. Other BSON types are
+// represented by ObjectID, DateTime, Decimal128, Binary, Regex, Timestamp,
+// MinKey, MaxKey, JavaScript, Symbol, CodeWithScope and DBPointer.
+package bson
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "bson"
+
+var _tags = []string{"bson", "json"}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	e := &encoder{config: config}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	d := &decoder{config: config, data: data}
+	return d.decode(v)
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,84 @@
+package bson
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	if encoding.GetMarshaler(Name) == nil || encoding.GetUnmarshaler(Name) == nil {
+		t.Errorf("codec is not registered as %q", Name)
+	}
+}
+
+type account struct {
+	ID       ObjectID          `bson:"_id,omitempty"`
+	Name     string            `json:"name"`
+	Balance  Decimal128        `bson:"balance"`
+	Created  time.Time         `bson:"created"`
+	Avatar   Binary            `bson:"avatar,omitempty"`
+	Tags     []string          `bson:"tags,omitempty"`
+	Settings map[string]string `bson:"settings,omitempty"`
+	Owner    *account          `bson:"owner,omitempty"`
+}
+
+func TestCodec_Struct(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	balance, err := ParseDecimal128("1024.50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := account{
+		ID:       NewObjectID(),
+		Name:     "张三",
+		Balance:  balance,
+		Created:  time.Date(2021, 6, 1, 8, 30, 0, 123e6, time.UTC),
+		Avatar:   Binary{Subtype: BinaryUUID, Data: make([]byte, 16)},
+		Tags:     []string{"a", "b"},
+		Settings: map[string]string{"lang": "zh"},
+		Owner:    &account{Name: "root", Created: time.Unix(0, 0).UTC()},
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out account
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	var doc map[string]interface{}
+	if err := u.Unmarshal(context.Background(), data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["_id"] != in.ID || doc["name"] != "张三" || doc["balance"] != balance {
+		t.Errorf("got %v", doc)
+	}
+	if _, ok := doc["owner"].(map[string]interface{})["_id"]; ok {
+		t.Error("zero object id is not omitted")
+	}
+}
+
+func TestCodec_Command(t *testing.T) {
+	// Commands take the command name as the first key.
+	cmd := D{{"find", "accounts"}, {"filter", D{{"name", "root"}}}, {"limit", int32(1)}}
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got D
+	if err := c.Unmarshal(context.Background(), data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := D{{"find", "accounts"}, {"filter", map[string]interface{}{"name": "root"}}, {"limit", int32(1)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
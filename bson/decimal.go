@@ -0,0 +1,165 @@
+package bson
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal128 is an IEEE 754-2008 128-bit decimal floating point number in the
+// binary integer decimal (BID) encoding.
+type Decimal128 struct {
+	h, l uint64
+}
+
+const (
+	decimalBias      = 6176
+	decimalMinExp    = -6176
+	decimalMaxExp    = 6111
+	decimalMaxDigits = 34
+)
+
+var (
+	_decimalMaxCoefficient = new(big.Int).Sub(new(big.Int).Exp(big.NewInt(10), big.NewInt(decimalMaxDigits), nil), big.NewInt(1))
+	_mask64                = new(big.Int).SetUint64(^uint64(0))
+	_bigTen                = big.NewInt(10)
+)
+
+// NewDecimal128 returns the Decimal128 of the high and low 64 bits.
+func NewDecimal128(high, low uint64) Decimal128 {
+	return Decimal128{h: high, l: low}
+}
+
+// Bits returns the high and low 64 bits of d.
+func (d Decimal128) Bits() (high, low uint64) {
+	return d.h, d.l
+}
+
+// IsNaN reports whether d is NaN.
+func (d Decimal128) IsNaN() bool {
+	return d.h>>58&0x1f == 0x1f
+}
+
+// IsInf reports whether d is an infinity.
+func (d Decimal128) IsInf() bool {
+	return d.h>>58&0x1f == 0x1e
+}
+
+// parts returns the coefficient and exponent of a finite d. Non-canonical
+// coefficients, which exceed 34 digits, are zero.
+func (d Decimal128) parts() (*big.Int, int) {
+	var exp uint64
+	coef := new(big.Int)
+	if d.h>>61&3 == 3 {
+		// The coefficient is 0b100 followed by 111 bits, which always exceeds
+		// 34 digits.
+		exp = d.h >> 47 & 0x3fff
+	} else {
+		exp = d.h >> 49 & 0x3fff
+		coef.SetUint64(d.h & (1<<49 - 1))
+		coef.Lsh(coef, 64).Or(coef, new(big.Int).SetUint64(d.l))
+		if coef.Cmp(_decimalMaxCoefficient) > 0 {
+			coef.SetUint64(0)
+		}
+	}
+	return coef, int(exp) - decimalBias
+}
+
+// String returns d in the string representation of the BSON spec, which is
+// scientific notation if the exponent is positive or the number is too small.
+func (d Decimal128) String() string {
+	sign := ""
+	if d.h>>63 == 1 {
+		sign = "-"
+	}
+	switch {
+	case d.IsNaN():
+		return "NaN"
+	case d.IsInf():
+		return sign + "Infinity"
+	}
+	coef, exp := d.parts()
+	digits := coef.String()
+	adjusted := exp + len(digits) - 1
+	if exp > 0 || adjusted < -6 {
+		s := digits[:1]
+		if len(digits) > 1 {
+			s += "." + digits[1:]
+		}
+		return fmt.Sprintf("%s%sE%+d", sign, s, adjusted)
+	}
+	if exp == 0 {
+		return sign + digits
+	}
+	if point := len(digits) + exp; point > 0 {
+		return sign + digits[:point] + "." + digits[point:]
+	}
+	return sign + "0." + strings.Repeat("0", -exp-len(digits)) + digits
+}
+
+// ParseDecimal128 parses s as a Decimal128. The number must be representable
+// exactly, rounding is not performed.
+func ParseDecimal128(s string) (Decimal128, error) {
+	invalid := fmt.Errorf("bson: invalid decimal128 %q", s)
+	text := s
+	var sign uint64
+	if text != "" && (text[0] == '+' || text[0] == '-') {
+		if text[0] == '-' {
+			sign = 1
+		}
+		text = text[1:]
+	}
+	switch strings.ToLower(text) {
+	case "inf", "infinity":
+		return Decimal128{h: sign<<63 | 0x1e<<58}, nil
+	case "nan":
+		return Decimal128{h: 0x1f << 58}, nil
+	}
+	mantissa, exp := text, 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(text[i+1:])
+		if err != nil {
+			return Decimal128{}, invalid
+		}
+		mantissa, exp = text[:i], e
+	}
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		exp -= len(mantissa) - i - 1
+		mantissa = mantissa[:i] + mantissa[i+1:]
+	}
+	if mantissa == "" || strings.Trim(mantissa, "0123456789") != "" {
+		return Decimal128{}, invalid
+	}
+	coef, _ := new(big.Int).SetString(mantissa, 10)
+	// Drop trailing zeros while the coefficient is too long or the exponent
+	// is too small, then pad the coefficient while the exponent is too large,
+	// keeping the value.
+	for coef.Cmp(_decimalMaxCoefficient) > 0 || exp < decimalMinExp && coef.Sign() != 0 {
+		q, r := new(big.Int).QuoRem(coef, _bigTen, new(big.Int))
+		if r.Sign() != 0 {
+			return Decimal128{}, fmt.Errorf("bson: decimal128 %q can not be represented exactly", s)
+		}
+		coef, exp = q, exp+1
+	}
+	if coef.Sign() == 0 {
+		if exp < decimalMinExp {
+			exp = decimalMinExp
+		} else if exp > decimalMaxExp {
+			exp = decimalMaxExp
+		}
+	}
+	for exp > decimalMaxExp {
+		c := new(big.Int).Mul(coef, _bigTen)
+		if c.Cmp(_decimalMaxCoefficient) > 0 {
+			break
+		}
+		coef, exp = c, exp-1
+	}
+	if exp < decimalMinExp || exp > decimalMaxExp {
+		return Decimal128{}, fmt.Errorf("bson: decimal128 %q out of range", s)
+	}
+	high := new(big.Int).Rsh(coef, 64).Uint64()
+	low := new(big.Int).And(coef, _mask64).Uint64()
+	return Decimal128{h: sign<<63 | uint64(exp+decimalBias)<<49 | high, l: low}, nil
+}
@@ -0,0 +1,74 @@
+package bson
+
+import (
+	"testing"
+)
+
+func TestDecimal128(t *testing.T) {
+	tests := []struct {
+		in   string
+		h, l uint64
+		out  string
+	}{
+		{in: "0", h: 0x3040000000000000, out: "0"},
+		{in: "-0", h: 0xb040000000000000, out: "-0"},
+		{in: "1", h: 0x3040000000000000, l: 1, out: "1"},
+		{in: "-1", h: 0xb040000000000000, l: 1, out: "-1"},
+		{in: "0.1", h: 0x303e000000000000, l: 1, out: "0.1"},
+		{in: "0.001234", h: 0x3034000000000000, l: 1234, out: "0.001234"},
+		{in: "+12.30", h: 0x303c000000000000, l: 1230, out: "12.30"},
+		{in: "1E+3", h: 0x3046000000000000, l: 1, out: "1E+3"},
+		{in: "0.0000001", h: 0x3032000000000000, l: 1, out: "1E-7"},
+		{in: "1.5e-3", h: 0x3038000000000000, l: 15, out: "0.0015"},
+		{in: "9.999999999999999999999999999999999E+6144", h: 0x5fffed09bead87c0, l: 0x378d8e63ffffffff, out: "9.999999999999999999999999999999999E+6144"},
+		{in: "1E+6144", h: 0x5ffe314dc6448d93, l: 0x38c15b0a00000000, out: "1.000000000000000000000000000000000E+6144"},
+		{in: "1E-6176", h: 0, l: 1, out: "1E-6176"},
+		{in: "0E-7000", h: 0, out: "0E-6176"},
+		{in: "10E-6177", h: 0, l: 1, out: "1E-6176"},
+		{in: "Infinity", h: 0x7800000000000000, out: "Infinity"},
+		{in: "-inf", h: 0xf800000000000000, out: "-Infinity"},
+		{in: "NaN", h: 0x7c00000000000000, out: "NaN"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal128(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal128(%q) error: %v", tt.in, err)
+			continue
+		}
+		if h, l := d.Bits(); h != tt.h || l != tt.l {
+			t.Errorf("ParseDecimal128(%q) = %#x %#x, want %#x %#x", tt.in, h, l, tt.h, tt.l)
+		}
+		if s := d.String(); s != tt.out {
+			t.Errorf("ParseDecimal128(%q).String() = %q, want %q", tt.in, s, tt.out)
+		}
+	}
+}
+
+func TestDecimal128_NonCanonical(t *testing.T) {
+	// Coefficients exceeding 34 digits are zero.
+	tests := []struct {
+		h, l uint64
+		out  string
+	}{
+		{h: 0x6c10000000000000, out: "0"},
+		{h: 0x3041ed09bead87c0, l: 0x378d8e6400000000, out: "0"},
+	}
+	for _, tt := range tests {
+		if s := NewDecimal128(tt.h, tt.l).String(); s != tt.out {
+			t.Errorf("NewDecimal128(%#x, %#x).String() = %q, want %q", tt.h, tt.l, s, tt.out)
+		}
+	}
+}
+
+func TestParseDecimal128Error(t *testing.T) {
+	for _, s := range []string{
+		"", "-", ".", "1.2.3", "1e", "1e+", "e3", "0x10", "1,000", "Infinit",
+		"12345678901234567890123456789012345",
+		"1E-6177",
+		"1E+6145",
+	} {
+		if _, err := ParseDecimal128(s); err == nil {
+			t.Errorf("ParseDecimal128(%q) expect error", s)
+		}
+	}
+}
@@ -0,0 +1,688 @@
+package bson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+// SyntaxError is an error of malformed BSON data.
+type SyntaxError struct {
+	// Offset is the offset in bytes where the error occurred.
+	Offset int
+	msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("bson: %s at offset %d", e.msg, e.Offset)
+}
+
+// UnmarshalTypeError is an error of decoding an element into a value of
+// inappropriate type.
+type UnmarshalTypeError struct {
+	// Value describes the element value, e.g. "string", "objectId".
+	Value string
+	// Type is the type of the target value.
+	Type reflect.Type
+	// Offset is the offset in bytes of the element value.
+	Offset int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("bson: cannot unmarshal %s into Go value of type %s at offset %d", e.Value, e.Type, e.Offset)
+}
+
+// _typeNames are the names of element types, as the $type aliases of MongoDB.
+var _typeNames = map[byte]string{
+	typeDouble:        "double",
+	typeString:        "string",
+	typeDocument:      "object",
+	typeArray:         "array",
+	typeBinary:        "binData",
+	typeUndefined:     "undefined",
+	typeObjectID:      "objectId",
+	typeBool:          "bool",
+	typeDateTime:      "date",
+	typeNull:          "null",
+	typeRegex:         "regex",
+	typeDBPointer:     "dbPointer",
+	typeJavaScript:    "javascript",
+	typeSymbol:        "symbol",
+	typeCodeWithScope: "javascriptWithScope",
+	typeInt32:         "int",
+	typeTimestamp:     "timestamp",
+	typeInt64:         "long",
+	typeDecimal128:    "decimal",
+	typeMinKey:        "minKey",
+	typeMaxKey:        "maxKey",
+}
+
+type decoder struct {
+	config *DecoderConfig
+	data   []byte
+	pos    int
+	depth  int
+}
+
+func (d *decoder) decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("bson: can not unmarshal to non-pointer or nil %T", v)
+	}
+	if err := d.value(typeDocument, rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return d.syntaxError("unexpected data after document")
+	}
+	return nil
+}
+
+func (d *decoder) syntaxError(msg string) error {
+	return &SyntaxError{Offset: d.pos, msg: msg}
+}
+
+func (d *decoder) typeError(t byte, typ reflect.Type) error {
+	return &UnmarshalTypeError{Value: _typeNames[t], Type: typ, Offset: d.pos}
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, d.syntaxError("unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) int32() (int32, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (d *decoder) int64() (int64, error) {
+	b, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
+}
+
+func (d *decoder) cstring() (string, error) {
+	i := bytes.IndexByte(d.data[d.pos:], 0)
+	if i < 0 {
+		return "", d.syntaxError("unterminated cstring")
+	}
+	s := string(d.data[d.pos : d.pos+i])
+	d.pos += i + 1
+	return s, nil
+}
+
+func (d *decoder) string() (string, error) {
+	start := d.pos
+	n, err := d.int32()
+	if err != nil {
+		return "", err
+	}
+	if n < 1 {
+		d.pos = start
+		return "", d.syntaxError("invalid string length")
+	}
+	b, err := d.read(int(n))
+	if err != nil {
+		return "", err
+	}
+	if b[n-1] != 0 {
+		return "", d.syntaxError("unterminated string")
+	}
+	return string(b[:n-1]), nil
+}
+
+func (d *decoder) binary() (Binary, error) {
+	n, err := d.int32()
+	if err != nil {
+		return Binary{}, err
+	}
+	b, err := d.read(1)
+	if err != nil {
+		return Binary{}, err
+	}
+	subtype := b[0]
+	if b, err = d.read(int(n)); err != nil {
+		return Binary{}, err
+	}
+	if subtype == BinaryOld {
+		if len(b) < 4 || int(binary.LittleEndian.Uint32(b)) != len(b)-4 {
+			return Binary{}, d.syntaxError("invalid length of old binary")
+		}
+		b = b[4:]
+	}
+	return Binary{Subtype: subtype, Data: append([]byte{}, b...)}, nil
+}
+
+func (d *decoder) objectID() (ObjectID, error) {
+	var id ObjectID
+	b, err := d.read(len(id))
+	copy(id[:], b)
+	return id, err
+}
+
+func (d *decoder) bool() (bool, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return false, err
+	}
+	if b[0] > 1 {
+		return false, d.syntaxError("invalid boolean")
+	}
+	return b[0] == 1, nil
+}
+
+// elements calls fn for each element of the document at the current
+// position, and fn must consume the value of the element.
+func (d *decoder) elements(fn func(key string, t byte) error) error {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDepth {
+		return d.syntaxError(fmt.Sprintf("exceeded max nesting depth %d", maxDepth))
+	}
+	start := d.pos
+	n, err := d.int32()
+	if err != nil {
+		return err
+	}
+	if n < 5 || int(n) > len(d.data)-start {
+		d.pos = start
+		return d.syntaxError("invalid document length")
+	}
+	end := start + int(n)
+	for {
+		if d.pos >= end {
+			return d.syntaxError("element exceeds document")
+		}
+		t := d.data[d.pos]
+		d.pos++
+		if t == 0 {
+			if d.pos != end {
+				return d.syntaxError("unexpected end of document")
+			}
+			return nil
+		}
+		if _, ok := _typeNames[t]; !ok {
+			d.pos--
+			return d.syntaxError(fmt.Sprintf("invalid element type 0x%02x", t))
+		}
+		key, err := d.cstring()
+		if err != nil {
+			return err
+		}
+		if err := fn(key, t); err != nil {
+			return err
+		}
+	}
+}
+
+// skip skips a value of the element type t.
+func (d *decoder) skip(t byte) error {
+	var err error
+	switch t {
+	case typeDouble, typeDateTime, typeTimestamp, typeInt64:
+		_, err = d.read(8)
+	case typeString, typeJavaScript, typeSymbol:
+		_, err = d.string()
+	case typeDocument, typeArray:
+		err = d.elements(func(_ string, t byte) error { return d.skip(t) })
+	case typeBinary:
+		_, err = d.binary()
+	case typeObjectID:
+		_, err = d.read(12)
+	case typeBool:
+		_, err = d.read(1)
+	case typeRegex:
+		if _, err = d.cstring(); err == nil {
+			_, err = d.cstring()
+		}
+	case typeDBPointer:
+		if _, err = d.string(); err == nil {
+			_, err = d.read(12)
+		}
+	case typeCodeWithScope:
+		_, err = d.codeWithScope()
+	case typeInt32:
+		_, err = d.read(4)
+	case typeDecimal128:
+		_, err = d.read(16)
+	}
+	return err
+}
+
+func (d *decoder) codeWithScope() (CodeWithScope, error) {
+	start := d.pos
+	n, err := d.int32()
+	if err != nil {
+		return CodeWithScope{}, err
+	}
+	code, err := d.string()
+	if err != nil {
+		return CodeWithScope{}, err
+	}
+	scope, err := d.any(typeDocument)
+	if err != nil {
+		return CodeWithScope{}, err
+	}
+	if d.pos-start != int(n) {
+		return CodeWithScope{}, &SyntaxError{Offset: start, msg: "invalid length of code with scope"}
+	}
+	return CodeWithScope{Code: JavaScript(code), Scope: scope}, nil
+}
+
+// number reads a numeric value as int64 or float64.
+func (d *decoder) number(t byte) (int64, float64, bool, error) {
+	switch t {
+	case typeInt32:
+		n, err := d.int32()
+		return int64(n), float64(n), true, err
+	case typeInt64:
+		n, err := d.int64()
+		return n, float64(n), true, err
+	}
+	n, err := d.int64()
+	return 0, math.Float64frombits(uint64(n)), false, err
+}
+
+// value decodes a value of the element type t into v.
+func (d *decoder) value(t byte, v reflect.Value) error {
+	if t == typeNull || t == typeUndefined {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.value(t, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return d.typeError(t, v.Type())
+		}
+		x, err := d.any(t)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+	switch v.Type() {
+	case _timeType, _dateTimeType:
+		if t != typeDateTime {
+			return d.typeError(t, v.Type())
+		}
+		n, err := d.int64()
+		if err != nil {
+			return err
+		}
+		if v.Type() == _timeType {
+			v.Set(reflect.ValueOf(DateTime(n).Time()))
+		} else {
+			v.SetInt(n)
+		}
+		return nil
+	case _binaryType:
+		if t != typeBinary {
+			return d.typeError(t, v.Type())
+		}
+		b, err := d.binary()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(b))
+		return nil
+	case _javaScriptType, _symbolType:
+		if t != typeString && t != typeJavaScript && t != typeSymbol {
+			return d.typeError(t, v.Type())
+		}
+		s, err := d.string()
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+		return nil
+	case _dType:
+		if t != typeDocument {
+			return d.typeError(t, v.Type())
+		}
+		doc, err := d.ordered()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(doc))
+		return nil
+	case _objectIDType, _decimal128Type, _regexType, _timestampType, _minKeyType, _maxKeyType, _codeWithScopeType, _dbPointerType:
+		offset := d.pos
+		x, err := d.any(t)
+		if err != nil {
+			return err
+		}
+		if reflect.TypeOf(x) != v.Type() {
+			return &UnmarshalTypeError{Value: _typeNames[t], Type: v.Type(), Offset: offset}
+		}
+		v.Set(reflect.ValueOf(x))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if t != typeBool {
+			return d.typeError(t, v.Type())
+		}
+		b, err := d.bool()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return d.integer(t, v)
+	case reflect.Float32, reflect.Float64:
+		if t != typeDouble && t != typeInt32 && t != typeInt64 {
+			return d.typeError(t, v.Type())
+		}
+		offset := d.pos
+		_, f, _, err := d.number(t)
+		if err != nil {
+			return err
+		}
+		if v.OverflowFloat(f) && !math.IsInf(f, 0) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %g", f), Type: v.Type(), Offset: offset}
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		if t != typeString && t != typeSymbol && t != typeJavaScript {
+			return d.typeError(t, v.Type())
+		}
+		s, err := d.string()
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		if t == typeBinary && v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.binary()
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b.Data)
+			return nil
+		}
+		if t != typeArray {
+			return d.typeError(t, v.Type())
+		}
+		slice := reflect.MakeSlice(v.Type(), 0, 0)
+		err := d.elements(func(_ string, t byte) error {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(t, elem); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		v.Set(slice)
+	case reflect.Array:
+		if t == typeBinary && v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.binary()
+			if err != nil {
+				return err
+			}
+			reflect.Copy(v, reflect.ValueOf(b.Data))
+			for i := len(b.Data); i < v.Len(); i++ {
+				v.Index(i).SetUint(0)
+			}
+			return nil
+		}
+		if t != typeArray {
+			return d.typeError(t, v.Type())
+		}
+		i := 0
+		err := d.elements(func(_ string, t byte) error {
+			defer func() { i++ }()
+			if i >= v.Len() {
+				return d.skip(t)
+			}
+			return d.value(t, v.Index(i))
+		})
+		if err != nil {
+			return err
+		}
+		for ; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	case reflect.Map:
+		if t != typeDocument || v.Type().Key().Kind() != reflect.String {
+			return d.typeError(t, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		return d.elements(func(key string, t byte) error {
+			return d.entry(v, key, t)
+		})
+	case reflect.Struct:
+		if t != typeDocument {
+			return d.typeError(t, v.Type())
+		}
+		return d.structValue(v)
+	default:
+		return d.typeError(t, v.Type())
+	}
+	return nil
+}
+
+// integer decodes a number into the integer v. Doubles must be integral.
+func (d *decoder) integer(t byte, v reflect.Value) error {
+	if t != typeDouble && t != typeInt32 && t != typeInt64 {
+		return d.typeError(t, v.Type())
+	}
+	offset := d.pos
+	n, f, exact, err := d.number(t)
+	if err != nil {
+		return err
+	}
+	if !exact {
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %g", f), Type: v.Type(), Offset: offset}
+		}
+		n = int64(f)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(n) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %d", n), Type: v.Type(), Offset: offset}
+		}
+		v.SetInt(n)
+	default:
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %d", n), Type: v.Type(), Offset: offset}
+		}
+		v.SetUint(uint64(n))
+	}
+	return nil
+}
+
+// entry decodes an element into the map v.
+func (d *decoder) entry(v reflect.Value, key string, t byte) error {
+	elem := reflect.New(v.Type().Elem()).Elem()
+	if err := d.value(t, elem); err != nil {
+		return err
+	}
+	k := reflect.New(v.Type().Key()).Elem()
+	k.SetString(key)
+	v.SetMapIndex(k, elem)
+	return nil
+}
+
+func (d *decoder) structValue(v reflect.Value) error {
+	fs := fields.Of(v.Type(), _tags...)
+	var inline *fields.Field
+	for i := range fs {
+		if isInlineMap(&fs[i]) {
+			inline = &fs[i]
+		}
+	}
+	return d.elements(func(key string, t byte) error {
+		f := lookupField(fs, key)
+		if f == nil && inline != nil {
+			m, ok := fields.ByIndex(v, inline.Index, true)
+			if !ok {
+				return fmt.Errorf("bson: field %s: can not set embedded pointer to unexported struct", inline.Name)
+			}
+			if m.IsNil() {
+				m.Set(reflect.MakeMap(m.Type()))
+			}
+			return d.entry(m, key, t)
+		}
+		if f == nil {
+			if d.config.DisallowUnknownFields {
+				return fmt.Errorf("bson: unknown field %q at offset %d", key, d.pos)
+			}
+			return d.skip(t)
+		}
+		fv, ok := fields.ByIndex(v, f.Index, true)
+		if !ok {
+			return fmt.Errorf("bson: field %s: can not set embedded pointer to unexported struct", f.Name)
+		}
+		return d.value(t, fv)
+	})
+}
+
+func lookupField(fs []fields.Field, key string) *fields.Field {
+	for i := range fs {
+		if fs[i].Name == key && !isInlineMap(&fs[i]) {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].Name, key) && !isInlineMap(&fs[i]) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+// ordered decodes a document into a D.
+func (d *decoder) ordered() (D, error) {
+	doc := D{}
+	err := d.elements(func(key string, t byte) error {
+		x, err := d.any(t)
+		if err != nil {
+			return err
+		}
+		doc = append(doc, E{Key: key, Value: x})
+		return nil
+	})
+	return doc, err
+}
+
+// any decodes a value of the element type t into a generic value: float64,
+// string, map[string]interface{} or D, []interface{}, []byte for generic
+// binary data, Binary, ObjectID, bool, time.Time, Regex, DBPointer,
+// JavaScript, Symbol, CodeWithScope, int32, Timestamp, int64, Decimal128,
+// MinKey, MaxKey, or nil for null and undefined.
+func (d *decoder) any(t byte) (interface{}, error) {
+	switch t {
+	case typeDouble, typeInt32, typeInt64:
+		n, f, _, err := d.number(t)
+		switch t {
+		case typeDouble:
+			return f, err
+		case typeInt32:
+			return int32(n), err
+		}
+		return n, err
+	case typeString:
+		return d.string()
+	case typeDocument:
+		if d.config.OrderedDocuments {
+			return d.ordered()
+		}
+		m := map[string]interface{}{}
+		err := d.elements(func(key string, t byte) error {
+			x, err := d.any(t)
+			m[key] = x
+			return err
+		})
+		return m, err
+	case typeArray:
+		items := []interface{}{}
+		err := d.elements(func(_ string, t byte) error {
+			x, err := d.any(t)
+			items = append(items, x)
+			return err
+		})
+		return items, err
+	case typeBinary:
+		b, err := d.binary()
+		if b.Subtype == BinaryGeneric {
+			return b.Data, err
+		}
+		return b, err
+	case typeObjectID:
+		return d.objectID()
+	case typeBool:
+		return d.bool()
+	case typeDateTime:
+		n, err := d.int64()
+		return DateTime(n).Time(), err
+	case typeRegex:
+		pattern, err := d.cstring()
+		if err != nil {
+			return nil, err
+		}
+		options, err := d.cstring()
+		return Regex{Pattern: pattern, Options: options}, err
+	case typeDBPointer:
+		ref, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		id, err := d.objectID()
+		return DBPointer{Ref: ref, ID: id}, err
+	case typeJavaScript:
+		s, err := d.string()
+		return JavaScript(s), err
+	case typeSymbol:
+		s, err := d.string()
+		return Symbol(s), err
+	case typeCodeWithScope:
+		return d.codeWithScope()
+	case typeTimestamp:
+		n, err := d.int64()
+		return Timestamp{T: uint32(uint64(n) >> 32), I: uint32(n)}, err
+	case typeDecimal128:
+		l, err := d.int64()
+		if err != nil {
+			return nil, err
+		}
+		h, err := d.int64()
+		return Decimal128{h: uint64(h), l: uint64(l)}, err
+	case typeMinKey:
+		return MinKey{}, nil
+	case typeMaxKey:
+		return MaxKey{}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,216 @@
+package bson
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func decode(data []byte, v interface{}, config *DecoderConfig) error {
+	if config == nil {
+		config = &DecoderConfig{}
+	}
+	return (&decoder{config: config, data: data}).decode(v)
+}
+
+func TestDecode(t *testing.T) {
+	for _, tt := range _vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			var got D
+			if err := decode(mustHex(tt.hex), &got, nil); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.doc) {
+				t.Errorf("got %#v, want %#v", got, tt.doc)
+			}
+		})
+	}
+}
+
+type typed struct {
+	I8     int8           `bson:"i8"`
+	U      uint           `bson:"u"`
+	F32    float32        `bson:"f32"`
+	S      string         `bson:"s"`
+	B      []byte         `bson:"b"`
+	Arr    [2]int         `bson:"arr"`
+	Items  []*int64       `bson:"items"`
+	At     time.Time      `bson:"at"`
+	DT     DateTime       `bson:"dt"`
+	Bin    Binary         `bson:"bin"`
+	ID     ObjectID       `bson:"id"`
+	JS     JavaScript     `bson:"js"`
+	Doc    D              `bson:"doc"`
+	M      map[string]int `bson:"m"`
+	Ptr    *string        `bson:"ptr"`
+	Any    interface{}    `bson:"any"`
+	Nested struct {
+		X int `json:"x"`
+	} `bson:"nested"`
+}
+
+func TestDecode_Typed(t *testing.T) {
+	s := "set"
+	in := D{
+		{"i8", 1.0},
+		{"u", int32(7)},
+		{"f32", int64(2)},
+		{"S", Symbol("sym")},
+		{"b", Binary{Subtype: BinaryOld, Data: []byte{1}}},
+		{"arr", []interface{}{int32(1), int32(2), int32(3)}},
+		{"items", []interface{}{int64(1), nil}},
+		{"at", DateTime(1500)},
+		{"dt", time.Unix(2, 0)},
+		{"bin", []byte{2}},
+		{"id", _id},
+		{"js", "code"},
+		{"doc", D{{"k", "v"}}},
+		{"m", map[string]interface{}{"a": int32(1)}},
+		{"ptr", s},
+		{"any", D{{"z", true}}},
+		{"nested", D{{"x", int32(3)}}},
+		{"unknown", MinKey{}},
+	}
+	data, err := (&codec{}).Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got typed
+	got.Ptr = new(string)
+	if err := decode(data, &got, nil); err != nil {
+		t.Fatal(err)
+	}
+	one := int64(1)
+	want := typed{
+		I8: 1, U: 7, F32: 2, S: "sym", B: []byte{1}, Arr: [2]int{1, 2},
+		Items: []*int64{&one, nil}, At: time.Unix(1, 5e8).UTC(), DT: 2000,
+		Bin: Binary{Subtype: BinaryGeneric, Data: []byte{2}}, ID: _id, JS: "code",
+		Doc: D{{"k", "v"}}, M: map[string]int{"a": 1}, Ptr: &s,
+		Any: map[string]interface{}{"z": true},
+	}
+	want.Nested.X = 3
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecode_Inline(t *testing.T) {
+	data := mustHex("1a000000 10 6500 01000000 10 6900 02000000 0a 7a00 08 7900 01 00")
+	var got withInline
+	if err := decode(data, &got, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.E != 1 || got.Inner.I != 2 || !reflect.DeepEqual(got.Extra, map[string]interface{}{"z": nil, "y": true}) {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestDecode_Ordered(t *testing.T) {
+	data := mustHex("1b000000 03 6100 13000000 10 6200 01000000 10 6100 02000000 00 00")
+	var got interface{}
+	if err := decode(data, &got, &DecoderConfig{OrderedDocuments: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := D{{"a", D{{"b", int32(1)}, {"a", int32(2)}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	var syntax *SyntaxError
+	var typ *UnmarshalTypeError
+	tests := []struct {
+		name   string
+		hex    string
+		v      interface{}
+		target interface{}
+	}{
+		{"empty", "", &D{}, &syntax},
+		{"short length", "04000000 00", &D{}, &syntax},
+		{"length exceeds data", "06000000 00", &D{}, &syntax},
+		{"not terminated", "05000000 01", &D{}, &syntax},
+		{"trailing data", "05000000 00 00", &D{}, &syntax},
+		{"element exceeds document", "08000000 08 6100 01 00", &D{}, &syntax},
+		{"invalid type", "08000000 20 6100 00", &D{}, &syntax},
+		{"unterminated key", "07000000 08 6161", &D{}, &syntax},
+		{"invalid string length", "0c000000 02 6100 00000000 00", &D{}, &syntax},
+		{"unterminated string", "0e000000 02 6100 02000000 6161 00", &D{}, &syntax},
+		{"invalid bool", "09000000 08 6100 02 00", &D{}, &syntax},
+		{"invalid old binary", "0f000000 05 6100 02000000 02 0102 00", &D{}, &syntax},
+		{"invalid code with scope", "17000000 0f 6100 10000000 02000000 7800 05000000 00 00", &D{}, &syntax},
+		{"string into int", "0e000000 02 6100 02000000 7800 00", &struct{ A int }{}, &typ},
+		{"fraction into int", "10000000 01 6100 000000000000f83f 00", &struct{ A int }{}, &typ},
+		{"overflow", "0c000000 10 6100 00010000 00", &struct{ A int8 }{}, &typ},
+		{"negative into uint", "0c000000 10 6100 ffffffff 00", &struct{ A uint }{}, &typ},
+		{"bool into string", "09000000 08 6100 01 00", &struct{ A string }{}, &typ},
+		{"document into slice", "0d000000 03 6100 05000000 00 00", &struct{ A []int }{}, &typ},
+		{"int into object id", "0c000000 10 6100 01000000 00", &struct{ A ObjectID }{}, &typ},
+		{"int into time", "0c000000 10 6100 01000000 00", &struct{ A time.Time }{}, &typ},
+		{"top-level into int", "05000000 00", new(int), &typ},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decode(mustHex(tt.hex), tt.v, nil)
+			if err == nil {
+				t.Fatal("expect error")
+			}
+			if !errors.As(err, tt.target) {
+				t.Errorf("got error %T %v", err, err)
+			}
+		})
+	}
+	if err := decode(mustHex("0c000000 10 6100 01000000 00"), &struct{}{}, &DecoderConfig{DisallowUnknownFields: true}); err == nil {
+		t.Error("expect error of unknown field")
+	}
+	if err := decode(nil, D{}, nil); err == nil {
+		t.Error("expect error of non-pointer")
+	}
+}
+
+func TestDecode_Depth(t *testing.T) {
+	deep := D{}
+	for i := 0; i < maxDepth-1; i++ {
+		deep = D{{"a", deep}}
+	}
+	data, err := (&codec{}).Marshal(context.Background(), deep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v interface{}
+	if err := decode(data, &v, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Wrap the document once more, which exceeds the depth.
+	data = append(append(mustHex("00000000 03 6100"), data...), 0)
+	data[0], data[1] = byte(len(data)), byte(len(data)>>8)
+	if err := decode(data, &v, nil); err == nil {
+		t.Error("expect error of depth")
+	}
+}
+
+func TestDecode_Float(t *testing.T) {
+	var v struct {
+		A float32
+		B float64
+	}
+	data, err := (&codec{}).Marshal(context.Background(), D{{"A", math.Inf(-1)}, {"B", math.MaxInt64}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decode(data, &v, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsInf(float64(v.A), -1) || v.B != math.MaxInt64 {
+		t.Errorf("got %+v", v)
+	}
+	if data, err = (&codec{}).Marshal(context.Background(), D{{"A", math.MaxFloat64}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := decode(data, &v, nil); err == nil {
+		t.Error("expect error of overflow")
+	}
+}
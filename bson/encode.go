@@ -0,0 +1,393 @@
+package bson
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+// maxDepth is the maximum nesting depth of documents and arrays.
+const maxDepth = 1000
+
+var (
+	_timeType          = reflect.TypeOf(time.Time{})
+	_dType             = reflect.TypeOf(D{})
+	_objectIDType      = reflect.TypeOf(ObjectID{})
+	_dateTimeType      = reflect.TypeOf(DateTime(0))
+	_decimal128Type    = reflect.TypeOf(Decimal128{})
+	_binaryType        = reflect.TypeOf(Binary{})
+	_regexType         = reflect.TypeOf(Regex{})
+	_timestampType     = reflect.TypeOf(Timestamp{})
+	_minKeyType        = reflect.TypeOf(MinKey{})
+	_maxKeyType        = reflect.TypeOf(MaxKey{})
+	_javaScriptType    = reflect.TypeOf(JavaScript(""))
+	_symbolType        = reflect.TypeOf(Symbol(""))
+	_codeWithScopeType = reflect.TypeOf(CodeWithScope{})
+	_dbPointerType     = reflect.TypeOf(DBPointer{})
+	_isZeroerType      = reflect.TypeOf((*interface{ IsZero() bool })(nil)).Elem()
+)
+
+type encoder struct {
+	config *EncoderConfig
+	buf    []byte
+	depth  int
+}
+
+// encode encodes v, which must be a document: a struct, a map with string
+// keys, a D, or a pointer to one of them.
+func (e *encoder) encode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return fmt.Errorf("bson: can not marshal nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || !isDocument(rv) {
+		return fmt.Errorf("bson: can not marshal %T as a document", v)
+	}
+	if rv.Kind() != reflect.Struct && rv.IsNil() {
+		return e.document(func() error { return nil })
+	}
+	_, err := e.value(rv)
+	return err
+}
+
+func isDocument(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct:
+		return v.Type() != _timeType && v.Type() != _decimal128Type && v.Type() != _binaryType &&
+			v.Type() != _regexType && v.Type() != _timestampType && v.Type() != _minKeyType &&
+			v.Type() != _maxKeyType && v.Type() != _codeWithScopeType && v.Type() != _dbPointerType
+	case reflect.Map:
+		return v.Type().Key().Kind() == reflect.String
+	}
+	return v.Type() == _dType
+}
+
+func (e *encoder) int32(n int32) {
+	e.buf = append(e.buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+func (e *encoder) int64(n int64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(n))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) cstring(s string) error {
+	if strings.IndexByte(s, 0) >= 0 {
+		return fmt.Errorf("bson: key or pattern %q contains NUL", s)
+	}
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+	return nil
+}
+
+func (e *encoder) string(s string) {
+	e.int32(int32(len(s) + 1))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// element appends an element of the key and the value v.
+func (e *encoder) element(key string, v reflect.Value) error {
+	pos := len(e.buf)
+	e.buf = append(e.buf, 0)
+	if err := e.cstring(key); err != nil {
+		return err
+	}
+	t, err := e.value(v)
+	if err != nil {
+		return err
+	}
+	e.buf[pos] = t
+	return nil
+}
+
+// document appends a document whose elements are appended by fn.
+func (e *encoder) document(fn func() error) error {
+	if e.depth++; e.depth > maxDepth {
+		return fmt.Errorf("bson: exceeded max nesting depth %d", maxDepth)
+	}
+	defer func() { e.depth-- }()
+	pos := len(e.buf)
+	e.int32(0)
+	if err := fn(); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, 0)
+	binary.LittleEndian.PutUint32(e.buf[pos:], uint32(len(e.buf)-pos))
+	return nil
+}
+
+// value appends the value v, and returns its element type.
+func (e *encoder) value(v reflect.Value) (byte, error) {
+	if !v.IsValid() {
+		return typeNull, nil
+	}
+	switch v.Type() {
+	case _timeType:
+		e.int64(int64(NewDateTime(v.Interface().(time.Time))))
+		return typeDateTime, nil
+	case _dateTimeType:
+		e.int64(v.Int())
+		return typeDateTime, nil
+	case _objectIDType:
+		id := v.Interface().(ObjectID)
+		e.buf = append(e.buf, id[:]...)
+		return typeObjectID, nil
+	case _decimal128Type:
+		d := v.Interface().(Decimal128)
+		e.int64(int64(d.l))
+		e.int64(int64(d.h))
+		return typeDecimal128, nil
+	case _binaryType:
+		b := v.Interface().(Binary)
+		e.binary(b.Subtype, b.Data)
+		return typeBinary, nil
+	case _regexType:
+		r := v.Interface().(Regex)
+		if err := e.cstring(r.Pattern); err != nil {
+			return 0, err
+		}
+		return typeRegex, e.cstring(sortOptions(r.Options))
+	case _timestampType:
+		ts := v.Interface().(Timestamp)
+		e.int64(int64(ts.T)<<32 | int64(ts.I))
+		return typeTimestamp, nil
+	case _minKeyType:
+		return typeMinKey, nil
+	case _maxKeyType:
+		return typeMaxKey, nil
+	case _javaScriptType:
+		e.string(v.String())
+		return typeJavaScript, nil
+	case _symbolType:
+		e.string(v.String())
+		return typeSymbol, nil
+	case _codeWithScopeType:
+		return typeCodeWithScope, e.codeWithScope(v.Interface().(CodeWithScope))
+	case _dbPointerType:
+		p := v.Interface().(DBPointer)
+		e.string(p.Ref)
+		e.buf = append(e.buf, p.ID[:]...)
+		return typeDBPointer, nil
+	case _dType:
+		if v.IsNil() {
+			return typeNull, nil
+		}
+		return typeDocument, e.document(func() error {
+			for _, elem := range v.Interface().(D) {
+				if err := e.element(elem.Key, reflect.ValueOf(elem.Value)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return typeNull, nil
+		}
+		return e.value(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+		return typeBool, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		e.int32(int32(v.Int()))
+		return typeInt32, nil
+	case reflect.Uint8, reflect.Uint16:
+		e.int32(int32(v.Uint()))
+		return typeInt32, nil
+	case reflect.Int, reflect.Int64:
+		return e.integer(v.Int()), nil
+	case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := v.Uint()
+		if n > math.MaxInt64 {
+			return 0, fmt.Errorf("bson: unsigned integer %d overflows int64", n)
+		}
+		return e.integer(int64(n)), nil
+	case reflect.Float32, reflect.Float64:
+		e.int64(int64(math.Float64bits(v.Float())))
+		return typeDouble, nil
+	case reflect.String:
+		e.string(v.String())
+		return typeString, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return typeNull, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.binary(BinaryGeneric, v.Bytes())
+			return typeBinary, nil
+		}
+		return typeArray, e.array(v)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.binary(BinaryGeneric, b)
+			return typeBinary, nil
+		}
+		return typeArray, e.array(v)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return 0, fmt.Errorf("bson: unsupported map key type %s", v.Type().Key())
+		}
+		if v.IsNil() {
+			return typeNull, nil
+		}
+		return typeDocument, e.document(func() error {
+			return e.entries(v, nil)
+		})
+	case reflect.Struct:
+		return typeDocument, e.document(func() error {
+			return e.fields(v)
+		})
+	}
+	return 0, fmt.Errorf("bson: unsupported type %s", v.Type())
+}
+
+// integer appends n as an int64, or as an int32 if it fits and MinSize is set.
+func (e *encoder) integer(n int64) byte {
+	if e.config.MinSize && n >= math.MinInt32 && n <= math.MaxInt32 {
+		e.int32(int32(n))
+		return typeInt32
+	}
+	e.int64(n)
+	return typeInt64
+}
+
+func (e *encoder) binary(subtype byte, data []byte) {
+	if subtype == BinaryOld {
+		e.int32(int32(len(data) + 4))
+		e.buf = append(e.buf, subtype)
+		e.int32(int32(len(data)))
+	} else {
+		e.int32(int32(len(data)))
+		e.buf = append(e.buf, subtype)
+	}
+	e.buf = append(e.buf, data...)
+}
+
+func (e *encoder) codeWithScope(c CodeWithScope) error {
+	pos := len(e.buf)
+	e.int32(0)
+	e.string(string(c.Code))
+	scope := reflect.ValueOf(c.Scope)
+	for scope.Kind() == reflect.Ptr || scope.Kind() == reflect.Interface {
+		scope = scope.Elem()
+	}
+	if !scope.IsValid() {
+		scope = reflect.ValueOf(D{})
+	}
+	if !isDocument(scope) {
+		return fmt.Errorf("bson: scope of code with scope must be a document, not %s", scope.Type())
+	}
+	if _, err := e.value(scope); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(e.buf[pos:], uint32(len(e.buf)-pos))
+	return nil
+}
+
+func (e *encoder) array(v reflect.Value) error {
+	return e.document(func() error {
+		for i := 0; i < v.Len(); i++ {
+			if err := e.element(strconv.Itoa(i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// entries appends entries of the map v in order of keys, except the ones in
+// skip, which is the set of field names of the struct holding an inline map.
+func (e *encoder) entries(v reflect.Value, skip map[string]bool) error {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, k := range keys {
+		if skip[k.String()] {
+			return fmt.Errorf("bson: key %q of inline map conflicts with a field", k.String())
+		}
+		if err := e.element(k.String(), v.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) fields(v reflect.Value) error {
+	fs := fields.Of(v.Type(), _tags...)
+	var inline reflect.Value
+	for i := range fs {
+		f := &fs[i]
+		fv, ok := fields.ByIndex(v, f.Index, false)
+		if !ok {
+			continue
+		}
+		if isInlineMap(f) {
+			inline = fv
+			continue
+		}
+		if f.OmitEmpty() && isEmpty(fv) {
+			continue
+		}
+		if err := e.element(f.Name, fv); err != nil {
+			return err
+		}
+	}
+	if !inline.IsValid() || inline.IsNil() {
+		return nil
+	}
+	names := make(map[string]bool, len(fs))
+	for i := range fs {
+		if !isInlineMap(&fs[i]) {
+			names[fs[i].Name] = true
+		}
+	}
+	return e.entries(inline, names)
+}
+
+// isInlineMap reports whether f is a map with string keys and the "inline"
+// option, whose entries are elements of the document of the struct.
+func isInlineMap(f *fields.Field) bool {
+	return f.Type.Kind() == reflect.Map && f.Type.Key().Kind() == reflect.String && f.Options.Contains("inline")
+}
+
+// isEmpty reports whether v is empty as defined by the "omitempty" option of
+// encoding/json, or v has an IsZero method reporting true, e.g. time.Time
+// and ObjectID.
+func isEmpty(v reflect.Value) bool {
+	if fields.IsEmpty(v) {
+		return true
+	}
+	if v.Type().Implements(_isZeroerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return true
+		}
+		return v.Interface().(interface{ IsZero() bool }).IsZero()
+	}
+	return false
+}
+
+// sortOptions sorts the options of a regular expression, as the spec requires.
+func sortOptions(options string) string {
+	b := []byte(options)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return string(b)
+}
@@ -0,0 +1,155 @@
+package bson
+
+import (
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var _id = ObjectID{0x5f, 0x2a, 0x3b, 0x4c, 1, 2, 3, 4, 5, 6, 7, 8}
+
+// _vectors are documents which round trip through D.
+var _vectors = []struct {
+	name string
+	doc  D
+	hex  string
+}{
+	{"empty", D{}, "05000000 00"},
+	{"string", D{{"hello", "world"}}, "16000000 02 68656c6c6f00 06000000 776f726c6400 00"},
+	{"array", D{{"BSON", []interface{}{"awesome", 5.05, int32(1986)}}},
+		"31000000 04 42534f4e00 26000000 02 3000 08000000 617765736f6d6500 01 3100 3333333333331440 10 3200 c2070000 00 00"},
+	{"double", D{{"a", 1.0}}, "10000000 01 6100 000000000000f03f 00"},
+	{"document", D{{"a", map[string]interface{}{}}}, "0d000000 03 6100 05000000 00 00"},
+	{"binary", D{{"a", []byte{1, 2}}}, "0f000000 05 6100 02000000 00 0102 00"},
+	{"binary old", D{{"a", Binary{Subtype: BinaryOld, Data: []byte{1, 2}}}}, "13000000 05 6100 06000000 02 02000000 0102 00"},
+	{"binary uuid", D{{"a", Binary{Subtype: BinaryUUID, Data: []byte{0xff}}}}, "0e000000 05 6100 01000000 04 ff 00"},
+	{"object id", D{{"a", _id}}, "14000000 07 6100 5f2a3b4c0102030405060708 00"},
+	{"bool", D{{"a", true}}, "09000000 08 6100 01 00"},
+	{"datetime", D{{"a", time.Unix(1, 0).UTC()}}, "10000000 09 6100 e803000000000000 00"},
+	{"null", D{{"a", nil}}, "08000000 0a 6100 00"},
+	{"regex", D{{"a", Regex{Pattern: "a*", Options: "im"}}}, "0e000000 0b 6100 612a00 696d00 00"},
+	{"db pointer", D{{"a", DBPointer{Ref: "b", ID: _id}}}, "1a000000 0c 6100 02000000 6200 5f2a3b4c0102030405060708 00"},
+	{"javascript", D{{"a", JavaScript("x")}}, "0e000000 0d 6100 02000000 7800 00"},
+	{"symbol", D{{"a", Symbol("x")}}, "0e000000 0e 6100 02000000 7800 00"},
+	{"code with scope", D{{"a", CodeWithScope{Code: "x", Scope: map[string]interface{}{}}}},
+		"17000000 0f 6100 0f000000 02000000 7800 05000000 00 00"},
+	{"int32", D{{"a", int32(-1)}}, "0c000000 10 6100 ffffffff 00"},
+	{"timestamp", D{{"a", Timestamp{T: 1, I: 2}}}, "10000000 11 6100 02000000 01000000 00"},
+	{"int64", D{{"a", int64(1)}}, "10000000 12 6100 0100000000000000 00"},
+	{"decimal128", D{{"a", NewDecimal128(0x3040000000000000, 1)}}, "18000000 13 6100 0100000000000000 0000000000004030 00"},
+	{"min key", D{{"a", MinKey{}}}, "08000000 ff 6100 00"},
+	{"max key", D{{"a", MaxKey{}}}, "08000000 7f 6100 00"},
+	{"order", D{{"b", int32(1)}, {"a", int32(2)}}, "13000000 10 6200 01000000 10 6100 02000000 00"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, tt := range _vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &encoder{config: &EncoderConfig{}}
+			if err := e.encode(tt.doc); err != nil {
+				t.Fatal(err)
+			}
+			if want := mustHex(tt.hex); string(e.buf) != string(want) {
+				t.Errorf("got % x, want % x", e.buf, want)
+			}
+		})
+	}
+}
+
+type fallback struct {
+	A int32  `json:"x"`
+	B string `bson:",omitempty"`
+	C string `bson:"-"`
+}
+
+type embedded struct {
+	E int32 `bson:"e"`
+}
+
+type withInline struct {
+	embedded
+	Inner struct {
+		I int32 `bson:"i"`
+	} `bson:",inline"`
+	ID    ObjectID               `bson:"_id,omitempty"`
+	At    time.Time              `bson:"at,omitempty"`
+	Extra map[string]interface{} `bson:",inline"`
+}
+
+func TestEncode_Values(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		hex  string
+	}{
+		{"json fallback", fallback{A: 1, C: "c"}, "0c000000 10 7800 01000000 00"},
+		{"omitempty", &fallback{B: "b"}, "15000000 10 7800 00000000 02 4200 02000000 6200 00"},
+		{"inline", withInline{Extra: map[string]interface{}{"z": nil}},
+			"16000000 10 6500 00000000 10 6900 00000000 0a 7a00 00"},
+		{"map in order", map[string]int8{"b": 1, "a": 2}, "13000000 10 6100 02000000 10 6200 01000000 00"},
+		{"nil map", map[string]int(nil), "05000000 00"},
+		{"small unsigned", D{{"a", uint16(1)}}, "0c000000 10 6100 01000000 00"},
+		{"int", D{{"a", 1}}, "10000000 12 6100 0100000000000000 00"},
+		{"uint32", D{{"a", uint32(1)}}, "10000000 12 6100 0100000000000000 00"},
+		{"float32", D{{"a", float32(1)}}, "10000000 01 6100 000000000000f03f 00"},
+		{"nil slice", D{{"a", []int(nil)}}, "08000000 0a 6100 00"},
+		{"byte array", D{{"a", [2]byte{1, 2}}}, "0f000000 05 6100 02000000 00 0102 00"},
+		{"regex options sorted", D{{"a", Regex{Pattern: "", Options: "xmi"}}}, "0d000000 0b 6100 00 696d7800 00"},
+		{"pointer", D{{"a", new(int32)}}, "0c000000 10 6100 00000000 00"},
+		{"date time", D{{"a", DateTime(-1)}}, "10000000 09 6100 ffffffffffffffff 00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &encoder{config: &EncoderConfig{}}
+			if err := e.encode(tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if want := mustHex(tt.hex); string(e.buf) != string(want) {
+				t.Errorf("got % x, want % x", e.buf, want)
+			}
+		})
+	}
+}
+
+func TestEncode_Error(t *testing.T) {
+	deep := D{}
+	for i := 0; i < maxDepth; i++ {
+		deep = D{{"a", deep}}
+	}
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"not document", 1},
+		{"array", []int{1}},
+		{"time", time.Now()},
+		{"nil pointer", (*fallback)(nil)},
+		{"nil", nil},
+		{"key with NUL", D{{"a\x00", 1}}},
+		{"regex with NUL", D{{"a", Regex{Pattern: "\x00"}}}},
+		{"uint64 overflow", D{{"a", uint64(math.MaxUint64)}}},
+		{"unsupported type", D{{"a", make(chan int)}}},
+		{"map key", D{{"a", map[int]int{1: 1}}}},
+		{"scope", D{{"a", CodeWithScope{Scope: 1}}}},
+		{"inline conflict", withInline{Extra: map[string]interface{}{"e": 1}}},
+		{"depth", deep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &encoder{config: &EncoderConfig{}}
+			if err := e.encode(tt.v); err == nil {
+				t.Errorf("expect error, got % x", e.buf)
+			}
+		})
+	}
+}
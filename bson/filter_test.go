@@ -0,0 +1,33 @@
+package bson
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, MinSize())
+	got, err := m.Marshal(context.Background(), D{{"a", 1}, {"b", int64(1) << 40}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustHex("17000000 10 6100 01000000 12 6200 0000000000010000 00"); string(got) != string(want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	data := mustHex("0c000000 10 6100 01000000 00")
+	var v interface{}
+	if err := WithDecoderOption(&codec{}, OrderedDocuments()).Unmarshal(context.Background(), data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := (D{{"a", int32(1)}}); !reflect.DeepEqual(v, want) {
+		t.Errorf("got %#v, want %#v", v, want)
+	}
+	var s struct{}
+	if err := WithDecoderOption(&codec{}, DisallowUnknownFields()).Unmarshal(context.Background(), data, &s); err == nil {
+		t.Error("expect error of unknown field")
+	}
+}
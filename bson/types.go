@@ -0,0 +1,213 @@
+package bson
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// Element types.
+const (
+	typeDouble        = 0x01
+	typeString        = 0x02
+	typeDocument      = 0x03
+	typeArray         = 0x04
+	typeBinary        = 0x05
+	typeUndefined     = 0x06
+	typeObjectID      = 0x07
+	typeBool          = 0x08
+	typeDateTime      = 0x09
+	typeNull          = 0x0a
+	typeRegex         = 0x0b
+	typeDBPointer     = 0x0c
+	typeJavaScript    = 0x0d
+	typeSymbol        = 0x0e
+	typeCodeWithScope = 0x0f
+	typeInt32         = 0x10
+	typeTimestamp     = 0x11
+	typeInt64         = 0x12
+	typeDecimal128    = 0x13
+	typeMinKey        = 0xff
+	typeMaxKey        = 0x7f
+)
+
+// Binary subtypes.
+const (
+	BinaryGeneric     byte = 0x00
+	BinaryFunction    byte = 0x01
+	BinaryOld         byte = 0x02
+	BinaryUUIDOld     byte = 0x03
+	BinaryUUID        byte = 0x04
+	BinaryMD5         byte = 0x05
+	BinaryEncrypted   byte = 0x06
+	BinaryUserDefined byte = 0x80
+)
+
+// D is an ordered document, for documents whose key order matters, such as
+// commands. Keys are not required to be unique.
+type D []E
+
+// E is an element of a D.
+type E struct {
+	Key   string
+	Value interface{}
+}
+
+// Map returns the elements of d as a map. Later elements win if keys are
+// duplicated.
+func (d D) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(d))
+	for _, e := range d {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+// ObjectID is a 12-byte object id, made of a 4-byte timestamp in seconds, a
+// 5-byte random value unique to the process and a 3-byte counter.
+type ObjectID [12]byte
+
+var (
+	_processUnique = processUnique()
+	_objectIDCount = atomic.NewUint32(counterStart())
+)
+
+func processUnique() [5]byte {
+	var b [5]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		panic(fmt.Sprintf("bson: can not initialize object id generator: %v", err))
+	}
+	return b
+}
+
+func counterStart() uint32 {
+	var b [4]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		panic(fmt.Sprintf("bson: can not initialize object id generator: %v", err))
+	}
+	return binary.BigEndian.Uint32(b[:])
+}
+
+// NewObjectID returns a new ObjectID of the current time.
+func NewObjectID() ObjectID {
+	return NewObjectIDFromTime(time.Now())
+}
+
+// NewObjectIDFromTime returns a new ObjectID of the time t.
+func NewObjectIDFromTime(t time.Time) ObjectID {
+	var id ObjectID
+	binary.BigEndian.PutUint32(id[:4], uint32(t.Unix()))
+	copy(id[4:9], _processUnique[:])
+	n := _objectIDCount.Inc()
+	id[9], id[10], id[11] = byte(n>>16), byte(n>>8), byte(n)
+	return id
+}
+
+// ObjectIDFromHex returns the ObjectID of the 24-character hex string s.
+func ObjectIDFromHex(s string) (ObjectID, error) {
+	var id ObjectID
+	if len(s) != 2*len(id) {
+		return id, fmt.Errorf("bson: invalid object id %q", s)
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, fmt.Errorf("bson: invalid object id %q", s)
+	}
+	return id, nil
+}
+
+// Hex returns the hex string of the ObjectID.
+func (id ObjectID) Hex() string {
+	return hex.EncodeToString(id[:])
+}
+
+// String returns the ObjectID in the form of ObjectID("hex").
+func (id ObjectID) String() string {
+	return fmt.Sprintf("ObjectID(%q)", id.Hex())
+}
+
+// Timestamp returns the time the ObjectID was generated at, in seconds.
+func (id ObjectID) Timestamp() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(id[:4])), 0).UTC()
+}
+
+// IsZero reports whether id is the zero ObjectID, which is omitted by the
+// "omitempty" option.
+func (id ObjectID) IsZero() bool {
+	return id == ObjectID{}
+}
+
+// DateTime is a UTC datetime in milliseconds since the Unix epoch. time.Time
+// is encoded as a datetime too, truncated to milliseconds.
+type DateTime int64
+
+// NewDateTime returns the DateTime of the time t.
+func NewDateTime(t time.Time) DateTime {
+	return DateTime(t.Unix()*1000 + int64(t.Nanosecond())/1e6)
+}
+
+// Time returns the DateTime as a time.Time in UTC.
+func (dt DateTime) Time() time.Time {
+	ms := int64(dt)
+	sec, msec := ms/1000, ms%1000
+	if msec < 0 {
+		sec, msec = sec-1, msec+1000
+	}
+	return time.Unix(sec, msec*1e6).UTC()
+}
+
+// Binary is binary data of a subtype. []byte is encoded as binary data of
+// BinaryGeneric. Data of BinaryOld does not include its inner length, which
+// is added when encoding and removed when decoding.
+type Binary struct {
+	Subtype byte
+	Data    []byte
+}
+
+// IsZero reports whether b is empty generic binary data, which is omitted by
+// the "omitempty" option.
+func (b Binary) IsZero() bool {
+	return b.Subtype == BinaryGeneric && len(b.Data) == 0
+}
+
+// Regex is a regular expression, with options in alphabetical order.
+type Regex struct {
+	Pattern string
+	Options string
+}
+
+// Timestamp is an internal timestamp of MongoDB, made of seconds since the
+// Unix epoch and an ordinal within the second.
+type Timestamp struct {
+	T uint32
+	I uint32
+}
+
+// MinKey is the value comparing lower than all other values.
+type MinKey struct{}
+
+// MaxKey is the value comparing higher than all other values.
+type MaxKey struct{}
+
+// JavaScript is JavaScript code.
+type JavaScript string
+
+// Symbol is a symbol, deprecated by the spec.
+type Symbol string
+
+// CodeWithScope is JavaScript code with a scope document, deprecated by the
+// spec.
+type CodeWithScope struct {
+	Code  JavaScript
+	Scope interface{}
+}
+
+// DBPointer is a reference to a document, deprecated by the spec.
+type DBPointer struct {
+	Ref string
+	ID  ObjectID
+}
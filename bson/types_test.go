@@ -0,0 +1,71 @@
+package bson
+
+import (
+	"testing"
+	"time"
+)
+
+func TestObjectID(t *testing.T) {
+	id, err := ObjectIDFromHex("5f2a3b4c0102030405060708")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Hex() != "5f2a3b4c0102030405060708" || id.String() != `ObjectID("5f2a3b4c0102030405060708")` {
+		t.Errorf("got %s", id)
+	}
+	if want := time.Unix(0x5f2a3b4c, 0).UTC(); !id.Timestamp().Equal(want) {
+		t.Errorf("got timestamp %v, want %v", id.Timestamp(), want)
+	}
+	if id.IsZero() || !(ObjectID{}).IsZero() {
+		t.Error("IsZero is wrong")
+	}
+	for _, s := range []string{"", "5f2a3b4c01020304050607", "5f2a3b4c010203040506070g"} {
+		if _, err := ObjectIDFromHex(s); err == nil {
+			t.Errorf("ObjectIDFromHex(%q) expect error", s)
+		}
+	}
+}
+
+func TestNewObjectID(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	a, b := NewObjectIDFromTime(now), NewObjectIDFromTime(now)
+	if a == b {
+		t.Errorf("got the same ids %s", a)
+	}
+	if string(a[:9]) != string(b[:9]) || !a.Timestamp().Equal(now) {
+		t.Errorf("got ids %s and %s", a, b)
+	}
+	if NewObjectID().IsZero() {
+		t.Error("got zero id")
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	tests := []struct {
+		t  time.Time
+		dt DateTime
+	}{
+		{t: time.Unix(0, 0), dt: 0},
+		{t: time.Unix(1, 5e8), dt: 1500},
+		{t: time.Unix(-1, 5e8), dt: -500},
+		{t: time.Unix(-2, 0), dt: -2000},
+	}
+	for _, tt := range tests {
+		if dt := NewDateTime(tt.t); dt != tt.dt {
+			t.Errorf("NewDateTime(%v) = %d, want %d", tt.t, dt, tt.dt)
+		}
+		if got := tt.dt.Time(); !got.Equal(tt.t) || got.Location() != time.UTC {
+			t.Errorf("DateTime(%d).Time() = %v, want %v", tt.dt, got, tt.t)
+		}
+	}
+	if dt := NewDateTime(time.Unix(1, 999999)); dt != 1000 {
+		t.Errorf("got %d, want truncated to milliseconds", dt)
+	}
+}
+
+func TestD_Map(t *testing.T) {
+	m := D{{"a", 1}, {"b", 2}, {"a", 3}}.Map()
+	if len(m) != 2 || m["a"] != 3 || m["b"] != 2 {
+		t.Errorf("got %v", m)
+	}
+}
@@ -0,0 +1,63 @@
+// Package cbor defines and registers Marshaler/Unmarshaler handling CBOR
+// (RFC 8949) content.
+//
+// Values are mapped to CBOR the way encoding/json maps them to JSON: structs
+// are encoded as maps keyed by field names, taken from the "cbor" tag or, in
+// absence of it, the "json" tag. In addition, []byte is encoded as a byte
+// string, time.Time as a tagged date/time, *big.Int as a bignum if it does not
+// fit in an integer, and DecimalFraction as a tagged decimal fraction.
+package cbor
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "cbor"
+
+// MIMEType is the media type of CBOR.
+const MIMEType = "application/cbor"
+
+var _tags = []string{"cbor", "json"}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	e := &encoder{config: config}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	d := &decoder{data: data}
+	if err := d.decode(v); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return d.syntaxError("unexpected data after top-level item")
+	}
+	return nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,188 @@
+package cbor
+
+import (
+	"context"
+	"encoding/hex"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func mustBig(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic(s)
+	}
+	return n
+}
+
+// Test vectors from RFC 8949 Appendix A, encoded in core deterministic form.
+var _vectors = []struct {
+	name string
+	v    interface{}
+	hex  string
+}{
+	{"0", uint64(0), "00"},
+	{"1", uint64(1), "01"},
+	{"10", uint64(10), "0a"},
+	{"23", uint64(23), "17"},
+	{"24", uint64(24), "18 18"},
+	{"25", uint64(25), "18 19"},
+	{"100", uint64(100), "18 64"},
+	{"1000", uint64(1000), "19 03 e8"},
+	{"1000000", uint64(1000000), "1a 00 0f 42 40"},
+	{"1000000000000", uint64(1000000000000), "1b 00 00 00 e8 d4 a5 10 00"},
+	{"18446744073709551615", uint64(math.MaxUint64), "1b ff ff ff ff ff ff ff ff"},
+	{"18446744073709551616", mustBig("18446744073709551616"), "c2 49 01 00 00 00 00 00 00 00 00"},
+	{"-18446744073709551616", mustBig("-18446744073709551616"), "3b ff ff ff ff ff ff ff ff"},
+	{"-18446744073709551617", mustBig("-18446744073709551617"), "c3 49 01 00 00 00 00 00 00 00 00"},
+	{"-1", int64(-1), "20"},
+	{"-10", int64(-10), "29"},
+	{"-100", int64(-100), "38 63"},
+	{"-1000", int64(-1000), "39 03 e7"},
+	{"0.0", 0.0, "f9 00 00"},
+	{"-0.0", math.Copysign(0, -1), "f9 80 00"},
+	{"1.0", 1.0, "f9 3c 00"},
+	{"1.1", 1.1, "fb 3f f1 99 99 99 99 99 9a"},
+	{"1.5", 1.5, "f9 3e 00"},
+	{"65504.0", 65504.0, "f9 7b ff"},
+	{"100000.0", 100000.0, "fa 47 c3 50 00"},
+	{"3.4028234663852886e+38", 3.4028234663852886e+38, "fa 7f 7f ff ff"},
+	{"1.0e+300", 1.0e+300, "fb 7e 37 e4 3c 88 00 75 9c"},
+	{"5.960464477539063e-8", 5.960464477539063e-8, "f9 00 01"},
+	{"0.00006103515625", 0.00006103515625, "f9 04 00"},
+	{"-4.0", -4.0, "f9 c4 00"},
+	{"-4.1", -4.1, "fb c0 10 66 66 66 66 66 66"},
+	{"Infinity", math.Inf(1), "f9 7c 00"},
+	{"-Infinity", math.Inf(-1), "f9 fc 00"},
+	{"false", false, "f4"},
+	{"true", true, "f5"},
+	{"null", nil, "f6"},
+	{"simple(16)", Simple(16), "f0"},
+	{"simple(255)", Simple(255), "f8 ff"},
+	{"0(\"2013-03-21T20:04:00Z\")", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "c0 74 32 30 31 33 2d 30 33 2d 32 31 54 32 30 3a 30 34 3a 30 30 5a"},
+	{"23(h'01020304')", Tag{Number: 23, Content: []byte{1, 2, 3, 4}}, "d7 44 01 02 03 04"},
+	{"32(\"http://www.example.com\")", Tag{Number: 32, Content: "http://www.example.com"}, "d8 20 76 68 74 74 70 3a 2f 2f 77 77 77 2e 65 78 61 6d 70 6c 65 2e 63 6f 6d"},
+	{"h''", []byte{}, "40"},
+	{"h'01020304'", []byte{1, 2, 3, 4}, "44 01 02 03 04"},
+	{"\"\"", "", "60"},
+	{"\"a\"", "a", "61 61"},
+	{"\"IETF\"", "IETF", "64 49 45 54 46"},
+	{"\"\\\"\\\\\"", "\"\\", "62 22 5c"},
+	{"\"ü\"", "ü", "62 c3 bc"},
+	{"\"水\"", "水", "63 e6 b0 b4"},
+	{"[]", []interface{}{}, "80"},
+	{"[1, 2, 3]", []interface{}{uint64(1), uint64(2), uint64(3)}, "83 01 02 03"},
+	{"[1, [2, 3], [4, 5]]", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}, "83 01 82 02 03 82 04 05"},
+	{"{}", map[string]interface{}{}, "a0"},
+	{"{1: 2, 3: 4}", map[interface{}]interface{}{uint64(1): uint64(2), uint64(3): uint64(4)}, "a2 01 02 03 04"},
+	{"{\"a\": 1, \"b\": [2, 3]}", map[string]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}, "a2 61 61 01 61 62 82 02 03"},
+	{"[\"a\", {\"b\": \"c\"}]", []interface{}{"a", map[string]interface{}{"b": "c"}}, "82 61 61 a1 61 62 61 63"},
+}
+
+func TestCodec_Vectors(t *testing.T) {
+	c := &codec{}
+	ctx := contextWithEncoderOption(context.Background(), Deterministic())
+	for _, tt := range _vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			want := mustHex(tt.hex)
+			got, err := c.Marshal(ctx, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("marshal got % x, want % x", got, want)
+			}
+			var v interface{}
+			if err := c.Unmarshal(context.Background(), want, &v); err != nil {
+				t.Fatal(err)
+			}
+			if n, ok := tt.v.(*big.Int); ok {
+				switch x := v.(type) {
+				case *big.Int:
+					if x.Cmp(n) != 0 {
+						t.Errorf("unmarshal got %v, want %v", x, n)
+					}
+				default:
+					t.Errorf("unmarshal got %#v, want %v", v, n)
+				}
+				return
+			}
+			if !reflect.DeepEqual(v, tt.v) {
+				t.Errorf("unmarshal got %#v, want %#v", v, tt.v)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+type inner struct {
+	Tags []string `cbor:"tags"`
+}
+
+type sample struct {
+	ID      int               `cbor:"id"`
+	Name    string            `json:"name"`
+	Score   float32           `cbor:"score,omitempty"`
+	Raw     []byte            `cbor:"raw"`
+	At      time.Time         `cbor:"at"`
+	Big     *big.Int          `cbor:"big"`
+	Inner   *inner            `cbor:"inner"`
+	Attrs   map[string]uint16 `cbor:"attrs"`
+	Fixed   [2]int8           `cbor:"fixed"`
+	Any     interface{}       `cbor:"any"`
+	Skipped string            `cbor:"-"`
+}
+
+func TestCodec_Struct(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := sample{
+		ID:    -7,
+		Name:  "name",
+		Raw:   []byte("raw"),
+		At:    time.Date(2021, 6, 1, 8, 0, 0, 1, time.UTC),
+		Big:   mustBig("123456789012345678901234567890"),
+		Inner: &inner{Tags: []string{"a"}},
+		Attrs: map[string]uint16{"b": 2, "a": 1},
+		Fixed: [2]int8{1, -1},
+		Any:   "x",
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out sample
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	var generic map[string]interface{}
+	if err := u.Unmarshal(context.Background(), data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if generic["name"] != "name" || generic["id"] != int64(-7) || generic["score"] != nil {
+		t.Errorf("unexpected generic map %v", generic)
+	}
+}
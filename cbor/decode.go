@@ -0,0 +1,748 @@
+package cbor
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+// Unmarshaler is implemented by types which decode themselves from CBOR.
+// The data is exactly one data item, and must be copied if it is retained
+// after returning.
+type Unmarshaler interface {
+	UnmarshalCBOR(data []byte) error
+}
+
+// SyntaxError is an error of malformed CBOR data.
+type SyntaxError struct {
+	// Offset is the offset in bytes where the error occurred.
+	Offset int
+	msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("cbor: %s at offset %d", e.msg, e.Offset)
+}
+
+// UnmarshalTypeError is an error of decoding a data item into a value of
+// inappropriate type.
+type UnmarshalTypeError struct {
+	// Value describes the data item, e.g. "text string", "array".
+	Value string
+	// Type is the type of the target value.
+	Type reflect.Type
+	// Offset is the offset in bytes of the data item.
+	Offset int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("cbor: cannot unmarshal %s into Go value of type %s at offset %d", e.Value, e.Type, e.Offset)
+}
+
+var _unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+var _majorNames = [...]string{
+	majorUint:   "unsigned integer",
+	majorNegint: "negative integer",
+	majorBytes:  "byte string",
+	majorText:   "text string",
+	majorArray:  "array",
+	majorMap:    "map",
+	majorTag:    "tag",
+	majorSimple: "simple value",
+}
+
+// head is the initial byte and argument of a data item. For major type 7,
+// info is the additional information, and arg holds the bits of floats.
+type head struct {
+	major      byte
+	info       byte
+	arg        uint64
+	indefinite bool
+	offset     int
+}
+
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *decoder) syntaxError(msg string) error {
+	return &SyntaxError{Offset: d.pos, msg: msg}
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if uint64(len(d.data)-d.pos) < n {
+		return nil, d.syntaxError("unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+func (d *decoder) head() (h head, err error) {
+	h.offset = d.pos
+	b, err := d.read(1)
+	if err != nil {
+		return h, err
+	}
+	h.major, h.info = b[0]>>5, b[0]&0x1f
+	switch {
+	case h.info < 24:
+		h.arg = uint64(h.info)
+	case h.info <= 27:
+		b, err := d.read(1 << (h.info - 24))
+		if err != nil {
+			return h, err
+		}
+		for _, x := range b {
+			h.arg = h.arg<<8 | uint64(x)
+		}
+		if h.major == majorSimple && h.info == 24 && h.arg < 32 {
+			return h, &SyntaxError{Offset: h.offset, msg: "invalid simple value"}
+		}
+	case h.info == 31:
+		switch h.major {
+		case majorBytes, majorText, majorArray, majorMap:
+			h.indefinite = true
+		case majorSimple:
+			return h, &SyntaxError{Offset: h.offset, msg: "unexpected break"}
+		default:
+			return h, &SyntaxError{Offset: h.offset, msg: "invalid indefinite length"}
+		}
+	default:
+		return h, &SyntaxError{Offset: h.offset, msg: fmt.Sprintf("invalid additional information %d", h.info)}
+	}
+	return h, nil
+}
+
+// isBreak reports whether the next byte is the break stop code, and consumes
+// it if so.
+func (d *decoder) isBreak() (bool, error) {
+	if d.pos >= len(d.data) {
+		return false, d.syntaxError("unexpected end of data")
+	}
+	if d.data[d.pos] == 0xff {
+		d.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+// count checks the number of elements of a definite length array or map.
+func (d *decoder) count(h head) (int, error) {
+	n := h.arg
+	if h.major == majorMap {
+		n *= 2
+	}
+	// Each data item takes at least one byte, so a count larger than the rest
+	// of data is malformed, and must not be used to allocate.
+	if h.arg > uint64(len(d.data)) || n > uint64(len(d.data)-d.pos) {
+		return 0, &SyntaxError{Offset: h.offset, msg: "length exceeds data"}
+	}
+	return int(h.arg), nil
+}
+
+// str reads the payload of a byte or text string, joining chunks of an
+// indefinite length string.
+func (d *decoder) str(h head) ([]byte, error) {
+	if !h.indefinite {
+		return d.read(h.arg)
+	}
+	var b []byte
+	for {
+		brk, err := d.isBreak()
+		if err != nil {
+			return nil, err
+		}
+		if brk {
+			return b, nil
+		}
+		ch, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if ch.major != h.major || ch.indefinite {
+			return nil, &SyntaxError{Offset: ch.offset, msg: "invalid chunk of indefinite length string"}
+		}
+		chunk, err := d.read(ch.arg)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+// skip skips the data item whose head has been read.
+func (d *decoder) skip(h head) error {
+	switch h.major {
+	case majorBytes, majorText:
+		_, err := d.str(h)
+		return err
+	case majorArray, majorMap:
+		return d.elements(h, func(int) error {
+			eh, err := d.head()
+			if err != nil {
+				return err
+			}
+			return d.skip(eh)
+		})
+	case majorTag:
+		return d.nest(func() error {
+			th, err := d.head()
+			if err != nil {
+				return err
+			}
+			return d.skip(th)
+		})
+	}
+	return nil
+}
+
+// nest calls fn for the content of an array, a map or a tag, one level
+// deeper, failing if the nesting is too deep.
+func (d *decoder) nest(fn func() error) error {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxDepth {
+		return d.syntaxError(fmt.Sprintf("exceeded max nesting depth %d", maxDepth))
+	}
+	return fn()
+}
+
+// elements calls fn for each element of an array, or each key and value of a
+// map, whose head has been read.
+func (d *decoder) elements(h head, fn func(i int) error) error {
+	return d.nest(func() error {
+		per := 1
+		if h.major == majorMap {
+			per = 2
+		}
+		if h.indefinite {
+			for i := 0; ; i++ {
+				brk, err := d.isBreak()
+				if err != nil {
+					return err
+				}
+				if brk {
+					if i%per != 0 {
+						return d.syntaxError("map with odd number of items")
+					}
+					return nil
+				}
+				if err := fn(i); err != nil {
+					return err
+				}
+			}
+		}
+		n, err := d.count(h)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n*per; i++ {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *decoder) decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("cbor: can not unmarshal to non-pointer or nil %T", v)
+	}
+	return d.value(rv.Elem())
+}
+
+func (d *decoder) typeError(h head, t reflect.Type) error {
+	return &UnmarshalTypeError{Value: _majorNames[h.major], Type: t, Offset: h.offset}
+}
+
+func isNull(h head) bool {
+	return h.major == majorSimple && (h.info == 22 || h.info == 23)
+}
+
+// value decodes the next data item into v.
+func (d *decoder) value(v reflect.Value) error {
+	start := d.pos
+	h, err := d.head()
+	if err != nil {
+		return err
+	}
+	if isNull(h) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if u, ok := unmarshalerOf(v); ok {
+		if err := d.skip(h); err != nil {
+			return err
+		}
+		return u.UnmarshalCBOR(d.data[start:d.pos])
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.pos = start
+		return d.value(v.Elem())
+	}
+	if v.Kind() == reflect.Interface {
+		if v.NumMethod() != 0 {
+			return d.typeError(h, v.Type())
+		}
+		x, err := d.any(h)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+	switch v.Type() {
+	case _timeType, _bigIntType, _decimalFractionType, _tagType:
+		x, err := d.any(h)
+		if err != nil {
+			return err
+		}
+		if v.Type() == _bigIntType {
+			switch n := x.(type) {
+			case uint64:
+				x = new(big.Int).SetUint64(n)
+			case int64:
+				x = big.NewInt(n)
+			}
+		}
+		if x == nil {
+			return &UnmarshalTypeError{Value: _majorNames[h.major], Type: v.Type(), Offset: h.offset}
+		}
+		xv := reflect.ValueOf(x)
+		if xv.Kind() == reflect.Ptr && xv.Type().Elem() == v.Type() {
+			xv = xv.Elem()
+		}
+		if xv.Type() != v.Type() {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("%T", x), Type: v.Type(), Offset: h.offset}
+		}
+		v.Set(xv)
+		return nil
+	}
+	if h.major == majorTag {
+		// Tags not handled are ignored when decoding into a typed value.
+		return d.nest(func() error { return d.value(v) })
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if h.major != majorSimple || h.info != 20 && h.info != 21 {
+			return d.typeError(h, v.Type())
+		}
+		v.SetBool(h.info == 21)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch {
+		case h.major == majorUint && h.arg <= math.MaxInt64:
+			n = int64(h.arg)
+		case h.major == majorNegint && h.arg <= math.MaxInt64:
+			n = -1 - int64(h.arg)
+		default:
+			return d.typeError(h, v.Type())
+		}
+		if v.OverflowInt(n) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %d", n), Type: v.Type(), Offset: h.offset}
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if h.major != majorUint {
+			return d.typeError(h, v.Type())
+		}
+		if v.OverflowUint(h.arg) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %d", h.arg), Type: v.Type(), Offset: h.offset}
+		}
+		v.SetUint(h.arg)
+	case reflect.Float32, reflect.Float64:
+		switch {
+		case h.major == majorSimple && h.info >= 25 && h.info <= 27:
+			v.SetFloat(floatOf(h))
+		case h.major == majorUint:
+			v.SetFloat(float64(h.arg))
+		case h.major == majorNegint:
+			v.SetFloat(-1 - float64(h.arg))
+		default:
+			return d.typeError(h, v.Type())
+		}
+	case reflect.String:
+		if h.major != majorText && h.major != majorBytes {
+			return d.typeError(h, v.Type())
+		}
+		b, err := d.str(h)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (h.major == majorBytes || h.major == majorText) {
+			b, err := d.str(h)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+		if h.major != majorArray {
+			return d.typeError(h, v.Type())
+		}
+		slice := reflect.MakeSlice(v.Type(), 0, 0)
+		if !h.indefinite {
+			n, err := d.count(h)
+			if err != nil {
+				return err
+			}
+			// The count is bounded by the bytes of the rest of data, but
+			// elements may take more memory than the bytes they are
+			// decoded from, so the capacity is bounded by the bytes too.
+			if size := int(v.Type().Elem().Size()); size > 1 && n > (len(d.data)-d.pos)/size {
+				n = (len(d.data) - d.pos) / size
+			}
+			slice = reflect.MakeSlice(v.Type(), 0, n)
+		}
+		err := d.elements(h, func(int) error {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		v.Set(slice)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (h.major == majorBytes || h.major == majorText) {
+			b, err := d.str(h)
+			if err != nil {
+				return err
+			}
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+		if h.major != majorArray {
+			return d.typeError(h, v.Type())
+		}
+		n := 0
+		err := d.elements(h, func(i int) error {
+			n++
+			if i >= v.Len() {
+				eh, err := d.head()
+				if err != nil {
+					return err
+				}
+				return d.skip(eh)
+			}
+			return d.value(v.Index(i))
+		})
+		if err != nil {
+			return err
+		}
+		for i := n; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	case reflect.Map:
+		if h.major != majorMap {
+			return d.typeError(h, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		var key reflect.Value
+		return d.elements(h, func(i int) error {
+			if i%2 == 0 {
+				key = reflect.New(v.Type().Key()).Elem()
+				return d.value(key)
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			if !hashable(key) {
+				return &SyntaxError{Offset: h.offset, msg: fmt.Sprintf("unhashable map key of type %s", key.Elem().Type())}
+			}
+			v.SetMapIndex(key, elem)
+			return nil
+		})
+	case reflect.Struct:
+		if h.major != majorMap {
+			return d.typeError(h, v.Type())
+		}
+		return d.structValue(h, v)
+	default:
+		return d.typeError(h, v.Type())
+	}
+	return nil
+}
+
+func unmarshalerOf(v reflect.Value) (Unmarshaler, bool) {
+	if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(_unmarshalerType) {
+		return v.Addr().Interface().(Unmarshaler), true
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Implements(_unmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v.Interface().(Unmarshaler), true
+	}
+	return nil, false
+}
+
+func (d *decoder) structValue(h head, v reflect.Value) error {
+	fs := fields.Of(v.Type(), _tags...)
+	var f *fields.Field
+	return d.elements(h, func(i int) error {
+		if i%2 == 0 {
+			var key string
+			if err := d.value(reflect.ValueOf(&key).Elem()); err != nil {
+				return err
+			}
+			f = lookupField(fs, key)
+			return nil
+		}
+		if f == nil {
+			eh, err := d.head()
+			if err != nil {
+				return err
+			}
+			return d.skip(eh)
+		}
+		fv, ok := fields.ByIndex(v, f.Index, true)
+		if !ok {
+			return fmt.Errorf("cbor: field %s: can not set embedded pointer to unexported struct", f.Name)
+		}
+		return d.value(fv)
+	})
+}
+
+func lookupField(fs []fields.Field, key string) *fields.Field {
+	for i := range fs {
+		if fs[i].Name == key {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].Name, key) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+func floatOf(h head) float64 {
+	switch h.info {
+	case 25:
+		return halfToFloat(uint16(h.arg))
+	case 26:
+		return float64(math.Float32frombits(uint32(h.arg)))
+	default:
+		return math.Float64frombits(h.arg)
+	}
+}
+
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// any decodes the data item whose head has been read into a generic value.
+func (d *decoder) any(h head) (interface{}, error) {
+	switch h.major {
+	case majorUint:
+		return h.arg, nil
+	case majorNegint:
+		if h.arg <= math.MaxInt64 {
+			return -1 - int64(h.arg), nil
+		}
+		n := new(big.Int).SetUint64(h.arg)
+		return n.Neg(n.Add(n, big.NewInt(1))), nil
+	case majorBytes:
+		b, err := d.str(h)
+		return append([]byte{}, b...), err
+	case majorText:
+		b, err := d.str(h)
+		return string(b), err
+	case majorArray:
+		var items []interface{}
+		err := d.elements(h, func(int) error {
+			var item interface{}
+			if err := d.value(reflect.ValueOf(&item).Elem()); err != nil {
+				return err
+			}
+			items = append(items, item)
+			return nil
+		})
+		if items == nil && err == nil {
+			items = []interface{}{}
+		}
+		return items, err
+	case majorMap:
+		return d.anyMap(h)
+	case majorTag:
+		return d.tag(h)
+	}
+	switch h.info {
+	case 20, 21:
+		return h.info == 21, nil
+	case 22, 23:
+		return nil, nil
+	case 25, 26, 27:
+		return floatOf(h), nil
+	}
+	return Simple(h.arg), nil
+}
+
+// hashable reports whether key can be a key of a map, which is not the case
+// for arrays and maps decoded into an interface.
+func hashable(key reflect.Value) bool {
+	return key.Kind() != reflect.Interface || key.IsNil() || key.Elem().Type().Comparable()
+}
+
+// anyMap decodes a map into map[string]interface{} if all keys are text
+// strings, otherwise into map[interface{}]interface{}.
+func (d *decoder) anyMap(h head) (interface{}, error) {
+	var keys, values []interface{}
+	allString := true
+	err := d.elements(h, func(i int) error {
+		var x interface{}
+		if err := d.value(reflect.ValueOf(&x).Elem()); err != nil {
+			return err
+		}
+		if i%2 == 0 {
+			if _, ok := x.(string); !ok {
+				allString = false
+			}
+			keys = append(keys, x)
+		} else {
+			values = append(values, x)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if allString {
+		m := make(map[string]interface{}, len(keys))
+		for i, k := range keys {
+			m[k.(string)] = values[i]
+		}
+		return m, nil
+	}
+	m := make(map[interface{}]interface{}, len(keys))
+	for i, k := range keys {
+		if k != nil && !reflect.TypeOf(k).Comparable() {
+			return nil, &UnmarshalTypeError{Value: "map key", Type: reflect.TypeOf(k), Offset: h.offset}
+		}
+		m[k] = values[i]
+	}
+	return m, nil
+}
+
+// tag decodes a tagged data item whose head has been read.
+func (d *decoder) tag(h head) (interface{}, error) {
+	var content interface{}
+	if err := d.nest(func() error { return d.value(reflect.ValueOf(&content).Elem()) }); err != nil {
+		return nil, err
+	}
+	invalid := &SyntaxError{Offset: h.offset, msg: fmt.Sprintf("invalid content of tag %d", h.arg)}
+	switch h.arg {
+	case TagDateTimeString:
+		s, ok := content.(string)
+		if !ok {
+			return nil, invalid
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, invalid
+		}
+		return t, nil
+	case TagEpochDateTime:
+		switch n := content.(type) {
+		case uint64:
+			if n > math.MaxInt64 {
+				return nil, invalid
+			}
+			return time.Unix(int64(n), 0).UTC(), nil
+		case int64:
+			return time.Unix(n, 0).UTC(), nil
+		case float64:
+			if math.IsNaN(n) || math.IsInf(n, 0) {
+				return nil, invalid
+			}
+			sec, frac := math.Modf(n)
+			return time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC(), nil
+		}
+		return nil, invalid
+	case TagPositiveBignum, TagNegativeBignum:
+		b, ok := content.([]byte)
+		if !ok {
+			return nil, invalid
+		}
+		n := new(big.Int).SetBytes(b)
+		if h.arg == TagNegativeBignum {
+			n.Neg(n.Add(n, big.NewInt(1)))
+		}
+		return n, nil
+	case TagDecimalFraction:
+		items, ok := content.([]interface{})
+		if !ok || len(items) != 2 {
+			return nil, invalid
+		}
+		f := DecimalFraction{}
+		switch e := items[0].(type) {
+		case uint64:
+			if e > math.MaxInt64 {
+				return nil, invalid
+			}
+			f.Exponent = int64(e)
+		case int64:
+			f.Exponent = e
+		default:
+			return nil, invalid
+		}
+		switch m := items[1].(type) {
+		case uint64:
+			f.Mantissa.SetUint64(m)
+		case int64:
+			f.Mantissa.SetInt64(m)
+		case *big.Int:
+			f.Mantissa.Set(m)
+		default:
+			return nil, invalid
+		}
+		return f, nil
+	}
+	return Tag{Number: h.arg, Content: content}, nil
+}
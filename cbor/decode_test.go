@@ -0,0 +1,175 @@
+package cbor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type raw struct {
+	data []byte
+}
+
+func (r *raw) UnmarshalCBOR(data []byte) error {
+	r.data = append([]byte{}, data...)
+	return nil
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		v    interface{}
+		want interface{}
+	}{
+		{name: "indefinite bytes", hex: "5f 42 01 02 43 03 04 05 ff", v: new(interface{}), want: []byte{1, 2, 3, 4, 5}},
+		{name: "indefinite text", hex: "7f 65 73 74 72 65 61 64 6d 69 6e 67 ff", v: new(string), want: "streaming"},
+		{name: "indefinite array", hex: "9f 01 82 02 03 9f 04 05 ff ff", v: new(interface{}),
+			want: []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{name: "indefinite map", hex: "bf 61 61 81 01 61 62 9f 02 03 ff ff", v: new(map[string][]int),
+			want: map[string][]int{"a": {1}, "b": {2, 3}}},
+		{name: "half float", hex: "f9 3e 00", v: new(float32), want: float32(1.5)},
+		{name: "half subnormal", hex: "f9 00 01", v: new(float64), want: 5.960464477539063e-8},
+		{name: "int to float", hex: "38 63", v: new(float64), want: -100.0},
+		{name: "epoch time", hex: "c1 1a 51 4b 67 b0", v: new(time.Time), want: time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{name: "epoch time float", hex: "c1 fb 41 d4 52 d9 ec 20 00 00", v: new(time.Time), want: time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC)},
+		{name: "bignum into big.Int", hex: "c2 49 01 00 00 00 00 00 00 00 00", v: new(big.Int), want: *mustBig("18446744073709551616")},
+		{name: "int into big.Int", hex: "20", v: new(*big.Int), want: big.NewInt(-1)},
+		{name: "decimal fraction", hex: "c4 82 21 19 6a b3", v: new(interface{}), want: DecimalFraction{Exponent: -2, Mantissa: *big.NewInt(27315)}},
+		{name: "unknown tag ignored", hex: "d8 20 61 61", v: new(string), want: "a"},
+		{name: "undefined", hex: "f7", v: &[]int{1}, want: []int(nil)},
+		{name: "array into array", hex: "83 01 02 03", v: &[2]int{9, 9}, want: [2]int{1, 2}},
+		{name: "unmarshaler", hex: "82 01 02", v: &raw{}, want: raw{data: []byte{0x82, 0x01, 0x02}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (&codec{}).Unmarshal(context.Background(), mustHex(tt.hex), tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Struct(t *testing.T) {
+	var v ordered
+	// {"LONG": 1, "extra": [1], "a": 3}
+	data := mustHex("a3 64 4c 4f 4e 47 01 65 65 78 74 72 61 81 01 61 61 03")
+	if err := (&codec{}).Unmarshal(context.Background(), data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := (ordered{Long: 1, A: 3}); v != want {
+		t.Errorf("got %+v, want %+v", v, want)
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		hex     string
+		v       interface{}
+		wantErr interface{}
+	}{
+		{name: "empty", hex: "", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "truncated", hex: "19 01", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "trailing", hex: "01 02", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "reserved info", hex: "1c", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "indefinite uint", hex: "1f", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "lone break", hex: "ff", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "bad chunk", hex: "5f 61 61 ff", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "odd indefinite map", hex: "bf 01 ff", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "huge length", hex: "9b 00 00 00 01 00 00 00 00", v: new([]int), wantErr: new(*SyntaxError)},
+		{name: "invalid simple", hex: "f8 18", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "bad date", hex: "c0 61 61", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "bad bignum", hex: "c2 01", v: new(interface{}), wantErr: new(*SyntaxError)},
+		{name: "type mismatch", hex: "61 61", v: new(int), wantErr: new(*UnmarshalTypeError)},
+		{name: "overflow", hex: "19 01 00", v: new(int8), wantErr: new(*UnmarshalTypeError)},
+		{name: "negative into uint", hex: "20", v: new(uint), wantErr: new(*UnmarshalTypeError)},
+		{name: "text into time", hex: "61 61", v: new(time.Time), wantErr: new(*UnmarshalTypeError)},
+		{name: "unhashable key", hex: "a1 81 01 01", v: new(map[interface{}]interface{}), wantErr: new(*SyntaxError)},
+		{name: "unhashable map key", hex: "a1 a1 01 01 01", v: new(map[interface{}]interface{}), wantErr: new(*SyntaxError)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&codec{}).Unmarshal(context.Background(), mustHex(tt.hex), tt.v)
+			if err == nil {
+				t.Fatal("want error, got nil")
+			}
+			if !errors.As(err, tt.wantErr) {
+				t.Errorf("got error %T %v, want %T", err, err, reflect.ValueOf(tt.wantErr).Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestDecoder_NonPointer(t *testing.T) {
+	var v int
+	if err := (&codec{}).Unmarshal(context.Background(), []byte{0x01}, v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+	if err := (&codec{}).Unmarshal(context.Background(), []byte{0x01}, (*int)(nil)); err == nil {
+		t.Error("want error of nil pointer")
+	}
+}
+
+func TestDecoder_MaxDepth(t *testing.T) {
+	data := make([]byte, maxDepth+2)
+	for i := range data {
+		data[i] = 0x81
+	}
+	data[len(data)-1] = 0x01
+	var v interface{}
+	if err := (&codec{}).Unmarshal(context.Background(), data, &v); err == nil {
+		t.Error("want error of exceeding max depth")
+	}
+	// Tags nest their content, as arrays and maps do.
+	tags := append(bytes.Repeat([]byte{0xd8, 0x64}, maxDepth+1), 0x01)
+	var n int
+	for _, v := range []interface{}{&v, &n, &struct{}{}} {
+		if err := (&codec{}).Unmarshal(context.Background(), tags, v); err == nil {
+			t.Errorf("%T: want error of exceeding max depth", v)
+		}
+	}
+	if err := (&codec{}).Unmarshal(context.Background(), tags[2:], &n); err != nil || n != 1 {
+		t.Errorf("got %d, %v, want 1", n, err)
+	}
+}
+
+func TestDecoder_LargeElements(t *testing.T) {
+	data := append(mustHex("9a 00 10 00 00"), bytes.Repeat([]byte{0x01}, 1<<20)...)
+	var blocks [][65536]byte
+	var typeErr *UnmarshalTypeError
+	if err := (&codec{}).Unmarshal(context.Background(), data, &blocks); !errors.As(err, &typeErr) {
+		t.Errorf("got %v, want *UnmarshalTypeError", err)
+	}
+}
+
+func TestHalfToFloat(t *testing.T) {
+	tests := []struct {
+		h    uint16
+		want float64
+	}{
+		{0x0000, 0},
+		{0x3c00, 1},
+		{0xc400, -4},
+		{0x7bff, 65504},
+		{0x0400, 0.00006103515625},
+		{0x7c00, math.Inf(1)},
+		{0xfc00, math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		if got := halfToFloat(tt.h); got != tt.want {
+			t.Errorf("halfToFloat(%#04x) = %v, want %v", tt.h, got, tt.want)
+		}
+	}
+	if !math.IsNaN(halfToFloat(0x7e00)) {
+		t.Error("halfToFloat(0x7e00) is not NaN")
+	}
+}
@@ -0,0 +1,364 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+// Marshaler is implemented by types which encode themselves into CBOR.
+// The returned data must be exactly one well-formed data item.
+type Marshaler interface {
+	MarshalCBOR() ([]byte, error)
+}
+
+// Major types.
+const (
+	majorUint   = 0
+	majorNegint = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// maxDepth is the maximum nesting depth of data items.
+const maxDepth = 1000
+
+var (
+	_marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	_timeType            = reflect.TypeOf(time.Time{})
+	_bigIntType          = reflect.TypeOf(big.Int{})
+	_decimalFractionType = reflect.TypeOf(DecimalFraction{})
+	_tagType             = reflect.TypeOf(Tag{})
+	_simpleType          = reflect.TypeOf(Simple(0))
+)
+
+type encoder struct {
+	config *EncoderConfig
+	buf    bytes.Buffer
+}
+
+func (e *encoder) encode(v interface{}) error {
+	return e.value(reflect.ValueOf(v), 0)
+}
+
+func (e *encoder) head(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		e.buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(major | 24)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(major | 25)
+		var b [2]byte
+		binary.BigEndian.PutUint16(b[:], uint16(n))
+		e.buf.Write(b[:])
+	case n <= math.MaxUint32:
+		e.buf.WriteByte(major | 26)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(n))
+		e.buf.Write(b[:])
+	default:
+		e.buf.WriteByte(major | 27)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], n)
+		e.buf.Write(b[:])
+	}
+}
+
+func (e *encoder) value(v reflect.Value, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("cbor: exceeded max nesting depth %d", maxDepth)
+	}
+	if !v.IsValid() {
+		e.buf.WriteByte(0xf6)
+		return nil
+	}
+	t := v.Type()
+	if t.Implements(_marshalerType) {
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			e.buf.WriteByte(0xf6)
+			return nil
+		}
+		data, err := v.Interface().(Marshaler).MarshalCBOR()
+		if err != nil {
+			return err
+		}
+		e.buf.Write(data)
+		return nil
+	}
+	switch t {
+	case _timeType:
+		return e.time(v.Interface().(time.Time))
+	case _bigIntType:
+		n := v.Interface().(big.Int)
+		e.bigInt(&n)
+		return nil
+	case _decimalFractionType:
+		f := v.Interface().(DecimalFraction)
+		e.head(majorTag, TagDecimalFraction)
+		e.head(majorArray, 2)
+		e.int(f.Exponent)
+		e.bigInt(&f.Mantissa)
+		return nil
+	case _tagType:
+		tag := v.Interface().(Tag)
+		e.head(majorTag, tag.Number)
+		return e.value(reflect.ValueOf(tag.Content), depth+1)
+	case _simpleType:
+		s := Simple(v.Uint())
+		if s >= 24 {
+			e.buf.WriteByte(0xf8)
+			e.buf.WriteByte(byte(s))
+		} else {
+			e.buf.WriteByte(0xe0 | byte(s))
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteByte(0xf6)
+			return nil
+		}
+		return e.value(v.Elem(), depth)
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf.WriteByte(0xf5)
+		} else {
+			e.buf.WriteByte(0xf4)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.head(majorUint, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.float(v.Float(), t.Bits())
+	case reflect.String:
+		e.head(majorText, uint64(v.Len()))
+		e.buf.WriteString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteByte(0xf6)
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.head(majorBytes, uint64(v.Len()))
+			e.buf.Write(v.Bytes())
+			return nil
+		}
+		return e.array(v, depth)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.head(majorBytes, uint64(v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				e.buf.WriteByte(byte(v.Index(i).Uint()))
+			}
+			return nil
+		}
+		return e.array(v, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteByte(0xf6)
+			return nil
+		}
+		return e.mapValue(v, depth)
+	case reflect.Struct:
+		return e.structValue(v, depth)
+	default:
+		return fmt.Errorf("cbor: unsupported type %s", t)
+	}
+	return nil
+}
+
+func (e *encoder) int(n int64) {
+	if n < 0 {
+		e.head(majorNegint, uint64(-(n + 1)))
+	} else {
+		e.head(majorUint, uint64(n))
+	}
+}
+
+// bigInt encodes n as an integer if it fits, otherwise as a bignum.
+func (e *encoder) bigInt(n *big.Int) {
+	if n.IsUint64() {
+		e.head(majorUint, n.Uint64())
+		return
+	}
+	if n.Sign() < 0 {
+		m := new(big.Int).Neg(n)
+		m.Sub(m, big.NewInt(1))
+		if m.IsUint64() {
+			e.head(majorNegint, m.Uint64())
+			return
+		}
+		e.head(majorTag, TagNegativeBignum)
+		b := m.Bytes()
+		e.head(majorBytes, uint64(len(b)))
+		e.buf.Write(b)
+		return
+	}
+	e.head(majorTag, TagPositiveBignum)
+	b := n.Bytes()
+	e.head(majorBytes, uint64(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *encoder) float(f float64, bits int) {
+	if e.config.Deterministic {
+		if math.IsNaN(f) {
+			e.buf.Write([]byte{0xf9, 0x7e, 0x00})
+			return
+		}
+		if f32 := float32(f); float64(f32) == f {
+			if h, ok := float16(f32); ok {
+				e.buf.WriteByte(0xf9)
+				e.buf.Write([]byte{byte(h >> 8), byte(h)})
+				return
+			}
+			bits = 32
+		} else {
+			bits = 64
+		}
+	}
+	if bits == 32 {
+		e.buf.WriteByte(0xfa)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], math.Float32bits(float32(f)))
+		e.buf.Write(b[:])
+		return
+	}
+	e.buf.WriteByte(0xfb)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(f))
+	e.buf.Write(b[:])
+}
+
+// float16 converts f to a half-precision float if it can be done exactly.
+func float16(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+	switch {
+	case exp == 0 && mant == 0:
+		return sign, true
+	case exp == 0xff:
+		if mant == 0 {
+			return sign | 0x7c00, true
+		}
+		return 0, false
+	case exp == 0:
+		return 0, false
+	}
+	e := exp - 127
+	switch {
+	case e >= -14 && e <= 15:
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(e+15)<<10 | uint16(mant>>13), true
+	case e >= -24 && e < -14:
+		full := mant | 1<<23
+		shift := uint(-(e + 1))
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	}
+	return 0, false
+}
+
+func (e *encoder) time(t time.Time) error {
+	if e.config.TimeFormat == TimeEpoch {
+		e.head(majorTag, TagEpochDateTime)
+		if t.Nanosecond() == 0 {
+			e.int(t.Unix())
+		} else {
+			e.float(float64(t.UnixNano())/1e9, 64)
+		}
+		return nil
+	}
+	e.head(majorTag, TagDateTimeString)
+	s := t.Format(time.RFC3339Nano)
+	e.head(majorText, uint64(len(s)))
+	e.buf.WriteString(s)
+	return nil
+}
+
+func (e *encoder) array(v reflect.Value, depth int) error {
+	e.head(majorArray, uint64(v.Len()))
+	for i := 0; i < v.Len(); i++ {
+		if err := e.value(v.Index(i), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type entry struct {
+	key   []byte
+	value reflect.Value
+	name  string
+}
+
+func (e *encoder) entries(entries []entry, depth int) error {
+	e.head(majorMap, uint64(len(entries)))
+	for _, en := range entries {
+		e.buf.Write(en.key)
+		if err := e.value(en.value, depth+1); err != nil {
+			if en.name != "" {
+				return fmt.Errorf("cbor: field %s: %w", en.name, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// mapValue encodes a map with entries sorted by the encoding of keys, so that
+// the result is deterministic.
+func (e *encoder) mapValue(v reflect.Value, depth int) error {
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := &encoder{config: e.config}
+		if err := k.value(iter.Key(), depth+1); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: k.buf.Bytes(), value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+	return e.entries(entries, depth)
+}
+
+func (e *encoder) structValue(v reflect.Value, depth int) error {
+	fs := fields.Of(v.Type(), _tags...)
+	entries := make([]entry, 0, len(fs))
+	for _, f := range fs {
+		fv, ok := fields.ByIndex(v, f.Index, false)
+		if !ok || f.OmitEmpty() && fields.IsEmpty(fv) {
+			continue
+		}
+		k := &encoder{config: e.config}
+		k.head(majorText, uint64(len(f.Name)))
+		k.buf.WriteString(f.Name)
+		entries = append(entries, entry{key: k.buf.Bytes(), value: fv, name: f.Name})
+	}
+	if e.config.Deterministic {
+		sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+	}
+	return e.entries(entries, depth)
+}
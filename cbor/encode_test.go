@@ -0,0 +1,116 @@
+package cbor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type custom struct{}
+
+func (custom) MarshalCBOR() ([]byte, error) {
+	return []byte{0x63, 'a', 'b', 'c'}, nil
+}
+
+type failing struct{}
+
+func (failing) MarshalCBOR() ([]byte, error) {
+	return nil, errors.New("cbor: failing")
+}
+
+type ordered struct {
+	Long  int `cbor:"long"`
+	B     int `cbor:"b"`
+	A     int `cbor:"a"`
+	Empty int `cbor:"empty,omitempty"`
+}
+
+func TestEncoder(t *testing.T) {
+	at := time.Date(2021, 6, 1, 8, 0, 0, 500000000, time.UTC)
+	tests := []struct {
+		name    string
+		opt     []EncoderOption
+		v       interface{}
+		hex     string
+		wantErr bool
+	}{
+		{name: "float32", v: float32(1), hex: "fa 3f 80 00 00"},
+		{name: "float64", v: 1.0, hex: "fb 3f f0 00 00 00 00 00 00"},
+		{name: "deterministic float16", opt: []EncoderOption{Deterministic()}, v: float32(1), hex: "f9 3c 00"},
+		{name: "deterministic float32", opt: []EncoderOption{Deterministic()}, v: 0.5 + 1.0/(1<<20), hex: "fa 3f 00 00 10"},
+		{name: "deterministic subnormal", opt: []EncoderOption{Deterministic()}, v: 6.103515625e-05 / 2, hex: "f9 02 00"},
+		{name: "deterministic NaN", opt: []EncoderOption{Deterministic()}, v: math.NaN(), hex: "f9 7e 00"},
+		{name: "struct order", v: ordered{Long: 1, B: 2, A: 3}, hex: "a3 64 6c 6f 6e 67 01 61 62 02 61 61 03"},
+		{name: "deterministic struct order", opt: []EncoderOption{Deterministic()}, v: ordered{Long: 1, B: 2, A: 3}, hex: "a3 61 61 03 61 62 02 64 6c 6f 6e 67 01"},
+		{name: "map order", v: map[string]int{"long": 1, "b": 2, "a": 3}, hex: "a3 61 61 03 61 62 02 64 6c 6f 6e 67 01"},
+		{name: "time RFC3339", v: at, hex: "c0 76 32 30 32 31 2d 30 36 2d 30 31 54 30 38 3a 30 30 3a 30 30 2e 35 5a"},
+		{name: "time epoch", opt: []EncoderOption{WithTimeFormat(TimeEpoch)}, v: at.Truncate(time.Second), hex: "c1 1a 60 b5 e9 00"},
+		{name: "time epoch fraction", opt: []EncoderOption{WithTimeFormat(TimeEpoch)}, v: at, hex: "c1 fb 41 d8 2d 7a 40 20 00 00"},
+		{name: "big int fits", v: big.NewInt(-2), hex: "21"},
+		{name: "decimal fraction", v: DecimalFraction{Exponent: -2, Mantissa: *big.NewInt(27315)}, hex: "c4 82 21 19 6a b3"},
+		{name: "byte array", v: [2]byte{1, 2}, hex: "42 01 02"},
+		{name: "nil slice", v: []int(nil), hex: "f6"},
+		{name: "nil map", v: map[string]int(nil), hex: "f6"},
+		{name: "nil pointer", v: (*int)(nil), hex: "f6"},
+		{name: "marshaler", v: custom{}, hex: "63 61 62 63"},
+		{name: "nil marshaler", v: (*custom)(nil), hex: "f6"},
+		{name: "failing marshaler", v: failing{}, wantErr: true},
+		{name: "unsupported", v: make(chan int), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := contextWithEncoderOption(context.Background(), tt.opt...)
+			got, err := (&codec{}).Marshal(ctx, tt.v)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want error, got % x", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustHex(tt.hex); !reflect.DeepEqual(got, want) {
+				t.Errorf("got % x, want % x", got, want)
+			}
+		})
+	}
+}
+
+func TestEncoder_MaxDepth(t *testing.T) {
+	var v interface{}
+	for i := 0; i <= maxDepth+1; i++ {
+		v = []interface{}{v}
+	}
+	if _, err := (&codec{}).Marshal(context.Background(), v); err == nil {
+		t.Error("want error of exceeding max depth")
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	tests := []struct {
+		f    float32
+		want uint16
+		ok   bool
+	}{
+		{0, 0x0000, true},
+		{1, 0x3c00, true},
+		{-2, 0xc000, true},
+		{65504, 0x7bff, true},
+		{65536, 0, false},
+		{5.9604645e-08, 0x0001, true},
+		{1.1, 0, false},
+		{float32(math.Inf(1)), 0x7c00, true},
+		{float32(math.NaN()), 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := float16(tt.f)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf("float16(%v) = %#04x, %v, want %#04x, %v", tt.f, got, ok, tt.want, tt.ok)
+		}
+	}
+}
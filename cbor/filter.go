@@ -0,0 +1,88 @@
+package cbor
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// TimeFormat is the format of encoding time.Time.
+type TimeFormat uint8
+
+// Formats of encoding time.Time.
+const (
+	// TimeRFC3339 encodes time.Time as tag 0 of a RFC 3339 string.
+	TimeRFC3339 TimeFormat = iota
+	// TimeEpoch encodes time.Time as tag 1 of seconds since the epoch, an
+	// integer if there are no fractional seconds, otherwise a float.
+	TimeEpoch
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Deterministic enables the core deterministic encoding requirements of
+	// RFC 8949 section 4.2.1: map keys, including field names of structs, are
+	// sorted in the bytewise lexicographic order of their encodings, and
+	// floats are encoded in the shortest form preserving their values.
+	// Integers and lengths are always encoded in the shortest form, and
+	// indefinite length is never used.
+	Deterministic bool
+	// TimeFormat is the format of encoding time.Time.
+	TimeFormat TimeFormat
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// Deterministic produces an EncoderOption which enables core deterministic
+// encoding, suitable for signing.
+func Deterministic() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Deterministic = true
+	}
+}
+
+// WithTimeFormat produces an EncoderOption which sets the format of encoding
+// time.Time.
+func WithTimeFormat(format TimeFormat) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.TimeFormat = format
+	}
+}
@@ -0,0 +1,29 @@
+package cbor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, Deterministic(), WithTimeFormat(TimeEpoch))
+	got, err := m.Marshal(context.Background(), []interface{}{1.5, time.Unix(1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustHex("82 f9 3e 00 c1 01"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestEncoderOptionFromContext(t *testing.T) {
+	if opt := encoderOptionFromContext(context.Background()); opt != nil {
+		t.Errorf("want nil options, got %v", opt)
+	}
+	ctx := contextWithEncoderOption(context.Background(), Deterministic())
+	if opt := encoderOptionFromContext(ctx); len(opt) != 1 {
+		t.Errorf("want 1 option, got %d", len(opt))
+	}
+}
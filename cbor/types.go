@@ -0,0 +1,29 @@
+package cbor
+
+import (
+	"math/big"
+)
+
+// Tag numbers the codec handles.
+const (
+	TagDateTimeString  = 0
+	TagEpochDateTime   = 1
+	TagPositiveBignum  = 2
+	TagNegativeBignum  = 3
+	TagDecimalFraction = 4
+)
+
+// Tag is a tagged data item of a tag number the codec does not handle.
+type Tag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// DecimalFraction is a decimal fraction, whose value is Mantissa*10^Exponent.
+type DecimalFraction struct {
+	Exponent int64
+	Mantissa big.Int
+}
+
+// Simple is a simple value other than false, true, null and undefined.
+type Simple uint8
@@ -0,0 +1,130 @@
+package cloudevents
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// headerPrefix is the prefix of headers of attributes in the binary content
+// mode of the HTTP binding.
+const headerPrefix = "ce-"
+
+// EncodeBinary converts an event into the headers and the body of the binary
+// content mode of the HTTP binding, after validating the attributes. Each
+// attribute and extension is a "ce-" header, except datacontenttype which is
+// Content-Type, set to DefaultDataContentType for data without content type.
+func EncodeBinary(ctx context.Context, e *Event) (http.Header, []byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	version := e.SpecVersion
+	if version == "" {
+		version = SpecVersion
+	}
+	for _, attr := range []struct{ name, value string }{
+		{"specversion", version},
+		{"id", e.ID},
+		{"source", e.Source},
+		{"type", e.Type},
+		{"subject", e.Subject},
+		{"dataschema", e.DataSchema},
+	} {
+		if attr.value != "" {
+			header.Set(headerPrefix+attr.name, encodeHeader(attr.value))
+		}
+	}
+	if !e.Time.IsZero() {
+		header.Set(headerPrefix+"time", e.Time.Format(time.RFC3339Nano))
+	}
+	for name, value := range e.Extensions {
+		s, _ := formatExtension(value)
+		header.Set(headerPrefix+name, encodeHeader(s))
+	}
+	var body []byte
+	if e.Data != nil {
+		data, err := encodeData(ctx, e.DataContentType, e.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		body = data
+	}
+	if contentType := e.DataContentType; contentType != "" {
+		header.Set("Content-Type", contentType)
+	} else if e.Data != nil {
+		header.Set("Content-Type", DefaultDataContentType)
+	}
+	return header, body, nil
+}
+
+// DecodeBinary converts the headers and the body of the binary content mode of
+// the HTTP binding into an event, and validates it. Extensions are read as
+// strings, and a non-empty body as the data.
+func DecodeBinary(header http.Header, body []byte) (*Event, error) {
+	e := &Event{DataContentType: header.Get("Content-Type")}
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if !strings.HasPrefix(name, headerPrefix) || len(values) == 0 {
+			continue
+		}
+		name = name[len(headerPrefix):]
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("cloudevents: header %s: %w", key, err)
+		}
+		switch name {
+		case "specversion":
+			e.SpecVersion = value
+		case "id":
+			e.ID = value
+		case "source":
+			e.Source = value
+		case "type":
+			e.Type = value
+		case "subject":
+			e.Subject = value
+		case "dataschema":
+			e.DataSchema = value
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("cloudevents: invalid time %q", value)
+			}
+			e.Time = t
+		default:
+			if e.Extensions == nil {
+				e.Extensions = map[string]interface{}{}
+			}
+			e.Extensions[name] = value
+		}
+	}
+	if e.SpecVersion == "" {
+		return nil, fmt.Errorf("cloudevents: missing required attribute specversion")
+	}
+	if len(body) > 0 {
+		e.Data = append([]byte(nil), body...)
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// encodeHeader percent-encodes the space, double quote, percent and the
+// characters outside printable ASCII of a header value.
+func encodeHeader(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c == '"' || c == '%' || c >= 0x7f {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
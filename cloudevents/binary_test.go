@@ -0,0 +1,116 @@
+package cloudevents
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeBinary(t *testing.T) {
+	ctx := context.Background()
+	e := &Event{
+		ID: "a", Source: "/s", Type: "t", Subject: "café 100%", Time: time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC),
+		Extensions: map[string]interface{}{"seq": 7, "note": `"q"`},
+		Data:       map[string]int{"n": 1},
+	}
+	header, body, err := EncodeBinary(ctx, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := http.Header{
+		"Ce-Specversion": {"1.0"},
+		"Ce-Id":          {"a"},
+		"Ce-Source":      {"/s"},
+		"Ce-Type":        {"t"},
+		"Ce-Subject":     {"caf%C3%A9%20100%25"},
+		"Ce-Time":        {"2021-06-01T08:30:00Z"},
+		"Ce-Seq":         {"7"},
+		"Ce-Note":        {"%22q%22"},
+		"Content-Type":   {"application/json"},
+	}
+	if !reflect.DeepEqual(header, want) {
+		t.Errorf("got %v\nwant %v", header, want)
+	}
+	if string(body) != `{"n":1}` {
+		t.Errorf("got body %s", body)
+	}
+
+	header, body, err = EncodeBinary(ctx, &Event{ID: "a", Source: "/s", Type: "t"})
+	if err != nil || header.Get("Content-Type") != "" || body != nil {
+		t.Errorf("got %v, %q, %v", header, body, err)
+	}
+	if _, _, err := EncodeBinary(ctx, &Event{Source: "/s", Type: "t"}); err == nil {
+		t.Error("encoded event without id")
+	}
+}
+
+func TestDecodeBinary(t *testing.T) {
+	header := http.Header{}
+	header.Set("ce-specversion", "1.0")
+	header.Set("ce-id", "a")
+	header.Set("ce-source", "/s")
+	header.Set("ce-type", "t")
+	header.Set("ce-subject", "caf%C3%A9+1")
+	header.Set("ce-time", "2021-06-01T08:30:00Z")
+	header.Set("ce-traceparent", "00-1")
+	header.Set("Content-Type", "text/plain")
+	header.Set("X-Other", "ignored")
+	e, err := DecodeBinary(header, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Event{
+		SpecVersion: "1.0", ID: "a", Source: "/s", Type: "t", Subject: "café+1",
+		Time: time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC), DataContentType: "text/plain",
+		Extensions: map[string]interface{}{"traceparent": "00-1"},
+		Data:       []byte("hello"),
+	}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("got %#v\nwant %#v", e, want)
+	}
+
+	tests := []struct {
+		modify func(h http.Header)
+		err    string
+	}{
+		{func(h http.Header) { h.Del("ce-specversion") }, "cloudevents: missing required attribute specversion"},
+		{func(h http.Header) { h.Del("ce-type") }, "cloudevents: missing required attribute type"},
+		{func(h http.Header) { h.Set("ce-time", "now") }, `cloudevents: invalid time "now"`},
+		{func(h http.Header) { h.Set("ce-subject", "%zz") }, "cloudevents: header Ce-Subject: "},
+		{func(h http.Header) { h.Set("ce-not-valid", "x") }, `cloudevents: invalid extension name "not-valid"`},
+	}
+	for i, test := range tests {
+		h := header.Clone()
+		test.modify(h)
+		_, err := DecodeBinary(h, nil)
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%d: got %v, want %q", i, err, test.err)
+		}
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	e := &Event{ID: "a", Source: "/s", Type: "t", DataContentType: "application/xml", Data: note{Text: "hi"}}
+	header, body, err := EncodeBinary(ctx, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeBinary(header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n note
+	if err := got.DecodeData(ctx, &n); err != nil || n.Text != "hi" {
+		t.Errorf("got %+v, %v", n, err)
+	}
+}
+
+func TestEncodeHeader(t *testing.T) {
+	if got, want := encodeHeader("a b\"%\x01ü~"), "a%20b%22%25%01%C3%BC~"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+// Package cloudevents defines and registers Marshaler/Unmarshaler handling
+// CloudEvents 1.0 in the structured content mode of the JSON event format
+// (application/cloudevents+json), and converts events from and to the binary
+// content mode of the HTTP binding by EncodeBinary and DecodeBinary.
+//
+// The data of an event is encoded by the codec registered for its
+// datacontenttype, application/json if empty: by the media type itself such as
+// "application/cbor", then by the structured syntax suffix such as "json" of
+// "application/problem+json", then by the subtype such as "xml" of "text/xml".
+// []byte is taken as data already encoded. JSON data is embedded into the
+// structured event as it is, textual data as a string, and the others as
+// base64. Data read from an event is kept as []byte of the content type, and
+// decoded later by Event.DecodeData into a type chosen by the caller.
+package cloudevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kita/encoding"
+	// the codec of data of the default content type.
+	_ "github.com/go-kita/encoding/json"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "cloudevents"
+
+// MIMEType is the media type of events in the structured content mode of the
+// JSON event format.
+const MIMEType = "application/cloudevents+json"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshal encodes an Event or *Event into a JSON object of its attributes,
+// extensions and data, after validating the attributes.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	switch e := v.(type) {
+	case Event:
+		return marshalStructured(ctx, &e)
+	case *Event:
+		if e != nil {
+			return marshalStructured(ctx, e)
+		}
+	}
+	return nil, fmt.Errorf("cloudevents: unsupported type %T", v)
+}
+
+// Unmarshal decodes a JSON object into *Event, validating the attributes.
+func (c *codec) Unmarshal(_ context.Context, data []byte, v interface{}) error {
+	e, ok := v.(*Event)
+	if !ok || e == nil {
+		return fmt.Errorf("cloudevents: can not unmarshal to %T, want *Event", v)
+	}
+	return unmarshalStructured(data, e)
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,50 @@
+package cloudevents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+func TestCodec(t *testing.T) {
+	ctx := context.Background()
+	c := &codec{}
+	e := Event{ID: "1", Source: "/orders", Type: "order.created", Data: map[string]int{"total": 3}}
+	data, err := c.Marshal(ctx, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"specversion":"1.0","id":"1","source":"/orders","type":"order.created","data":{"total":3}}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+	var got Event
+	if err := c.Unmarshal(ctx, data, &got); err != nil {
+		t.Fatal(err)
+	}
+	var payload struct{ Total int }
+	if err := got.DecodeData(ctx, &payload); err != nil || payload.Total != 3 {
+		t.Errorf("got data %+v, %v", payload, err)
+	}
+
+	for _, v := range []interface{}{nil, (*Event)(nil), map[string]string{}} {
+		if _, err := c.Marshal(ctx, v); err == nil {
+			t.Errorf("%T: marshaled unsupported type", v)
+		}
+	}
+	if _, err := c.Marshal(ctx, &Event{ID: "1", Source: "/s"}); err == nil {
+		t.Error("marshaled event without type")
+	}
+	if err := c.Unmarshal(ctx, data, got); err == nil {
+		t.Error("unmarshaled to non-pointer")
+	}
+}
@@ -0,0 +1,112 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/go-kita/encoding"
+)
+
+// DefaultDataContentType is the content type of data by default.
+const DefaultDataContentType = "application/json"
+
+// mediaType returns the media type of a content type without parameters, in
+// lower case.
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return DefaultDataContentType
+	}
+	if t, _, err := mime.ParseMediaType(contentType); err == nil {
+		return t
+	}
+	t := strings.SplitN(contentType, ";", 2)[0]
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// codecNames returns the names the codec of a content type is looked up by:
+// the media type, the structured syntax suffix and the subtype.
+func codecNames(contentType string) []string {
+	t := mediaType(contentType)
+	names := []string{t}
+	subtype := t[strings.Index(t, "/")+1:]
+	if i := strings.LastIndex(subtype, "+"); i >= 0 {
+		names = append(names, subtype[i+1:])
+		subtype = subtype[:i]
+	}
+	return append(names, subtype, strings.TrimPrefix(subtype, "x-"))
+}
+
+// isJSON reports whether the content type is JSON.
+func isJSON(contentType string) bool {
+	t := mediaType(contentType)
+	return t == "application/json" || t == "text/json" || strings.HasSuffix(t, "+json")
+}
+
+// isText reports whether data of the content type is text, which is embedded
+// into structured events as a string rather than base64.
+func isText(contentType string) bool {
+	t := mediaType(contentType)
+	if strings.HasPrefix(t, "text/") {
+		return true
+	}
+	for _, name := range codecNames(contentType)[1:] {
+		switch name {
+		case "xml", "yaml", "csv", "javascript":
+			return true
+		}
+	}
+	return false
+}
+
+// encodeData encodes data by the codec of the content type. []byte is taken as
+// encoded, and so is a string unless the content type is JSON.
+func encodeData(ctx context.Context, contentType string, data interface{}) ([]byte, error) {
+	switch d := data.(type) {
+	case []byte:
+		return d, nil
+	case string:
+		if !isJSON(contentType) {
+			return []byte(d), nil
+		}
+	}
+	for _, name := range codecNames(contentType) {
+		if m := encoding.GetMarshaler(name); m != nil {
+			encoded, err := m.Marshal(ctx, data)
+			if err != nil {
+				return nil, fmt.Errorf("cloudevents: data: %w", err)
+			}
+			if isJSON(contentType) {
+				encoded = bytes.TrimRight(encoded, "\n")
+			}
+			return encoded, nil
+		}
+	}
+	return nil, fmt.Errorf("cloudevents: no codec for data content type %q", contentType)
+}
+
+// decodeData decodes data by the codec of the content type, or copies it into
+// *[]byte or *string.
+func decodeData(ctx context.Context, contentType string, data []byte, v interface{}) error {
+	switch vv := v.(type) {
+	case *[]byte:
+		*vv = append([]byte(nil), data...)
+		return nil
+	case *string:
+		if !isJSON(contentType) {
+			*vv = string(data)
+			return nil
+		}
+	}
+	for _, name := range codecNames(contentType) {
+		if u := encoding.GetUnmarshaler(name); u != nil {
+			if err := u.Unmarshal(ctx, data, v); err != nil {
+				return fmt.Errorf("cloudevents: data: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("cloudevents: no codec for data content type %q", contentType)
+}
@@ -0,0 +1,106 @@
+package cloudevents
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	_ "github.com/go-kita/encoding/xml"
+)
+
+func TestCodecNames(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        []string
+	}{
+		{"", []string{"application/json", "json", "json"}},
+		{"application/cbor", []string{"application/cbor", "cbor", "cbor"}},
+		{"Application/Problem+JSON; charset=utf-8", []string{"application/problem+json", "json", "problem", "problem"}},
+		{"application/x-yaml", []string{"application/x-yaml", "x-yaml", "yaml"}},
+		{"text/xml;", []string{"text/xml", "xml", "xml"}},
+	}
+	for _, test := range tests {
+		if got := codecNames(test.contentType); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %q, want %q", test.contentType, got, test.want)
+		}
+	}
+}
+
+func TestContentTypes(t *testing.T) {
+	tests := []struct {
+		contentType  string
+		json, isText bool
+	}{
+		{"", true, false},
+		{"application/json", true, false},
+		{"application/ld+json", true, false},
+		{"text/plain; charset=utf-8", false, true},
+		{"application/xml", false, true},
+		{"application/atom+xml", false, true},
+		{"application/x-yaml", false, true},
+		{"application/octet-stream", false, false},
+	}
+	for _, test := range tests {
+		if got := isJSON(test.contentType); got != test.json {
+			t.Errorf("isJSON(%q) = %v", test.contentType, got)
+		}
+		if got := isText(test.contentType); got != test.isText {
+			t.Errorf("isText(%q) = %v", test.contentType, got)
+		}
+	}
+}
+
+type note struct {
+	Text string `xml:"text"`
+}
+
+func TestEncodeData(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		contentType string
+		data        interface{}
+		want        string
+	}{
+		{"", map[string]bool{"ok": true}, `{"ok":true}`},
+		{"application/json", "s", `"s"`},
+		{"text/plain", "s", "s"},
+		{"application/octet-stream", []byte{0, 1}, "\x00\x01"},
+		{"application/json", []byte(`{"raw":1}`), `{"raw":1}`},
+		{"application/xml", note{Text: "hi"}, "<note><text>hi</text></note>"},
+	}
+	for _, test := range tests {
+		got, err := encodeData(ctx, test.contentType, test.data)
+		if err != nil || string(got) != test.want {
+			t.Errorf("%q: got %q, %v, want %q", test.contentType, got, err, test.want)
+		}
+	}
+	if _, err := encodeData(ctx, "application/unknown", struct{}{}); err == nil {
+		t.Error("encoded data without codec")
+	}
+}
+
+func TestDecodeData(t *testing.T) {
+	ctx := context.Background()
+	var n note
+	if err := decodeData(ctx, "text/xml", []byte("<note><text>hi</text></note>"), &n); err != nil || n.Text != "hi" {
+		t.Errorf("got %+v, %v", n, err)
+	}
+	var s string
+	if err := decodeData(ctx, "", []byte(`"quoted"`), &s); err != nil || s != "quoted" {
+		t.Errorf("got %q, %v", s, err)
+	}
+	data := []byte{1, 2}
+	var b []byte
+	if err := decodeData(ctx, "application/json", data, &b); err != nil || string(b) != "\x01\x02" {
+		t.Errorf("got %v, %v", b, err)
+	}
+	if b[0] = 9; data[0] != 1 {
+		t.Error("decoded data shares memory with the event")
+	}
+	if err := decodeData(ctx, "", []byte(`{`), &n); err == nil {
+		t.Error("decoded malformed data")
+	}
+	if err := decodeData(ctx, "application/unknown", data, &n); err == nil {
+		t.Error("decoded data without codec")
+	}
+}
@@ -0,0 +1,151 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// SpecVersion is the version of the specification of events.
+const SpecVersion = "1.0"
+
+// Event is an event with its context attributes and data.
+type Event struct {
+	// SpecVersion is the version of the specification, SpecVersion if empty
+	// when written.
+	SpecVersion string
+	// ID identifies the event, unique for the source. It is required.
+	ID string
+	// Source is the URI-reference of the context the event happened in. It is
+	// required.
+	Source string
+	// Type is the type of the event, such as "com.example.order.created". It
+	// is required.
+	Type string
+	// Subject is the subject of the event in the context of the source.
+	Subject string
+	// DataSchema is the absolute URI of the schema the data adheres to.
+	DataSchema string
+	// Time is the time the event happened, omitted if zero.
+	Time time.Time
+	// DataContentType is the media type of the data, application/json if
+	// empty.
+	DataContentType string
+	// Extensions are the extension attributes by name, which consists of
+	// lower-case letters and digits. Values are strings, booleans, integers
+	// in the range of int32, []byte, time.Time or *url.URL. Extensions read
+	// from an event are strings, booleans and ints, or only strings from
+	// binary content mode.
+	Extensions map[string]interface{}
+	// Data is the data of the event, omitted if nil. Data read from an event
+	// is []byte of the content type, see DecodeData.
+	Data interface{}
+}
+
+// DecodeData decodes the data read from an event into v by the codec of the
+// content type, or copies it into *[]byte or *string. v is left unchanged if
+// the event has no data.
+func (e *Event) DecodeData(ctx context.Context, v interface{}) error {
+	if e.Data == nil {
+		return nil
+	}
+	data, ok := e.Data.([]byte)
+	if !ok {
+		return fmt.Errorf("cloudevents: can not decode %T, which is not read from an event", e.Data)
+	}
+	return decodeData(ctx, e.DataContentType, data, v)
+}
+
+// reserved are the names of attributes and members which are not extensions.
+var reserved = map[string]bool{
+	"specversion": true, "id": true, "source": true, "type": true, "subject": true,
+	"dataschema": true, "time": true, "datacontenttype": true, "data": true, "data_base64": true,
+}
+
+// Validate checks the attributes against the specification: the required
+// ones, URIs, and names and values of extensions.
+func (e *Event) Validate() error {
+	if e.SpecVersion != "" && e.SpecVersion != SpecVersion {
+		return fmt.Errorf("cloudevents: unsupported specversion %q", e.SpecVersion)
+	}
+	for _, attr := range []struct{ name, value string }{{"id", e.ID}, {"source", e.Source}, {"type", e.Type}} {
+		if attr.value == "" {
+			return fmt.Errorf("cloudevents: missing required attribute %s", attr.name)
+		}
+	}
+	if _, err := url.Parse(e.Source); err != nil {
+		return fmt.Errorf("cloudevents: invalid source %q", e.Source)
+	}
+	if e.DataSchema != "" {
+		if u, err := url.Parse(e.DataSchema); err != nil || !u.IsAbs() {
+			return fmt.Errorf("cloudevents: invalid dataschema %q", e.DataSchema)
+		}
+	}
+	for name, value := range e.Extensions {
+		if !validName(name) || reserved[name] {
+			return fmt.Errorf("cloudevents: invalid extension name %q", name)
+		}
+		if _, err := formatExtension(value); err != nil {
+			return fmt.Errorf("cloudevents: extension %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validName reports whether name consists of lower-case letters and digits.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// formatExtension returns the canonical string of an extension value.
+func formatExtension(v interface{}) (string, error) {
+	switch vv := v.(type) {
+	case string:
+		return vv, nil
+	case bool:
+		return strconv.FormatBool(vv), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(vv), nil
+	case time.Time:
+		return vv.Format(time.RFC3339Nano), nil
+	case *url.URL:
+		if vv != nil {
+			return vv.String(), nil
+		}
+	}
+	if n, ok := integer(v); ok {
+		return strconv.FormatInt(n, 10), nil
+	}
+	return "", fmt.Errorf("unsupported value %T", v)
+}
+
+// integer returns the value of an integer in the range of int32.
+func integer(v interface{}) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	var n int64
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt32 {
+			return 0, false
+		}
+		n = int64(rv.Uint())
+	default:
+		return 0, false
+	}
+	return n, n >= math.MinInt32 && n <= math.MaxInt32
+}
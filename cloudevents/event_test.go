@@ -0,0 +1,96 @@
+package cloudevents
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent_Validate(t *testing.T) {
+	valid := func() Event {
+		return Event{ID: "1", Source: "urn:s", Type: "t"}
+	}
+	tests := []struct {
+		modify func(e *Event)
+		err    string
+	}{
+		{func(e *Event) {}, ""},
+		{func(e *Event) { e.SpecVersion = "1.0" }, ""},
+		{func(e *Event) { e.SpecVersion = "0.3" }, `unsupported specversion "0.3"`},
+		{func(e *Event) { e.ID = "" }, "missing required attribute id"},
+		{func(e *Event) { e.Source = "" }, "missing required attribute source"},
+		{func(e *Event) { e.Type = "" }, "missing required attribute type"},
+		{func(e *Event) { e.Source = "%zz" }, `invalid source "%zz"`},
+		{func(e *Event) { e.DataSchema = "/relative" }, `invalid dataschema "/relative"`},
+		{func(e *Event) { e.DataSchema = "https://example.com/schema" }, ""},
+		{func(e *Event) {
+			u, _ := url.Parse("https://example.com")
+			e.Extensions = map[string]interface{}{"a": "s", "b": true, "c": int64(-5), "d": []byte{1}, "e": time.Now(), "f": u}
+		}, ""},
+		{func(e *Event) { e.Extensions = map[string]interface{}{"Upper": "s"} }, `invalid extension name "Upper"`},
+		{func(e *Event) { e.Extensions = map[string]interface{}{"with-dash": "s"} }, `invalid extension name "with-dash"`},
+		{func(e *Event) { e.Extensions = map[string]interface{}{"data": "s"} }, `invalid extension name "data"`},
+		{func(e *Event) { e.Extensions = map[string]interface{}{"big": int64(1) << 40} }, "extension big: unsupported value int64"},
+		{func(e *Event) { e.Extensions = map[string]interface{}{"f": 1.5} }, "extension f: unsupported value float64"},
+	}
+	for i, test := range tests {
+		e := valid()
+		test.modify(&e)
+		err := e.Validate()
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%d: %v", i, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != "cloudevents: "+test.err {
+			t.Errorf("%d: got %v, want %q", i, err, test.err)
+		}
+	}
+}
+
+func TestFormatExtension(t *testing.T) {
+	u, _ := url.Parse("https://example.com/a")
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{"s", "s"},
+		{false, "false"},
+		{42, "42"},
+		{uint8(7), "7"},
+		{[]byte("hi"), "aGk="},
+		{time.Date(2021, 6, 1, 8, 0, 0, 5e8, time.UTC), "2021-06-01T08:00:00.5Z"},
+		{u, "https://example.com/a"},
+	}
+	for _, test := range tests {
+		got, err := formatExtension(test.v)
+		if err != nil || got != test.want {
+			t.Errorf("%#v: got %q, %v, want %q", test.v, got, err, test.want)
+		}
+	}
+	for _, v := range []interface{}{nil, (*url.URL)(nil), uint64(1) << 31, struct{}{}} {
+		if _, err := formatExtension(v); err == nil {
+			t.Errorf("%#v: formatted unsupported value", v)
+		}
+	}
+}
+
+func TestEvent_DecodeData(t *testing.T) {
+	ctx := context.Background()
+	e := &Event{DataContentType: "text/plain", Data: []byte("hello")}
+	var s string
+	if err := e.DecodeData(ctx, &s); err != nil || s != "hello" {
+		t.Errorf("got %q, %v", s, err)
+	}
+	e = &Event{Data: "set by caller"}
+	if err := e.DecodeData(ctx, &s); err == nil || !strings.Contains(err.Error(), "not read from an event") {
+		t.Errorf("got %v", err)
+	}
+	s = "unchanged"
+	if err := (&Event{}).DecodeData(ctx, &s); err != nil || s != "unchanged" {
+		t.Errorf("got %q, %v", s, err)
+	}
+}
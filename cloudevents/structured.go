@@ -0,0 +1,240 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// marshalStructured encodes an event into a JSON object, with the attributes
+// in the order of the specification and extensions sorted by name.
+func marshalStructured(ctx context.Context, e *Event) ([]byte, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	w := &objectWriter{}
+	version := e.SpecVersion
+	if version == "" {
+		version = SpecVersion
+	}
+	w.member("specversion", version)
+	w.member("id", e.ID)
+	w.member("source", e.Source)
+	w.member("type", e.Type)
+	for _, attr := range []struct{ name, value string }{
+		{"subject", e.Subject},
+		{"dataschema", e.DataSchema},
+		{"datacontenttype", e.DataContentType},
+	} {
+		if attr.value != "" {
+			w.member(attr.name, attr.value)
+		}
+	}
+	if !e.Time.IsZero() {
+		w.member("time", e.Time.Format(time.RFC3339Nano))
+	}
+	names := make([]string, 0, len(e.Extensions))
+	for name := range e.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := e.Extensions[name]
+		if b, ok := value.(bool); ok {
+			w.member(name, b)
+			continue
+		}
+		if n, ok := integer(value); ok {
+			w.member(name, n)
+			continue
+		}
+		s, _ := formatExtension(value)
+		w.member(name, s)
+	}
+	if e.Data != nil {
+		data, err := encodeData(ctx, e.DataContentType, e.Data)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case isJSON(e.DataContentType):
+			if !json.Valid(data) {
+				return nil, fmt.Errorf("cloudevents: data is not valid JSON")
+			}
+			w.raw("data", data)
+		case isText(e.DataContentType) && utf8.Valid(data):
+			w.member("data", string(data))
+		default:
+			w.member("data_base64", base64.StdEncoding.EncodeToString(data))
+		}
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	w.buf.WriteByte('}')
+	return w.buf.Bytes(), nil
+}
+
+// objectWriter writes members of a JSON object in order, keeping the first
+// error.
+type objectWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (w *objectWriter) member(name string, value interface{}) {
+	if w.err != nil {
+		return
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		w.err = err
+		return
+	}
+	w.raw(name, bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+func (w *objectWriter) raw(name string, value []byte) {
+	if w.buf.Len() == 0 {
+		w.buf.WriteByte('{')
+	} else {
+		w.buf.WriteByte(',')
+	}
+	key, _ := json.Marshal(name)
+	w.buf.Write(key)
+	w.buf.WriteByte(':')
+	w.buf.Write(value)
+}
+
+// unmarshalStructured decodes a JSON object into an event, and validates it.
+func unmarshalStructured(data []byte, e *Event) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return fmt.Errorf("cloudevents: %w", err)
+	}
+	if members == nil {
+		return fmt.Errorf("cloudevents: event is not an object")
+	}
+	var event Event
+	for _, attr := range []struct {
+		name  string
+		value *string
+	}{
+		{"specversion", &event.SpecVersion},
+		{"id", &event.ID},
+		{"source", &event.Source},
+		{"type", &event.Type},
+		{"subject", &event.Subject},
+		{"dataschema", &event.DataSchema},
+		{"datacontenttype", &event.DataContentType},
+	} {
+		raw, ok := members[attr.name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, attr.value); err != nil || isNull(raw) {
+			return fmt.Errorf("cloudevents: attribute %s is not a string", attr.name)
+		}
+	}
+	if event.SpecVersion == "" {
+		return fmt.Errorf("cloudevents: missing required attribute specversion")
+	}
+	if raw, ok := members["time"]; ok {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil || isNull(raw) {
+			return fmt.Errorf("cloudevents: attribute time is not a string")
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("cloudevents: invalid time %q", s)
+		}
+		event.Time = t
+	}
+	if err := readData(members, &event); err != nil {
+		return err
+	}
+	for name, raw := range members {
+		if reserved[name] {
+			continue
+		}
+		value, err := readExtension(raw)
+		if err != nil {
+			return fmt.Errorf("cloudevents: extension %s: %w", name, err)
+		}
+		if event.Extensions == nil {
+			event.Extensions = map[string]interface{}{}
+		}
+		event.Extensions[name] = value
+	}
+	if err := event.Validate(); err != nil {
+		return err
+	}
+	*e = event
+	return nil
+}
+
+// readData reads data as []byte of the content type from "data", embedded
+// JSON or a string, or from "data_base64". Null is no data.
+func readData(members map[string]json.RawMessage, e *Event) error {
+	raw, hasData := members["data"]
+	encoded, hasBase64 := members["data_base64"]
+	hasData = hasData && !isNull(raw)
+	hasBase64 = hasBase64 && !isNull(encoded)
+	switch {
+	case hasData && hasBase64:
+		return fmt.Errorf("cloudevents: both data and data_base64 are present")
+	case hasBase64:
+		var s string
+		if err := json.Unmarshal(encoded, &s); err != nil {
+			return fmt.Errorf("cloudevents: data_base64 is not a string")
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("cloudevents: data_base64: %w", err)
+		}
+		e.Data = data
+	case hasData && isJSON(e.DataContentType):
+		e.Data = []byte(append(json.RawMessage(nil), raw...))
+	case hasData:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("cloudevents: data of content type %q is not a string", e.DataContentType)
+		}
+		e.Data = []byte(s)
+	}
+	return nil
+}
+
+// readExtension reads an extension value, a string, a boolean or an integer
+// in the range of int32.
+func readExtension(raw json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	switch vv := v.(type) {
+	case string, bool:
+		return vv, nil
+	case json.Number:
+		n, err := vv.Int64()
+		if err != nil || n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("invalid integer %s", vv)
+		}
+		return int(n), nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", raw)
+}
+
+func isNull(data []byte) bool {
+	return string(data) == "null"
+}
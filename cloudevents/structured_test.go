@@ -0,0 +1,120 @@
+package cloudevents
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalStructured(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC)
+	tests := []struct {
+		e    Event
+		want string
+	}{
+		{
+			Event{ID: "a", Source: "/s", Type: "t", Subject: "sub", DataSchema: "urn:schema", Time: at,
+				Extensions: map[string]interface{}{"traceparent": "00-1", "seq": 7, "sampled": true, "raw": []byte("x")}},
+			`{"specversion":"1.0","id":"a","source":"/s","type":"t","subject":"sub","dataschema":"urn:schema",` +
+				`"time":"2021-06-01T08:30:00Z","raw":"eA==","sampled":true,"seq":7,"traceparent":"00-1"}`,
+		},
+		{
+			Event{ID: "a", Source: "/s", Type: "t", DataContentType: "application/cloudevents-data+json", Data: []int{1}},
+			`{"specversion":"1.0","id":"a","source":"/s","type":"t","datacontenttype":"application/cloudevents-data+json","data":[1]}`,
+		},
+		{
+			Event{ID: "a", Source: "/s", Type: "t", DataContentType: "text/plain", Data: "<hi>"},
+			`{"specversion":"1.0","id":"a","source":"/s","type":"t","datacontenttype":"text/plain","data":"<hi>"}`,
+		},
+		{
+			Event{ID: "a", Source: "/s", Type: "t", DataContentType: "application/octet-stream", Data: []byte{0xff}},
+			`{"specversion":"1.0","id":"a","source":"/s","type":"t","datacontenttype":"application/octet-stream","data_base64":"/w=="}`,
+		},
+	}
+	for _, test := range tests {
+		got, err := marshalStructured(ctx, &test.e)
+		if err != nil {
+			t.Errorf("%s: %v", test.want, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("got  %s\nwant %s", got, test.want)
+		}
+	}
+	e := &Event{ID: "a", Source: "/s", Type: "t", Data: []byte("not json")}
+	if _, err := marshalStructured(ctx, e); err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestUnmarshalStructured(t *testing.T) {
+	data := `{"specversion":"1.0","id":"a","source":"/s","type":"t","time":"2021-06-01T08:30:00.5+08:00",` +
+		`"datacontenttype":"application/json","data":{"n":1},"seq":7,"sampled":true,"traceparent":"00-1"}`
+	var e Event
+	if err := unmarshalStructured([]byte(data), &e); err != nil {
+		t.Fatal(err)
+	}
+	want := Event{
+		SpecVersion: "1.0", ID: "a", Source: "/s", Type: "t", DataContentType: "application/json",
+		Time:       time.Date(2021, 6, 1, 0, 30, 0, 5e8, time.UTC),
+		Extensions: map[string]interface{}{"seq": 7, "sampled": true, "traceparent": "00-1"},
+		Data:       []byte(`{"n":1}`),
+	}
+	if !e.Time.Equal(want.Time) {
+		t.Errorf("got time %v, want %v", e.Time, want.Time)
+	}
+	e.Time = want.Time
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("got %#v\nwant %#v", e, want)
+	}
+
+	tests := []struct {
+		data string
+		want interface{}
+	}{
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","datacontenttype":"text/plain","data":"hi"}`, []byte("hi")},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","data_base64":"/w=="}`, []byte{0xff}},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","data":null}`, nil},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","data":"s"}`, []byte(`"s"`)},
+	}
+	for _, test := range tests {
+		var e Event
+		if err := unmarshalStructured([]byte(test.data), &e); err != nil {
+			t.Errorf("%s: %v", test.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(e.Data, test.want) {
+			t.Errorf("%s: got data %#v, want %#v", test.data, e.Data, test.want)
+		}
+	}
+}
+
+func TestUnmarshalStructured_Invalid(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{`[]`, "cloudevents: json: cannot unmarshal"},
+		{`null`, "cloudevents: event is not an object"},
+		{`{"id":"a","source":"/s","type":"t"}`, "cloudevents: missing required attribute specversion"},
+		{`{"specversion":"1.0","source":"/s","type":"t"}`, "cloudevents: missing required attribute id"},
+		{`{"specversion":"1.0","id":1,"source":"/s","type":"t"}`, "cloudevents: attribute id is not a string"},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","time":"yesterday"}`, `cloudevents: invalid time "yesterday"`},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","data":1,"data_base64":"AA=="}`, "cloudevents: both data and data_base64 are present"},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","data_base64":"!"}`, "cloudevents: data_base64: "},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","datacontenttype":"text/plain","data":{}}`, `cloudevents: data of content type "text/plain" is not a string`},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","ext":1.5}`, "cloudevents: extension ext: invalid integer 1.5"},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","ext":{}}`, "cloudevents: extension ext: unsupported value {}"},
+		{`{"specversion":"1.0","id":"a","source":"/s","type":"t","Ext":"s"}`, `cloudevents: invalid extension name "Ext"`},
+	}
+	for _, test := range tests {
+		var e Event
+		err := unmarshalStructured([]byte(test.data), &e)
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%s: got %v, want %q", test.data, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package csv defines and registers Marshaler/Unmarshaler handling CSV and TSV
+// content of tabular data.
+package csv
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+	RegisterTSV(TSVName)
+}
+
+// Name is type name.
+const Name = "csv"
+
+// TSVName is type name of the tab separated variant.
+const TSVName = "tsv"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+	comma rune
+}
+
+// Marshal encodes a slice or an array of structs, pointers to structs, or
+// maps with string keys into rows of CSV content, or [][]string as-is.
+// The header row is taken from the "csv" tags of the struct, or the sorted
+// keys of maps.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	if config.Comma == 0 {
+		config.Comma = c.comma
+	}
+	return encode(v, config)
+}
+
+// Unmarshal decodes rows of CSV content into a pointer to a slice of structs,
+// pointers to structs, or map[string]string, or into *[][]string as-is.
+// Columns are mapped to fields by the header row regardless of the order.
+// Errors of decoding fields are reported as *RowError with the row and column
+// numbers.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	if config.Comma == 0 {
+		config.Comma = c.comma
+	}
+	return decode(data, v, config)
+}
+
+// Register register marshaler/unmarshaler of CSV.
+func Register(name string) {
+	register(name, ',')
+}
+
+// RegisterTSV register marshaler/unmarshaler of TSV.
+func RegisterTSV(name string) {
+	register(name, '\t')
+}
+
+func register(name string, comma rune) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{comma: comma} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{comma: comma} })
+}
@@ -0,0 +1,75 @@
+package csv
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+type report struct {
+	Name   string    `csv:"name"`
+	Amount float64   `csv:"amount"`
+	Date   time.Time `csv:"date"`
+	Note   string    `csv:"-"`
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	c := &codec{comma: ','}
+	in := []report{
+		{Name: "李雷", Amount: 1.5, Date: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), Note: "x"},
+		{Name: "a,b", Amount: 2},
+	}
+	data, err := c.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name,amount,date\n李雷,1.5,2021-01-02T00:00:00Z\n\"a,b\",2,0001-01-01T00:00:00Z\n"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+	var out []report
+	if err := c.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	in[0].Note = ""
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestCodec_Charset(t *testing.T) {
+	m := encoding.FilterMarshaler(encoding.GetMarshaler(Name), encoding.EncodeWithCharset("GBK"))
+	data, err := m.Marshal(context.Background(), []report{{Name: "李雷"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := encoding.FilterUnmarshaler(encoding.GetUnmarshaler(Name), encoding.DecodingWithCharset("GBK"))
+	var out []*report
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].Name != "李雷" {
+		t.Errorf("got %+v", out)
+	}
+}
+
+func TestCodec_TSV(t *testing.T) {
+	m := encoding.GetMarshaler(TSVName)
+	data, err := m.Marshal(context.Background(), []map[string]string{{"b": "2", "a": "1"}, {"c": "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a\tb\tc\n1\t2\t\n\t\t3\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+	var out []map[string]string
+	if err := encoding.GetUnmarshaler(TSVName).Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || out[0]["b"] != "2" || out[1]["c"] != "3" {
+		t.Errorf("got %v", out)
+	}
+}
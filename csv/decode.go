@@ -0,0 +1,171 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+// RowError is an error of decoding or encoding a field of a row.
+type RowError struct {
+	// Row is the 1-based number of the record, including the header row.
+	Row int
+	// Column is the 1-based number of the column.
+	Column int
+	// Field is the name of the column.
+	Field string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("csv: row %d, column %d (%s): %v", e.Row, e.Column, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is a list of RowError reported when ContinueOnError is set.
+type Errors []*RowError
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Error(), len(e)-1)
+}
+
+func decode(data []byte, v interface{}, config *DecoderConfig) error {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\uFEFF"))))
+	r.Comma = config.Comma
+	r.Comment = config.Comment
+	r.LazyQuotes = config.LazyQuotes
+	r.TrimLeadingSpace = config.TrimLeadingSpace
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	if vv, ok := v.(*[][]string); ok {
+		*vv = rows
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("csv: can not unmarshal to %T, want pointer to slice", v)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	baseType := elemType
+	for baseType.Kind() == reflect.Ptr {
+		baseType = baseType.Elem()
+	}
+	var header []string
+	first := 1
+	if !config.NoHeader && len(rows) > 0 {
+		header, rows = rows[0], rows[1:]
+		first = 2
+	}
+	var fill func(item reflect.Value, row []string, rowNum int) error
+	switch {
+	case baseType.Kind() == reflect.Struct:
+		fill = structFiller(baseType, header, config.NoHeader)
+	case baseType.Kind() == reflect.Map && baseType.Key().Kind() == reflect.String:
+		if config.NoHeader {
+			return fmt.Errorf("csv: can not unmarshal to %T without header", v)
+		}
+		fill = mapFiller(header)
+	default:
+		return fmt.Errorf("csv: unsupported type %T", v)
+	}
+	result := reflect.MakeSlice(slice.Type(), 0, len(rows))
+	var errs Errors
+	for i, row := range rows {
+		item := reflect.New(elemType).Elem()
+		base := item
+		for base.Kind() == reflect.Ptr {
+			base.Set(reflect.New(base.Type().Elem()))
+			base = base.Elem()
+		}
+		if err := fill(base, row, first+i); err != nil {
+			if !config.ContinueOnError {
+				return err
+			}
+			errs = append(errs, err.(*RowError))
+			continue
+		}
+		result = reflect.Append(result, item)
+	}
+	slice.Set(result)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func structFiller(t reflect.Type, header []string, noHeader bool) func(reflect.Value, []string, int) error {
+	fs := fields.Of(t, tagName)
+	columns := make([]int, len(fs))
+	for i, f := range fs {
+		columns[i] = -1
+		if noHeader {
+			columns[i] = i
+			continue
+		}
+		for j, name := range header {
+			if name == f.Name {
+				columns[i] = j
+				break
+			}
+		}
+		if columns[i] >= 0 {
+			continue
+		}
+		for j, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), f.Name) {
+				columns[i] = j
+				break
+			}
+		}
+	}
+	return func(item reflect.Value, row []string, rowNum int) error {
+		for i, f := range fs {
+			column := columns[i]
+			if column < 0 || column >= len(row) || row[column] == "" {
+				continue
+			}
+			fv, ok := fields.ByIndex(item, f.Index, true)
+			if !ok {
+				continue
+			}
+			if err := textual.Unmarshal(row[column], fv); err != nil {
+				return &RowError{Row: rowNum, Column: column + 1, Field: f.Name, Err: err}
+			}
+		}
+		return nil
+	}
+}
+
+func mapFiller(header []string) func(reflect.Value, []string, int) error {
+	return func(item reflect.Value, row []string, rowNum int) error {
+		item.Set(reflect.MakeMapWithSize(item.Type(), len(header)))
+		for i, name := range header {
+			if i >= len(row) {
+				break
+			}
+			value := reflect.New(item.Type().Elem()).Elem()
+			if err := textual.Unmarshal(row[i], value); err != nil {
+				return &RowError{Row: rowNum, Column: i + 1, Field: name, Err: err}
+			}
+			item.SetMapIndex(reflect.ValueOf(name).Convert(item.Type().Key()), value)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type item struct {
+	ID    int     `csv:"id"`
+	Name  string  `csv:"name"`
+	Price float64 `csv:"price"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		config DecoderConfig
+		want   []item
+	}{
+		{
+			name:   "reordered columns",
+			data:   "\uFEFFname,extra,ID,price\nfoo,x,1,2.5\nbar,y,2,\n",
+			config: DecoderConfig{Comma: ','},
+			want:   []item{{ID: 1, Name: "foo", Price: 2.5}, {ID: 2, Name: "bar"}},
+		},
+		{
+			name:   "no header",
+			data:   "1|foo|3\n# comment\n2|bar\n",
+			config: DecoderConfig{Comma: '|', Comment: '#', NoHeader: true},
+			want:   []item{{ID: 1, Name: "foo", Price: 3}, {ID: 2, Name: "bar"}},
+		},
+		{
+			name:   "empty",
+			data:   "",
+			config: DecoderConfig{Comma: ','},
+			want:   []item{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			var got []item
+			if err := decode([]byte(tt.data), &got, &config); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode_RowError(t *testing.T) {
+	data := []byte("id,name,price\n1,a,x\n2,b,3\nz,c,4\n")
+	var got []item
+	err := decode(data, &got, &DecoderConfig{Comma: ','})
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 2 || rowErr.Column != 3 || rowErr.Field != "price" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	err = decode(data, &got, &DecoderConfig{Comma: ',', ContinueOnError: true})
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[1].Row != 4 || errs[1].Column != 1 {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("expect good rows kept, got %+v", got)
+	}
+	if errs.Error() != `csv: row 2, column 3 (price): strconv.ParseFloat: parsing "x": invalid syntax (and 1 more errors)` {
+		t.Errorf("unexpected message %s", errs.Error())
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	config := &DecoderConfig{Comma: ','}
+	var items []item
+	var ints []int
+	var maps []map[string]string
+	tests := []struct {
+		data   string
+		v      interface{}
+		config *DecoderConfig
+	}{
+		{"a,\"b\n", &items, config},
+		{"a\n", items, config},
+		{"a\n", &ints, config},
+		{"a\n", &maps, &DecoderConfig{Comma: ',', NoHeader: true}},
+	}
+	for _, tt := range tests {
+		if err := decode([]byte(tt.data), tt.v, tt.config); err == nil {
+			t.Errorf("decode %q into %T expect error", tt.data, tt.v)
+		}
+	}
+	var raw [][]string
+	if err := decode([]byte("a,b\n"), &raw, config); err != nil || len(raw) != 1 || raw[0][1] != "b" {
+		t.Errorf("unexpected raw %v, %v", raw, err)
+	}
+}
@@ -0,0 +1,162 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+const tagName = "csv"
+
+func encode(v interface{}, config *EncoderConfig) ([]byte, error) {
+	header, rows, err := records(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if config.BOM {
+		buf.WriteString("\uFEFF")
+	}
+	if header != nil && !config.NoHeader {
+		writeRecord(buf, header, config)
+	}
+	for _, row := range rows {
+		writeRecord(buf, row, config)
+	}
+	return buf.Bytes(), nil
+}
+
+// records converts v into the header and rows. The header is nil for [][]string.
+func records(v interface{}) ([]string, [][]string, error) {
+	if rows, ok := v.([][]string); ok {
+		return nil, rows, nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("csv: unsupported type %T", v)
+	}
+	elemType := rv.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	switch {
+	case elemType.Kind() == reflect.Struct:
+		return structRecords(rv, elemType)
+	case elemType.Kind() == reflect.Map && elemType.Key().Kind() == reflect.String:
+		return mapRecords(rv)
+	}
+	return nil, nil, fmt.Errorf("csv: unsupported type %T", v)
+}
+
+func structRecords(rv reflect.Value, elemType reflect.Type) ([]string, [][]string, error) {
+	fs := fields.Of(elemType, tagName)
+	header := make([]string, len(fs))
+	for i, f := range fs {
+		header[i] = f.Name
+	}
+	rows := make([][]string, rv.Len())
+	for i := range rows {
+		row := make([]string, len(fs))
+		item := reflect.Indirect(rv.Index(i))
+		for item.Kind() == reflect.Ptr {
+			item = item.Elem()
+		}
+		if item.IsValid() {
+			for j, f := range fs {
+				fv, ok := fields.ByIndex(item, f.Index, false)
+				if !ok {
+					continue
+				}
+				cell, err := textual.Marshal(fv)
+				if err != nil {
+					return nil, nil, &RowError{Row: i + 2, Column: j + 1, Field: f.Name, Err: err}
+				}
+				row[j] = cell
+			}
+		}
+		rows[i] = row
+	}
+	return header, rows, nil
+}
+
+func mapRecords(rv reflect.Value) ([]string, [][]string, error) {
+	seen := map[string]bool{}
+	var header []string
+	for i := 0; i < rv.Len(); i++ {
+		m := reflect.Indirect(rv.Index(i))
+		if !m.IsValid() {
+			continue
+		}
+		for _, key := range m.MapKeys() {
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				header = append(header, key.String())
+			}
+		}
+	}
+	sort.Strings(header)
+	rows := make([][]string, rv.Len())
+	for i := range rows {
+		m := reflect.Indirect(rv.Index(i))
+		row := make([]string, len(header))
+		rows[i] = row
+		if !m.IsValid() {
+			// a nil element is an empty row, like a nil struct.
+			continue
+		}
+		for j, key := range header {
+			value := m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+			if !value.IsValid() {
+				continue
+			}
+			cell, err := textual.Marshal(value)
+			if err != nil {
+				return nil, nil, &RowError{Row: i + 2, Column: j + 1, Field: key, Err: err}
+			}
+			row[j] = cell
+		}
+	}
+	return header, rows, nil
+}
+
+func writeRecord(buf *bytes.Buffer, record []string, config *EncoderConfig) {
+	for i, field := range record {
+		if i > 0 {
+			buf.WriteRune(config.Comma)
+		}
+		if !config.QuoteAll && !needsQuotes(field, config.Comma) {
+			buf.WriteString(field)
+			continue
+		}
+		buf.WriteByte('"')
+		buf.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		buf.WriteByte('"')
+	}
+	if config.UseCRLF {
+		buf.WriteString("\r\n")
+	} else {
+		buf.WriteByte('\n')
+	}
+}
+
+// needsQuotes reports whether the field must be quoted, following encoding/csv.
+func needsQuotes(field string, comma rune) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, comma) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"errors"
+	"testing"
+)
+
+type badText struct{}
+
+func (badText) MarshalText() ([]byte, error) {
+	return nil, errors.New("bad")
+}
+
+func TestEncode(t *testing.T) {
+	type row struct {
+		A string `csv:"a"`
+		B *int   `csv:"b"`
+	}
+	n := 1
+	tests := []struct {
+		name   string
+		v      interface{}
+		config EncoderConfig
+		want   string
+	}{
+		{
+			name:   "pointers",
+			v:      []*row{{A: " lead", B: &n}, nil},
+			config: EncoderConfig{Comma: ','},
+			want:   "a,b\n\" lead\",1\n,\n",
+		},
+		{
+			name:   "quote all, crlf, bom",
+			v:      [1]row{{A: "x\"y"}},
+			config: EncoderConfig{Comma: ';', QuoteAll: true, UseCRLF: true, BOM: true},
+			want:   "\uFEFF\"a\";\"b\"\r\n\"x\"\"y\";\"\"\r\n",
+		},
+		{
+			name:   "no header",
+			v:      &[]row{{A: "x"}},
+			config: EncoderConfig{Comma: ',', NoHeader: true},
+			want:   "x,\n",
+		},
+		{
+			name:   "nil map pointers",
+			v:      []*map[string]int{nil, {"b": 2, "a": 1}, nil},
+			config: EncoderConfig{Comma: ','},
+			want:   "a,b\n,\n1,2\n,\n",
+		},
+		{
+			name:   "raw",
+			v:      [][]string{{"1", "2\n3"}},
+			config: EncoderConfig{Comma: ','},
+			want:   "1,\"2\n3\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			got, err := encode(tt.v, &config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode_Error(t *testing.T) {
+	config := &EncoderConfig{Comma: ','}
+	for _, v := range []interface{}{1, []int{1}, []struct {
+		B badText `csv:"b"`
+	}{{}}} {
+		if _, err := encode(v, config); err == nil {
+			t.Errorf("encode %T expect error", v)
+		}
+	}
+	_, err := encode([]struct {
+		A string
+		B badText
+	}{{}}, config)
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 2 || rowErr.Column != 2 || rowErr.Field != "B" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package csv
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Comma is the field delimiter. Zero means the default delimiter of the codec.
+	Comma rune
+	// UseCRLF terminates each line with \r\n instead of \n.
+	UseCRLF bool
+	// QuoteAll quotes every field, not only those need to be quoted.
+	QuoteAll bool
+	// NoHeader omits the header row.
+	NoHeader bool
+	// BOM writes a UTF-8 byte order mark first, which makes Excel recognize
+	// the content as UTF-8. It conflicts with re-encoding the content into
+	// another charset, such as by encoding.EncodeWithCharset, which can not
+	// represent the mark: the codec is unaware of the filter, so leave BOM
+	// unset then.
+	BOM bool
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// Comma is the field delimiter. Zero means the default delimiter of the codec.
+	Comma rune
+	// Comment, if not zero, is the comment character. Lines beginning with it
+	// are ignored.
+	Comment rune
+	// NoHeader means there is no header row, columns are mapped to fields
+	// in order.
+	NoHeader bool
+	// LazyQuotes allows quotes to appear in unquoted fields, and non-doubled
+	// quotes to appear in quoted fields.
+	LazyQuotes bool
+	// TrimLeadingSpace ignores leading white space in fields.
+	TrimLeadingSpace bool
+	// ContinueOnError skips rows which can not be decoded instead of stopping.
+	// Errors of all skipped rows are returned as Errors.
+	ContinueOnError bool
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// DecodeComma produces a DecoderOption which sets the field delimiter.
+func DecodeComma(comma rune) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Comma = comma
+	}
+}
+
+// Comment produces a DecoderOption which sets the comment character.
+func Comment(comment rune) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Comment = comment
+	}
+}
+
+// DecodeNoHeader produces a DecoderOption which decodes content without header
+// row, mapping columns to fields in order.
+func DecodeNoHeader() DecoderOption {
+	return func(config *DecoderConfig) {
+		config.NoHeader = true
+	}
+}
+
+// LazyQuotes produces a DecoderOption which relaxes the quoting rules.
+func LazyQuotes() DecoderOption {
+	return func(config *DecoderConfig) {
+		config.LazyQuotes = true
+	}
+}
+
+// TrimLeadingSpace produces a DecoderOption which ignores leading white space
+// in fields.
+func TrimLeadingSpace() DecoderOption {
+	return func(config *DecoderConfig) {
+		config.TrimLeadingSpace = true
+	}
+}
+
+// ContinueOnError produces a DecoderOption which skips rows can not be decoded,
+// and reports all of them as Errors at last.
+func ContinueOnError() DecoderOption {
+	return func(config *DecoderConfig) {
+		config.ContinueOnError = true
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// EncodeComma produces an EncoderOption which sets the field delimiter.
+func EncodeComma(comma rune) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Comma = comma
+	}
+}
+
+// UseCRLF produces an EncoderOption which terminates lines with \r\n.
+func UseCRLF() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.UseCRLF = true
+	}
+}
+
+// QuoteAll produces an EncoderOption which quotes every field.
+func QuoteAll() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.QuoteAll = true
+	}
+}
+
+// EncodeNoHeader produces an EncoderOption which omits the header row.
+func EncodeNoHeader() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.NoHeader = true
+	}
+}
+
+// WithBOM produces an EncoderOption which writes a UTF-8 byte order mark
+// first. It conflicts with filters re-encoding the content into a charset
+// other than UTF-8, see EncoderConfig.BOM.
+func WithBOM() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.BOM = true
+	}
+}
@@ -0,0 +1,29 @@
+package csv
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{comma: ','}, EncodeComma(';'), EncodeNoHeader(), QuoteAll(), UseCRLF(), WithBOM())
+	got, err := m.Marshal(context.Background(), []map[string]string{{"a": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\uFEFF\"1\"\r\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	u := WithDecoderOption(&codec{comma: ','}, DecodeComma(';'), DecodeNoHeader(), Comment('#'),
+		LazyQuotes(), TrimLeadingSpace(), ContinueOnError())
+	var got []item
+	if err := u.Unmarshal(context.Background(), []byte("# c\n 1; a\"b;x\n2;c;3\n"), &got); err == nil {
+		t.Fatal("expect error of the first row")
+	}
+	if len(got) != 1 || got[0].Name != "c" {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,289 @@
+package fixedwidth
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+	xencoding "golang.org/x/text/encoding"
+)
+
+// LineError is an error of encoding or decoding a record.
+type LineError struct {
+	// Line is the 1-based line number of the record.
+	Line int
+	// Field is the name of the field of the column, if any.
+	Field string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *LineError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("fixedwidth: line %d: field %s: %v", e.Line, e.Field, e.Err)
+	}
+	return fmt.Sprintf("fixedwidth: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// line is a non-empty line of content.
+type line struct {
+	no   int
+	text string
+	// raw is the text in the charset, whose bytes are sliced into columns.
+	raw []byte
+}
+
+type decoder struct {
+	// encoder and decoder convert lines from and to the charset, nil for
+	// UTF-8.
+	encoder  *xencoding.Encoder
+	decoder  *xencoding.Decoder
+	location *time.Location
+}
+
+func (d *decoder) lines(data []byte) ([]*line, error) {
+	text := strings.TrimPrefix(string(data), "\uFEFF")
+	var lines []*line
+	for i, s := range strings.Split(text, "\n") {
+		s = strings.TrimSuffix(s, "\r")
+		if s == "" {
+			continue
+		}
+		l := &line{no: i + 1, text: s, raw: []byte(s)}
+		if d.encoder != nil {
+			raw, err := d.encoder.Bytes(l.raw)
+			if err != nil {
+				return nil, &LineError{Line: l.no, Err: err}
+			}
+			l.raw = raw
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func (d *decoder) decode(data []byte, v reflect.Value) error {
+	lines, err := d.lines(data)
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		elem := v.Type().Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return fmt.Errorf("fixedwidth: unsupported record type %s", elem)
+		}
+		slice := reflect.MakeSlice(v.Type(), len(lines), len(lines))
+		for i, l := range lines {
+			if err := d.record(l, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Struct:
+		l, err := layoutOf(v.Type())
+		if err != nil {
+			return err
+		}
+		if len(l.columns) == 0 {
+			return d.file(lines, v)
+		}
+		switch len(lines) {
+		case 0:
+			return errors.New("fixedwidth: no record")
+		case 1:
+			return d.record(lines[0], v)
+		}
+		return &LineError{Line: lines[1].no, Err: errors.New("more than one record")}
+	}
+	return fmt.Errorf("fixedwidth: unsupported type %s", v.Type())
+}
+
+// file decodes lines into the fields of a file struct in order. A slice field
+// takes records as long as they match the constant columns of its record type,
+// leaving a record for each of the following non-slice fields.
+func (d *decoder) file(lines []*line, v reflect.Value) error {
+	ffs, err := fileFields(v.Type())
+	if err != nil {
+		return err
+	}
+	need := make([]int, len(ffs))
+	for i := len(ffs) - 2; i >= 0; i-- {
+		need[i] = need[i+1]
+		if !ffs[i+1].many {
+			need[i]++
+		}
+	}
+	pos := 0
+	for i, f := range ffs {
+		fv := v.FieldByIndex(f.index)
+		if !f.many {
+			if pos >= len(lines) {
+				return fmt.Errorf("fixedwidth: missing record of %s", f.name)
+			}
+			if !d.matches(f.layout, lines[pos]) {
+				return &LineError{Line: lines[pos].no, Err: fmt.Errorf("record does not match %s", f.name)}
+			}
+			if err := d.record(lines[pos], fv); err != nil {
+				return err
+			}
+			pos++
+			continue
+		}
+		slice := reflect.MakeSlice(fv.Type(), 0, 0)
+		for ; pos < len(lines)-need[i] && d.matches(f.layout, lines[pos]); pos++ {
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := d.record(lines[pos], elem); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		fv.Set(slice)
+	}
+	if pos < len(lines) {
+		return &LineError{Line: lines[pos].no, Err: errors.New("unexpected record")}
+	}
+	return nil
+}
+
+// matches reports whether the constant columns of a layout match a line.
+func (d *decoder) matches(l *layout, ln *line) bool {
+	for i := range l.columns {
+		c := &l.columns[i]
+		if !c.constant {
+			continue
+		}
+		text, err := d.text(c, ln)
+		if err != nil || text != c.value {
+			return false
+		}
+	}
+	return true
+}
+
+// text returns the text of a column of a line, without padding. Bytes beyond
+// the end of the line are regarded as padding.
+func (d *decoder) text(c *column, ln *line) (string, error) {
+	var seg []byte
+	if c.start < len(ln.raw) {
+		end := c.start + c.width
+		if end > len(ln.raw) {
+			end = len(ln.raw)
+		}
+		seg = ln.raw[c.start:end]
+	}
+	if d.decoder != nil {
+		var err error
+		if seg, err = d.decoder.Bytes(seg); err != nil {
+			return "", err
+		}
+	}
+	text := string(seg)
+	if c.right {
+		text = strings.TrimLeft(text, string(c.pad))
+	} else {
+		text = strings.TrimRight(text, string(c.pad))
+	}
+	if c.typ.Kind() != reflect.String {
+		text = strings.TrimSpace(text)
+	}
+	return text, nil
+}
+
+func (d *decoder) record(ln *line, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return &LineError{Line: ln.no, Err: fmt.Errorf("unsupported record type %s", v.Type())}
+	}
+	l, err := layoutOf(v.Type())
+	if err != nil {
+		return err
+	}
+	for i := range l.columns {
+		c := &l.columns[i]
+		text, err := d.text(c, ln)
+		if err != nil {
+			return &LineError{Line: ln.no, Field: c.name, Err: err}
+		}
+		if c.constant && text != c.value {
+			return &LineError{Line: ln.no, Field: c.name, Err: fmt.Errorf("value %q differs from constant %q", text, c.value)}
+		}
+		if c.index == nil {
+			continue
+		}
+		fv, ok := fields.ByIndex(v, c.index, true)
+		if !ok {
+			return &LineError{Line: ln.no, Field: c.name, Err: errors.New("can not set embedded pointer to unexported struct")}
+		}
+		if err := d.parse(c, text, fv); err != nil {
+			return &LineError{Line: ln.no, Field: c.name, Err: err}
+		}
+	}
+	return nil
+}
+
+// parse parses the text of a column into v. Empty text means the zero value.
+func (d *decoder) parse(c *column, text string, v reflect.Value) error {
+	if text == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	switch {
+	case c.typ == _timeType:
+		t, err := time.ParseInLocation(c.format, text, d.location)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case c.decimals >= 0:
+		f, err := parseDecimal(text, c.decimals, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	return textual.Unmarshal(text, v)
+}
+
+// parseDecimal parses digits with implied decimals.
+func parseDecimal(text string, decimals, bits int) (float64, error) {
+	sign, digits := "", text
+	if digits[0] == '-' || digits[0] == '+' {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid number %q", text)
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	n := len(digits) - decimals
+	return strconv.ParseFloat(sign+digits[:n]+"."+digits[n:], bits)
+}
@@ -0,0 +1,132 @@
+package fixedwidth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func unmarshal(d *decoder, data string, v interface{}) error {
+	if d.location == nil {
+		d.location = time.UTC
+	}
+	return d.decode([]byte(data), reflect.ValueOf(v).Elem())
+}
+
+func TestDecoder(t *testing.T) {
+	note := "x"
+	count := 2
+	date := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	gbk := simplifiedchinese.GBK
+	tests := []struct {
+		name    string
+		decoder *decoder
+		data    string
+		v       interface{}
+		want    interface{}
+	}{
+		{name: "record", data: "0007DTab    0000123520210601     x\n", v: &detail{},
+			want: &detail{base: base{ID: 7}, Name: "ab", Amount: 12.35, Date: date, Note: &note}},
+		{name: "short line", data: "\uFEFF0007DT ab", v: &detail{}, want: &detail{base: base{ID: 7}, Name: " ab"}},
+		{name: "slice", data: "0000DT      -0000150\r\n\r\n0000DT      00000005\r\n", v: &[]detail{},
+			want: &[]detail{{Amount: -1.5}, {Amount: 0.05}}},
+		{name: "empty slice", data: "", v: &[]*detail{}, want: &[]*detail{}},
+		{name: "file", data: "HDB     2021-06-01  2\n0001DT\n0002DT\nTR0000000350\n", v: &file{}, want: &file{
+			Header:  header{Type: "HD", Bank: "B", Date: date, Count: &count},
+			Details: []*detail{{base: base{ID: 1}}, {base: base{ID: 2}}},
+			Trailer: &trailer{Total: 3.5},
+		}},
+		{name: "file without details", data: "HD\nTR\n", v: &file{}, want: &file{
+			Header:  header{Type: "HD"},
+			Details: []*detail{},
+			Trailer: &trailer{},
+		}},
+		{name: "file of unmatched slice", data: "0001DT\n0002DT\n", v: &struct {
+			Details []detail
+			Last    detail
+		}{}, want: &struct {
+			Details []detail
+			Last    detail
+		}{Details: []detail{{base: base{ID: 1}}}, Last: detail{base: base{ID: 2}}}},
+		{name: "charset", decoder: &decoder{encoder: gbk.NewEncoder(), decoder: gbk.NewDecoder()},
+			data: "0000DT中文  00000001", v: &detail{}, want: &detail{Name: "中文", Amount: 0.01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.decoder
+			if d == nil {
+				d = &decoder{}
+			}
+			if err := unmarshal(d, tt.data, tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("got %+v, want %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		v    interface{}
+		want string
+	}{
+		{name: "number", data: "00x1DT\n", v: &detail{}, want: `fixedwidth: line 1: field ID: strconv.ParseInt: parsing "x1": invalid syntax`},
+		{name: "decimal", data: "0000DT      00001.50\n", v: &detail{}, want: `fixedwidth: line 1: field Amount: invalid number "1.50"`},
+		{name: "time", data: "0000DT      00000000 2021\n", v: &detail{}, want: `fixedwidth: line 1: field Date: parsing time "2021" as "20060102": cannot parse "" as "01"`},
+		{name: "constant", data: "\n\n0000XX\n", v: &detail{}, want: `fixedwidth: line 3: field _: value "XX" differs from constant "DT"`},
+		{name: "no record", data: "\n", v: &detail{}, want: "fixedwidth: no record"},
+		{name: "more records", data: "0000DT\n0000DT\n", v: &detail{}, want: "fixedwidth: line 2: more than one record"},
+		{name: "missing trailer", data: "HD\n", v: &file{}, want: "fixedwidth: missing record of Trailer"},
+		{name: "unmatched trailer", data: "HD\n0001DT\n", v: &file{}, want: "fixedwidth: line 2: record does not match Trailer"},
+		{name: "unmatched header", data: "TR\n", v: &file{}, want: "fixedwidth: line 1: record does not match Header"},
+		{name: "unexpected", data: "HD\nTR\nTR\n", v: &file{}, want: "fixedwidth: line 3: unexpected record"},
+		{name: "record type", data: "1\n", v: &[]int{}, want: "fixedwidth: unsupported record type int"},
+		{name: "unsupported", data: "1\n", v: new(int), want: "fixedwidth: unsupported type int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unmarshal(&decoder{}, tt.data, tt.v)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+			var le *LineError
+			if errors.As(err, &le) && le.Unwrap() == nil {
+				t.Error("want underlying error")
+			}
+		})
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		text     string
+		decimals int
+		want     float64
+	}{
+		{text: "1234", decimals: 2, want: 12.34},
+		{text: "5", decimals: 3, want: 0.005},
+		{text: "-5", decimals: 1, want: -0.5},
+		{text: "+12", decimals: 0, want: 12},
+	}
+	for _, tt := range tests {
+		got, err := parseDecimal(tt.text, tt.decimals, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("parseDecimal(%q, %d) = %v, want %v", tt.text, tt.decimals, got, tt.want)
+		}
+	}
+	for _, text := range []string{"-", "1e5", "1.5"} {
+		if _, err := parseDecimal(text, 2, 64); err == nil {
+			t.Errorf("want error of %q", text)
+		}
+	}
+}
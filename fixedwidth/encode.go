@@ -0,0 +1,190 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+	xencoding "golang.org/x/text/encoding"
+)
+
+type encoder struct {
+	lineEnding string
+	// charset measures widths, nil for UTF-8.
+	charset *xencoding.Encoder
+	buf     bytes.Buffer
+	line    int
+}
+
+// width returns the width of s in bytes of the charset.
+func (e *encoder) width(s string) (int, error) {
+	if e.charset == nil {
+		return len(s), nil
+	}
+	b, err := e.charset.String(s)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (e *encoder) encode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return fmt.Errorf("fixedwidth: unsupported nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.record(rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		l, err := layoutOf(rv.Type())
+		if err != nil {
+			return err
+		}
+		if len(l.columns) > 0 {
+			return e.record(rv)
+		}
+		return e.file(rv)
+	}
+	return fmt.Errorf("fixedwidth: unsupported type %T", v)
+}
+
+// file encodes the records of the fields of a file struct in order.
+func (e *encoder) file(v reflect.Value) error {
+	ffs, err := fileFields(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range ffs {
+		fv, ok := fields.ByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+		if f.many {
+			for i := 0; i < fv.Len(); i++ {
+				if err := e.record(fv.Index(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			continue
+		}
+		if err := e.record(fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) record(v reflect.Value) error {
+	e.line++
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return &LineError{Line: e.line, Err: fmt.Errorf("unsupported nil record")}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return &LineError{Line: e.line, Err: fmt.Errorf("unsupported record type %s", v.Type())}
+	}
+	l, err := layoutOf(v.Type())
+	if err != nil {
+		return err
+	}
+	if len(l.columns) == 0 {
+		return &LineError{Line: e.line, Err: fmt.Errorf("record type %s has no columns", v.Type())}
+	}
+	pos := 0
+	for i := range l.columns {
+		c := &l.columns[i]
+		e.buf.WriteString(strings.Repeat(" ", c.start-pos))
+		if err := e.column(c, v); err != nil {
+			return &LineError{Line: e.line, Field: c.name, Err: err}
+		}
+		pos = c.start + c.width
+	}
+	e.buf.WriteString(e.lineEnding)
+	return nil
+}
+
+func (e *encoder) column(c *column, record reflect.Value) error {
+	var text string
+	if c.index != nil {
+		v, ok := fields.ByIndex(record, c.index, false)
+		if ok {
+			var err error
+			if text, err = format(c, v); err != nil {
+				return err
+			}
+		}
+	}
+	if c.constant {
+		if text != "" && text != c.value {
+			return fmt.Errorf("value %q differs from constant %q", text, c.value)
+		}
+		text = c.value
+	}
+	w, err := e.width(text)
+	if err != nil {
+		return err
+	}
+	if w > c.width {
+		return fmt.Errorf("value %q overflows width %d", text, c.width)
+	}
+	padding := strings.Repeat(string(c.pad), c.width-w)
+	switch {
+	case !c.right:
+		e.buf.WriteString(text)
+		e.buf.WriteString(padding)
+	case c.pad == '0' && strings.HasPrefix(text, "-"):
+		e.buf.WriteByte('-')
+		e.buf.WriteString(padding)
+		e.buf.WriteString(text[1:])
+	default:
+		e.buf.WriteString(padding)
+		e.buf.WriteString(text)
+	}
+	return nil
+}
+
+// format returns the text of a column. Nil pointers and zero times have empty
+// text.
+func format(c *column, v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case c.typ == _timeType:
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return "", nil
+		}
+		return t.Format(c.format), nil
+	case c.decimals >= 0:
+		s := strconv.FormatFloat(v.Float(), 'f', c.decimals, v.Type().Bits())
+		s = strings.Replace(s, ".", "", 1)
+		if strings.Trim(s, "-0") == "" {
+			s = strings.TrimPrefix(s, "-")
+		}
+		return s, nil
+	}
+	return textual.Marshal(v)
+}
@@ -0,0 +1,112 @@
+package fixedwidth
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type header struct {
+	Type  string    `fw:"1,2,const=HD"`
+	Bank  string    `fw:"3,6"`
+	Date  time.Time `fw:"9,10,format=2006-01-02"`
+	Count *int      `fw:"19,3"`
+}
+
+type trailer struct {
+	_     struct{} `fw:"1,2,const=TR"`
+	Total float64  `fw:"3,10,decimals=2,pad=0"`
+}
+
+type file struct {
+	Header  header
+	Details []*detail
+	Trailer *trailer
+}
+
+func marshal(e *encoder, v interface{}) (string, error) {
+	if e.lineEnding == "" {
+		e.lineEnding = DefaultLineEnding
+	}
+	err := e.encode(v)
+	return e.buf.String(), err
+}
+
+func TestEncoder(t *testing.T) {
+	note := "x"
+	count := 2
+	date := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		encoder *encoder
+		v       interface{}
+		want    string
+	}{
+		{name: "record", v: detail{base: base{ID: 7}, Name: "ab", Amount: 12.345, Date: date, Note: &note},
+			want: "0007DTab    0000123520210601     x\n"},
+		{name: "zero", v: &detail{}, want: "0000DT      00000000              \n"},
+		{name: "negative", v: []detail{{Amount: -1.5}, {Amount: -0.001}},
+			want: "0000DT      -0000150              \n0000DT      00000000              \n"},
+		{name: "slice of interfaces", encoder: &encoder{lineEnding: "\r\n"}, v: []interface{}{header{Bank: "B", Date: date}, &trailer{Total: 1}},
+			want: "HDB     2021-06-01   \r\nTR0000000100\r\n"},
+		{name: "file", v: file{
+			Header:  header{Bank: "B", Count: &count},
+			Details: []*detail{{base: base{ID: 1}}, {base: base{ID: 2}}},
+			Trailer: &trailer{Total: 3.5},
+		}, want: "HDB                 2\n0001DT      00000000              \n0002DT      00000000              \nTR0000000350\n"},
+		{name: "file without trailer", v: &file{Header: header{Type: "HD"}}, want: "HD                   \n"},
+		{name: "charset", encoder: &encoder{charset: simplifiedchinese.GBK.NewEncoder()}, v: detail{Name: "中文"},
+			want: "0000DT中文  00000000              \n"},
+		{name: "utf-8", v: detail{Name: "中文"}, want: "0000DT中文00000000              \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.encoder
+			if e == nil {
+				e = &encoder{}
+			}
+			got, err := marshal(e, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder *encoder
+		v       interface{}
+		want    string
+	}{
+		{name: "overflow", v: []detail{{}, {Name: "toolong"}}, want: `fixedwidth: line 2: field Name: value "toolong" overflows width 6`},
+		{name: "overflow charset", encoder: &encoder{charset: simplifiedchinese.GBK.NewEncoder()}, v: detail{Name: "中文字x"},
+			want: "fixedwidth: line 1: field Name: value \"中文字x\" overflows width 6"},
+		{name: "unencodable", encoder: &encoder{charset: simplifiedchinese.GBK.NewEncoder()}, v: detail{Name: "\U0001F600"},
+			want: "fixedwidth: line 1: field Name: encoding: rune not supported by encoding."},
+		{name: "constant", v: header{Type: "XX"}, want: `fixedwidth: line 1: field Type: value "XX" differs from constant "HD"`},
+		{name: "nil record", v: []*detail{nil}, want: "fixedwidth: line 1: unsupported nil record"},
+		{name: "not record", v: []int{1}, want: "fixedwidth: line 1: unsupported record type int"},
+		{name: "no columns", v: []struct{}{{}}, want: "fixedwidth: line 1: record type struct {} has no columns"},
+		{name: "file field", v: struct{ A int }{}, want: "fixedwidth: struct { A int }: field A of type int is not a record"},
+		{name: "unsupported", v: 1, want: "fixedwidth: unsupported type int"},
+		{name: "nil", v: (*detail)(nil), want: "fixedwidth: unsupported nil *fixedwidth.detail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.encoder
+			if e == nil {
+				e = &encoder{}
+			}
+			_, err := marshal(e, tt.v)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
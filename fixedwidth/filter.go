@@ -0,0 +1,143 @@
+package fixedwidth
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+// DefaultLineEnding is the default line ending of records.
+const DefaultLineEnding = "\n"
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Charset is the IANA name of the charset in which widths are measured,
+	// which should be the charset the content is finally encoded into, e.g.
+	// by encoding.EncodeWithCharset. Empty means UTF-8.
+	Charset string
+	// LineEnding is written after each record. Empty means
+	// DefaultLineEnding.
+	LineEnding string
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// Charset is the IANA name of the charset in which widths are measured,
+	// which should be the charset the content is originally encoded in, e.g.
+	// before decoded by encoding.DecodingWithCharset. Empty means UTF-8.
+	Charset string
+	// Location is the location of times. Nil means time.Local.
+	Location *time.Location
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// DecoderCharset produces a DecoderOption which sets the charset in which
+// widths are measured.
+func DecoderCharset(name string) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Charset = name
+	}
+}
+
+// WithLocation produces a DecoderOption which sets the location of times.
+func WithLocation(loc *time.Location) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Location = loc
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// EncoderCharset produces an EncoderOption which sets the charset in which
+// widths are measured.
+func EncoderCharset(name string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Charset = name
+	}
+}
+
+// LineEnding produces an EncoderOption which sets the line ending of records,
+// e.g. "\r\n".
+func LineEnding(ending string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.LineEnding = ending
+	}
+}
@@ -0,0 +1,30 @@
+package fixedwidth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, LineEnding("\r\n"), EncoderCharset("GBK"))
+	got, err := m.Marshal(context.Background(), []trailer{{}, {Total: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "TR0000000000\r\nTR0000000100\r\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	loc := time.FixedZone("test", 8*3600)
+	u := WithDecoderOption(&codec{}, WithLocation(loc))
+	var v header
+	if err := u.Unmarshal(context.Background(), []byte("HD      2021-06-01\n"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 6, 1, 0, 0, 0, 0, loc); !v.Date.Equal(want) || v.Date.Location() != loc {
+		t.Errorf("got %v, want %v", v.Date, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Package fixedwidth defines and registers Marshaler/Unmarshaler handling
+// fixed-width text records, one record per line, as exchanged with mainframe
+// and banking systems.
+//
+// Records are structs whose columns are fields tagged in the form of
+//
+//	`fw:"start,len[,align=left|right][,pad=c][,decimals=n][,format=layout][,const=value]"`
+//
+// where start is the 1-based byte position of the column, and len its width in
+// bytes. Values are aligned to the left and padded with spaces by default,
+// numbers to the right. decimals is the number of implied decimals of a float,
+// e.g. 12.34 is written as 1234 with decimals=2. format is the layout of a
+// time.Time, DefaultTimeFormat by default. A constant column always has the
+// value, and identifies the type of records; blank (_) fields may be constant
+// columns. Gaps between columns are filled with spaces.
+//
+// Marshal encodes a record, or a slice or an array of records. Unmarshal
+// decodes a record, or a slice of records. Files with header and trailer
+// records are structs whose fields are records and slices of records, in the
+// order they appear:
+//
+//	type File struct {
+//		Header  Header
+//		Details []Detail
+//		Trailer Trailer
+//	}
+//
+// A slice takes the following records which match the constant columns of its
+// record type, leaving a record for each of the following non-slice fields.
+//
+// Widths are measured in bytes of EncoderConfig.Charset and
+// DecoderConfig.Charset, while content is read and written in UTF-8. To handle
+// content in other charsets, e.g. GBK, set the charset and compose the codec
+// with encoding.EncodeWithCharset/encoding.DecodingWithCharset filters of the
+// same charset.
+package fixedwidth
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-kita/encoding"
+	xencoding "golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "fixedwidth"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	e := &encoder{lineEnding: config.LineEnding}
+	if e.lineEnding == "" {
+		e.lineEnding = DefaultLineEnding
+	}
+	if config.Charset != "" {
+		charset, err := lookupCharset(config.Charset)
+		if err != nil {
+			return nil, err
+		}
+		e.charset = charset.NewEncoder()
+	}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("fixedwidth: can not unmarshal to non-pointer or nil %T", v)
+	}
+	d := &decoder{location: config.Location}
+	if d.location == nil {
+		d.location = time.Local
+	}
+	if config.Charset != "" {
+		charset, err := lookupCharset(config.Charset)
+		if err != nil {
+			return err
+		}
+		d.encoder, d.decoder = charset.NewEncoder(), charset.NewDecoder()
+	}
+	return d.decode(data, rv.Elem())
+}
+
+func lookupCharset(name string) (xencoding.Encoding, error) {
+	charset, err := ianaindex.IANA.Encoding(name)
+	if err != nil {
+		return nil, fmt.Errorf("fixedwidth: %w", err)
+	}
+	if charset == nil {
+		return nil, fmt.Errorf("fixedwidth: unsupported charset %q", name)
+	}
+	return charset, nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,58 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestCodec_Charset(t *testing.T) {
+	m := encoding.FilterMarshaler(WithEncoderOption(encoding.GetMarshaler(Name), EncoderCharset("GBK")),
+		encoding.EncodeWithCharset("GBK"))
+	u := encoding.FilterUnmarshaler(WithDecoderOption(encoding.GetUnmarshaler(Name), DecoderCharset("GBK"), WithLocation(time.UTC)),
+		encoding.DecodingWithCharset("GBK"))
+	count := 1
+	in := file{
+		Header:  header{Type: "HD", Bank: "中行", Date: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), Count: &count},
+		Details: []*detail{{base: base{ID: 1}, Name: "张三", Amount: 99.99}},
+		Trailer: &trailer{Total: 99.99},
+	}
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	for i, width := range []int{21, 34, 12} {
+		if len(lines[i]) != width {
+			t.Errorf("line %d: got %d bytes, want %d: %q", i+1, len(lines[i]), width, lines[i])
+		}
+	}
+	if want := "0001DT\xd5\xc5\xc8\xfd  00009999"; !bytes.HasPrefix(lines[1], []byte(want)) {
+		t.Errorf("got %q, want prefix %q", lines[1], want)
+	}
+	var out file
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	var v []detail
+	if err := u.Unmarshal(context.Background(), nil, v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+	if err := WithDecoderOption(u, DecoderCharset("MY-Fake")).Unmarshal(context.Background(), nil, &v); err == nil {
+		t.Error("want error of unknown charset")
+	}
+	if _, err := WithEncoderOption(m, EncoderCharset("MY-Fake")).Marshal(context.Background(), v); err == nil {
+		t.Error("want error of unknown charset")
+	}
+}
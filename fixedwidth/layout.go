@@ -0,0 +1,221 @@
+package fixedwidth
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+// DefaultTimeFormat is the default layout of time.Time columns.
+const DefaultTimeFormat = "20060102"
+
+var _timeType = reflect.TypeOf(time.Time{})
+
+// column is a column of a record, mapped to a field.
+type column struct {
+	// index is the index sequence of the field, nil for a blank field.
+	index []int
+	name  string
+	typ   reflect.Type
+	// start is the 0-based byte offset of the column.
+	start int
+	width int
+	right bool
+	pad   byte
+	// decimals is the number of implied decimals, or -1.
+	decimals int
+	format   string
+	// value is the constant value of the column, if constant.
+	value    string
+	constant bool
+}
+
+// layout is the layout of records of a struct type.
+type layout struct {
+	columns []column
+	// width is the width of records in bytes.
+	width int
+	// constant reports whether some columns are constant, which identify the
+	// type of records.
+	constant bool
+}
+
+var _layouts sync.Map
+
+// layoutOf returns the layout of records of the struct type t. A struct type
+// without columns has an empty layout.
+func layoutOf(t reflect.Type) (*layout, error) {
+	if l, ok := _layouts.Load(t); ok {
+		return l.(*layout), nil
+	}
+	l := &layout{}
+	if err := l.add(t, nil); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(l.columns, func(i, j int) bool { return l.columns[i].start < l.columns[j].start })
+	for i, c := range l.columns {
+		if i > 0 && c.start < l.columns[i-1].start+l.columns[i-1].width {
+			return nil, fmt.Errorf("fixedwidth: %s: column %s overlaps column %s", t, c.name, l.columns[i-1].name)
+		}
+		if end := c.start + c.width; end > l.width {
+			l.width = end
+		}
+		l.constant = l.constant || c.constant
+	}
+	_layouts.Store(t, l)
+	return l, nil
+}
+
+func (l *layout) add(t reflect.Type, index []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("fw")
+		fieldIndex := append(index[:len(index):len(index)], i)
+		if !ok || tag == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && ft.Kind() == reflect.Struct {
+				if err := l.add(ft, fieldIndex); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" || sf.PkgPath != "" && sf.Name != "_" {
+			continue
+		}
+		c, err := parseColumn(sf, tag)
+		if err != nil {
+			return fmt.Errorf("fixedwidth: %s: field %s: %w", t, sf.Name, err)
+		}
+		if sf.Name != "_" {
+			c.index = fieldIndex
+		} else if !c.constant {
+			return fmt.Errorf("fixedwidth: %s: blank field must be constant", t)
+		}
+		l.columns = append(l.columns, *c)
+	}
+	return nil
+}
+
+// parseColumn parses a tag in the form of
+// "start,len[,align=left|right][,pad=c][,decimals=n][,format=layout][,const=value]".
+func parseColumn(sf reflect.StructField, tag string) (*column, error) {
+	parts := strings.SplitN(tag, ",", 3)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("tag %q must start with start and length", tag)
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || start < 1 {
+		return nil, fmt.Errorf("invalid start %q", parts[0])
+	}
+	width, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || width < 1 {
+		return nil, fmt.Errorf("invalid length %q", parts[1])
+	}
+	var options fields.Options
+	if len(parts) == 3 {
+		options = fields.Options(parts[2])
+	}
+	t := sf.Type
+	for t.Kind() == reflect.Ptr && t != _timeType {
+		t = t.Elem()
+	}
+	c := &column{name: sf.Name, typ: t, start: start - 1, width: width, pad: ' ', decimals: -1}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		c.right = true
+	}
+	if align, ok := options.Lookup("align"); ok {
+		switch align {
+		case "left":
+			c.right = false
+		case "right":
+			c.right = true
+		default:
+			return nil, fmt.Errorf("invalid align %q", align)
+		}
+	}
+	if pad, ok := options.Lookup("pad"); ok && pad != "" {
+		if len(pad) != 1 || pad[0] >= 0x80 {
+			return nil, fmt.Errorf("pad %q must be one ASCII character", pad)
+		}
+		c.pad = pad[0]
+	}
+	if decimals, ok := options.Lookup("decimals"); ok {
+		n, err := strconv.Atoi(decimals)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid decimals %q", decimals)
+		}
+		if t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64 {
+			return nil, fmt.Errorf("decimals of non-float type %s", sf.Type)
+		}
+		c.decimals = n
+	}
+	if t == _timeType {
+		c.format = DefaultTimeFormat
+		if format, ok := options.Lookup("format"); ok && format != "" {
+			c.format = format
+		}
+	} else if _, ok := options.Lookup("format"); ok {
+		return nil, fmt.Errorf("format of non-time type %s", sf.Type)
+	} else if sf.Name != "_" && !textual.IsScalar(sf.Type) {
+		return nil, fmt.Errorf("unsupported type %s", sf.Type)
+	}
+	c.value, c.constant = options.Lookup("const")
+	return c, nil
+}
+
+// fileField is a field of a file struct, which holds a record or a slice of
+// records.
+type fileField struct {
+	index []int
+	name  string
+	// elem is the struct type of records.
+	elem reflect.Type
+	many bool
+	// layout is the layout of records.
+	layout *layout
+}
+
+// fileFields returns the fields of a file struct type in order.
+func fileFields(t reflect.Type) ([]fileField, error) {
+	var ffs []fileField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || sf.Tag.Get("fw") == "-" {
+			continue
+		}
+		f := fileField{index: []int{i}, name: sf.Name, elem: sf.Type}
+		if f.elem.Kind() == reflect.Slice {
+			f.elem, f.many = f.elem.Elem(), true
+		}
+		if f.elem.Kind() == reflect.Ptr {
+			f.elem = f.elem.Elem()
+		}
+		if f.elem.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("fixedwidth: %s: field %s of type %s is not a record", t, sf.Name, sf.Type)
+		}
+		l, err := layoutOf(f.elem)
+		if err != nil {
+			return nil, err
+		}
+		if len(l.columns) == 0 {
+			return nil, fmt.Errorf("fixedwidth: %s: field %s of type %s is not a record", t, sf.Name, sf.Type)
+		}
+		f.layout = l
+		ffs = append(ffs, f)
+	}
+	return ffs, nil
+}
@@ -0,0 +1,104 @@
+package fixedwidth
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type base struct {
+	ID int `fw:"1,4,pad=0"`
+}
+
+type detail struct {
+	_ struct{} `fw:"5,2,const=DT"`
+	base
+	Name     string    `fw:"7,6"`
+	Amount   float64   `fw:"13,8,decimals=2,pad=0"`
+	Date     time.Time `fw:"21,8"`
+	Note     *string   `fw:"31,4,align=right"`
+	Ignored  string    `fw:"-"`
+	Untagged string
+}
+
+func TestLayoutOf(t *testing.T) {
+	l, err := layoutOf(reflect.TypeOf(detail{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.width != 34 || !l.constant || len(l.columns) != 6 {
+		t.Fatalf("got layout %+v", l)
+	}
+	want := []struct {
+		name     string
+		start    int
+		width    int
+		right    bool
+		pad      byte
+		decimals int
+		format   string
+	}{
+		{name: "ID", start: 0, width: 4, right: true, pad: '0', decimals: -1},
+		{name: "_", start: 4, width: 2, pad: ' ', decimals: -1},
+		{name: "Name", start: 6, width: 6, pad: ' ', decimals: -1},
+		{name: "Amount", start: 12, width: 8, right: true, pad: '0', decimals: 2},
+		{name: "Date", start: 20, width: 8, pad: ' ', decimals: -1, format: DefaultTimeFormat},
+		{name: "Note", start: 30, width: 4, right: true, pad: ' ', decimals: -1},
+	}
+	for i, w := range want {
+		c := l.columns[i]
+		if c.name != w.name || c.start != w.start || c.width != w.width || c.right != w.right ||
+			c.pad != w.pad || c.decimals != w.decimals || c.format != w.format {
+			t.Errorf("column %d: got %+v, want %+v", i, c, w)
+		}
+	}
+	if l.columns[1].index != nil || l.columns[1].value != "DT" || !reflect.DeepEqual(l.columns[0].index, []int{1, 0}) {
+		t.Errorf("got columns %+v", l.columns[:2])
+	}
+}
+
+func TestLayoutOf_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "no length", v: struct {
+			A string `fw:"1"`
+		}{}},
+		{name: "start", v: struct {
+			A string `fw:"0,1"`
+		}{}},
+		{name: "length", v: struct {
+			A string `fw:"1,x"`
+		}{}},
+		{name: "align", v: struct {
+			A string `fw:"1,1,align=center"`
+		}{}},
+		{name: "pad", v: struct {
+			A string `fw:"1,1,pad=ab"`
+		}{}},
+		{name: "decimals", v: struct {
+			A int `fw:"1,1,decimals=2"`
+		}{}},
+		{name: "format", v: struct {
+			A string `fw:"1,1,format=2006"`
+		}{}},
+		{name: "type", v: struct {
+			A []int `fw:"1,1"`
+		}{}},
+		{name: "blank", v: struct {
+			_ string `fw:"1,1"`
+		}{}},
+		{name: "overlap", v: struct {
+			A string `fw:"1,3"`
+			B string `fw:"3,1"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := layoutOf(reflect.TypeOf(tt.v)); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package form
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+// parse splits urlencoded content into unescaped key/value pairs in order.
+func parse(data []byte, filter encoding.FilterFunc) ([]pair, error) {
+	var pairs []pair
+	for len(data) > 0 {
+		var item []byte
+		if i := bytes.IndexByte(data, '&'); i >= 0 {
+			item, data = data[:i], data[i+1:]
+		} else {
+			item, data = data, nil
+		}
+		if len(item) == 0 {
+			continue
+		}
+		rawKey, rawValue := item, []byte(nil)
+		if i := bytes.IndexByte(item, '='); i >= 0 {
+			rawKey, rawValue = item[:i], item[i+1:]
+		}
+		key, err := unescape(string(rawKey), filter)
+		if err != nil {
+			return nil, err
+		}
+		value, err := unescape(string(rawValue), filter)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair{key: key, value: value})
+	}
+	return pairs, nil
+}
+
+func unescape(s string, filter encoding.FilterFunc) (string, error) {
+	s, err := url.QueryUnescape(s)
+	if err != nil {
+		return "", fmt.Errorf("form: %w", err)
+	}
+	if filter == nil {
+		return s, nil
+	}
+	data, err := filter([]byte(s))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// node is a node of the tree built from nested keys.
+type node struct {
+	values   []string
+	children map[string]*node
+	keys     []string
+}
+
+func (n *node) child(key string) *node {
+	if c, ok := n.children[key]; ok {
+		return c
+	}
+	if n.children == nil {
+		n.children = map[string]*node{}
+	}
+	c := &node{}
+	n.children[key] = c
+	n.keys = append(n.keys, key)
+	return c
+}
+
+// lookup finds the child by key, falling back to case-insensitive match.
+func (n *node) lookup(key string) *node {
+	if c, ok := n.children[key]; ok {
+		return c
+	}
+	for _, k := range n.keys {
+		if strings.EqualFold(k, key) {
+			return n.children[k]
+		}
+	}
+	return nil
+}
+
+// maxDepth is the maximum number of segments of a key, which bounds the
+// recursion of decoding the tree.
+const maxDepth = 1000
+
+func tree(pairs []pair) (*node, error) {
+	root := &node{}
+	for _, p := range pairs {
+		segments := splitKey(p.key)
+		if len(segments) > maxDepth {
+			return nil, fmt.Errorf("form: key exceeds max depth %d", maxDepth)
+		}
+		n := root
+		for _, segment := range segments {
+			n = n.child(segment)
+		}
+		n.values = append(n.values, p.value)
+	}
+	return root, nil
+}
+
+// splitKey splits a nested key in "a[b][c]" or "a.b.c" style, or mixed of
+// them, into segments. An empty bracket pair, as in "a[]", adds no segment.
+func splitKey(key string) []string {
+	var segments []string
+	current := 0
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '.':
+			if i > current {
+				segments = append(segments, key[current:i])
+			}
+			current = i + 1
+		case '[':
+			end := strings.IndexByte(key[i:], ']')
+			if i == 0 || end < 0 {
+				continue
+			}
+			if i > current {
+				segments = append(segments, key[current:i])
+			}
+			if end > 1 {
+				segments = append(segments, key[i+1:i+end])
+			}
+			i += end
+			current = i + 1
+		}
+	}
+	if current < len(key) {
+		segments = append(segments, key[current:])
+	}
+	return segments
+}
+
+func decode(n *node, v reflect.Value, path string) error {
+	if textual.IsScalar(v.Type()) {
+		if len(n.values) == 0 {
+			return nil
+		}
+		if err := textual.Unmarshal(n.values[0], v); err != nil {
+			return fmt.Errorf("form: field %s: %w", path, err)
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(n, v.Elem(), path)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		v.Set(reflect.ValueOf(n.interfaceValue()))
+		return nil
+	case reflect.Struct:
+		for _, f := range fields.Of(v.Type(), Name) {
+			c := n.lookup(f.Name)
+			if c == nil {
+				continue
+			}
+			fv, ok := fields.ByIndex(v, f.Index, true)
+			if !ok {
+				continue
+			}
+			if err := decode(c, fv, join(path, f.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, key := range n.keys {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decode(n.children[key], elem, join(path, key)); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		return decodeList(n, v, path)
+	}
+	return fmt.Errorf("form: field %s: unsupported type %s", path, v.Type())
+}
+
+// maxIndex is the largest index of a list accepted regardless of the number
+// of items. Larger indexes are accepted up to twice the number of items, which
+// bounds the length of slices allocated for sparse indexes.
+const maxIndex = 1000
+
+// decodeList decodes repeated values, or children with indexes as keys, into
+// a slice or an array.
+func decodeList(n *node, v reflect.Value, path string) error {
+	type item struct {
+		index int
+		node  *node
+	}
+	var items []item
+	if len(n.children) > 0 {
+		for _, key := range n.keys {
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 {
+				return fmt.Errorf("form: field %s: invalid index %q", path, key)
+			}
+			// the bound also keeps index+1 from overflowing.
+			if index > maxIndex && index >= 2*len(n.keys) {
+				return fmt.Errorf("form: field %s: index %d out of range", path, index)
+			}
+			items = append(items, item{index: index, node: n.children[key]})
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].index < items[j].index })
+	} else {
+		for i, value := range n.values {
+			items = append(items, item{index: i, node: &node{values: []string{value}}})
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	length := items[len(items)-1].index + 1
+	if v.Kind() == reflect.Slice {
+		if v.Len() < length {
+			grown := reflect.MakeSlice(v.Type(), length, length)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+	} else if length > v.Len() {
+		return fmt.Errorf("form: field %s: index %d out of array bounds", path, length-1)
+	}
+	for _, it := range items {
+		if err := decode(it.node, v.Index(it.index), join(path, strconv.Itoa(it.index))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *node) interfaceValue() interface{} {
+	if len(n.children) > 0 {
+		m := make(map[string]interface{}, len(n.children))
+		for key, c := range n.children {
+			m[key] = c.interfaceValue()
+		}
+		return m
+	}
+	if len(n.values) == 1 {
+		return n.values[0]
+	}
+	return n.values
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,139 @@
+package form
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeTree decodes pairs into v through a tree.
+func decodeTree(pairs []pair, v interface{}) error {
+	root, err := tree(pairs)
+	if err != nil {
+		return err
+	}
+	return decode(root, reflect.ValueOf(v).Elem(), "")
+}
+
+func TestSplitKey(t *testing.T) {
+	tests := map[string][]string{
+		"a":          {"a"},
+		"a[b][c]":    {"a", "b", "c"},
+		"a.b.c":      {"a", "b", "c"},
+		"a.b[c]":     {"a", "b", "c"},
+		"a[]":        {"a"},
+		"a[b":        {"a[b"},
+		"a[b]x":      {"a", "b", "x"},
+		"a[0].b":     {"a", "0", "b"},
+		"[a]":        {"[a]"},
+		"a[0][name]": {"a", "0", "name"},
+	}
+	for key, want := range tests {
+		if got := splitKey(key); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type item struct {
+		ID   int    `form:"id"`
+		Name string `form:"name"`
+	}
+	type target struct {
+		Items  []item                 `form:"items"`
+		IDs    []int                  `form:"ids"`
+		Arr    [2]string              `form:"arr"`
+		Ptr    *item                  `form:"ptr"`
+		Any    interface{}            `form:"any"`
+		Map    map[string]interface{} `form:"map"`
+		Folded string
+	}
+	pairs, err := parse([]byte("items[1][id]=2&items[0].id=1&items[0][name]=a+b&ids[]=3&ids[]=4&arr=x"+
+		"&ptr.id=5&any=1&any=2&map[k][x]=y&map[v]=w&folded=f"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got target
+	if err := decodeTree(pairs, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := target{
+		Items:  []item{{ID: 1, Name: "a b"}, {ID: 2}},
+		IDs:    []int{3, 4},
+		Arr:    [2]string{"x"},
+		Ptr:    &item{ID: 5},
+		Any:    []string{"1", "2"},
+		Map:    map[string]interface{}{"k": map[string]interface{}{"x": "y"}, "v": "w"},
+		Folded: "f",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	type target struct {
+		N   int       `form:"n"`
+		Arr [1]string `form:"arr"`
+		L   []int     `form:"l"`
+		C   chan int  `form:"c"`
+	}
+	for _, data := range []string{"n=x", "arr=a&arr=b", "l[x]=1", "c=1", "n=%zz"} {
+		pairs, err := parse([]byte(data), nil)
+		if err == nil {
+			var got target
+			err = decodeTree(pairs, &got)
+		}
+		if err == nil {
+			t.Errorf("decode %q expect error", data)
+		}
+	}
+}
+
+func TestDecode_Index(t *testing.T) {
+	type target struct {
+		A []string `form:"a"`
+	}
+	tests := []struct {
+		data string
+		want int
+		err  string
+	}{
+		{"a[1000]=x", 1001, ""},
+		{"a[100000000000]=x", 0, "form: field a: index 100000000000 out of range"},
+		{"a[9223372036854775807]=x", 0, "form: field a: index 9223372036854775807 out of range"},
+		{"a[9223372036854775808]=x", 0, `form: field a: invalid index "9223372036854775808"`},
+	}
+	for _, test := range tests {
+		pairs, err := parse([]byte(test.data), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got target
+		err = decodeTree(pairs, &got)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: got %v, want %q", test.data, err, test.err)
+			}
+			continue
+		}
+		if err != nil || len(got.A) != test.want {
+			t.Errorf("%s: got %d items, %v, want %d", test.data, len(got.A), err, test.want)
+		}
+	}
+}
+
+func TestDecode_Depth(t *testing.T) {
+	deep := strings.Repeat("a.", maxDepth) + "k=1"
+	for _, v := range []interface{}{&struct{ A interface{} }{}, &map[string]interface{}{}} {
+		if err := (&codec{}).Unmarshal(context.Background(), []byte(deep), v); err == nil || !strings.Contains(err.Error(), "max depth") {
+			t.Errorf("%T: got %v, want error of depth", v, err)
+		}
+	}
+	var got map[string]interface{}
+	if err := (&codec{}).Unmarshal(context.Background(), []byte(strings.Repeat("a.", maxDepth-1)+"k=1"), &got); err != nil {
+		t.Error(err)
+	}
+}
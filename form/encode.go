@@ -0,0 +1,139 @@
+package form
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+type pair struct {
+	key   string
+	value string
+}
+
+type encoder struct {
+	config *EncoderConfig
+	pairs  []pair
+}
+
+func (e *encoder) encode(v interface{}) error {
+	if values, ok := v.(url.Values); ok {
+		v = map[string][]string(values)
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map {
+		return fmt.Errorf("form: unsupported type %T", v)
+	}
+	return e.value("", rv)
+}
+
+func (e *encoder) join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if e.config.KeyStyle == DotStyle {
+		return prefix + "." + name
+	}
+	return prefix + "[" + name + "]"
+}
+
+func (e *encoder) value(key string, v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if key != "" && textual.IsScalar(v.Type()) {
+		s, err := textual.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("form: field %s: %w", key, err)
+		}
+		e.pairs = append(e.pairs, pair{key: key, value: s})
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return e.value(key, v.Elem())
+	case reflect.Struct:
+		for _, f := range fields.Of(v.Type(), Name) {
+			fv, ok := fields.ByIndex(v, f.Index, false)
+			if !ok || f.OmitEmpty() && fields.IsEmpty(fv) {
+				continue
+			}
+			if err := e.value(e.join(key, f.Name), fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("form: unsupported map key type %s", v.Type().Key())
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			if err := e.value(e.join(key, k.String()), v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		scalar := textual.IsScalar(v.Type().Elem())
+		for i := 0; i < v.Len(); i++ {
+			itemKey := key
+			if !scalar {
+				itemKey = e.join(key, strconv.Itoa(i))
+			}
+			if err := e.value(itemKey, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("form: field %s: unsupported type %s", key, v.Type())
+}
+
+func (e *encoder) bytes() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for i, p := range e.pairs {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		key, err := e.filter(p.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := e.filter(p.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(url.QueryEscape(key))
+		buf.WriteByte('=')
+		buf.WriteString(url.QueryEscape(value))
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *encoder) filter(s string) (string, error) {
+	if e.config.Filter == nil {
+		return s, nil
+	}
+	data, err := e.config.Filter([]byte(s))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
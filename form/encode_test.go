@@ -0,0 +1,90 @@
+package form
+
+import (
+	"errors"
+	"testing"
+)
+
+type failText struct{}
+
+func (failText) MarshalText() ([]byte, error) {
+	return nil, errors.New("fail")
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	type item struct {
+		ID int `form:"id"`
+	}
+	type nested struct {
+		Items []item          `form:"items"`
+		Ptr   *item           `form:"ptr"`
+		Nil   *item           `form:"nil"`
+		Any   interface{}     `form:"any"`
+		Arr   [2]int          `form:"arr"`
+		Deep  map[string]item `form:"deep"`
+	}
+	v := nested{
+		Items: []item{{1}, {2}},
+		Ptr:   &item{3},
+		Any:   "x",
+		Arr:   [2]int{4, 5},
+		Deep:  map[string]item{"k": {6}},
+	}
+	tests := []struct {
+		name  string
+		style KeyStyle
+		want  string
+	}{
+		{
+			name:  "bracket",
+			style: BracketStyle,
+			want: "items%5B0%5D%5Bid%5D=1&items%5B1%5D%5Bid%5D=2&ptr%5Bid%5D=3&any=x&arr=4&arr=5" +
+				"&deep%5Bk%5D%5Bid%5D=6",
+		},
+		{
+			name:  "dot",
+			style: DotStyle,
+			want:  "items.0.id=1&items.1.id=2&ptr.id=3&any=x&arr=4&arr=5&deep.k.id=6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &encoder{config: &EncoderConfig{KeyStyle: tt.style}}
+			if err := e.encode(v); err != nil {
+				t.Fatal(err)
+			}
+			got, err := e.bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_EncodeError(t *testing.T) {
+	tests := []interface{}{
+		struct {
+			F failText `form:"f"`
+		}{},
+		map[int]string{1: "a"},
+		struct {
+			C chan int `form:"c"`
+		}{C: make(chan int)},
+	}
+	for _, v := range tests {
+		e := &encoder{config: &EncoderConfig{}}
+		if err := e.encode(v); err == nil {
+			t.Errorf("encode %T expect error", v)
+		}
+	}
+	e := &encoder{config: &EncoderConfig{Filter: func([]byte) ([]byte, error) { return nil, errors.New("fail") }}}
+	if err := e.encode(map[string]string{"a": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.bytes(); err == nil {
+		t.Errorf("filter error expected")
+	}
+}
@@ -0,0 +1,137 @@
+package form
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// KeyStyle is the style of nested keys.
+type KeyStyle uint8
+
+// Styles of nested keys.
+const (
+	// BracketStyle writes nested keys like "a[b][c]".
+	BracketStyle KeyStyle = iota
+	// DotStyle writes nested keys like "a.b.c".
+	DotStyle
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// KeyStyle is the style of nested keys.
+	KeyStyle KeyStyle
+	// Filter processes each key and value before they are escaped.
+	Filter encoding.FilterFunc
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// Filter processes each key and value after they are unescaped.
+	Filter encoding.FilterFunc
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// DecodeFilter produces a DecoderOption which processes each unescaped key and
+// value with the FilterFunc. Use encoding.DecodingWithCharset to decode forms
+// posted in charset other than UTF-8, e.g. GBK.
+func DecodeFilter(fn encoding.FilterFunc) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Filter = fn
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// NestedKeyStyle produces an EncoderOption which sets the style of nested keys.
+func NestedKeyStyle(style KeyStyle) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.KeyStyle = style
+	}
+}
+
+// EncodeFilter produces an EncoderOption which processes each key and value
+// with the FilterFunc before they are escaped. Use encoding.EncodeWithCharset
+// to post forms in charset other than UTF-8, e.g. GBK.
+func EncodeFilter(fn encoding.FilterFunc) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Filter = fn
+	}
+}
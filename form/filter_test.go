@@ -0,0 +1,32 @@
+package form
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, NestedKeyStyle(DotStyle))
+	data, err := m.Marshal(context.Background(), map[string]map[string]int{"a": {"b": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a.b=1" {
+		t.Errorf("got %s", data)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	called := false
+	u := WithDecoderOption(&codec{}, DecodeFilter(func(pre []byte) ([]byte, error) {
+		called = true
+		return pre, nil
+	}))
+	var got map[string]string
+	if err := u.Unmarshal(context.Background(), []byte("a=1"), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !called || got["a"] != "1" {
+		t.Errorf("filter not applied, got %v", got)
+	}
+}
@@ -0,0 +1,97 @@
+// Package form defines and registers Marshaler/Unmarshaler handling
+// application/x-www-form-urlencoded content.
+package form
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "form"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshal encodes url.Values, maps with string keys and structs into urlencoded
+// form content. Fields of structs are named by the "form" tag. Nested structs
+// and maps are encoded with nested keys, slices and arrays of scalars are
+// encoded as repeated keys.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	e := &encoder{config: config}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.bytes()
+}
+
+// Unmarshal decodes urlencoded form content into url.Values, maps and structs.
+// Nested keys in both "a[b][c]" and "a.b.c" styles are accepted.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	pairs, err := parse(data, config.Filter)
+	if err != nil {
+		return err
+	}
+	switch vv := v.(type) {
+	case *url.Values:
+		if *vv == nil {
+			*vv = url.Values{}
+		}
+		for _, p := range pairs {
+			vv.Add(p.key, p.value)
+		}
+		return nil
+	case *map[string][]string:
+		if *vv == nil {
+			*vv = map[string][]string{}
+		}
+		for _, p := range pairs {
+			(*vv)[p.key] = append((*vv)[p.key], p.value)
+		}
+		return nil
+	case *map[string]string:
+		if *vv == nil {
+			*vv = map[string]string{}
+		}
+		for _, p := range pairs {
+			if _, exist := (*vv)[p.key]; !exist {
+				(*vv)[p.key] = p.value
+			}
+		}
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("form: can not unmarshal to non-pointer or nil %T", v)
+	}
+	root, err := tree(pairs)
+	if err != nil {
+		return err
+	}
+	return decode(root, rv.Elem(), "")
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,108 @@
+package form
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+type address struct {
+	City string `form:"city"`
+	Zip  string `form:"zip,omitempty"`
+}
+
+type profile struct {
+	Name     string            `form:"name"`
+	Age      int               `form:"age"`
+	Tags     []string          `form:"tags"`
+	Address  address           `form:"address"`
+	Extra    map[string]string `form:"extra"`
+	Birthday time.Time         `form:"birthday"`
+	Ignored  string            `form:"-"`
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	c := &codec{}
+	p := profile{
+		Name:     "李雷",
+		Age:      18,
+		Tags:     []string{"a", "b"},
+		Address:  address{City: "Beijing"},
+		Extra:    map[string]string{"k": "v"},
+		Birthday: time.Date(2003, 1, 2, 0, 0, 0, 0, time.UTC),
+		Ignored:  "x",
+	}
+	data, err := c.Marshal(context.Background(), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name=%E6%9D%8E%E9%9B%B7&age=18&tags=a&tags=b&address%5Bcity%5D=Beijing&extra%5Bk%5D=v" +
+		"&birthday=2003-01-02T00%3A00%3A00Z"
+	if string(data) != want {
+		t.Errorf("got %s\nwant %s", data, want)
+	}
+	var got profile
+	if err := c.Unmarshal(context.Background(), data, &got); err != nil {
+		t.Fatal(err)
+	}
+	p.Ignored = ""
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("got %+v, want %+v", got, p)
+	}
+}
+
+func TestCodec_Values(t *testing.T) {
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), url.Values{"b": {"2", "3"}, "a": {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a=1&b=2&b=3" {
+		t.Errorf("got %s", data)
+	}
+	var values url.Values
+	if err := c.Unmarshal(context.Background(), []byte("a=1&b=2&b=3&c"), &values); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, url.Values{"a": {"1"}, "b": {"2", "3"}, "c": {""}}) {
+		t.Errorf("got %v", values)
+	}
+	var flat map[string]string
+	if err := c.Unmarshal(context.Background(), []byte("a[x]=1&a[x]=2"), &flat); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(flat, map[string]string{"a[x]": "1"}) {
+		t.Errorf("got %v", flat)
+	}
+	if _, err := c.Marshal(context.Background(), 1); err == nil {
+		t.Errorf("marshal int expect error")
+	}
+	if err := c.Unmarshal(context.Background(), []byte("a=1"), profile{}); err == nil {
+		t.Errorf("unmarshal to non-pointer expect error")
+	}
+}
+
+func TestCodec_Charset(t *testing.T) {
+	m := WithEncoderOption(encoding.GetMarshaler(Name), EncodeFilter(encoding.EncodeWithCharset("GBK")))
+	data, err := m.Marshal(context.Background(), map[string]string{"name": "李雷"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "name=%C0%EE%C0%D7" {
+		t.Errorf("got %s", data)
+	}
+	u := WithDecoderOption(encoding.GetUnmarshaler(Name), DecodeFilter(encoding.DecodingWithCharset("GBK")))
+	var got struct {
+		Name string `form:"name"`
+	}
+	if err := u.Unmarshal(context.Background(), data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "李雷" {
+		t.Errorf("got %q", got.Name)
+	}
+}
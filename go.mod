@@ -5,4 +5,5 @@ go 1.16
 require (
 	go.uber.org/atomic v1.8.0
 	golang.org/x/text v0.3.6
+	google.golang.org/protobuf v1.26.0
 )
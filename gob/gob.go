@@ -0,0 +1,76 @@
+// Package gob defines and registers Marshaler/Unmarshaler handling gob
+// content, by encoding/gob, for exchanging values between Go programs.
+//
+// Each call of Marshal produces a self-contained gob stream, which carries the
+// descriptors of the types it uses. To send the descriptors only once per
+// connection, use an Encoder/Decoder pair over the connection, or a
+// StreamMarshaler/StreamUnmarshaler pair if messages are framed by the
+// transport.
+//
+// Concrete types transmitted as values of interface fields must be registered
+// by RegisterType or RegisterTypeName on both sides.
+package gob
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "gob"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(_ context.Context, v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(_ context.Context, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("gob: can not unmarshal to non-pointer or nil %T", v)
+	}
+	r := bytes.NewReader(data)
+	if err := gob.NewDecoder(r).Decode(v); err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return fmt.Errorf("gob: %d bytes of unexpected data after value", r.Len())
+	}
+	return nil
+}
+
+// RegisterType registers the concrete type of value, under its default name,
+// for transmitting it as a value of interface fields. See gob.Register.
+func RegisterType(value interface{}) {
+	gob.Register(value)
+}
+
+// RegisterTypeName registers the concrete type of value under name, for
+// transmitting it as a value of interface fields. See gob.RegisterName.
+func RegisterTypeName(name string, value interface{}) {
+	gob.RegisterName(name, value)
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,103 @@
+package gob
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+type shape interface {
+	Area() float64
+}
+
+type square struct {
+	Side float64
+}
+
+func (s square) Area() float64 { return s.Side * s.Side }
+
+type circle struct {
+	R float64
+}
+
+func (c *circle) Area() float64 { return 3 * c.R * c.R }
+
+type drawing struct {
+	Name   string
+	Shapes []shape
+	Meta   map[string]interface{}
+}
+
+func init() {
+	RegisterType(square{})
+	RegisterTypeName("gob_test.circle", &circle{})
+}
+
+func TestCodec(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	tests := []struct {
+		name string
+		in   interface{}
+		out  interface{}
+	}{
+		{name: "int", in: 42, out: new(int)},
+		{name: "struct", in: drawing{
+			Name:   "d",
+			Shapes: []shape{square{Side: 2}, &circle{R: 1}},
+			Meta:   map[string]interface{}{"square": square{Side: 1}},
+		}, out: &drawing{}},
+		{name: "value", in: encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewArray(encoding.NewNumber("1"))}),
+			out: &encoding.Value{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := m.Marshal(context.Background(), tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := u.Unmarshal(context.Background(), data, tt.out); err != nil {
+				t.Fatal(err)
+			}
+			want := reflect.ValueOf(tt.in)
+			if want.Kind() != reflect.Ptr {
+				p := reflect.New(want.Type())
+				p.Elem().Set(want)
+				want = p
+			}
+			if !reflect.DeepEqual(tt.out, want.Interface()) {
+				t.Errorf("got %+v, want %+v", tt.out, want.Interface())
+			}
+		})
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	if _, err := m.Marshal(context.Background(), nil); err == nil {
+		t.Error("want error of nil value")
+	}
+	type unregistered struct{ X int }
+	if _, err := m.Marshal(context.Background(), drawing{Meta: map[string]interface{}{"x": unregistered{}}}); err == nil {
+		t.Error("want error of unregistered type")
+	}
+	data, err := m.Marshal(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v int
+	if err := u.Unmarshal(context.Background(), data, v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+	if err := u.Unmarshal(context.Background(), data[:len(data)-1], &v); err == nil {
+		t.Error("want error of truncated data")
+	}
+	if err := u.Unmarshal(context.Background(), append(data, data...), &v); err == nil {
+		t.Error("want error of trailing data")
+	}
+	var s string
+	if err := u.Unmarshal(context.Background(), data, &s); err == nil {
+		t.Error("want error of type mismatch")
+	}
+}
@@ -0,0 +1,125 @@
+package gob
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/go-kita/encoding"
+)
+
+// Encoder writes a stream of values to an io.Writer. The descriptor of a type
+// is written once, before the first value of the type, so the stream must be
+// read by a single Decoder from its beginning. It is safe for concurrent use.
+type Encoder struct {
+	mu  sync.Mutex
+	enc *gob.Encoder
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{enc: gob.NewEncoder(w)}
+}
+
+// Encode writes v to the stream.
+func (e *Encoder) Encode(v interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(v)
+}
+
+// Decoder reads a stream of values written by an Encoder from an io.Reader.
+// It is safe for concurrent use.
+type Decoder struct {
+	mu  sync.Mutex
+	dec *gob.Decoder
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: gob.NewDecoder(r)}
+}
+
+// Decode reads the next value from the stream into v, which must be a pointer,
+// or discards it if v is nil. io.EOF is returned at the end of the stream.
+func (d *Decoder) Decode(v interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dec.Decode(v)
+}
+
+// StreamMarshaler is a stateful encoding.Marshaler which encodes values as
+// parts of one gob stream: the result of a Marshal call carries the
+// descriptors of types not seen by previous calls only. The results must be
+// unmarshaled in order by a single StreamUnmarshaler, e.g. one per connection.
+type StreamMarshaler struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	enc *gob.Encoder
+}
+
+var _ encoding.Marshaler = (*StreamMarshaler)(nil)
+
+// NewStreamMarshaler returns a StreamMarshaler at the beginning of a stream.
+func NewStreamMarshaler() *StreamMarshaler {
+	m := &StreamMarshaler{}
+	m.enc = gob.NewEncoder(&m.buf)
+	return m
+}
+
+// Marshal encodes v into the next part of the stream.
+func (m *StreamMarshaler) Marshal(_ context.Context, v interface{}) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer m.buf.Reset()
+	if err := m.enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), m.buf.Bytes()...), nil
+}
+
+// StreamUnmarshaler is a stateful encoding.Unmarshaler which decodes the
+// results of a StreamMarshaler, in the order they are produced.
+type StreamUnmarshaler struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	dec *gob.Decoder
+}
+
+var _ encoding.Unmarshaler = (*StreamUnmarshaler)(nil)
+
+// NewStreamUnmarshaler returns a StreamUnmarshaler at the beginning of a
+// stream.
+func NewStreamUnmarshaler() *StreamUnmarshaler {
+	u := &StreamUnmarshaler{}
+	u.dec = gob.NewDecoder(&u.buf)
+	return u
+}
+
+// Unmarshal decodes the next part of the stream into v. A part which is
+// incomplete or carries more than one value is an error, after which the
+// state of the stream is undefined.
+func (u *StreamUnmarshaler) Unmarshal(_ context.Context, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("gob: can not unmarshal to non-pointer or nil %T", v)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	defer u.buf.Reset()
+	u.buf.Write(data)
+	if err := u.dec.Decode(v); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if u.buf.Len() > 0 {
+		return fmt.Errorf("gob: %d bytes of unexpected data after value", u.buf.Len())
+	}
+	return nil
+}
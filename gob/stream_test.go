@@ -0,0 +1,95 @@
+package gob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestEncoder_Decoder(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	in := []drawing{
+		{Name: "a", Shapes: []shape{square{Side: 1}}},
+		{Name: "b", Shapes: []shape{&circle{R: 2}}},
+	}
+	var sizes []int
+	for _, v := range in {
+		n := buf.Len()
+		if err := e.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, buf.Len()-n)
+	}
+	if sizes[1] >= sizes[0] {
+		t.Errorf("type descriptors resent: sizes %v", sizes)
+	}
+	d := NewDecoder(&buf)
+	for _, want := range in {
+		var got drawing
+		if err := d.Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+	if err := d.Decode(&drawing{}); err != io.EOF {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
+
+func TestStreamMarshaler(t *testing.T) {
+	m, u := NewStreamMarshaler(), NewStreamUnmarshaler()
+	one, err := (&codec{}).Marshal(context.Background(), drawing{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		data, err := m.Marshal(context.Background(), drawing{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 && !bytes.Equal(data, one) {
+			t.Errorf("got first part %q, want %q", data, one)
+		}
+		if i > 0 && len(data) >= len(one) {
+			t.Errorf("part %d of %d bytes resends type descriptors", i, len(data))
+		}
+		var got drawing
+		if err := u.Unmarshal(context.Background(), data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != name {
+			t.Errorf("got %q, want %q", got.Name, name)
+		}
+	}
+	if _, err := m.Marshal(context.Background(), nil); err == nil {
+		t.Error("want error of nil value")
+	}
+}
+
+func TestStreamUnmarshaler_Errors(t *testing.T) {
+	m := NewStreamMarshaler()
+	first, _ := m.Marshal(context.Background(), 1)
+	second, _ := m.Marshal(context.Background(), 2)
+	var v int
+	if err := NewStreamUnmarshaler().Unmarshal(context.Background(), first, v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+	if err := NewStreamUnmarshaler().Unmarshal(context.Background(), nil, &v); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want io.ErrUnexpectedEOF", err)
+	}
+	if err := NewStreamUnmarshaler().Unmarshal(context.Background(), append(first, second...), &v); err == nil {
+		t.Error("want error of more than one value")
+	}
+	u := NewStreamUnmarshaler()
+	if err := u.Unmarshal(context.Background(), first, &v); err != nil || v != 1 {
+		t.Fatalf("got %d, %v", v, err)
+	}
+	if err := u.Unmarshal(context.Background(), second, &v); err != nil || v != 2 {
+		t.Errorf("got %d, %v", v, err)
+	}
+}
@@ -0,0 +1,104 @@
+package ini
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/nested"
+)
+
+var _valueType = reflect.TypeOf(encoding.Value{})
+
+type decoder struct {
+	config *DecoderConfig
+}
+
+func (d *decoder) decode(sections []*section, v reflect.Value) error {
+	if d.config.DuplicateKeys == DuplicateError {
+		if err := checkDuplicates(sections); err != nil {
+			return err
+		}
+	}
+	if v.Type() == _valueType {
+		v.Set(reflect.ValueOf(*d.value(sections)))
+		return nil
+	}
+	root := &nested.Node{}
+	for _, s := range sections {
+		path := sectionPath(s.name)
+		for _, p := range s.pairs {
+			if err := root.Add(append(path[:len(path):len(path)], p.key), p.value, p.line); err != nil {
+				return &SyntaxError{Line: p.line, msg: err.Error()}
+			}
+		}
+	}
+	nd := &nested.Decoder{
+		Prefix:                "ini",
+		Tags:                  _tags,
+		First:                 d.config.DuplicateKeys == DuplicateFirst,
+		Multi:                 d.config.DuplicateKeys == DuplicateAppend,
+		DisallowUnknownFields: d.config.DisallowUnknownFields,
+	}
+	return nd.Decode(root, v, nil)
+}
+
+// sectionPath splits a section name by dots into the path of nested
+// sections.
+func sectionPath(name string) []string {
+	if name == "" {
+		return nil
+	}
+	path := strings.Split(name, ".")
+	for i := range path {
+		path[i] = strings.TrimSpace(path[i])
+	}
+	return path
+}
+
+func checkDuplicates(sections []*section) error {
+	for _, s := range sections {
+		seen := make(map[string]bool, len(s.pairs))
+		for _, p := range s.pairs {
+			if seen[p.key] {
+				return &SyntaxError{Line: p.line, msg: fmt.Sprintf("duplicate key %q", p.key)}
+			}
+			seen[p.key] = true
+		}
+	}
+	return nil
+}
+
+// value decodes sections into an object Value: pairs of the default section
+// are string members, other sections are object members. Comments are kept.
+func (d *decoder) value(sections []*section) *encoding.Value {
+	root := encoding.NewObject(d.members(sections[0].pairs)...)
+	for _, s := range sections[1:] {
+		root.Members = append(root.Members, &encoding.Member{
+			Key:     s.name,
+			Value:   encoding.NewObject(d.members(s.pairs)...),
+			Comment: s.comment,
+		})
+	}
+	return root
+}
+
+// members converts pairs into members with duplicate keys merged by the
+// policy. A merged member stays at the position of the first occurrence.
+func (d *decoder) members(pairs []*pair) []*encoding.Member {
+	members := make([]*encoding.Member, 0, len(pairs))
+	index := make(map[string]int, len(pairs))
+	for _, p := range pairs {
+		value := encoding.NewString(p.value)
+		if i, ok := index[p.key]; ok && d.config.DuplicateKeys != DuplicateAppend {
+			if d.config.DuplicateKeys != DuplicateFirst {
+				members[i].Value = value
+			}
+			continue
+		}
+		index[p.key] = len(members)
+		members = append(members, &encoding.Member{Key: p.key, Value: value, Comment: p.comment})
+	}
+	return members
+}
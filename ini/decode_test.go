@@ -0,0 +1,158 @@
+package ini
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func unmarshal(config *DecoderConfig, data string, v interface{}) error {
+	sections, err := parse([]byte(data))
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.decode(sections, reflect.ValueOf(v).Elem())
+}
+
+type server struct {
+	Host  string   `ini:"host"`
+	Port  int      `ini:"port"`
+	Tags  []string `ini:"tag"`
+	Debug *bool    `ini:"debug"`
+}
+
+type document struct {
+	Name    string            `ini:"name"`
+	Server  server            `ini:"server"`
+	Backup  *server           `ini:"backup"`
+	Labels  map[string]string `ini:"labels"`
+	Servers map[string]server `ini:"servers"`
+}
+
+func TestDecoder_Struct(t *testing.T) {
+	data := `name = app
+[server]
+host = a
+port = 80
+tag = x
+tag = y
+debug = true
+[backup]
+host = b
+[labels]
+env = prod
+[labels.team]
+owner = ops
+[servers.east]
+host = e
+[servers.west]
+port = 2
+`
+	var got document
+	if err := unmarshal(&DecoderConfig{}, data, &got); err != nil {
+		t.Fatal(err)
+	}
+	debug := true
+	want := document{
+		Name:    "app",
+		Server:  server{Host: "a", Port: 80, Tags: []string{"x", "y"}, Debug: &debug},
+		Backup:  &server{Host: "b"},
+		Labels:  map[string]string{"env": "prod", "team.owner": "ops"},
+		Servers: map[string]server{"east": {Host: "e"}, "west": {Port: 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecoder_Duplicates(t *testing.T) {
+	data := "a = 1\na = 2\n[s]\nb = x\nb = y\n"
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   interface{}
+	}{
+		{policy: DuplicateLast, want: map[string]interface{}{"a": "2", "s": map[string]interface{}{"b": "y"}}},
+		{policy: DuplicateFirst, want: map[string]interface{}{"a": "1", "s": map[string]interface{}{"b": "x"}}},
+		{policy: DuplicateAppend, want: map[string]interface{}{
+			"a": []interface{}{"1", "2"},
+			"s": map[string]interface{}{"b": []interface{}{"x", "y"}},
+		}},
+	}
+	for _, tt := range tests {
+		var got interface{}
+		if err := unmarshal(&DecoderConfig{DuplicateKeys: tt.policy}, data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("policy %d: got %#v, want %#v", tt.policy, got, tt.want)
+		}
+	}
+	var got interface{}
+	err := unmarshal(&DecoderConfig{DuplicateKeys: DuplicateError}, "[s]\nb = x\n[t]\nb = y\n[s]\nb = z\n", &got)
+	if se, ok := err.(*SyntaxError); !ok || se.Line != 6 {
+		t.Errorf("got %v, want syntax error at line 6", err)
+	}
+}
+
+func TestDecoder_Value(t *testing.T) {
+	data := "; about\nname = app\nname = other\n\n; main\n; server\n[server]\n# address\nhost = a\n[server.tls]\ncert = c\n"
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		name   []*encoding.Value
+	}{
+		{policy: DuplicateLast, name: []*encoding.Value{encoding.NewString("other")}},
+		{policy: DuplicateFirst, name: []*encoding.Value{encoding.NewString("app")}},
+		{policy: DuplicateAppend, name: []*encoding.Value{encoding.NewString("app"), encoding.NewString("other")}},
+	}
+	for _, tt := range tests {
+		var got encoding.Value
+		if err := unmarshal(&DecoderConfig{DuplicateKeys: tt.policy}, data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if names := got.GetAll("name"); !reflect.DeepEqual(names, tt.name) {
+			t.Errorf("policy %d: got names %v, want %v", tt.policy, names, tt.name)
+		}
+		if c := got.Members[0].Comment; c != "about" {
+			t.Errorf("got comment %q, want %q", c, "about")
+		}
+		server := got.Members[len(got.Members)-2]
+		if server.Key != "server" || server.Comment != "main\nserver" {
+			t.Errorf("got section %q with comment %q", server.Key, server.Comment)
+		}
+		if m := server.Value.Members[0]; m.Key != "host" || m.Comment != "address" || m.Value.Text != "a" {
+			t.Errorf("got member %+v", m)
+		}
+		if tls := got.Get("server.tls"); tls.Get("cert").Text != "c" {
+			t.Errorf("got section server.tls %+v", tls)
+		}
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *DecoderConfig
+		data   string
+		v      interface{}
+	}{
+		{name: "type", data: "[server]\nport = x\n", v: &document{}},
+		{name: "unknown field", config: &DecoderConfig{DisallowUnknownFields: true}, data: "[server]\nname = x\n", v: &document{}},
+		{name: "unsupported", data: "a = 1\n", v: &map[int]int{}},
+		{name: "array overflow", data: "a = 1\na = 2\n", v: &struct{ A [1]int }{}},
+		{name: "deep section", data: "[" + strings.Repeat("a.", 1000) + "b]\nk = 1\n", v: &map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = &DecoderConfig{}
+			}
+			if err := unmarshal(config, tt.data, tt.v); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/nested"
+)
+
+type encoder struct {
+	delimiter string
+	buf       bytes.Buffer
+}
+
+func (e *encoder) encode(v interface{}) error {
+	var sections []*section
+	var err error
+	switch value := v.(type) {
+	case encoding.Value:
+		sections, err = valueSections(&value)
+	case *encoding.Value:
+		sections, err = valueSections(value)
+	default:
+		sections, err = flatSections(reflect.ValueOf(v))
+	}
+	if err != nil {
+		return err
+	}
+	return e.write(sections)
+}
+
+// flatSections flattens v into sections. Keys of nested structs and maps are
+// put into sections named by their paths joined with ".".
+func flatSections(v reflect.Value) ([]*section, error) {
+	entries, err := nested.Flatten(v, "ini", _tags...)
+	if err != nil {
+		return nil, err
+	}
+	sections := []*section{{}}
+	byName := map[string]*section{"": sections[0]}
+	for _, entry := range entries {
+		n := len(entry.Path) - 1
+		name := strings.Join(entry.Path[:n], ".")
+		s, ok := byName[name]
+		if !ok {
+			s = &section{name: name}
+			byName[name] = s
+			sections = append(sections, s)
+		}
+		s.pairs = append(s.pairs, &pair{key: entry.Path[n], value: entry.Value})
+	}
+	return sections, nil
+}
+
+// valueSections converts an object Value into sections: scalar members are
+// pairs, array members are repeated keys and object members are sections,
+// nested ones named by their paths joined with ".".
+func valueSections(value *encoding.Value) ([]*section, error) {
+	sections := []*section{{}}
+	if value == nil || value.Kind == encoding.NullKind {
+		return sections, nil
+	}
+	if value.Kind != encoding.ObjectKind {
+		return nil, fmt.Errorf("ini: top-level value must be an object, not %s", value.Kind)
+	}
+	var add func(s *section, value *encoding.Value) error
+	add = func(s *section, value *encoding.Value) error {
+		for _, m := range value.Members {
+			if m.Value.Kind != encoding.ObjectKind {
+				items := []*encoding.Value{m.Value}
+				if m.Value.Kind == encoding.ArrayKind {
+					items = m.Value.Items
+				}
+				for i, item := range items {
+					text, err := scalarText(item)
+					if err != nil {
+						return fmt.Errorf("ini: key %s: %w", m.Key, err)
+					}
+					p := &pair{key: m.Key, value: text}
+					if i == 0 {
+						p.comment = m.Comment
+					}
+					s.pairs = append(s.pairs, p)
+				}
+				continue
+			}
+			name := m.Key
+			if s.name != "" {
+				name = s.name + "." + m.Key
+			}
+			sub := &section{name: name, comment: m.Comment}
+			sections = append(sections, sub)
+			if err := add(sub, m.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(sections[0], value); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+func scalarText(value *encoding.Value) (string, error) {
+	switch value.Kind {
+	case encoding.NullKind:
+		return "", nil
+	case encoding.BoolKind:
+		return strconv.FormatBool(value.Bool), nil
+	case encoding.NumberKind, encoding.StringKind:
+		return value.Text, nil
+	}
+	return "", fmt.Errorf("unsupported %s value", value.Kind)
+}
+
+func (e *encoder) write(sections []*section) error {
+	for _, s := range sections {
+		if s.name != "" {
+			if err := checkName(s.name); err != nil {
+				return err
+			}
+			if e.buf.Len() > 0 {
+				e.buf.WriteByte('\n')
+			}
+			e.comment(s.comment)
+			e.buf.WriteString("[" + s.name + "]\n")
+		}
+		for _, p := range s.pairs {
+			if err := checkKey(p.key); err != nil {
+				return err
+			}
+			if strings.ContainsAny(p.value, "\r\n") {
+				return fmt.Errorf("ini: key %s: value must not contain line breaks", p.key)
+			}
+			e.comment(p.comment)
+			e.buf.WriteString(p.key)
+			e.buf.WriteString(e.delimiter)
+			value, ok := quote(p.value)
+			if !ok {
+				return fmt.Errorf("ini: key %s: value %q can not be written", p.key, p.value)
+			}
+			e.buf.WriteString(value)
+			e.buf.WriteByte('\n')
+		}
+	}
+	return nil
+}
+
+func (e *encoder) comment(comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		if line == "" {
+			e.buf.WriteString(";\n")
+			continue
+		}
+		e.buf.WriteString("; " + line + "\n")
+	}
+}
+
+func checkName(name string) error {
+	if strings.ContainsAny(name, "]\r\n") || strings.TrimSpace(name) != name {
+		return fmt.Errorf("ini: invalid section name %q", name)
+	}
+	return nil
+}
+
+func checkKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "=:\r\n") || strings.ContainsAny(key[:1], ";#[") ||
+		strings.TrimSpace(key) != key {
+		return fmt.Errorf("ini: invalid key %q", key)
+	}
+	return nil
+}
+
+// quote wraps s in quotes if it would not be parsed back as-is. It reports
+// false if s can not be parsed back either way.
+func quote(s string) (string, bool) {
+	if parseValue(s) == s {
+		return s, true
+	}
+	for _, q := range []string{`"`, `'`} {
+		if parseValue(q+s+q) == s {
+			return q + s + q, true
+		}
+	}
+	return "", false
+}
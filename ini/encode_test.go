@@ -0,0 +1,87 @@
+package ini
+
+import (
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func marshal(delimiter string, v interface{}) (string, error) {
+	if delimiter == "" {
+		delimiter = DefaultDelimiter
+	}
+	e := &encoder{delimiter: delimiter}
+	err := e.encode(v)
+	return e.buf.String(), err
+}
+
+func TestEncoder(t *testing.T) {
+	debug := false
+	tests := []struct {
+		name      string
+		delimiter string
+		v         interface{}
+		want      string
+	}{
+		{name: "nil", v: nil, want: ""},
+		{name: "struct", v: document{
+			Name:   "app",
+			Server: server{Host: "a", Port: 80, Tags: []string{"x", "y"}, Debug: &debug},
+			Labels: map[string]string{"env": "prod", "team.owner": "ops"},
+		}, want: "name = app\n\n[server]\nhost = a\nport = 80\ntag = x\ntag = y\ndebug = false\n\n[labels]\nenv = prod\nteam.owner = ops\n"},
+		{name: "nested map", v: map[string]interface{}{"b": map[string]int{"c": 1}, "a": 1},
+			want: "a = 1\n\n[b]\nc = 1\n"},
+		{name: "quoted", v: map[string]string{"a": " x", "b": `"y"`, "c": "'", "d": ""},
+			want: "a = \" x\"\nb = \"\"y\"\"\nc = '\nd = \n"},
+		{name: "comment markers", v: map[string]string{"a": "x ; y", "b": `x" ; y`, "c": `a\;b`, "d": "#"},
+			want: "a = \"x ; y\"\nb = 'x\" ; y'\nc = \"a\\;b\"\nd = \"#\"\n"},
+		{name: "delimiter", delimiter: ":", v: map[string]int{"a": 1}, want: "a:1\n"},
+		{name: "value", v: encoding.NewObject(
+			&encoding.Member{Key: "name", Value: encoding.NewString("app"), Comment: "about\n\nthe app"},
+			&encoding.Member{Key: "server", Value: encoding.NewObject(
+				&encoding.Member{Key: "port", Value: encoding.NewNumber("80"), Comment: "port"},
+				&encoding.Member{Key: "tls", Value: encoding.NewObject(
+					&encoding.Member{Key: "on", Value: encoding.NewBool(true)},
+				)},
+				&encoding.Member{Key: "tag", Value: encoding.NewArray(encoding.NewString("x"), encoding.NewNull())},
+			), Comment: "main"},
+			&encoding.Member{Key: "empty", Value: encoding.NewObject()},
+		), want: "; about\n;\n; the app\nname = app\n\n; main\n[server]\n; port\nport = 80\ntag = x\ntag = \n\n[server.tls]\non = true\n\n[empty]\n"},
+		{name: "null value", v: (*encoding.Value)(nil), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshal(tt.delimiter, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "scalar", v: 1},
+		{name: "slice of structs", v: map[string][]server{"a": {{}}}},
+		{name: "key", v: map[string]int{"a=b": 1}},
+		{name: "comment key", v: map[string]int{"#a": 1}},
+		{name: "section", v: map[string]map[string]int{"a]": {"b": 1}}},
+		{name: "line break", v: map[string]string{"a": "x\ny"}},
+		{name: "unquotable value", v: map[string]string{"a": `x" ;' ;`}},
+		{name: "value scalar", v: encoding.NewString("x")},
+		{name: "value nested array", v: encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewArray(encoding.NewArray())})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := marshal("", tt.v); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package ini
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	tests := []struct {
+		delimiter string
+		want      string
+	}{
+		{delimiter: "=", want: "a=1\n"},
+		{delimiter: ": ", want: "a: 1\n"},
+	}
+	for _, tt := range tests {
+		got, err := WithEncoderOption(&codec{}, Delimiter(tt.delimiter)).Marshal(context.Background(), map[string]int{"a": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+	for _, delimiter := range []string{"-", "==", " = x"} {
+		if _, err := WithEncoderOption(&codec{}, Delimiter(delimiter)).Marshal(context.Background(), map[string]int{}); err == nil {
+			t.Errorf("want error of invalid delimiter %q", delimiter)
+		}
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	var v server
+	u := WithDecoderOption(&codec{}, DisallowUnknownFields())
+	if err := u.Unmarshal(context.Background(), []byte("host = a\nname = x\n"), &v); err == nil {
+		t.Error("want error of unknown field")
+	}
+	u = WithDecoderOption(&codec{}, DuplicateKeys(DuplicateFirst))
+	if err := u.Unmarshal(context.Background(), []byte("host = a\nhost = b\n"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Host != "a" {
+		t.Errorf("got %q, want %q", v.Host, "a")
+	}
+	u = WithDecoderOption(&codec{}, DuplicateKeys(DuplicateError))
+	if err := u.Unmarshal(context.Background(), []byte("host = a\nhost = b\n"), &v); err == nil {
+		t.Error("want error of duplicate key")
+	}
+}
@@ -0,0 +1,82 @@
+// Package ini defines and registers Marshaler/Unmarshaler handling INI
+// content.
+//
+// Keys before the first section header belong to the default section. Sections
+// are mapped to nested structs or maps: a key of section [a.b] is addressed by
+// the path a, b, key. Fields are named by the "ini" tag, and slices of scalars
+// are mapped to repeated keys. Lines starting with ";" or "#" are comments,
+// which are kept on the members of an encoding.Value, so that documents can be
+// round-tripped with their comments. Inline comments, starting at ";" or "#"
+// after whitespace, are dropped from section headers and values; "\;" and "\#"
+// stand for ";" and "#" in values. Values enclosed in matching quotes are
+// unquoted and taken literally.
+//
+// Content in charsets other than UTF-8 can be handled by composing the codec
+// with encoding.EncodeWithCharset/encoding.DecodingWithCharset filters.
+package ini
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "ini"
+
+var _tags = []string{"ini"}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	delimiter := config.Delimiter
+	if delimiter == "" {
+		delimiter = DefaultDelimiter
+	}
+	if d := strings.TrimSpace(delimiter); d != "=" && d != ":" || strings.Trim(delimiter, " =:") != "" {
+		return nil, fmt.Errorf("ini: invalid delimiter %q", delimiter)
+	}
+	e := &encoder{delimiter: delimiter}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("ini: can not unmarshal to non-pointer or nil %T", v)
+	}
+	sections, err := parse(data)
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.decode(sections, rv.Elem())
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,79 @@
+package ini
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestCodec_Struct(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	debug := true
+	in := document{
+		Name:    "app",
+		Server:  server{Host: "a", Port: 80, Tags: []string{"x"}, Debug: &debug},
+		Backup:  &server{Host: " b "},
+		Servers: map[string]server{"east": {Host: "e"}},
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out document
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v\n%s", out, in, data)
+	}
+}
+
+func TestCodec_ValueComments(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	data := []byte("; about\nname = app\n\n; main\n[server]\n; port\nport = 80\n\n[server.tls]\non = true\n")
+	var v encoding.Value
+	if err := u.Unmarshal(context.Background(), data, &v); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Marshal(context.Background(), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %q, want %q", got, data)
+	}
+}
+
+func TestCodec_Charset(t *testing.T) {
+	m := encoding.FilterMarshaler(encoding.GetMarshaler(Name), encoding.EncodeWithCharset("GBK"))
+	u := encoding.FilterUnmarshaler(encoding.GetUnmarshaler(Name), encoding.DecodingWithCharset("GBK"))
+	in := map[string]map[string]string{"s": {"name": "中文"}}
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte("[s]\nname = \xd6\xd0\xce\xc4\n"); !bytes.Equal(data, want) {
+		t.Errorf("got %q, want %q", data, want)
+	}
+	var out map[string]map[string]string
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %v, want %v", out, in)
+	}
+}
+
+func TestCodec_Unmarshal_NonPointer(t *testing.T) {
+	u := encoding.GetUnmarshaler(Name)
+	var v map[string]string
+	if err := u.Unmarshal(context.Background(), []byte("a = 1"), v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+	if err := u.Unmarshal(context.Background(), []byte("[a"), &v); err == nil {
+		t.Error("want syntax error")
+	}
+}
@@ -0,0 +1,137 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is an error of parsing INI content.
+type SyntaxError struct {
+	// Line is the 1-based line number where the error occurs.
+	Line int
+	msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("ini: line %d: %s", e.Line, e.msg)
+}
+
+// pair is a key/value pair of a section.
+type pair struct {
+	key     string
+	value   string
+	comment string
+	line    int
+}
+
+// section is a section of a document. The default section, which holds pairs
+// before the first section header, has an empty name.
+type section struct {
+	name    string
+	comment string
+	line    int
+	pairs   []*pair
+}
+
+// parse parses INI content into sections, the default one first. Sections
+// with the same name are merged. Comments are attached to the following
+// section header or key.
+func parse(data []byte) ([]*section, error) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+	sections := []*section{{line: 1}}
+	byName := map[string]*section{"": sections[0]}
+	current := sections[0]
+	var comment []string
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(strings.TrimSuffix(raw, "\r"))
+		lineNo := i + 1
+		switch {
+		case line == "":
+		case line[0] == ';' || line[0] == '#':
+			text := line[1:]
+			if strings.HasPrefix(text, " ") {
+				text = text[1:]
+			}
+			comment = append(comment, text)
+		case line[0] == '[':
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, &SyntaxError{Line: lineNo, msg: "expected ] of section header"}
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+				return nil, &SyntaxError{Line: lineNo, msg: fmt.Sprintf("unexpected %q after section header", rest)}
+			}
+			name := strings.TrimSpace(line[1:end])
+			if name == "" {
+				return nil, &SyntaxError{Line: lineNo, msg: "empty section name"}
+			}
+			s, ok := byName[name]
+			if !ok {
+				s = &section{name: name, line: lineNo}
+				byName[name] = s
+				sections = append(sections, s)
+			}
+			if len(comment) > 0 {
+				s.comment = joinComment(s.comment, comment)
+				comment = nil
+			}
+			current = s
+		default:
+			sep := strings.IndexAny(line, "=:")
+			if sep < 0 {
+				return nil, &SyntaxError{Line: lineNo, msg: fmt.Sprintf("expected = or : after key %q", line)}
+			}
+			key := strings.TrimSpace(line[:sep])
+			if key == "" {
+				return nil, &SyntaxError{Line: lineNo, msg: "empty key"}
+			}
+			current.pairs = append(current.pairs, &pair{
+				key:     key,
+				value:   parseValue(line[sep+1:]),
+				comment: strings.Join(comment, "\n"),
+				line:    lineNo,
+			})
+			comment = nil
+		}
+	}
+	return sections, nil
+}
+
+func joinComment(comment string, lines []string) string {
+	if comment == "" {
+		return strings.Join(lines, "\n")
+	}
+	return comment + "\n" + strings.Join(lines, "\n")
+}
+
+// parseValue returns the value of a key from the text after the delimiter.
+// An inline comment starts at ";" or "#" at the beginning of the value or after
+// whitespace, and is dropped. A value enclosed in matching quotes ends at the
+// first closing quote followed by the end of line or an inline comment, and is
+// taken literally. In other values, "\;" and "\#" stand for ";" and "#".
+func parseValue(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') {
+		for i := 1; i < len(s); i++ {
+			if s[i] == s[0] && isComment(strings.TrimSpace(s[i+1:])) {
+				return s[1:i]
+			}
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if (s[i] == ';' || s[i] == '#') && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			s = strings.TrimSpace(s[:i])
+			break
+		}
+	}
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	return strings.NewReplacer(`\;`, ";", `\#`, "#").Replace(s)
+}
+
+// isComment reports whether the rest of a line is empty or an inline comment.
+func isComment(rest string) bool {
+	return rest == "" || rest[0] == ';' || rest[0] == '#'
+}
@@ -0,0 +1,91 @@
+package ini
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := "\uFEFF; top\nname = app\r\n\n# server\n; settings\n[server] ; trailing\nhost: \"a b\" \nport=80 # http\n\n[ db ]\nuser = 'x'\nempty =\n[server]\nport = 81\n"
+	got, err := parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*section{
+		{line: 1, pairs: []*pair{{key: "name", value: "app", comment: "top", line: 2}}},
+		{name: "server", comment: "server\nsettings", line: 6, pairs: []*pair{
+			{key: "host", value: "a b", line: 7},
+			{key: "port", value: "80", line: 8},
+			{key: "port", value: "81", line: 14},
+		}},
+		{name: "db", line: 10, pairs: []*pair{
+			{key: "user", value: "x", line: 11},
+			{key: "empty", value: "", line: 12},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Logf("%+v", got[i])
+			for _, p := range got[i].pairs {
+				t.Logf("  %+v", p)
+			}
+		}
+		t.Error("unexpected sections")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		line int
+	}{
+		{name: "unclosed header", data: "a = 1\n[s\n", line: 2},
+		{name: "after header", data: "[s] x\n", line: 1},
+		{name: "empty section", data: "[ ]\n", line: 1},
+		{name: "no delimiter", data: "[s]\n\nkey\n", line: 3},
+		{name: "empty key", data: "= 1\n", line: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse([]byte(tt.data))
+			se, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("got %v, want *SyntaxError", err)
+			}
+			if se.Line != tt.line {
+				t.Errorf("got line %d, want %d", se.Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := map[string]string{
+		``:            ``,
+		`"`:           `"`,
+		`""`:          ``,
+		`"a"`:         `a`,
+		`'a'`:         `a`,
+		`"a'`:         `"a'`,
+		`"a"b"`:       `a"b`,
+		`x "a"`:       `x "a"`,
+		`'' ''`:       `' '`,
+		` a b `:       `a b`,
+		`a ; b`:       `a`,
+		`a	# b`:       `a`,
+		`; a`:         ``,
+		`a;b#c`:       `a;b#c`,
+		`a \; b \# c`: `a ; b # c`,
+		`a\b`:         `a\b`,
+		`"a ; b" ; c`: `a ; b`,
+		`'a # b'# c`:  `a # b`,
+		`"a \; b"`:    `a \; b`,
+		`"a" b ; "c"`: `a" b ; "c`,
+	}
+	for in, want := range tests {
+		if got := parseValue(in); got != want {
+			t.Errorf("parseValue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Package fields resolves the fields of struct types which codecs encode and
+// decode, according to struct tags.
+package fields
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Field is an encodable field of a struct type.
+type Field struct {
+	// Name is the key of the field, taken from the tag or the field name.
+	Name string
+	// Index is the index sequence for reflect.Value.FieldByIndex.
+	Index []int
+	// Type is the type of the field.
+	Type reflect.Type
+	// Tagged reports whether the name is taken from a tag.
+	Tagged bool
+	// Options are the comma separated options following the name in the tag.
+	Options Options
+}
+
+// OmitEmpty reports whether the field has the "omitempty" option.
+func (f *Field) OmitEmpty() bool {
+	return f.Options.Contains("omitempty")
+}
+
+// Options are the comma separated options of a struct tag.
+type Options string
+
+// Contains reports whether the options contain the flag name, or an option
+// in the form of "name=value".
+func (o Options) Contains(name string) bool {
+	_, ok := o.Lookup(name)
+	return ok
+}
+
+// Lookup returns the value of an option in the form of "name=value". If the
+// option is a flag without value, an empty string and true are returned.
+func (o Options) Lookup(name string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var opt string
+		if i := strings.IndexByte(s, ','); i >= 0 {
+			opt, s = s[:i], s[i+1:]
+		} else {
+			opt, s = s, ""
+		}
+		key, value := opt, ""
+		if i := strings.IndexByte(opt, '='); i >= 0 {
+			key, value = opt[:i], opt[i+1:]
+		}
+		if strings.TrimSpace(key) == name {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
+
+type cacheKey struct {
+	t    reflect.Type
+	tags string
+}
+
+var _cache sync.Map
+
+// Of returns the fields of the struct type t. The name and options of a field
+// are taken from the first non-empty tag in tags. Unexported fields and fields
+// tagged with "-" are skipped. Fields of anonymous struct fields without a tag
+// name, and of struct fields with the "inline" option, are promoted, following
+// the rules of encoding/json: a shallower field hides deeper ones, and among
+// fields at the same depth a tagged one wins, otherwise all of them are dropped.
+// The result is cached and must not be modified.
+func Of(t reflect.Type, tags ...string) []Field {
+	key := cacheKey{t: t, tags: strings.Join(tags, ",")}
+	if fields, ok := _cache.Load(key); ok {
+		return fields.([]Field)
+	}
+	fields, _ := _cache.LoadOrStore(key, resolve(t, tags))
+	return fields.([]Field)
+}
+
+// Lookup returns the tag name and options of a struct field, taken from the
+// first non-empty tag in tags.
+func Lookup(sf reflect.StructField, tags ...string) (name string, options Options, ok bool) {
+	for _, tag := range tags {
+		value, exist := sf.Tag.Lookup(tag)
+		if !exist || value == "" {
+			continue
+		}
+		if i := strings.IndexByte(value, ','); i >= 0 {
+			return value[:i], Options(value[i+1:]), true
+		}
+		return value, "", true
+	}
+	return "", "", false
+}
+
+func resolve(t reflect.Type, tags []string) []Field {
+	type candidate struct {
+		t     reflect.Type
+		index []int
+	}
+	var fields []Field
+	current := []candidate{}
+	next := []candidate{{t: t}}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		var depth []Field
+		for _, c := range current {
+			if visited[c.t] {
+				continue
+			}
+			visited[c.t] = true
+			for i := 0; i < c.t.NumField(); i++ {
+				sf := c.t.Field(i)
+				name, options, tagged := Lookup(sf, tags...)
+				if name == "-" && options == "" {
+					continue
+				}
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr && ft.Name() == "" {
+					ft = ft.Elem()
+				}
+				index := make([]int, len(c.index)+1)
+				copy(index, c.index)
+				index[len(c.index)] = i
+				inline := options.Contains("inline") && (sf.PkgPath == "" || sf.Anonymous)
+				if ft.Kind() == reflect.Struct && ((sf.Anonymous && name == "") || inline) {
+					next = append(next, candidate{t: ft, index: index})
+					continue
+				}
+				if sf.PkgPath != "" {
+					continue
+				}
+				if name == "" {
+					name = sf.Name
+				}
+				depth = append(depth, Field{
+					Name:    name,
+					Index:   index,
+					Type:    sf.Type,
+					Tagged:  tagged,
+					Options: options,
+				})
+			}
+		}
+		fields = append(fields, dominant(fields, depth)...)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return lessIndex(fields[i].Index, fields[j].Index)
+	})
+	return fields
+}
+
+// dominant returns fields of a depth which are not hidden by shallower fields
+// and not conflicting with each other.
+func dominant(shallower []Field, depth []Field) []Field {
+	taken := make(map[string]bool, len(shallower))
+	for _, f := range shallower {
+		taken[f.Name] = true
+	}
+	byName := make(map[string][]Field, len(depth))
+	var order []string
+	for _, f := range depth {
+		if taken[f.Name] {
+			continue
+		}
+		if _, ok := byName[f.Name]; !ok {
+			order = append(order, f.Name)
+		}
+		byName[f.Name] = append(byName[f.Name], f)
+	}
+	var result []Field
+	for _, name := range order {
+		candidates := byName[name]
+		if len(candidates) == 1 {
+			result = append(result, candidates[0])
+			continue
+		}
+		var tagged []Field
+		for _, f := range candidates {
+			if f.Tagged {
+				tagged = append(tagged, f)
+			}
+		}
+		if len(tagged) == 1 {
+			result = append(result, tagged[0])
+		}
+	}
+	return result
+}
+
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// ByIndex returns the field of the struct value v by index. If a pointer to
+// an embedded struct on the way is nil, a new struct will be allocated when
+// alloc is true, otherwise false will be returned.
+func ByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// IsEmpty reports whether v is the empty value of its type as defined by the
+// "omitempty" option of encoding/json.
+func IsEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
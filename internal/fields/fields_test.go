@@ -0,0 +1,95 @@
+package fields
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Base struct {
+	ID   int `form:"id"`
+	Name string
+}
+
+type Other struct {
+	Name string
+	Note string
+}
+
+type sample struct {
+	Base
+	*Other
+	Name    string `json:"name,omitempty"`
+	Skip    string `form:"-"`
+	private string
+	Inline  struct {
+		Deep string `form:"deep"`
+	} `form:",inline"`
+	Fallback string `json:"fb"`
+	Limit    int    `form:"limit,len=u16"`
+}
+
+func TestOf(t *testing.T) {
+	fields := Of(reflect.TypeOf(sample{}), "form", "json")
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	want := []string{"id", "Note", "name", "deep", "fb", "limit"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+	if !fields[2].OmitEmpty() || !fields[2].Tagged || fields[1].Tagged {
+		t.Errorf("unexpected field options %+v", fields)
+	}
+	if v, ok := fields[5].Options.Lookup("len"); !ok || v != "u16" {
+		t.Errorf("Lookup(len) = %q, %v", v, ok)
+	}
+	if &Of(reflect.TypeOf(sample{}), "form", "json")[0] != &fields[0] {
+		t.Errorf("expect cached result")
+	}
+}
+
+func TestOf_Conflict(t *testing.T) {
+	type A struct{ X, Y int }
+	type B struct {
+		X int
+		Y int `form:"Y"`
+	}
+	type C struct {
+		A
+		B
+	}
+	fields := Of(reflect.TypeOf(C{}), "form")
+	if len(fields) != 1 || fields[0].Name != "Y" || !reflect.DeepEqual(fields[0].Index, []int{1, 1}) {
+		t.Errorf("unexpected fields %+v", fields)
+	}
+}
+
+func TestByIndex(t *testing.T) {
+	v := reflect.ValueOf(&sample{}).Elem()
+	f := Of(v.Type(), "form", "json")[1]
+	if _, ok := ByIndex(v, f.Index, false); ok {
+		t.Errorf("expect nil embedded pointer not followed")
+	}
+	fv, ok := ByIndex(v, f.Index, true)
+	if !ok {
+		t.Fatalf("expect embedded pointer allocated")
+	}
+	fv.SetString("note")
+	if v.Interface().(sample).Other.Note != "note" {
+		t.Errorf("field not set")
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	for _, v := range []interface{}{0, "", false, 0.0, uint(0), []int{}, map[string]int{}, (*int)(nil)} {
+		if !IsEmpty(reflect.ValueOf(v)) {
+			t.Errorf("IsEmpty(%#v) expect true", v)
+		}
+	}
+	for _, v := range []interface{}{1, "a", true, struct{}{}} {
+		if IsEmpty(reflect.ValueOf(v)) {
+			t.Errorf("IsEmpty(%#v) expect false", v)
+		}
+	}
+}
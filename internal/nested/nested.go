@@ -0,0 +1,433 @@
+// Package nested maps Go values to and from flat documents whose keys are
+// paths of names, such as INI and Java properties files. Keys are arranged in
+// a tree: nested structs and maps are addressed by paths, and repeated keys
+// hold the elements of slices.
+package nested
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+var _valueType = reflect.TypeOf(encoding.Value{})
+
+// MaxDepth is the maximum number of names of a path, which bounds the
+// recursion of decoding the tree.
+const MaxDepth = 1000
+
+// Node is a node of the tree of keys.
+type Node struct {
+	// Values are the values of the key in order of appearance, and Lines are
+	// the 1-based line numbers where they appear.
+	Values []string
+	Lines  []int
+	// Keys are the names of children in order of appearance.
+	Keys     []string
+	Children map[string]*Node
+}
+
+// Add adds a value of the key at path. It fails if the path has more names
+// than MaxDepth.
+func (n *Node) Add(path []string, value string, line int) error {
+	if len(path) > MaxDepth {
+		return fmt.Errorf("key exceeds max depth %d", MaxDepth)
+	}
+	for _, name := range path {
+		n = n.child(name)
+	}
+	n.Values = append(n.Values, value)
+	n.Lines = append(n.Lines, line)
+	return nil
+}
+
+func (n *Node) child(name string) *Node {
+	if c, ok := n.Children[name]; ok {
+		return c
+	}
+	if n.Children == nil {
+		n.Children = map[string]*Node{}
+	}
+	c := &Node{}
+	n.Children[name] = c
+	n.Keys = append(n.Keys, name)
+	return c
+}
+
+// Decoder decodes a tree of keys into Go values.
+type Decoder struct {
+	// Prefix is the prefix of error messages, e.g. "ini".
+	Prefix string
+	// Tags are the struct tags naming fields.
+	Tags []string
+	// First decodes the first of repeated values into a non-slice value,
+	// instead of the last one.
+	First bool
+	// Multi decodes repeated values into []interface{} for interface{}.
+	Multi bool
+	// DisallowUnknownFields fails decoding a key which matches no field.
+	DisallowUnknownFields bool
+}
+
+func (d *Decoder) errorf(n *Node, path []string, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if len(n.Lines) > 0 {
+		return fmt.Errorf("%s: line %d: key %s: %s", d.Prefix, n.Lines[0], strings.Join(path, "."), msg)
+	}
+	return fmt.Errorf("%s: key %s: %s", d.Prefix, strings.Join(path, "."), msg)
+}
+
+func (d *Decoder) pick(n *Node) int {
+	if d.First {
+		return 0
+	}
+	return len(n.Values) - 1
+}
+
+// isLeaf reports whether values of type t are decoded from the values of a
+// key rather than its children: scalars, and slices and arrays of scalars.
+func isLeaf(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr && !textual.IsScalar(t) {
+		t = t.Elem()
+	}
+	if textual.IsScalar(t) {
+		return true
+	}
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && textual.IsScalar(t.Elem())
+}
+
+// Decode decodes the tree under n, at path, into v which must be settable.
+func (d *Decoder) Decode(n *Node, v reflect.Value, path []string) error {
+	if v.Type() == _valueType {
+		v.Set(reflect.ValueOf(*d.value(n)))
+		return nil
+	}
+	if textual.IsScalar(v.Type()) {
+		if len(n.Values) == 0 {
+			return nil
+		}
+		i := d.pick(n)
+		if err := textual.Unmarshal(n.Values[i], v); err != nil {
+			return d.errorf(&Node{Lines: n.Lines[i:]}, path, "%v", err)
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.Decode(n, v.Elem(), path)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		if x := d.any(n); x != nil {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if !textual.IsScalar(v.Type().Elem()) {
+			break
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(n.Values), len(n.Values)))
+		} else if len(n.Values) > v.Len() {
+			return d.errorf(n, path, "%d values overflow %s", len(n.Values), v.Type())
+		}
+		for i, value := range n.Values {
+			if err := textual.Unmarshal(value, v.Index(i)); err != nil {
+				return d.errorf(&Node{Lines: n.Lines[i:]}, path, "%v", err)
+			}
+		}
+		return nil
+	case reflect.Struct:
+		fs := fields.Of(v.Type(), d.Tags...)
+		for _, key := range n.Keys {
+			c := n.Children[key]
+			f := lookupField(fs, key)
+			if f == nil {
+				if d.DisallowUnknownFields {
+					return d.errorf(first(c), append(path[:len(path):len(path)], key), "unknown field")
+				}
+				continue
+			}
+			fv, ok := fields.ByIndex(v, f.Index, true)
+			if !ok {
+				return fmt.Errorf("%s: field %s: can not set embedded pointer to unexported struct", d.Prefix, f.Name)
+			}
+			if err := d.Decode(c, fv, append(path[:len(path):len(path)], key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		if isLeaf(v.Type().Elem()) {
+			return d.flatMap(n, v, path, nil)
+		}
+		for _, key := range n.Keys {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.Decode(n.Children[key], elem, append(path[:len(path):len(path)], key)); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		return nil
+	}
+	return d.errorf(first(n), path, "unsupported type %s", v.Type())
+}
+
+// flatMap decodes all keys with values under n into a map, keyed by their
+// paths relative to n joined with ".".
+func (d *Decoder) flatMap(n *Node, v reflect.Value, path, rel []string) error {
+	if len(rel) > 0 && len(n.Values) > 0 {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := d.Decode(n, elem, path); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(strings.Join(rel, ".")).Convert(v.Type().Key()), elem)
+	}
+	for _, key := range n.Keys {
+		childRel := append(rel[:len(rel):len(rel)], key)
+		if err := d.flatMap(n.Children[key], v, append(path[:len(path):len(path)], key), childRel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// first returns the first node under n with values, for the line number of
+// an error.
+func first(n *Node) *Node {
+	if len(n.Values) > 0 {
+		return n
+	}
+	for _, key := range n.Keys {
+		if c := first(n.Children[key]); len(c.Values) > 0 {
+			return c
+		}
+	}
+	return n
+}
+
+func lookupField(fs []fields.Field, key string) *fields.Field {
+	for i := range fs {
+		if fs[i].Name == key {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].Name, key) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+// any decodes n into a string, []interface{} of strings, or
+// map[string]interface{} if n has children.
+func (d *Decoder) any(n *Node) interface{} {
+	if len(n.Children) > 0 {
+		m := make(map[string]interface{}, len(n.Children))
+		for key, c := range n.Children {
+			m[key] = d.any(c)
+		}
+		return m
+	}
+	switch {
+	case len(n.Values) == 0:
+		return nil
+	case len(n.Values) > 1 && d.Multi:
+		items := make([]interface{}, len(n.Values))
+		for i, value := range n.Values {
+			items[i] = value
+		}
+		return items
+	}
+	return n.Values[d.pick(n)]
+}
+
+// value decodes n into a string Value, an array Value of strings, or an
+// object Value if n has children.
+func (d *Decoder) value(n *Node) *encoding.Value {
+	if len(n.Children) > 0 {
+		value := encoding.NewObject()
+		for _, key := range n.Keys {
+			value.Members = append(value.Members, &encoding.Member{Key: key, Value: d.value(n.Children[key])})
+		}
+		return value
+	}
+	switch {
+	case len(n.Values) == 0:
+		return encoding.NewNull()
+	case len(n.Values) > 1 && d.Multi:
+		value := encoding.NewArray()
+		for _, s := range n.Values {
+			value.Append(encoding.NewString(s))
+		}
+		return value
+	}
+	return encoding.NewString(n.Values[d.pick(n)])
+}
+
+// Entry is a key/value pair flattened from a Go value.
+type Entry struct {
+	Path  []string
+	Value string
+}
+
+// Flatten flattens v, a struct or a map with string keys, into key/value
+// pairs. Fields of structs are named by tags. Nested structs and maps make
+// longer paths, slices and arrays of scalars make repeated keys, and nil
+// values are omitted. Keys of maps are sorted. An encoding.Value is flattened
+// the same way, with null values flattened into empty values.
+func Flatten(v reflect.Value, prefix string, tags ...string) ([]Entry, error) {
+	f := &flattener{prefix: prefix, tags: tags}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
+		return nil, fmt.Errorf("%s: unsupported type %s", prefix, v.Type())
+	}
+	if err := f.flatten(nil, v); err != nil {
+		return nil, err
+	}
+	return f.entries, nil
+}
+
+type flattener struct {
+	prefix  string
+	tags    []string
+	entries []Entry
+}
+
+func (f *flattener) add(path []string, v reflect.Value) error {
+	s, err := textual.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s: key %s: %w", f.prefix, strings.Join(path, "."), err)
+	}
+	f.entries = append(f.entries, Entry{Path: path, Value: s})
+	return nil
+}
+
+func (f *flattener) flatten(path []string, v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == _valueType {
+		value := v.Interface().(encoding.Value)
+		return f.value(path, &value)
+	}
+	if len(path) > 0 && textual.IsScalar(v.Type()) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return nil
+		}
+		return f.add(path, v)
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return f.flatten(path, v.Elem())
+	case reflect.Struct:
+		for _, field := range fields.Of(v.Type(), f.tags...) {
+			fv, ok := fields.ByIndex(v, field.Index, false)
+			if !ok || field.OmitEmpty() && fields.IsEmpty(fv) {
+				continue
+			}
+			if err := f.flatten(append(path[:len(path):len(path)], field.Name), fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: unsupported map key type %s", f.prefix, v.Type().Key())
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			if err := f.flatten(append(path[:len(path):len(path)], k.String()), v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if len(path) == 0 || !textual.IsScalar(v.Type().Elem()) {
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := f.add(path, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: key %s: unsupported type %s", f.prefix, strings.Join(path, "."), v.Type())
+}
+
+func (f *flattener) value(path []string, value *encoding.Value) error {
+	switch value.Kind {
+	case encoding.ObjectKind:
+		for _, m := range value.Members {
+			if m.Value == nil {
+				continue
+			}
+			if err := f.value(append(path[:len(path):len(path)], m.Key), m.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case encoding.ArrayKind:
+		if len(path) == 0 {
+			break
+		}
+		for _, item := range value.Items {
+			if item.Kind == encoding.ArrayKind || item.Kind == encoding.ObjectKind {
+				return fmt.Errorf("%s: key %s: unsupported nested %s value", f.prefix, strings.Join(path, "."), item.Kind)
+			}
+			if err := f.value(path, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case encoding.NullKind:
+		if len(path) == 0 {
+			return nil
+		}
+		f.entries = append(f.entries, Entry{Path: path})
+		return nil
+	case encoding.BoolKind:
+		if len(path) == 0 {
+			break
+		}
+		f.entries = append(f.entries, Entry{Path: path, Value: strconv.FormatBool(value.Bool)})
+		return nil
+	default:
+		if len(path) == 0 {
+			break
+		}
+		f.entries = append(f.entries, Entry{Path: path, Value: value.Text})
+		return nil
+	}
+	return fmt.Errorf("%s: top-level value must be an object, not %s", f.prefix, value.Kind)
+}
@@ -0,0 +1,166 @@
+package nested
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+type inner struct {
+	Port  int      `kv:"port"`
+	Hosts []string `kv:"host"`
+}
+
+type outer struct {
+	Name   string            `kv:"name"`
+	Inner  inner             `kv:"inner"`
+	Ptr    *inner            `kv:"ptr"`
+	Labels map[string]string `kv:"labels"`
+	Nested map[string]inner  `kv:"nested"`
+	Any    interface{}       `kv:"any"`
+	Doc    encoding.Value    `kv:"doc"`
+	Empty  string            `kv:"empty,omitempty"`
+}
+
+func tree(pairs ...[]string) *Node {
+	n := &Node{}
+	for i, p := range pairs {
+		n.Add(p[:len(p)-1], p[len(p)-1], i+1)
+	}
+	return n
+}
+
+func TestNode_Add(t *testing.T) {
+	n := tree([]string{"a", "b", "1"}, []string{"a", "2"}, []string{"a", "b", "3"})
+	a := n.Children["a"]
+	if !reflect.DeepEqual(n.Keys, []string{"a"}) || !reflect.DeepEqual(a.Values, []string{"2"}) || !reflect.DeepEqual(a.Lines, []int{2}) {
+		t.Errorf("got node %+v", a)
+	}
+	if b := a.Children["b"]; !reflect.DeepEqual(b.Values, []string{"1", "3"}) || !reflect.DeepEqual(b.Lines, []int{1, 3}) {
+		t.Errorf("got node %+v", b)
+	}
+	path := make([]string, MaxDepth+1)
+	if err := n.Add(path, "x", 4); err == nil {
+		t.Error("want error of exceeding max depth")
+	}
+	if err := n.Add(path[1:], "x", 5); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	n := tree(
+		[]string{"name", "x"},
+		[]string{"name", "y"},
+		[]string{"inner", "port", "1"},
+		[]string{"inner", "host", "a"},
+		[]string{"inner", "host", "b"},
+		[]string{"ptr", "port", "2"},
+		[]string{"labels", "a", "1"},
+		[]string{"labels", "b", "c", "2"},
+		[]string{"nested", "k", "port", "3"},
+		[]string{"any", "a", "1"},
+		[]string{"any", "a", "2"},
+		[]string{"doc", "b", "1"},
+		[]string{"unknown", "1"},
+	)
+	tests := []struct {
+		name string
+		d    *Decoder
+		want outer
+	}{
+		{name: "last", d: &Decoder{Tags: []string{"kv"}}, want: outer{
+			Name:   "y",
+			Inner:  inner{Port: 1, Hosts: []string{"a", "b"}},
+			Ptr:    &inner{Port: 2},
+			Labels: map[string]string{"a": "1", "b.c": "2"},
+			Nested: map[string]inner{"k": {Port: 3}},
+			Any:    map[string]interface{}{"a": "2"},
+			Doc:    *encoding.NewObject(&encoding.Member{Key: "b", Value: encoding.NewString("1")}),
+		}},
+		{name: "first multi", d: &Decoder{Tags: []string{"kv"}, First: true, Multi: true}, want: outer{
+			Name:   "x",
+			Inner:  inner{Port: 1, Hosts: []string{"a", "b"}},
+			Ptr:    &inner{Port: 2},
+			Labels: map[string]string{"a": "1", "b.c": "2"},
+			Nested: map[string]inner{"k": {Port: 3}},
+			Any:    map[string]interface{}{"a": []interface{}{"1", "2"}},
+			Doc:    *encoding.NewObject(&encoding.Member{Key: "b", Value: encoding.NewString("1")}),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got outer
+			if err := tt.d.Decode(n, reflect.ValueOf(&got).Elem(), nil); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *Decoder
+		n    *Node
+		v    interface{}
+		want string
+	}{
+		{name: "type", d: &Decoder{Prefix: "kv"}, n: tree([]string{"inner", "port", "x"}), v: &outer{},
+			want: `kv: line 1: key inner.port: strconv.ParseInt: parsing "x": invalid syntax`},
+		{name: "unknown", d: &Decoder{Prefix: "kv", DisallowUnknownFields: true}, n: tree([]string{"name", "x"}, []string{"a", "b", "1"}), v: &outer{},
+			want: "kv: line 2: key a: unknown field"},
+		{name: "overflow", d: &Decoder{Prefix: "kv"}, n: tree([]string{"a", "1"}, []string{"a", "2"}), v: &map[string][1]int{},
+			want: "kv: line 1: key a: 2 values overflow [1]int"},
+		{name: "unsupported", d: &Decoder{Prefix: "kv"}, n: tree([]string{"a", "1"}), v: &map[string]chan int{},
+			want: "kv: line 1: key a: unsupported type chan int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.d.Decode(tt.n, reflect.ValueOf(tt.v).Elem(), nil)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	v := outer{
+		Name:   "x",
+		Inner:  inner{Port: 1, Hosts: []string{"a", "b"}},
+		Labels: map[string]string{"b": "2", "a": "1"},
+		Any:    []int{3},
+		Doc:    *encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewArray(encoding.NewBool(true), encoding.NewNull())}),
+	}
+	got, err := Flatten(reflect.ValueOf(&v), "kv", "kv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Path: []string{"name"}, Value: "x"},
+		{Path: []string{"inner", "port"}, Value: "1"},
+		{Path: []string{"inner", "host"}, Value: "a"},
+		{Path: []string{"inner", "host"}, Value: "b"},
+		{Path: []string{"labels", "a"}, Value: "1"},
+		{Path: []string{"labels", "b"}, Value: "2"},
+		{Path: []string{"any"}, Value: "3"},
+		{Path: []string{"doc", "a"}, Value: "true"},
+		{Path: []string{"doc", "a"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got, err := Flatten(reflect.ValueOf(nil), "kv"); got != nil || err != nil {
+		t.Errorf("got %v, %v, want nothing", got, err)
+	}
+	for _, v := range []interface{}{1, map[int]int{1: 1}, map[string]chan int{"a": nil}, map[string][]inner{"a": {{}}}} {
+		if _, err := Flatten(reflect.ValueOf(v), "kv"); err == nil {
+			t.Errorf("want error of %T", v)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package textual converts scalar values from and to their textual form, for
+// codecs of text based formats which have no type system of their own.
+package textual
+
+import (
+	se "encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+var (
+	_textMarshalerType   = reflect.TypeOf((*se.TextMarshaler)(nil)).Elem()
+	_textUnmarshalerType = reflect.TypeOf((*se.TextUnmarshaler)(nil)).Elem()
+)
+
+// IsScalar reports whether values of type t have a textual form: types which
+// implement encoding.TextMarshaler or encoding.TextUnmarshaler, booleans,
+// numbers, strings, byte slices and pointers to them.
+func IsScalar(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		if t.Implements(_textMarshalerType) || t.Implements(_textUnmarshalerType) {
+			return true
+		}
+		t = t.Elem()
+	}
+	if t.Implements(_textMarshalerType) || reflect.PtrTo(t).Implements(_textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// Marshal returns the textual form of v. A nil pointer or interface is
+// marshaled to an empty string.
+func Marshal(v reflect.Value) (string, error) {
+	for {
+		if !v.IsValid() {
+			return "", nil
+		}
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return "", nil
+		}
+		if v.Type().Implements(_textMarshalerType) {
+			text, err := v.Interface().(se.TextMarshaler).MarshalText()
+			if err != nil {
+				return "", err
+			}
+			return string(text), nil
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(_textMarshalerType) {
+		text, err := v.Addr().Interface().(se.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return FormatFloat(v.Float(), v.Type().Bits()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// FormatFloat formats a float the way encoding/json does: without exponent
+// unless the number is very small or very large.
+func FormatFloat(f float64, bits int) string {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21)) {
+		format = 'e'
+	}
+	return strconv.FormatFloat(f, format, -1, bits)
+}
+
+// Unmarshal parses text into v, which must be settable. Nil pointers on the
+// way are allocated.
+func Unmarshal(text string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().Implements(_textUnmarshalerType) {
+			return v.Interface().(se.TextUnmarshaler).UnmarshalText([]byte(text))
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(_textUnmarshalerType) {
+		return v.Addr().Interface().(se.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(text))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.SetBytes([]byte(text))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
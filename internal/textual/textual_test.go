@@ -0,0 +1,99 @@
+package textual
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshal(t *testing.T) {
+	n := 5
+	tm := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{true, "true"},
+		{"str", "str"},
+		{-3, "-3"},
+		{uint8(7), "7"},
+		{1.5, "1.5"},
+		{float32(0.1), "0.1"},
+		{1e21, "1e+21"},
+		{[]byte("raw"), "raw"},
+		{&n, "5"},
+		{(*int)(nil), ""},
+		{tm, "2021-06-01T08:00:00Z"},
+		{net.ParseIP("127.0.0.1"), "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		got, err := Marshal(reflect.ValueOf(tt.v))
+		if err != nil || got != tt.want {
+			t.Errorf("Marshal(%#v) = %q, %v, want %q", tt.v, got, err, tt.want)
+		}
+	}
+	if _, err := Marshal(reflect.ValueOf(struct{}{})); err == nil {
+		t.Errorf("Marshal(struct) expect error")
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var (
+		b   bool
+		i   int16
+		u   uint
+		f   float32
+		s   string
+		p   *int
+		tm  time.Time
+		iface interface{}
+		raw []byte
+	)
+	tests := []struct {
+		text string
+		v    interface{}
+		want interface{}
+	}{
+		{"true", &b, true},
+		{"-12", &i, int16(-12)},
+		{"12", &u, uint(12)},
+		{"0.5", &f, float32(0.5)},
+		{"str", &s, "str"},
+		{"3", &p, 3},
+		{"2021-06-01T08:00:00Z", &tm, time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)},
+		{"x", &iface, "x"},
+		{"raw", &raw, []byte("raw")},
+	}
+	for _, tt := range tests {
+		if err := Unmarshal(tt.text, reflect.ValueOf(tt.v).Elem()); err != nil {
+			t.Errorf("Unmarshal(%q) error %v", tt.text, err)
+			continue
+		}
+		got := reflect.ValueOf(tt.v).Elem()
+		if got.Kind() == reflect.Ptr {
+			got = got.Elem()
+		}
+		if !reflect.DeepEqual(got.Interface(), tt.want) {
+			t.Errorf("Unmarshal(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+	for _, text := range []string{"x", "70000"} {
+		if err := Unmarshal(text, reflect.ValueOf(&i).Elem()); err == nil {
+			t.Errorf("Unmarshal(%q) into int16 expect error", text)
+		}
+	}
+}
+
+func TestIsScalar(t *testing.T) {
+	for _, v := range []interface{}{1, "", (*int)(nil), time.Time{}, &time.Time{}, []byte{}, net.IP{}} {
+		if !IsScalar(reflect.TypeOf(v)) {
+			t.Errorf("IsScalar(%T) expect true", v)
+		}
+	}
+	for _, v := range []interface{}{struct{}{}, []int{}, map[string]string{}} {
+		if IsScalar(reflect.TypeOf(v)) {
+			t.Errorf("IsScalar(%T) expect false", v)
+		}
+	}
+}
@@ -31,7 +31,7 @@ func (a *asciiSafe) Marshal(ctx context.Context, v interface{}) ([]byte, error)
 		ch, _, err := reader.ReadRune()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				return buf.Bytes(), nil
+				return append([]byte(nil), buf.Bytes()...), nil
 			}
 			return nil, err
 		}
@@ -70,6 +70,20 @@ func Test_codec_Marshal(t *testing.T) {
 	}
 }
 
+func Test_codec_Marshal_Copy(t *testing.T) {
+	c := &codec{buf: _bufPool}
+	got, err := c.Marshal(context.Background(), "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Marshal(context.Background(), "second"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\"first\"\n"; string(got) != want {
+		t.Errorf("codec.Marshal() = %q after reuse of the buffer, want %q", got, want)
+	}
+}
+
 func Test_codec_Unmarshal(t *testing.T) {
 	type args struct {
 		ctx  context.Context
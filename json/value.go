@@ -0,0 +1,145 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-kita/encoding"
+)
+
+// decodeValue decodes a JSON value from the decoder into an *encoding.Value.
+// Key order of objects and the literal text of numbers are kept.
+func decodeValue(decoder *json.Decoder) (*encoding.Value, error) {
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return decodeToken(decoder, token)
+}
+
+func decodeToken(decoder *json.Decoder, token json.Token) (*encoding.Value, error) {
+	switch t := token.(type) {
+	case nil:
+		return encoding.NewNull(), nil
+	case bool:
+		return encoding.NewBool(t), nil
+	case json.Number:
+		return encoding.NewNumber(string(t)), nil
+	case string:
+		return encoding.NewString(t), nil
+	case json.Delim:
+		switch t {
+		case '[':
+			value := encoding.NewArray()
+			for decoder.More() {
+				item, err := decodeValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				value.Items = append(value.Items, item)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return value, nil
+		case '{':
+			value := encoding.NewObject()
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				member, err := decodeValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				value.Members = append(value.Members, &encoding.Member{Key: key.(string), Value: member})
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("json: unexpected token %v", token)
+}
+
+// encodeValue encodes an *encoding.Value into compact JSON.
+func encodeValue(value *encoding.Value) (json.RawMessage, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := writeValue(buf, encoder, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeValue(buf *bytes.Buffer, encoder *json.Encoder, value *encoding.Value) error {
+	if value == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	switch value.Kind {
+	case encoding.NullKind:
+		buf.WriteString("null")
+	case encoding.BoolKind:
+		if value.Bool {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case encoding.NumberKind:
+		if _, err := json.Marshal(json.Number(value.Text)); err != nil {
+			return err
+		}
+		buf.WriteString(value.Text)
+	case encoding.StringKind:
+		return writeString(buf, encoder, value.Text)
+	case encoding.ArrayKind:
+		buf.WriteByte('[')
+		for i, item := range value.Items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeValue(buf, encoder, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case encoding.ObjectKind:
+		buf.WriteByte('{')
+		for i, m := range value.Members {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeString(buf, encoder, m.Key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeValue(buf, encoder, m.Value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("json: unsupported value kind %s", value.Kind)
+	}
+	return nil
+}
+
+func writeString(buf *bytes.Buffer, encoder *json.Encoder, s string) error {
+	if err := encoder.Encode(s); err != nil {
+		return err
+	}
+	// json.Encoder terminates each value with a newline.
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
@@ -0,0 +1,64 @@
+package json
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestCodec_Value(t *testing.T) {
+	c := &codec{buf: _bufPool}
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "scalar", data: `12345678901234567890.000`, want: "12345678901234567890.000\n"},
+		{name: "null", data: `null`, want: "null\n"},
+		{
+			name: "ordered",
+			data: `{"z": 1, "a": [true, false, null, "<b>"], "m": {"y": 1e3, "x": "李"}}`,
+			want: `{"z":1,"a":[true,false,null,"\u003cb\u003e"],"m":{"y":1e3,"x":"李"}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := &encoding.Value{}
+			if err := c.Unmarshal(context.Background(), []byte(tt.data), value); err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.Marshal(context.Background(), value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodec_ValueOption(t *testing.T) {
+	value := encoding.NewObject(&encoding.Member{Key: "k", Value: encoding.NewString("<v>")})
+	m := WithEncoderOption(&codec{buf: _bufPool}, EscapeHTML(false), Indent("", " "))
+	got, err := m.Marshal(context.Background(), value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n \"k\": \"<v>\"\n}\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCodec_ValueError(t *testing.T) {
+	c := &codec{buf: _bufPool}
+	for _, data := range []string{``, `{"a":`, `[1,`, `{"a" 1}`} {
+		if err := c.Unmarshal(context.Background(), []byte(data), &encoding.Value{}); err == nil {
+			t.Errorf("unmarshal %q expect error", data)
+		}
+	}
+	if _, err := c.Marshal(context.Background(), encoding.NewNumber("abc")); err == nil {
+		t.Errorf("marshal invalid number expect error")
+	}
+}
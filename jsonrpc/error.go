@@ -0,0 +1,109 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Error codes defined by the specification. Codes from -32000 to -32099 are
+// reserved for implementation-defined server errors.
+const (
+	// ParseError means the JSON is malformed.
+	ParseError = -32700
+	// InvalidRequest means the JSON is not a valid message.
+	InvalidRequest = -32600
+	// MethodNotFound means the method does not exist or is not available.
+	MethodNotFound = -32601
+	// InvalidParams means the parameters of the method are invalid.
+	InvalidParams = -32602
+	// InternalError means an internal error of the server.
+	InternalError = -32603
+)
+
+// Error is the error object of a response. It is also returned by decoding
+// messages which break the rules of the specification, with the code
+// ParseError or InvalidRequest.
+type Error struct {
+	// Code is the type of the error.
+	Code int
+	// Message is a short description of the error.
+	Message string
+	// Data is additional information, omitted if nil. Data read from a
+	// message is a json.RawMessage, see DecodeData.
+	Data interface{}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %d: %s", e.Code, e.Message)
+}
+
+// DecodeData decodes the data of an error read from a message into v.
+func (e *Error) DecodeData(v interface{}) error {
+	return decodeRaw(e.Data, v)
+}
+
+type errorObject struct {
+	Code    *int            `json:"code"`
+	Message *string         `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	object := errorObject{Code: &e.Code, Message: &e.Message}
+	if e.Data != nil {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return nil, err
+		}
+		object.Data = data
+	}
+	return json.Marshal(&object)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The code and message are
+// required.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var object errorObject
+	if err := json.Unmarshal(data, &object); err != nil || isNull(data) {
+		return invalid("error is not an object")
+	}
+	if object.Code == nil || object.Message == nil {
+		return invalid("error without code or message")
+	}
+	*e = Error{Code: *object.Code, Message: *object.Message}
+	if object.Data != nil {
+		e.Data = object.Data
+	}
+	return nil
+}
+
+// checkSyntax returns an error of ParseError if data is not valid JSON.
+func checkSyntax(data []byte) error {
+	if !json.Valid(data) {
+		return &Error{Code: ParseError, Message: "Parse error"}
+	}
+	return nil
+}
+
+// invalid returns an error of an invalid message.
+func invalid(format string, args ...interface{}) *Error {
+	return &Error{Code: InvalidRequest, Message: "Invalid Request: " + fmt.Sprintf(format, args...)}
+}
+
+// decodeRaw decodes a json.RawMessage read from a message into v, leaving v
+// unchanged if the member is absent.
+func decodeRaw(raw interface{}, v interface{}) error {
+	if raw == nil {
+		return nil
+	}
+	data, ok := raw.(json.RawMessage)
+	if !ok {
+		return fmt.Errorf("jsonrpc: can not decode %T, which is not read from a message", raw)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func isNull(data []byte) bool {
+	return string(data) == "null"
+}
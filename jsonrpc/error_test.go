@@ -0,0 +1,98 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestError_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		e    *Error
+		want string
+	}{
+		{&Error{Code: MethodNotFound, Message: "Method not found"}, `{"code":-32601,"message":"Method not found"}`},
+		{&Error{Code: -32000, Message: "busy", Data: []int{1}}, `{"code":-32000,"message":"busy","data":[1]}`},
+	}
+	for _, test := range tests {
+		got, err := json.Marshal(test.e)
+		if err != nil {
+			t.Errorf("%v: %v", test.e, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestError_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		code    int
+		data2   string
+		invalid bool
+	}{
+		{`{"code":-32602,"message":"bad"}`, InvalidParams, "", false},
+		{`{"code":1,"message":"m","data":{"a":1}}`, 1, `{"a":1}`, false},
+		{`{"message":"m"}`, 0, "", true},
+		{`{"code":1}`, 0, "", true},
+		{`[]`, 0, "", true},
+		{`null`, 0, "", true},
+	}
+	for _, test := range tests {
+		var e Error
+		err := e.UnmarshalJSON([]byte(test.data))
+		if test.invalid {
+			if ee, ok := err.(*Error); !ok || ee.Code != InvalidRequest {
+				t.Errorf("%s: got error %v, want InvalidRequest", test.data, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.data, err)
+			continue
+		}
+		if e.Code != test.code {
+			t.Errorf("%s: got code %d, want %d", test.data, e.Code, test.code)
+		}
+		if test.data2 == "" {
+			if e.Data != nil {
+				t.Errorf("%s: got data %v, want nil", test.data, e.Data)
+			}
+			continue
+		}
+		var got map[string]int
+		if err := e.DecodeData(&got); err != nil || got["a"] != 1 {
+			t.Errorf("%s: got data %v, %v", test.data, got, err)
+		}
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	e := &Error{Code: InternalError, Message: "Internal error"}
+	if got, want := e.Error(), "jsonrpc: -32603: Internal error"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestError_DecodeData(t *testing.T) {
+	e := &Error{Data: "set by caller"}
+	var s string
+	if err := e.DecodeData(&s); err == nil {
+		t.Error("decoding data which is not read from a message succeeded")
+	}
+	e = &Error{}
+	if err := e.DecodeData(&s); err != nil || s != "" {
+		t.Errorf("got %q, %v, want unchanged", s, err)
+	}
+}
+
+func TestCheckSyntax(t *testing.T) {
+	if err := checkSyntax([]byte(`{"a":1}`)); err != nil {
+		t.Errorf("valid JSON: %v", err)
+	}
+	err := checkSyntax([]byte(`{"a":`))
+	if e, ok := err.(*Error); !ok || e.Code != ParseError {
+		t.Errorf("got %v, want ParseError", err)
+	}
+}
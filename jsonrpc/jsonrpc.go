@@ -0,0 +1,64 @@
+// Package jsonrpc defines and registers Marshaler/Unmarshaler handling JSON-RPC
+// 2.0 messages on top of the json codec.
+//
+// Request, Notification, Response and Error are written with the "jsonrpc"
+// member set, and Batch as an array of messages of any kind. Reading them
+// enforces the rules of the specification, such as the version, the types of
+// ids and results exclusive of errors, and returns an *Error with the code
+// ParseError or InvalidRequest otherwise, which is ready to be sent back.
+// Params, results and data of errors are kept as json.RawMessage when read,
+// and decoded later into types chosen by the caller, such as by the method:
+//
+//	var request jsonrpc.Request
+//	if err := unmarshaler.Unmarshal(ctx, data, &request); err != nil {
+//		return err
+//	}
+//	var params Params
+//	err := request.DecodeParams(&params)
+package jsonrpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/json"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "jsonrpc"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshal encodes v by the json codec, honoring its options.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	return encoding.GetMarshaler(json.Name).Marshal(ctx, v)
+}
+
+// Unmarshal decodes data by the json codec, such as into *Request, *Response,
+// *Message or *Batch. Malformed JSON is reported as an *Error of ParseError.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("jsonrpc: can not unmarshal to non-pointer or nil %T", v)
+	}
+	if err := checkSyntax(data); err != nil {
+		return err
+	}
+	return encoding.GetUnmarshaler(json.Name).Unmarshal(ctx, data, v)
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,59 @@
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	if encoding.GetMarshaler(Name) == nil || encoding.GetUnmarshaler(Name) == nil {
+		t.Errorf("codec is not registered as %q", Name)
+	}
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	c := &codec{}
+	got, err := c.Marshal(context.Background(), &Request{ID: IntID(1), Method: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := c.Marshal(context.Background(), &Notification{Method: "b"})
+	if want := `{"jsonrpc":"2.0","id":1,"method":"a"}` + "\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if want := `{"jsonrpc":"2.0","method":"b"}` + "\n"; string(other) != want {
+		t.Errorf("got %q, want %q", other, want)
+	}
+}
+
+func TestCodec_Unmarshal(t *testing.T) {
+	c := &codec{}
+	ctx := context.Background()
+	var m Message
+	if err := c.Unmarshal(ctx, []byte(`{"jsonrpc":"2.0","id":"a","method":"m","params":[]}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Request == nil || m.Request.ID != StringID("a") {
+		t.Errorf("got %#v", m)
+	}
+	tests := []struct {
+		data string
+		code int
+	}{
+		{`{"jsonrpc":"2.0",`, ParseError},
+		{`[`, ParseError},
+		{`{"jsonrpc":"2.0","id":{},"method":"m"}`, InvalidRequest},
+		{`"2.0"`, InvalidRequest},
+	}
+	for _, test := range tests {
+		err := c.Unmarshal(ctx, []byte(test.data), &m)
+		if e, ok := err.(*Error); !ok || e.Code != test.code {
+			t.Errorf("%s: got %v, want code %d", test.data, err, test.code)
+		}
+	}
+	if err := c.Unmarshal(ctx, []byte(`{}`), m); err == nil {
+		t.Error("unmarshaled to non-pointer")
+	}
+}
@@ -0,0 +1,406 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the protocol, which every message carries.
+const Version = "2.0"
+
+type idKind uint8
+
+const (
+	idNull idKind = iota
+	idString
+	idNumber
+)
+
+// ID identifies a request and its response. It is a string, a number, or
+// null, which is the zero value, for responses to requests whose id can not
+// be read. IDs are comparable, and can be used as keys of maps to match
+// responses with requests.
+type ID struct {
+	kind idKind
+	// value is the string, or the text of the number.
+	value string
+}
+
+// StringID returns an ID of the string s.
+func StringID(s string) ID {
+	return ID{kind: idString, value: s}
+}
+
+// IntID returns an ID of the number n.
+func IntID(n int64) ID {
+	return ID{kind: idNumber, value: strconv.FormatInt(n, 10)}
+}
+
+// IsNull reports whether the id is null.
+func (id ID) IsNull() bool {
+	return id.kind == idNull
+}
+
+// String returns the string, the text of the number, or "null".
+func (id ID) String() string {
+	if id.kind == idNull {
+		return "null"
+	}
+	return id.value
+}
+
+// MarshalJSON implements json.Marshaler.
+func (id ID) MarshalJSON() ([]byte, error) {
+	switch id.kind {
+	case idString:
+		return json.Marshal(id.value)
+	case idNumber:
+		return []byte(id.value), nil
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Ids other than strings, numbers
+// and null are invalid.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return invalid("malformed id")
+	}
+	switch vv := v.(type) {
+	case nil:
+		*id = ID{}
+	case string:
+		*id = StringID(vv)
+	case json.Number:
+		*id = ID{kind: idNumber, value: vv.String()}
+	default:
+		return invalid("id must be a string, a number or null")
+	}
+	return nil
+}
+
+// Request is a request of a method call, which expects a response.
+type Request struct {
+	// ID identifies the request.
+	ID ID
+	// Method is the name of the method.
+	Method string
+	// Params are the parameters, an object or an array, omitted if nil.
+	// Params read from a message are a json.RawMessage, see DecodeParams.
+	Params interface{}
+}
+
+// DecodeParams decodes the params read from a message into v, leaving v
+// unchanged if there are no params.
+func (r *Request) DecodeParams(v interface{}) error {
+	return decodeRaw(r.Params, v)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r Request) MarshalJSON() ([]byte, error) {
+	params, err := encodeParams(r.Params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&message{JSONRPC: Version, ID: &r.ID, Method: r.Method, Params: params})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It returns an *Error of
+// InvalidRequest if data is not a valid request.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	m, err := parse(data)
+	if err != nil {
+		return err
+	}
+	if m.ID == nil {
+		return invalid("request without id")
+	}
+	request, err := m.request()
+	if err != nil {
+		return err
+	}
+	*r = *request
+	return nil
+}
+
+// Notification is a request of a method call without id, which expects no
+// response.
+type Notification struct {
+	// Method is the name of the method.
+	Method string
+	// Params are the parameters, an object or an array, omitted if nil.
+	// Params read from a message are a json.RawMessage, see DecodeParams.
+	Params interface{}
+}
+
+// DecodeParams decodes the params read from a message into v, leaving v
+// unchanged if there are no params.
+func (n *Notification) DecodeParams(v interface{}) error {
+	return decodeRaw(n.Params, v)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (n Notification) MarshalJSON() ([]byte, error) {
+	params, err := encodeParams(n.Params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&message{JSONRPC: Version, Method: n.Method, Params: params})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It returns an *Error of
+// InvalidRequest if data is not a valid notification.
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	m, err := parse(data)
+	if err != nil {
+		return err
+	}
+	if m.ID != nil {
+		return invalid("notification with id")
+	}
+	request, err := m.request()
+	if err != nil {
+		return err
+	}
+	*n = Notification{Method: request.Method, Params: request.Params}
+	return nil
+}
+
+// Response is the response to a request, carrying either a result or an
+// error.
+type Response struct {
+	// ID is the id of the request.
+	ID ID
+	// Result is the result of a successful call, written as null if nil.
+	// Result read from a message is a json.RawMessage, see DecodeResult.
+	Result interface{}
+	// Error is the error of a failed call. Result is ignored if Error is
+	// set.
+	Error *Error
+}
+
+// DecodeResult decodes the result read from a message into v, leaving v
+// unchanged if the response is an error.
+func (r *Response) DecodeResult(v interface{}) error {
+	return decodeRaw(r.Result, v)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r Response) MarshalJSON() ([]byte, error) {
+	m := &message{JSONRPC: Version, ID: &r.ID, Error: r.Error}
+	if r.Error == nil {
+		result, err := json.Marshal(r.Result)
+		if err != nil {
+			return nil, err
+		}
+		m.Result = result
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It returns an *Error of
+// InvalidRequest if data is not a valid response.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	m, err := parse(data)
+	if err != nil {
+		return err
+	}
+	response, err := m.response()
+	if err != nil {
+		return err
+	}
+	*r = *response
+	return nil
+}
+
+// Message is a message of any kind, for reading messages whose kind is not
+// known in advance, such as elements of batches. Exactly one of the fields is
+// set.
+type Message struct {
+	Request      *Request
+	Notification *Notification
+	Response     *Response
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Message) MarshalJSON() ([]byte, error) {
+	switch {
+	case m.Request != nil:
+		return m.Request.MarshalJSON()
+	case m.Notification != nil:
+		return m.Notification.MarshalJSON()
+	case m.Response != nil:
+		return m.Response.MarshalJSON()
+	}
+	return nil, errors.New("jsonrpc: empty message")
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A message with a method is a
+// request, or a notification without id, and a message with a result or an
+// error is a response. It returns an *Error of InvalidRequest otherwise.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	raw, err := parse(data)
+	if err != nil {
+		return err
+	}
+	*m = Message{}
+	switch {
+	case raw.Method != nil:
+		request, err := raw.request()
+		if err != nil {
+			return err
+		}
+		if raw.ID == nil {
+			m.Notification = &Notification{Method: request.Method, Params: request.Params}
+		} else {
+			m.Request = request
+		}
+	case raw.Result != nil || raw.Error != nil:
+		m.Response, err = raw.response()
+	default:
+		err = invalid("neither request nor response")
+	}
+	return err
+}
+
+// Batch is an array of messages sent at once.
+type Batch []Message
+
+// MarshalJSON implements json.Marshaler. An empty batch is invalid.
+func (b Batch) MarshalJSON() ([]byte, error) {
+	if len(b) == 0 {
+		return nil, errors.New("jsonrpc: empty batch")
+	}
+	return json.Marshal([]Message(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It returns an *Error of
+// InvalidRequest if data is not a non-empty array of valid messages.
+func (b *Batch) UnmarshalJSON(data []byte) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil || isNull(data) {
+		return invalid("batch is not an array")
+	}
+	if len(elements) == 0 {
+		return invalid("empty batch")
+	}
+	batch := make(Batch, len(elements))
+	for i, element := range elements {
+		if err := batch[i].UnmarshalJSON(element); err != nil {
+			if e, ok := err.(*Error); ok {
+				return invalid("batch element %d: %s", i, strings.TrimPrefix(e.Message, "Invalid Request: "))
+			}
+			return err
+		}
+	}
+	*b = batch
+	return nil
+}
+
+// message is a message to write.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *ID             `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// rawMessage is a message read, whose members are nil if absent.
+type rawMessage struct {
+	JSONRPC *string         `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  *string         `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Result  json.RawMessage `json:"result"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// parse reads a message, checking it is an object of the version.
+func parse(data []byte) (*rawMessage, error) {
+	var m rawMessage
+	if err := json.Unmarshal(data, &m); err != nil || isNull(data) {
+		return nil, invalid("message is not an object")
+	}
+	if m.JSONRPC == nil || *m.JSONRPC != Version {
+		return nil, invalid("jsonrpc must be %q", Version)
+	}
+	return &m, nil
+}
+
+// request converts a message into a request, whose id is null for
+// notifications.
+func (m *rawMessage) request() (*Request, error) {
+	if m.Method == nil {
+		return nil, invalid("request without method")
+	}
+	if m.Result != nil || m.Error != nil {
+		return nil, invalid("request with result or error")
+	}
+	r := &Request{Method: *m.Method}
+	if m.ID != nil {
+		if err := r.ID.UnmarshalJSON(m.ID); err != nil {
+			return nil, err
+		}
+	}
+	if m.Params != nil {
+		if !structured(m.Params) {
+			return nil, invalid("params must be an object or an array")
+		}
+		r.Params = m.Params
+	}
+	return r, nil
+}
+
+// response converts a message into a response.
+func (m *rawMessage) response() (*Response, error) {
+	if m.Method != nil {
+		return nil, invalid("response with method")
+	}
+	if m.ID == nil {
+		return nil, invalid("response without id")
+	}
+	if (m.Result == nil) == (m.Error == nil) {
+		return nil, invalid("response must have either result or error")
+	}
+	r := &Response{}
+	if err := r.ID.UnmarshalJSON(m.ID); err != nil {
+		return nil, err
+	}
+	if m.Error != nil {
+		r.Error = &Error{}
+		if err := r.Error.UnmarshalJSON(m.Error); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	r.Result = m.Result
+	return r, nil
+}
+
+// encodeParams encodes params, which must be an object or an array.
+func encodeParams(params interface{}) (json.RawMessage, error) {
+	if params == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	if !structured(data) {
+		return nil, errors.New("jsonrpc: params must be an object or an array")
+	}
+	return data, nil
+}
+
+func structured(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && (data[0] == '{' || data[0] == '[')
+}
@@ -0,0 +1,207 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	tests := []struct {
+		data   string
+		want   ID
+		str    string
+		isNull bool
+	}{
+		{`"a1"`, StringID("a1"), "a1", false},
+		{`7`, IntID(7), "7", false},
+		{`-1.5`, ID{kind: idNumber, value: "-1.5"}, "-1.5", false},
+		{`null`, ID{}, "null", true},
+	}
+	for _, test := range tests {
+		var id ID
+		if err := json.Unmarshal([]byte(test.data), &id); err != nil {
+			t.Errorf("%s: %v", test.data, err)
+			continue
+		}
+		if id != test.want {
+			t.Errorf("%s: got %#v, want %#v", test.data, id, test.want)
+		}
+		if id.String() != test.str || id.IsNull() != test.isNull {
+			t.Errorf("%s: got %q, %v", test.data, id.String(), id.IsNull())
+		}
+		got, err := json.Marshal(id)
+		if err != nil || string(got) != test.data {
+			t.Errorf("%s: got %s, %v", test.data, got, err)
+		}
+	}
+	if StringID("1") == IntID(1) {
+		t.Error("string id equals number id")
+	}
+	for _, data := range []string{`true`, `{}`, `[1]`} {
+		var id ID
+		err := json.Unmarshal([]byte(data), &id)
+		if e, ok := err.(*Error); !ok || e.Code != InvalidRequest {
+			t.Errorf("%s: got %v, want InvalidRequest", data, err)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{Request{ID: IntID(1), Method: "sum", Params: []int{1, 2}},
+			`{"jsonrpc":"2.0","id":1,"method":"sum","params":[1,2]}`},
+		{&Request{ID: StringID("x"), Method: "ping"},
+			`{"jsonrpc":"2.0","id":"x","method":"ping"}`},
+		{Notification{Method: "update", Params: map[string]int{"a": 1}},
+			`{"jsonrpc":"2.0","method":"update","params":{"a":1}}`},
+		{Response{ID: IntID(1), Result: 3},
+			`{"jsonrpc":"2.0","id":1,"result":3}`},
+		{Response{ID: IntID(1)},
+			`{"jsonrpc":"2.0","id":1,"result":null}`},
+		{Response{Result: 3, Error: &Error{Code: ParseError, Message: "Parse error"}},
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`},
+		{Batch{{Request: &Request{ID: IntID(1), Method: "a"}}, {Notification: &Notification{Method: "b"}}},
+			`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","method":"b"}]`},
+	}
+	for _, test := range tests {
+		got, err := json.Marshal(test.v)
+		if err != nil {
+			t.Errorf("%#v: %v", test.v, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+	for _, v := range []interface{}{
+		Request{Method: "a", Params: 1},
+		Notification{Method: "a", Params: "s"},
+		Message{},
+		Batch{},
+	} {
+		if _, err := json.Marshal(v); err == nil {
+			t.Errorf("%#v: marshaled invalid message", v)
+		}
+	}
+}
+
+func TestRequest_UnmarshalJSON(t *testing.T) {
+	var r Request
+	data := `{"jsonrpc":"2.0","id":"r","method":"sum","params":{"a":1,"b":2}}`
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != StringID("r") || r.Method != "sum" {
+		t.Errorf("got %#v", r)
+	}
+	var params struct{ A, B int }
+	if err := r.DecodeParams(&params); err != nil || params.A != 1 || params.B != 2 {
+		t.Errorf("got params %+v, %v", params, err)
+	}
+
+	tests := []string{
+		`{"id":1,"method":"a"}`,
+		`{"jsonrpc":"1.0","id":1,"method":"a"}`,
+		`{"jsonrpc":"2.0","method":"a"}`,
+		`{"jsonrpc":"2.0","id":1}`,
+		`{"jsonrpc":"2.0","id":true,"method":"a"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"a","params":3}`,
+		`{"jsonrpc":"2.0","id":1,"method":"a","result":3}`,
+		`[]`,
+		`null`,
+	}
+	for _, data := range tests {
+		var r Request
+		err := json.Unmarshal([]byte(data), &r)
+		if e, ok := err.(*Error); !ok || e.Code != InvalidRequest {
+			t.Errorf("%s: got %v, want InvalidRequest", data, err)
+		}
+	}
+}
+
+func TestNotification_UnmarshalJSON(t *testing.T) {
+	var n Notification
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"tick","params":[1]}`), &n); err != nil {
+		t.Fatal(err)
+	}
+	var params []int
+	if err := n.DecodeParams(&params); err != nil || n.Method != "tick" || !reflect.DeepEqual(params, []int{1}) {
+		t.Errorf("got %#v, %v, %v", n, params, err)
+	}
+	err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":null,"method":"tick"}`), &n)
+	if e, ok := err.(*Error); !ok || e.Code != InvalidRequest {
+		t.Errorf("got %v, want InvalidRequest", err)
+	}
+}
+
+func TestResponse_UnmarshalJSON(t *testing.T) {
+	var r Response
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":3,"result":{"sum":3}}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	var result struct{ Sum int }
+	if err := r.DecodeResult(&result); err != nil || r.ID != IntID(3) || result.Sum != 3 || r.Error != nil {
+		t.Errorf("got %#v, %+v, %v", r, result, err)
+	}
+
+	r = Response{}
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if !r.ID.IsNull() || r.Error == nil || r.Error.Code != ParseError || r.Result != nil {
+		t.Errorf("got %#v", r)
+	}
+
+	tests := []string{
+		`{"jsonrpc":"2.0","id":1}`,
+		`{"jsonrpc":"2.0","result":1}`,
+		`{"jsonrpc":"2.0","id":1,"result":1,"error":{"code":1,"message":"m"}}`,
+		`{"jsonrpc":"2.0","id":1,"error":{"code":1}}`,
+		`{"jsonrpc":"2.0","id":1,"method":"a","result":1}`,
+		`{"jsonrpc":2,"id":1,"result":1}`,
+	}
+	for _, data := range tests {
+		var r Response
+		err := json.Unmarshal([]byte(data), &r)
+		if e, ok := err.(*Error); !ok || e.Code != InvalidRequest {
+			t.Errorf("%s: got %v, want InvalidRequest", data, err)
+		}
+	}
+}
+
+func TestBatch_UnmarshalJSON(t *testing.T) {
+	var b Batch
+	data := `[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","method":"b"},{"jsonrpc":"2.0","id":1,"result":null}]`
+	if err := json.Unmarshal([]byte(data), &b); err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 3 || b[0].Request == nil || b[1].Notification == nil || b[2].Response == nil {
+		t.Fatalf("got %#v", b)
+	}
+	var result interface{} = "unchanged"
+	if err := b[2].Response.DecodeResult(&result); err != nil || result != nil {
+		t.Errorf("got result %v, %v, want nil", result, err)
+	}
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`[]`, "Invalid Request: empty batch"},
+		{`{}`, "Invalid Request: batch is not an array"},
+		{`null`, "Invalid Request: batch is not an array"},
+		{`[{"jsonrpc":"2.0","method":"a"},1]`, "Invalid Request: batch element 1: message is not an object"},
+		{`[{"jsonrpc":"2.0"}]`, "Invalid Request: batch element 0: neither request nor response"},
+	}
+	for _, test := range tests {
+		var b Batch
+		err := json.Unmarshal([]byte(test.data), &b)
+		if e, ok := err.(*Error); !ok || e.Code != InvalidRequest || e.Message != test.want {
+			t.Errorf("%s: got %v, want %q", test.data, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// Algorithms of signatures.
+const (
+	// HS256 is HMAC using SHA-256.
+	HS256 = "HS256"
+	// RS256 is RSASSA-PKCS1-v1_5 using SHA-256.
+	RS256 = "RS256"
+	// ES256 is ECDSA using P-256 and SHA-256.
+	ES256 = "ES256"
+	// EdDSA is Ed25519.
+	EdDSA = "EdDSA"
+)
+
+// algorithm signs the signing input of tokens, and verifies signatures. verify
+// returns an error only if the key does not fit the algorithm.
+type algorithm struct {
+	sign   func(key interface{}, input []byte) ([]byte, error)
+	verify func(key interface{}, input, sig []byte) (bool, error)
+}
+
+var algorithms = map[string]algorithm{
+	HS256: {signHMAC, verifyHMAC},
+	RS256: {signRSA, verifyRSA},
+	ES256: {signECDSA, verifyECDSA},
+	EdDSA: {signEd25519, verifyEd25519},
+}
+
+func keyError(alg string, key interface{}) error {
+	return fmt.Errorf("jws: invalid key %T for %s", key, alg)
+}
+
+func digest(input []byte) []byte {
+	sum := sha256.Sum256(input)
+	return sum[:]
+}
+
+func hmacKey(key interface{}) ([]byte, error) {
+	k, ok := key.([]byte)
+	if !ok || len(k) == 0 {
+		return nil, keyError(HS256, key)
+	}
+	return k, nil
+}
+
+func signHMAC(key interface{}, input []byte) ([]byte, error) {
+	k, err := hmacKey(key)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, k)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
+
+func verifyHMAC(key interface{}, input, sig []byte) (bool, error) {
+	want, err := signHMAC(key, input)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(sig, want), nil
+}
+
+func signRSA(key interface{}, input []byte) ([]byte, error) {
+	k, ok := key.(*rsa.PrivateKey)
+	if !ok || k == nil {
+		return nil, keyError(RS256, key)
+	}
+	return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest(input))
+}
+
+func verifyRSA(key interface{}, input, sig []byte) (bool, error) {
+	var k *rsa.PublicKey
+	switch kk := key.(type) {
+	case *rsa.PublicKey:
+		k = kk
+	case *rsa.PrivateKey:
+		if kk != nil {
+			k = &kk.PublicKey
+		}
+	}
+	if k == nil {
+		return false, keyError(RS256, key)
+	}
+	return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest(input), sig) == nil, nil
+}
+
+// es256Size is the size of each of r and s of ES256 signatures.
+const es256Size = 32
+
+func signECDSA(key interface{}, input []byte) ([]byte, error) {
+	k, ok := key.(*ecdsa.PrivateKey)
+	if !ok || k == nil || k.Curve != elliptic.P256() {
+		return nil, keyError(ES256, key)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, k, digest(input))
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 2*es256Size)
+	r.FillBytes(sig[:es256Size])
+	s.FillBytes(sig[es256Size:])
+	return sig, nil
+}
+
+func verifyECDSA(key interface{}, input, sig []byte) (bool, error) {
+	var k *ecdsa.PublicKey
+	switch kk := key.(type) {
+	case *ecdsa.PublicKey:
+		k = kk
+	case *ecdsa.PrivateKey:
+		if kk != nil {
+			k = &kk.PublicKey
+		}
+	}
+	if k == nil || k.Curve != elliptic.P256() {
+		return false, keyError(ES256, key)
+	}
+	if len(sig) != 2*es256Size {
+		return false, nil
+	}
+	r := new(big.Int).SetBytes(sig[:es256Size])
+	s := new(big.Int).SetBytes(sig[es256Size:])
+	return ecdsa.Verify(k, digest(input), r, s), nil
+}
+
+func signEd25519(key interface{}, input []byte) ([]byte, error) {
+	k, ok := key.(ed25519.PrivateKey)
+	if !ok || len(k) != ed25519.PrivateKeySize {
+		return nil, keyError(EdDSA, key)
+	}
+	return ed25519.Sign(k, input), nil
+}
+
+func verifyEd25519(key interface{}, input, sig []byte) (bool, error) {
+	var k ed25519.PublicKey
+	switch kk := key.(type) {
+	case ed25519.PublicKey:
+		k = kk
+	case ed25519.PrivateKey:
+		if len(kk) == ed25519.PrivateKeySize {
+			k = kk.Public().(ed25519.PublicKey)
+		}
+	}
+	if len(k) != ed25519.PublicKeySize {
+		return false, keyError(EdDSA, key)
+	}
+	return ed25519.Verify(k, input, sig), nil
+}
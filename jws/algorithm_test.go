@@ -0,0 +1,118 @@
+package jws
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+	"testing"
+)
+
+type testKeys struct {
+	rsa   *rsa.PrivateKey
+	ec    *ecdsa.PrivateKey
+	ec384 *ecdsa.PrivateKey
+	ed    ed25519.PrivateKey
+}
+
+var (
+	_keys     testKeys
+	_keysOnce sync.Once
+)
+
+func keys(t *testing.T) testKeys {
+	_keysOnce.Do(func() {
+		var err error
+		if _keys.rsa, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			t.Fatal(err)
+		}
+		if _keys.ec, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+		if _keys.ec384, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+		if _, _keys.ed, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return _keys
+}
+
+func TestAlgorithms(t *testing.T) {
+	k := keys(t)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tests := []struct {
+		alg      string
+		signKey  interface{}
+		pubKey   interface{}
+		otherKey interface{}
+	}{
+		{HS256, []byte("secret"), []byte("secret"), []byte("other")},
+		{RS256, k.rsa, &k.rsa.PublicKey, k.rsa},
+		{ES256, k.ec, &k.ec.PublicKey, &other.PublicKey},
+		{EdDSA, k.ed, k.ed.Public(), k.ed},
+	}
+	input := []byte("header.payload")
+	for _, test := range tests {
+		alg := algorithms[test.alg]
+		sig, err := alg.sign(test.signKey, input)
+		if err != nil {
+			t.Errorf("%s: %v", test.alg, err)
+			continue
+		}
+		for _, key := range []interface{}{test.pubKey, test.signKey} {
+			if ok, err := alg.verify(key, input, sig); !ok || err != nil {
+				t.Errorf("%s: %T does not verify: %v", test.alg, key, err)
+			}
+		}
+		if ok, _ := alg.verify(test.pubKey, []byte("header.tampered"), sig); ok {
+			t.Errorf("%s: verified tampered input", test.alg)
+		}
+		if test.alg != RS256 && test.alg != EdDSA {
+			if ok, _ := alg.verify(test.otherKey, input, sig); ok {
+				t.Errorf("%s: verified by other key", test.alg)
+			}
+		}
+	}
+	if sig, _ := signECDSA(k.ec, input); len(sig) != 64 {
+		t.Errorf("got ES256 signature of %d bytes, want 64", len(sig))
+	}
+}
+
+func TestAlgorithms_InvalidKey(t *testing.T) {
+	k := keys(t)
+	tests := []struct {
+		alg string
+		key interface{}
+	}{
+		{HS256, "secret"},
+		{HS256, []byte{}},
+		{RS256, &k.rsa.PublicKey},
+		{RS256, k.ec},
+		{ES256, k.ec384},
+		{ES256, []byte("secret")},
+		{EdDSA, k.ed.Public()},
+		{EdDSA, (*rsa.PrivateKey)(nil)},
+	}
+	for _, test := range tests {
+		if _, err := algorithms[test.alg].sign(test.key, []byte("x")); err == nil {
+			t.Errorf("%s: signed with %T", test.alg, test.key)
+		}
+	}
+	for _, test := range []struct {
+		alg string
+		key interface{}
+	}{
+		{RS256, k.ec},
+		{ES256, &k.ec384.PublicKey},
+		{EdDSA, ed25519.PublicKey{1}},
+		{HS256, nil},
+	} {
+		if _, err := algorithms[test.alg].verify(test.key, []byte("x"), nil); err == nil {
+			t.Errorf("%s: verified with %T", test.alg, test.key)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package jws
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Algorithm is the algorithm signing tokens, such as HS256.
+	Algorithm string
+	// Key is the signing key of the algorithm: []byte for HS256,
+	// *rsa.PrivateKey for RS256, *ecdsa.PrivateKey of P-256 for ES256 and
+	// ed25519.PrivateKey for EdDSA.
+	Key interface{}
+	// KeyID is the "kid" header parameter, omitted if empty.
+	KeyID string
+	// Type is the "typ" header parameter, DefaultType if empty.
+	Type string
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// Keys are the keys verifying signatures by algorithm, which is the
+	// whitelist of algorithms: tokens of the other algorithms are rejected.
+	// The public key of a signing key, or the key itself for HS256, verifies
+	// its signatures.
+	Keys map[string][]interface{}
+	// Audience is the audience which the "aud" claim must contain, unchecked
+	// if empty.
+	Audience string
+	// Issuer is the issuer which the "iss" claim must be, unchecked if empty.
+	Issuer string
+	// Leeway is the clock skew tolerated checking the "exp", "nbf" and "iat"
+	// claims.
+	Leeway time.Duration
+	// Now returns the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// VerifyWith produces a DecoderOption which allows the algorithm, verifying
+// signatures with the key. It can be used multiple times, such as for keys of
+// other algorithms, or rotated keys of the same algorithm.
+func VerifyWith(alg string, key interface{}) DecoderOption {
+	return func(config *DecoderConfig) {
+		if config.Keys == nil {
+			config.Keys = map[string][]interface{}{}
+		}
+		config.Keys[alg] = append(config.Keys[alg], key)
+	}
+}
+
+// ExpectAudience produces a DecoderOption which requires the "aud" claim to
+// contain the audience.
+func ExpectAudience(aud string) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Audience = aud
+	}
+}
+
+// ExpectIssuer produces a DecoderOption which requires the "iss" claim to be
+// the issuer.
+func ExpectIssuer(iss string) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Issuer = iss
+	}
+}
+
+// Leeway produces a DecoderOption which tolerates the clock skew checking the
+// "exp", "nbf" and "iat" claims.
+func Leeway(d time.Duration) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Leeway = d
+	}
+}
+
+// Clock produces a DecoderOption which takes the current time from now.
+func Clock(now func() time.Time) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Now = now
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// SignWith produces an EncoderOption which signs tokens by the algorithm with
+// the key.
+func SignWith(alg string, key interface{}) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Algorithm = alg
+		config.Key = key
+	}
+}
+
+// KeyID produces an EncoderOption which sets the "kid" header parameter.
+func KeyID(kid string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.KeyID = kid
+	}
+}
+
+// TokenType produces an EncoderOption which sets the "typ" header parameter.
+func TokenType(typ string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Type = typ
+	}
+}
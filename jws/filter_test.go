@@ -0,0 +1,36 @@
+package jws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncoderOptions(t *testing.T) {
+	config := &EncoderConfig{}
+	for _, option := range []EncoderOption{SignWith(HS256, []byte("k")), KeyID("k1"), TokenType("at+jwt")} {
+		option(config)
+	}
+	want := &EncoderConfig{Algorithm: HS256, Key: []byte("k"), KeyID: "k1", Type: "at+jwt"}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
+
+func TestDecoderOptions(t *testing.T) {
+	config := &DecoderConfig{}
+	now := time.Unix(1, 0)
+	for _, option := range []DecoderOption{
+		VerifyWith(HS256, []byte("a")), VerifyWith(HS256, []byte("b")), VerifyWith(EdDSA, nil),
+		ExpectAudience("api"), ExpectIssuer("auth"), Leeway(time.Second), Clock(func() time.Time { return now }),
+	} {
+		option(config)
+	}
+	keys := map[string][]interface{}{HS256: {[]byte("a"), []byte("b")}, EdDSA: {nil}}
+	if !reflect.DeepEqual(config.Keys, keys) {
+		t.Errorf("got keys %v, want %v", config.Keys, keys)
+	}
+	if config.Audience != "api" || config.Issuer != "auth" || config.Leeway != time.Second || config.Now() != now {
+		t.Errorf("got %+v", config)
+	}
+}
@@ -0,0 +1,86 @@
+// Package jws defines and registers Marshaler/Unmarshaler handling JSON Web
+// Tokens in the compact serialization of JSON Web Signatures.
+//
+// Claims of any type, such as structs embedding Claims, are marshaled by the
+// json codec and signed by the Sign filter with the algorithm and the key set
+// by the SignWith option: HS256, RS256, ES256 or EdDSA. Tokens are verified by
+// the Verify filter before the claims are unmarshaled by the json codec: the
+// algorithm must be allowed and the signature verified by a key set by the
+// VerifyWith option, the "exp", "nbf" and "iat" claims are checked within the
+// Leeway, and the "aud" and "iss" claims against ExpectAudience and
+// ExpectIssuer.
+package jws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/json"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "jws"
+
+// MIMEType is the media type of JSON Web Tokens.
+const MIMEType = "application/jwt"
+
+var (
+	// ErrMalformed is returned when a token is not in the compact
+	// serialization, or its header or claims are not JSON objects.
+	ErrMalformed = errors.New("jws: malformed token")
+	// ErrAlgorithm is returned when the algorithm of a token is not allowed.
+	ErrAlgorithm = errors.New("jws: algorithm not allowed")
+	// ErrSignature is returned when no key verifies the signature of a token.
+	ErrSignature = errors.New("jws: invalid signature")
+	// ErrExpired is returned when a token is expired by the "exp" claim.
+	ErrExpired = errors.New("jws: token is expired")
+	// ErrNotValidYet is returned when a token is not valid yet by the "nbf"
+	// claim.
+	ErrNotValidYet = errors.New("jws: token is not valid yet")
+	// ErrIssuedInFuture is returned when a token is issued in the future by
+	// the "iat" claim.
+	ErrIssuedInFuture = errors.New("jws: token is issued in the future")
+	// ErrAudience is returned when the "aud" claim does not contain the
+	// expected audience.
+	ErrAudience = errors.New("jws: invalid audience")
+	// ErrIssuer is returned when the "iss" claim is not the expected issuer.
+	ErrIssuer = errors.New("jws: invalid issuer")
+)
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshal encodes v by the json codec into the claims of a token signed by
+// the Sign filter.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	marshaler := encoding.FilterMarshaler(encoding.GetMarshaler(json.Name), Sign(encoderOptionFromContext(ctx)...))
+	return marshaler.Marshal(ctx, v)
+}
+
+// Unmarshal verifies a token by the Verify filter, and decodes its claims into
+// v by the json codec.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("jws: can not unmarshal to non-pointer or nil %T", v)
+	}
+	unmarshaler := encoding.FilterUnmarshaler(encoding.GetUnmarshaler(json.Name), Verify(decoderOptionFromContext(ctx)...))
+	return unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
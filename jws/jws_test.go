@@ -0,0 +1,101 @@
+package jws
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+type session struct {
+	Claims
+	Roles []string `json:"roles"`
+}
+
+func TestCodec(t *testing.T) {
+	k := keys(t)
+	ctx := context.Background()
+	now := time.Now()
+	in := session{
+		Claims: Claims{Issuer: "auth", Subject: "u1", Audience: Audience{"api"}, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()},
+		Roles:  []string{"admin"},
+	}
+	tests := []struct {
+		alg       string
+		signKey   interface{}
+		verifyKey interface{}
+	}{
+		{HS256, []byte("secret"), []byte("secret")},
+		{RS256, k.rsa, &k.rsa.PublicKey},
+		{ES256, k.ec, &k.ec.PublicKey},
+		{EdDSA, k.ed, k.ed.Public()},
+	}
+	for _, test := range tests {
+		m := WithEncoderOption(&codec{}, SignWith(test.alg, test.signKey))
+		token, err := m.Marshal(ctx, &in)
+		if err != nil {
+			t.Errorf("%s: %v", test.alg, err)
+			continue
+		}
+		u := WithDecoderOption(&codec{}, VerifyWith(test.alg, test.verifyKey), ExpectAudience("api"), ExpectIssuer("auth"))
+		var out session
+		if err := u.Unmarshal(ctx, token, &out); err != nil {
+			t.Errorf("%s: %v", test.alg, err)
+			continue
+		}
+		if out.Subject != "u1" || len(out.Roles) != 1 || out.Roles[0] != "admin" || out.ExpiresAt != in.ExpiresAt {
+			t.Errorf("%s: got %+v", test.alg, out)
+		}
+	}
+
+	token, _ := WithEncoderOption(&codec{}, SignWith(HS256, []byte("secret"))).Marshal(ctx, &in)
+	out := session{Roles: []string{"kept"}}
+	err := WithDecoderOption(&codec{}, VerifyWith(ES256, &k.ec.PublicKey)).Unmarshal(ctx, token, &out)
+	if err == nil || out.Roles[0] != "kept" {
+		t.Errorf("got %v, %+v, want rejected algorithm", err, out)
+	}
+	if err := (&codec{}).Unmarshal(ctx, token, out); err == nil {
+		t.Error("unmarshaled to non-pointer")
+	}
+	if _, err := (&codec{}).Marshal(ctx, &in); err == nil {
+		t.Error("marshaled without signing key")
+	}
+}
+
+func TestCodec_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	m := WithEncoderOption(&codec{}, SignWith(HS256, []byte("secret")))
+	u := WithDecoderOption(&codec{}, VerifyWith(HS256, []byte("secret")))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 5000; i++ {
+				subject := strconv.Itoa(g) + "/" + strconv.Itoa(i)
+				token, err := m.Marshal(ctx, &Claims{Subject: subject})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				var out Claims
+				if err := u.Unmarshal(ctx, token, &out); err != nil || out.Subject != subject {
+					t.Errorf("got %q, %v, want subject %q", out.Subject, err, subject)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
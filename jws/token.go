@@ -0,0 +1,265 @@
+package jws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+// DefaultType is the "typ" header parameter of tokens by default.
+const DefaultType = "JWT"
+
+// header is the protected header of a token.
+type header struct {
+	Algorithm string   `json:"alg"`
+	KeyID     string   `json:"kid,omitempty"`
+	Type      string   `json:"typ,omitempty"`
+	Critical  []string `json:"crit,omitempty"`
+}
+
+// Audience is the "aud" claim, a single string or an array of strings.
+type Audience []string
+
+// Contains reports whether aud is one of the audience.
+func (a Audience) Contains(aud string) bool {
+	for _, s := range a {
+		if s == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements json.Marshaler, writing a single audience as a
+// string.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Claims are the registered claims, which can be embedded into structs of
+// claims. Times are in seconds since the Unix epoch.
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// registered are the registered claims checked by verifying, whose times
+// can be fractional.
+type registered struct {
+	Issuer    string   `json:"iss"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	IssuedAt  *float64 `json:"iat"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// Sign produces a FilterFunc which signs claims encoded in a JSON object into
+// a compact token, such as by decorating the json codec:
+//
+//	encoding.FilterMarshaler(encoding.GetMarshaler(json.Name), jws.Sign(jws.SignWith(jws.HS256, key)))
+func Sign(opt ...EncoderOption) encoding.FilterFunc {
+	config := &EncoderConfig{}
+	for _, option := range opt {
+		option(config)
+	}
+	return func(pre []byte) ([]byte, error) {
+		return sign(config, pre)
+	}
+}
+
+// Verify produces a FilterFunc which verifies a compact token and its claims,
+// and returns the claims encoded in a JSON object, such as by decorating the
+// json codec:
+//
+//	encoding.FilterUnmarshaler(encoding.GetUnmarshaler(json.Name), jws.Verify(jws.VerifyWith(jws.HS256, key)))
+func Verify(opt ...DecoderOption) encoding.FilterFunc {
+	config := &DecoderConfig{}
+	for _, option := range opt {
+		option(config)
+	}
+	return func(pre []byte) ([]byte, error) {
+		return verify(config, pre)
+	}
+}
+
+func sign(config *EncoderConfig, payload []byte) ([]byte, error) {
+	if config.Algorithm == "" {
+		return nil, fmt.Errorf("jws: no signing algorithm")
+	}
+	alg, ok := algorithms[config.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("jws: unsupported algorithm %q", config.Algorithm)
+	}
+	payload = bytes.TrimSpace(payload)
+	if !isObject(payload) {
+		return nil, fmt.Errorf("jws: claims are not a JSON object")
+	}
+	h := &header{Algorithm: config.Algorithm, KeyID: config.KeyID, Type: config.Type}
+	if h.Type == "" {
+		h.Type = DefaultType
+	}
+	encodedHeader, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	token := make([]byte, 0, b64.EncodedLen(len(encodedHeader))+b64.EncodedLen(len(payload))+2)
+	token = appendBase64(token, encodedHeader)
+	token = append(token, '.')
+	token = appendBase64(token, payload)
+	sig, err := alg.sign(config.Key, token)
+	if err != nil {
+		return nil, err
+	}
+	token = append(token, '.')
+	return appendBase64(token, sig), nil
+}
+
+func appendBase64(dst, src []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, b64.EncodedLen(len(src)))...)
+	b64.Encode(dst[n:], src)
+	return dst
+}
+
+func verify(config *DecoderConfig, token []byte) ([]byte, error) {
+	token = bytes.TrimSpace(token)
+	parts := bytes.Split(token, []byte("."))
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var h header
+	if err := decodePart(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	if len(h.Critical) > 0 {
+		return nil, fmt.Errorf("jws: unsupported critical header parameters %q", h.Critical)
+	}
+	keys := config.Keys[h.Algorithm]
+	alg, ok := algorithms[h.Algorithm]
+	if !ok || len(keys) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithm, h.Algorithm)
+	}
+	sig, err := b64.DecodeString(string(parts[2]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformed, err)
+	}
+	input := token[:len(parts[0])+1+len(parts[1])]
+	verified := false
+	for _, key := range keys {
+		if verified, err = alg.verify(key, input, sig); err != nil {
+			return nil, err
+		}
+		if verified {
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrSignature
+	}
+	payload, err := b64.DecodeString(string(parts[1]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrMalformed, err)
+	}
+	if err := checkClaims(config, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func decodePart(part []byte, v interface{}) error {
+	data, err := b64.DecodeString(string(part))
+	if err != nil {
+		return err
+	}
+	if !isObject(data) {
+		return fmt.Errorf("not a JSON object")
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkClaims checks the times, the audience and the issuer of the claims.
+func checkClaims(config *DecoderConfig, payload []byte) error {
+	var claims registered
+	if !isObject(payload) {
+		return fmt.Errorf("%w: claims are not a JSON object", ErrMalformed)
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("%w: claims: %v", ErrMalformed, err)
+	}
+	now := time.Now()
+	if config.Now != nil {
+		now = config.Now()
+	}
+	for _, date := range []struct {
+		name  string
+		value *float64
+	}{{"exp", claims.ExpiresAt}, {"nbf", claims.NotBefore}, {"iat", claims.IssuedAt}} {
+		if date.value != nil && !validDate(*date.value) {
+			return fmt.Errorf("%w: claim %s out of range", ErrMalformed, date.name)
+		}
+	}
+	if claims.ExpiresAt != nil && !now.Before(unixTime(*claims.ExpiresAt).Add(config.Leeway)) {
+		return ErrExpired
+	}
+	if claims.NotBefore != nil && now.Add(config.Leeway).Before(unixTime(*claims.NotBefore)) {
+		return ErrNotValidYet
+	}
+	if claims.IssuedAt != nil && now.Add(config.Leeway).Before(unixTime(*claims.IssuedAt)) {
+		return ErrIssuedInFuture
+	}
+	if config.Audience != "" && !claims.Audience.Contains(config.Audience) {
+		return ErrAudience
+	}
+	if config.Issuer != "" && claims.Issuer != config.Issuer {
+		return ErrIssuer
+	}
+	return nil
+}
+
+// validDate reports whether a NumericDate is finite and in the range of int64
+// seconds, which unixTime can convert.
+func validDate(seconds float64) bool {
+	// -2^63 is exact as a float64, and so is 2^63, the first value above the
+	// range.
+	return !math.IsNaN(seconds) && seconds >= math.MinInt64 && seconds < -math.MinInt64
+}
+
+// unixTime returns the time of a NumericDate, which must be valid.
+func unixTime(seconds float64) time.Time {
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+}
+
+func isObject(data []byte) bool {
+	return len(data) > 0 && data[0] == '{'
+}
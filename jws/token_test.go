@@ -0,0 +1,184 @@
+package jws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAudience(t *testing.T) {
+	tests := []struct {
+		data string
+		want Audience
+	}{
+		{`"api"`, Audience{"api"}},
+		{`["api","web"]`, Audience{"api", "web"}},
+	}
+	for _, test := range tests {
+		var a Audience
+		if err := json.Unmarshal([]byte(test.data), &a); err != nil {
+			t.Errorf("%s: %v", test.data, err)
+			continue
+		}
+		if len(a) != len(test.want) || !a.Contains(test.want[0]) || a.Contains("other") {
+			t.Errorf("%s: got %q", test.data, a)
+		}
+		if got, _ := json.Marshal(a); string(got) != test.data {
+			t.Errorf("got %s, want %s", got, test.data)
+		}
+	}
+	var a Audience
+	if err := json.Unmarshal([]byte(`1`), &a); err == nil {
+		t.Error("unmarshaled number audience")
+	}
+}
+
+func TestSign(t *testing.T) {
+	sign := Sign(SignWith(HS256, []byte("secret")), KeyID("k1"))
+	token, err := sign([]byte(`{"sub":"1234567890","name":"John Doe","iat":1516239022}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		t.Fatalf("got %s", token)
+	}
+	header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	if want := `{"alg":"HS256","kid":"k1","typ":"JWT"}`; string(header) != want {
+		t.Errorf("got header %s, want %s", header, want)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if want := `{"sub":"1234567890","name":"John Doe","iat":1516239022}`; string(payload) != want {
+		t.Errorf("got payload %s, want %s", payload, want)
+	}
+
+	for _, test := range []struct {
+		opt  []EncoderOption
+		data string
+		err  string
+	}{
+		{nil, `{}`, "jws: no signing algorithm"},
+		{[]EncoderOption{SignWith("none", nil)}, `{}`, `jws: unsupported algorithm "none"`},
+		{[]EncoderOption{SignWith(HS256, []byte("k"))}, `[1]`, "jws: claims are not a JSON object"},
+		{[]EncoderOption{SignWith(RS256, []byte("k"))}, `{}`, "jws: invalid key []uint8 for RS256"},
+	} {
+		_, err := Sign(test.opt...)([]byte(test.data))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("got %v, want %q", err, test.err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// the example token of RFC 7519, section 3.1.
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	key, _ := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	expiry := time.Unix(1300819380, 0)
+	verify := Verify(VerifyWith(HS256, key), ExpectIssuer("joe"), Clock(func() time.Time { return expiry.Add(-time.Second) }))
+	payload, err := verify([]byte(token + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims struct {
+		Root bool `json:"http://example.com/is_root"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || !claims.Root {
+		t.Errorf("got %s, %v", payload, err)
+	}
+	if _, err := Verify(VerifyWith(HS256, key), Clock(func() time.Time { return expiry }))([]byte(token)); err != ErrExpired {
+		t.Errorf("got %v, want %v", err, ErrExpired)
+	}
+}
+
+func TestVerify_Claims(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1600000000, 0)
+	clock := Clock(func() time.Time { return now })
+	tests := []struct {
+		claims string
+		opt    []DecoderOption
+		err    error
+	}{
+		{`{}`, nil, nil},
+		{`{"exp":1600000001}`, nil, nil},
+		{`{"exp":1600000000}`, nil, ErrExpired},
+		{`{"exp":1599999999.5}`, nil, ErrExpired},
+		{`{"exp":1599999990}`, []DecoderOption{Leeway(time.Minute)}, nil},
+		{`{"nbf":1600000000}`, nil, nil},
+		{`{"nbf":1600000001}`, nil, ErrNotValidYet},
+		{`{"nbf":1600000030}`, []DecoderOption{Leeway(time.Minute)}, nil},
+		{`{"iat":1600000001}`, nil, ErrIssuedInFuture},
+		{`{"iat":1600000001}`, []DecoderOption{Leeway(time.Second)}, nil},
+		{`{"aud":"api"}`, []DecoderOption{ExpectAudience("api")}, nil},
+		{`{"aud":["web","api"]}`, []DecoderOption{ExpectAudience("api")}, nil},
+		{`{"aud":"web"}`, []DecoderOption{ExpectAudience("api")}, ErrAudience},
+		{`{}`, []DecoderOption{ExpectAudience("api")}, ErrAudience},
+		{`{"iss":"a"}`, []DecoderOption{ExpectIssuer("a")}, nil},
+		{`{"iss":"b"}`, []DecoderOption{ExpectIssuer("a")}, ErrIssuer},
+		{`{"exp":"tomorrow"}`, nil, ErrMalformed},
+		{`{"nbf":1e300}`, nil, ErrMalformed},
+		{`{"nbf":-1e300}`, nil, ErrMalformed},
+		{`{"exp":9223372036854775808}`, nil, ErrMalformed},
+		{`{"iat":-9223372036854775808}`, nil, nil},
+		{`[]`, nil, ErrMalformed},
+	}
+	for _, test := range tests {
+		token := rawToken(`{"alg":"HS256"}`, test.claims, key)
+		opt := append([]DecoderOption{VerifyWith(HS256, key), clock}, test.opt...)
+		_, err := Verify(opt...)([]byte(token))
+		if !errors.Is(err, test.err) || (err != nil && test.err == nil) {
+			t.Errorf("%s: got %v, want %v", test.claims, err, test.err)
+		}
+	}
+}
+
+func TestVerify_Token(t *testing.T) {
+	key := []byte("secret")
+	valid := rawToken(`{"alg":"HS256"}`, `{}`, key)
+	tests := []struct {
+		token string
+		opt   []DecoderOption
+		err   string
+	}{
+		{valid, []DecoderOption{VerifyWith(HS256, []byte("old")), VerifyWith(HS256, key)}, ""},
+		{valid, []DecoderOption{VerifyWith(HS256, []byte("other"))}, "jws: invalid signature"},
+		{valid, []DecoderOption{VerifyWith(RS256, key)}, `jws: algorithm not allowed: "HS256"`},
+		{valid, nil, `jws: algorithm not allowed: "HS256"`},
+		{rawToken(`{"alg":"none"}`, `{}`, nil), []DecoderOption{VerifyWith("none", nil)}, `jws: algorithm not allowed: "none"`},
+		{valid, []DecoderOption{VerifyWith(HS256, "secret")}, "jws: invalid key string for HS256"},
+		{rawToken(`{"alg":"HS256","crit":["exp"]}`, `{}`, key), []DecoderOption{VerifyWith(HS256, key)}, `jws: unsupported critical header parameters ["exp"]`},
+		{"a.b", []DecoderOption{VerifyWith(HS256, key)}, "jws: malformed token"},
+		{"e30.e30.e30.e30", []DecoderOption{VerifyWith(HS256, key)}, "jws: malformed token"},
+		{"!.e30.sig", []DecoderOption{VerifyWith(HS256, key)}, "jws: malformed token: header: "},
+		{"WzFd.e30.sig", []DecoderOption{VerifyWith(HS256, key)}, "jws: malformed token: header: not a JSON object"},
+		{valid + "=", []DecoderOption{VerifyWith(HS256, key)}, "jws: malformed token: signature: "},
+	}
+	for _, test := range tests {
+		_, err := Verify(test.opt...)([]byte(test.token))
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", test.token, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%s: got %v, want %q", test.token, err, test.err)
+		}
+	}
+}
+
+// rawToken returns a token of the header and the claims as they are, signed
+// by HS256 with key unless key is nil.
+func rawToken(header, claims string, key []byte) string {
+	input := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	var sig []byte
+	if key != nil {
+		sig, _ = signHMAC(key, []byte(input))
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
@@ -0,0 +1,642 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+// SyntaxError is an error of malformed MessagePack data.
+type SyntaxError struct {
+	// Offset is the offset in bytes where the error occurred.
+	Offset int
+	msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("msgpack: %s at offset %d", e.msg, e.Offset)
+}
+
+// UnmarshalTypeError is an error of decoding an object into a value of
+// inappropriate type.
+type UnmarshalTypeError struct {
+	// Value describes the object, e.g. "str", "array".
+	Value string
+	// Type is the type of the target value.
+	Type reflect.Type
+	// Offset is the offset in bytes of the object.
+	Offset int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("msgpack: cannot unmarshal %s into Go value of type %s at offset %d", e.Value, e.Type, e.Offset)
+}
+
+var _unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+type family uint8
+
+const (
+	famNil family = iota
+	famBool
+	famInt
+	famUint
+	famFloat32
+	famFloat64
+	famStr
+	famBin
+	famArray
+	famMap
+	famExt
+)
+
+var _familyNames = [...]string{
+	famNil:     "nil",
+	famBool:    "bool",
+	famInt:     "int",
+	famUint:    "uint",
+	famFloat32: "float32",
+	famFloat64: "float64",
+	famStr:     "str",
+	famBin:     "bin",
+	famArray:   "array",
+	famMap:     "map",
+	famExt:     "ext",
+}
+
+// header is the header of an object. For str, bin and ext, n is the length of
+// the payload following. For array and map, n is the number of elements.
+type header struct {
+	family  family
+	offset  int
+	b       bool
+	i       int64
+	u       uint64
+	f       float64
+	n       int
+	extType int8
+}
+
+// maxDepth is the maximum nesting depth of objects.
+const maxDepth = 1000
+
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *decoder) syntaxError(msg string) error {
+	return &SyntaxError{Offset: d.pos, msg: msg}
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, d.syntaxError("unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *decoder) header() (h header, err error) {
+	h.offset = d.pos
+	b, err := d.read(1)
+	if err != nil {
+		return h, err
+	}
+	c := b[0]
+	var n uint64
+	switch {
+	case c <= 0x7f:
+		h.family, h.i = famInt, int64(c)
+		return h, nil
+	case c >= 0xe0:
+		h.family, h.i = famInt, int64(int8(c))
+		return h, nil
+	case c&0xf0 == 0x80:
+		h.family, n = famMap, uint64(c&0x0f)
+	case c&0xf0 == 0x90:
+		h.family, n = famArray, uint64(c&0x0f)
+	case c&0xe0 == 0xa0:
+		h.family, n = famStr, uint64(c&0x1f)
+	default:
+		switch c {
+		case 0xc0:
+			h.family = famNil
+			return h, nil
+		case 0xc2, 0xc3:
+			h.family, h.b = famBool, c == 0xc3
+			return h, nil
+		case 0xc4, 0xc5, 0xc6:
+			h.family = famBin
+			n, err = d.readUint(1 << (c - 0xc4))
+		case 0xc7, 0xc8, 0xc9:
+			h.family = famExt
+			n, err = d.readUint(1 << (c - 0xc7))
+		case 0xca:
+			h.family = famFloat32
+			n, err = d.readUint(4)
+			h.f = float64(math.Float32frombits(uint32(n)))
+			return h, err
+		case 0xcb:
+			h.family = famFloat64
+			n, err = d.readUint(8)
+			h.f = math.Float64frombits(n)
+			return h, err
+		case 0xcc, 0xcd, 0xce, 0xcf:
+			h.family = famUint
+			h.u, err = d.readUint(1 << (c - 0xcc))
+			return h, err
+		case 0xd0, 0xd1, 0xd2, 0xd3:
+			h.family = famInt
+			size := 1 << (c - 0xd0)
+			n, err = d.readUint(size)
+			shift := uint(64 - 8*size)
+			h.i = int64(n<<shift) >> shift
+			return h, err
+		case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+			h.family, n = famExt, 1<<(c-0xd4)
+		case 0xd9, 0xda, 0xdb:
+			h.family = famStr
+			n, err = d.readUint(1 << (c - 0xd9))
+		case 0xdc, 0xdd:
+			h.family = famArray
+			n, err = d.readUint(2 << (c - 0xdc))
+		case 0xde, 0xdf:
+			h.family = famMap
+			n, err = d.readUint(2 << (c - 0xde))
+		default:
+			return h, &SyntaxError{Offset: h.offset, msg: fmt.Sprintf("invalid format 0x%02x", c)}
+		}
+	}
+	if err != nil {
+		return h, err
+	}
+	if h.family == famExt {
+		t, err := d.read(1)
+		if err != nil {
+			return h, err
+		}
+		h.extType = int8(t[0])
+	}
+	// Each element takes at least one byte, so a count larger than the rest
+	// of data is malformed, and must not be used to allocate.
+	remain := uint64(len(d.data) - d.pos)
+	if h.family == famMap && n > remain/2 || n > remain {
+		return h, d.syntaxError("length exceeds data")
+	}
+	h.n = int(n)
+	return h, nil
+}
+
+// skip skips the object whose header has been read.
+func (d *decoder) skip(h header) error {
+	switch h.family {
+	case famStr, famBin, famExt:
+		_, err := d.read(h.n)
+		return err
+	case famArray, famMap:
+		d.depth++
+		defer func() { d.depth-- }()
+		if d.depth > maxDepth {
+			return d.syntaxError(fmt.Sprintf("exceeded max nesting depth %d", maxDepth))
+		}
+		count := h.n
+		if h.family == famMap {
+			count *= 2
+		}
+		for i := 0; i < count; i++ {
+			eh, err := d.header()
+			if err != nil {
+				return err
+			}
+			if err := d.skip(eh); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("msgpack: can not unmarshal to non-pointer or nil %T", v)
+	}
+	return d.value(rv.Elem())
+}
+
+func (d *decoder) typeError(h header, t reflect.Type) error {
+	return &UnmarshalTypeError{Value: _familyNames[h.family], Type: t, Offset: h.offset}
+}
+
+// value decodes the next object into v.
+func (d *decoder) value(v reflect.Value) error {
+	start := d.pos
+	h, err := d.header()
+	if err != nil {
+		return err
+	}
+	if h.family == famNil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if u, ok := unmarshalerOf(v); ok {
+		if err := d.skip(h); err != nil {
+			return err
+		}
+		return u.UnmarshalMsgpack(d.data[start:d.pos])
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.pos = start
+		return d.value(v.Elem())
+	}
+	if h.family == famArray || h.family == famMap {
+		d.depth++
+		defer func() { d.depth-- }()
+		if d.depth > maxDepth {
+			return d.syntaxError(fmt.Sprintf("exceeded max nesting depth %d", maxDepth))
+		}
+	}
+	if h.family == famExt {
+		return d.ext(h, v)
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return d.typeError(h, v.Type())
+		}
+		x, err := d.any(h)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case reflect.Bool:
+		if h.family != famBool {
+			return d.typeError(h, v.Type())
+		}
+		v.SetBool(h.b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := h.i
+		switch {
+		case h.family == famUint && h.u <= math.MaxInt64:
+			n = int64(h.u)
+		case h.family != famInt:
+			return d.typeError(h, v.Type())
+		}
+		if v.OverflowInt(n) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %d", n), Type: v.Type(), Offset: h.offset}
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := h.u
+		switch {
+		case h.family == famInt && h.i >= 0:
+			n = uint64(h.i)
+		case h.family != famUint:
+			return d.typeError(h, v.Type())
+		}
+		if v.OverflowUint(n) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("number %d", n), Type: v.Type(), Offset: h.offset}
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch h.family {
+		case famFloat32, famFloat64:
+			v.SetFloat(h.f)
+		case famInt:
+			v.SetFloat(float64(h.i))
+		case famUint:
+			v.SetFloat(float64(h.u))
+		default:
+			return d.typeError(h, v.Type())
+		}
+	case reflect.String:
+		if h.family != famStr && h.family != famBin {
+			return d.typeError(h, v.Type())
+		}
+		b, err := d.read(h.n)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (h.family == famBin || h.family == famStr) {
+			b, err := d.read(h.n)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+		if h.family != famArray {
+			return d.typeError(h, v.Type())
+		}
+		// the slice grows as elements are decoded rather than being allocated
+		// by the count, which is bounded only by the size of the rest of the
+		// data, not by the elements it can hold.
+		slice := reflect.MakeSlice(v.Type(), 0, 0)
+		zero := reflect.Zero(v.Type().Elem())
+		for i := 0; i < h.n; i++ {
+			slice = reflect.Append(slice, zero)
+			if err := d.value(slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (h.family == famBin || h.family == famStr) {
+			b, err := d.read(h.n)
+			if err != nil {
+				return err
+			}
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+		if h.family != famArray {
+			return d.typeError(h, v.Type())
+		}
+		for i := 0; i < h.n; i++ {
+			if i >= v.Len() {
+				eh, err := d.header()
+				if err == nil {
+					err = d.skip(eh)
+				}
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		for i := h.n; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	case reflect.Map:
+		if h.family != famMap {
+			return d.typeError(h, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), h.n))
+		}
+		for i := 0; i < h.n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.value(key); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			if !hashable(key) {
+				return &SyntaxError{Offset: h.offset, msg: fmt.Sprintf("unhashable map key of type %s", key.Elem().Type())}
+			}
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Struct:
+		if v.Type() == _timeType {
+			return d.typeError(h, v.Type())
+		}
+		if h.family != famMap {
+			return d.typeError(h, v.Type())
+		}
+		return d.structValue(h, v)
+	default:
+		return d.typeError(h, v.Type())
+	}
+	return nil
+}
+
+func unmarshalerOf(v reflect.Value) (Unmarshaler, bool) {
+	if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(_unmarshalerType) {
+		return v.Addr().Interface().(Unmarshaler), true
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Implements(_unmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v.Interface().(Unmarshaler), true
+	}
+	return nil, false
+}
+
+func (d *decoder) structValue(h header, v reflect.Value) error {
+	fs := fields.Of(v.Type(), _tags...)
+	for i := 0; i < h.n; i++ {
+		var key string
+		if err := d.value(reflect.ValueOf(&key).Elem()); err != nil {
+			return err
+		}
+		f := lookupField(fs, key)
+		if f == nil {
+			eh, err := d.header()
+			if err == nil {
+				err = d.skip(eh)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		fv, ok := fields.ByIndex(v, f.Index, true)
+		if !ok {
+			return fmt.Errorf("msgpack: field %s: can not set embedded pointer to unexported struct", f.Name)
+		}
+		if err := d.value(fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lookupField(fs []fields.Field, key string) *fields.Field {
+	for i := range fs {
+		if fs[i].Name == key {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].Name, key) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+// ext decodes an extension object into v.
+func (d *decoder) ext(h header, v reflect.Value) error {
+	data, err := d.read(h.n)
+	if err != nil {
+		return err
+	}
+	if h.extType == TimestampType && (v.Type() == _timeType || v.Kind() == reflect.Interface) {
+		t, err := timestamp(data)
+		if err != nil {
+			return &SyntaxError{Offset: h.offset, msg: err.Error()}
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.Type() == _extType || v.Kind() == reflect.Interface {
+		if t, ok := extTypeOf(h.extType); ok && v.Kind() == reflect.Interface {
+			value, err := unmarshalExt(t, data)
+			if err != nil {
+				return err
+			}
+			v.Set(value)
+			return nil
+		}
+		v.Set(reflect.ValueOf(Ext{Type: h.extType, Data: append([]byte{}, data...)}))
+		return nil
+	}
+	if code, ok := extCodeOf(v.Type()); ok && code == h.extType && v.CanAddr() {
+		return v.Addr().Interface().(ExtUnmarshaler).UnmarshalMsgpackExt(data)
+	}
+	if code, ok := extCodeOf(reflect.PtrTo(v.Type())); ok && code == h.extType && v.CanAddr() {
+		return v.Addr().Interface().(ExtUnmarshaler).UnmarshalMsgpackExt(data)
+	}
+	return &UnmarshalTypeError{Value: fmt.Sprintf("ext type %d", h.extType), Type: v.Type(), Offset: h.offset}
+}
+
+// unmarshalExt decodes the data of an extension value into a new value of
+// the registered type t.
+func unmarshalExt(t reflect.Type, data []byte) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		target := reflect.New(t.Elem())
+		if err := target.Interface().(ExtUnmarshaler).UnmarshalMsgpackExt(data); err != nil {
+			return reflect.Value{}, err
+		}
+		return target, nil
+	}
+	target := reflect.New(t)
+	if err := target.Interface().(ExtUnmarshaler).UnmarshalMsgpackExt(data); err != nil {
+		return reflect.Value{}, err
+	}
+	return target.Elem(), nil
+}
+
+func timestamp(data []byte) (time.Time, error) {
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		n := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(n&(1<<34-1)), int64(n>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp length %d", len(data))
+}
+
+// any decodes the object whose header has been read into a generic value.
+func (d *decoder) any(h header) (interface{}, error) {
+	switch h.family {
+	case famNil:
+		return nil, nil
+	case famBool:
+		return h.b, nil
+	case famInt:
+		return h.i, nil
+	case famUint:
+		return h.u, nil
+	case famFloat32:
+		return float32(h.f), nil
+	case famFloat64:
+		return h.f, nil
+	case famStr:
+		b, err := d.read(h.n)
+		return string(b), err
+	case famBin:
+		b, err := d.read(h.n)
+		return append([]byte{}, b...), err
+	case famArray:
+		items := make([]interface{}, h.n)
+		for i := range items {
+			if err := d.value(reflect.ValueOf(&items[i]).Elem()); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case famMap:
+		return d.anyMap(h)
+	default:
+		var x interface{}
+		err := d.ext(h, reflect.ValueOf(&x).Elem())
+		return x, err
+	}
+}
+
+// hashable reports whether key can be a key of a map, which is not the case
+// for arrays and maps decoded into an interface.
+func hashable(key reflect.Value) bool {
+	return key.Kind() != reflect.Interface || key.IsNil() || key.Elem().Type().Comparable()
+}
+
+// anyMap decodes a map into map[string]interface{} if all keys are strings,
+// otherwise into map[interface{}]interface{}.
+func (d *decoder) anyMap(h header) (interface{}, error) {
+	keys := make([]interface{}, h.n)
+	values := make([]interface{}, h.n)
+	allString := true
+	for i := 0; i < h.n; i++ {
+		if err := d.value(reflect.ValueOf(&keys[i]).Elem()); err != nil {
+			return nil, err
+		}
+		if _, ok := keys[i].(string); !ok {
+			allString = false
+		}
+		if err := d.value(reflect.ValueOf(&values[i]).Elem()); err != nil {
+			return nil, err
+		}
+	}
+	if allString {
+		m := make(map[string]interface{}, h.n)
+		for i, k := range keys {
+			m[k.(string)] = values[i]
+		}
+		return m, nil
+	}
+	m := make(map[interface{}]interface{}, h.n)
+	for i, k := range keys {
+		if k != nil && !reflect.TypeOf(k).Comparable() {
+			return nil, &UnmarshalTypeError{Value: "map key", Type: reflect.TypeOf(k), Offset: h.offset}
+		}
+		m[k] = values[i]
+	}
+	return m, nil
+}
@@ -0,0 +1,158 @@
+package msgpack
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type selfUnmarshaler struct {
+	raw []byte
+}
+
+func (s *selfUnmarshaler) UnmarshalMsgpack(data []byte) error {
+	s.raw = append([]byte{}, data...)
+	return nil
+}
+
+func TestDecoder_Typed(t *testing.T) {
+	c := &codec{}
+	var (
+		i8   int8
+		u16  uint16
+		f32  float32
+		s    string
+		b    []byte
+		arr  [2]int
+		p    *int
+		tm   time.Time
+		self selfUnmarshaler
+		ext  Ext
+	)
+	n := 1
+	p = &n
+	tests := []struct {
+		hex  string
+		v    interface{}
+		want interface{}
+	}{
+		{"d0 80", &i8, int8(-128)},
+		{"cc ff", &u16, uint16(255)},
+		{"05", &f32, float32(5)},
+		{"c4 02 61 62", &s, "ab"},
+		{"a2 61 62", &b, []byte("ab")},
+		{"93 01 02 03", &arr, [2]int{1, 2}},
+		{"91 07", &arr, [2]int{7, 0}},
+		{"c0", &p, (*int)(nil)},
+		{"d6 ff 00 00 00 01", &tm, time.Unix(1, 0).UTC()},
+		{"92 01 a1 61", &self, selfUnmarshaler{raw: mustHex("92 01 a1 61")}},
+		{"d4 05 01", &ext, Ext{Type: 5, Data: []byte{1}}},
+	}
+	for _, tt := range tests {
+		if err := c.Unmarshal(context.Background(), mustHex(tt.hex), tt.v); err != nil {
+			t.Errorf("unmarshal %s error %v", tt.hex, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("unmarshal %s got %#v, want %#v", tt.hex, got, tt.want)
+		}
+	}
+}
+
+func TestDecoder_Struct(t *testing.T) {
+	var v struct {
+		A int `msgpack:"a"`
+		B string
+	}
+	// {"x": [1, {"y": nil}], "A": 2, "b": "s"}, unknown keys are skipped and
+	// names are matched case-insensitively.
+	data := mustHex("83 a1 78 92 01 81 a1 79 c0 a1 41 02 a1 62 a1 73")
+	if err := (&codec{}).Unmarshal(context.Background(), data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 2 || v.B != "s" {
+		t.Errorf("unexpected %+v", v)
+	}
+}
+
+func TestDecoder_Error(t *testing.T) {
+	c := &codec{}
+	var (
+		i8  int8
+		u   uint
+		b   bool
+		s   string
+		m   map[string]int
+		st  struct{}
+		tm  time.Time
+		arr []int
+		e   error
+		mi  map[interface{}]interface{}
+	)
+	tests := []struct {
+		hex      string
+		v        interface{}
+		typeErr  bool
+		syntaxEr bool
+	}{
+		{"cc ff", &i8, true, false},
+		{"ff", &u, true, false},
+		{"a0", &b, true, false},
+		{"01", &s, true, false},
+		{"90", &m, true, false},
+		{"90", &st, true, false},
+		{"80", &tm, true, false},
+		{"a0", &arr, true, false},
+		{"01", &e, true, false},
+		{"c1", &u, false, true},
+		{"a2 61", &s, false, true},
+		{"dd ff ff ff ff", &arr, false, true},
+		{"01 02", &u, false, true},
+		{"", &u, false, true},
+		{"d6 ff 00", &tm, false, true},
+		{"d5 ff 00 00", &tm, false, true},
+		{"81 91 01 01", &mi, false, true},
+		{"81 81 01 01 01", &mi, false, true},
+	}
+	for _, tt := range tests {
+		err := c.Unmarshal(context.Background(), mustHex(tt.hex), tt.v)
+		var typeErr *UnmarshalTypeError
+		var syntaxErr *SyntaxError
+		if tt.typeErr && !errors.As(err, &typeErr) || tt.syntaxEr && !errors.As(err, &syntaxErr) {
+			t.Errorf("unmarshal %s into %T got error %v", tt.hex, tt.v, err)
+		}
+	}
+	if err := c.Unmarshal(context.Background(), mustHex("01"), u); err == nil {
+		t.Errorf("unmarshal to non-pointer expect error")
+	}
+}
+
+func TestDecoder_Limits(t *testing.T) {
+	c := &codec{}
+	deep := append(bytes.Repeat([]byte{0x91}, maxDepth), 0x01)
+	var x interface{}
+	if err := c.Unmarshal(context.Background(), deep, &x); err != nil {
+		t.Errorf("depth %d: %v", maxDepth, err)
+	}
+	tooDeep := append([]byte{0x91}, deep...)
+	var skipped struct{}
+	for _, v := range []interface{}{&x, &[]interface{}{}, &skipped} {
+		data := tooDeep
+		if v == &skipped {
+			data = append(mustHex("81 a1 78"), tooDeep...)
+		}
+		var syntaxErr *SyntaxError
+		if err := c.Unmarshal(context.Background(), data, v); !errors.As(err, &syntaxErr) {
+			t.Errorf("%T: got %v, want *SyntaxError", v, err)
+		}
+	}
+	large := append(mustHex("dd 00 10 00 00"), bytes.Repeat([]byte{0x01}, 1<<20)...)
+	var blocks [][65536]byte
+	var typeErr *UnmarshalTypeError
+	if err := c.Unmarshal(context.Background(), large, &blocks); !errors.As(err, &typeErr) {
+		t.Errorf("got %v, want *UnmarshalTypeError", err)
+	}
+}
@@ -0,0 +1,351 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+var (
+	_marshalerType = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	_timeType      = reflect.TypeOf(time.Time{})
+	_extType       = reflect.TypeOf(Ext{})
+)
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) encode(v interface{}) error {
+	return e.value(reflect.ValueOf(v))
+}
+
+func (e *encoder) value(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteByte(0xc0)
+		return nil
+	}
+	t := v.Type()
+	if code, ok := extCodeOf(t); ok {
+		return e.ext(code, v)
+	}
+	if t.Implements(_marshalerType) {
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		data, err := v.Interface().(Marshaler).MarshalMsgpack()
+		if err != nil {
+			return err
+		}
+		e.buf.Write(data)
+		return nil
+	}
+	switch t {
+	case _timeType:
+		e.timestamp(v.Interface().(time.Time))
+		return nil
+	case _extType:
+		ext := v.Interface().(Ext)
+		e.extHeader(ext.Type, len(ext.Data))
+		e.buf.Write(ext.Data)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		return e.value(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf.WriteByte(0xc3)
+		} else {
+			e.buf.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.uint(v.Uint())
+	case reflect.Float32:
+		e.buf.WriteByte(0xca)
+		e.write32(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf.WriteByte(0xcb)
+		e.write64(math.Float64bits(v.Float()))
+	case reflect.String:
+		e.strHeader(v.Len())
+		e.buf.WriteString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.binHeader(v.Len())
+			e.buf.Write(v.Bytes())
+			return nil
+		}
+		return e.array(v)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.binHeader(v.Len())
+			for i := 0; i < v.Len(); i++ {
+				e.buf.WriteByte(byte(v.Index(i).Uint()))
+			}
+			return nil
+		}
+		return e.array(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		return e.mapValue(v)
+	case reflect.Struct:
+		return e.structValue(v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", t)
+	}
+	return nil
+}
+
+func (e *encoder) array(v reflect.Value) error {
+	e.arrayHeader(v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if err := e.value(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapValue encodes a map with entries sorted by the encoding of keys, so
+// that the result is deterministic.
+func (e *encoder) mapValue(v reflect.Value) error {
+	type entry struct {
+		key   []byte
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := &encoder{}
+		if err := k.value(iter.Key()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: k.buf.Bytes(), value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+	e.mapHeader(len(entries))
+	for _, en := range entries {
+		e.buf.Write(en.key)
+		if err := e.value(en.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) structValue(v reflect.Value) error {
+	fs := fields.Of(v.Type(), _tags...)
+	values := make([]reflect.Value, 0, len(fs))
+	names := make([]string, 0, len(fs))
+	for _, f := range fs {
+		fv, ok := fields.ByIndex(v, f.Index, false)
+		if !ok || f.OmitEmpty() && fields.IsEmpty(fv) {
+			continue
+		}
+		values = append(values, fv)
+		names = append(names, f.Name)
+	}
+	e.mapHeader(len(values))
+	for i, fv := range values {
+		e.strHeader(len(names[i]))
+		e.buf.WriteString(names[i])
+		if err := e.value(fv); err != nil {
+			return fmt.Errorf("msgpack: field %s: %w", names[i], err)
+		}
+	}
+	return nil
+}
+
+func (e *encoder) ext(code int8, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		e.buf.WriteByte(0xc0)
+		return nil
+	}
+	data, err := v.Interface().(ExtMarshaler).MarshalMsgpackExt()
+	if err != nil {
+		return err
+	}
+	e.extHeader(code, len(data))
+	e.buf.Write(data)
+	return nil
+}
+
+// timestamp encodes t in the smallest of timestamp 32, 64 and 96 formats.
+func (e *encoder) timestamp(t time.Time) {
+	sec, nsec := t.Unix(), uint32(t.Nanosecond())
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		e.extHeader(TimestampType, 4)
+		e.write32(uint32(sec))
+	case sec>>34 == 0:
+		e.extHeader(TimestampType, 8)
+		e.write64(uint64(nsec)<<34 | uint64(sec))
+	default:
+		e.extHeader(TimestampType, 12)
+		e.write32(nsec)
+		e.write64(uint64(sec))
+	}
+}
+
+func (e *encoder) int(n int64) {
+	switch {
+	case n >= 0:
+		e.uint(uint64(n))
+	case n >= -32:
+		e.buf.WriteByte(byte(n))
+	case n >= math.MinInt8:
+		e.buf.WriteByte(0xd0)
+		e.buf.WriteByte(byte(n))
+	case n >= math.MinInt16:
+		e.buf.WriteByte(0xd1)
+		e.write16(uint16(n))
+	case n >= math.MinInt32:
+		e.buf.WriteByte(0xd2)
+		e.write32(uint32(n))
+	default:
+		e.buf.WriteByte(0xd3)
+		e.write64(uint64(n))
+	}
+}
+
+func (e *encoder) uint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(0xcc)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xcd)
+		e.write16(uint16(n))
+	case n <= math.MaxUint32:
+		e.buf.WriteByte(0xce)
+		e.write32(uint32(n))
+	default:
+		e.buf.WriteByte(0xcf)
+		e.write64(n)
+	}
+}
+
+func (e *encoder) strHeader(n int) {
+	switch {
+	case n < 32:
+		e.buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(0xd9)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xda)
+		e.write16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdb)
+		e.write32(uint32(n))
+	}
+}
+
+func (e *encoder) binHeader(n int) {
+	switch {
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(0xc4)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xc5)
+		e.write16(uint16(n))
+	default:
+		e.buf.WriteByte(0xc6)
+		e.write32(uint32(n))
+	}
+}
+
+func (e *encoder) arrayHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xdc)
+		e.write16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdd)
+		e.write32(uint32(n))
+	}
+}
+
+func (e *encoder) mapHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xde)
+		e.write16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdf)
+		e.write32(uint32(n))
+	}
+}
+
+func (e *encoder) extHeader(code int8, n int) {
+	switch n {
+	case 1:
+		e.buf.WriteByte(0xd4)
+	case 2:
+		e.buf.WriteByte(0xd5)
+	case 4:
+		e.buf.WriteByte(0xd6)
+	case 8:
+		e.buf.WriteByte(0xd7)
+	case 16:
+		e.buf.WriteByte(0xd8)
+	default:
+		switch {
+		case n <= math.MaxUint8:
+			e.buf.WriteByte(0xc7)
+			e.buf.WriteByte(byte(n))
+		case n <= math.MaxUint16:
+			e.buf.WriteByte(0xc8)
+			e.write16(uint16(n))
+		default:
+			e.buf.WriteByte(0xc9)
+			e.write32(uint32(n))
+		}
+	}
+	e.buf.WriteByte(byte(code))
+}
+
+func (e *encoder) write16(n uint16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], n)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) write32(n uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], n)
+	e.buf.Write(b[:])
+}
+
+func (e *encoder) write64(n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	e.buf.Write(b[:])
+}
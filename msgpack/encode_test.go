@@ -0,0 +1,81 @@
+package msgpack
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type selfMarshaler struct{}
+
+func (selfMarshaler) MarshalMsgpack() ([]byte, error) {
+	return []byte{0xc3}, nil
+}
+
+type failMarshaler struct{}
+
+func (failMarshaler) MarshalMsgpack() ([]byte, error) {
+	return nil, errors.New("fail")
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	var nilMap map[string]int
+	var nilSlice []int
+	var nilPtr *int
+	tests := []struct {
+		name string
+		v    interface{}
+		hex  string
+	}{
+		{"int as uint", 200, "cc c8"},
+		{"uint8", uint8(1), "01"},
+		{"byte array", [2]byte{1, 2}, "c4 02 01 02"},
+		{"nil map", nilMap, "c0"},
+		{"nil slice", nilSlice, "c0"},
+		{"nil pointer", nilPtr, "c0"},
+		{"marshaler", selfMarshaler{}, "c3"},
+		{"nil marshaler", (*selfMarshaler)(nil), "c0"},
+		{"array of int", [3]int{1, 2, 3}, "93 01 02 03"},
+		{"timestamp64 max", time.Unix(1<<34-1, 0).UTC(), "d7 ff 00 00 00 03 ff ff ff ff"},
+		{"omitempty", struct {
+			A int `msgpack:"a,omitempty"`
+			B int `json:"b"`
+		}{B: 1}, "81 a1 62 01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &encoder{}
+			if err := e.encode(tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if want := mustHex(tt.hex); !reflect.DeepEqual(e.buf.Bytes(), want) {
+				t.Errorf("got % x, want % x", e.buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestEncoder_Headers(t *testing.T) {
+	e := &encoder{}
+	e.strHeader(65536)
+	e.binHeader(65536)
+	e.arrayHeader(65536)
+	e.mapHeader(16)
+	e.mapHeader(65536)
+	e.extHeader(1, 256)
+	e.extHeader(1, 65536)
+	want := mustHex("db 00 01 00 00 c6 00 01 00 00 dd 00 01 00 00 de 00 10 df 00 01 00 00 c8 01 00 01 c9 00 01 00 00 01")
+	if !reflect.DeepEqual(e.buf.Bytes(), want) {
+		t.Errorf("got % x, want % x", e.buf.Bytes(), want)
+	}
+}
+
+func TestEncoder_Error(t *testing.T) {
+	for _, v := range []interface{}{func() {}, make(chan int), failMarshaler{}, struct{ F func() }{}, map[string]func(){"a": nil}} {
+		e := &encoder{}
+		if err := e.encode(v); err == nil {
+			t.Errorf("encode %T expect error", v)
+		}
+	}
+}
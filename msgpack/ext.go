@@ -0,0 +1,94 @@
+package msgpack
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"go.uber.org/atomic"
+)
+
+// TimestampType is the extension type of timestamps.
+const TimestampType int8 = -1
+
+// Ext is an extension value of a type not registered by RegisterExt.
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+// ExtMarshaler is implemented by types registered as extension types, to
+// encode themselves into the data of extension values.
+type ExtMarshaler interface {
+	MarshalMsgpackExt() ([]byte, error)
+}
+
+// ExtUnmarshaler is implemented by pointers to types registered as extension
+// types, or registered pointer types themselves, to decode themselves from the
+// data of extension values.
+type ExtUnmarshaler interface {
+	UnmarshalMsgpackExt(data []byte) error
+}
+
+type extRegistry struct {
+	byType map[reflect.Type]int8
+	byCode map[int8]reflect.Type
+}
+
+var _extRegistry = atomic.NewUnsafePointer(unsafe.Pointer(&extRegistry{
+	byType: map[reflect.Type]int8{},
+	byCode: map[int8]reflect.Type{},
+}))
+
+var (
+	_extMarshalerType   = reflect.TypeOf((*ExtMarshaler)(nil)).Elem()
+	_extUnmarshalerType = reflect.TypeOf((*ExtUnmarshaler)(nil)).Elem()
+)
+
+// RegisterExt registers the type of value as an extension type with the code.
+// The type must implement ExtMarshaler, and the pointer to the type must
+// implement ExtUnmarshaler. Codes less than 0 are reserved by the spec.
+// If more than one type is registered with the same code, the later one wins.
+// RegisterExt panics if the requirements are not met.
+func RegisterExt(code int8, value interface{}) {
+	t := reflect.TypeOf(value)
+	if code < 0 {
+		panic(fmt.Sprintf("msgpack: extension type code %d is reserved", code))
+	}
+	if t == nil || !t.Implements(_extMarshalerType) ||
+		!reflect.PtrTo(t).Implements(_extUnmarshalerType) && !(t.Kind() == reflect.Ptr && t.Implements(_extUnmarshalerType)) {
+		panic(fmt.Sprintf("msgpack: type %v must implement ExtMarshaler and *%v ExtUnmarshaler", t, t))
+	}
+	for {
+		o := (*extRegistry)(_extRegistry.Load())
+		r := &extRegistry{
+			byType: make(map[reflect.Type]int8, len(o.byType)+1),
+			byCode: make(map[int8]reflect.Type, len(o.byCode)+1),
+		}
+		for k, v := range o.byType {
+			if v != code {
+				r.byType[k] = v
+			}
+		}
+		for k, v := range o.byCode {
+			if v != t {
+				r.byCode[k] = v
+			}
+		}
+		r.byType[t] = code
+		r.byCode[code] = t
+		if _extRegistry.CAS(unsafe.Pointer(o), unsafe.Pointer(r)) {
+			return
+		}
+	}
+}
+
+func extCodeOf(t reflect.Type) (int8, bool) {
+	code, ok := (*extRegistry)(_extRegistry.Load()).byType[t]
+	return code, ok
+}
+
+func extTypeOf(code int8) (reflect.Type, bool) {
+	t, ok := (*extRegistry)(_extRegistry.Load()).byCode[code]
+	return t, ok
+}
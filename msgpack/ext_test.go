@@ -0,0 +1,98 @@
+package msgpack
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type point struct {
+	X, Y int8
+}
+
+func (p point) MarshalMsgpackExt() ([]byte, error) {
+	return []byte{byte(p.X), byte(p.Y)}, nil
+}
+
+func (p *point) UnmarshalMsgpackExt(data []byte) error {
+	if len(data) != 2 {
+		return errors.New("point: invalid data")
+	}
+	p.X, p.Y = int8(data[0]), int8(data[1])
+	return nil
+}
+
+type label struct {
+	text string
+}
+
+func (l *label) MarshalMsgpackExt() ([]byte, error) {
+	return []byte(l.text), nil
+}
+
+func (l *label) UnmarshalMsgpackExt(data []byte) error {
+	l.text = string(data)
+	return nil
+}
+
+func TestRegisterExt(t *testing.T) {
+	RegisterExt(10, point{})
+	RegisterExt(11, &label{})
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), []interface{}{point{1, -1}, &label{"abc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustHex("92 d5 0a 01 ff c7 03 0b 61 62 63"); !reflect.DeepEqual(data, want) {
+		t.Errorf("got % x, want % x", data, want)
+	}
+	var generic []interface{}
+	if err := c.Unmarshal(context.Background(), data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(generic, []interface{}{point{1, -1}, &label{"abc"}}) {
+		t.Errorf("unexpected generic %#v", generic)
+	}
+	var typed struct {
+		P  point
+		PP *point
+		L  *label
+	}
+	data, err = c.Marshal(context.Background(), map[string]interface{}{"P": point{2, 3}, "PP": point{4, 5}, "L": &label{"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Unmarshal(context.Background(), data, &typed); err != nil {
+		t.Fatal(err)
+	}
+	if typed.P != (point{2, 3}) || *typed.PP != (point{4, 5}) || typed.L.text != "x" {
+		t.Errorf("unexpected typed %+v", typed)
+	}
+	if err := c.Unmarshal(context.Background(), mustHex("d4 0a 01"), &typed.P); err == nil {
+		t.Errorf("expect error of ext unmarshaler")
+	}
+	if err := c.Unmarshal(context.Background(), mustHex("d4 0c 01"), &typed.P); err == nil {
+		t.Errorf("expect error of mismatched ext type")
+	}
+}
+
+func TestRegisterExt_Panic(t *testing.T) {
+	for _, tt := range []struct {
+		code  int8
+		value interface{}
+	}{
+		{-2, point{}},
+		{1, 1},
+		{1, nil},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterExt(%d, %T) expect panic", tt.code, tt.value)
+				}
+			}()
+			RegisterExt(tt.code, tt.value)
+		}()
+	}
+}
@@ -0,0 +1,68 @@
+// Package msgpack defines and registers Marshaler/Unmarshaler handling
+// MessagePack content.
+//
+// Values are mapped to MessagePack the way encoding/json maps them to JSON:
+// structs are encoded as maps keyed by field names, taken from the "msgpack"
+// tag or, in absence of it, the "json" tag. In addition, []byte is encoded as
+// bin, time.Time as the timestamp extension type, and values of types
+// registered by RegisterExt as extension types.
+package msgpack
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "msgpack"
+
+var _tags = []string{"msgpack", "json"}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshaler is implemented by types which encode themselves into MessagePack.
+// The returned data must be exactly one valid MessagePack object.
+type Marshaler interface {
+	MarshalMsgpack() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types which decode themselves from MessagePack.
+// The data is exactly one MessagePack object, and must be copied if it is
+// retained after returning.
+type Unmarshaler interface {
+	UnmarshalMsgpack(data []byte) error
+}
+
+func (c *codec) Marshal(_ context.Context, v interface{}) ([]byte, error) {
+	e := &encoder{}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(_ context.Context, data []byte, v interface{}) error {
+	d := &decoder{data: data}
+	if err := d.decode(v); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return d.syntaxError("unexpected data after top-level object")
+	}
+	return nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,166 @@
+package msgpack
+
+import (
+	"context"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Test vectors from the msgpack-test-suite project.
+var _vectors = []struct {
+	name string
+	v    interface{}
+	hex  string
+}{
+	{"nil", nil, "c0"},
+	{"false", false, "c2"},
+	{"true", true, "c3"},
+	{"positive fixint 0", int64(0), "00"},
+	{"positive fixint 127", int64(127), "7f"},
+	{"uint8", uint64(128), "cc 80"},
+	{"uint8 max", uint64(255), "cc ff"},
+	{"uint16", uint64(256), "cd 01 00"},
+	{"uint16 max", uint64(65535), "cd ff ff"},
+	{"uint32", uint64(65536), "ce 00 01 00 00"},
+	{"uint32 max", uint64(4294967295), "ce ff ff ff ff"},
+	{"uint64", uint64(4294967296), "cf 00 00 00 01 00 00 00 00"},
+	{"uint64 max", uint64(math.MaxUint64), "cf ff ff ff ff ff ff ff ff"},
+	{"negative fixint -1", int64(-1), "ff"},
+	{"negative fixint -32", int64(-32), "e0"},
+	{"int8", int64(-33), "d0 df"},
+	{"int8 min", int64(-128), "d0 80"},
+	{"int16", int64(-129), "d1 ff 7f"},
+	{"int16 min", int64(-32768), "d1 80 00"},
+	{"int32", int64(-32769), "d2 ff ff 7f ff"},
+	{"int32 min", int64(-2147483648), "d2 80 00 00 00"},
+	{"int64", int64(-2147483649), "d3 ff ff ff ff 7f ff ff ff"},
+	{"int64 min", int64(math.MinInt64), "d3 80 00 00 00 00 00 00 00"},
+	{"float32", float32(0.5), "ca 3f 00 00 00"},
+	{"float64", 0.5, "cb 3f e0 00 00 00 00 00 00"},
+	{"float64 1.1", 1.1, "cb 3f f1 99 99 99 99 99 9a"},
+	{"fixstr empty", "", "a0"},
+	{"fixstr", "a", "a1 61"},
+	{"fixstr utf-8", "日本", "a6 e6 97 a5 e6 9c ac"},
+	{"str8", strings.Repeat("a", 32), "d9 20" + strings.Repeat(" 61", 32)},
+	{"str16", strings.Repeat("a", 256), "da 01 00" + strings.Repeat(" 61", 256)},
+	{"bin8 empty", []byte{}, "c4 00"},
+	{"bin8", []byte{1}, "c4 01 01"},
+	{"bin16", make([]byte, 256), "c5 01 00" + strings.Repeat(" 00", 256)},
+	{"fixarray empty", []interface{}{}, "90"},
+	{"fixarray", []interface{}{int64(1)}, "91 01"},
+	{"array16", make([]interface{}, 16), "dc 00 10" + strings.Repeat(" c0", 16)},
+	{"fixmap empty", map[string]interface{}{}, "80"},
+	{"fixmap", map[string]interface{}{"a": int64(1)}, "81 a1 61 01"},
+	{"nested", []interface{}{[]interface{}{}, map[string]interface{}{"": []interface{}{}}}, "92 90 81 a0 90"},
+	{"timestamp32", time.Unix(1514862245, 0).UTC(), "d6 ff 5a 4a f6 a5"},
+	{"timestamp64", time.Unix(1514862245, 678901234).UTC(), "d7 ff a1 dc d7 c8 5a 4a f6 a5"},
+	{"timestamp96", time.Unix(-1, 0).UTC(), "c7 0c ff 00 00 00 00 ff ff ff ff ff ff ff ff"},
+	{"fixext1", Ext{Type: 1, Data: []byte{0x10}}, "d4 01 10"},
+	{"fixext16", Ext{Type: 1, Data: make([]byte, 16)}, "d8 01" + strings.Repeat(" 00", 16)},
+	{"ext8", Ext{Type: 1, Data: []byte{1, 2, 3}}, "c7 03 01 01 02 03"},
+}
+
+func TestCodec_Vectors(t *testing.T) {
+	c := &codec{}
+	for _, tt := range _vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			want := mustHex(tt.hex)
+			got, err := c.Marshal(context.Background(), tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("marshal got % x, want % x", got, want)
+			}
+			var v interface{}
+			if err := c.Unmarshal(context.Background(), want, &v); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, tt.v) {
+				t.Errorf("unmarshal got %#v, want %#v", v, tt.v)
+			}
+		})
+	}
+}
+
+type inner struct {
+	Tags []string `msgpack:"tags"`
+}
+
+type sample struct {
+	ID      int               `msgpack:"id"`
+	Name    string            `json:"name"`
+	Score   float32           `msgpack:"score,omitempty"`
+	Raw     []byte            `msgpack:"raw"`
+	At      time.Time         `msgpack:"at"`
+	Inner   *inner            `msgpack:"inner"`
+	Attrs   map[string]uint16 `msgpack:"attrs"`
+	Fixed   [2]int8           `msgpack:"fixed"`
+	Any     interface{}       `msgpack:"any"`
+	Skipped string            `msgpack:"-"`
+}
+
+func TestCodec_Struct(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := sample{
+		ID:    -7,
+		Name:  "name",
+		Raw:   []byte("raw"),
+		At:    time.Date(2021, 6, 1, 8, 0, 0, 1, time.UTC),
+		Inner: &inner{Tags: []string{"a"}},
+		Attrs: map[string]uint16{"b": 2, "a": 1},
+		Fixed: [2]int8{1, -1},
+		Any:   "x",
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out sample
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	var generic map[string]interface{}
+	if err := u.Unmarshal(context.Background(), data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if generic["name"] != "name" || generic["id"] != int64(-7) || generic["score"] != nil {
+		t.Errorf("unexpected generic map %v", generic)
+	}
+}
+
+func TestCodec_Deterministic(t *testing.T) {
+	c := &codec{}
+	m := map[interface{}]interface{}{"b": 1, "a": 2, 3: 4}
+	first, err := c.Marshal(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustHex("83 03 04 a1 61 02 a1 62 01"); !reflect.DeepEqual(first, want) {
+		t.Errorf("got % x, want % x", first, want)
+	}
+	var out interface{}
+	if err := c.Unmarshal(context.Background(), first, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.(map[interface{}]interface{}); got[int64(3)] != int64(4) || got["a"] != int64(2) {
+		t.Errorf("unexpected map %v", got)
+	}
+}
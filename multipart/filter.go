@@ -0,0 +1,154 @@
+package multipart
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// Default limits of decoding.
+const (
+	DefaultMaxParts    = 1000
+	DefaultMaxPartSize = 32 << 20
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Boundary is the boundary to use. A random boundary is generated if empty.
+	Boundary string
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// Boundary is the boundary of the body. If empty, it is taken from the
+	// boundary parameter of ContentType, or from the first line of the body
+	// which begins with "--".
+	Boundary string
+	// ContentType is the Content-Type header of the body, which has the
+	// boundary as a parameter.
+	ContentType string
+	// MaxParts is the maximum number of parts. Zero means DefaultMaxParts,
+	// negative means no limit.
+	MaxParts int
+	// MaxPartSize is the maximum size of the content of a part in bytes.
+	// Zero means DefaultMaxPartSize, negative means no limit.
+	MaxPartSize int64
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// DecodeBoundary produces a DecoderOption which sets the boundary of the body,
+// usually taken from the Content-Type header.
+func DecodeBoundary(boundary string) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Boundary = boundary
+	}
+}
+
+// DecodeContentType produces a DecoderOption which sets the Content-Type
+// header of the body, taking the boundary from its parameter.
+func DecodeContentType(contentType string) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.ContentType = contentType
+	}
+}
+
+// MaxParts produces a DecoderOption which limits the number of parts.
+func MaxParts(n int) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.MaxParts = n
+	}
+}
+
+// MaxPartSize produces a DecoderOption which limits the size of each part.
+func MaxPartSize(n int64) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.MaxPartSize = n
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// EncodeBoundary produces an EncoderOption which sets the boundary to use
+// instead of a random one.
+func EncodeBoundary(boundary string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Boundary = boundary
+	}
+}
@@ -0,0 +1,25 @@
+package multipart
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, EncodeBoundary("bad boundary!"))
+	if _, err := m.Marshal(context.Background(), map[string]string{}); err == nil {
+		t.Errorf("invalid boundary expect error")
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	data := []byte("preamble\r\n--b\r\nContent-Disposition: form-data; name=\"k\"\r\n\r\nv\r\n--b--\r\n")
+	u := WithDecoderOption(&codec{}, DecodeBoundary("b"))
+	var form Form
+	if err := u.Unmarshal(context.Background(), data, &form); err != nil {
+		t.Fatal(err)
+	}
+	if form.Value["k"][0] != "v" {
+		t.Errorf("unexpected form %v", form)
+	}
+}
@@ -0,0 +1,406 @@
+// Package multipart defines and registers Marshaler/Unmarshaler handling
+// multipart/form-data content.
+package multipart
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	mm "mime/multipart"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "multipart"
+
+// tagName is the struct tag naming fields, shared with urlencoded forms.
+const tagName = "form"
+
+var (
+	// ErrTooManyParts is returned when the number of parts exceeds the limit.
+	ErrTooManyParts = errors.New("multipart: too many parts")
+	// ErrPartTooLarge is returned when the size of a part exceeds the limit.
+	ErrPartTooLarge = errors.New("multipart: part too large")
+)
+
+var (
+	_filePartType    = reflect.TypeOf(FilePart{})
+	_filePartPtrType = reflect.TypeOf(&FilePart{})
+)
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshal encodes a *Form, url.Values, maps with string keys or a struct into a
+// multipart/form-data body. Fields of structs are named by the "form" tag.
+// Fields of type FilePart, *FilePart or slices of them are encoded as file
+// parts, scalar fields and slices of scalars are encoded as value parts.
+//
+// Use ContentType to get the Content-Type header, including the boundary,
+// of the body produced.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	buf := &bytes.Buffer{}
+	w := mm.NewWriter(buf)
+	if config.Boundary != "" {
+		if err := w.SetBoundary(config.Boundary); err != nil {
+			return nil, fmt.Errorf("multipart: %w", err)
+		}
+	}
+	var err error
+	switch vv := v.(type) {
+	case *Form:
+		err = writeForm(w, vv)
+	case Form:
+		err = writeForm(w, &vv)
+	case url.Values:
+		err = writeForm(w, &Form{Value: vv})
+	case map[string][]string:
+		err = writeForm(w, &Form{Value: vv})
+	case map[string]string:
+		values := make(map[string][]string, len(vv))
+		for key, value := range vv {
+			values[key] = []string{value}
+		}
+		err = writeForm(w, &Form{Value: values})
+	default:
+		err = writeStruct(w, v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeForm(w *mm.Writer, form *Form) error {
+	for _, key := range sortedKeys(reflect.ValueOf(form.Value)) {
+		for _, value := range form.Value[key] {
+			if err := w.WriteField(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range sortedKeys(reflect.ValueOf(form.File)) {
+		for _, file := range form.File[key] {
+			if err := writeFile(w, key, file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m reflect.Value) []string {
+	keys := make([]string, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var _quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeFile(w *mm.Writer, name string, file *FilePart) error {
+	if file == nil {
+		return nil
+	}
+	header := make(textproto.MIMEHeader, len(file.Header)+2)
+	for key, values := range file.Header {
+		header[key] = values
+	}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		_quoteEscaper.Replace(name), _quoteEscaper.Replace(file.FileName)))
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(file.Content)
+	return err
+}
+
+func writeStruct(w *mm.Writer, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("multipart: unsupported type %T", v)
+	}
+	for _, f := range fields.Of(rv.Type(), tagName) {
+		fv, ok := fields.ByIndex(rv, f.Index, false)
+		if !ok || f.OmitEmpty() && fields.IsEmpty(fv) {
+			continue
+		}
+		if err := writeField(w, f.Name, fv); err != nil {
+			return fmt.Errorf("multipart: field %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func writeField(w *mm.Writer, name string, v reflect.Value) error {
+	switch v.Type() {
+	case _filePartType:
+		file := v.Interface().(FilePart)
+		return writeFile(w, name, &file)
+	case _filePartPtrType:
+		return writeFile(w, name, v.Interface().(*FilePart))
+	}
+	if textual.IsScalar(v.Type()) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return nil
+		}
+		s, err := textual.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return w.WriteField(name, s)
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := writeField(w, name, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return writeField(w, name, v.Elem())
+	}
+	return fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// Unmarshal decodes a multipart/form-data body into a *Form, *url.Values,
+// *map[string][]string or a pointer to struct. Fields of structs are named by
+// the "form" tag. Fields of type FilePart, *FilePart or slices of them receive
+// file parts, scalar fields and slices of scalars receive value parts.
+//
+// The boundary is provided by DecodeBoundary, or taken from the Content-Type
+// header provided by DecodeContentType. Otherwise it is taken from the first
+// line of the body which begins with "--", after any preamble. The number and size of parts are limited,
+// see DecoderConfig.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	form, err := readForm(data, config)
+	if err != nil {
+		return err
+	}
+	switch vv := v.(type) {
+	case *Form:
+		*vv = *form
+		return nil
+	case *url.Values:
+		*vv = form.Value
+		return nil
+	case *map[string][]string:
+		*vv = form.Value
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("multipart: can not unmarshal to non-pointer or nil %T", v)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("multipart: unsupported type %T", v)
+	}
+	for _, f := range fields.Of(rv.Type(), tagName) {
+		values, files := form.Value[f.Name], form.File[f.Name]
+		if len(values) == 0 && len(files) == 0 {
+			continue
+		}
+		fv, ok := fields.ByIndex(rv, f.Index, true)
+		if !ok {
+			continue
+		}
+		if err := readField(fv, values, files); err != nil {
+			return fmt.Errorf("multipart: field %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func readForm(data []byte, config *DecoderConfig) (*Form, error) {
+	boundary := config.Boundary
+	if boundary == "" && config.ContentType != "" {
+		_, params, err := mime.ParseMediaType(config.ContentType)
+		if err != nil {
+			return nil, fmt.Errorf("multipart: %w", err)
+		}
+		if boundary = params["boundary"]; boundary == "" {
+			return nil, fmt.Errorf("multipart: no boundary in content type %q", config.ContentType)
+		}
+	}
+	if boundary == "" {
+		var err error
+		if boundary, err = boundaryOf(data); err != nil {
+			return nil, err
+		}
+	}
+	if !bytes.Contains(data, []byte("--"+boundary)) {
+		return nil, fmt.Errorf("multipart: boundary %q not found", boundary)
+	}
+	maxParts, maxPartSize := config.MaxParts, config.MaxPartSize
+	if maxParts == 0 {
+		maxParts = DefaultMaxParts
+	}
+	if maxPartSize == 0 {
+		maxPartSize = DefaultMaxPartSize
+	}
+	form := &Form{Value: map[string][]string{}, File: map[string][]*FilePart{}}
+	r := mm.NewReader(bytes.NewReader(data), boundary)
+	for count := 1; ; count++ {
+		part, err := r.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return form, nil
+			}
+			return nil, fmt.Errorf("multipart: %w", err)
+		}
+		if maxParts > 0 && count > maxParts {
+			return nil, ErrTooManyParts
+		}
+		var reader io.Reader = part
+		if maxPartSize > 0 {
+			reader = io.LimitReader(part, maxPartSize+1)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("multipart: %w", err)
+		}
+		if maxPartSize > 0 && int64(len(content)) > maxPartSize {
+			return nil, ErrPartTooLarge
+		}
+		name := part.FormName()
+		if fileName := part.FileName(); fileName != "" {
+			form.File[name] = append(form.File[name], &FilePart{
+				FileName: fileName,
+				Header:   part.Header,
+				Content:  content,
+			})
+		} else {
+			form.Value[name] = append(form.Value[name], string(content))
+		}
+	}
+}
+
+func readField(v reflect.Value, values []string, files []*FilePart) error {
+	switch v.Type() {
+	case _filePartType:
+		if len(files) > 0 {
+			v.Set(reflect.ValueOf(*files[0]))
+		}
+		return nil
+	case _filePartPtrType:
+		if len(files) > 0 {
+			v.Set(reflect.ValueOf(files[0]))
+		}
+		return nil
+	}
+	if textual.IsScalar(v.Type()) {
+		if len(values) == 0 {
+			return nil
+		}
+		return textual.Unmarshal(values[0], v)
+	}
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	switch v.Type().Elem() {
+	case _filePartType, _filePartPtrType:
+		slice := reflect.MakeSlice(v.Type(), len(files), len(files))
+		for i := range files {
+			if err := readField(slice.Index(i), nil, files[i:i+1]); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+	for i := range values {
+		if err := readField(slice.Index(i), values[i:i+1], nil); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+// boundaryOf takes the boundary from the first line of a body which begins
+// with "--", skipping the preamble before it. Transport padding after the
+// boundary is ignored. If the line is a close delimiter, as in a body of no
+// parts, the trailing "--" is not part of the boundary: a line ending with
+// "--" is an open delimiter only if it is followed by its close delimiter.
+func boundaryOf(data []byte) (string, error) {
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		line = bytes.TrimRight(line, " \t\r")
+		if !bytes.HasPrefix(line, []byte("--")) || len(line) == 2 {
+			continue
+		}
+		if bytes.HasSuffix(line, []byte("--")) && len(line) > 4 && !bytes.Contains(data, line) {
+			line = line[:len(line)-2]
+		}
+		return string(line[2:]), nil
+	}
+	return "", errors.New("multipart: no boundary found")
+}
+
+// ContentType returns the Content-Type header, including the boundary, of a
+// multipart/form-data body produced by Marshal.
+func ContentType(data []byte) (string, error) {
+	boundary, err := boundaryOf(data)
+	if err != nil {
+		return "", err
+	}
+	return mime.FormatMediaType("multipart/form-data", map[string]string{"boundary": boundary}), nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
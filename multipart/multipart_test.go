@@ -0,0 +1,195 @@
+package multipart
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+type upload struct {
+	Title  string      `form:"title"`
+	Count  int         `form:"count"`
+	Tags   []string    `form:"tags"`
+	Avatar *FilePart   `form:"avatar"`
+	Docs   []FilePart  `form:"docs"`
+	Note   string      `form:"note,omitempty"`
+	Skip   interface{} `form:"-"`
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	c := &codec{}
+	in := upload{
+		Title:  "标题",
+		Count:  2,
+		Tags:   []string{"a", "b"},
+		Avatar: &FilePart{FileName: "a.png", Header: textproto.MIMEHeader{"Content-Type": {"image/png"}}, Content: []byte("png")},
+		Docs:   []FilePart{{FileName: "1.txt", Content: []byte("one")}, {FileName: "2.txt", Content: []byte("two")}},
+	}
+	m := WithEncoderOption(c, EncodeBoundary("xyz"))
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "--xyz\r\n") {
+		t.Errorf("unexpected body %q", data)
+	}
+	contentType, err := ContentType(data)
+	if err != nil || contentType != "multipart/form-data; boundary=xyz" {
+		t.Errorf("ContentType() = %q, %v", contentType, err)
+	}
+	var out upload
+	if err := c.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Title != in.Title || out.Count != in.Count || !reflect.DeepEqual(out.Tags, in.Tags) {
+		t.Errorf("unexpected values %+v", out)
+	}
+	if out.Avatar == nil || out.Avatar.FileName != "a.png" || out.Avatar.ContentType() != "image/png" ||
+		string(out.Avatar.Content) != "png" {
+		t.Errorf("unexpected avatar %+v", out.Avatar)
+	}
+	if len(out.Docs) != 2 || out.Docs[1].Size() != 3 || out.Docs[0].ContentType() != "application/octet-stream" {
+		t.Errorf("unexpected docs %+v", out.Docs)
+	}
+}
+
+func TestCodec_Form(t *testing.T) {
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), &Form{
+		Value: map[string][]string{"b": {"2"}, "a": {"1", "3"}},
+		File:  map[string][]*FilePart{"f": {{FileName: `x"y.txt`, Content: []byte("x")}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var form Form
+	if err := c.Unmarshal(context.Background(), data, &form); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(form.Value, map[string][]string{"a": {"1", "3"}, "b": {"2"}}) {
+		t.Errorf("unexpected values %v", form.Value)
+	}
+	if len(form.File["f"]) != 1 || form.File["f"][0].FileName != `x"y.txt` {
+		t.Errorf("unexpected files %v", form.File)
+	}
+	var values url.Values
+	if err := c.Unmarshal(context.Background(), data, &values); err != nil || values.Get("b") != "2" {
+		t.Errorf("unexpected values %v, %v", values, err)
+	}
+	data, err = encoding.GetMarshaler(Name).Marshal(context.Background(), map[string]string{"k": "v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string][]string
+	if err := encoding.GetUnmarshaler(Name).Unmarshal(context.Background(), data, &m); err != nil || m["k"][0] != "v" {
+		t.Errorf("unexpected map %v, %v", m, err)
+	}
+}
+
+func TestCodec_Limits(t *testing.T) {
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), map[string][]string{"a": {"1", "2", "3"}, "b": {"long value"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var form Form
+	u := WithDecoderOption(c, MaxParts(3))
+	if err := u.Unmarshal(context.Background(), data, &form); !errors.Is(err, ErrTooManyParts) {
+		t.Errorf("expect ErrTooManyParts, got %v", err)
+	}
+	u = WithDecoderOption(c, MaxPartSize(5))
+	if err := u.Unmarshal(context.Background(), data, &form); !errors.Is(err, ErrPartTooLarge) {
+		t.Errorf("expect ErrPartTooLarge, got %v", err)
+	}
+	u = WithDecoderOption(c, MaxParts(-1), MaxPartSize(-1))
+	if err := u.Unmarshal(context.Background(), data, &form); err != nil {
+		t.Errorf("expect no limit, got %v", err)
+	}
+}
+
+func TestCodec_Error(t *testing.T) {
+	c := &codec{}
+	if _, err := c.Marshal(context.Background(), 1); err == nil {
+		t.Errorf("marshal int expect error")
+	}
+	if _, err := c.Marshal(context.Background(), struct {
+		M map[string]int `form:"m"`
+	}{M: map[string]int{}}); err == nil {
+		t.Errorf("marshal map field expect error")
+	}
+	if err := c.Unmarshal(context.Background(), []byte("no boundary"), &Form{}); err == nil {
+		t.Errorf("unmarshal without boundary expect error")
+	}
+	data := []byte("--b\r\nContent-Disposition: form-data; name=\"count\"\r\n\r\nx\r\n--b--\r\n")
+	if err := c.Unmarshal(context.Background(), data, &upload{}); err == nil {
+		t.Errorf("unmarshal invalid int expect error")
+	}
+	if err := c.Unmarshal(context.Background(), data, upload{}); err == nil {
+		t.Errorf("unmarshal to non-pointer expect error")
+	}
+	u := WithDecoderOption(c, DecodeBoundary("other"))
+	if err := u.Unmarshal(context.Background(), data, &Form{}); err == nil {
+		t.Errorf("unmarshal with wrong boundary expect error")
+	}
+}
+
+func TestCodec_Boundary(t *testing.T) {
+	c := &codec{}
+	part := "Content-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n"
+	tests := []struct {
+		name string
+		data string
+		opt  []DecoderOption
+	}{
+		{name: "first line", data: "--b\r\n" + part + "--b--\r\n"},
+		{name: "preamble", data: "This is a preamble.\r\nIgnore it.\r\n--b  \r\n" + part + "--b--\r\n"},
+		{name: "content type", data: "-- preamble\r\n--b\r\n" + part + "--b--\r\n",
+			opt: []DecoderOption{DecodeContentType(`multipart/form-data; boundary="b"`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form Form
+			if err := WithDecoderOption(c, tt.opt...).Unmarshal(context.Background(), []byte(tt.data), &form); err != nil {
+				t.Fatal(err)
+			}
+			if got := form.Value["a"]; len(got) != 1 || got[0] != "1" {
+				t.Errorf("got %v", form.Value)
+			}
+		})
+	}
+	for data, want := range map[string]string{
+		"--b--\r\n":                    "b",
+		"preamble\r\n--b--  \r\n":      "b",
+		"--b--\r\n" + part + "--b----": "b--",
+		"------\r\n":                   "--",
+	} {
+		if got, err := boundaryOf([]byte(data)); err != nil || got != want {
+			t.Errorf("boundaryOf(%q) = %q, %v, want %q", data, got, err, want)
+		}
+	}
+	data, err := c.Marshal(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentType, err := ContentType(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, _ := mime.ParseMediaType(contentType)
+	if want := "\r\n--" + params["boundary"] + "--\r\n"; string(data) != want {
+		t.Errorf("got %q for body %q", contentType, data)
+	}
+	for _, contentType := range []string{"multipart/form-data", "multipart/form-data; boundary="} {
+		u := WithDecoderOption(c, DecodeContentType(contentType))
+		if err := u.Unmarshal(context.Background(), []byte("--b\r\n"+part+"--b--\r\n"), &Form{}); err == nil {
+			t.Errorf("%q: expect error", contentType)
+		}
+	}
+}
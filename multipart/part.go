@@ -0,0 +1,39 @@
+package multipart
+
+import (
+	"bytes"
+	"io"
+	"net/textproto"
+)
+
+// FilePart is a file part of a multipart/form-data body.
+type FilePart struct {
+	// FileName is the filename parameter of the Content-Disposition header.
+	FileName string
+	// Header is the header of the part. When marshaling, Content-Disposition
+	// is always generated, and Content-Type defaults to application/octet-stream.
+	Header textproto.MIMEHeader
+	// Content is the content of the part.
+	Content []byte
+}
+
+// Open returns a reader of the content.
+func (f *FilePart) Open() io.Reader {
+	return bytes.NewReader(f.Content)
+}
+
+// Size returns the size of the content in bytes.
+func (f *FilePart) Size() int {
+	return len(f.Content)
+}
+
+// ContentType returns the Content-Type header of the part.
+func (f *FilePart) ContentType() string {
+	return f.Header.Get("Content-Type")
+}
+
+// Form is a parsed multipart/form-data body, keyed by form names.
+type Form struct {
+	Value map[string][]string
+	File  map[string][]*FilePart
+}
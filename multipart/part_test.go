@@ -0,0 +1,22 @@
+package multipart
+
+import (
+	"io/ioutil"
+	"net/textproto"
+	"testing"
+)
+
+func TestFilePart(t *testing.T) {
+	f := &FilePart{
+		FileName: "a.txt",
+		Header:   textproto.MIMEHeader{"Content-Type": {"text/plain"}},
+		Content:  []byte("hello"),
+	}
+	data, err := ioutil.ReadAll(f.Open())
+	if err != nil || string(data) != "hello" {
+		t.Errorf("Open() read %q, %v", data, err)
+	}
+	if f.Size() != 5 || f.ContentType() != "text/plain" {
+		t.Errorf("unexpected size %d or content type %q", f.Size(), f.ContentType())
+	}
+}
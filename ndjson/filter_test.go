@@ -0,0 +1,33 @@
+package ndjson
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, EscapeHTML(false))
+	got, err := m.Marshal(context.Background(), []string{"<a>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "\"<a>\"\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	u := WithDecoderOption(&codec{}, UseNumber())
+	var got []interface{}
+	if err := u.Unmarshal(context.Background(), []byte("1.50\n"), &got); err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := got[0].(json.Number); !ok || n.String() != "1.50" {
+		t.Errorf("expect json.Number, got %#v", got[0])
+	}
+	u = WithDecoderOption(&codec{}, DisallowUnknownFields())
+	if err := u.Unmarshal(context.Background(), []byte("{\"x\":1}\n"), &[]record{}); err == nil {
+		t.Errorf("expect unknown field error")
+	}
+}
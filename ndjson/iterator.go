@@ -0,0 +1,103 @@
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// DefaultMaxLineSize is the default maximum size of a line in bytes.
+const DefaultMaxLineSize = 16 << 20
+
+// Iterator reads records of newline-delimited JSON from an io.Reader one at
+// a time. Blank lines are skipped.
+//
+//	it := ndjson.NewIterator(r)
+//	for it.Next() {
+//		var record Record
+//		if err := it.Decode(&record); err != nil {
+//			return err
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator struct {
+	scanner *bufio.Scanner
+	options []DecoderOption
+	line    int
+	current []byte
+	err     error
+}
+
+// NewIterator returns an Iterator reading from r, with lines up to
+// DefaultMaxLineSize bytes.
+func NewIterator(r io.Reader, opt ...DecoderOption) *Iterator {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, DefaultMaxLineSize)
+	return &Iterator{scanner: scanner, options: opt}
+}
+
+// MaxLineSize sets the maximum size of a line in bytes. It must be called
+// before the first call of Next.
+func (it *Iterator) MaxLineSize(n int) *Iterator {
+	it.scanner.Buffer(nil, n)
+	return it
+}
+
+// Next advances to the next record. It returns false when there are no more
+// records or an error occurred, see Err.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.scanner.Scan() {
+		it.line++
+		line := bytes.TrimSpace(it.scanner.Bytes())
+		if len(line) > 0 {
+			it.current = line
+			return true
+		}
+	}
+	if err := it.scanner.Err(); err != nil {
+		it.err = &LineError{Line: it.line + 1, Err: err}
+	}
+	it.current = nil
+	return false
+}
+
+// Line returns the 1-based line number of the current record.
+func (it *Iterator) Line() int {
+	return it.line
+}
+
+// Bytes returns the raw JSON of the current record. The underlying array may
+// be overwritten by the next call of Next.
+func (it *Iterator) Bytes() []byte {
+	return it.current
+}
+
+// Decode decodes the current record into v. Errors are reported as *LineError.
+func (it *Iterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return errors.New("ndjson: Decode called without a current record")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(it.current))
+	for _, option := range it.options {
+		option(decoder)
+	}
+	if err := decoder.Decode(v); err != nil {
+		return &LineError{Line: it.line, Err: err}
+	}
+	if decoder.More() {
+		return &LineError{Line: it.line, Err: errors.New("more than one value in a line")}
+	}
+	return nil
+}
+
+// Err returns the first error occurred during reading, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
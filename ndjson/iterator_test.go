@@ -0,0 +1,58 @@
+package ndjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	it := NewIterator(strings.NewReader("{\"id\":1}\n\n{\"id\":2}\n{\"id\":\"bad\"}\n"))
+	var ids []int
+	var lines []int
+	var err error
+	for it.Next() {
+		var r record
+		if err = it.Decode(&r); err != nil {
+			break
+		}
+		ids = append(ids, r.ID)
+		lines = append(lines, it.Line())
+	}
+	if len(ids) != 2 || ids[1] != 2 || lines[1] != 3 {
+		t.Errorf("unexpected ids %v at lines %v", ids, lines)
+	}
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 4 {
+		t.Errorf("expect error at line 4, got %v", err)
+	}
+	if string(it.Bytes()) != "{\"id\":\"bad\"}" {
+		t.Errorf("unexpected bytes %s", it.Bytes())
+	}
+}
+
+func TestIterator_Options(t *testing.T) {
+	it := NewIterator(strings.NewReader("{\"id\":1,\"x\":2}\n"), DisallowUnknownFields())
+	if !it.Next() {
+		t.Fatal("expect a record")
+	}
+	if err := it.Decode(&record{}); err == nil {
+		t.Errorf("expect unknown field error")
+	}
+	if it.Next() || it.Err() != nil {
+		t.Errorf("expect end without error, got %v", it.Err())
+	}
+	if err := it.Decode(&record{}); err == nil {
+		t.Errorf("decode after end expect error")
+	}
+}
+
+func TestIterator_TooLong(t *testing.T) {
+	it := NewIterator(strings.NewReader("1\n" + strings.Repeat("2", 100) + "\n")).MaxLineSize(10)
+	for it.Next() {
+	}
+	var lineErr *LineError
+	if !errors.As(it.Err(), &lineErr) || lineErr.Line != 2 {
+		t.Errorf("expect error at line 2, got %v", it.Err())
+	}
+}
@@ -0,0 +1,129 @@
+// Package ndjson defines and registers Marshaler/Unmarshaler handling
+// newline-delimited JSON (JSON Lines) content, one JSON value per line.
+package ndjson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "ndjson"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// LineError is an error of decoding a line.
+type LineError struct {
+	// Line is the 1-based line number.
+	Line int
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("ndjson: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Marshal encodes each element of a slice or an array, or each value received
+// from a channel until it is closed, into a line of JSON.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(encoder)
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := encoder.Encode(rv.Index(i).Interface()); err != nil {
+				return nil, fmt.Errorf("ndjson: record %d: %w", i, err)
+			}
+		}
+	case reflect.Chan:
+		if rv.Type().ChanDir()&reflect.RecvDir == 0 {
+			return nil, fmt.Errorf("ndjson: can not receive from %T", v)
+		}
+		for i := 0; ; i++ {
+			item, ok := rv.Recv()
+			if !ok {
+				break
+			}
+			if err := encoder.Encode(item.Interface()); err != nil {
+				return nil, fmt.Errorf("ndjson: record %d: %w", i, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("ndjson: unsupported type %T", v)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes each non-blank line of JSON into an element of the slice
+// or the array v points to. Errors are reported as *LineError.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("ndjson: can not unmarshal to non-pointer or nil %T", v)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("ndjson: unsupported type %T", v)
+	}
+	it := NewIterator(bytes.NewReader(data))
+	it.options = decoderOptionFromContext(ctx)
+	n := 0
+	for ; it.Next(); n++ {
+		var item reflect.Value
+		if rv.Kind() == reflect.Slice {
+			item = reflect.New(rv.Type().Elem())
+		} else if n < rv.Len() {
+			item = rv.Index(n).Addr()
+		} else {
+			return &LineError{Line: it.Line(), Err: fmt.Errorf("more than %d records for %T", rv.Len(), v)}
+		}
+		if err := it.Decode(item.Interface()); err != nil {
+			return err
+		}
+		if rv.Kind() == reflect.Slice {
+			if n == 0 {
+				rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+			}
+			rv.Set(reflect.Append(rv, item.Elem()))
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if rv.Kind() == reflect.Slice && n == 0 {
+		rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+	}
+	return nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
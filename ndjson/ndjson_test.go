@@ -0,0 +1,98 @@
+package ndjson
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+type record struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	c := &codec{}
+	ch := make(chan record, 2)
+	ch <- record{ID: 1}
+	ch <- record{ID: 2, Name: "<b>"}
+	close(ch)
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "slice", v: []record{{ID: 1}, {ID: 2, Name: "<b>"}}, want: "{\"id\":1}\n{\"id\":2,\"name\":\"\\u003cb\\u003e\"}\n"},
+		{name: "array pointer", v: &[2]interface{}{1, "a"}, want: "1\n\"a\"\n"},
+		{name: "channel", v: ch, want: "{\"id\":1}\n{\"id\":2,\"name\":\"\\u003cb\\u003e\"}\n"},
+		{name: "empty", v: []record{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Marshal(context.Background(), tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	for _, v := range []interface{}{record{}, []interface{}{func() {}}, make(chan<- int)} {
+		if _, err := c.Marshal(context.Background(), v); err == nil {
+			t.Errorf("marshal %T expect error", v)
+		}
+	}
+}
+
+func TestCodec_Unmarshal(t *testing.T) {
+	u := encoding.GetUnmarshaler(Name)
+	var got []record
+	if err := u.Unmarshal(context.Background(), []byte("{\"id\":1}\n\n  {\"id\":2,\"name\":\"b\"}  \r\n"), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := []record{{ID: 1}, {ID: 2, Name: "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if err := u.Unmarshal(context.Background(), nil, &got); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("expect empty slice, got %v, %v", got, err)
+	}
+	var arr [2]record
+	if err := u.Unmarshal(context.Background(), []byte("{\"id\":1}\n{\"id\":2}\n"), &arr); err != nil || arr[1].ID != 2 {
+		t.Errorf("unexpected array %v, %v", arr, err)
+	}
+	err := u.Unmarshal(context.Background(), []byte("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"), &arr)
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Errorf("expect error at line 3, got %v", err)
+	}
+}
+
+func TestCodec_UnmarshalError(t *testing.T) {
+	c := &codec{}
+	tests := []struct {
+		data string
+		v    interface{}
+		line int
+	}{
+		{data: "{\"id\":1}\n{\"id\":\"x\"}\n", v: &[]record{}, line: 2},
+		{data: "\n\n{\"id\":1} {\"id\":2}\n", v: &[]record{}, line: 3},
+		{data: "{", v: &[]record{}, line: 1},
+	}
+	for _, tt := range tests {
+		err := c.Unmarshal(context.Background(), []byte(tt.data), tt.v)
+		var lineErr *LineError
+		if !errors.As(err, &lineErr) || lineErr.Line != tt.line {
+			t.Errorf("unmarshal %q expect error at line %d, got %v", tt.data, tt.line, err)
+		}
+	}
+	if err := c.Unmarshal(context.Background(), nil, []record{}); err == nil {
+		t.Errorf("unmarshal to non-pointer expect error")
+	}
+	if err := c.Unmarshal(context.Background(), nil, &record{}); err == nil {
+		t.Errorf("unmarshal to struct expect error")
+	}
+}
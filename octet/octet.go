@@ -0,0 +1,112 @@
+// Package octet defines and registers Marshaler/Unmarshaler passing opaque
+// binary data through, for blobs which are sent as they are, or processed by
+// filters only, such as compression and encryption.
+//
+// []byte and string are marshaled as their bytes, *bytes.Buffer as its unread
+// bytes without consuming them, encoding.BinaryMarshaler by MarshalBinary,
+// io.WriterTo by WriteTo and io.Reader by reading it to the end, which consumes
+// them. Data is unmarshaled into *[]byte and *string as a copy, into
+// encoding.BinaryUnmarshaler by UnmarshalBinary, into *bytes.Buffer replacing
+// its content, and into io.Writer by writing it.
+package octet
+
+import (
+	"bytes"
+	"context"
+	se "encoding"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "octet-stream"
+
+// MIMEType is the media type of opaque binary data.
+const MIMEType = "application/octet-stream"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(_ context.Context, v interface{}) ([]byte, error) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return append([]byte(nil), vv...), nil
+	case string:
+		return []byte(vv), nil
+	case *bytes.Buffer:
+		if vv == nil {
+			break
+		}
+		return append([]byte(nil), vv.Bytes()...), nil
+	case se.BinaryMarshaler:
+		return vv.MarshalBinary()
+	case io.WriterTo:
+		buf := &bytes.Buffer{}
+		if _, err := vv.WriteTo(buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case io.Reader:
+		return io.ReadAll(vv)
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch {
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		return append([]byte(nil), rv.Bytes()...), nil
+	case rv.Kind() == reflect.String:
+		return []byte(rv.String()), nil
+	}
+	return nil, fmt.Errorf("octet-stream: can not marshal %T", v)
+}
+
+func (c *codec) Unmarshal(_ context.Context, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() || !rv.IsValid() {
+		return fmt.Errorf("octet-stream: can not unmarshal to non-pointer or nil %T", v)
+	}
+	switch vv := v.(type) {
+	case se.BinaryUnmarshaler:
+		return vv.UnmarshalBinary(data)
+	case *bytes.Buffer:
+		vv.Reset()
+		vv.Write(data)
+		return nil
+	case io.Writer:
+		_, err := vv.Write(data)
+		return err
+	}
+	if rv.Kind() != reflect.Ptr {
+		return fmt.Errorf("octet-stream: can not unmarshal to non-pointer or nil %T", v)
+	}
+	switch rv = rv.Elem(); {
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		rv.SetBytes(append([]byte(nil), data...))
+		return nil
+	case rv.Kind() == reflect.String:
+		rv.SetString(string(data))
+		return nil
+	}
+	return fmt.Errorf("octet-stream: can not unmarshal to %T", v)
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
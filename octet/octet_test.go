@@ -0,0 +1,131 @@
+package octet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+// writerTo is an io.WriterTo which is not an io.Reader.
+type writerTo string
+
+func (w writerTo) WriteTo(dst io.Writer) (int64, error) {
+	n, err := io.WriteString(dst, string(w))
+	return int64(n), err
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	data := []byte("blob")
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, ""},
+		{data, "blob"},
+		{&data, "blob"},
+		{"text", "text"},
+		{json.RawMessage(`{}`), "{}"},
+		{net.IPv4(10, 0, 0, 1).To4(), "\x0a\x00\x00\x01"},
+		{writerTo("written"), "written"},
+		{strings.NewReader("read"), "read"},
+		{bytes.NewBufferString("buffered"), "buffered"},
+	}
+	c := &codec{}
+	for _, test := range tests {
+		got, err := c.Marshal(context.Background(), test.v)
+		if err != nil {
+			t.Errorf("%T: %v", test.v, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%T: got %q, want %q", test.v, got, test.want)
+		}
+	}
+	buf := bytes.NewBufferString("kept")
+	if got, _ := c.Marshal(context.Background(), buf); string(got) != "kept" || buf.String() != "kept" {
+		t.Errorf("got %q, buffer left with %q", got, buf.String())
+	}
+	got, _ := c.Marshal(context.Background(), data)
+	if got[0] = 'x'; string(data) != "blob" {
+		t.Error("marshaled data shares memory with the value")
+	}
+	if _, err := c.Marshal(context.Background(), 1); err == nil {
+		t.Error("expect error of unsupported type")
+	}
+}
+
+func TestCodec_Unmarshal(t *testing.T) {
+	c := &codec{}
+	ctx := context.Background()
+	data := []byte("\x0a\x00\x00\x01")
+	var b []byte
+	if err := c.Unmarshal(ctx, data, &b); err != nil || string(b) != string(data) {
+		t.Errorf("got %q, %v", b, err)
+	}
+	if b[0] = 0; data[0] != 0x0a {
+		t.Error("unmarshaled value shares memory with the data")
+	}
+	var s string
+	if err := c.Unmarshal(ctx, data, &s); err != nil || s != string(data) {
+		t.Errorf("got %q, %v", s, err)
+	}
+	buf := bytes.NewBufferString("old")
+	if err := c.Unmarshal(ctx, data, buf); err != nil || buf.String() != string(data) {
+		t.Errorf("got %q, %v", buf, err)
+	}
+	var sb strings.Builder
+	sb.WriteString("old")
+	if err := c.Unmarshal(ctx, data, &sb); err != nil || sb.String() != "old"+string(data) {
+		t.Errorf("got %q, %v", sb.String(), err)
+	}
+	var ip net.IP
+	if err := c.Unmarshal(ctx, data, &ip); err != nil || ip.String() != "10.0.0.1" {
+		t.Errorf("got %v, %v", ip, err)
+	}
+	var n int
+	for _, v := range []interface{}{nil, (*bytes.Buffer)(nil), b, &n} {
+		if err := c.Unmarshal(ctx, data, v); err == nil {
+			t.Errorf("%T: expect error", v)
+		}
+	}
+}
+
+func TestCodec_Filter(t *testing.T) {
+	reverse := func(pre []byte) ([]byte, error) {
+		post := make([]byte, len(pre))
+		for i, b := range pre {
+			post[len(pre)-1-i] = b
+		}
+		return post, nil
+	}
+	m := encoding.FilterMarshaler(encoding.GetMarshaler(Name), reverse)
+	u := encoding.FilterUnmarshaler(encoding.GetUnmarshaler(MIMEType), reverse)
+	data, err := m.Marshal(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "cba" {
+		t.Errorf("got %q", data)
+	}
+	var got []byte
+	if err := u.Unmarshal(context.Background(), data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "abc" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,197 @@
+package pem
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	_blockType      = reflect.TypeOf(pem.Block{})
+	_privateKeyType = reflect.TypeOf((*crypto.PrivateKey)(nil)).Elem()
+	_publicKeyType  = reflect.TypeOf((*crypto.PublicKey)(nil)).Elem()
+
+	// _signerType and _equalerType are implemented by the private and public
+	// keys of the standard library, which PKCS #8 and PKIX blocks are parsed
+	// into.
+	_signerType  = reflect.TypeOf((*interface{ Public() crypto.PublicKey })(nil)).Elem()
+	_equalerType = reflect.TypeOf((*interface{ Equal(crypto.PublicKey) bool })(nil)).Elem()
+)
+
+// _parsedTypes are the types of values parsed from blocks of types in
+// exactly one way.
+var _parsedTypes = map[string]reflect.Type{
+	TypeCertificate:        reflect.TypeOf((*x509.Certificate)(nil)),
+	TypeCertificateRequest: reflect.TypeOf((*x509.CertificateRequest)(nil)),
+	TypeRSAPrivateKey:      reflect.TypeOf((*rsa.PrivateKey)(nil)),
+	TypeECPrivateKey:       reflect.TypeOf((*ecdsa.PrivateKey)(nil)),
+	TypeRSAPublicKey:       reflect.TypeOf((*rsa.PublicKey)(nil)),
+}
+
+type decoder struct {
+	config *DecoderConfig
+}
+
+// decode decodes the blocks of data into v, all of them into a slice, and
+// exactly one into other values.
+func (d *decoder) decode(data []byte, v reflect.Value) error {
+	var blocks []*pem.Block
+	for {
+		var b *pem.Block
+		if b, data = pem.Decode(data); b == nil {
+			break
+		}
+		blocks = append(blocks, b)
+	}
+	if isSlice(v.Type()) {
+		values := reflect.MakeSlice(v.Type(), 0, len(blocks))
+		for _, b := range blocks {
+			item, err := d.block(b, v.Type().Elem())
+			if err != nil {
+				return err
+			}
+			if item.IsValid() {
+				values = reflect.Append(values, item)
+			}
+		}
+		if values.Len() == 0 {
+			return ErrNoBlock
+		}
+		v.Set(values)
+		return nil
+	}
+	var values []reflect.Value
+	for _, b := range blocks {
+		item, err := d.block(b, v.Type())
+		if err != nil {
+			return err
+		}
+		if item.IsValid() {
+			values = append(values, item)
+		}
+	}
+	switch len(values) {
+	case 0:
+		return ErrNoBlock
+	case 1:
+		v.Set(values[0])
+		return nil
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		items := make([]interface{}, len(values))
+		for i, item := range values {
+			items[i] = item.Interface()
+		}
+		v.Set(reflect.ValueOf(items))
+		return nil
+	}
+	return fmt.Errorf("pem: can not unmarshal %d blocks into Go value of type %s", len(values), v.Type())
+}
+
+// isSlice reports whether t is a slice of values decoded from blocks, rather
+// than a value decoded from a block itself, like ed25519.PrivateKey.
+func isSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// block returns the value of type t parsed from the block b. If the block
+// can not be held by t and other blocks are skipped, an invalid value is
+// returned. Blocks are only parsed if they may be held by t.
+func (d *decoder) block(b *pem.Block, t reflect.Type) (reflect.Value, error) {
+	switch t {
+	case _blockType:
+		return reflect.ValueOf(*b), nil
+	case reflect.PtrTo(_blockType):
+		return reflect.ValueOf(b), nil
+	}
+	if !holds(t, b.Type) {
+		return d.other(b, t)
+	}
+	x, err := parse(b)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !reflect.TypeOf(x).AssignableTo(t) {
+		return d.other(b, t)
+	}
+	v := reflect.New(t).Elem()
+	v.Set(reflect.ValueOf(x))
+	return v, nil
+}
+
+// other returns an invalid value if other blocks are skipped, or an error of
+// the block b which can not be held by t.
+func (d *decoder) other(b *pem.Block, t reflect.Type) (reflect.Value, error) {
+	if d.config.SkipOtherBlocks {
+		return reflect.Value{}, nil
+	}
+	return reflect.Value{}, &UnmarshalTypeError{Block: b.Type, Type: t}
+}
+
+// holds reports whether t may hold the value parsed from a block of type typ,
+// which is decided only by parsing PKCS #8 and PKIX keys.
+func holds(t reflect.Type, typ string) bool {
+	switch t {
+	case _privateKeyType:
+		return isPrivateKey(typ)
+	case _publicKeyType:
+		return isPublicKey(typ)
+	}
+	if pt, ok := _parsedTypes[typ]; ok {
+		return pt.AssignableTo(t)
+	}
+	switch typ {
+	case TypePrivateKey:
+		return t.Kind() == reflect.Interface || t.Implements(_signerType)
+	case TypePublicKey:
+		return t.Kind() == reflect.Interface || t.Implements(_equalerType) ||
+			t == reflect.TypeOf((*dsa.PublicKey)(nil))
+	}
+	return reflect.PtrTo(_blockType).AssignableTo(t)
+}
+
+func isPrivateKey(typ string) bool {
+	return typ == TypePrivateKey || typ == TypeRSAPrivateKey || typ == TypeECPrivateKey
+}
+
+func isPublicKey(typ string) bool {
+	return typ == TypePublicKey || typ == TypeRSAPublicKey
+}
+
+// parse parses the block b according to its type, into a certificate, a
+// certificate request or a key, or returns b itself for unknown types.
+func parse(b *pem.Block) (interface{}, error) {
+	if _, ok := b.Headers["DEK-Info"]; ok && isPrivateKey(b.Type) {
+		return nil, errors.New("pem: encrypted private keys are not supported")
+	}
+	var x interface{}
+	var err error
+	switch b.Type {
+	case TypeCertificate:
+		x, err = x509.ParseCertificate(b.Bytes)
+	case TypeCertificateRequest:
+		x, err = x509.ParseCertificateRequest(b.Bytes)
+	case TypePrivateKey:
+		x, err = x509.ParsePKCS8PrivateKey(b.Bytes)
+	case TypeRSAPrivateKey:
+		x, err = x509.ParsePKCS1PrivateKey(b.Bytes)
+	case TypeECPrivateKey:
+		x, err = x509.ParseECPrivateKey(b.Bytes)
+	case TypePublicKey:
+		x, err = x509.ParsePKIXPublicKey(b.Bytes)
+	case TypeRSAPublicKey:
+		x, err = x509.ParsePKCS1PublicKey(b.Bytes)
+	default:
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pem: %s block: %w", b.Type, err)
+	}
+	return x, nil
+}
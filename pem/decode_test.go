@@ -0,0 +1,101 @@
+package pem
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// bundle returns a key followed by a certificate, with text around them.
+func bundle(t *testing.T) []byte {
+	f := keys(t)
+	e := &encoder{config: &EncoderConfig{Traditional: true}}
+	e.buf.WriteString("# server key\n")
+	if err := e.encode([]interface{}{f.ec, f.cert}); err != nil {
+		t.Fatal(err)
+	}
+	e.buf.WriteString("# end\n")
+	return e.buf.Bytes()
+}
+
+func TestDecoder_Blocks(t *testing.T) {
+	data := bundle(t)
+	var blocks []pem.Block
+	if err := (&decoder{config: &DecoderConfig{}}).decode(data, reflect.ValueOf(&blocks).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0].Type != TypeECPrivateKey || blocks[1].Type != TypeCertificate {
+		t.Errorf("got %v", blocks)
+	}
+	var values []interface{}
+	if err := (&decoder{config: &DecoderConfig{}}).decode(data, reflect.ValueOf(&values).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || !reflect.DeepEqual(values[1], keys(t).cert) {
+		t.Errorf("got %v", values)
+	}
+	unknown := pem.EncodeToMemory(&pem.Block{Type: "MESSAGE", Bytes: []byte("hi")})
+	var v interface{}
+	if err := (&decoder{config: &DecoderConfig{}}).decode(unknown, reflect.ValueOf(&v).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := v.(*pem.Block); !ok || string(b.Bytes) != "hi" {
+		t.Errorf("got %v", v)
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	data := bundle(t)
+	tests := []struct {
+		name string
+		data []byte
+		v    interface{}
+	}{
+		{"multiple blocks", data, new(*pem.Block)},
+		{"wrong type", pem.EncodeToMemory(&pem.Block{Type: TypeCertificate, Bytes: keys(t).cert.Raw}), new(*rsa.PrivateKey)},
+		{"unknown block as private key", pem.EncodeToMemory(&pem.Block{Type: "MESSAGE"}), new(crypto.PrivateKey)},
+		{"invalid der", pem.EncodeToMemory(&pem.Block{Type: TypeCertificate, Bytes: []byte{1, 2}}), new(*x509.Certificate)},
+		{"encrypted", pem.EncodeToMemory(&pem.Block{Type: TypeRSAPrivateKey, Headers: map[string]string{
+			"Proc-Type": "4,ENCRYPTED", "DEK-Info": "AES-128-CBC,00"}, Bytes: []byte{1}}), new(crypto.PrivateKey)},
+	}
+	for _, test := range tests {
+		if err := (&decoder{config: &DecoderConfig{}}).decode(test.data, reflect.ValueOf(test.v).Elem()); err == nil {
+			t.Errorf("%s: expect error", test.name)
+		}
+	}
+	var key *rsa.PrivateKey
+	err := (&decoder{config: &DecoderConfig{}}).decode(data, reflect.ValueOf(&key).Elem())
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Block != TypeECPrivateKey || typeErr.Type != reflect.TypeOf(key) {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestDecoder_SkipUnparsed(t *testing.T) {
+	var data []byte
+	for _, typ := range []string{TypePrivateKey, TypeRSAPrivateKey, TypePublicKey, "MESSAGE"} {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: []byte{1, 2}})...)
+	}
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: TypeCertificate, Bytes: keys(t).cert.Raw})...)
+	var certs []*x509.Certificate
+	if err := (&decoder{config: &DecoderConfig{SkipOtherBlocks: true}}).decode(data, reflect.ValueOf(&certs).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []*x509.Certificate{keys(t).cert}; !reflect.DeepEqual(certs, want) {
+		t.Errorf("got %d certificates", len(certs))
+	}
+	err := (&decoder{config: &DecoderConfig{}}).decode(data, reflect.ValueOf(&certs).Elem())
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Block != TypePrivateKey {
+		t.Errorf("got error %v", err)
+	}
+	var ecKeys []*ecdsa.PrivateKey
+	if err := (&decoder{config: &DecoderConfig{SkipOtherBlocks: true}}).decode(data, reflect.ValueOf(&ecKeys).Elem()); err == nil {
+		t.Error("expect error of malformed PKCS #8 key")
+	}
+}
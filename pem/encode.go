@@ -0,0 +1,106 @@
+package pem
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+)
+
+type encoder struct {
+	config *EncoderConfig
+	buf    bytes.Buffer
+}
+
+// encode appends the blocks of v, a value or a slice of values.
+func (e *encoder) encode(v interface{}) error {
+	if b, err := e.block(v); b != nil || err != nil {
+		if err != nil {
+			return err
+		}
+		return pem.Encode(&e.buf, b)
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("pem: can not marshal %T", v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		b, err := e.block(item)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("pem: can not marshal %T", item)
+		}
+		if err := pem.Encode(&e.buf, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// block returns the block of v, or nil if v is not of a type encoded as a
+// block.
+func (e *encoder) block(v interface{}) (*pem.Block, error) {
+	var typ string
+	var der []byte
+	var err error
+	if rv := reflect.ValueOf(v); !rv.IsValid() || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, fmt.Errorf("pem: can not marshal nil %T", v)
+	}
+	switch v := v.(type) {
+	case pem.Block:
+		return &v, nil
+	case *pem.Block:
+		return v, nil
+	case *x509.Certificate:
+		typ, der = TypeCertificate, v.Raw
+	case *x509.CertificateRequest:
+		typ, der = TypeCertificateRequest, v.Raw
+	case *rsa.PrivateKey:
+		if e.config.Traditional {
+			typ, der = TypeRSAPrivateKey, x509.MarshalPKCS1PrivateKey(v)
+		} else {
+			typ = TypePrivateKey
+			der, err = x509.MarshalPKCS8PrivateKey(v)
+		}
+	case *ecdsa.PrivateKey:
+		if e.config.Traditional {
+			typ = TypeECPrivateKey
+			der, err = x509.MarshalECPrivateKey(v)
+		} else {
+			typ = TypePrivateKey
+			der, err = x509.MarshalPKCS8PrivateKey(v)
+		}
+	case ed25519.PrivateKey:
+		typ = TypePrivateKey
+		der, err = x509.MarshalPKCS8PrivateKey(v)
+	case *rsa.PublicKey:
+		if e.config.Traditional {
+			typ, der = TypeRSAPublicKey, x509.MarshalPKCS1PublicKey(v)
+		} else {
+			typ = TypePublicKey
+			der, err = x509.MarshalPKIXPublicKey(v)
+		}
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		typ = TypePublicKey
+		der, err = x509.MarshalPKIXPublicKey(v)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pem: %w", err)
+	}
+	if len(der) == 0 {
+		return nil, fmt.Errorf("pem: can not marshal %T without raw DER", v)
+	}
+	return &pem.Block{Type: typ, Bytes: der}, nil
+}
@@ -0,0 +1,74 @@
+package pem
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestEncoder_Types(t *testing.T) {
+	f := keys(t)
+	tests := []struct {
+		v           interface{}
+		want        string
+		traditional string
+	}{
+		{f.cert, TypeCertificate, TypeCertificate},
+		{f.csr, TypeCertificateRequest, TypeCertificateRequest},
+		{f.rsa, TypePrivateKey, TypeRSAPrivateKey},
+		{f.ec, TypePrivateKey, TypeECPrivateKey},
+		{f.ed, TypePrivateKey, TypePrivateKey},
+		{&f.rsa.PublicKey, TypePublicKey, TypeRSAPublicKey},
+		{&f.ec.PublicKey, TypePublicKey, TypePublicKey},
+		{f.ed.Public(), TypePublicKey, TypePublicKey},
+		{pem.Block{Type: "MESSAGE"}, "MESSAGE", "MESSAGE"},
+	}
+	for _, test := range tests {
+		for _, traditional := range []bool{false, true} {
+			e := &encoder{config: &EncoderConfig{Traditional: traditional}}
+			if err := e.encode(test.v); err != nil {
+				t.Errorf("%T: %v", test.v, err)
+				continue
+			}
+			b, rest := pem.Decode(e.buf.Bytes())
+			want := test.want
+			if traditional {
+				want = test.traditional
+			}
+			if b == nil || len(rest) != 0 || b.Type != want {
+				t.Errorf("%T: got %q, want block %s", test.v, e.buf.String(), want)
+			}
+		}
+	}
+}
+
+func TestEncoder_Blocks(t *testing.T) {
+	blocks := []*pem.Block{
+		{Type: "MESSAGE", Headers: map[string]string{"Subject": "hello"}, Bytes: []byte("hi")},
+		{Type: "MESSAGE", Bytes: []byte("there")},
+	}
+	e := &encoder{config: &EncoderConfig{}}
+	if err := e.encode(&blocks); err != nil {
+		t.Fatal(err)
+	}
+	want := "-----BEGIN MESSAGE-----\nSubject: hello\n\naGk=\n-----END MESSAGE-----\n" +
+		"-----BEGIN MESSAGE-----\ndGhlcmU=\n-----END MESSAGE-----\n"
+	if got := e.buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	tests := []interface{}{
+		nil,
+		(*x509.Certificate)(nil),
+		&x509.Certificate{},
+		[]interface{}{keys(t).cert, 1},
+		[]byte("raw"),
+	}
+	for _, test := range tests {
+		if err := (&encoder{config: &EncoderConfig{}}).encode(test); err == nil {
+			t.Errorf("%T: expect error", test)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package pem
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"reflect"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, TraditionalKeys())
+	data, err := m.Marshal(context.Background(), keys(t).rsa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := pem.Decode(data); b == nil || b.Type != TypeRSAPrivateKey {
+		t.Errorf("got %s", data)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	data := bundle(t)
+	u := WithDecoderOption(&codec{}, SkipOtherBlocks())
+	var certs []*x509.Certificate
+	if err := u.Unmarshal(context.Background(), data, &certs); err != nil {
+		t.Fatal(err)
+	}
+	if want := []*x509.Certificate{keys(t).cert}; !reflect.DeepEqual(certs, want) {
+		t.Errorf("got %d certificates", len(certs))
+	}
+	var key crypto.PrivateKey
+	if err := u.Unmarshal(context.Background(), data, &key); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(key, keys(t).ec) {
+		t.Errorf("got %T", key)
+	}
+	var pub crypto.PublicKey
+	if err := u.Unmarshal(context.Background(), data, &pub); err != ErrNoBlock {
+		t.Errorf("got error %v, want %v", err, ErrNoBlock)
+	}
+	if err := (&codec{}).Unmarshal(context.Background(), data, &certs); err == nil {
+		t.Error("expect error of key block")
+	}
+}
@@ -0,0 +1,104 @@
+// Package pem defines and registers Marshaler/Unmarshaler handling PEM
+// (RFC 7468) content, the textual encoding of certificates and keys.
+//
+// Certificates (*x509.Certificate), certificate requests
+// (*x509.CertificateRequest), private keys (*rsa.PrivateKey,
+// *ecdsa.PrivateKey and ed25519.PrivateKey), public keys (*rsa.PublicKey,
+// *ecdsa.PublicKey and ed25519.PublicKey) and blocks (pem.Block) are encoded
+// as PEM blocks, and slices of them as consecutive blocks. Private keys are
+// encoded in PKCS #8 and public keys in PKIX by default, the TraditionalKeys
+// option encodes RSA keys in PKCS #1 and ECDSA private keys in SEC 1 instead.
+//
+// When decoding, a block is parsed according to its type into any of the
+// above types, crypto.PrivateKey, crypto.PublicKey, crypto.Signer or
+// interface{}, which holds the parsed certificate or key, or the *pem.Block
+// of unknown types. Private keys are read from PKCS #1, PKCS #8 and SEC 1
+// blocks. A slice receives all blocks, other values exactly one block, and
+// the SkipOtherBlocks option skips blocks of types the value can not hold.
+// Text around blocks is ignored.
+package pem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "pem"
+
+// MIMEType is the media type of PEM.
+const MIMEType = "application/x-pem-file"
+
+// Types of PEM blocks.
+const (
+	TypeCertificate        = "CERTIFICATE"
+	TypeCertificateRequest = "CERTIFICATE REQUEST"
+	TypePrivateKey         = "PRIVATE KEY"
+	TypeRSAPrivateKey      = "RSA PRIVATE KEY"
+	TypeECPrivateKey       = "EC PRIVATE KEY"
+	TypePublicKey          = "PUBLIC KEY"
+	TypeRSAPublicKey       = "RSA PUBLIC KEY"
+)
+
+// ErrNoBlock is returned when decoding data without any PEM block, or without
+// blocks of the expected types if other blocks are skipped.
+var ErrNoBlock = errors.New("pem: no PEM block found")
+
+// UnmarshalTypeError is an error of decoding a block into a value of
+// inappropriate type.
+type UnmarshalTypeError struct {
+	// Block is the type of the block, e.g. "CERTIFICATE".
+	Block string
+	// Type is the type of the target value.
+	Type reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("pem: cannot unmarshal %s block into Go value of type %s", e.Block, e.Type)
+}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	e := &encoder{config: config}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("pem: can not unmarshal to non-pointer or nil %T", v)
+	}
+	d := &decoder{config: config}
+	return d.decode(data, rv.Elem())
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,134 @@
+package pem
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+type fixture struct {
+	rsa   *rsa.PrivateKey
+	ec    *ecdsa.PrivateKey
+	ed    ed25519.PrivateKey
+	cert  *x509.Certificate
+	csr   *x509.CertificateRequest
+	setup sync.Once
+}
+
+var _fixture fixture
+
+// keys returns keys and a self-signed certificate shared by tests.
+func keys(t *testing.T) *fixture {
+	f := &_fixture
+	f.setup.Do(func() {
+		var err error
+		if f.rsa, err = rsa.GenerateKey(rand.Reader, 1024); err != nil {
+			t.Fatal(err)
+		}
+		if f.ec, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+		if _, f.ed, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "example.com"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, f.ec.Public(), f.ec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.cert, err = x509.ParseCertificate(der); err != nil {
+			t.Fatal(err)
+		}
+		der, err = x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: template.Subject}, f.ed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.csr, err = x509.ParseCertificateRequest(der); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if f.cert == nil {
+		t.Fatal("fixture is not set up")
+	}
+	return f
+}
+
+func TestCodec(t *testing.T) {
+	f := keys(t)
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	tests := []struct {
+		name string
+		in   interface{}
+		out  interface{}
+	}{
+		{"certificate", f.cert, new(*x509.Certificate)},
+		{"certificate request", f.csr, new(*x509.CertificateRequest)},
+		{"rsa private key", f.rsa, new(*rsa.PrivateKey)},
+		{"ecdsa private key", f.ec, new(*ecdsa.PrivateKey)},
+		{"ed25519 private key", f.ed, new(ed25519.PrivateKey)},
+		{"rsa public key", &f.rsa.PublicKey, new(*rsa.PublicKey)},
+		{"ecdsa public key", &f.ec.PublicKey, new(*ecdsa.PublicKey)},
+		{"ed25519 public key", f.ed.Public(), new(ed25519.PublicKey)},
+		{"chain", []*x509.Certificate{f.cert, f.cert}, new([]*x509.Certificate)},
+		{"private key", f.rsa, new(crypto.PrivateKey)},
+		{"signer", f.ec, new(crypto.Signer)},
+		{"public key", f.ed.Public(), new(crypto.PublicKey)},
+		{"interface", f.cert, new(interface{})},
+		{"interfaces", []interface{}{f.cert, f.ec}, new(interface{})},
+	}
+	for _, test := range tests {
+		data, err := m.Marshal(context.Background(), test.in)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if err := u.Unmarshal(context.Background(), data, test.out); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := reflect.ValueOf(test.out).Elem().Interface()
+		if !reflect.DeepEqual(got, test.in) {
+			t.Errorf("%s: got %T, want %T", test.name, got, test.in)
+		}
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	c := &codec{}
+	var cert *x509.Certificate
+	if err := c.Unmarshal(context.Background(), nil, cert); err == nil {
+		t.Error("expect error of non-pointer")
+	}
+	if err := c.Unmarshal(context.Background(), []byte("no blocks"), &cert); err != ErrNoBlock {
+		t.Errorf("got error %v, want %v", err, ErrNoBlock)
+	}
+	if _, err := c.Marshal(context.Background(), "text"); err == nil {
+		t.Error("expect error of unsupported type")
+	}
+}
@@ -0,0 +1,66 @@
+package properties
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/nested"
+)
+
+var _valueType = reflect.TypeOf(encoding.Value{})
+
+type decoder struct {
+	config *DecoderConfig
+}
+
+func (d *decoder) decode(entries []*entry, v reflect.Value) error {
+	if d.config.DuplicateKeys == DuplicateError {
+		seen := make(map[string]bool, len(entries))
+		for _, e := range entries {
+			if seen[e.key] {
+				return &SyntaxError{Line: e.line, msg: fmt.Sprintf("duplicate key %q", e.key)}
+			}
+			seen[e.key] = true
+		}
+	}
+	if v.Type() == _valueType {
+		v.Set(reflect.ValueOf(*d.value(entries)))
+		return nil
+	}
+	root := &nested.Node{}
+	for _, e := range entries {
+		if err := root.Add(strings.Split(e.key, "."), e.value, e.line); err != nil {
+			return &SyntaxError{Line: e.line, msg: err.Error()}
+		}
+	}
+	nd := &nested.Decoder{
+		Prefix:                "properties",
+		Tags:                  _tags,
+		First:                 d.config.DuplicateKeys == DuplicateFirst,
+		Multi:                 d.config.DuplicateKeys == DuplicateAppend,
+		DisallowUnknownFields: d.config.DisallowUnknownFields,
+	}
+	return nd.Decode(root, v, nil)
+}
+
+// value decodes entries into an object Value of string members keyed by the
+// full keys, with duplicate keys merged by the policy. A merged member stays
+// at the position of the first occurrence. Comments are kept.
+func (d *decoder) value(entries []*entry) *encoding.Value {
+	members := make([]*encoding.Member, 0, len(entries))
+	index := make(map[string]int, len(entries))
+	for _, e := range entries {
+		value := encoding.NewString(e.value)
+		if i, ok := index[e.key]; ok && d.config.DuplicateKeys != DuplicateAppend {
+			if d.config.DuplicateKeys != DuplicateFirst {
+				members[i].Value = value
+			}
+			continue
+		}
+		index[e.key] = len(members)
+		members = append(members, &encoding.Member{Key: e.key, Value: value, Comment: e.comment})
+	}
+	return encoding.NewObject(members...)
+}
@@ -0,0 +1,142 @@
+package properties
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func unmarshal(config *DecoderConfig, data string, v interface{}) error {
+	entries, err := parse([]byte(data))
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.decode(entries, reflect.ValueOf(v).Elem())
+}
+
+type datasource struct {
+	URL      string   `properties:"url"`
+	Pool     int      `properties:"pool"`
+	Replicas []string `properties:"replica"`
+}
+
+type settings struct {
+	App    map[string]string `properties:"app"`
+	DB     datasource        `properties:"db"`
+	Cache  *datasource       `properties:"cache"`
+	Ignore string            `properties:"-"`
+}
+
+func TestDecoder_Struct(t *testing.T) {
+	data := `db.url = jdbc:x
+db.pool = 4
+db.replica = r1
+db.replica = r2
+cache.url = redis
+app.version = 1.0
+app.owner.team = ops
+`
+	var got settings
+	if err := unmarshal(&DecoderConfig{}, data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := settings{
+		App:   map[string]string{"version": "1.0", "owner.team": "ops"},
+		DB:    datasource{URL: "jdbc:x", Pool: 4, Replicas: []string{"r1", "r2"}},
+		Cache: &datasource{URL: "redis"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDecoder_Duplicates(t *testing.T) {
+	data := "a = 1\nb.c = x\na = 2\nb.c = y\n"
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   interface{}
+	}{
+		{policy: DuplicateLast, want: map[string]interface{}{"a": "2", "b": map[string]interface{}{"c": "y"}}},
+		{policy: DuplicateFirst, want: map[string]interface{}{"a": "1", "b": map[string]interface{}{"c": "x"}}},
+		{policy: DuplicateAppend, want: map[string]interface{}{
+			"a": []interface{}{"1", "2"},
+			"b": map[string]interface{}{"c": []interface{}{"x", "y"}},
+		}},
+	}
+	for _, tt := range tests {
+		var got interface{}
+		if err := unmarshal(&DecoderConfig{DuplicateKeys: tt.policy}, data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("policy %d: got %#v, want %#v", tt.policy, got, tt.want)
+		}
+	}
+	var got interface{}
+	err := unmarshal(&DecoderConfig{DuplicateKeys: DuplicateError}, data, &got)
+	if se, ok := err.(*SyntaxError); !ok || se.Line != 3 {
+		t.Errorf("got %v, want syntax error at line 3", err)
+	}
+}
+
+func TestDecoder_Value(t *testing.T) {
+	data := "# about\nname = app\n\n# db\ndb.url = x\nname = other\n"
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   *encoding.Value
+	}{
+		{policy: DuplicateLast, want: encoding.NewObject(
+			&encoding.Member{Key: "name", Value: encoding.NewString("other")},
+			&encoding.Member{Key: "db.url", Value: encoding.NewString("x")},
+		)},
+		{policy: DuplicateFirst, want: encoding.NewObject(
+			&encoding.Member{Key: "name", Value: encoding.NewString("app")},
+			&encoding.Member{Key: "db.url", Value: encoding.NewString("x")},
+		)},
+		{policy: DuplicateAppend, want: encoding.NewObject(
+			&encoding.Member{Key: "name", Value: encoding.NewString("app")},
+			&encoding.Member{Key: "db.url", Value: encoding.NewString("x")},
+			&encoding.Member{Key: "name", Value: encoding.NewString("other")},
+		)},
+	}
+	for _, tt := range tests {
+		var got encoding.Value
+		if err := unmarshal(&DecoderConfig{DuplicateKeys: tt.policy}, data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("policy %d: got %+v, want %+v", tt.policy, got, tt.want)
+		}
+		if got.Members[0].Comment != "about" || got.Members[1].Comment != "db" {
+			t.Errorf("policy %d: got comments %q, %q", tt.policy, got.Members[0].Comment, got.Members[1].Comment)
+		}
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *DecoderConfig
+		data   string
+		v      interface{}
+	}{
+		{name: "type", data: "db.pool = x\n", v: &settings{}},
+		{name: "unknown field", config: &DecoderConfig{DisallowUnknownFields: true}, data: "db.user = x\n", v: &settings{}},
+		{name: "unsupported", data: "a = 1\n", v: &map[int]int{}},
+		{name: "deep key", data: strings.Repeat("a.", 1000) + "k = 1\n", v: &map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = &DecoderConfig{}
+			}
+			if err := unmarshal(config, tt.data, tt.v); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
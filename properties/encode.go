@@ -0,0 +1,185 @@
+package properties
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/nested"
+)
+
+type encoder struct {
+	config    *EncoderConfig
+	delimiter string
+	buf       bytes.Buffer
+}
+
+func (e *encoder) encode(v interface{}) error {
+	var entries []*entry
+	var err error
+	switch value := v.(type) {
+	case encoding.Value:
+		entries, err = valueEntries(&value)
+	case *encoding.Value:
+		entries, err = valueEntries(value)
+	default:
+		entries, err = flatEntries(reflect.ValueOf(v))
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		e.comment(entry.comment)
+		e.escape(entry.key, true)
+		e.buf.WriteString(e.delimiter)
+		e.escape(entry.value, false)
+		e.buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// flatEntries flattens v into entries keyed by paths joined with ".".
+func flatEntries(v reflect.Value) ([]*entry, error) {
+	flat, err := nested.Flatten(v, "properties", _tags...)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*entry, len(flat))
+	for i, f := range flat {
+		entries[i] = &entry{key: strings.Join(f.Path, "."), value: f.Value}
+	}
+	return entries, nil
+}
+
+// valueEntries converts an object Value into entries: scalar members are
+// entries, array members are repeated keys and members of object members are
+// keyed by their paths joined with ".".
+func valueEntries(value *encoding.Value) ([]*entry, error) {
+	if value == nil || value.Kind == encoding.NullKind {
+		return nil, nil
+	}
+	if value.Kind != encoding.ObjectKind {
+		return nil, fmt.Errorf("properties: top-level value must be an object, not %s", value.Kind)
+	}
+	var entries []*entry
+	var add func(prefix string, value *encoding.Value) error
+	add = func(prefix string, value *encoding.Value) error {
+		for _, m := range value.Members {
+			key := prefix + m.Key
+			if m.Value.Kind == encoding.ObjectKind {
+				if err := add(key+".", m.Value); err != nil {
+					return err
+				}
+				continue
+			}
+			items := []*encoding.Value{m.Value}
+			if m.Value.Kind == encoding.ArrayKind {
+				items = m.Value.Items
+			}
+			for i, item := range items {
+				text, err := scalarText(item)
+				if err != nil {
+					return fmt.Errorf("properties: key %s: %w", key, err)
+				}
+				e := &entry{key: key, value: text}
+				if i == 0 {
+					e.comment = m.Comment
+				}
+				entries = append(entries, e)
+			}
+		}
+		return nil
+	}
+	if err := add("", value); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func scalarText(value *encoding.Value) (string, error) {
+	switch value.Kind {
+	case encoding.NullKind:
+		return "", nil
+	case encoding.BoolKind:
+		return strconv.FormatBool(value.Bool), nil
+	case encoding.NumberKind, encoding.StringKind:
+		return value.Text, nil
+	}
+	return "", fmt.Errorf("unsupported %s value", value.Kind)
+}
+
+func (e *encoder) comment(comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		if line == "" {
+			e.buf.WriteString("#\n")
+			continue
+		}
+		e.buf.WriteString("# ")
+		e.escapeComment(line)
+		e.buf.WriteByte('\n')
+	}
+}
+
+// escapeComment writes a line of comment, escaping characters above U+007E
+// if configured. Line breaks are not possible in lines of comments.
+func (e *encoder) escapeComment(s string) {
+	for _, r := range s {
+		if r > 0x7e && e.config.EscapeUnicode {
+			e.unicode(r)
+			continue
+		}
+		if r == '\r' {
+			continue
+		}
+		e.buf.WriteRune(r)
+	}
+}
+
+// escape writes s escaped like java.util.Properties.store does: spaces of
+// keys and leading spaces of values, "\", "=", ":", "#" and "!" are escaped
+// with a backslash, control characters are escaped as \t, \n, \r, \f or
+// \uXXXX, and characters above U+007E are escaped as \uXXXX if configured.
+func (e *encoder) escape(s string, key bool) {
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if key || i == 0 {
+				e.buf.WriteByte('\\')
+			}
+			e.buf.WriteByte(' ')
+		case '\t':
+			e.buf.WriteString(`\t`)
+		case '\n':
+			e.buf.WriteString(`\n`)
+		case '\r':
+			e.buf.WriteString(`\r`)
+		case '\f':
+			e.buf.WriteString(`\f`)
+		case '\\', '=', ':', '#', '!':
+			e.buf.WriteByte('\\')
+			e.buf.WriteRune(r)
+		default:
+			if r < 0x20 || r == 0x7f || r > 0x7e && e.config.EscapeUnicode {
+				e.unicode(r)
+				continue
+			}
+			e.buf.WriteRune(r)
+		}
+	}
+}
+
+func (e *encoder) unicode(r rune) {
+	if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+		fmt.Fprintf(&e.buf, `\u%04X\u%04X`, r1, r2)
+		return
+	}
+	fmt.Fprintf(&e.buf, `\u%04X`, r)
+}
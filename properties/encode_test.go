@@ -0,0 +1,107 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func marshal(config *EncoderConfig, v interface{}) (string, error) {
+	delimiter := config.Delimiter
+	if delimiter == "" {
+		delimiter = DefaultDelimiter
+	}
+	e := &encoder{config: config, delimiter: delimiter}
+	err := e.encode(v)
+	return e.buf.String(), err
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *EncoderConfig
+		v      interface{}
+		want   string
+	}{
+		{name: "nil", v: nil, want: ""},
+		{name: "struct", v: settings{
+			App:   map[string]string{"version": "1.0"},
+			DB:    datasource{URL: "jdbc:x", Replicas: []string{"r1", "r2"}},
+			Cache: nil,
+		}, want: "app.version=1.0\ndb.url=jdbc\\:x\ndb.pool=0\ndb.replica=r1\ndb.replica=r2\n"},
+		{name: "escapes", v: map[string]string{"a b": " x y", "c=d": "#!\\\t\n\r\f\x01", "e": "é\U0001F600"},
+			want: "a\\ b=\\ x y\nc\\=d=\\#\\!\\\\\\t\\n\\r\\f\\u0001\ne=é\U0001F600\n"},
+		{name: "escape unicode", config: &EncoderConfig{EscapeUnicode: true}, v: map[string]string{"é": "\U0001F600"},
+			want: "\\u00E9=\\uD83D\\uDE00\n"},
+		{name: "delimiter", config: &EncoderConfig{Delimiter: " : "}, v: map[string]int{"a": 1}, want: "a : 1\n"},
+		{name: "value", config: &EncoderConfig{EscapeUnicode: true}, v: encoding.NewObject(
+			&encoding.Member{Key: "name", Value: encoding.NewString("app"), Comment: "about\n\ncafé"},
+			&encoding.Member{Key: "db", Value: encoding.NewObject(
+				&encoding.Member{Key: "pool", Value: encoding.NewNumber("4"), Comment: "pool"},
+				&encoding.Member{Key: "tls", Value: encoding.NewBool(true)},
+				&encoding.Member{Key: "replica", Value: encoding.NewArray(encoding.NewString("r1"), encoding.NewNull())},
+			)},
+		), want: "# about\n#\n# caf\\u00E9\nname=app\n# pool\ndb.pool=4\ndb.tls=true\ndb.replica=r1\ndb.replica=\n"},
+		{name: "null value", v: (*encoding.Value)(nil), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = &EncoderConfig{}
+			}
+			got, err := marshal(config, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	in := map[string]string{
+		"a b":   " x y ",
+		"c=d:e": "#!\\\t\n\r\f\x01",
+		"f":     "é\U0001F600",
+		"":      "empty key",
+		"g#h!":  "",
+		"é":     "=:",
+	}
+	for _, config := range []*EncoderConfig{{}, {EscapeUnicode: true}} {
+		data, err := marshal(config, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out map[string]string
+		if err := unmarshal(&DecoderConfig{}, data, &out); err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range in {
+			if out[k] != v {
+				t.Errorf("key %q: got %q, want %q\n%s", k, out[k], v, data)
+			}
+		}
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "scalar", v: 1},
+		{name: "slice of structs", v: map[string][]datasource{"a": {{}}}},
+		{name: "value scalar", v: encoding.NewString("x")},
+		{name: "value nested array", v: encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewArray(encoding.NewArray())})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := marshal(&EncoderConfig{}, tt.v); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package properties
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// DuplicateKeyPolicy decides the value of a key which appears more than once
+// of a document. Unless the policy is DuplicateError, keys decoded into slices
+// take all of their values.
+type DuplicateKeyPolicy uint8
+
+// Policies of duplicate keys.
+const (
+	// DuplicateLast takes the last value.
+	DuplicateLast DuplicateKeyPolicy = iota
+	// DuplicateFirst takes the first value.
+	DuplicateFirst
+	// DuplicateError fails decoding.
+	DuplicateError
+	// DuplicateAppend takes all values: repeated members of an
+	// encoding.Value, or a []interface{} for interface{}. Other values take
+	// the last one.
+	DuplicateAppend
+)
+
+// DefaultDelimiter is the default delimiter between keys and values.
+const DefaultDelimiter = "="
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Delimiter is written between keys and values, which must be "=" or ":"
+	// optionally surrounded by spaces. Empty means DefaultDelimiter.
+	Delimiter string
+	// EscapeUnicode escapes characters above U+007E as \uXXXX, like
+	// java.util.Properties.store(OutputStream) does, so that the output is
+	// pure ASCII.
+	EscapeUnicode bool
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// DuplicateKeys is the policy of duplicate keys.
+	DuplicateKeys DuplicateKeyPolicy
+	// DisallowUnknownFields fails decoding a key which matches no field.
+	DisallowUnknownFields bool
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// DuplicateKeys produces a DecoderOption which sets the policy of duplicate
+// keys.
+func DuplicateKeys(policy DuplicateKeyPolicy) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.DuplicateKeys = policy
+	}
+}
+
+// DisallowUnknownFields produces a DecoderOption which disallows keys matching
+// no field when decoding into a struct.
+func DisallowUnknownFields() DecoderOption {
+	return func(config *DecoderConfig) {
+		config.DisallowUnknownFields = true
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// Delimiter produces an EncoderOption which sets the delimiter between keys
+// and values.
+func Delimiter(delimiter string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Delimiter = delimiter
+	}
+}
+
+// EscapeUnicode produces an EncoderOption which escapes characters above
+// U+007E as \uXXXX.
+func EscapeUnicode() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.EscapeUnicode = true
+	}
+}
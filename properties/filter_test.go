@@ -0,0 +1,50 @@
+package properties
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	tests := []struct {
+		opt  []EncoderOption
+		want string
+	}{
+		{want: "a=\u00e9\n"},
+		{opt: []EncoderOption{Delimiter(": ")}, want: "a: \u00e9\n"},
+		{opt: []EncoderOption{EscapeUnicode()}, want: "a=\\u00E9\n"},
+	}
+	for _, tt := range tests {
+		got, err := WithEncoderOption(&codec{}, tt.opt...).Marshal(context.Background(), map[string]string{"a": "\u00e9"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+	for _, delimiter := range []string{"-", "==", " = x"} {
+		if _, err := WithEncoderOption(&codec{}, Delimiter(delimiter)).Marshal(context.Background(), map[string]int{}); err == nil {
+			t.Errorf("want error of invalid delimiter %q", delimiter)
+		}
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	var v datasource
+	u := WithDecoderOption(&codec{}, DisallowUnknownFields())
+	if err := u.Unmarshal(context.Background(), []byte("url = a\nname = x\n"), &v); err == nil {
+		t.Error("want error of unknown field")
+	}
+	u = WithDecoderOption(&codec{}, DuplicateKeys(DuplicateFirst))
+	if err := u.Unmarshal(context.Background(), []byte("url = a\nurl = b\n"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.URL != "a" {
+		t.Errorf("got %q, want %q", v.URL, "a")
+	}
+	u = WithDecoderOption(&codec{}, DuplicateKeys(DuplicateError))
+	if err := u.Unmarshal(context.Background(), []byte("url = a\nurl = b\n"), &v); err == nil {
+		t.Error("want error of duplicate key")
+	}
+}
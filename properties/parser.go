@@ -0,0 +1,210 @@
+package properties
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// SyntaxError is an error of parsing properties content.
+type SyntaxError struct {
+	// Line is the 1-based line number where the error occurs.
+	Line int
+	msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("properties: line %d: %s", e.Line, e.msg)
+}
+
+// entry is a key/value pair. Line is the line where the entry starts.
+type entry struct {
+	key     string
+	value   string
+	comment string
+	line    int
+}
+
+// naturalLines splits data into lines terminated by "\n", "\r" or "\r\n".
+func naturalLines(data string) []string {
+	var lines []string
+	for len(data) > 0 {
+		i := strings.IndexAny(data, "\r\n")
+		if i < 0 {
+			lines = append(lines, data)
+			break
+		}
+		lines = append(lines, data[:i])
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			i++
+		}
+		data = data[i+1:]
+	}
+	return lines
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f'
+}
+
+func trimLeft(s string) string {
+	for len(s) > 0 && isSpace(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
+// continued reports whether line ends with an odd number of backslashes.
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// parse parses properties content, in the grammar of
+// java.util.Properties.load, into entries. Comments are attached to the
+// following entry.
+func parse(data []byte) ([]*entry, error) {
+	lines := naturalLines(strings.TrimPrefix(string(data), "\uFEFF"))
+	var entries []*entry
+	var comment []string
+	for i := 0; i < len(lines); i++ {
+		line := trimLeft(lines[i])
+		lineNo := i + 1
+		if line == "" {
+			continue
+		}
+		if line[0] == '#' || line[0] == '!' {
+			text := line[1:]
+			if strings.HasPrefix(text, " ") {
+				text = text[1:]
+			}
+			comment = append(comment, text)
+			continue
+		}
+		logical := line
+		for continued(logical) && i+1 < len(lines) {
+			i++
+			logical = logical[:len(logical)-1] + trimLeft(lines[i])
+		}
+		if continued(logical) {
+			logical = logical[:len(logical)-1]
+		}
+		key, value := split(logical)
+		e := &entry{comment: strings.Join(comment, "\n"), line: lineNo}
+		comment = nil
+		var err error
+		if e.key, err = unescape(key); err != nil {
+			return nil, &SyntaxError{Line: lineNo, msg: err.Error()}
+		}
+		if e.value, err = unescape(value); err != nil {
+			return nil, &SyntaxError{Line: lineNo, msg: err.Error()}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// split splits a logical line into the escaped key and value. The key ends at
+// the first unescaped "=", ":" or whitespace, which may be surrounded by
+// whitespace.
+func split(line string) (key, value string) {
+	i := 0
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || isSpace(c) {
+			break
+		}
+	}
+	if i >= len(line) {
+		return line, ""
+	}
+	key, rest := line[:i], line[i:]
+	if rest[0] == '=' || rest[0] == ':' {
+		return key, trimLeft(rest[1:])
+	}
+	rest = trimLeft(rest)
+	if len(rest) > 0 && (rest[0] == '=' || rest[0] == ':') {
+		rest = rest[1:]
+	}
+	return key, trimLeft(rest)
+}
+
+// unescape resolves escapes: \t, \n, \r, \f, \uXXXX, where surrogate pairs
+// are combined, and \c which means c. A trailing lone backslash continues the
+// line at the end of input, so it is dropped.
+func unescape(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	var high rune
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 == len(s) {
+			break
+		}
+		if c != '\\' {
+			if high != 0 {
+				b.WriteRune(high)
+				high = 0
+			}
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		var r rune
+		switch s[i] {
+		case 't':
+			r = '\t'
+		case 'n':
+			r = '\n'
+		case 'r':
+			r = '\r'
+		case 'f':
+			r = '\f'
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape %q", s[i-1:])
+			}
+			n, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape %q", s[i-1:i+5])
+			}
+			i += 4
+			r = rune(n)
+		default:
+			if s[i] >= 0x80 {
+				// Keep a multi-byte UTF-8 character intact.
+				i--
+				continue
+			}
+			r = rune(s[i])
+		}
+		if high != 0 {
+			if utf16.IsSurrogate(r) && r >= 0xDC00 {
+				b.WriteRune(utf16.DecodeRune(high, r))
+				high = 0
+				continue
+			}
+			b.WriteRune(high)
+			high = 0
+		}
+		if utf16.IsSurrogate(r) && r < 0xDC00 {
+			high = r
+			continue
+		}
+		b.WriteRune(r)
+	}
+	if high != 0 {
+		b.WriteRune(high)
+	}
+	return b.String(), nil
+}
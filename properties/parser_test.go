@@ -0,0 +1,76 @@
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := "\uFEFF# about\r\n! app\r\nname = app\rlist = a, \\\n    b, \\\r\n\tc\n\n  key\\ with\\ spaces : v\\=1\nempty\nsep  value\ncolon:=x\nescapes = \\t\\n\\u00e9\\ud83d\\ude00\\q\\\\\ntrail = \\\n"
+	got, err := parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*entry{
+		{key: "name", value: "app", comment: "about\napp", line: 3},
+		{key: "list", value: "a, b, c", line: 4},
+		{key: "key with spaces", value: "v=1", line: 8},
+		{key: "empty", value: "", line: 9},
+		{key: "sep", value: "value", line: 10},
+		{key: "colon", value: "=x", line: 11},
+		{key: "escapes", value: "\t\n\u00e9\U0001F600q\\", line: 12},
+		{key: "trail", value: "", line: 13},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for _, e := range got {
+			t.Logf("%+v", e)
+		}
+		t.Error("unexpected entries")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		line int
+	}{
+		{name: "short unicode", data: "a = 1\nb = \\u12\n", line: 2},
+		{name: "invalid unicode", data: "\\uXYZW = 1\n", line: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse([]byte(tt.data))
+			se, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("got %v, want *SyntaxError", err)
+			}
+			if se.Line != tt.line {
+				t.Errorf("got line %d, want %d", se.Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := map[string]string{
+		`plain`:              "plain",
+		`\u0041\u00e9`:       "A\u00e9",
+		`\ud83d\ude00`:       "\U0001F600",
+		`\ud83dx`:            "\uFFFDx",
+		"\\\u00e9":           "\u00e9",
+		`\ud83d\ud83d\ude00`: "\uFFFD\U0001F600",
+		`a\`:                 "a",
+		`a\\\`:               "a\\",
+		`\ud83d\`:            "\uFFFD",
+	}
+	for in, want := range tests {
+		got, err := unescape(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("unescape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
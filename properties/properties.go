@@ -0,0 +1,82 @@
+// Package properties defines and registers Marshaler/Unmarshaler handling
+// Java properties content, in the grammar of java.util.Properties.
+//
+// Keys are paths whose names are separated by "." and mapped to nested
+// structs or maps: key a.b.c is addressed by the path a, b, c. Fields are
+// named by the "properties" tag, and slices of scalars are mapped to repeated
+// keys. Lines starting with "#" or "!" are comments, which are kept on the
+// members of an encoding.Value, so that documents can be round-tripped with
+// their comments. Escapes, including \uXXXX, and line continuations are
+// resolved when decoding and written when encoding.
+//
+// Content is read and written in UTF-8. Content in ISO-8859-1, which
+// java.util.Properties uses for streams, or other charsets can be handled by
+// composing the codec with encoding.EncodeWithCharset/
+// encoding.DecodingWithCharset filters, or by EscapeUnicode.
+package properties
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "properties"
+
+var _tags = []string{"properties"}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	delimiter := config.Delimiter
+	if delimiter == "" {
+		delimiter = DefaultDelimiter
+	}
+	if d := strings.TrimSpace(delimiter); d != "=" && d != ":" || strings.Trim(delimiter, " =:") != "" {
+		return nil, fmt.Errorf("properties: invalid delimiter %q", delimiter)
+	}
+	e := &encoder{config: config, delimiter: delimiter}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("properties: can not unmarshal to non-pointer or nil %T", v)
+	}
+	entries, err := parse(data)
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.decode(entries, rv.Elem())
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,77 @@
+package properties
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestCodec_Struct(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := settings{
+		App:   map[string]string{"version": "1.0", "owner": " ops "},
+		DB:    datasource{URL: "jdbc:x://h", Pool: 4, Replicas: []string{"r1", "r2"}},
+		Cache: &datasource{URL: "redis"},
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out settings
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v\n%s", out, in, data)
+	}
+}
+
+func TestCodec_ValueComments(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	data := []byte("# about\n#\n# the app\nname=app\n# pool\ndb.pool=4\ndb.url=jdbc\\:x\n")
+	var v encoding.Value
+	if err := u.Unmarshal(context.Background(), data, &v); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Marshal(context.Background(), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %q, want %q", got, data)
+	}
+}
+
+func TestCodec_Charset(t *testing.T) {
+	m := encoding.FilterMarshaler(encoding.GetMarshaler(Name), encoding.EncodeWithCharset("ISO-8859-1"))
+	u := encoding.FilterUnmarshaler(encoding.GetUnmarshaler(Name), encoding.DecodingWithCharset("ISO-8859-1"))
+	in := map[string]string{"name": "caf\u00e9"}
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte("name=caf\xe9\n"); !bytes.Equal(data, want) {
+		t.Errorf("got %q, want %q", data, want)
+	}
+	var out map[string]string
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %v, want %v", out, in)
+	}
+}
+
+func TestCodec_Unmarshal_NonPointer(t *testing.T) {
+	u := encoding.GetUnmarshaler(Name)
+	var v map[string]string
+	if err := u.Unmarshal(context.Background(), []byte("a = 1"), v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+	if err := u.Unmarshal(context.Background(), []byte("a = \\u"), &v); err == nil {
+		t.Error("want syntax error")
+	}
+}
@@ -0,0 +1,218 @@
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var errOverflow = errors.New("value overflows the field")
+
+type decoder struct {
+	depth int
+}
+
+// message decodes the fields in b into the struct v. Fields which are not in
+// the struct are skipped. Fields of nested messages are merged, and values of
+// repeated fields are appended.
+func (d *decoder) message(b []byte, v reflect.Value) error {
+	if d.depth++; d.depth > maxDepth {
+		return errDepth
+	}
+	defer func() { d.depth-- }()
+	p, err := planOf(v.Type())
+	if err != nil {
+		return err
+	}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		f, ok := p.byNum[num]
+		if !ok {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		if n, err = d.field(b, f, typ, v.Field(f.index)); err == errDepth {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// field decodes a value of wire type typ in b into the field v, and returns
+// the number of bytes consumed.
+func (d *decoder) field(b []byte, f *field, typ protowire.Type, v reflect.Value) (int, error) {
+	switch {
+	case f.key != nil:
+		if typ != protowire.BytesType {
+			return 0, fmt.Errorf("wire type %d, want %d", typ, protowire.BytesType)
+		}
+		entry, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.New(v.Type().Key()).Elem()
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := d.entry(entry, f, key, value); err != nil {
+			return 0, err
+		}
+		v.SetMapIndex(key, value)
+		return n, nil
+	case f.repeated && f.kind != bytesKind && typ == protowire.BytesType:
+		packed, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		for len(packed) > 0 {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			m, err := d.scalar(packed, f.kind, elem)
+			if err != nil {
+				return 0, err
+			}
+			v.Set(reflect.Append(v, elem))
+			packed = packed[m:]
+		}
+		return n, nil
+	case f.repeated:
+		elem := reflect.New(v.Type().Elem()).Elem()
+		n, err := d.value(b, f, typ, elem)
+		if err != nil {
+			return 0, err
+		}
+		v.Set(reflect.Append(v, elem))
+		return n, nil
+	}
+	return d.value(b, f, typ, v)
+}
+
+// entry decodes a map entry into key and value.
+func (d *decoder) entry(b []byte, f *field, key, value reflect.Value) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch num {
+		case 1:
+			n, err := d.value(b, f.key, typ, key)
+			if err != nil {
+				return fmt.Errorf("map key: %w", err)
+			}
+			b = b[n:]
+		case 2:
+			n, err := d.value(b, f.value, typ, value)
+			if err != nil {
+				return fmt.Errorf("map value: %w", err)
+			}
+			b = b[n:]
+		default:
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		value.Set(reflect.New(value.Type().Elem()))
+	}
+	return nil
+}
+
+// value decodes a single value of wire type typ in b into v, and returns the
+// number of bytes consumed.
+func (d *decoder) value(b []byte, f *field, typ protowire.Type, v reflect.Value) (int, error) {
+	if want := f.kind.wireType(); typ != want {
+		return 0, fmt.Errorf("wire type %d, want %d", typ, want)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if f.kind != bytesKind {
+		return d.scalar(b, f.kind, v)
+	}
+	data, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if !utf8.Valid(data) {
+			return 0, errors.New("invalid UTF-8")
+		}
+		v.SetString(string(data))
+	case reflect.Slice:
+		v.SetBytes(append([]byte{}, data...))
+	default:
+		if err := d.message(data, v); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// scalar decodes a value of a scalar kind in b, without tag, into v, and
+// returns the number of bytes consumed.
+func (d *decoder) scalar(b []byte, k kind, v reflect.Value) (int, error) {
+	var x uint64
+	var n int
+	switch k {
+	case varintKind, zigzagKind:
+		x, n = protowire.ConsumeVarint(b)
+	case fixed32Kind:
+		var y uint32
+		y, n = protowire.ConsumeFixed32(b)
+		x = uint64(y)
+		switch v.Kind() {
+		case reflect.Float32:
+			x = math.Float64bits(float64(math.Float32frombits(y)))
+		case reflect.Int8, reflect.Int16, reflect.Int32:
+			x = uint64(int32(y))
+		}
+	case fixed64Kind:
+		x, n = protowire.ConsumeFixed64(b)
+	}
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(x != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := int64(x)
+		if k == zigzagKind {
+			i = protowire.DecodeZigZag(x)
+		}
+		if v.OverflowInt(i) {
+			return 0, errOverflow
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.OverflowUint(x) {
+			return 0, errOverflow
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(math.Float64frombits(x))
+	}
+	return n, nil
+}
@@ -0,0 +1,104 @@
+package protobuf
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func decode(data []byte, v interface{}) error {
+	return (&decoder{}).message(data, reflect.ValueOf(v).Elem())
+}
+
+func TestDecode(t *testing.T) {
+	for _, tt := range _wire {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reflect.New(reflect.TypeOf(tt.v).Elem())
+			if err := decode(mustHex(tt.hex), got.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Interface(), tt.v) {
+				t.Errorf("got %+v, want %+v", got.Interface(), tt.v)
+			}
+		})
+	}
+}
+
+func TestDecodeWireForms(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		want shape
+	}{
+		{name: "unpacked into packed", hex: "30 01 30 02", want: shape{Values: []int32{1, 2}}},
+		{name: "packed into unpacked", hex: "3a 02 01 00", want: shape{Flags: []bool{true, false}}},
+		{name: "unknown fields", hex: "78 01 82 01 01 00 8d 01 00 00 00 00 08 01", want: shape{ID: 1}},
+		{name: "last scalar wins", hex: "08 01 08 02", want: shape{ID: 2}},
+		{name: "messages merged", hex: "2a 02 08 02 2a 02 10 04", want: shape{Center: point{X: 1, Y: 2}}},
+		{name: "map entry without value", hex: "42 03 0a 01 61", want: shape{Labels: map[string]uint32{"a": 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got shape
+			if err := decode(mustHex(tt.hex), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		v    interface{}
+		want string
+	}{
+		{name: "truncated tag", hex: "80", v: &shape{}},
+		{name: "truncated bytes", hex: "52 02 ff", v: &shape{}},
+		{name: "wrong wire type", hex: "0d 00 00 00 00", v: &shape{}, want: "field ID: wire type 5, want 0"},
+		{name: "invalid utf-8", hex: "2a 03 1a 01 ff", v: &shape{}, want: "field Center: field Name: invalid UTF-8"},
+		{name: "overflow", hex: "08 80 80 80 80 10", v: &struct {
+			A uint32 `pb:"1"`
+		}{}, want: "field A: value overflows the field"},
+		{name: "unsupported type", hex: "", v: &struct {
+			A complex64 `pb:"1"`
+		}{}, want: "unsupported type complex64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decode(mustHex(tt.hex), tt.v)
+			if err == nil {
+				t.Fatal("expect error")
+			}
+			if tt.want != "" && !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %q, want %q", err, tt.want)
+			}
+		})
+	}
+	if err := decode(mustHex("52 02 ff"), &shape{}); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestDecodeDepth(t *testing.T) {
+	// sizes[i] is the size of the i-th message from the innermost one.
+	sizes := make([]int, maxDepth+1)
+	for i := 1; i < len(sizes); i++ {
+		sizes[i] = 1 + len(protowire.AppendVarint(nil, uint64(sizes[i-1]))) + sizes[i-1]
+	}
+	var data []byte
+	for i := len(sizes) - 1; i > 0; i-- {
+		data = protowire.AppendVarint(append(data, 0x12), uint64(sizes[i-1]))
+	}
+	if err := decode(data, &node{}); !errors.Is(err, errDepth) {
+		t.Errorf("got error %v, want %v", err, errDepth)
+	}
+}
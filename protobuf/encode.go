@@ -0,0 +1,160 @@
+package protobuf
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxDepth is the maximum depth of nested messages, as the one of
+// google.golang.org/protobuf.
+const maxDepth = 10000
+
+var errDepth = errors.New("exceeded maximum recursion depth")
+
+type encoder struct {
+	depth int
+}
+
+// message appends the fields of the struct v to b.
+func (e *encoder) message(b []byte, v reflect.Value) ([]byte, error) {
+	if e.depth++; e.depth > maxDepth {
+		return nil, errDepth
+	}
+	defer func() { e.depth-- }()
+	p, err := planOf(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range p.fields {
+		if b, err = e.field(b, f, v.Field(f.index)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// field appends a field of a message to b. Zero scalars, nil pointers and
+// empty slices and maps are omitted.
+func (e *encoder) field(b []byte, f *field, v reflect.Value) ([]byte, error) {
+	var err error
+	switch {
+	case f.key != nil:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+		for _, k := range keys {
+			var entry []byte
+			if entry, err = e.value(entry, f.key, k); err != nil {
+				return nil, err
+			}
+			if entry, err = e.value(entry, f.value, v.MapIndex(k)); err != nil {
+				return nil, err
+			}
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendBytes(b, entry)
+		}
+		return b, nil
+	case f.packed:
+		if v.Len() == 0 {
+			return b, nil
+		}
+		var packed []byte
+		for i := 0; i < v.Len(); i++ {
+			packed = appendScalar(packed, f.kind, v.Index(i))
+		}
+		b = protowire.AppendTag(b, f.num, protowire.BytesType)
+		return protowire.AppendBytes(b, packed), nil
+	case f.repeated:
+		for i := 0; i < v.Len(); i++ {
+			if b, err = e.value(b, f, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			return b, nil
+		}
+	case v.IsZero():
+		return b, nil
+	}
+	return e.value(b, f, v)
+}
+
+// value appends a single value of a field to b, including its tag.
+func (e *encoder) value(b []byte, f *field, v reflect.Value) ([]byte, error) {
+	b = protowire.AppendTag(b, f.num, f.kind.wireType())
+	if f.kind != bytesKind {
+		return appendScalar(b, f.kind, v), nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return protowire.AppendVarint(b, 0), nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return protowire.AppendString(b, v.String()), nil
+	case reflect.Slice:
+		return protowire.AppendBytes(b, v.Bytes()), nil
+	}
+	m, err := e.message(nil, v)
+	if err != nil {
+		return nil, err
+	}
+	return protowire.AppendBytes(b, m), nil
+}
+
+// appendScalar appends a value of a scalar kind to b, without tag.
+func appendScalar(b []byte, k kind, v reflect.Value) []byte {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	switch k {
+	case varintKind:
+		return protowire.AppendVarint(b, bits(v))
+	case zigzagKind:
+		return protowire.AppendVarint(b, protowire.EncodeZigZag(v.Int()))
+	case fixed32Kind:
+		if v.Kind() == reflect.Float32 {
+			return protowire.AppendFixed32(b, math.Float32bits(float32(v.Float())))
+		}
+		return protowire.AppendFixed32(b, uint32(bits(v)))
+	}
+	if v.Kind() == reflect.Float64 {
+		return protowire.AppendFixed64(b, math.Float64bits(v.Float()))
+	}
+	return protowire.AppendFixed64(b, bits(v))
+}
+
+// bits returns a bool or an integer as uint64. Negative integers are sign
+// extended.
+func bits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
+
+// less orders map keys, so that maps are encoded deterministically.
+func less(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	}
+	return a.String() < b.String()
+}
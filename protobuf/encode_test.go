@@ -0,0 +1,95 @@
+package protobuf
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	X       int32  `pb:"1,zigzag"`
+	Y       int32  `pb:"2,zigzag"`
+	Name    string `pb:"3"`
+	Ignored string
+}
+
+type shape struct {
+	ID      uint64            `pb:"1"`
+	Ratio   float64           `pb:"2"`
+	Scale   float32           `pb:"3"`
+	Points  []*point          `pb:"4"`
+	Center  point             `pb:"5"`
+	Values  []int32           `pb:"6"`
+	Flags   []bool            `pb:"7,unpacked"`
+	Labels  map[string]uint32 `pb:"8"`
+	Visible *bool             `pb:"9"`
+	Data    []byte            `pb:"10"`
+	CRC     uint32            `pb:"11,fixed32"`
+	Offset  int64             `pb:"12,fixed64"`
+	Delta   int32             `pb:"13"`
+}
+
+type node struct {
+	Value    int32   `pb:"1"`
+	Children []*node `pb:"2"`
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var _false = false
+
+var _wire = []struct {
+	name string
+	v    interface{}
+	hex  string
+}{
+	{"empty", &shape{}, ""},
+	{"uint64", &shape{ID: 150}, "08 96 01"},
+	{"float64", &shape{Ratio: 1}, "11 00 00 00 00 00 00 f0 3f"},
+	{"float32", &shape{Scale: 1}, "1d 00 00 80 3f"},
+	{"zigzag", &point{X: -1, Y: 1}, "08 01 10 02"},
+	{"string", &point{Name: "ab"}, "1a 02 61 62"},
+	{"repeated message", &shape{Points: []*point{{X: 1}, {}}}, "22 02 08 02 22 00"},
+	{"message", &shape{Center: point{Y: -2}}, "2a 02 10 03"},
+	{"packed", &shape{Values: []int32{1, 0, 300}}, "32 04 01 00 ac 02"},
+	{"unpacked", &shape{Flags: []bool{true, false}}, "38 01 38 00"},
+	{"map", &shape{Labels: map[string]uint32{"b": 2, "a": 0}}, "42 05 0a 01 61 10 00 42 05 0a 01 62 10 02"},
+	{"optional", &shape{Visible: &_false}, "48 00"},
+	{"bytes", &shape{Data: []byte{0xff}}, "52 01 ff"},
+	{"fixed32", &shape{CRC: 1}, "5d 01 00 00 00"},
+	{"sfixed64", &shape{Offset: -2}, "61 fe ff ff ff ff ff ff ff"},
+	{"negative int32", &shape{Delta: -1}, "68 ff ff ff ff ff ff ff ff ff 01"},
+	{"recursive", &node{Value: 1, Children: []*node{{Value: 2}}}, "08 01 12 02 08 02"},
+}
+
+func TestEncode(t *testing.T) {
+	for _, tt := range _wire {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&encoder{}).message(nil, reflect.ValueOf(tt.v).Elem())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustHex(tt.hex); hex.EncodeToString(got) != hex.EncodeToString(want) {
+				t.Errorf("got % x, want % x", got, want)
+			}
+		})
+	}
+}
+
+func TestEncodeDepth(t *testing.T) {
+	root := &node{}
+	for n, i := root, 0; i < maxDepth; i++ {
+		n.Children = []*node{{}}
+		n = n.Children[0]
+	}
+	if _, err := (&encoder{}).message(nil, reflect.ValueOf(root).Elem()); err != errDepth {
+		t.Errorf("got error %v, want %v", err, errDepth)
+	}
+}
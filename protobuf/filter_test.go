@@ -0,0 +1,51 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	msg, err := structpb.NewStruct(map[string]interface{}{"b": 1, "a": "x", "c": true, "d": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := WithEncoderOption(&codec{}, Deterministic())
+	got, err := m.Marshal(context.Background(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	// Seconds 1 followed by the unknown field 15.
+	data := []byte{0x08, 0x01, 0x78, 0x01}
+	tests := []struct {
+		opt  []DecoderOption
+		want int
+	}{
+		{want: 4},
+		{opt: []DecoderOption{DiscardUnknown()}, want: 2},
+	}
+	for _, tt := range tests {
+		var msg timestamppb.Timestamp
+		if err := WithDecoderOption(&codec{}, tt.opt...).Unmarshal(context.Background(), data, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Seconds != 1 {
+			t.Errorf("got %v", &msg)
+		}
+		if n := proto.Size(&msg); n != tt.want {
+			t.Errorf("got size %d, want %d", n, tt.want)
+		}
+	}
+}
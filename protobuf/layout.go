@@ -0,0 +1,199 @@
+package protobuf
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// kind is the encoding of a scalar, as named in the "pb" tag.
+type kind uint8
+
+const (
+	varintKind kind = iota + 1
+	zigzagKind
+	fixed32Kind
+	fixed64Kind
+	bytesKind
+)
+
+var _kindNames = map[string]kind{
+	"varint":  varintKind,
+	"zigzag":  zigzagKind,
+	"fixed32": fixed32Kind,
+	"fixed64": fixed64Kind,
+	"bytes":   bytesKind,
+}
+
+func (k kind) String() string {
+	for name, kk := range _kindNames {
+		if kk == k {
+			return name
+		}
+	}
+	return "kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// wireType returns the wire type of a single value of the kind.
+func (k kind) wireType() protowire.Type {
+	switch k {
+	case varintKind, zigzagKind:
+		return protowire.VarintType
+	case fixed32Kind:
+		return protowire.Fixed32Type
+	case fixed64Kind:
+		return protowire.Fixed64Type
+	}
+	return protowire.BytesType
+}
+
+// field is a field of a message, or the key or value of a map entry.
+type field struct {
+	index int
+	name  string
+	num   protowire.Number
+	kind  kind
+	// repeated reports whether the field is a slice other than []byte.
+	repeated bool
+	// packed reports whether a repeated scalar field is packed.
+	packed bool
+	// key and value are the fields of entries of a map field.
+	key, value *field
+}
+
+// plan is the layout of a struct type.
+type plan struct {
+	fields []*field
+	byNum  map[protowire.Number]*field
+}
+
+var _plans sync.Map
+
+// planOf returns the plan of the struct type t. Types of nested messages are
+// planned when they are encoded or decoded.
+func planOf(t reflect.Type) (*plan, error) {
+	if p, ok := _plans.Load(t); ok {
+		return p.(*plan), nil
+	}
+	p := &plan{byNum: map[protowire.Number]*field{}}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("pb")
+		if !ok || tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+		f, err := parseField(sf.Type, tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s: field %s: %w", t, sf.Name, err)
+		}
+		if g, ok := p.byNum[f.num]; ok {
+			return nil, fmt.Errorf("%s: fields %s and %s have the same number %d", t, g.name, sf.Name, f.num)
+		}
+		f.index, f.name = i, sf.Name
+		p.fields = append(p.fields, f)
+		p.byNum[f.num] = f
+	}
+	actual, _ := _plans.LoadOrStore(t, p)
+	return actual.(*plan), nil
+}
+
+// parseField parses the "pb" tag of a field of type t, which is the field
+// number, optionally followed by the kind and "packed" or "unpacked".
+func parseField(t reflect.Type, tag string) (*field, error) {
+	parts := strings.Split(tag, ",")
+	n, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 32)
+	if err != nil || !protowire.Number(n).IsValid() {
+		return nil, fmt.Errorf("invalid field number %q", parts[0])
+	}
+	f := &field{num: protowire.Number(n)}
+	unpacked := false
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "packed":
+		case "unpacked":
+			unpacked = true
+		default:
+			k, ok := _kindNames[part]
+			if !ok {
+				return nil, fmt.Errorf("unknown option %q", part)
+			}
+			f.kind = k
+		}
+	}
+	switch {
+	case t.Kind() == reflect.Map:
+		f.key = &field{num: 1}
+		if err := f.key.check(t.Key()); err != nil {
+			return nil, fmt.Errorf("map key: %w", err)
+		}
+		if k := t.Key().Kind(); k == reflect.Float32 || k == reflect.Float64 || f.key.kind == bytesKind && k != reflect.String {
+			return nil, fmt.Errorf("map key: unsupported type %s", t.Key())
+		}
+		f.value = &field{num: 2, kind: f.kind}
+		if err := f.value.check(t.Elem()); err != nil {
+			return nil, fmt.Errorf("map value: %w", err)
+		}
+		f.kind = bytesKind
+		return f, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		f.repeated = true
+		if err := f.check(t.Elem()); err != nil {
+			return nil, err
+		}
+		f.packed = f.kind != bytesKind && !unpacked
+		return f, nil
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() != reflect.Struct:
+		// A pointer to a scalar is an optional field.
+		return f, f.check(t.Elem())
+	}
+	return f, f.check(t)
+}
+
+// check checks that values of type t can be encoded as the kind of the field.
+// If the kind is not set, it is inferred from t.
+func (f *field) check(t reflect.Type) error {
+	var kinds []kind
+	switch t.Kind() {
+	case reflect.Bool:
+		kinds = []kind{varintKind}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		kinds = []kind{varintKind, zigzagKind, fixed32Kind, fixed64Kind}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		kinds = []kind{varintKind, zigzagKind, fixed64Kind}
+	case reflect.Float32:
+		kinds = []kind{fixed32Kind}
+	case reflect.Float64:
+		kinds = []kind{fixed64Kind}
+	case reflect.String, reflect.Struct:
+		kinds = []kind{bytesKind}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			kinds = []kind{bytesKind}
+		}
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct {
+			kinds = []kind{bytesKind}
+		}
+	}
+	if len(kinds) == 0 {
+		return fmt.Errorf("unsupported type %s", t)
+	}
+	if f.kind == 0 {
+		f.kind = kinds[0]
+		return nil
+	}
+	for _, k := range kinds {
+		if k == f.kind {
+			if k == zigzagKind && t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64 {
+				break
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("can not encode %s as %s", t, f.kind)
+}
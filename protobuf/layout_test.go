@@ -0,0 +1,83 @@
+package protobuf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		tag  string
+		typ  reflect.Type
+		want field
+	}{
+		{tag: "1", typ: reflect.TypeOf(int32(0)), want: field{num: 1, kind: varintKind}},
+		{tag: "2,zigzag", typ: reflect.TypeOf(int64(0)), want: field{num: 2, kind: zigzagKind}},
+		{tag: "3", typ: reflect.TypeOf(float32(0)), want: field{num: 3, kind: fixed32Kind}},
+		{tag: "4, fixed32", typ: reflect.TypeOf(uint32(0)), want: field{num: 4, kind: fixed32Kind}},
+		{tag: "5", typ: reflect.TypeOf(""), want: field{num: 5, kind: bytesKind}},
+		{tag: "6", typ: reflect.TypeOf([]byte{}), want: field{num: 6, kind: bytesKind}},
+		{tag: "7", typ: reflect.TypeOf(&point{}), want: field{num: 7, kind: bytesKind}},
+		{tag: "8", typ: reflect.TypeOf([]int64{}), want: field{num: 8, kind: varintKind, repeated: true, packed: true}},
+		{tag: "9,fixed64,unpacked", typ: reflect.TypeOf([]uint64{}), want: field{num: 9, kind: fixed64Kind, repeated: true}},
+		{tag: "10", typ: reflect.TypeOf([]string{}), want: field{num: 10, kind: bytesKind, repeated: true}},
+		{tag: "11", typ: reflect.TypeOf(new(bool)), want: field{num: 11, kind: varintKind}},
+		{
+			tag: "12,zigzag", typ: reflect.TypeOf(map[string]int32{}),
+			want: field{num: 12, kind: bytesKind, key: &field{num: 1, kind: bytesKind}, value: &field{num: 2, kind: zigzagKind}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseField(tt.typ, tt.tag)
+		if err != nil {
+			t.Errorf("parseField(%s, %q) error: %v", tt.typ, tt.tag, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("parseField(%s, %q) = %+v, want %+v", tt.typ, tt.tag, *got, tt.want)
+		}
+	}
+}
+
+func TestParseFieldError(t *testing.T) {
+	tests := []struct {
+		tag string
+		typ reflect.Type
+	}{
+		{tag: "", typ: reflect.TypeOf(0)},
+		{tag: "0", typ: reflect.TypeOf(0)},
+		{tag: "19000", typ: reflect.TypeOf(0)},
+		{tag: "1,sint", typ: reflect.TypeOf(0)},
+		{tag: "1,zigzag", typ: reflect.TypeOf(uint32(0))},
+		{tag: "1,fixed32", typ: reflect.TypeOf(int64(0))},
+		{tag: "1,varint", typ: reflect.TypeOf(float64(0))},
+		{tag: "1", typ: reflect.TypeOf([]*int{})},
+		{tag: "1", typ: reflect.TypeOf([][]int{})},
+		{tag: "1", typ: reflect.TypeOf(map[float64]int{})},
+		{tag: "1", typ: reflect.TypeOf(map[point]int{})},
+		{tag: "1", typ: reflect.TypeOf(map[string][]int{})},
+		{tag: "1", typ: reflect.TypeOf(complex64(0))},
+	}
+	for _, tt := range tests {
+		if _, err := parseField(tt.typ, tt.tag); err == nil {
+			t.Errorf("parseField(%s, %q) expect error", tt.typ, tt.tag)
+		}
+	}
+}
+
+func TestPlanOf(t *testing.T) {
+	p, err := planOf(reflect.TypeOf(point{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.fields) != 3 || p.byNum[3].name != "Name" {
+		t.Errorf("got plan %+v", p)
+	}
+	type dup struct {
+		A int `pb:"1"`
+		B int `pb:"1"`
+	}
+	if _, err := planOf(reflect.TypeOf(dup{})); err == nil {
+		t.Error("expect error of duplicate numbers")
+	}
+}
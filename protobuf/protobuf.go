@@ -0,0 +1,119 @@
+// Package protobuf defines and registers Marshaler/Unmarshaler handling
+// Protocol Buffers content in the binary wire format.
+//
+// Values implementing proto.Message, such as generated messages, are handled
+// by google.golang.org/protobuf/proto. Plain Go structs can be used as simple
+// messages without generated code, by tagging their fields with "pb":
+//
+//	type Point struct {
+//	    X    int32   `pb:"1,zigzag"`
+//	    Y    int32   `pb:"2,zigzag"`
+//	    Name string  `pb:"3"`
+//	    Tags []int64 `pb:"4,varint,unpacked"`
+//	}
+//
+// A tag is the field number, optionally followed by the encoding of the field
+// and "packed" or "unpacked". Encodings are:
+//
+//   - varint: bool, integers. It is the default for them. Negative integers
+//     take 10 bytes, as int32 and int64 of protobuf.
+//   - zigzag: signed integers, as sint32 and sint64.
+//   - fixed32: integers of up to 32 bits, as fixed32 and sfixed32, and
+//     float32, which is the default for it.
+//   - fixed64: 64-bit integers, as fixed64 and sfixed64, and float64, which
+//     is the default for it.
+//   - bytes: strings, []byte, and structs or pointers to structs as nested
+//     messages. It is the default for them.
+//
+// Slices other than []byte are repeated fields, with the encoding applied to
+// their elements. Repeated fields of numbers and bools are packed unless
+// "unpacked" is set; both forms are accepted when decoding. Maps are map
+// fields, with the encoding applied to their values, and keys, which must be
+// bools, integers or strings, encoded by their default. Pointers to scalars
+// are fields with presence, written when not nil.
+//
+// Fields without the tag are ignored. As in protobuf, zero values of other
+// fields are not written, and unknown fields are skipped when decoding.
+package protobuf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+	"google.golang.org/protobuf/proto"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "protobuf"
+
+// MIMEType is the media type of protobuf.
+const MIMEType = "application/x-protobuf"
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	if m, ok := v.(proto.Message); ok {
+		return proto.MarshalOptions{Deterministic: config.Deterministic}.Marshal(m)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.Type().Elem().Kind() == reflect.Struct {
+		if rv.IsNil() {
+			return []byte{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("protobuf: can not marshal %T, which is neither a proto.Message nor a struct", v)
+	}
+	b, err := (&encoder{}).message([]byte{}, rv)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf: %w", err)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes data into v, which is reset first, as proto.Unmarshal
+// does.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("protobuf: can not unmarshal to non-pointer or nil %T", v)
+	}
+	if m, ok := v.(proto.Message); ok {
+		return proto.UnmarshalOptions{DiscardUnknown: config.DiscardUnknown}.Unmarshal(data, m)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("protobuf: can not unmarshal to %T, which is neither a proto.Message nor a pointer to struct", v)
+	}
+	rv.Set(reflect.Zero(rv.Type()))
+	if err := (&decoder{}).message(data, rv); err != nil {
+		return fmt.Errorf("protobuf: %w", err)
+	}
+	return nil
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,133 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kita/encoding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+func TestCodec_Message(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := &timestamppb.Timestamp{Seconds: 1600000000, Nanos: 5}
+	data, err := m.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := proto.Marshal(in)
+	if !bytes.Equal(data, want) {
+		t.Errorf("marshal got % x, want % x", data, want)
+	}
+	out := &timestamppb.Timestamp{Seconds: 1, Nanos: 2}
+	if err := u.Unmarshal(context.Background(), data, out); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(in, out) {
+		t.Errorf("unmarshal got %v, want %v", out, in)
+	}
+}
+
+type timestamp struct {
+	Seconds int64 `pb:"1"`
+	Nanos   int32 `pb:"2"`
+}
+
+type fieldMask struct {
+	Paths []string `pb:"1"`
+}
+
+// TestCodec_Compatible checks that tagged structs and generated messages of
+// the same schema share the wire format.
+func TestCodec_Compatible(t *testing.T) {
+	tests := []struct {
+		name   string
+		tagged interface{}
+		msg    proto.Message
+	}{
+		{name: "timestamp", tagged: &timestamp{Seconds: -62135596800, Nanos: 999}, msg: &timestamppb.Timestamp{Seconds: -62135596800, Nanos: 999}},
+		{name: "field mask", tagged: &fieldMask{Paths: []string{"a.b", ""}}, msg: &fieldmaskpb.FieldMask{Paths: []string{"a.b", ""}}},
+	}
+	c := &codec{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := c.Marshal(context.Background(), tt.tagged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := tt.msg.ProtoReflect().New().Interface()
+			if err := c.Unmarshal(context.Background(), data, msg); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(msg, tt.msg) {
+				t.Errorf("got message %v, want %v", msg, tt.msg)
+			}
+			if data, err = c.Marshal(context.Background(), tt.msg); err != nil {
+				t.Fatal(err)
+			}
+			tagged := reflect.New(reflect.TypeOf(tt.tagged).Elem()).Interface()
+			if err := c.Unmarshal(context.Background(), data, tagged); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tagged, tt.tagged) {
+				t.Errorf("got struct %+v, want %+v", tagged, tt.tagged)
+			}
+		})
+	}
+}
+
+func TestCodec_Struct(t *testing.T) {
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), point{X: 3, Name: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := point{Y: 7, Ignored: "x"}
+	if err := c.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := (point{X: 3, Name: "p"}); out != want {
+		t.Errorf("got %+v, want %+v, reset before decoding", out, want)
+	}
+	if data, err = c.Marshal(context.Background(), (*point)(nil)); err != nil || len(data) != 0 {
+		t.Errorf("marshal nil pointer got % x, %v", data, err)
+	}
+}
+
+func TestCodec_Error(t *testing.T) {
+	c := &codec{}
+	if _, err := c.Marshal(context.Background(), 1); err == nil {
+		t.Error("marshal non-struct expect error")
+	}
+	if _, err := c.Marshal(context.Background(), struct {
+		A complex64 `pb:"1"`
+	}{}); err == nil {
+		t.Error("marshal unsupported type expect error")
+	}
+	var p point
+	if err := c.Unmarshal(context.Background(), nil, p); err == nil {
+		t.Error("unmarshal to non-pointer expect error")
+	}
+	var n int
+	if err := c.Unmarshal(context.Background(), nil, &n); err == nil {
+		t.Error("unmarshal to non-struct expect error")
+	}
+	if err := c.Unmarshal(context.Background(), []byte{0x80}, &p); err == nil {
+		t.Error("unmarshal truncated data expect error")
+	}
+	if err := c.Unmarshal(context.Background(), []byte{0x80}, &timestamppb.Timestamp{}); err == nil {
+		t.Error("unmarshal truncated message expect error")
+	}
+}
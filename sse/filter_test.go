@@ -0,0 +1,39 @@
+package sse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kita/encoding/xml"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	type item struct {
+		N int
+	}
+	m := WithEncoderOption(&codec{}, EncodeDataWith(xml.Name))
+	got, err := m.Marshal(context.Background(), item{N: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "data: <item><N>1</N></item>\n\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := WithEncoderOption(&codec{}, EncodeDataWith("unknown")).Marshal(context.Background(), 1); err == nil {
+		t.Error("expect error of unknown codec")
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	type item struct {
+		N int
+	}
+	u := WithDecoderOption(&codec{}, DecodeDataWith(xml.Name))
+	var got []item
+	if err := u.Unmarshal(context.Background(), []byte("data: <item><N>1</N></item>\n\ndata: <item><N>2</N></item>\n\n"), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].N != 1 || got[1].N != 2 {
+		t.Errorf("got %+v", got)
+	}
+}
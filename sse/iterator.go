@@ -0,0 +1,220 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+// DefaultMaxLineSize is the default maximum size of a line in bytes.
+const DefaultMaxLineSize = 16 << 20
+
+// Iterator reads events from a stream one at a time, following the parsing
+// rules of browsers: lines end with CRLF, LF or CR, comments and unknown
+// fields are ignored, and the last event id and reconnection time are kept
+// across events.
+//
+//	it := sse.NewIterator(resp.Body)
+//	for it.Next() {
+//		var update Update
+//		if err := it.Decode(&update); err != nil {
+//			return err
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator struct {
+	scanner *bufio.Scanner
+	ctx     context.Context
+	config  *DecoderConfig
+	line    int
+	// first is the line number of the first line of the current event.
+	first   int
+	lastID  string
+	retry   time.Duration
+	current *Event
+	err     error
+}
+
+// NewIterator returns an Iterator reading from r, with lines up to
+// DefaultMaxLineSize bytes.
+func NewIterator(r io.Reader, opt ...DecoderOption) *Iterator {
+	config := &DecoderConfig{}
+	for _, option := range opt {
+		option(config)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, DefaultMaxLineSize)
+	scanner.Split(scanLines)
+	return &Iterator{scanner: scanner, ctx: context.Background(), config: config}
+}
+
+// MaxLineSize sets the maximum size of a line in bytes. It must be called
+// before the first call of Next.
+func (it *Iterator) MaxLineSize(n int) *Iterator {
+	it.scanner.Buffer(nil, n)
+	return it
+}
+
+// scanLines is a bufio.SplitFunc of lines ending with CRLF, LF or CR.
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	i := bytes.IndexAny(data, "\r\n")
+	switch {
+	case i < 0:
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	case data[i] == '\n':
+		return i + 1, data[:i], nil
+	case i+1 < len(data):
+		if data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	case atEOF:
+		return i + 1, data[:i], nil
+	}
+	// A CR at the end may be followed by a LF.
+	return 0, nil, nil
+}
+
+// Next advances to the next event. It returns false when there are no more
+// events or an error occurred, see Err.
+func (it *Iterator) Next() bool {
+	it.current = nil
+	if it.err != nil {
+		return false
+	}
+	var data strings.Builder
+	hasData := false
+	eventType := ""
+	it.first = 0
+	for it.scanner.Scan() {
+		it.line++
+		line := it.scanner.Text()
+		if it.line == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		if line == "" {
+			if hasData {
+				it.current = &Event{
+					ID:    it.lastID,
+					Event: eventType,
+					Retry: it.retry,
+					Data:  strings.TrimSuffix(data.String(), "\n"),
+				}
+				return true
+			}
+			data.Reset()
+			eventType, it.first = "", 0
+			continue
+		}
+		if it.first == 0 {
+			it.first = it.line
+		}
+		if line[0] == ':' {
+			continue
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				it.lastID = value
+			}
+		case "retry":
+			if n, err := strconv.ParseUint(value, 10, 63); err == nil && n <= math.MaxInt64/uint64(time.Millisecond) {
+				it.retry = time.Duration(n) * time.Millisecond
+			}
+		}
+	}
+	if err := it.scanner.Err(); err != nil {
+		it.err = &LineError{Line: it.line + 1, Err: err}
+	}
+	return false
+}
+
+// Line returns the 1-based line number of the first line of the current
+// event.
+func (it *Iterator) Line() int {
+	return it.first
+}
+
+// Event returns the current event, whose Data is a string.
+func (it *Iterator) Event() *Event {
+	return it.current
+}
+
+// Decode decodes the data of the current event into v by the data codec, or
+// sets it as it is into *string or *[]byte. Errors are reported as
+// *LineError.
+func (it *Iterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return errors.New("sse: Decode called without a current event")
+	}
+	if err := decodeData(it.ctx, it.current.Data.(string), v, it.config); err != nil {
+		return &LineError{Line: it.first, Err: err}
+	}
+	return nil
+}
+
+// decode decodes the current event into v, which is either *Event or the
+// data of the event.
+func (it *Iterator) decode(v interface{}) error {
+	event, ok := v.(*Event)
+	if !ok {
+		return it.Decode(v)
+	}
+	data := event.Data
+	*event = *it.current
+	if rv := reflect.ValueOf(data); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		event.Data = data
+		return it.Decode(data)
+	}
+	return nil
+}
+
+// Err returns the first error occurred during reading, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+func decodeData(ctx context.Context, data string, v interface{}, config *DecoderConfig) error {
+	switch vv := v.(type) {
+	case *string:
+		*vv = data
+		return nil
+	case *[]byte:
+		*vv = []byte(data)
+		return nil
+	}
+	name := config.DataCodec
+	if name == "" {
+		name = DefaultDataCodec
+	}
+	u := encoding.GetUnmarshaler(name)
+	if u == nil {
+		return fmt.Errorf("no unmarshaler %q of data", name)
+	}
+	return u.Unmarshal(ctx, []byte(data), v)
+}
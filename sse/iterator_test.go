@@ -0,0 +1,120 @@
+package sse
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding/xml"
+)
+
+func TestIterator(t *testing.T) {
+	stream := "\uFEFF: welcome\r\n" +
+		"retry: 2000\r\n" +
+		"id: 1\r\n" +
+		"event: add\r\n" +
+		"data:first\r\n" +
+		"data\r\n" +
+		"unknown: x\r\n" +
+		"\r\n" +
+		"data: second\r" +
+		"id\r" +
+		"retry: soon\r" +
+		"\r" +
+		"id: 3\n" +
+		"\n" +
+		"data:  third\n" +
+		"id: a\x00b\n" +
+		"\n" +
+		"data: lost\n"
+	it := NewIterator(strings.NewReader(stream))
+	var events []Event
+	var lines []int
+	for it.Next() {
+		events = append(events, *it.Event())
+		lines = append(lines, it.Line())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{
+		{ID: "1", Event: "add", Retry: 2 * time.Second, Data: "first\n"},
+		{Retry: 2 * time.Second, Data: "second"},
+		{ID: "3", Retry: 2 * time.Second, Data: " third"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got %+v, want %+v", events, want)
+	}
+	if want := []int{1, 9, 15}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got lines %v, want %v", lines, want)
+	}
+	if it.Event() != nil {
+		t.Error("expect no current event at the end")
+	}
+	if err := it.Decode(new(string)); err == nil {
+		t.Error("decode after end expect error")
+	}
+}
+
+// oneByteReader returns the content one byte at a time, splitting CRLF.
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (r *oneByteReader) Read(p []byte) (int, error) {
+	return r.r.Read(p[:1])
+}
+
+func TestIterator_SplitCRLF(t *testing.T) {
+	it := NewIterator(&oneByteReader{strings.NewReader("data: a\r\n\r\ndata: b\r\n\r\n")})
+	var data []string
+	for it.Next() {
+		var s string
+		if err := it.Decode(&s); err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, s)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(data, want) {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
+
+func TestIterator_Options(t *testing.T) {
+	it := NewIterator(strings.NewReader("data: <m><A>1</A></m>\n\n"), DecodeDataWith(xml.Name))
+	if !it.Next() {
+		t.Fatal("expect an event")
+	}
+	var m struct{ A int }
+	if err := it.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m.A != 1 {
+		t.Errorf("got %+v", m)
+	}
+	it = NewIterator(strings.NewReader("data: x\n\n"), DecodeDataWith("unknown"))
+	if !it.Next() {
+		t.Fatal("expect an event")
+	}
+	if err := it.Decode(&m); err == nil {
+		t.Error("expect error of unknown codec")
+	}
+}
+
+func TestIterator_TooLong(t *testing.T) {
+	it := NewIterator(strings.NewReader("data: " + strings.Repeat("x", 64) + "\n\n")).MaxLineSize(32)
+	if it.Next() {
+		t.Fatal("expect no event")
+	}
+	var lineErr *LineError
+	if err := it.Err(); !errors.As(err, &lineErr) || !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("got error %v, want ErrTooLong", err)
+	}
+	if it.Next() {
+		t.Error("expect no event after error")
+	}
+}
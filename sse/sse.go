@@ -0,0 +1,171 @@
+// Package sse defines and registers Marshaler/Unmarshaler handling Server-Sent
+// Events (text/event-stream) content, which pushes updates to browsers.
+//
+// An Event is marshaled into a frame of fields, and any other value into a
+// frame of data only. The data of events which is not a string or []byte is
+// encoded by an inner codec, JSON unless set by the EncodeDataWith option, and
+// is split into a "data" field per line. Streams are written frame by frame by
+// Writer, and read event by event by Iterator.
+package sse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/json"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "sse"
+
+// MIMEType is the media type of Server-Sent Events.
+const MIMEType = "text/event-stream"
+
+// DefaultDataCodec is the name of the codec of data by default.
+const DefaultDataCodec = json.Name
+
+// Event is an event of a stream.
+type Event struct {
+	// ID is the event id, which is kept by the client as the last event id.
+	// Events read from a stream carry the last event id of the stream.
+	ID string
+	// Event is the event type, which defaults to "message" on the client.
+	Event string
+	// Retry is the reconnection time, written in milliseconds. Zero means
+	// none. Events read from a stream carry the last reconnection time set.
+	Retry time.Duration
+	// Data is the payload. A string or []byte is written as it is, and any
+	// other non-nil value is encoded by the data codec. Data read from a
+	// stream is a string, unless Data is set to a pointer before unmarshaling,
+	// into which data is decoded.
+	Data interface{}
+}
+
+// LineError is an error of reading or decoding an event.
+type LineError struct {
+	// Line is the 1-based line number of the first line of the event.
+	Line int
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("sse: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	_eventType = reflect.TypeOf(Event{})
+	_bytesType = reflect.TypeOf([]byte(nil))
+)
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshal encodes an Event or a pointer to it into a frame, a slice or an
+// array of Event, or a pointer to it, into frames, and any other value into a frame of data only.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	buf := &bytes.Buffer{}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem() == _eventType {
+		for i := 0; i < rv.Len(); i++ {
+			event := rv.Index(i).Interface().(Event)
+			if err := writeFrame(ctx, buf, &event, config); err != nil {
+				return nil, err
+			}
+		}
+		return buf.Bytes(), nil
+	}
+	if err := writeFrame(ctx, buf, toEvent(v), config); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toEvent returns v if it is an Event, or an event of the data v.
+func toEvent(v interface{}) *Event {
+	switch vv := v.(type) {
+	case *Event:
+		if vv != nil {
+			return vv
+		}
+	case Event:
+		return &vv
+	}
+	return &Event{Data: v}
+}
+
+// Unmarshal decodes events into a pointer to an Event, a slice of Event, a
+// value, or a slice of values. Data of events is decoded into values by the
+// data codec. A single Event or value requires exactly one event. Events not
+// terminated by a blank line are discarded, as clients do.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("sse: can not unmarshal to non-pointer or nil %T", v)
+	}
+	it := NewIterator(bytes.NewReader(data))
+	it.ctx, it.config = ctx, config
+	rv = rv.Elem()
+	if rv.Kind() == reflect.Slice && rv.Type() != _bytesType {
+		items := reflect.MakeSlice(rv.Type(), 0, 0)
+		for it.Next() {
+			item := reflect.New(rv.Type().Elem())
+			if err := it.decode(item.Interface()); err != nil {
+				return err
+			}
+			items = reflect.Append(items, item.Elem())
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+		rv.Set(items)
+		return nil
+	}
+	if !it.Next() {
+		if err := it.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("sse: no event")
+	}
+	if err := it.decode(v); err != nil {
+		return err
+	}
+	if it.Next() {
+		return &LineError{Line: it.Line(), Err: fmt.Errorf("more than one event for %T", v)}
+	}
+	return it.Err()
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
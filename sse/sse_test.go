@@ -0,0 +1,137 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+type update struct {
+	Price float64 `json:"price"`
+	Note  string  `json:"note,omitempty"`
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"event", Event{ID: "1", Event: "tick", Retry: 3 * time.Second, Data: update{Price: 1.5}},
+			"id: 1\nevent: tick\nretry: 3000\ndata: {\"price\":1.5}\n\n"},
+		{"multi-line data", &Event{Data: "a\r\nb\rc\nd"}, "data: a\ndata: b\ndata: c\ndata: d\n\n"},
+		{"empty data", Event{Data: []byte{}}, "data: \n\n"},
+		{"no data", Event{ID: "7"}, "id: 7\n\n"},
+		{"value", update{Price: 2, Note: "<up>"}, "data: {\"price\":2,\"note\":\"\\u003cup\\u003e\"}\n\n"},
+		{"events", &[]Event{{Data: "x"}, {Event: "end", Data: 1}}, "data: x\n\nevent: end\ndata: 1\n\n"},
+		{"slice value", []int{1, 2}, "data: [1,2]\n\n"},
+	}
+	c := &codec{}
+	for _, test := range tests {
+		got, err := c.Marshal(context.Background(), test.v)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+	for _, v := range []interface{}{Event{ID: "a\nb"}, Event{Event: "a\rb"}, Event{ID: "a\x00"}, make(chan int)} {
+		if _, err := c.Marshal(context.Background(), v); err == nil {
+			t.Errorf("%v: expect error", v)
+		}
+	}
+}
+
+func TestCodec_Unmarshal(t *testing.T) {
+	c := &codec{}
+	ctx := context.Background()
+	data := []byte("id: 1\nevent: tick\ndata: {\"price\":1.5}\n\ndata: {\"price\":2}\n\n")
+	var events []Event
+	if err := c.Unmarshal(ctx, data, &events); err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{{ID: "1", Event: "tick", Data: `{"price":1.5}`}, {ID: "1", Data: `{"price":2}`}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got %+v, want %+v", events, want)
+	}
+	var updates []update
+	if err := c.Unmarshal(ctx, data, &updates); err != nil {
+		t.Fatal(err)
+	}
+	if want := []update{{Price: 1.5}, {Price: 2}}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("got %+v, want %+v", updates, want)
+	}
+	var u update
+	event := Event{Data: &u}
+	if err := c.Unmarshal(ctx, []byte("event: tick\ndata: {\"price\":3}\n\n"), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Event != "tick" || event.Data != &u || u.Price != 3 {
+		t.Errorf("got %+v, %+v", event, u)
+	}
+	var s string
+	if err := c.Unmarshal(ctx, []byte("data: a\ndata: b\n\n"), &s); err != nil || s != "a\nb" {
+		t.Errorf("got %q, %v", s, err)
+	}
+}
+
+func TestCodec_UnmarshalErrors(t *testing.T) {
+	c := &codec{}
+	ctx := context.Background()
+	var u update
+	tests := []struct {
+		name string
+		data string
+		v    interface{}
+	}{
+		{"nil", "data: 1\n\n", nil},
+		{"non-pointer", "data: 1\n\n", u},
+		{"no event", "data: 1\n", &u},
+		{"more than one event", "data: {}\n\ndata: {}\n\n", &u},
+		{"invalid data", "\n\ndata: {\n\n", &u},
+		{"invalid data of slice", "data: {}\n\ndata: x\n\n", &[]update{}},
+	}
+	for _, test := range tests {
+		if err := c.Unmarshal(ctx, []byte(test.data), test.v); err == nil {
+			t.Errorf("%s: expect error", test.name)
+		}
+	}
+	var lineErr *LineError
+	if err := c.Unmarshal(ctx, []byte("\n\ndata: {\n\n"), &u); !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Errorf("got error %v, want LineError at line 3", err)
+	}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	in := []Event{
+		{ID: "42", Event: "update", Retry: 1500 * time.Millisecond, Data: "line 1\nline 2"},
+		{Data: ""},
+	}
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []Event
+	if err := c.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{in[0], {ID: "42", Retry: 1500 * time.Millisecond, Data: ""}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("got %+v, want %+v", out, want)
+	}
+}
@@ -0,0 +1,138 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+// lineBreaks are the line endings of event streams.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// writeFrame writes the fields of an event followed by a blank line.
+func writeFrame(ctx context.Context, buf *bytes.Buffer, event *Event, config *EncoderConfig) error {
+	if strings.ContainsAny(event.ID, "\r\n\x00") {
+		return fmt.Errorf("sse: invalid event id %q", event.ID)
+	}
+	if strings.ContainsAny(event.Event, "\r\n") {
+		return fmt.Errorf("sse: invalid event type %q", event.Event)
+	}
+	if event.ID != "" {
+		buf.WriteString("id: ")
+		buf.WriteString(event.ID)
+		buf.WriteByte('\n')
+	}
+	if event.Event != "" {
+		buf.WriteString("event: ")
+		buf.WriteString(event.Event)
+		buf.WriteByte('\n')
+	}
+	if event.Retry > 0 {
+		buf.WriteString("retry: ")
+		buf.WriteString(strconv.FormatInt(int64(event.Retry/time.Millisecond), 10))
+		buf.WriteByte('\n')
+	}
+	if event.Data != nil {
+		data, err := encodeData(ctx, event.Data, config)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(lineBreaks.Replace(data), "\n") {
+			buf.WriteString("data: ")
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+// encodeData returns the text of data, encoded by the data codec unless it is
+// a string or []byte. A trailing line break of encoded data is dropped.
+func encodeData(ctx context.Context, data interface{}, config *EncoderConfig) (string, error) {
+	switch vv := data.(type) {
+	case string:
+		return vv, nil
+	case []byte:
+		return string(vv), nil
+	}
+	name := config.DataCodec
+	if name == "" {
+		name = DefaultDataCodec
+	}
+	m := encoding.GetMarshaler(name)
+	if m == nil {
+		return "", fmt.Errorf("sse: no marshaler %q of data", name)
+	}
+	text, err := m.Marshal(ctx, data)
+	if err != nil {
+		return "", fmt.Errorf("sse: %w", err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(text), "\n"), "\r"), nil
+}
+
+// Writer writes events to a stream frame by frame, flushing each frame if the
+// underlying io.Writer supports it, such as http.ResponseWriter.
+//
+//	w.Header().Set("Content-Type", sse.MIMEType)
+//	writer := sse.NewWriter(w)
+//	for update := range updates {
+//		if err := writer.Write(&sse.Event{Event: "update", Data: update}); err != nil {
+//			return err
+//		}
+//	}
+type Writer struct {
+	w      io.Writer
+	config *EncoderConfig
+	buf    bytes.Buffer
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, opt ...EncoderOption) *Writer {
+	config := &EncoderConfig{}
+	for _, option := range opt {
+		option(config)
+	}
+	return &Writer{w: w, config: config}
+}
+
+// Write writes an Event, or a frame of data only of any other value.
+func (w *Writer) Write(v interface{}) error {
+	w.buf.Reset()
+	if err := writeFrame(context.Background(), &w.buf, toEvent(v), w.config); err != nil {
+		return err
+	}
+	return w.write()
+}
+
+// Comment writes a comment, which is ignored by clients, such as a heartbeat
+// keeping the connection alive.
+func (w *Writer) Comment(text string) error {
+	w.buf.Reset()
+	for _, line := range strings.Split(lineBreaks.Replace(text), "\n") {
+		w.buf.WriteString(": ")
+		w.buf.WriteString(line)
+		w.buf.WriteByte('\n')
+	}
+	w.buf.WriteByte('\n')
+	return w.write()
+}
+
+func (w *Writer) write() error {
+	if _, err := w.w.Write(w.buf.Bytes()); err != nil {
+		return err
+	}
+	switch f := w.w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// flushRecorder records frames flushed, like http.ResponseWriter.
+type flushRecorder struct {
+	bytes.Buffer
+	flushed []string
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushed = append(r.flushed, r.String())
+}
+
+func TestWriter(t *testing.T) {
+	r := &flushRecorder{}
+	w := NewWriter(r)
+	if err := w.Write(&Event{Event: "add", Data: map[string]int{"n": 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Comment("ping\nping"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("bye"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"event: add\ndata: {\"n\":1}\n\n",
+		"event: add\ndata: {\"n\":1}\n\n: ping\n: ping\n\n",
+		"event: add\ndata: {\"n\":1}\n\n: ping\n: ping\n\ndata: bye\n\n",
+	}
+	if len(r.flushed) != len(want) {
+		t.Fatalf("got %d flushes, want %d", len(r.flushed), len(want))
+	}
+	for i := range want {
+		if r.flushed[i] != want[i] {
+			t.Errorf("flush %d: got %q, want %q", i, r.flushed[i], want[i])
+		}
+	}
+	if err := w.Write(Event{ID: "\n"}); err == nil {
+		t.Error("expect error of invalid id")
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("closed")
+}
+
+func TestWriter_Errors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	bw := bufio.NewWriterSize(failWriter{}, 16)
+	if err := NewWriter(bw).Write("0123456789"); err == nil {
+		t.Error("expect error of flushing")
+	}
+	if err := NewWriter(failWriter{}).Write("x"); err == nil {
+		t.Error("expect error of writing")
+	}
+	if err := NewWriter(buf).Write(func() {}); err == nil {
+		t.Error("expect error of encoding data")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("got %q written, want nothing", buf)
+	}
+}
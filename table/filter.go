@@ -0,0 +1,87 @@
+package table
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// KeyHeader is the header of the key column of key/value tables,
+	// DefaultKeyHeader if empty.
+	KeyHeader string
+	// ValueHeader is the header of the value column of key/value tables,
+	// DefaultValueHeader if empty.
+	ValueHeader string
+	// Compact renders Markdown tables without padding columns to the same
+	// width.
+	Compact bool
+	// Class is the class attribute of HTML tables.
+	Class string
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// KeyValueHeaders produces an EncoderOption which sets the headers of
+// key/value tables.
+func KeyValueHeaders(key, value string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.KeyHeader = key
+		config.ValueHeader = value
+	}
+}
+
+// Compact produces an EncoderOption which renders Markdown tables without
+// padding.
+func Compact() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Compact = true
+	}
+}
+
+// Class produces an EncoderOption which sets the class attribute of HTML
+// tables.
+func Class(class string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Class = class
+	}
+}
@@ -0,0 +1,26 @@
+package table
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	v := map[string]int{"cpu": 2}
+	m := WithEncoderOption(&codec{render: renderMarkdown}, KeyValueHeaders("Metric", "Count"), Compact())
+	got, err := m.Marshal(context.Background(), v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "| Metric | Count |\n| --- | --: |\n| cpu | 2 |\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	m = WithEncoderOption(&codec{render: renderHTML}, Class("metrics"))
+	if got, err = m.Marshal(context.Background(), v); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), `<table class="metrics">`) {
+		t.Errorf("got %q, want class metrics", got)
+	}
+}
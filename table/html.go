@@ -0,0 +1,54 @@
+package table
+
+import (
+	"bytes"
+	"html"
+	"strings"
+)
+
+// renderHTML renders t as an HTML table, escaping all text. Line breaks of
+// cells are rendered as <br>.
+func renderHTML(t *table, config *EncoderConfig) []byte {
+	if len(t.header) == 0 {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("<table")
+	if config.Class != "" {
+		buf.WriteString(` class="`)
+		buf.WriteString(html.EscapeString(config.Class))
+		buf.WriteByte('"')
+	}
+	buf.WriteString(">\n<thead>\n<tr>")
+	for i, name := range t.header {
+		writeHTMLCell(buf, "th", name, t.aligns[i])
+	}
+	buf.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range t.rows {
+		buf.WriteString("<tr>")
+		for i, c := range row {
+			writeHTMLCell(buf, "td", c.text, t.aligns[i])
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("</tbody>\n</table>\n")
+	return buf.Bytes()
+}
+
+var lineBreaker = strings.NewReplacer("\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func writeHTMLCell(buf *bytes.Buffer, tag string, text string, align alignment) {
+	buf.WriteByte('<')
+	buf.WriteString(tag)
+	switch align {
+	case alignRight:
+		buf.WriteString(` style="text-align: right"`)
+	case alignCenter:
+		buf.WriteString(` style="text-align: center"`)
+	}
+	buf.WriteByte('>')
+	buf.WriteString(lineBreaker.Replace(html.EscapeString(text)))
+	buf.WriteString("</")
+	buf.WriteString(tag)
+	buf.WriteByte('>')
+}
@@ -0,0 +1,28 @@
+package table
+
+import (
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	tb := &table{
+		header: []string{"a&b", "ok"},
+		rows: [][]cell{
+			{{text: `<script>"x"</script>`}, {text: "true", align: alignCenter}},
+			{{text: "line 1\r\nline 2"}, {}},
+		},
+		aligns: []alignment{alignLeft, alignCenter},
+	}
+	want := "<table class=\"report &#34;x&#34;\">\n<thead>\n" +
+		`<tr><th>a&amp;b</th><th style="text-align: center">ok</th></tr>` + "\n" +
+		"</thead>\n<tbody>\n" +
+		`<tr><td>&lt;script&gt;&#34;x&#34;&lt;/script&gt;</td><td style="text-align: center">true</td></tr>` + "\n" +
+		`<tr><td>line 1<br>line 2</td><td style="text-align: center"></td></tr>` + "\n" +
+		"</tbody>\n</table>\n"
+	if got := renderHTML(tb, &EncoderConfig{Class: `report "x"`}); string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := renderHTML(&table{}, &EncoderConfig{}); len(got) != 0 {
+		t.Errorf("got %q, want empty", got)
+	}
+}
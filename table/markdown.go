@@ -0,0 +1,107 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+// markdownEscaper escapes pipes which delimit cells, and line breaks which
+// end rows.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// renderMarkdown renders t as a GitHub Flavored Markdown table. Columns are
+// padded to the same width unless config.Compact is set.
+func renderMarkdown(t *table, config *EncoderConfig) []byte {
+	if len(t.header) == 0 {
+		return nil
+	}
+	header := make([]string, len(t.header))
+	for i, name := range t.header {
+		header[i] = markdownEscaper.Replace(name)
+	}
+	rows := make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = make([]string, len(row))
+		for j, c := range row {
+			rows[i][j] = markdownEscaper.Replace(c.text)
+		}
+	}
+	widths := make([]int, len(header))
+	for i := range widths {
+		widths[i] = 3
+		if config.Compact {
+			continue
+		}
+		if w := displayWidth(header[i]); w > widths[i] {
+			widths[i] = w
+		}
+		for _, row := range rows {
+			if w := displayWidth(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	writeRow := func(row []string) {
+		for i, text := range row {
+			buf.WriteString("| ")
+			if config.Compact {
+				buf.WriteString(text)
+			} else {
+				writePadded(buf, text, widths[i], t.aligns[i])
+			}
+			buf.WriteByte(' ')
+		}
+		buf.WriteString("|\n")
+	}
+	writeRow(header)
+	for i, align := range t.aligns {
+		buf.WriteString("| ")
+		switch align {
+		case alignRight:
+			buf.WriteString(strings.Repeat("-", widths[i]-1) + ":")
+		case alignCenter:
+			buf.WriteString(":" + strings.Repeat("-", widths[i]-2) + ":")
+		default:
+			buf.WriteString(strings.Repeat("-", widths[i]))
+		}
+		buf.WriteByte(' ')
+	}
+	buf.WriteString("|\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return buf.Bytes()
+}
+
+func writePadded(buf *bytes.Buffer, text string, w int, align alignment) {
+	pad := w - displayWidth(text)
+	left := 0
+	switch align {
+	case alignRight:
+		left = pad
+	case alignCenter:
+		left = pad / 2
+	}
+	buf.WriteString(strings.Repeat(" ", left))
+	buf.WriteString(text)
+	buf.WriteString(strings.Repeat(" ", pad-left))
+}
+
+// displayWidth returns the width of s in a monospaced font, in which East
+// Asian wide and fullwidth characters take two columns.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
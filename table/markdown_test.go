@@ -0,0 +1,61 @@
+package table
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tb := &table{
+		header: []string{"名称", "a|b", "n"},
+		rows: [][]cell{
+			{{text: "中文"}, {text: "x\ny"}, {text: "1", align: alignRight}},
+			{{text: "abcdef"}, {}, {text: "100", align: alignRight}},
+		},
+		aligns: []alignment{alignLeft, alignLeft, alignRight},
+	}
+	tests := []struct {
+		config EncoderConfig
+		want   string
+	}{
+		{EncoderConfig{}, "" +
+			"| 名称   | a\\|b   |   n |\n" +
+			"| ------ | ------ | --: |\n" +
+			"| 中文   | x<br>y |   1 |\n" +
+			"| abcdef |        | 100 |\n"},
+		{EncoderConfig{Compact: true}, "" +
+			"| 名称 | a\\|b | n |\n" +
+			"| --- | --- | --: |\n" +
+			"| 中文 | x<br>y | 1 |\n" +
+			"| abcdef |  | 100 |\n"},
+	}
+	for _, test := range tests {
+		if got := renderMarkdown(tb, &test.config); string(got) != test.want {
+			t.Errorf("got\n%s\nwant\n%s", got, test.want)
+		}
+	}
+	if got := renderMarkdown(&table{}, &EncoderConfig{}); len(got) != 0 {
+		t.Errorf("got %q, want empty", got)
+	}
+}
+
+func TestWritePadded(t *testing.T) {
+	tests := []struct {
+		align alignment
+		want  string
+	}{
+		{alignLeft, "ab   "},
+		{alignRight, "   ab"},
+		{alignCenter, " ab  "},
+	}
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		writePadded(buf, "ab", 5, test.align)
+		if got := buf.String(); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.align, got, test.want)
+		}
+	}
+	if got := displayWidth("a中ｂ"); got != 5 {
+		t.Errorf("got width %d, want 5", got)
+	}
+}
@@ -0,0 +1,235 @@
+package table
+
+import (
+	"bytes"
+	se "encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+var _tags = []string{"table", "csv", "json"}
+
+var _textMarshalerType = reflect.TypeOf((*se.TextMarshaler)(nil)).Elem()
+
+// alignment is the alignment of a column.
+type alignment int
+
+const (
+	alignLeft alignment = iota
+	alignRight
+	alignCenter
+)
+
+// cell is a rendered value of a table.
+type cell struct {
+	text string
+	// align is the alignment following the kind of the value.
+	align alignment
+}
+
+// table is the header and rows of values to render.
+type table struct {
+	header []string
+	rows   [][]cell
+	// aligns are the alignments of columns: the alignment shared by all
+	// non-empty cells of a column, alignLeft if they differ.
+	aligns []alignment
+}
+
+// build converts v into a table: a slice or an array of structs, pointers to
+// structs or maps with string keys into rows, and a single struct or map into
+// a table of key/value rows.
+func build(v interface{}, config *EncoderConfig) (*table, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	var t *table
+	var err error
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elemType := rv.Type().Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		switch {
+		case elemType.Kind() == reflect.Struct:
+			t, err = structRows(rv, elemType)
+		case elemType.Kind() == reflect.Map && elemType.Key().Kind() == reflect.String:
+			t, err = mapRows(rv)
+		default:
+			return nil, fmt.Errorf("table: unsupported type %T", v)
+		}
+	case reflect.Struct, reflect.Map:
+		if rv.Kind() == reflect.Map && rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("table: unsupported type %T", v)
+		}
+		t, err = keyValueRows(rv, config)
+	default:
+		return nil, fmt.Errorf("table: unsupported type %T", v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.align()
+	return t, nil
+}
+
+func structRows(rv reflect.Value, elemType reflect.Type) (*table, error) {
+	fs := fields.Of(elemType, _tags...)
+	t := &table{header: make([]string, len(fs)), rows: make([][]cell, rv.Len())}
+	for i, f := range fs {
+		t.header[i] = f.Name
+	}
+	for i := range t.rows {
+		row := make([]cell, len(fs))
+		item := rv.Index(i)
+		for item.Kind() == reflect.Ptr && !item.IsNil() {
+			item = item.Elem()
+		}
+		if item.Kind() == reflect.Struct {
+			for j, f := range fs {
+				fv, ok := fields.ByIndex(item, f.Index, false)
+				if !ok {
+					continue
+				}
+				c, err := newCell(fv)
+				if err != nil {
+					return nil, fmt.Errorf("table: row %d, field %s: %w", i+1, f.Name, err)
+				}
+				row[j] = c
+			}
+		}
+		t.rows[i] = row
+	}
+	return t, nil
+}
+
+func mapRows(rv reflect.Value) (*table, error) {
+	seen := map[string]bool{}
+	t := &table{rows: make([][]cell, rv.Len())}
+	for i := 0; i < rv.Len(); i++ {
+		for _, key := range reflect.Indirect(rv.Index(i)).MapKeys() {
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				t.header = append(t.header, key.String())
+			}
+		}
+	}
+	sort.Strings(t.header)
+	for i := range t.rows {
+		m := reflect.Indirect(rv.Index(i))
+		row := make([]cell, len(t.header))
+		for j, key := range t.header {
+			if !m.IsValid() {
+				break
+			}
+			c, err := newCell(m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key())))
+			if err != nil {
+				return nil, fmt.Errorf("table: row %d, key %s: %w", i+1, key, err)
+			}
+			row[j] = c
+		}
+		t.rows[i] = row
+	}
+	return t, nil
+}
+
+func keyValueRows(rv reflect.Value, config *EncoderConfig) (*table, error) {
+	t := &table{header: []string{config.KeyHeader, config.ValueHeader}}
+	if t.header[0] == "" {
+		t.header[0] = DefaultKeyHeader
+	}
+	if t.header[1] == "" {
+		t.header[1] = DefaultValueHeader
+	}
+	if rv.Kind() == reflect.Struct {
+		for _, f := range fields.Of(rv.Type(), _tags...) {
+			fv, _ := fields.ByIndex(rv, f.Index, false)
+			c, err := newCell(fv)
+			if err != nil {
+				return nil, fmt.Errorf("table: field %s: %w", f.Name, err)
+			}
+			t.rows = append(t.rows, []cell{{text: f.Name}, c})
+		}
+		return t, nil
+	}
+	keys := make([]string, 0, rv.Len())
+	for _, key := range rv.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		c, err := newCell(rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())))
+		if err != nil {
+			return nil, fmt.Errorf("table: key %s: %w", key, err)
+		}
+		t.rows = append(t.rows, []cell{{text: key}, c})
+	}
+	return t, nil
+}
+
+// newCell renders a value by its textual form, or as compact JSON if it is not
+// a scalar, such as a nested slice or map. Numbers are aligned to the right
+// and booleans to the center.
+func newCell(v reflect.Value) (cell, error) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return cell{}, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return cell{}, nil
+	}
+	if !textual.IsScalar(v.Type()) {
+		buf := &bytes.Buffer{}
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v.Interface()); err != nil {
+			return cell{}, err
+		}
+		return cell{text: strings.TrimSuffix(buf.String(), "\n")}, nil
+	}
+	text, err := textual.Marshal(v)
+	if err != nil {
+		return cell{}, err
+	}
+	c := cell{text: text}
+	if v.Type().Implements(_textMarshalerType) {
+		return c, nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		c.align = alignRight
+	case reflect.Bool:
+		c.align = alignCenter
+	}
+	return c, nil
+}
+
+func (t *table) align() {
+	t.aligns = make([]alignment, len(t.header))
+	for i := range t.header {
+		set := false
+		for _, row := range t.rows {
+			if row[i].text == "" {
+				continue
+			}
+			if !set {
+				t.aligns[i], set = row[i].align, true
+			} else if t.aligns[i] != row[i].align {
+				t.aligns[i] = alignLeft
+				break
+			}
+		}
+	}
+}
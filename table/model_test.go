@@ -0,0 +1,100 @@
+package table
+
+import (
+	"reflect"
+	"testing"
+)
+
+func texts(t *table) [][]string {
+	rows := make([][]string, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = make([]string, len(row))
+		for j, c := range row {
+			rows[i][j] = c.text
+		}
+	}
+	return rows
+}
+
+func TestBuild(t *testing.T) {
+	type inner struct {
+		Tags []string          `json:"tags"`
+		Meta map[string]string `json:"meta"`
+	}
+	type outer struct {
+		ID    uint   `table:"ID"`
+		Label string `csv:"label"`
+		inner
+		Ratio *float32
+	}
+	ratio := float32(0.5)
+	tests := []struct {
+		name   string
+		v      interface{}
+		header []string
+		rows   [][]string
+		aligns []alignment
+	}{
+		{
+			"structs",
+			[]*outer{{ID: 1, Label: "a", inner: inner{Tags: []string{"<x>"}, Meta: map[string]string{"k": "v"}}, Ratio: &ratio}, nil},
+			[]string{"ID", "label", "tags", "meta", "Ratio"},
+			[][]string{{"1", "a", `["<x>"]`, `{"k":"v"}`, "0.5"}, {"", "", "", "", ""}},
+			[]alignment{alignRight, alignLeft, alignLeft, alignLeft, alignRight},
+		},
+		{
+			"maps",
+			&[]map[string]interface{}{{"b": 1, "a": true}, {"b": "x", "c": nil}},
+			[]string{"a", "b", "c"},
+			[][]string{{"true", "1", ""}, {"", "x", ""}},
+			[]alignment{alignCenter, alignLeft, alignLeft},
+		},
+		{
+			"struct",
+			outer{ID: 2, Label: "b"},
+			[]string{DefaultKeyHeader, DefaultValueHeader},
+			[][]string{{"ID", "2"}, {"label", "b"}, {"tags", "null"}, {"meta", "null"}, {"Ratio", ""}},
+			[]alignment{alignLeft, alignLeft},
+		},
+		{
+			"map",
+			map[string]int{"y": 2, "x": 1},
+			[]string{DefaultKeyHeader, DefaultValueHeader},
+			[][]string{{"x", "1"}, {"y", "2"}},
+			[]alignment{alignLeft, alignRight},
+		},
+		{
+			"empty",
+			[]map[string]string{},
+			nil,
+			[][]string{},
+			[]alignment{},
+		},
+	}
+	for _, test := range tests {
+		got, err := build(test.v, &EncoderConfig{})
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.header, test.header) {
+			t.Errorf("%s: got header %q, want %q", test.name, got.header, test.header)
+		}
+		if rows := texts(got); !reflect.DeepEqual(rows, test.rows) {
+			t.Errorf("%s: got rows %q, want %q", test.name, rows, test.rows)
+		}
+		if !reflect.DeepEqual(got.aligns, test.aligns) {
+			t.Errorf("%s: got aligns %v, want %v", test.name, got.aligns, test.aligns)
+		}
+	}
+}
+
+func TestBuild_KeyValueHeaders(t *testing.T) {
+	got, err := build(map[string]string{"k": "v"}, &EncoderConfig{KeyHeader: "Name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Name", DefaultValueHeader}; !reflect.DeepEqual(got.header, want) {
+		t.Errorf("got %q, want %q", got.header, want)
+	}
+}
@@ -0,0 +1,69 @@
+// Package table defines and registers marshal-only Marshalers rendering
+// tabular data as Markdown or HTML tables, for reports of chat-ops bots and
+// emails. There are no Unmarshalers, as rendered tables are not meant to be
+// parsed.
+//
+// A slice of structs, pointers to structs or maps with string keys is rendered
+// into a table with a header row, taken in order from the "table" tags of the
+// fields, or the "csv" or "json" tags, or the sorted keys of maps. A single
+// struct or map is rendered into a table of key/value rows. Values are
+// rendered by their textual form, and values which are not scalars, such as
+// nested slices, as compact JSON. Columns of numbers are aligned to the right
+// and columns of booleans to the center.
+package table
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	RegisterMarkdown(MarkdownName)
+	RegisterHTML(HTMLName)
+}
+
+// MarkdownName is type name of Markdown tables.
+const MarkdownName = "markdown"
+
+// HTMLName is type name of HTML tables.
+const HTMLName = "html-table"
+
+// Default headers of key/value tables.
+const (
+	DefaultKeyHeader   = "Field"
+	DefaultValueHeader = "Value"
+)
+
+var _ encoding.Marshaler = (*codec)(nil)
+
+type codec struct {
+	render func(t *table, config *EncoderConfig) []byte
+}
+
+// Marshal renders v into a table.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	t, err := build(v, config)
+	if err != nil {
+		return nil, err
+	}
+	return c.render(t, config), nil
+}
+
+// RegisterMarkdown register marshaler of Markdown tables.
+func RegisterMarkdown(name string) {
+	register(name, renderMarkdown)
+}
+
+// RegisterHTML register marshaler of HTML tables.
+func RegisterHTML(name string) {
+	register(name, renderHTML)
+}
+
+func register(name string, render func(t *table, config *EncoderConfig) []byte) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{render: render} })
+}
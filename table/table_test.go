@@ -0,0 +1,76 @@
+package table
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{MarkdownName, HTMLName} {
+		if encoding.GetMarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+		if encoding.GetUnmarshaler(name) != nil {
+			t.Errorf("unexpected unmarshaler registered as %q", name)
+		}
+	}
+}
+
+type service struct {
+	Name    string    `table:"Service"`
+	Healthy bool      `table:"Healthy"`
+	Latency float64   `json:"latency_ms"`
+	Since   time.Time `table:"Since"`
+	Secret  string    `table:"-"`
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	in := []service{
+		{Name: "api", Healthy: true, Latency: 12.5, Since: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), Secret: "x"},
+		{Name: "<db>", Latency: 120},
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{MarkdownName, "" +
+			"| Service | Healthy | latency_ms | Since                |\n" +
+			"| ------- | :-----: | ---------: | -------------------- |\n" +
+			"| api     |  true   |       12.5 | 2021-06-01T00:00:00Z |\n" +
+			"| <db>    |  false  |        120 | 0001-01-01T00:00:00Z |\n"},
+		{HTMLName, "" +
+			"<table>\n<thead>\n" +
+			`<tr><th>Service</th><th style="text-align: center">Healthy</th><th style="text-align: right">latency_ms</th><th>Since</th></tr>` + "\n" +
+			"</thead>\n<tbody>\n" +
+			`<tr><td>api</td><td style="text-align: center">true</td><td style="text-align: right">12.5</td><td>2021-06-01T00:00:00Z</td></tr>` + "\n" +
+			`<tr><td>&lt;db&gt;</td><td style="text-align: center">false</td><td style="text-align: right">120</td><td>0001-01-01T00:00:00Z</td></tr>` + "\n" +
+			"</tbody>\n</table>\n"},
+	}
+	for _, test := range tests {
+		got, err := encoding.GetMarshaler(test.name).Marshal(context.Background(), in)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	for _, name := range []string{MarkdownName, HTMLName} {
+		m := encoding.GetMarshaler(name)
+		for _, v := range []interface{}{nil, 1, "s", []int{1}, map[int]string{}, []func(){}} {
+			if _, err := m.Marshal(context.Background(), v); err == nil {
+				t.Errorf("%s, %T: expect error", name, v)
+			}
+		}
+		if _, err := m.Marshal(context.Background(), []map[string]interface{}{{"f": func() {}}}); err == nil {
+			t.Errorf("%s: expect error of unsupported value", name)
+		}
+	}
+}
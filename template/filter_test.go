@@ -0,0 +1,19 @@
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(encoding.GetMarshaler("text-test"), Named("email"))
+	got, err := m.Marshal(context.Background(), welcome{User: "Li"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Dear Li, reset your password."; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
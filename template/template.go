@@ -0,0 +1,122 @@
+// Package template defines Marshalers rendering values into text bodies, such
+// as emails, SMS and configuration files, by text/template or html/template.
+// There are no Unmarshalers.
+//
+// Codecs are registered together with their template sets by Register and
+// RegisterHTML, usually under Name and HTMLName. The template a value is
+// rendered by is chosen in order: the name set by the Named option in the
+// context, the name returned by TemplateName if the value implements Namer,
+// the name of the Go type of the value if the set defines it, and at last the
+// set itself. The html variant escapes values by their contexts in HTML.
+//
+// The rendered text is UTF-8, and can be re-encoded by charset filters, such
+// as encoding.EncodeWithCharset("GBK") for SMS bodies.
+package template
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"reflect"
+	"text/template"
+
+	"github.com/go-kita/encoding"
+)
+
+// Name is type name of the text variant.
+const Name = "template"
+
+// HTMLName is type name of the html variant.
+const HTMLName = "html-template"
+
+// Namer is implemented by values which choose the template they are rendered
+// by.
+type Namer interface {
+	// TemplateName returns the name of a template of the set.
+	TemplateName() string
+}
+
+var _ encoding.Marshaler = (*codec)(nil)
+
+type codec struct {
+	// root is the name of the set itself.
+	root    string
+	defined func(name string) bool
+	execute func(w io.Writer, name string, data interface{}) error
+}
+
+// Marshal renders v by the template chosen by the Named option, Namer, or the
+// type of v.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	name, err := c.choose(v, config)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := c.execute(buf, name, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *codec) choose(v interface{}, config *EncoderConfig) (string, error) {
+	if config.Name != "" {
+		if !c.defined(config.Name) {
+			return "", fmt.Errorf("template: no template %q", config.Name)
+		}
+		return config.Name, nil
+	}
+	if namer, ok := v.(Namer); ok {
+		name := namer.TemplateName()
+		if !c.defined(name) {
+			return "", fmt.Errorf("template: no template %q for %T", name, v)
+		}
+		return name, nil
+	}
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Name() != "" && c.defined(t.Name()) {
+		return t.Name(), nil
+	}
+	if c.defined(c.root) {
+		return c.root, nil
+	}
+	return "", fmt.Errorf("template: no template for %T", v)
+}
+
+// Register register marshaler rendering values by the text templates of t.
+func Register(name string, t *template.Template) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler {
+		return &codec{
+			root: t.Name(),
+			defined: func(name string) bool {
+				found := t.Lookup(name)
+				return found != nil && found.Tree != nil
+			},
+			execute: t.ExecuteTemplate,
+		}
+	})
+}
+
+// RegisterHTML register marshaler rendering values by the HTML templates of t,
+// which escape values by their contexts.
+func RegisterHTML(name string, t *htmltemplate.Template) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler {
+		return &codec{
+			root: t.Name(),
+			defined: func(name string) bool {
+				found := t.Lookup(name)
+				return found != nil && found.Tree != nil
+			},
+			execute: t.ExecuteTemplate,
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package template
+
+import (
+	"context"
+	htmltemplate "html/template"
+	"testing"
+	"text/template"
+
+	"github.com/go-kita/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type welcome struct {
+	User string
+}
+
+type reset struct {
+	User string
+	Kind string
+}
+
+func (r reset) TemplateName() string {
+	return r.Kind
+}
+
+var _texts = template.Must(template.New("default").Parse(`Hi {{.}}`))
+
+func init() {
+	template.Must(_texts.New("welcome").Parse(`Welcome, {{.User}}!`))
+	template.Must(_texts.New("sms").Parse(`【通知】{{.User}}，您的验证码已发送。`))
+	template.Must(_texts.New("email").Parse(`Dear {{.User}}, reset your password.`))
+	template.Must(_texts.New("fail").Parse(`{{.Missing}}`))
+	Register("text-test", _texts)
+	RegisterHTML("html-test", htmltemplate.Must(htmltemplate.New("page").Parse(
+		`<p title="{{.User}}">{{.User}}</p><a href="/u?name={{.User}}">x</a>`)))
+}
+
+func TestCodec_Marshal(t *testing.T) {
+	m := encoding.GetMarshaler("text-test")
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"type name", welcome{User: "Li"}, "Welcome, Li!"},
+		{"pointer type name", &welcome{User: "Li"}, "Welcome, Li!"},
+		{"namer", reset{User: "Han", Kind: "email"}, "Dear Han, reset your password."},
+		{"root", "Mei", "Hi Mei"},
+		{"nil", nil, "Hi <no value>"},
+	}
+	for _, test := range tests {
+		got, err := m.Marshal(context.Background(), test.v)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCodec_HTML(t *testing.T) {
+	got, err := encoding.GetMarshaler("html-test").Marshal(context.Background(), welcome{User: `<b>"A&B"</b>`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<p title="&lt;b&gt;&#34;A&amp;B&#34;&lt;/b&gt;">&lt;b&gt;&#34;A&amp;B&#34;&lt;/b&gt;</p>` +
+		`<a href="/u?name=%3cb%3e%22A%26B%22%3c%2fb%3e">x</a>`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestCodec_Charset(t *testing.T) {
+	m := WithEncoderOption(encoding.GetMarshaler("text-test"), Named("sms"))
+	m = encoding.FilterMarshaler(m, encoding.EncodeWithCharset("GBK"))
+	got, err := m.Marshal(context.Background(), welcome{User: "李雷"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := simplifiedchinese.GBK.NewEncoder().String("【通知】李雷，您的验证码已发送。")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	ctx := context.Background()
+	m := encoding.GetMarshaler("text-test")
+	if _, err := m.Marshal(ctx, reset{Kind: "unknown"}); err == nil {
+		t.Error("expect error of unknown template of Namer")
+	}
+	if _, err := WithEncoderOption(m, Named("unknown")).Marshal(ctx, 1); err == nil {
+		t.Error("expect error of unknown template")
+	}
+	if _, err := WithEncoderOption(m, Named("fail")).Marshal(ctx, welcome{}); err == nil {
+		t.Error("expect error of execution")
+	}
+	Register("empty-test", template.New("empty"))
+	if _, err := encoding.GetMarshaler("empty-test").Marshal(ctx, 1); err == nil {
+		t.Error("expect error of no template")
+	}
+	if encoding.GetUnmarshaler("text-test") != nil {
+		t.Error("unexpected unmarshaler")
+	}
+}
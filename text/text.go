@@ -28,6 +28,8 @@ var _codec = &codec{}
 
 func (s *codec) Marshal(_ context.Context, v interface{}) (data []byte, err error) {
 	switch vv := v.(type) {
+	case *encoding.Value:
+		data, err = marshalValue(vv)
 	case se.TextMarshaler:
 		data, err = vv.MarshalText()
 	case fmt.Stringer:
@@ -63,11 +65,30 @@ func (s *codec) Unmarshal(_ context.Context, data []byte, v interface{}) (err er
 	case *string:
 		*vv = string(data)
 		return nil
+	case *encoding.Value:
+		*vv = *encoding.NewString(string(data))
+		return nil
 	default:
 		return fmt.Errorf("text: can not unmarshal type %T", v)
 	}
 }
 
+func marshalValue(value *encoding.Value) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch value.Kind {
+	case encoding.NullKind:
+		return nil, nil
+	case encoding.BoolKind:
+		return []byte(fmt.Sprintf("%t", value.Bool)), nil
+	case encoding.NumberKind, encoding.StringKind:
+		return []byte(value.Text), nil
+	default:
+		return nil, fmt.Errorf("text: can not marshal %s value", value.Kind)
+	}
+}
+
 // Register register marshaler/unmarshaler.
 func Register(name string) {
 	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/go-kita/encoding"
 )
 
 var _ se.TextMarshaler = (*textual)(nil)
@@ -94,6 +96,24 @@ func TestCodec_Marshal(t *testing.T) {
 			wantData:  []byte{0xE4, 0xB8, 0xAD, 0xE6, 0x96, 0x87},
 			wantError: false,
 		},
+		{
+			ctx:       bg,
+			v:         encoding.NewNumber("1.50"),
+			wantData:  []byte("1.50"),
+			wantError: false,
+		},
+		{
+			ctx:       bg,
+			v:         encoding.NewBool(true),
+			wantData:  []byte("true"),
+			wantError: false,
+		},
+		{
+			ctx:       bg,
+			v:         encoding.NewArray(),
+			wantData:  nil,
+			wantError: true,
+		},
 	}
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
@@ -179,6 +199,13 @@ func TestCodec_Unmarshal(t *testing.T) {
 			wantV:   nil,
 			wantErr: true,
 		},
+		{
+			ctx:     bg,
+			data:    []byte("str"),
+			v:       &encoding.Value{},
+			wantV:   encoding.NewString("str"),
+			wantErr: false,
+		},
 	}
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
@@ -0,0 +1,113 @@
+package toml
+
+import (
+	"reflect"
+	"time"
+)
+
+// LocalDateTime is a local datetime, which has no offset. It is decoded in
+// DecoderConfig.Location, and its wall clock is encoded.
+type LocalDateTime struct {
+	Time time.Time
+}
+
+// LocalDate is a local date, which has neither time nor offset. It is decoded
+// at midnight in DecoderConfig.Location, and its date is encoded.
+type LocalDate struct {
+	Time time.Time
+}
+
+// LocalTime is a local time, which has neither date nor offset. It is decoded
+// on January 1 of year 0 in DecoderConfig.Location, and its time of day is
+// encoded.
+type LocalTime struct {
+	Time time.Time
+}
+
+// Layouts of local datetimes written by the encoder, with fractional seconds
+// if any.
+const (
+	localDateTimeFormat = "2006-01-02T15:04:05.999999999"
+	localTimeFormat     = "15:04:05.999999999"
+)
+
+func (t LocalDateTime) String() string {
+	return t.Time.Format(localDateTimeFormat)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t LocalDateTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *LocalDateTime) UnmarshalText(text []byte) error {
+	return parseLocal(text, localDateTimeLayout, &t.Time)
+}
+
+func (t LocalDate) String() string {
+	return t.Time.Format(localDateLayout)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t LocalDate) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *LocalDate) UnmarshalText(text []byte) error {
+	return parseLocal(text, localDateLayout, &t.Time)
+}
+
+func (t LocalTime) String() string {
+	return t.Time.Format(localTimeFormat)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t LocalTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *LocalTime) UnmarshalText(text []byte) error {
+	return parseLocal(text, localTimeLayout, &t.Time)
+}
+
+func parseLocal(text []byte, layout string, t *time.Time) error {
+	parsed, err := time.ParseInLocation(layout, string(text), time.Local)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+var (
+	_localDateTimeType = reflect.TypeOf(LocalDateTime{})
+	_localDateType     = reflect.TypeOf(LocalDate{})
+	_localTimeType     = reflect.TypeOf(LocalTime{})
+)
+
+// localTypes are the types of local datetimes by the layouts of the parser.
+var localTypes = map[string]reflect.Type{
+	localDateTimeLayout: _localDateTimeType,
+	localDateLayout:     _localDateType,
+	localTimeLayout:     _localTimeType,
+}
+
+// isLocal reports whether t is the type of a local datetime.
+func isLocal(t reflect.Type) bool {
+	return t == _localDateTimeType || t == _localDateType || t == _localTimeType
+}
+
+// local returns a local datetime of t, as a value of the type.
+func local(t reflect.Type, tm time.Time) reflect.Value {
+	v := reflect.New(t).Elem()
+	v.Field(0).Set(reflect.ValueOf(tm))
+	return v
+}
+
+// localText formats a local datetime, which is a value of a type isLocal.
+func localText(v reflect.Value) string {
+	return v.Interface().(interface{ String() string }).String()
+}
@@ -0,0 +1,96 @@
+package toml
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDatetime_RoundTrip(t *testing.T) {
+	src := "a = 1979-05-27T07:32:00-08:00\n" +
+		"b = 1979-05-27T07:32:00.5\n" +
+		"c = 1979-05-27\n" +
+		"d = 07:32:00\n" +
+		"e = 00:32:00.999999\n"
+	ctx := context.Background()
+	var v map[string]interface{}
+	if err := (&codec{}).Unmarshal(ctx, []byte(src), &v); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{
+		"a": time.Time{}, "b": LocalDateTime{}, "c": LocalDate{}, "d": LocalTime{}, "e": LocalTime{},
+	} {
+		if got := v[key]; got == nil || typeName(got) != typeName(want) {
+			t.Errorf("%s: got %T, want %T", key, got, want)
+		}
+	}
+	data, err := (&codec{}).Marshal(ctx, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != src {
+		t.Errorf("got\n%s\nwant\n%s", data, src)
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case time.Time:
+		return "offset"
+	case LocalDateTime:
+		return "datetime"
+	case LocalDate:
+		return "date"
+	case LocalTime:
+		return "time"
+	}
+	return "other"
+}
+
+func TestDatetime_Struct(t *testing.T) {
+	type times struct {
+		Offset   time.Time     `toml:"offset"`
+		DateTime LocalDateTime `toml:"datetime"`
+		Date     LocalDate     `toml:"date"`
+		Time     LocalTime     `toml:"time"`
+	}
+	src := "offset = 2021-06-01T08:00:00Z\ndatetime = 2021-06-01T08:00:00\ndate = 2021-06-01\ntime = 08:00:00\n"
+	ctx := context.Background()
+	u := WithDecoderOption(&codec{}, WithLocation(time.UTC))
+	var v times
+	if err := u.Unmarshal(ctx, []byte(src), &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC); !v.Date.Time.Equal(want) {
+		t.Errorf("got date %v, want %v", v.Date.Time, want)
+	}
+	data, err := (&codec{}).Marshal(ctx, &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != src {
+		t.Errorf("got\n%s\nwant\n%s", data, src)
+	}
+
+	for _, src := range []string{"date = 08:00:00\n", "time = 2021-06-01\n", "datetime = 2021-06-01T08:00:00Z\n"} {
+		var v times
+		if _, ok := u.Unmarshal(ctx, []byte(src), &v).(*UnmarshalTypeError); !ok {
+			t.Errorf("%q: expect *UnmarshalTypeError", src)
+		}
+	}
+}
+
+func TestLocal_Text(t *testing.T) {
+	var d LocalDate
+	if err := d.UnmarshalText([]byte("1979-05-27")); err != nil || d.String() != "1979-05-27" {
+		t.Errorf("got %v, %v", d, err)
+	}
+	var tm LocalTime
+	if err := tm.UnmarshalText([]byte("1979-05-27")); err == nil {
+		t.Error("unmarshaled date into LocalTime")
+	}
+	text, _ := LocalDateTime{time.Date(2021, 6, 1, 8, 0, 0, 0, time.FixedZone("", 3600))}.MarshalText()
+	if string(text) != "2021-06-01T08:00:00" {
+		t.Errorf("got %s", text)
+	}
+}
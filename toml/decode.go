@@ -0,0 +1,319 @@
+package toml
+
+import (
+	se "encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+)
+
+// UnmarshalTypeError is an error of decoding a value into a Go value of
+// inappropriate type.
+type UnmarshalTypeError struct {
+	// Value describes the TOML value, e.g. "string", "integer 7".
+	Value string
+	// Type is the type of the target value.
+	Type reflect.Type
+	// Line is the 1-based line number where the value is defined.
+	Line int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("toml: line %d: cannot unmarshal %s into Go value of type %s", e.Line, e.Value, e.Type)
+}
+
+var (
+	_valueType           = reflect.TypeOf(encoding.Value{})
+	_timeType            = reflect.TypeOf(time.Time{})
+	_textUnmarshalerType = reflect.TypeOf((*se.TextUnmarshaler)(nil)).Elem()
+)
+
+type decoder struct {
+	config *DecoderConfig
+}
+
+func typeError(n *node, t reflect.Type) error {
+	value := n.kind.String()
+	switch n.kind {
+	case integerNode, floatNode, boolNode:
+		value += " " + n.text
+	}
+	return &UnmarshalTypeError{Value: value, Type: t, Line: n.line}
+}
+
+// time returns the time of a datetime node. Datetimes without offset are in
+// the location of the configuration.
+func (d *decoder) time(n *node) (time.Time, error) {
+	loc := d.config.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t, err := time.ParseInLocation(n.layout, n.text, loc)
+	if err != nil {
+		return time.Time{}, &SyntaxError{Line: n.line, msg: err.Error()}
+	}
+	return t, nil
+}
+
+// unmarshal decodes a node into v, which must be settable.
+func (d *decoder) unmarshal(n *node, v reflect.Value) error {
+	switch {
+	case v.Type() == _valueType:
+		v.Set(reflect.ValueOf(*d.value(n)))
+		return nil
+	case v.Type() == _timeType && n.kind == datetimeNode:
+		t, err := d.time(n)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case isLocal(v.Type()) && n.kind == datetimeNode:
+		if localTypes[n.layout] != v.Type() {
+			return typeError(n, v.Type())
+		}
+		t, err := d.time(n)
+		if err != nil {
+			return err
+		}
+		v.Set(local(v.Type(), t))
+		return nil
+	case n.kind == stringNode && v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(_textUnmarshalerType):
+		if err := v.Addr().Interface().(se.TextUnmarshaler).UnmarshalText([]byte(n.text)); err != nil {
+			return fmt.Errorf("toml: line %d: %w", n.line, err)
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.unmarshal(n, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return typeError(n, v.Type())
+		}
+		x, err := d.any(n)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(x))
+		return nil
+	}
+	switch n.kind {
+	case arrayNode:
+		return d.array(n, v)
+	case tableNode:
+		return d.table(n, v)
+	}
+	return d.scalar(n, v)
+}
+
+func (d *decoder) scalar(n *node, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		if n.kind == stringNode || n.kind == datetimeNode {
+			v.SetString(n.text)
+			return nil
+		}
+	case reflect.Bool:
+		if n.kind == boolNode {
+			v.SetBool(n.b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n.kind == integerNode && !v.OverflowInt(n.i) {
+			v.SetInt(n.i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n.kind == integerNode && n.i >= 0 && !v.OverflowUint(uint64(n.i)) {
+			v.SetUint(uint64(n.i))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n.kind {
+		case integerNode:
+			v.SetFloat(float64(n.i))
+			return nil
+		case floatNode:
+			if !v.OverflowFloat(n.f) || math.IsInf(n.f, 0) {
+				v.SetFloat(n.f)
+				return nil
+			}
+		}
+	case reflect.Slice:
+		if n.kind == stringNode && v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := base64.StdEncoding.DecodeString(n.text)
+			if err != nil {
+				return fmt.Errorf("toml: line %d: %w", n.line, err)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+	}
+	return typeError(n, v.Type())
+}
+
+func (d *decoder) array(n *node, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(n.items), len(n.items))
+		for i, item := range n.items {
+			if err := d.unmarshal(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if i >= len(n.items) {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			} else if err := d.unmarshal(n.items[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return typeError(n, v.Type())
+}
+
+func (d *decoder) table(n *node, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Map:
+		kt := v.Type().Key()
+		text := reflect.PtrTo(kt).Implements(_textUnmarshalerType)
+		if kt.Kind() != reflect.String && !text {
+			return typeError(n, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, k := range n.keys {
+			key := reflect.New(kt).Elem()
+			if !text {
+				key.SetString(k)
+			} else if err := key.Addr().Interface().(se.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+				return fmt.Errorf("toml: line %d: %w", n.members[k].line, err)
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.unmarshal(n.members[k], elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return nil
+	case reflect.Struct:
+		fs := fields.Of(v.Type(), _tags...)
+		for _, k := range n.keys {
+			member := n.members[k]
+			f := lookupField(fs, k)
+			if f == nil {
+				if d.config.DisallowUnknownFields {
+					return fmt.Errorf("toml: line %d: unknown field %q", member.line, k)
+				}
+				continue
+			}
+			fv, ok := fields.ByIndex(v, f.Index, true)
+			if !ok {
+				return fmt.Errorf("toml: field %s: can not set embedded pointer to unexported struct", f.Name)
+			}
+			if err := d.unmarshal(member, fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return typeError(n, v.Type())
+}
+
+func lookupField(fs []fields.Field, key string) *fields.Field {
+	for i := range fs {
+		if fs[i].Name == key {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].Name, key) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+// any decodes a node into bool, int64, float64, string, time.Time for offset
+// datetimes, LocalDateTime, LocalDate, LocalTime, []interface{} or
+// map[string]interface{}.
+func (d *decoder) any(n *node) (interface{}, error) {
+	switch n.kind {
+	case stringNode:
+		return n.text, nil
+	case integerNode:
+		return n.i, nil
+	case floatNode:
+		return n.f, nil
+	case boolNode:
+		return n.b, nil
+	case datetimeNode:
+		t, err := d.time(n)
+		if err != nil {
+			return nil, err
+		}
+		if lt, ok := localTypes[n.layout]; ok {
+			return local(lt, t).Interface(), nil
+		}
+		return t, nil
+	case arrayNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			x, err := d.any(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, x)
+		}
+		return items, nil
+	}
+	m := make(map[string]interface{}, len(n.keys))
+	for _, k := range n.keys {
+		x, err := d.any(n.members[k])
+		if err != nil {
+			return nil, err
+		}
+		m[k] = x
+	}
+	return m, nil
+}
+
+// value decodes a node into an *encoding.Value. Numbers keep their literals
+// without underscores, integers in decimal. Datetimes are decoded into strings
+// in RFC 3339 form.
+func (d *decoder) value(n *node) *encoding.Value {
+	switch n.kind {
+	case stringNode, datetimeNode:
+		return encoding.NewString(n.text)
+	case integerNode, floatNode:
+		return encoding.NewNumber(n.text)
+	case boolNode:
+		return encoding.NewBool(n.b)
+	case arrayNode:
+		value := encoding.NewArray()
+		for _, item := range n.items {
+			value.Append(d.value(item))
+		}
+		return value
+	}
+	value := encoding.NewObject()
+	for _, k := range n.keys {
+		value.Members = append(value.Members, &encoding.Member{Key: k, Value: d.value(n.members[k])})
+	}
+	return value
+}
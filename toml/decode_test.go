@@ -0,0 +1,162 @@
+package toml
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+func unmarshal(config *DecoderConfig, data string, v interface{}) error {
+	root, err := parse([]byte(data))
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.unmarshal(root, reflect.ValueOf(v).Elem())
+}
+
+type server struct {
+	Host    string        `toml:"host"`
+	Port    uint16        `toml:"port"`
+	Timeout time.Duration `toml:"timeout"`
+	Ratio   float32
+	IP      net.IP    `toml:"ip"`
+	Started time.Time `toml:"started"`
+	Tags    []string  `toml:"tags"`
+	Debug   *bool     `toml:"debug"`
+}
+
+func TestDecoder(t *testing.T) {
+	debug := true
+	local := time.FixedZone("local", 3600)
+	tests := []struct {
+		name   string
+		config *DecoderConfig
+		src    string
+		v      interface{}
+		want   interface{}
+	}{
+		{name: "struct", src: "host = 'localhost'\nport = 8080\ntimeout = 1_000\nRATIO = 1\nip = '10.0.0.1'\nstarted = 2021-06-01T08:00:00Z\ntags = ['a']\ndebug = true\nextra = [1]\n",
+			v: &server{}, want: server{Host: "localhost", Port: 8080, Timeout: 1000, Ratio: 1, IP: net.ParseIP("10.0.0.1"),
+				Started: time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC), Tags: []string{"a"}, Debug: &debug}},
+		{name: "local datetime", config: &DecoderConfig{Location: local}, src: "started = 2021-06-01 08:00:00\n",
+			v: &server{}, want: server{Started: time.Date(2021, 6, 1, 8, 0, 0, 0, local)}},
+		{name: "datetime into string", src: "a = 2021-06-01t08:00:00z\n", v: new(map[string]string),
+			want: map[string]string{"a": "2021-06-01T08:00:00Z"}},
+		{name: "arrays of tables", src: "[[servers]]\nhost = 'a'\n[[servers]]\nhost = 'b'\n", v: new(map[string][]server),
+			want: map[string][]server{"servers": {{Host: "a"}, {Host: "b"}}}},
+		{name: "nested map", src: "[a.b]\nc = 1\n", v: &map[string]map[string]map[string]int{"x": nil},
+			want: map[string]map[string]map[string]int{"x": nil, "a": {"b": {"c": 1}}}},
+		{name: "array", src: "a = [1, 2, 3]\n", v: new(struct{ A [2]int8 }), want: struct{ A [2]int8 }{[2]int8{1, 2}}},
+		{name: "bytes", src: "a = 'aGVsbG8='\n", v: new(struct{ A []byte }), want: struct{ A []byte }{[]byte("hello")}},
+		{name: "interface", src: "a = 1\n", v: new(interface{}), want: map[string]interface{}{"a": int64(1)}},
+		{name: "text key", src: "'10.0.0.1' = 1\n", v: new(map[textKey]int), want: map[textKey]int{"10.0.0.1": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = &DecoderConfig{}
+			}
+			if err := unmarshal(config, tt.src, tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+type textKey string
+
+func (k *textKey) UnmarshalText(text []byte) error {
+	if net.ParseIP(string(text)) == nil {
+		return errors.New("invalid IP")
+	}
+	*k = textKey(text)
+	return nil
+}
+
+func TestDecoder_TypeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		v     interface{}
+		line  int
+		value string
+	}{
+		{name: "string into int", src: "port = 80\n\nhost = 1\n", v: &server{}, line: 3, value: "integer 1"},
+		{name: "overflow", src: "port = 70000\n", v: &server{}, line: 1, value: "integer 70000"},
+		{name: "negative into uint", src: "port = -1\n", v: &server{}, line: 1, value: "integer -1"},
+		{name: "float into int", src: "port = 1.5\n", v: &server{}, line: 1, value: "float 1.5"},
+		{name: "table into slice", src: "[tags]\n", v: &server{}, line: 1, value: "table"},
+		{name: "array into string", src: "\nhost = [\n'a']\n", v: &server{}, line: 2, value: "array"},
+		{name: "datetime into int", src: "port = 2021-06-01\n", v: &server{}, line: 1, value: "datetime"},
+		{name: "string into bool", src: "debug = 'true'\n", v: &server{}, line: 1, value: "string"},
+		{name: "non-string key", src: "a = 1\n", v: new(map[int]int), line: 1, value: "table"},
+		{name: "top-level into slice", src: "a = 1\n", v: new([]int), line: 1, value: "table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unmarshal(&DecoderConfig{}, tt.src, tt.v)
+			var te *UnmarshalTypeError
+			if !errors.As(err, &te) {
+				t.Fatalf("want *UnmarshalTypeError, got %v", err)
+			}
+			if te.Line != tt.line || te.Value != tt.value {
+				t.Errorf("got %v, want line %d of %s", err, tt.line, tt.value)
+			}
+		})
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *DecoderConfig
+		src    string
+		v      interface{}
+		want   string
+	}{
+		{name: "unknown field", config: &DecoderConfig{DisallowUnknownFields: true}, src: "host = 'a'\nhots = 'b'\n", v: &server{},
+			want: `toml: line 2: unknown field "hots"`},
+		{name: "text unmarshaler", config: &DecoderConfig{}, src: "\nip = 'x'\n", v: &server{},
+			want: "toml: line 2: invalid IP address"},
+		{name: "text key", config: &DecoderConfig{}, src: "x = 1\n", v: new(map[textKey]int),
+			want: "toml: line 1: invalid IP"},
+		{name: "bytes", config: &DecoderConfig{}, src: "a = '!'\n", v: new(struct{ A []byte }),
+			want: "toml: line 1: illegal base64 data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unmarshal(tt.config, tt.src, tt.v)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Value(t *testing.T) {
+	src := "b = 0x10\na = [1.50, 'x', true, 1979-05-27, -inf]\n[t]\nc.d = 1e3\n"
+	var value encoding.Value
+	if err := unmarshal(&DecoderConfig{}, src, &value); err != nil {
+		t.Fatal(err)
+	}
+	want := encoding.NewObject(
+		&encoding.Member{Key: "b", Value: encoding.NewNumber("16")},
+		&encoding.Member{Key: "a", Value: encoding.NewArray(encoding.NewNumber("1.50"), encoding.NewString("x"),
+			encoding.NewBool(true), encoding.NewString("1979-05-27"), encoding.NewNumber("-inf"))},
+		&encoding.Member{Key: "t", Value: encoding.NewObject(
+			&encoding.Member{Key: "c", Value: encoding.NewObject(&encoding.Member{Key: "d", Value: encoding.NewNumber("1e3")})})},
+	)
+	if !value.Equal(want) {
+		t.Errorf("got %+v, want %+v", value, want)
+	}
+}
@@ -0,0 +1,409 @@
+package toml
+
+import (
+	"bytes"
+	se "encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+var _textMarshalerType = reflect.TypeOf((*se.TextMarshaler)(nil)).Elem()
+
+type encoder struct {
+	config *EncoderConfig
+	buf    bytes.Buffer
+}
+
+func (e *encoder) encode(v interface{}) error {
+	n, err := e.represent(reflect.ValueOf(v), 0)
+	if err != nil {
+		return err
+	}
+	if n == nil || n.kind != tableNode {
+		return fmt.Errorf("toml: top-level value must be a table, not %T", v)
+	}
+	e.table(nil, n, false)
+	return nil
+}
+
+// represent converts v into a node. Nil values, which TOML can not represent,
+// are converted into nil nodes.
+func (e *encoder) represent(v reflect.Value, depth int) (*node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("toml: exceeded max depth of %d", maxDepth)
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	t := v.Type()
+	switch {
+	case t == _valueType:
+		value := v.Interface().(encoding.Value)
+		return e.valueNode(&value, depth)
+	case t == reflect.PtrTo(_valueType):
+		return e.valueNode(v.Interface().(*encoding.Value), depth)
+	case t == _timeType:
+		return &node{kind: datetimeNode, text: v.Interface().(time.Time).Format(time.RFC3339Nano)}, nil
+	case isLocal(t):
+		return &node{kind: datetimeNode, text: localText(v)}, nil
+	case t.Implements(_textMarshalerType):
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, nil
+		}
+		return e.text(v.Interface().(se.TextMarshaler))
+	case t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(t).Implements(_textMarshalerType):
+		return e.text(v.Addr().Interface().(se.TextMarshaler))
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.represent(v.Elem(), depth)
+	case reflect.Bool:
+		return &node{kind: boolNode, text: strconv.FormatBool(v.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &node{kind: integerNode, text: strconv.FormatInt(v.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("toml: integer %d overflows int64", v.Uint())
+		}
+		return &node{kind: integerNode, text: strconv.FormatUint(v.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &node{kind: floatNode, text: formatFloat(v.Float(), t.Bits())}, nil
+	case reflect.String:
+		return &node{kind: stringNode, text: v.String()}, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &node{kind: stringNode, text: base64.StdEncoding.EncodeToString(v.Bytes())}, nil
+		}
+		return e.arrayNode(v, depth)
+	case reflect.Array:
+		return e.arrayNode(v, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.mapNode(v, depth)
+	case reflect.Struct:
+		return e.structNode(v, depth)
+	}
+	return nil, fmt.Errorf("toml: unsupported type %s", t)
+}
+
+func (e *encoder) text(m se.TextMarshaler) (*node, error) {
+	text, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return &node{kind: stringNode, text: string(text)}, nil
+}
+
+// formatFloat formats a float as a TOML float, which has a fractional part or
+// an exponent part.
+func formatFloat(f float64, bits int) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	s := textual.FormatFloat(f, bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func (e *encoder) arrayNode(v reflect.Value, depth int) (*node, error) {
+	n := &node{kind: arrayNode, items: make([]*node, 0, v.Len())}
+	for i := 0; i < v.Len(); i++ {
+		item, err := e.represent(v.Index(i), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, fmt.Errorf("toml: can not encode nil element of %s", v.Type())
+		}
+		n.items = append(n.items, item)
+	}
+	return n, nil
+}
+
+// mapNode converts a map into a table with keys sorted.
+func (e *encoder) mapNode(v reflect.Value, depth int) (*node, error) {
+	kt := v.Type().Key()
+	if kt.Kind() != reflect.String && !kt.Implements(_textMarshalerType) {
+		return nil, fmt.Errorf("toml: unsupported map key type %s", kt)
+	}
+	n := newTable(explicitOrigin, 0)
+	iter := v.MapRange()
+	for iter.Next() {
+		var key string
+		if kt.Kind() == reflect.String {
+			key = iter.Key().String()
+		} else {
+			text, err := iter.Key().Interface().(se.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			key = string(text)
+		}
+		value, err := e.represent(iter.Value(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			n.set(key, value)
+		}
+	}
+	sort.Strings(n.keys)
+	return n, nil
+}
+
+func (e *encoder) structNode(v reflect.Value, depth int) (*node, error) {
+	n := newTable(explicitOrigin, 0)
+	for _, f := range fields.Of(v.Type(), _tags...) {
+		fv, ok := fields.ByIndex(v, f.Index, false)
+		if !ok || f.OmitEmpty() && fields.IsEmpty(fv) {
+			continue
+		}
+		value, err := e.represent(fv, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("toml: field %s: %w", f.Name, err)
+		}
+		if value != nil {
+			n.set(f.Name, value)
+		}
+	}
+	return n, nil
+}
+
+func (e *encoder) valueNode(value *encoding.Value, depth int) (*node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("toml: exceeded max depth of %d", maxDepth)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch value.Kind {
+	case encoding.NullKind:
+		return nil, nil
+	case encoding.BoolKind:
+		return &node{kind: boolNode, text: strconv.FormatBool(value.Bool)}, nil
+	case encoding.NumberKind:
+		n, err := scalar(value.Text)
+		if err != nil || n.kind != integerNode && n.kind != floatNode {
+			return nil, fmt.Errorf("toml: invalid number %q", value.Text)
+		}
+		return &node{kind: n.kind, text: value.Text}, nil
+	case encoding.StringKind:
+		return &node{kind: stringNode, text: value.Text}, nil
+	case encoding.ArrayKind:
+		n := &node{kind: arrayNode, items: make([]*node, 0, len(value.Items))}
+		for _, item := range value.Items {
+			child, err := e.valueNode(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if child == nil {
+				return nil, fmt.Errorf("toml: can not encode null element of array")
+			}
+			n.items = append(n.items, child)
+		}
+		return n, nil
+	case encoding.ObjectKind:
+		n := newTable(explicitOrigin, 0)
+		for _, m := range value.Members {
+			if _, ok := n.members[m.Key]; ok {
+				return nil, fmt.Errorf("toml: duplicate key %q", m.Key)
+			}
+			child, err := e.valueNode(m.Value, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if child != nil {
+				n.set(m.Key, child)
+			}
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("toml: unsupported value kind %s", value.Kind)
+}
+
+// isTables reports whether n is written as an array of tables, which is a
+// non-empty array of tables only.
+func isTables(n *node) bool {
+	if n.kind != arrayNode || len(n.items) == 0 {
+		return false
+	}
+	for _, item := range n.items {
+		if item.kind != tableNode {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *encoder) spaces(level int) {
+	for i := 0; i < level*e.config.Indent; i++ {
+		e.buf.WriteByte(' ')
+	}
+}
+
+// table writes the key/value pairs of table n, followed by its sub-tables and
+// arrays of tables. The header of a table is written unless the table has
+// sub-tables only, or it is an element of an array of tables, whose header is
+// written by the caller.
+func (e *encoder) table(path []string, n *node, element bool) {
+	level := len(path) - 1
+	if level < 0 {
+		level = 0
+	}
+	var pairs, subs []string
+	for _, key := range n.keys {
+		if child := n.members[key]; child.kind == tableNode || isTables(child) {
+			subs = append(subs, key)
+		} else {
+			pairs = append(pairs, key)
+		}
+	}
+	if len(path) > 0 && !element && (len(pairs) > 0 || len(subs) == 0) {
+		e.header(path, "[", "]")
+	}
+	for _, key := range pairs {
+		e.spaces(level)
+		e.buf.WriteString(formatKeys([]string{key}))
+		e.buf.WriteString(" = ")
+		if child := n.members[key]; child.kind == stringNode && strings.Contains(child.text, "\n") {
+			e.buf.WriteString(quoteMultiLine(child.text))
+		} else {
+			e.inline(child)
+		}
+		e.buf.WriteByte('\n')
+	}
+	for _, key := range subs {
+		child := n.members[key]
+		childPath := append(path[:len(path):len(path)], key)
+		if child.kind == tableNode {
+			e.table(childPath, child, false)
+			continue
+		}
+		for _, item := range child.items {
+			e.header(childPath, "[[", "]]")
+			e.table(childPath, item, true)
+		}
+	}
+}
+
+func (e *encoder) header(path []string, open, close string) {
+	if e.buf.Len() > 0 {
+		e.buf.WriteByte('\n')
+	}
+	e.spaces(len(path) - 1)
+	e.buf.WriteString(open)
+	e.buf.WriteString(formatKeys(path))
+	e.buf.WriteString(close)
+	e.buf.WriteByte('\n')
+}
+
+// inline writes n as an inline value.
+func (e *encoder) inline(n *node) {
+	switch n.kind {
+	case stringNode:
+		e.buf.WriteString(quote(n.text))
+	case arrayNode:
+		e.buf.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				e.buf.WriteString(", ")
+			}
+			e.inline(item)
+		}
+		e.buf.WriteByte(']')
+	case tableNode:
+		if len(n.keys) == 0 {
+			e.buf.WriteString("{}")
+			return
+		}
+		e.buf.WriteString("{ ")
+		for i, key := range n.keys {
+			if i > 0 {
+				e.buf.WriteString(", ")
+			}
+			e.buf.WriteString(formatKeys([]string{key}))
+			e.buf.WriteString(" = ")
+			e.inline(n.members[key])
+		}
+		e.buf.WriteString(" }")
+	default:
+		e.buf.WriteString(n.text)
+	}
+}
+
+// quote quotes s as a basic string. Invalid UTF-8 is replaced with U+FFFD.
+func quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	escape(&b, s, false)
+	b.WriteByte('"')
+	return b.String()
+}
+
+// quoteMultiLine quotes s as a multi-line basic string, with line feeds kept
+// as they are.
+func quoteMultiLine(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 7)
+	b.WriteString("\"\"\"\n")
+	escape(&b, s, true)
+	b.WriteString("\"\"\"")
+	return b.String()
+}
+
+func escape(b *strings.Builder, s string, multiLine bool) {
+	for _, r := range s {
+		if r == '\n' && multiLine {
+			b.WriteByte('\n')
+			continue
+		}
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+type failingText struct{}
+
+func (failingText) MarshalText() ([]byte, error) {
+	return nil, errors.New("toml: failing")
+}
+
+func marshal(config *EncoderConfig, v interface{}) (string, error) {
+	e := &encoder{config: config}
+	err := e.encode(v)
+	return e.buf.String(), err
+}
+
+type owner struct {
+	Name string    `toml:"name"`
+	DOB  time.Time `toml:"dob"`
+}
+
+type product struct {
+	Name  string `toml:"name,omitempty"`
+	SKU   int    `toml:"sku,omitempty"`
+	Color string `toml:"color,omitempty"`
+}
+
+type document struct {
+	Title    string                       `toml:"title"`
+	Owner    owner                        `toml:"owner"`
+	Ports    []int                        `toml:"ports"`
+	Servers  map[string]map[string]string `toml:"servers"`
+	Products []product                    `toml:"products"`
+	Note     *string                      `toml:"note"`
+}
+
+func TestEncoder(t *testing.T) {
+	doc := document{
+		Title:    "TOML Example",
+		Owner:    owner{Name: "Tom", DOB: time.Date(1979, 5, 27, 7, 32, 0, 0, time.FixedZone("", -8*3600))},
+		Ports:    []int{8000, 8001},
+		Servers:  map[string]map[string]string{"beta": {"ip": "10.0.0.2"}, "alpha": {"ip": "10.0.0.1"}},
+		Products: []product{{Name: "Hammer", SKU: 738594937}, {}, {Name: "Nail", Color: "gray"}},
+	}
+	tests := []struct {
+		name   string
+		config *EncoderConfig
+		v      interface{}
+		want   string
+	}{
+		{name: "document", v: doc, want: `title = "TOML Example"
+ports = [8000, 8001]
+
+[owner]
+name = "Tom"
+dob = 1979-05-27T07:32:00-08:00
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[servers.beta]
+ip = "10.0.0.2"
+
+[[products]]
+name = "Hammer"
+sku = 738594937
+
+[[products]]
+
+[[products]]
+name = "Nail"
+color = "gray"
+`},
+		{name: "indent", config: &EncoderConfig{Indent: 2}, v: map[string]interface{}{"a": map[string]interface{}{"b": map[string]int{"c": 1}, "d": 2}},
+			want: "[a]\nd = 2\n\n  [a.b]\n  c = 1\n"},
+		{name: "scalars", v: map[string]interface{}{"b": true, "f": 1.0, "g": float32(0.1), "h": 1e21, "i": -3, "n": math.NaN(), "p": math.Inf(1), "u": uint8(7)},
+			want: "b = true\nf = 1.0\ng = 0.1\nh = 1e+21\ni = -3\nn = nan\np = inf\nu = 7\n"},
+		{name: "strings", v: map[string]string{"a": "quote\" back\\ tab\t\x01\x7f", "b": "x\ny\r\n", "c": ""},
+			want: "a = \"quote\\\" back\\\\ tab\\t\\u0001\\u007F\"\nb = \"\"\"\nx\ny\\r\n\"\"\"\nc = \"\"\n"},
+		{name: "keys", v: map[string]int{"a.b": 1, "": 2, "c d": 3, "é": 4, "x-y_1": 5},
+			want: "\"\" = 2\n\"a.b\" = 1\n\"c d\" = 3\nx-y_1 = 5\n\"é\" = 4\n"},
+		{name: "inline", v: map[string]interface{}{"a": []interface{}{1, "x", map[string]interface{}{"k": []int{}, "l": map[string]string{"m\n": "v\n"}}}, "e": []int{}},
+			want: "a = [1, \"x\", { k = [], l = { \"m\\n\" = \"v\\n\" } }]\ne = []\n"},
+		{name: "nil omitted", v: map[string]interface{}{"a": nil, "b": (*int)(nil), "c": []int(nil), "d": map[string]int(nil)}, want: ""},
+		{name: "empty table", v: map[string]interface{}{"a": struct{}{}}, want: "[a]\n"},
+		{name: "bytes and text", v: map[string]interface{}{"b": []byte("hi"), "t": time.Duration(0), "x": textKey("k")},
+			want: "b = \"aGk=\"\nt = 0\nx = \"k\"\n"},
+		{name: "text keys", v: map[code]string{10: "x", 2: "y"}, want: "c02 = \"y\"\nc10 = \"x\"\n"},
+		{name: "value", v: encoding.NewObject(
+			&encoding.Member{Key: "n", Value: encoding.NewNumber("1.50")},
+			&encoding.Member{Key: "z", Value: encoding.NewNull()},
+			&encoding.Member{Key: "t", Value: encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewArray(encoding.NewBool(false))})},
+		), want: "n = 1.50\n\n[t]\na = [false]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = &EncoderConfig{}
+			}
+			got, err := marshal(config, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+type code int
+
+func (c code) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("c%02d", int(c))), nil
+}
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	in := map[string]interface{}{
+		"s":     "multi\nline \"\"\" quotes\\\n",
+		"i":     int64(math.MinInt64),
+		"f":     -1.5e-7,
+		"t":     time.Date(2021, 6, 1, 8, 0, 0, 1, time.UTC),
+		"a":     []interface{}{[]interface{}{}, map[string]interface{}{"x y": "z"}},
+		"tbl":   map[string]interface{}{"sub": map[string]interface{}{"k": true}},
+		"array": []interface{}{map[string]interface{}{"n": int64(1)}, map[string]interface{}{"sub": map[string]interface{}{}}},
+	}
+	data, err := marshal(&EncoderConfig{Indent: 4}, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := parse([]byte(data))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	got, err := (&decoder{config: &DecoderConfig{}}).any(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, in) {
+		t.Errorf("got %#v, want %#v\n%s", got, in, data)
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	var cyclic []interface{}
+	cyclic = append(cyclic, &cyclic)
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "top-level scalar", v: 1},
+		{name: "top-level array", v: []int{1}},
+		{name: "top-level nil", v: nil},
+		{name: "unsupported", v: map[string]interface{}{"c": make(chan int)}},
+		{name: "map key", v: map[int]int{1: 1}},
+		{name: "uint64 overflow", v: map[string]uint64{"a": math.MaxUint64}},
+		{name: "nil element", v: map[string][]*int{"a": {nil}}},
+		{name: "text marshaler", v: map[string]interface{}{"a": failingText{}}},
+		{name: "field", v: struct{ F func() }{}},
+		{name: "cyclic", v: map[string]interface{}{"a": cyclic}},
+		{name: "invalid number", v: encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewNumber("1.5.5")})},
+		{name: "null element", v: encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewArray(encoding.NewNull())})},
+		{name: "duplicate key", v: encoding.NewObject(&encoding.Member{Key: "a", Value: encoding.NewBool(true)},
+			&encoding.Member{Key: "a", Value: encoding.NewBool(false)})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := marshal(&EncoderConfig{}, tt.v); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package toml
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, Indent(4))
+	got, err := m.Marshal(context.Background(), map[string]map[string]map[string]int{"a": {"b": {"c": 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "    [a.b]\n    c = 1\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := WithEncoderOption(&codec{}, Indent(-1)).Marshal(context.Background(), map[string]int{}); err == nil {
+		t.Error("want error of invalid indent")
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	u := WithDecoderOption(&codec{}, DisallowUnknownFields())
+	var v server
+	if err := u.Unmarshal(context.Background(), []byte("host = 'a'\nname = 'x'\n"), &v); err == nil {
+		t.Error("want error of unknown field")
+	}
+	loc := time.FixedZone("test", -3600)
+	u = WithDecoderOption(&codec{}, WithLocation(loc))
+	if err := u.Unmarshal(context.Background(), []byte("started = 2021-06-01\n"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 6, 1, 0, 0, 0, 0, loc); !v.Started.Equal(want) || v.Started.Location() != loc {
+		t.Errorf("got %v, want %v", v.Started, want)
+	}
+}
@@ -0,0 +1,804 @@
+package toml
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDepth is the maximum nesting depth of arrays, inline tables and segments
+// of keys.
+const maxDepth = 1000
+
+// SyntaxError is an error of parsing TOML.
+type SyntaxError struct {
+	// Line is the 1-based line number where the error occurs.
+	Line int
+	msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("toml: line %d: %s", e.Line, e.msg)
+}
+
+type nodeKind uint8
+
+const (
+	stringNode nodeKind = iota
+	integerNode
+	floatNode
+	boolNode
+	datetimeNode
+	arrayNode
+	tableNode
+)
+
+var _kindNames = [...]string{
+	stringNode:   "string",
+	integerNode:  "integer",
+	floatNode:    "float",
+	boolNode:     "boolean",
+	datetimeNode: "datetime",
+	arrayNode:    "array",
+	tableNode:    "table",
+}
+
+func (k nodeKind) String() string {
+	return _kindNames[k]
+}
+
+// origin records how a table or an array is defined, which decides whether it
+// can be extended later.
+type origin uint8
+
+const (
+	// implicitOrigin is a table created as a parent of a table header.
+	implicitOrigin origin = iota
+	// explicitOrigin is a table defined by a table header, or an element of
+	// an array of tables.
+	explicitOrigin
+	// dottedOrigin is a table created by a dotted key.
+	dottedOrigin
+	// inlineOrigin is an inline table or a static array, which is complete
+	// once defined.
+	inlineOrigin
+	// tablesOrigin is an array of tables.
+	tablesOrigin
+)
+
+// node is a value of a TOML document.
+type node struct {
+	kind nodeKind
+	// text is the content of a string, or the literal of an integer, a
+	// float or a datetime, normalized for output.
+	text string
+	b    bool
+	i    int64
+	f    float64
+	// layout is the time layout of a datetime.
+	layout string
+	// items are the elements of an array.
+	items []*node
+	// keys are the keys of a table in order of definition.
+	keys    []string
+	members map[string]*node
+	origin  origin
+	// line is the 1-based line number where the node is defined.
+	line int
+}
+
+func newTable(o origin, line int) *node {
+	return &node{kind: tableNode, members: map[string]*node{}, origin: o, line: line}
+}
+
+func (n *node) set(key string, value *node) {
+	if _, ok := n.members[key]; !ok {
+		n.keys = append(n.keys, key)
+	}
+	n.members[key] = value
+}
+
+// Layouts of datetimes. Fractional seconds are accepted by time.Parse without
+// being in layouts.
+const (
+	offsetDateTimeLayout = "2006-01-02T15:04:05Z07:00"
+	localDateTimeLayout  = "2006-01-02T15:04:05"
+	localDateLayout      = "2006-01-02"
+	localTimeLayout      = "15:04:05"
+)
+
+var (
+	_decimalPattern  = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)$`)
+	_hexPattern      = regexp.MustCompile(`^0x[0-9A-Fa-f](_?[0-9A-Fa-f])*$`)
+	_octPattern      = regexp.MustCompile(`^0o[0-7](_?[0-7])*$`)
+	_binPattern      = regexp.MustCompile(`^0b[01](_?[01])*$`)
+	_floatPattern    = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][-+]?[0-9](_?[0-9])*)?$`)
+	_specialPattern  = regexp.MustCompile(`^[-+]?(inf|nan)$`)
+	_datetimePattern = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2})(?:[Tt ]([0-9]{2}:[0-9]{2}:[0-9]{2}(?:\.[0-9]+)?)([Zz]|[-+][0-9]{2}:[0-9]{2})?)?$`)
+	_timePattern     = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?$`)
+	_bareKeyPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+type parser struct {
+	src  []byte
+	pos  int
+	line int
+	// depth is the nesting depth of arrays and inline tables.
+	depth int
+	root  *node
+	// current is the table which key/value pairs are added to.
+	current *node
+}
+
+// parse parses a TOML document into a table node.
+func parse(data []byte) (*node, error) {
+	p := &parser{src: data, line: 1, root: newTable(explicitOrigin, 1)}
+	p.current = p.root
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if err := p.document(); err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Line: p.line, msg: fmt.Sprintf(format, args...)}
+}
+
+// validate checks that the document is valid UTF-8.
+func (p *parser) validate() error {
+	line := 1
+	for i := 0; i < len(p.src); {
+		r, size := utf8.DecodeRune(p.src[i:])
+		if r == utf8.RuneError && size == 1 {
+			return &SyntaxError{Line: line, msg: "invalid UTF-8"}
+		}
+		if r == '\n' {
+			line++
+		}
+		i += size
+	}
+	return nil
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return bytes.HasPrefix(p.src[p.pos:], []byte(s))
+}
+
+// space skips spaces and tabs.
+func (p *parser) space() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// newline consumes a line break, and reports whether there is one.
+func (p *parser) newline() bool {
+	switch {
+	case p.hasPrefix("\n"):
+		p.pos++
+	case p.hasPrefix("\r\n"):
+		p.pos += 2
+	default:
+		return false
+	}
+	p.line++
+	return true
+}
+
+// comment skips a comment if any.
+func (p *parser) comment() error {
+	if p.peek() != '#' {
+		return nil
+	}
+	for !p.eof() && p.src[p.pos] != '\n' && !p.hasPrefix("\r\n") {
+		if c := p.src[p.pos]; c < 0x20 && c != '\t' || c == 0x7f {
+			return p.errorf("control character %U in comment", rune(c))
+		}
+		p.pos++
+	}
+	return nil
+}
+
+// lineEnd skips trailing spaces and a comment, and consumes the line break.
+func (p *parser) lineEnd() error {
+	p.space()
+	if err := p.comment(); err != nil {
+		return err
+	}
+	if !p.eof() && !p.newline() {
+		return p.errorf("expected end of line, found %q", p.src[p.pos])
+	}
+	return nil
+}
+
+// blank skips spaces, tabs, line breaks and comments, as allowed in arrays.
+func (p *parser) blank() error {
+	for {
+		p.space()
+		if err := p.comment(); err != nil {
+			return err
+		}
+		if !p.newline() {
+			return nil
+		}
+	}
+}
+
+func (p *parser) document() error {
+	for {
+		p.space()
+		if p.eof() {
+			return nil
+		}
+		switch p.peek() {
+		case '#', '\n', '\r':
+		case '[':
+			if err := p.header(); err != nil {
+				return err
+			}
+		default:
+			if err := p.keyValue(p.current); err != nil {
+				return err
+			}
+		}
+		if err := p.lineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+// header parses a table header or an array of tables header.
+func (p *parser) header() error {
+	line := p.line
+	array := p.hasPrefix("[[")
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	p.space()
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.space()
+	if array {
+		if !p.hasPrefix("]]") {
+			return p.errorf("expected ]] of array of tables header")
+		}
+		p.pos += 2
+	} else {
+		if !p.hasPrefix("]") {
+			return p.errorf("expected ] of table header")
+		}
+		p.pos++
+	}
+	t := p.root
+	for i, key := range keys[:len(keys)-1] {
+		child, ok := t.members[key]
+		if !ok {
+			child = newTable(implicitOrigin, line)
+			t.set(key, child)
+		}
+		switch {
+		case child.kind == tableNode && child.origin != inlineOrigin:
+		case child.kind == arrayNode && child.origin == tablesOrigin:
+			child = child.items[len(child.items)-1]
+		default:
+			return p.errorf("key %s is already defined as %s", formatKeys(keys[:i+1]), child.kind)
+		}
+		t = child
+	}
+	last := keys[len(keys)-1]
+	existing, ok := t.members[last]
+	if array {
+		if !ok {
+			existing = &node{kind: arrayNode, origin: tablesOrigin, line: line}
+			t.set(last, existing)
+		} else if existing.kind != arrayNode || existing.origin != tablesOrigin {
+			return p.errorf("key %s is already defined as %s", formatKeys(keys), existing.kind)
+		}
+		p.current = newTable(explicitOrigin, line)
+		existing.items = append(existing.items, p.current)
+		return nil
+	}
+	if !ok {
+		p.current = newTable(explicitOrigin, line)
+		t.set(last, p.current)
+		return nil
+	}
+	if existing.kind != tableNode || existing.origin != implicitOrigin {
+		return p.errorf("table %s is already defined", formatKeys(keys))
+	}
+	existing.origin = explicitOrigin
+	p.current = existing
+	return nil
+}
+
+// keyValue parses a key/value pair and adds it to table t.
+func (p *parser) keyValue(t *node) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.space()
+	if p.peek() != '=' {
+		return p.errorf("expected = after key %s", formatKeys(keys))
+	}
+	p.pos++
+	p.space()
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	for i, key := range keys[:len(keys)-1] {
+		child, ok := t.members[key]
+		if !ok {
+			child = newTable(dottedOrigin, value.line)
+			t.set(key, child)
+		} else if child.kind != tableNode || child.origin != dottedOrigin {
+			return &SyntaxError{Line: value.line, msg: fmt.Sprintf("key %s is already defined", formatKeys(keys[:i+1]))}
+		}
+		t = child
+	}
+	last := keys[len(keys)-1]
+	if _, ok := t.members[last]; ok {
+		return &SyntaxError{Line: value.line, msg: fmt.Sprintf("key %s is already defined", formatKeys(keys))}
+	}
+	t.set(last, value)
+	return nil
+}
+
+// key parses a simple or dotted key.
+func (p *parser) key() ([]string, error) {
+	var keys []string
+	for {
+		var key string
+		switch p.peek() {
+		case '"':
+			if p.hasPrefix(`"""`) {
+				return nil, p.errorf("multi-line string can not be a key")
+			}
+			s, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case '\'':
+			if p.hasPrefix("'''") {
+				return nil, p.errorf("multi-line string can not be a key")
+			}
+			s, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.src[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				if p.eof() || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+					return nil, p.errorf("expected key")
+				}
+				return nil, p.errorf("invalid character %q in key", p.src[p.pos])
+			}
+			key = string(p.src[start:p.pos])
+		}
+		keys = append(keys, key)
+		if p.depth+len(keys) > maxDepth {
+			return nil, p.errorf("exceeded max depth of %d", maxDepth)
+		}
+		p.space()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+		p.space()
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// formatKeys formats keys as a dotted key for messages and output.
+func formatKeys(keys []string) string {
+	var b strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		if _bareKeyPattern.MatchString(key) {
+			b.WriteString(key)
+		} else {
+			b.WriteString(quote(key))
+		}
+	}
+	return b.String()
+}
+
+func (p *parser) value() (*node, error) {
+	line := p.line
+	switch {
+	case p.hasPrefix(`"""`):
+		s, err := p.multiLineBasicString()
+		return &node{kind: stringNode, text: s, line: line}, err
+	case p.hasPrefix(`"`):
+		s, err := p.basicString()
+		return &node{kind: stringNode, text: s, line: line}, err
+	case p.hasPrefix("'''"):
+		s, err := p.multiLineLiteralString()
+		return &node{kind: stringNode, text: s, line: line}, err
+	case p.hasPrefix("'"):
+		s, err := p.literalString()
+		return &node{kind: stringNode, text: s, line: line}, err
+	case p.hasPrefix("["):
+		return p.array()
+	case p.hasPrefix("{"):
+		return p.inlineTable()
+	}
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	// A space can separate the date and the time of a datetime.
+	if p.pos-start == 10 && p.pos+3 < len(p.src) && p.src[p.pos] == ' ' &&
+		isDigit(p.src[p.pos+1]) && isDigit(p.src[p.pos+2]) && p.src[p.pos+3] == ':' {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.src[p.pos])) {
+			p.pos++
+		}
+	}
+	token := string(p.src[start:p.pos])
+	if token == "" {
+		if p.eof() || p.peek() == '\n' || p.peek() == '\r' {
+			return nil, p.errorf("expected value")
+		}
+		return nil, p.errorf("invalid character %q at start of value", p.peek())
+	}
+	n, err := scalar(token)
+	if err != nil {
+		return nil, p.errorf("%s", err)
+	}
+	n.line = line
+	return n, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// scalar parses a boolean, a number or a datetime.
+func scalar(token string) (*node, error) {
+	switch token {
+	case "true", "false":
+		return &node{kind: boolNode, b: token == "true", text: token}, nil
+	}
+	if _specialPattern.MatchString(token) {
+		f := math.NaN()
+		if strings.HasSuffix(token, "inf") {
+			f = math.Inf(1)
+			if token[0] == '-' {
+				f = math.Inf(-1)
+			}
+		}
+		return &node{kind: floatNode, f: f, text: strings.TrimPrefix(token, "+")}, nil
+	}
+	if m := _datetimePattern.FindStringSubmatch(token); m != nil {
+		return datetime(m)
+	}
+	if _timePattern.MatchString(token) {
+		if _, err := time.Parse(localTimeLayout, token); err != nil {
+			return nil, fmt.Errorf("invalid time %s", token)
+		}
+		return &node{kind: datetimeNode, layout: localTimeLayout, text: token}, nil
+	}
+	digits := strings.Replace(token, "_", "", -1)
+	base := 0
+	switch {
+	case _decimalPattern.MatchString(token):
+		base = 10
+	case _hexPattern.MatchString(token):
+		digits, base = digits[2:], 16
+	case _octPattern.MatchString(token):
+		digits, base = digits[2:], 8
+	case _binPattern.MatchString(token):
+		digits, base = digits[2:], 2
+	case _floatPattern.MatchString(token):
+		f, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return nil, fmt.Errorf("float %s out of range", token)
+		}
+		return &node{kind: floatNode, f: f, text: strings.TrimPrefix(digits, "+")}, nil
+	default:
+		return nil, fmt.Errorf("invalid value %s", token)
+	}
+	i, err := strconv.ParseInt(digits, base, 64)
+	if err != nil {
+		return nil, fmt.Errorf("integer %s out of range", token)
+	}
+	return &node{kind: integerNode, i: i, text: strconv.FormatInt(i, 10)}, nil
+}
+
+// datetime makes a node of a date, a local datetime or an offset datetime
+// from the submatches of _datetimePattern.
+func datetime(m []string) (*node, error) {
+	text, layout := m[1], localDateLayout
+	if m[2] != "" {
+		text, layout = m[1]+"T"+m[2], localDateTimeLayout
+		if m[3] != "" {
+			text, layout = text+strings.ToUpper(m[3]), offsetDateTimeLayout
+		}
+	}
+	if _, err := time.Parse(layout, text); err != nil {
+		return nil, fmt.Errorf("invalid datetime %s", m[0])
+	}
+	return &node{kind: datetimeNode, layout: layout, text: text}, nil
+}
+
+func (p *parser) array() (*node, error) {
+	n := &node{kind: arrayNode, origin: inlineOrigin, line: p.line}
+	if p.depth++; p.depth > maxDepth {
+		return nil, p.errorf("exceeded max depth of %d", maxDepth)
+	}
+	p.pos++
+	for {
+		if err := p.blank(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' {
+			break
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		n.items = append(n.items, item)
+		if err := p.blank(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.peek() != ']' {
+			return nil, p.errorf("expected , or ] in array")
+		}
+		break
+	}
+	p.pos++
+	p.depth--
+	return n, nil
+}
+
+func (p *parser) inlineTable() (*node, error) {
+	n := newTable(dottedOrigin, p.line)
+	if p.depth++; p.depth > maxDepth {
+		return nil, p.errorf("exceeded max depth of %d", maxDepth)
+	}
+	p.pos++
+	p.space()
+	if p.peek() != '}' {
+		for {
+			if err := p.keyValue(n); err != nil {
+				return nil, err
+			}
+			p.space()
+			if p.peek() == '}' {
+				break
+			}
+			if p.peek() != ',' {
+				return nil, p.errorf("expected , or } in inline table")
+			}
+			p.pos++
+			p.space()
+		}
+	}
+	p.pos++
+	p.depth--
+	n.origin = inlineOrigin
+	return n, nil
+}
+
+// basicString parses a basic string.
+func (p *parser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		case c == '\n' || c == '\r':
+			return "", p.errorf("unterminated string")
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", p.errorf("control character %U in string", rune(c))
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// multiLineBasicString parses a multi-line basic string.
+func (p *parser) multiLineBasicString() (string, error) {
+	p.pos += 3
+	p.newline()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case p.hasPrefix(`"""`):
+			// Up to two quotes can precede the closing delimiter.
+			for i := 0; i < 2 && p.hasPrefix(`""""`); i++ {
+				b.WriteByte('"')
+				p.pos++
+			}
+			p.pos += 3
+			return b.String(), nil
+		case c == '\\':
+			// A line ending backslash trims following white space.
+			q := p.pos + 1
+			for q < len(p.src) && (p.src[q] == ' ' || p.src[q] == '\t') {
+				q++
+			}
+			if q < len(p.src) && (p.src[q] == '\n' || p.src[q] == '\r') {
+				p.pos = q
+				if !p.newline() {
+					return "", p.errorf("control character %U in string", '\r')
+				}
+				for {
+					p.space()
+					if !p.newline() {
+						break
+					}
+				}
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		case c == '\n' || c == '\r':
+			if !p.newline() {
+				return "", p.errorf("control character %U in string", '\r')
+			}
+			b.WriteByte('\n')
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", p.errorf("control character %U in string", rune(c))
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+var _escapes = map[byte]byte{
+	'b':  '\b',
+	't':  '\t',
+	'n':  '\n',
+	'f':  '\f',
+	'r':  '\r',
+	'"':  '"',
+	'\\': '\\',
+}
+
+// escape parses an escape sequence.
+func (p *parser) escape(b *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	if r, ok := _escapes[c]; ok {
+		b.WriteByte(r)
+		return nil
+	}
+	size := 0
+	switch c {
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	if p.pos+size > len(p.src) {
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	hex := string(p.src[p.pos : p.pos+size])
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || code > utf8.MaxRune || 0xD800 <= code && code <= 0xDFFF {
+		return p.errorf("invalid escape sequence \\%c%s", c, hex)
+	}
+	p.pos += size
+	b.WriteRune(rune(code))
+	return nil
+}
+
+// literalString parses a literal string.
+func (p *parser) literalString() (string, error) {
+	p.pos++
+	start := p.pos
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '\'':
+			s := string(p.src[start:p.pos])
+			p.pos++
+			return s, nil
+		case c == '\n' || c == '\r':
+			return "", p.errorf("unterminated string")
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", p.errorf("control character %U in string", rune(c))
+		}
+		p.pos++
+	}
+}
+
+// multiLineLiteralString parses a multi-line literal string.
+func (p *parser) multiLineLiteralString() (string, error) {
+	p.pos += 3
+	p.newline()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case p.hasPrefix("'''"):
+			for i := 0; i < 2 && p.hasPrefix("''''"); i++ {
+				b.WriteByte('\'')
+				p.pos++
+			}
+			p.pos += 3
+			return b.String(), nil
+		case c == '\n' || c == '\r':
+			if !p.newline() {
+				return "", p.errorf("control character %U in string", '\r')
+			}
+			b.WriteByte('\n')
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", p.errorf("control character %U in string", rune(c))
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package toml
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeAny(t *testing.T, src string) map[string]interface{} {
+	t.Helper()
+	root, err := parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &decoder{config: &DecoderConfig{Location: time.UTC}}
+	v, err := d.any(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.(map[string]interface{})
+}
+
+type m = map[string]interface{}
+type s = []interface{}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want m
+	}{
+		{name: "empty", src: "", want: m{}},
+		{name: "comments", src: "# c\n\n  a = 1 # c\n", want: m{"a": int64(1)}},
+		{name: "crlf", src: "a = 1\r\nb = 2\r\n", want: m{"a": int64(1), "b": int64(2)}},
+		{name: "keys", src: "bare_key-1 = 1\n\"quoted key\" = 2\n'literal' = 3\n\"\" = 4\n1234 = 5\n",
+			want: m{"bare_key-1": int64(1), "quoted key": int64(2), "literal": int64(3), "": int64(4), "1234": int64(5)}},
+		{name: "dotted keys", src: "a.b = 1\na . c = 2\n\"x.y\".z = 3\n3.14 = 4\n",
+			want: m{"a": m{"b": int64(1), "c": int64(2)}, "x.y": m{"z": int64(3)}, "3": m{"14": int64(4)}}},
+		{name: "basic string", src: `a = "tab\tquote\"back\\\u00e9\U0001F600\b\f\r"`,
+			want: m{"a": "tab\tquote\"back\\é😀\b\f\r"}},
+		{name: "literal string", src: `a = 'C:\path "x"'`, want: m{"a": `C:\path "x"`}},
+		{name: "multi-line basic", src: "a = \"\"\"\nRoses\n  \"Violets\"\"\"\"\n", want: m{"a": "Roses\n  \"Violets\""}},
+		{name: "line ending backslash", src: "a = \"\"\"\\\n  one \\  \n\n  two\\\n  \"\"\"", want: m{"a": "one two"}},
+		{name: "multi-line literal", src: "a = '''\nx\\n\n'y'''''\n", want: m{"a": "x\\n\n'y''"}},
+		{name: "integers", src: "a = +99\nb = -17\nc = 0\nd = 1_000\ne = 0xDEAD_beef\nf = 0o755\ng = 0b1101\nh = -9223372036854775808\n",
+			want: m{"a": int64(99), "b": int64(-17), "c": int64(0), "d": int64(1000), "e": int64(0xdeadbeef), "f": int64(0755), "g": int64(13), "h": int64(math.MinInt64)}},
+		{name: "floats", src: "a = +1.0\nb = -0.01\nc = 5e+22\nd = 6.626e-34\ne = 224_617.445_991\nf = 1e06\n",
+			want: m{"a": 1.0, "b": -0.01, "c": 5e22, "d": 6.626e-34, "e": 224617.445991, "f": 1e6}},
+		{name: "booleans", src: "a = true\nb = false\n", want: m{"a": true, "b": false}},
+		{name: "datetimes", src: "a = 1979-05-27T07:32:00Z\nb = 1979-05-27 00:32:00.5-07:00\nc = 1979-05-27t07:32:00\nd = 1979-05-27\ne = 07:32:00.25\n",
+			want: m{
+				"a": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+				"b": time.Date(1979, 5, 27, 0, 32, 0, 5e8, time.FixedZone("", -7*3600)),
+				"c": LocalDateTime{time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)},
+				"d": LocalDate{time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC)},
+				"e": LocalTime{time.Date(0, 1, 1, 7, 32, 0, 25e7, time.UTC)},
+			}},
+		{name: "arrays", src: "a = [1, 'x', [2.5, []], {b = 1}]\nb = [\n  1, # c\n  2,\n]\n",
+			want: m{"a": s{int64(1), "x", s{2.5, s{}}, m{"b": int64(1)}}, "b": s{int64(1), int64(2)}}},
+		{name: "inline tables", src: "a = { x = 1, y.z = 2, w = {} }\nb = {}\n",
+			want: m{"a": m{"x": int64(1), "y": m{"z": int64(2)}, "w": m{}}, "b": m{}}},
+		{name: "tables", src: "a = 1\n[t]\nb = 2\n[ t . u ]\nc = 3\n[x.y.z]\n[x]\nd = 4\n",
+			want: m{"a": int64(1), "t": m{"b": int64(2), "u": m{"c": int64(3)}}, "x": m{"y": m{"z": m{}}, "d": int64(4)}}},
+		{name: "sub-table of dotted", src: "[fruit]\napple.color = 'red'\n[fruit.apple.texture]\nsmooth = true\n",
+			want: m{"fruit": m{"apple": m{"color": "red", "texture": m{"smooth": true}}}}},
+		{name: "arrays of tables", src: "[[p]]\na = 1\n[p.q]\nb = 2\n[[p.r]]\n[[p]]\n[[p]]\nc.d = 3\n",
+			want: m{"p": s{m{"a": int64(1), "q": m{"b": int64(2)}, "r": s{m{}}}, m{}, m{"c": m{"d": int64(3)}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeAny(t, tt.src)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Special(t *testing.T) {
+	got := decodeAny(t, "a = inf\nb = -inf\nc = +nan\nd = -0.0\n")
+	if !math.IsInf(got["a"].(float64), 1) || !math.IsInf(got["b"].(float64), -1) || !math.IsNaN(got["c"].(float64)) {
+		t.Errorf("unexpected special floats %v", got)
+	}
+	if d := got["d"].(float64); d != 0 || !math.Signbit(d) {
+		t.Errorf("got %v, want -0", d)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		msg  string
+	}{
+		{name: "duplicate key", src: "a = 1\na = 2\n", line: 2, msg: "key a is already defined"},
+		{name: "duplicate table", src: "[a]\n[b]\n[a]\n", line: 3, msg: "table a is already defined"},
+		{name: "table of dotted", src: "[a]\nb.c = 1\n[a.b]\n", line: 3, msg: "table a.b is already defined"},
+		{name: "dotted into table", src: "[a.b.c]\n[a]\nb.c.d = 1\n", line: 3, msg: "key b is already defined"},
+		{name: "extend inline table", src: "a = {}\n[a.b]\n", line: 2, msg: "key a is already defined as table"},
+		{name: "extend static array", src: "a = []\n[[a]]\n", line: 2, msg: "key a is already defined as array"},
+		{name: "table as array", src: "[a]\n[[a]]\n", line: 2, msg: "key a is already defined as table"},
+		{name: "key as table", src: "a = 1\n[a.b]\n", line: 2, msg: "key a is already defined as integer"},
+		{name: "inline dotted into value", src: "a = {b = 1, b.c = 2}\n", line: 1, msg: "key b is already defined"},
+		{name: "missing value", src: "a =\n", line: 1, msg: "expected value"},
+		{name: "missing equals", src: "a 1\n", line: 1, msg: "expected = after key a"},
+		{name: "two pairs", src: "a = 1 b = 2\n", line: 1, msg: "expected end of line"},
+		{name: "invalid value", src: "a = yes\n", line: 1, msg: "invalid value yes"},
+		{name: "leading zero", src: "a = 01\n", line: 1, msg: "invalid value 01"},
+		{name: "double underscore", src: "a = 1__0\n", line: 1, msg: "invalid value 1__0"},
+		{name: "bare dot float", src: "a = .5\n", line: 1, msg: "invalid value .5"},
+		{name: "integer overflow", src: "\na = 9223372036854775808\n", line: 2, msg: "integer 9223372036854775808 out of range"},
+		{name: "invalid date", src: "a = 2021-02-30\n", line: 1, msg: "invalid datetime 2021-02-30"},
+		{name: "invalid time", src: "a = 24:00:00\n", line: 1, msg: "invalid time 24:00:00"},
+		{name: "unterminated string", src: "a = \"x\nb = 1\n", line: 1, msg: "unterminated string"},
+		{name: "unterminated multi-line", src: "a = '''\nx\n", line: 3, msg: "unterminated string"},
+		{name: "bad escape", src: `a = "\x41"`, line: 1, msg: `invalid escape sequence \x`},
+		{name: "surrogate escape", src: `a = "\uD800"`, line: 1, msg: `invalid escape sequence \uD800`},
+		{name: "control character", src: "a = \"\x01\"", line: 1, msg: "control character U+0001 in string"},
+		{name: "control in comment", src: "# \x7f\n", line: 1, msg: "control character U+007F in comment"},
+		{name: "multi-line key", src: "\"\"\"a\"\"\" = 1\n", line: 1, msg: "multi-line string can not be a key"},
+		{name: "invalid key", src: "a! = 1\n", line: 1, msg: "expected = after key a"},
+		{name: "empty key", src: "= 1\n", line: 1, msg: "invalid character '=' in key"},
+		{name: "unclosed header", src: "[a\n", line: 1, msg: "expected ] of table header"},
+		{name: "unclosed array header", src: "[[a]\n", line: 1, msg: "expected ]] of array of tables header"},
+		{name: "unclosed array", src: "a = [1,\n2\n", line: 3, msg: "expected , or ] in array"},
+		{name: "trailing comma in inline table", src: "a = {b = 1,}\n", line: 1, msg: "invalid character '}' in key"},
+		{name: "newline in inline table", src: "a = {b = 1\n}\n", line: 1, msg: "expected , or } in inline table"},
+		{name: "lone cr", src: "a = 1\rb = 2\n", line: 1, msg: "expected end of line"},
+		{name: "invalid utf-8", src: "a = 1\nb = \"\xff\"\n", line: 2, msg: "invalid UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse([]byte(tt.src))
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("want *SyntaxError, got %v", err)
+			}
+			if se.Line != tt.line || !strings.HasPrefix(se.msg, tt.msg) {
+				t.Errorf("got %v, want line %d: %s", err, tt.line, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParse_MaxDepth(t *testing.T) {
+	dotted := strings.Repeat("a.", maxDepth)
+	for _, src := range []string{
+		"a = " + strings.Repeat("[", maxDepth+1),
+		dotted + "b = 1",
+		"[" + dotted + "b]",
+		"[[" + dotted + "b]]",
+		"a = {" + dotted[2:] + "b = {c = 1}}",
+	} {
+		if _, err := parse([]byte(src)); err == nil || !strings.Contains(err.Error(), "max depth") {
+			t.Errorf("%.20q: want error of exceeding max depth, got %v", src, err)
+		}
+	}
+	if _, err := parse([]byte(dotted[2:] + "b = 1")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,78 @@
+// Package toml defines and registers Marshaler/Unmarshaler handling TOML
+// (v1.0.0) content.
+//
+// Values are mapped to TOML the way encoding/json maps them to JSON: structs
+// are encoded as tables keyed by field names, taken from the "toml" tag.
+// time.Time is encoded as an offset datetime, and all kinds of datetimes are
+// decoded into time.Time, those without offset in DecoderConfig.Location.
+// LocalDateTime, LocalDate and LocalTime keep the kinds of datetimes without
+// offset, and are what they are decoded into as interface{} values, so that
+// they are encoded back as they are.
+// Slices of structs or maps are encoded as arrays of tables. Nil values are
+// omitted since TOML has no null, and the top-level value must be a table.
+package toml
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "toml"
+
+// MIMEType is the media type of TOML.
+const MIMEType = "application/toml"
+
+var _tags = []string{"toml"}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	if config.Indent < 0 {
+		return nil, fmt.Errorf("toml: invalid indent %d", config.Indent)
+	}
+	e := &encoder{config: config}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("toml: can not unmarshal to non-pointer or nil %T", v)
+	}
+	root, err := parse(data)
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.unmarshal(root, rv.Elem())
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
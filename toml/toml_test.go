@@ -0,0 +1,97 @@
+package toml
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+type database struct {
+	Enabled bool              `toml:"enabled"`
+	Ports   []uint16          `toml:"ports"`
+	Limits  map[string]uint32 `toml:"limits"`
+}
+
+type config struct {
+	Title    string        `toml:"title"`
+	Version  int           `toml:"version,omitempty"`
+	Ratio    float64       `toml:"ratio"`
+	Updated  time.Time     `toml:"updated"`
+	Database *database     `toml:"database"`
+	Servers  []server      `toml:"servers"`
+	Timeout  time.Duration `toml:"timeout"`
+	Skipped  string        `toml:"-"`
+}
+
+func TestCodec_Struct(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := config{
+		Title:    "service",
+		Ratio:    0.5,
+		Updated:  time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC),
+		Database: &database{Enabled: true, Ports: []uint16{5432}, Limits: map[string]uint32{"max": 10}},
+		Servers:  []server{{Host: "a", Port: 1, Tags: []string{"x"}}, {Host: "b"}},
+		Timeout:  time.Second,
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `title = "service"
+ratio = 0.5
+updated = 2021-06-01T08:00:00Z
+timeout = 1000000000
+
+[database]
+enabled = true
+ports = [5432]
+
+[database.limits]
+max = 10
+
+[[servers]]
+host = "a"
+port = 1
+timeout = 0
+Ratio = 0.0
+ip = ""
+started = 0001-01-01T00:00:00Z
+tags = ["x"]
+
+[[servers]]
+host = "b"
+port = 0
+timeout = 0
+Ratio = 0.0
+ip = ""
+started = 0001-01-01T00:00:00Z
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+	var out config
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestCodec_Unmarshal_NonPointer(t *testing.T) {
+	var v config
+	if err := (&codec{}).Unmarshal(context.Background(), []byte("title = 'x'"), v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package encoding
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Kind is the kind of a Value.
+type Kind uint8
+
+// Kinds of Value.
+const (
+	NullKind Kind = iota
+	BoolKind
+	NumberKind
+	StringKind
+	ArrayKind
+	ObjectKind
+)
+
+var _kindNames = [...]string{
+	NullKind:   "null",
+	BoolKind:   "bool",
+	NumberKind: "number",
+	StringKind: "string",
+	ArrayKind:  "array",
+	ObjectKind: "object",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	if int(k) < len(_kindNames) {
+		return _kindNames[k]
+	}
+	return "kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Value is a node of the format-neutral document tree. Codecs which support
+// the document model decode data into a *Value and encode a *Value back to
+// their own format, so that a Value can be used to transcode, query or diff
+// documents without knowing the format they come from.
+//
+// Which fields are meaningful depends on the Kind of the Value:
+//   - NullKind: none.
+//   - BoolKind: Bool.
+//   - NumberKind: Text, the literal text of the number. Numbers keep their
+//     text as-is so no precision is lost.
+//   - StringKind: Text.
+//   - ArrayKind: Items.
+//   - ObjectKind: Members, in the order they appear. Keys are not required to
+//     be unique, since some formats (e.g. XML) allow repeated keys.
+//
+// Space and Attrs carry the namespace and attributes of an XML element. They
+// are ignored by formats which have no such concept.
+type Value struct {
+	Kind    Kind
+	Bool    bool
+	Text    string
+	Items   []*Value
+	Members []*Member
+	Space   string
+	Attrs   []Attr
+}
+
+// Member is a key/value pair of an object Value.
+//
+// Comment is the comment preceding the member, without comment markers, for
+// formats which keep comments (e.g. INI). Lines of a comment are separated by
+// "\n". Comments are ignored by Equal.
+type Member struct {
+	Key     string
+	Value   *Value
+	Comment string
+}
+
+// Attr is an attribute of an XML element.
+type Attr struct {
+	Space string
+	Name  string
+	Value string
+}
+
+// NewNull returns a null Value.
+func NewNull() *Value {
+	return &Value{Kind: NullKind}
+}
+
+// NewBool returns a bool Value.
+func NewBool(b bool) *Value {
+	return &Value{Kind: BoolKind, Bool: b}
+}
+
+// NewNumber returns a number Value with the literal text of the number.
+// The text is not validated.
+func NewNumber(text string) *Value {
+	return &Value{Kind: NumberKind, Text: text}
+}
+
+// NewString returns a string Value.
+func NewString(s string) *Value {
+	return &Value{Kind: StringKind, Text: s}
+}
+
+// NewArray returns an array Value holding items.
+func NewArray(items ...*Value) *Value {
+	return &Value{Kind: ArrayKind, Items: items}
+}
+
+// NewObject returns an object Value holding members.
+func NewObject(members ...*Member) *Value {
+	return &Value{Kind: ObjectKind, Members: members}
+}
+
+// Len returns the number of items of an array Value, or the number of members
+// of an object Value. For other kinds, 0 is returned.
+func (v *Value) Len() int {
+	if v == nil {
+		return 0
+	}
+	switch v.Kind {
+	case ArrayKind:
+		return len(v.Items)
+	case ObjectKind:
+		return len(v.Members)
+	}
+	return 0
+}
+
+// Index returns the i-th item of an array Value.
+// If v is not an array or i is out of range, nil will be returned.
+func (v *Value) Index(i int) *Value {
+	if v == nil || v.Kind != ArrayKind || i < 0 || i >= len(v.Items) {
+		return nil
+	}
+	return v.Items[i]
+}
+
+// Get returns the value of the first member with the key of an object Value.
+// If v is not an object or no such member exists, nil will be returned.
+func (v *Value) Get(key string) *Value {
+	if v == nil || v.Kind != ObjectKind {
+		return nil
+	}
+	for _, m := range v.Members {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return nil
+}
+
+// GetAll returns values of all members with the key of an object Value.
+func (v *Value) GetAll(key string) []*Value {
+	if v == nil || v.Kind != ObjectKind {
+		return nil
+	}
+	var values []*Value
+	for _, m := range v.Members {
+		if m.Key == key {
+			values = append(values, m.Value)
+		}
+	}
+	return values
+}
+
+// Keys returns the keys of an object Value in order.
+func (v *Value) Keys() []string {
+	if v == nil || v.Kind != ObjectKind {
+		return nil
+	}
+	keys := make([]string, 0, len(v.Members))
+	for _, m := range v.Members {
+		keys = append(keys, m.Key)
+	}
+	return keys
+}
+
+// Set replaces the value of the first member with the key of an object Value,
+// or appends a new member if no such member exists. Set does nothing if v is
+// not an object.
+func (v *Value) Set(key string, value *Value) {
+	if v == nil || v.Kind != ObjectKind {
+		return
+	}
+	for _, m := range v.Members {
+		if m.Key == key {
+			m.Value = value
+			return
+		}
+	}
+	v.Members = append(v.Members, &Member{Key: key, Value: value})
+}
+
+// Delete removes all members with the key of an object Value.
+func (v *Value) Delete(key string) {
+	if v == nil || v.Kind != ObjectKind {
+		return
+	}
+	members := v.Members[:0]
+	for _, m := range v.Members {
+		if m.Key != key {
+			members = append(members, m)
+		}
+	}
+	for i := len(members); i < len(v.Members); i++ {
+		v.Members[i] = nil
+	}
+	v.Members = members
+}
+
+// Append appends items to an array Value. Append does nothing if v is not an
+// array.
+func (v *Value) Append(items ...*Value) {
+	if v == nil || v.Kind != ArrayKind {
+		return
+	}
+	v.Items = append(v.Items, items...)
+}
+
+// Float64 returns the number of a number Value as float64.
+func (v *Value) Float64() (float64, error) {
+	if v == nil || v.Kind != NumberKind {
+		return 0, fmt.Errorf("encoding: Float64 of non-number value")
+	}
+	return strconv.ParseFloat(v.Text, 64)
+}
+
+// Int64 returns the number of a number Value as int64.
+func (v *Value) Int64() (int64, error) {
+	if v == nil || v.Kind != NumberKind {
+		return 0, fmt.Errorf("encoding: Int64 of non-number value")
+	}
+	return strconv.ParseInt(v.Text, 10, 64)
+}
+
+// Equal reports whether v and o are deeply equal. Numbers are compared by their
+// literal text, members of objects are compared in order, ignoring comments.
+// A nil Value is treated as equal to a null Value.
+func (v *Value) Equal(o *Value) bool {
+	if v == nil || o == nil {
+		return v.kind() == NullKind && o.kind() == NullKind &&
+			len(v.attrs()) == 0 && len(o.attrs()) == 0
+	}
+	if v.Kind != o.Kind || v.Space != o.Space || len(v.Attrs) != len(o.Attrs) {
+		return false
+	}
+	for i := range v.Attrs {
+		if v.Attrs[i] != o.Attrs[i] {
+			return false
+		}
+	}
+	switch v.Kind {
+	case BoolKind:
+		return v.Bool == o.Bool
+	case NumberKind, StringKind:
+		return v.Text == o.Text
+	case ArrayKind:
+		if len(v.Items) != len(o.Items) {
+			return false
+		}
+		for i := range v.Items {
+			if !v.Items[i].Equal(o.Items[i]) {
+				return false
+			}
+		}
+	case ObjectKind:
+		if len(v.Members) != len(o.Members) {
+			return false
+		}
+		for i := range v.Members {
+			if v.Members[i].Key != o.Members[i].Key || !v.Members[i].Value.Equal(o.Members[i].Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Clone returns a deep copy of v.
+func (v *Value) Clone() *Value {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	if v.Items != nil {
+		c.Items = make([]*Value, len(v.Items))
+		for i, item := range v.Items {
+			c.Items[i] = item.Clone()
+		}
+	}
+	if v.Members != nil {
+		c.Members = make([]*Member, len(v.Members))
+		for i, m := range v.Members {
+			c.Members[i] = &Member{Key: m.Key, Value: m.Value.Clone(), Comment: m.Comment}
+		}
+	}
+	if v.Attrs != nil {
+		c.Attrs = append([]Attr(nil), v.Attrs...)
+	}
+	return &c
+}
+
+func (v *Value) kind() Kind {
+	if v == nil {
+		return NullKind
+	}
+	return v.Kind
+}
+
+func (v *Value) attrs() []Attr {
+	if v == nil {
+		return nil
+	}
+	return v.Attrs
+}
@@ -0,0 +1,95 @@
+package encoding
+
+import (
+	"testing"
+)
+
+func TestValue_Object(t *testing.T) {
+	v := NewObject(
+		&Member{Key: "b", Value: NewNumber("1")},
+		&Member{Key: "a", Value: NewString("x")},
+	)
+	v.Set("c", NewBool(true))
+	v.Set("b", NewNumber("2"))
+	if got, want := v.Keys(), []string{"b", "a", "c"}; len(got) != len(want) || got[0] != want[0] ||
+		got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if n, err := v.Get("b").Int64(); err != nil || n != 2 {
+		t.Errorf("Get(b).Int64() = %v, %v, want 2", n, err)
+	}
+	if v.Get("missing") != nil {
+		t.Errorf("Get(missing) expect nil")
+	}
+	v.Members = append(v.Members, &Member{Key: "a", Value: NewString("y")})
+	if got := v.GetAll("a"); len(got) != 2 || got[1].Text != "y" {
+		t.Errorf("GetAll(a) = %v", got)
+	}
+	v.Delete("a")
+	if v.Len() != 2 || v.Get("a") != nil {
+		t.Errorf("Delete(a) left %v", v.Keys())
+	}
+}
+
+func TestValue_Array(t *testing.T) {
+	v := NewArray(NewNull())
+	v.Append(NewNumber("1.50"))
+	if v.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", v.Len())
+	}
+	if f, err := v.Index(1).Float64(); err != nil || f != 1.5 {
+		t.Errorf("Index(1).Float64() = %v, %v", f, err)
+	}
+	if v.Index(1).Text != "1.50" {
+		t.Errorf("number text not kept: %q", v.Index(1).Text)
+	}
+	if v.Index(2) != nil || v.Index(-1) != nil {
+		t.Errorf("Index out of range expect nil")
+	}
+	if _, err := NewString("1").Float64(); err == nil {
+		t.Errorf("Float64 of string expect error")
+	}
+}
+
+func TestValue_EqualClone(t *testing.T) {
+	v := NewObject(
+		&Member{Key: "list", Value: NewArray(NewNumber("1"), NewBool(false), NewNull())},
+		&Member{Key: "obj", Value: &Value{Kind: StringKind, Text: "x", Space: "urn:a", Attrs: []Attr{{Name: "id", Value: "1"}}}, Comment: "c"},
+	)
+	c := v.Clone()
+	if !v.Equal(c) {
+		t.Fatalf("clone not equal")
+	}
+	if c.Members[1].Comment != "c" {
+		t.Errorf("clone lost comment")
+	}
+	c.Members[1].Comment = ""
+	if !v.Equal(c) {
+		t.Errorf("expect equal ignoring comments")
+	}
+	c.Get("obj").Attrs[0].Value = "2"
+	if v.Equal(c) {
+		t.Errorf("expect not equal after modifying attrs of clone")
+	}
+	if v.Get("obj").Attrs[0].Value != "1" {
+		t.Errorf("clone shares attrs with origin")
+	}
+	c = v.Clone()
+	c.Get("list").Items[0] = NewNumber("1.0")
+	if v.Equal(c) {
+		t.Errorf("numbers with different text expect not equal")
+	}
+	var null *Value
+	if !null.Equal(NewNull()) || null.Equal(NewBool(false)) {
+		t.Errorf("nil value expect equal to null only")
+	}
+}
+
+func TestKind_String(t *testing.T) {
+	if ObjectKind.String() != "object" {
+		t.Errorf("got %s", ObjectKind)
+	}
+	if Kind(100).String() != "kind(100)" {
+		t.Errorf("got %s", Kind(100))
+	}
+}
@@ -0,0 +1,388 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+	"github.com/go-kita/encoding/xml"
+)
+
+// DefaultMaxPartSize is the default maximum uncompressed size of a part of
+// the package in bytes, which is read into memory as a whole.
+const DefaultMaxPartSize = 256 << 20
+
+// maxSerial is the serial number after 9999-12-31, the last date of Excel.
+const maxSerial = 2958466
+
+// fromSerial returns the time of a serial number of the 1900 date system,
+// rounded to milliseconds.
+func fromSerial(n float64) (time.Time, error) {
+	if math.IsNaN(n) || n < 0 || n >= maxSerial {
+		return time.Time{}, fmt.Errorf("invalid date serial %v", n)
+	}
+	days := math.Floor(n)
+	clock := time.Duration(math.Round((n-days)*24*60*60*1000)) * time.Millisecond
+	if days < 61 {
+		days++
+	}
+	return epoch.AddDate(0, 0, int(days)).Add(clock), nil
+}
+
+// dateLayouts are the layouts of ISO 8601 dates of cells of the date type.
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02", "15:04:05.999999999"}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// value is the content of a cell.
+type value struct {
+	text string
+	// typ is cellNumber for numbers, cellDate for ISO 8601 dates, and
+	// cellString for the others.
+	typ string
+}
+
+// record is a row of a sheet.
+type record struct {
+	// row is the 1-based number of the row.
+	row    int
+	values []value
+}
+
+type decoder struct {
+	ctx     context.Context
+	files   map[string]*zip.File
+	maxSize int64
+}
+
+func decode(ctx context.Context, data []byte, v interface{}, config *DecoderConfig) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("xlsx: %w", err)
+	}
+	d := &decoder{ctx: ctx, files: make(map[string]*zip.File, len(zr.File)), maxSize: config.MaxPartSize}
+	if d.maxSize <= 0 {
+		d.maxSize = DefaultMaxPartSize
+	}
+	for _, f := range zr.File {
+		d.files[f.Name] = f
+	}
+	records, err := d.sheet(config.Sheet)
+	if err != nil {
+		return err
+	}
+	if vv, ok := v.(*[][]string); ok {
+		rows := make([][]string, len(records))
+		for i, r := range records {
+			rows[i] = make([]string, len(r.values))
+			for j, value := range r.values {
+				rows[i][j] = value.text
+			}
+		}
+		*vv = rows
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("xlsx: can not unmarshal to %T, want pointer to slice", v)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	baseType := elemType
+	for baseType.Kind() == reflect.Ptr {
+		baseType = baseType.Elem()
+	}
+	if baseType.Kind() != reflect.Struct {
+		return fmt.Errorf("xlsx: unsupported type %T", v)
+	}
+	var header []value
+	if len(records) > 0 {
+		header, records = records[0].values, records[1:]
+	}
+	fill := structFiller(baseType, header)
+	result := reflect.MakeSlice(slice.Type(), 0, len(records))
+	for _, r := range records {
+		item := reflect.New(elemType).Elem()
+		base := item
+		for base.Kind() == reflect.Ptr {
+			base.Set(reflect.New(base.Type().Elem()))
+			base = base.Elem()
+		}
+		if err := fill(base, r); err != nil {
+			return err
+		}
+		result = reflect.Append(result, item)
+	}
+	slice.Set(result)
+	return nil
+}
+
+func structFiller(t reflect.Type, header []value) func(reflect.Value, record) error {
+	fs := fields.Of(t, _tags...)
+	columns := make([]int, len(fs))
+	for i, f := range fs {
+		columns[i] = -1
+		for j, name := range header {
+			if name.text == f.Name {
+				columns[i] = j
+				break
+			}
+		}
+		if columns[i] >= 0 {
+			continue
+		}
+		for j, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name.text), f.Name) {
+				columns[i] = j
+				break
+			}
+		}
+	}
+	return func(item reflect.Value, r record) error {
+		for i, f := range fs {
+			column := columns[i]
+			if column < 0 || column >= len(r.values) || r.values[column].text == "" {
+				continue
+			}
+			fv, ok := fields.ByIndex(item, f.Index, true)
+			if !ok {
+				continue
+			}
+			if err := setValue(fv, r.values[column]); err != nil {
+				return &RowError{Row: r.row, Column: column + 1, Field: f.Name, Err: err}
+			}
+		}
+		return nil
+	}
+}
+
+// setValue sets the value of a cell into v, converting serial numbers and ISO
+// 8601 dates into time.Time.
+func setValue(v reflect.Value, value value) error {
+	base := v
+	for base.Kind() == reflect.Ptr {
+		if base.IsNil() {
+			base.Set(reflect.New(base.Type().Elem()))
+		}
+		base = base.Elem()
+	}
+	if base.Type() == _timeType && value.typ != cellString {
+		var t time.Time
+		var err error
+		if value.typ == cellDate {
+			t, err = parseDate(value.text)
+		} else {
+			var n float64
+			if n, err = strconv.ParseFloat(value.text, 64); err == nil {
+				t, err = fromSerial(n)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		base.Set(reflect.ValueOf(t))
+		return nil
+	}
+	return textual.Unmarshal(value.text, v)
+}
+
+// part unmarshals the part at path by the xml codec. Parts larger than the
+// maximum size are rejected before they are read as a whole.
+func (d *decoder) part(path string, v interface{}) error {
+	f, ok := d.files[path]
+	if !ok {
+		return fmt.Errorf("xlsx: missing part %s", path)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("xlsx: %s: %w", path, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, d.maxSize+1))
+	if err != nil {
+		return fmt.Errorf("xlsx: %s: %w", path, err)
+	}
+	if int64(len(data)) > d.maxSize {
+		return fmt.Errorf("xlsx: %s: part larger than %d bytes", path, d.maxSize)
+	}
+	if err := encoding.GetUnmarshaler(xml.Name).Unmarshal(d.ctx, data, v); err != nil {
+		return fmt.Errorf("xlsx: %s: %w", path, err)
+	}
+	return nil
+}
+
+// relationships returns the relationships of the part at path by id, with
+// their targets resolved into paths of the package.
+func (d *decoder) relationships(partPath string) (map[string]relationship, error) {
+	dir, file := path.Split(partPath)
+	var rels relationships
+	if err := d.part(dir+"_rels/"+file+".rels", &rels); err != nil {
+		return nil, err
+	}
+	result := make(map[string]relationship, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			rel.Target = rel.Target[1:]
+		} else {
+			rel.Target = path.Join(dir, rel.Target)
+		}
+		result[rel.ID] = rel
+	}
+	return result, nil
+}
+
+// sheet reads the rows of the sheet of the name, or of the first sheet if the
+// name is empty.
+func (d *decoder) sheet(name string) ([]record, error) {
+	rootRels, err := d.relationships("")
+	if err != nil {
+		return nil, err
+	}
+	bookPath := ""
+	for _, rel := range rootRels {
+		if strings.HasSuffix(rel.Type, relOfficeDocument) {
+			bookPath = rel.Target
+		}
+	}
+	if bookPath == "" {
+		return nil, fmt.Errorf("xlsx: missing workbook")
+	}
+	var book workbook
+	if err := d.part(bookPath, &book); err != nil {
+		return nil, err
+	}
+	rels, err := d.relationships(bookPath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := lookupSheet(book.Sheets, name)
+	if err != nil {
+		return nil, err
+	}
+	rel, ok := rels[s.RelID]
+	if !ok || !strings.HasSuffix(rel.Type, relWorksheet) {
+		return nil, fmt.Errorf("xlsx: missing worksheet of sheet %q", s.Name)
+	}
+	var shared sst
+	for _, rel := range rels {
+		if strings.HasSuffix(rel.Type, relSharedStrings) {
+			if err := d.part(rel.Target, &shared); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var ws worksheet
+	if err := d.part(rel.Target, &ws); err != nil {
+		return nil, err
+	}
+	return readRows(ws.SheetData.Rows, shared.Items)
+}
+
+// lookupSheet returns the sheet of the name, matching case-insensitively as
+// Excel does, or the first sheet if the name is empty.
+func lookupSheet(sheets []sheet, name string) (*sheet, error) {
+	if name == "" {
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("xlsx: no sheet")
+		}
+		return &sheets[0], nil
+	}
+	for i := range sheets {
+		if sheets[i].Name == name {
+			return &sheets[i], nil
+		}
+	}
+	for i := range sheets {
+		if strings.EqualFold(sheets[i].Name, name) {
+			return &sheets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("xlsx: sheet %q not found", name)
+}
+
+// readRows converts rows of a worksheet into records, skipping rows without
+// any value.
+func readRows(rows []row, shared []stringItem) ([]record, error) {
+	var records []record
+	rowNum := 0
+	for _, r := range rows {
+		if r.R > 0 {
+			rowNum = r.R
+		} else {
+			rowNum++
+		}
+		var values []value
+		column := -1
+		for _, c := range r.Cells {
+			if c.Ref != "" {
+				var err error
+				if column, _, err = parseRef(c.Ref); err != nil {
+					return nil, err
+				}
+			} else {
+				column++
+			}
+			v, err := cellValue(c, shared)
+			if err != nil {
+				return nil, &RowError{Row: rowNum, Column: column + 1, Err: err}
+			}
+			if v.text == "" {
+				continue
+			}
+			for len(values) <= column {
+				values = append(values, value{})
+			}
+			values[column] = v
+		}
+		if len(values) > 0 {
+			records = append(records, record{row: rowNum, values: values})
+		}
+	}
+	return records, nil
+}
+
+func cellValue(c cell, shared []stringItem) (value, error) {
+	if c.Value == "" && c.Type != cellInlineString {
+		return value{typ: cellString}, nil
+	}
+	switch c.Type {
+	case cellNumber, cellNumberN:
+		return value{text: c.Value, typ: cellNumber}, nil
+	case cellDate:
+		return value{text: c.Value, typ: cellDate}, nil
+	case cellSharedString:
+		index, err := strconv.Atoi(c.Value)
+		if err != nil || index < 0 || index >= len(shared) {
+			return value{}, fmt.Errorf("invalid shared string index %q", c.Value)
+		}
+		return value{text: shared[index].String(), typ: cellString}, nil
+	case cellInlineString:
+		if c.Inline == nil {
+			return value{typ: cellString}, nil
+		}
+		return value{text: c.Inline.String(), typ: cellString}, nil
+	case cellBool:
+		return value{text: strconv.FormatBool(c.Value == "1"), typ: cellString}, nil
+	case cellString, cellError:
+		return value{text: c.Value, typ: cellString}, nil
+	}
+	return value{}, fmt.Errorf("unknown cell type %q", c.Type)
+}
@@ -0,0 +1,141 @@
+package xlsx
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromSerial(t *testing.T) {
+	tests := []struct {
+		n    float64
+		want time.Time
+	}{
+		{1, time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{59, time.Date(1900, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{61, time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{44348, time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{44348.75, time.Date(2021, 6, 1, 18, 0, 0, 0, time.UTC)},
+		{44349.587155671295, time.Date(2021, 6, 2, 14, 5, 30, 250e6, time.UTC)},
+		{0.5, time.Date(1899, 12, 31, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		got, err := fromSerial(test.n)
+		if err != nil {
+			t.Errorf("%v: %v", test.n, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("%v: got %v, want %v", test.n, got, test.want)
+		}
+	}
+	for _, n := range []float64{-1, maxSerial} {
+		if _, err := fromSerial(n); err == nil {
+			t.Errorf("%v: expect error", n)
+		}
+	}
+}
+
+// workbookParts are the parts of a workbook of two sheets, written in the way
+// of other applications: prefixed elements, an absolute target, rich and
+// inline strings, and cells without references.
+var workbookParts = map[string]string{
+	"[Content_Types].xml": contentTypes,
+	"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="R1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="/book/main.xml"/>` +
+		`</Relationships>`,
+	"book/main.xml": `<x:workbook xmlns:x="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:rel="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><x:sheets>` +
+		`<x:sheet name="Summary" sheetId="1" rel:id="s1"/>` +
+		`<x:sheet name="Orders" sheetId="2" rel:id="s2"/>` +
+		`</x:sheets></x:workbook>`,
+	"book/_rels/main.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="s1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="summary.xml"/>` +
+		`<Relationship Id="s2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/data/orders.xml"/>` +
+		`<Relationship Id="ss" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="../strings.xml"/>` +
+		`</Relationships>`,
+	"book/summary.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row><c t="inlineStr"><is><t>Total</t></is></c></row>` +
+		`<row><c><v>3</v></c></row>` +
+		`</sheetData></worksheet>`,
+	"data/orders.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c>` +
+		`<c r="D1" t="s"><v>3</v></c><c r="E1" t="s"><v>4</v></c><c r="F1" t="s"><v>5</v></c></row>` +
+		`<row r="2"/>` +
+		`<row r="3"><c r="A3"><v>1</v></c><c r="B3" t="s"><v>6</v></c><c r="D3" t="b"><v>1</v></c>` +
+		`<c r="E3" t="d"><v>2021-06-01T08:30:00</v></c><c r="F3" t="str"><v>ok</v></c></row>` +
+		`<row r="5"><c r="A5" t="n"><v>2</v></c><c r="C5" s="3"><v>1.5</v></c>` +
+		`<c r="E5" s="1"><v>44348.5</v></c><c r="F5" t="e"><v>#N/A</v></c></row>` +
+		`</sheetData></worksheet>`,
+	"strings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<si><t>ID</t></si><si><t>customer</t></si><si><t> Amount </t></si><si><t>Paid</t></si>` +
+		`<si><t>Date</t></si><si><t>Status</t></si>` +
+		`<si><r><t>Li </t></r><r><rPr><b/></rPr><t>Lei</t></r><rPh><t>x</t></rPh></si>` +
+		`</sst>`,
+}
+
+func TestDecode(t *testing.T) {
+	type item struct {
+		ID       int
+		Customer string `xlsx:"Customer"`
+		Amount   float64
+		Paid     bool
+		Date     time.Time
+		Status   string
+	}
+	data := writeParts(t, workbookParts)
+	var got []item
+	if err := decode(context.Background(), data, &got, &DecoderConfig{Sheet: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []item{
+		{ID: 1, Customer: "Li Lei", Paid: true, Date: time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC), Status: "ok"},
+		{ID: 2, Amount: 1.5, Date: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), Status: "#N/A"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	var rows [][]string
+	if err := decode(context.Background(), data, &rows, &DecoderConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"Total"}, {"3"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %q, want %q", rows, want)
+	}
+	if err := decode(context.Background(), data, &rows, &DecoderConfig{Sheet: "Missing"}); err == nil {
+		t.Error("expect error of missing sheet")
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		part  string
+		sheet string
+	}{
+		{"missing shared strings", "strings.xml", "", "Orders"},
+		{"shared string index", "strings.xml", `<sst/>`, "Orders"},
+		{"cell reference", "book/summary.xml", `<worksheet><sheetData><row><c r="1A"><v>1</v></c></row></sheetData></worksheet>`, ""},
+		{"cell type", "book/summary.xml", `<worksheet><sheetData><row><c t="x"><v>1</v></c></row></sheetData></worksheet>`, ""},
+		{"malformed part", "book/summary.xml", `<worksheet>`, ""},
+		{"no sheet", "book/main.xml", `<workbook/>`, ""},
+		{"missing workbook", "_rels/.rels", `<Relationships/>`, ""},
+	}
+	for _, test := range tests {
+		parts := map[string]string{}
+		for name, content := range workbookParts {
+			parts[name] = content
+		}
+		if test.part == "" {
+			delete(parts, test.path)
+		} else {
+			parts[test.path] = test.part
+		}
+		var rows [][]string
+		if err := decode(context.Background(), writeParts(t, parts), &rows, &DecoderConfig{Sheet: test.sheet}); err == nil {
+			t.Errorf("%s: expect error", test.name)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	se "encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+	"github.com/go-kita/encoding/xml"
+)
+
+// DefaultSheetName is the name of the sheet written unless set by EncodeSheet.
+const DefaultSheetName = "Sheet1"
+
+var (
+	_timeType          = reflect.TypeOf(time.Time{})
+	_textMarshalerType = reflect.TypeOf((*se.TextMarshaler)(nil)).Elem()
+)
+
+// epoch is the day before serial 1 of the 1900 date system, taking the
+// nonexistent 1900-02-29 into account for serials after it.
+var epoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// leapBug is the first day after the nonexistent 1900-02-29, serial 61.
+var leapBug = time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// serial returns the serial number of the 1900 date system of the wall clock
+// of t, and whether t has a time of day.
+func serial(t time.Time) (float64, bool) {
+	year, month, day := t.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	days := float64(date.Sub(epoch) / (24 * time.Hour))
+	if date.Before(leapBug) {
+		days--
+	}
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+	return days + float64(clock)/float64(24*time.Hour), clock != 0
+}
+
+type encoder struct {
+	ctx     context.Context
+	strings map[string]int
+	shared  sst
+}
+
+func encode(ctx context.Context, v interface{}, config *EncoderConfig) ([]byte, error) {
+	name := config.SheetName
+	if name == "" {
+		name = DefaultSheetName
+	}
+	if err := validSheetName(name); err != nil {
+		return nil, err
+	}
+	e := &encoder{ctx: ctx, strings: map[string]int{}}
+	header, widths, rows, err := e.rows(v)
+	if err != nil {
+		return nil, err
+	}
+	ws := worksheet{Xmlns: nsMain}
+	for i, name := range header {
+		width := widths[i]
+		if w, ok := config.ColumnWidths[name]; ok {
+			width = w
+		}
+		if width > 0 {
+			ws.Cols = append(ws.Cols, col{Min: i + 1, Max: i + 1, Width: width, CustomWidth: 1})
+		}
+	}
+	ws.SheetData.Rows = make([]row, len(rows))
+	for i, cells := range rows {
+		r := row{R: i + 1}
+		for j, c := range cells {
+			if c.Type == cellNumber && c.Value == "" {
+				continue
+			}
+			c.Ref = columnName(j) + strconv.Itoa(i+1)
+			r.Cells = append(r.Cells, c)
+		}
+		ws.SheetData.Rows[i] = r
+	}
+	e.shared.Xmlns = nsMain
+	e.shared.UniqueCount = len(e.shared.Items)
+	book := workbook{
+		Xmlns:  nsMain,
+		XmlnsR: nsRelationships,
+		Sheets: []sheet{{Name: name, SheetID: 1, ID: "rId1"}},
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, part := range []struct {
+		path  string
+		value interface{}
+	}{
+		{pathContentTypes, contentTypes},
+		{pathRootRels, rootRels},
+		{pathWorkbook, &book},
+		{pathWorkbookRels, workbookRels},
+		{pathSheet, &ws},
+		{pathSharedStrings, &e.shared},
+		{pathStyles, styles},
+	} {
+		if err := e.writePart(zw, part.path, part.value); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writePart writes a part of static content, or a value marshaled by the xml
+// codec.
+func (e *encoder) writePart(zw *zip.Writer, path string, value interface{}) error {
+	w, err := zw.Create(path)
+	if err != nil {
+		return err
+	}
+	if s, ok := value.(string); ok {
+		_, err = w.Write([]byte(s))
+		return err
+	}
+	data, err := encoding.GetMarshaler(xml.Name).Marshal(e.ctx, value)
+	if err != nil {
+		return fmt.Errorf("xlsx: %s: %w", path, err)
+	}
+	if _, err = w.Write([]byte(xmlHeader)); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// validSheetName checks name against the rules of Excel.
+func validSheetName(name string) error {
+	if n := utf8.RuneCountInString(name); n == 0 || n > 31 ||
+		strings.ContainsAny(name, `[]:*?/\`) || name[0] == '\'' || name[len(name)-1] == '\'' {
+		return fmt.Errorf("xlsx: invalid sheet name %q", name)
+	}
+	return nil
+}
+
+// rows converts v into the header, widths of columns and rows, which begin
+// with the header row. The header is nil for [][]string.
+func (e *encoder) rows(v interface{}) ([]string, []float64, [][]cell, error) {
+	if records, ok := v.([][]string); ok {
+		rows := make([][]cell, len(records))
+		for i, record := range records {
+			rows[i] = make([]cell, len(record))
+			for j, s := range record {
+				if s != "" {
+					rows[i][j] = e.stringCell(s)
+				}
+			}
+		}
+		return nil, nil, rows, nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, nil, nil, fmt.Errorf("xlsx: unsupported type %T", v)
+	}
+	elemType := rv.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, nil, nil, fmt.Errorf("xlsx: unsupported type %T", v)
+	}
+	fs := fields.Of(elemType, _tags...)
+	header := make([]string, len(fs))
+	widths := make([]float64, len(fs))
+	for i, f := range fs {
+		header[i] = f.Name
+		if s, ok := f.Options.Lookup("width"); ok {
+			width, err := strconv.ParseFloat(s, 64)
+			if err != nil || width < 0 || width > 255 {
+				return nil, nil, nil, fmt.Errorf("xlsx: invalid width %q of field %s", s, f.Name)
+			}
+			widths[i] = width
+		}
+	}
+	rows := make([][]cell, rv.Len()+1)
+	rows[0] = make([]cell, len(fs))
+	for i, name := range header {
+		rows[0][i] = e.stringCell(name)
+	}
+	for i := 1; i < len(rows); i++ {
+		cells := make([]cell, len(fs))
+		item := rv.Index(i - 1)
+		for item.Kind() == reflect.Ptr && !item.IsNil() {
+			item = item.Elem()
+		}
+		if item.Kind() == reflect.Struct {
+			for j, f := range fs {
+				fv, ok := fields.ByIndex(item, f.Index, false)
+				if !ok {
+					continue
+				}
+				c, err := e.cell(fv)
+				if err != nil {
+					return nil, nil, nil, &RowError{Row: i + 1, Column: j + 1, Field: f.Name, Err: err}
+				}
+				cells[j] = c
+			}
+		}
+		rows[i] = cells
+	}
+	return header, widths, rows, nil
+}
+
+// cell converts a field into a cell, keeping numbers, booleans and dates.
+// Dates before 1900, which have no serial number, are converted into text. An
+// empty numeric cell means no cell.
+func (e *encoder) cell(v reflect.Value) (cell, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return cell{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == _timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return cell{}, nil
+		}
+		if t.Year() >= 1900 {
+			n, clock := serial(t)
+			style := styleDate
+			if clock {
+				style = styleDateTime
+			}
+			return cell{Style: style, Value: strconv.FormatFloat(n, 'f', -1, 64)}, nil
+		}
+	}
+	if !v.Type().Implements(_textMarshalerType) && !(v.CanAddr() && v.Addr().Type().Implements(_textMarshalerType)) {
+		switch v.Kind() {
+		case reflect.Bool:
+			if v.Bool() {
+				return cell{Type: cellBool, Value: "1"}, nil
+			}
+			return cell{Type: cellBool, Value: "0"}, nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cell{Value: strconv.FormatInt(v.Int(), 10)}, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cell{Value: strconv.FormatUint(v.Uint(), 10)}, nil
+		case reflect.Float32, reflect.Float64:
+			if f := v.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+				return cell{Value: textual.FormatFloat(f, v.Type().Bits())}, nil
+			}
+		}
+	}
+	s, err := textual.Marshal(v)
+	if err != nil || s == "" {
+		return cell{}, err
+	}
+	return e.stringCell(s), nil
+}
+
+// stringCell returns a cell of a shared string.
+func (e *encoder) stringCell(s string) cell {
+	e.shared.Count++
+	index, ok := e.strings[s]
+	if !ok {
+		index = len(e.shared.Items)
+		e.strings[s] = index
+		e.shared.Items = append(e.shared.Items, newStringItem(s))
+	}
+	return cell{Type: cellSharedString, Value: strconv.Itoa(index)}
+}
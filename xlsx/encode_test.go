@@ -0,0 +1,138 @@
+package xlsx
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSerial(t *testing.T) {
+	tests := []struct {
+		t     time.Time
+		want  float64
+		clock bool
+	}{
+		{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), 1, false},
+		{time.Date(1900, 2, 28, 0, 0, 0, 0, time.UTC), 59, false},
+		{time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC), 61, false},
+		{time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), 44348, false},
+		{time.Date(2021, 6, 1, 18, 0, 0, 0, time.UTC), 44348.75, true},
+		{time.Date(2021, 6, 1, 18, 0, 0, 0, time.FixedZone("UTC+8", 8*60*60)), 44348.75, true},
+	}
+	for _, test := range tests {
+		got, clock := serial(test.t)
+		if got != test.want || clock != test.clock {
+			t.Errorf("%v: got %v, %v, want %v, %v", test.t, got, clock, test.want, test.clock)
+		}
+	}
+}
+
+func TestEncoder_Cell(t *testing.T) {
+	var nilTime *time.Time
+	type level int
+	tests := []struct {
+		v    interface{}
+		want cell
+	}{
+		{int8(-3), cell{Value: "-3"}},
+		{uint64(math.MaxUint64), cell{Value: "18446744073709551615"}},
+		{1e-7, cell{Value: "1e-07"}},
+		{float32(0.1), cell{Value: "0.1"}},
+		{level(2), cell{Value: "2"}},
+		{true, cell{Type: cellBool, Value: "1"}},
+		{false, cell{Type: cellBool, Value: "0"}},
+		{"", cell{}},
+		{nilTime, cell{}},
+		{time.Time{}, cell{}},
+		{time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), cell{Style: styleDate, Value: "44348"}},
+		{time.Date(2021, 6, 1, 6, 0, 0, 0, time.UTC), cell{Style: styleDateTime, Value: "44348.25"}},
+		{"text", cell{Type: cellSharedString, Value: "0"}},
+		{math.Inf(1), cell{Type: cellSharedString, Value: "1"}},
+		{time.Date(1899, 1, 1, 0, 0, 0, 0, time.UTC), cell{Type: cellSharedString, Value: "2"}},
+		{[]byte("text"), cell{Type: cellSharedString, Value: "0"}},
+	}
+	e := &encoder{strings: map[string]int{}}
+	for _, test := range tests {
+		got, err := e.cell(reflect.ValueOf(test.v))
+		if err != nil {
+			t.Errorf("%v: %v", test.v, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%v: got %+v, want %+v", test.v, got, test.want)
+		}
+	}
+	if want := []string{"text", "+Inf", "1899-01-01T00:00:00Z"}; len(e.shared.Items) != len(want) {
+		t.Errorf("got %d shared strings, want %d", len(e.shared.Items), len(want))
+	} else {
+		for i, s := range want {
+			if got := e.shared.Items[i].String(); got != s {
+				t.Errorf("got shared string %q, want %q", got, s)
+			}
+		}
+	}
+	if e.shared.Count != 4 {
+		t.Errorf("got count %d, want 4", e.shared.Count)
+	}
+	if _, err := e.cell(reflect.ValueOf(map[string]int{})); err == nil {
+		t.Error("expect error of unsupported type")
+	}
+}
+
+func TestEncode_Widths(t *testing.T) {
+	type item struct {
+		A string `xlsx:"A,width=12.5"`
+		B string
+		C string `xlsx:"C,width=8"`
+	}
+	config := &EncoderConfig{ColumnWidths: map[string]float64{"B": 30, "C": 0}}
+	data, err := encode(context.Background(), []item{{A: "a"}}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := readParts(t, data)[pathSheet]
+	want := `<cols><col min="1" max="1" width="12.5" customWidth="1"></col><col min="2" max="2" width="30" customWidth="1"></col></cols>`
+	if !strings.Contains(sheet, want) {
+		t.Errorf("got %s, want %s", sheet, want)
+	}
+	type invalid struct {
+		A string `xlsx:"A,width=wide"`
+	}
+	if _, err := encode(context.Background(), []invalid{}, &EncoderConfig{}); err == nil {
+		t.Error("expect error of invalid width")
+	}
+}
+
+func TestEncode_Empty(t *testing.T) {
+	data, err := encode(context.Background(), []order{}, &EncoderConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := readParts(t, data)[pathSheet]
+	if !strings.Contains(sheet, `<sheetData><row r="1">`) || strings.Contains(sheet, `r="2"`) {
+		t.Errorf("got %s, want the header row only", sheet)
+	}
+	data, err = encode(context.Background(), [][]string{}, &EncoderConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet := readParts(t, data)[pathSheet]; !strings.Contains(sheet, `<sheetData></sheetData>`) {
+		t.Errorf("got %s, want empty sheet data", sheet)
+	}
+}
+
+func TestValidSheetName(t *testing.T) {
+	for _, name := range []string{"Sheet1", "报表 2021", strings.Repeat("x", 31), "it's"} {
+		if err := validSheetName(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", strings.Repeat("x", 32), "a/b", "[a]", "a:b", "'a'", "a?"} {
+		if err := validSheetName(name); err == nil {
+			t.Errorf("%q: expect error", name)
+		}
+	}
+}
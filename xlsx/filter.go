@@ -0,0 +1,140 @@
+package xlsx
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// SheetName is the name of the sheet, DefaultSheetName if empty.
+	SheetName string
+	// ColumnWidths are the widths of columns by their names in the header, in
+	// characters, overriding the "width" options of tags.
+	ColumnWidths map[string]float64
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// Sheet is the name of the sheet to decode, the first sheet if empty.
+	Sheet string
+	// MaxPartSize is the maximum uncompressed size of a part of the package
+	// in bytes, DefaultMaxPartSize if not positive. It protects against zip
+	// bombs.
+	MaxPartSize int64
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// DecodeSheet produces a DecoderOption which decodes the sheet of the name,
+// instead of the first sheet.
+func DecodeSheet(name string) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.Sheet = name
+	}
+}
+
+// MaxPartSize produces a DecoderOption which sets the maximum uncompressed size
+// of a part of the package in bytes.
+func MaxPartSize(size int64) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.MaxPartSize = size
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// EncodeSheet produces an EncoderOption which sets the name of the sheet.
+func EncodeSheet(name string) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.SheetName = name
+	}
+}
+
+// ColumnWidth produces an EncoderOption which sets the width of the column of
+// the name, in characters.
+func ColumnWidth(name string, width float64) EncoderOption {
+	return func(config *EncoderConfig) {
+		if config.ColumnWidths == nil {
+			config.ColumnWidths = map[string]float64{}
+		}
+		config.ColumnWidths[name] = width
+	}
+}
@@ -0,0 +1,43 @@
+package xlsx
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, EncodeSheet("Orders"), ColumnWidth("Customer", 40))
+	data, err := m.Marshal(context.Background(), []order{{ID: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := readParts(t, data)
+	if !strings.Contains(parts[pathWorkbook], `<sheet name="Orders" sheetId="1" r:id="rId1">`) {
+		t.Errorf("got %s, want sheet Orders", parts[pathWorkbook])
+	}
+	if !strings.Contains(parts[pathSheet], `<col min="2" max="2" width="40" customWidth="1">`) {
+		t.Errorf("got %s, want width 40 of column 2", parts[pathSheet])
+	}
+	m = WithEncoderOption(&codec{}, EncodeSheet("a/b"))
+	if _, err := m.Marshal(context.Background(), []order{}); err == nil {
+		t.Error("expect error of invalid sheet name")
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	data := writeParts(t, workbookParts)
+	u := WithDecoderOption(&codec{}, DecodeSheet("Summary"))
+	var got []struct{ Total int }
+	if err := u.Unmarshal(context.Background(), data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := []struct{ Total int }{{3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	u = WithDecoderOption(&codec{}, MaxPartSize(64))
+	if err := u.Unmarshal(context.Background(), data, &got); err == nil || !strings.Contains(err.Error(), "larger than 64 bytes") {
+		t.Errorf("got error %v, want error of part size", err)
+	}
+}
@@ -0,0 +1,227 @@
+package xlsx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Namespaces and relationship types of SpreadsheetML.
+const (
+	nsMain          = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+	relOfficeDocument = "/officeDocument"
+	relWorksheet      = "/worksheet"
+	relSharedStrings  = "/sharedStrings"
+)
+
+// Paths of the parts written by the encoder.
+const (
+	pathContentTypes  = "[Content_Types].xml"
+	pathRootRels      = "_rels/.rels"
+	pathWorkbook      = "xl/workbook.xml"
+	pathWorkbookRels  = "xl/_rels/workbook.xml.rels"
+	pathSheet         = "xl/worksheets/sheet1.xml"
+	pathSharedStrings = "xl/sharedStrings.xml"
+	pathStyles        = "xl/styles.xml"
+)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const contentTypes = xmlHeader +
+	`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/sharedStrings.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sharedStrings+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRels = xmlHeader +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookRels = xmlHeader +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>` +
+	`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// Indexes of the cell formats defined by styles.
+const (
+	styleDate     = 1
+	styleDateTime = 2
+)
+
+const styles = xmlHeader +
+	`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2">` +
+	`<numFmt numFmtId="164" formatCode="yyyy\-mm\-dd"/>` +
+	`<numFmt numFmtId="165" formatCode="yyyy\-mm\-dd\ hh:mm:ss"/>` +
+	`</numFmts>` +
+	`<fonts count="1"><font><sz val="11"/><name val="Calibri"/><family val="2"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// relationships is the part of relationships of a package or a part.
+type relationships struct {
+	Relationships []relationship `xml:"Relationship"`
+}
+
+type relationship struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+// workbook is the workbook part. The element name is taken from the type name.
+type workbook struct {
+	Xmlns  string  `xml:"xmlns,attr,omitempty"`
+	XmlnsR string  `xml:"xmlns:r,attr,omitempty"`
+	Sheets []sheet `xml:"sheets>sheet"`
+}
+
+type sheet struct {
+	Name    string `xml:"name,attr"`
+	SheetID int    `xml:"sheetId,attr"`
+	// ID is the relationship id written with the prefix declared on the
+	// workbook, and RelID is the same attribute read by its namespace.
+	ID    string `xml:"r:id,attr,omitempty"`
+	RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr,omitempty"`
+}
+
+// sst is the shared strings part.
+type sst struct {
+	Xmlns       string       `xml:"xmlns,attr,omitempty"`
+	Count       int          `xml:"count,attr,omitempty"`
+	UniqueCount int          `xml:"uniqueCount,attr,omitempty"`
+	Items       []stringItem `xml:"si"`
+}
+
+// stringItem is a shared or inline string, either plain or rich text of runs.
+type stringItem struct {
+	Text *text `xml:"t"`
+	Runs []run `xml:"r"`
+}
+
+type run struct {
+	Text text `xml:"t"`
+}
+
+type text struct {
+	Space string `xml:"xml:space,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+func newStringItem(s string) stringItem {
+	t := &text{Value: s}
+	if strings.TrimSpace(s) != s {
+		t.Space = "preserve"
+	}
+	return stringItem{Text: t}
+}
+
+func (s *stringItem) String() string {
+	var b strings.Builder
+	if s.Text != nil {
+		b.WriteString(s.Text.Value)
+	}
+	for _, r := range s.Runs {
+		b.WriteString(r.Text.Value)
+	}
+	return b.String()
+}
+
+// worksheet is the worksheet part.
+type worksheet struct {
+	Xmlns     string    `xml:"xmlns,attr,omitempty"`
+	Cols      []col     `xml:"cols>col,omitempty"`
+	SheetData sheetData `xml:"sheetData"`
+}
+
+type col struct {
+	Min         int     `xml:"min,attr"`
+	Max         int     `xml:"max,attr"`
+	Width       float64 `xml:"width,attr"`
+	CustomWidth int     `xml:"customWidth,attr,omitempty"`
+}
+
+type sheetData struct {
+	Rows []row `xml:"row"`
+}
+
+type row struct {
+	R     int    `xml:"r,attr,omitempty"`
+	Cells []cell `xml:"c"`
+}
+
+// Types of cells.
+const (
+	cellNumber       = ""
+	cellNumberN      = "n"
+	cellBool         = "b"
+	cellError        = "e"
+	cellDate         = "d"
+	cellSharedString = "s"
+	cellString       = "str"
+	cellInlineString = "inlineStr"
+)
+
+type cell struct {
+	Ref    string      `xml:"r,attr,omitempty"`
+	Style  int         `xml:"s,attr,omitempty"`
+	Type   string      `xml:"t,attr,omitempty"`
+	Value  string      `xml:"v,omitempty"`
+	Inline *stringItem `xml:"is"`
+}
+
+// columnName returns the name of the 0-based column, such as "A" or "AB".
+func columnName(column int) string {
+	var name []byte
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = append([]byte{byte('A' + (column-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// parseRef parses a cell reference such as "B3" into the 0-based column and
+// the 1-based row.
+func parseRef(ref string) (column, row int, err error) {
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		column = column*26 + int(ref[i]-'A') + 1
+		if column > maxColumns {
+			return 0, 0, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+		}
+	}
+	if i == 0 || i == len(ref) {
+		return 0, 0, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+	}
+	for ; i < len(ref); i++ {
+		if ref[i] < '0' || ref[i] > '9' || row > maxRows {
+			return 0, 0, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+		}
+		row = row*10 + int(ref[i]-'0')
+	}
+	if row == 0 || row > maxRows {
+		return 0, 0, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+	}
+	return column - 1, row, nil
+}
+
+// Limits of worksheets.
+const (
+	maxColumns = 16384
+	maxRows    = 1048576
+)
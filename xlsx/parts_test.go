@@ -0,0 +1,50 @@
+package xlsx
+
+import (
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		column int
+		name   string
+		ref    string
+		row    int
+	}{
+		{0, "A", "A1", 1},
+		{25, "Z", "Z9", 9},
+		{26, "AA", "AA10", 10},
+		{701, "ZZ", "ZZ3", 3},
+		{702, "AAA", "AAA1048576", 1048576},
+		{maxColumns - 1, "XFD", "XFD2", 2},
+	}
+	for _, test := range tests {
+		if got := columnName(test.column); got != test.name {
+			t.Errorf("%d: got %s, want %s", test.column, got, test.name)
+		}
+		column, row, err := parseRef(test.ref)
+		if err != nil || column != test.column || row != test.row {
+			t.Errorf("%s: got %d, %d, %v, want %d, %d", test.ref, column, row, err, test.column, test.row)
+		}
+	}
+	for _, ref := range []string{"", "A", "1", "a1", "A0", "A1B", "XFE1", "A1048577"} {
+		if _, _, err := parseRef(ref); err == nil {
+			t.Errorf("%q: expect error", ref)
+		}
+	}
+}
+
+func TestStringItem(t *testing.T) {
+	if got := newStringItem("a"); got.Text.Space != "" {
+		t.Errorf("got space %q, want none", got.Text.Space)
+	}
+	for _, s := range []string{" a", "a\n", "\t"} {
+		if got := newStringItem(s); got.Text.Space != "preserve" || got.String() != s {
+			t.Errorf("%q: got %+v", s, got.Text)
+		}
+	}
+	rich := stringItem{Runs: []run{{Text: text{Value: "a"}}, {Text: text{Value: "b"}}}}
+	if got := rich.String(); got != "ab" {
+		t.Errorf("got %q, want %q", got, "ab")
+	}
+}
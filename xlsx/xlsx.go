@@ -0,0 +1,87 @@
+// Package xlsx defines and registers Marshaler/Unmarshaler handling Office
+// Open XML workbooks of a single sheet of tabular data, which can be opened
+// by spreadsheet applications such as Excel directly.
+//
+// A slice of structs is marshaled into a sheet with a header row taken from
+// the "xlsx" tags of the fields, or the "csv" tags. Numbers and booleans are
+// kept as they are, time.Time values are written as dates of the 1900 date
+// system, and the other values as shared strings of their textual form. The
+// width of a column is set by the "width" option of the tag, such as
+// `xlsx:"Name,width=20"`, or by the ColumnWidth option.
+package xlsx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+	Register(MIMEType)
+}
+
+// Name is type name.
+const Name = "xlsx"
+
+// MIMEType is the media type of Office Open XML workbooks.
+const MIMEType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+var _tags = []string{"xlsx", "csv"}
+
+// RowError is an error of decoding or encoding a cell of a row.
+type RowError struct {
+	// Row is the 1-based number of the row, including the header row.
+	Row int
+	// Column is the 1-based number of the column.
+	Column int
+	// Field is the name of the column.
+	Field string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("xlsx: cell %s%d (%s): %v", columnName(e.Column-1), e.Row, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+// Marshal encodes a slice or an array of structs or pointers to structs into
+// a workbook of a single sheet with a header row, or [][]string as-is.
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	return encode(ctx, v, config)
+}
+
+// Unmarshal decodes the first sheet of a workbook, or the one set by the
+// DecodeSheet option, into a pointer to a slice of structs or pointers to
+// structs, or into *[][]string as-is. Columns are mapped to fields by the
+// header row regardless of the order, and rows without any value are skipped.
+// Errors of decoding fields are reported as *RowError.
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	return decode(ctx, data, v, config)
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,158 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+// readParts returns the parts of a package by their paths.
+func readParts(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(b)
+	}
+	return parts
+}
+
+// writeParts returns a package of the parts.
+func writeParts(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRegister(t *testing.T) {
+	for _, name := range []string{Name, MIMEType} {
+		if encoding.GetMarshaler(name) == nil || encoding.GetUnmarshaler(name) == nil {
+			t.Errorf("codec is not registered as %q", name)
+		}
+	}
+}
+
+type order struct {
+	ID       int        `xlsx:"ID"`
+	Customer string     `xlsx:"Customer,width=24"`
+	Amount   float64    `csv:"amount"`
+	Paid     bool       `xlsx:"Paid"`
+	Date     time.Time  `xlsx:"Date"`
+	Shipped  *time.Time `xlsx:"Shipped"`
+	Note     string     `xlsx:"-"`
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	shipped := time.Date(2021, 6, 2, 14, 5, 30, 250e6, time.UTC)
+	in := []order{
+		{ID: 1, Customer: "李雷", Amount: 12.5, Paid: true, Date: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), Shipped: &shipped},
+		{ID: 2, Customer: " <Han & Mei> ", Amount: -0.25},
+		{},
+	}
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []order
+	if err := c.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	// Zero numbers and booleans are kept, while zero times are left empty.
+	in[0].Note = ""
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+	var rows [][]string
+	if err := c.Unmarshal(context.Background(), data, &rows); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"ID", "Customer", "amount", "Paid", "Date", "Shipped"},
+		{"1", "李雷", "12.5", "true", "44348", "44349.587155671295"},
+		{"2", " <Han & Mei> ", "-0.25", "false"},
+		{"0", "", "0", "false"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %q, want %q", rows, want)
+	}
+}
+
+func TestCodec_Pointers(t *testing.T) {
+	c := &codec{}
+	data, err := c.Marshal(context.Background(), &[]*order{{ID: 7, Customer: "x"}, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []*order
+	if err := c.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].ID != 7 || out[0].Customer != "x" {
+		t.Errorf("got %+v", out)
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	c := &codec{}
+	ctx := context.Background()
+	for _, v := range []interface{}{1, []int{1}, map[string]string{}} {
+		if _, err := c.Marshal(ctx, v); err == nil {
+			t.Errorf("%T: expect error", v)
+		}
+	}
+	data, err := c.Marshal(ctx, [][]string{{"ID"}, {"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orders []order
+	var rowErr *RowError
+	if err := c.Unmarshal(ctx, data, &orders); !errors.As(err, &rowErr) {
+		t.Fatalf("got error %v, want RowError", err)
+	} else if rowErr.Row != 2 || rowErr.Column != 1 || rowErr.Field != "ID" {
+		t.Errorf("got %+v", rowErr)
+	} else if want := `xlsx: cell A2 (ID): strconv.ParseInt: parsing "x": invalid syntax`; err.Error() != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+	var n int
+	for _, v := range []interface{}{nil, orders, &n, &[]int{}} {
+		if err := c.Unmarshal(ctx, data, v); err == nil {
+			t.Errorf("%T: expect error", v)
+		}
+	}
+	if err := c.Unmarshal(ctx, []byte("ID\n1\n"), &orders); err == nil {
+		t.Error("expect error of non-zip data")
+	}
+}
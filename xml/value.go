@@ -0,0 +1,166 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-kita/encoding"
+)
+
+// TextKey is the member key which holds the character data of an element
+// that also has child elements.
+const TextKey = "#text"
+
+// decodeValue decodes an XML document into an *encoding.Value.
+//
+// The document is decoded into an object Value, whose members are the top-level
+// elements keyed by their local names. An element with child elements is decoded
+// into an object Value, repeated child elements are kept as repeated members.
+// An element without child elements is decoded into a string Value holding its
+// character data. Namespace and attributes of an element are kept in the Space
+// and Attrs of its Value. Namespace declarations are dropped, because they can
+// be derived from the namespace of elements and attributes.
+func decodeValue(decoder *xml.Decoder) (*encoding.Value, error) {
+	root := encoding.NewObject()
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			root.Members = append(root.Members, &encoding.Member{Key: start.Name.Local, Value: value})
+		}
+	}
+	if len(root.Members) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return root, nil
+}
+
+func decodeElement(decoder *xml.Decoder, start xml.StartElement) (*encoding.Value, error) {
+	var members []*encoding.Member
+	text := &bytes.Buffer{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := decodeElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, &encoding.Member{Key: t.Name.Local, Value: value})
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			var value *encoding.Value
+			if len(members) == 0 {
+				value = encoding.NewString(text.String())
+			} else {
+				if trimmed := bytes.TrimSpace(text.Bytes()); len(trimmed) > 0 {
+					members = append(members, &encoding.Member{Key: TextKey, Value: encoding.NewString(string(trimmed))})
+				}
+				value = encoding.NewObject(members...)
+			}
+			value.Space = start.Name.Space
+			for _, attr := range start.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				value.Attrs = append(value.Attrs, encoding.Attr{
+					Space: attr.Name.Space,
+					Name:  attr.Name.Local,
+					Value: attr.Value,
+				})
+			}
+			return value, nil
+		}
+	}
+}
+
+// encodeValue encodes an *encoding.Value as XML elements.
+//
+// The Value must be an object, each member of which is encoded as a top-level
+// element named by its key. Members of objects are encoded as child elements,
+// an array is encoded as repeated elements with the same name, and a scalar is
+// encoded as character data. The member keyed by TextKey is encoded as the
+// character data of its parent element.
+func encodeValue(encoder *xml.Encoder, value *encoding.Value) error {
+	if value == nil || value.Kind != encoding.ObjectKind {
+		return errors.New("xml: top-level value must be an object")
+	}
+	for _, m := range value.Members {
+		if err := encodeElement(encoder, m.Key, m.Value); err != nil {
+			return err
+		}
+	}
+	return encoder.Flush()
+}
+
+func encodeElement(encoder *xml.Encoder, name string, value *encoding.Value) error {
+	if value == nil {
+		value = encoding.NewNull()
+	}
+	if value.Kind == encoding.ArrayKind {
+		for _, item := range value.Items {
+			if err := encodeElement(encoder, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	start := xml.StartElement{Name: xml.Name{Space: value.Space, Local: name}}
+	for _, attr := range value.Attrs {
+		start.Attr = append(start.Attr, xml.Attr{
+			Name:  xml.Name{Space: attr.Space, Local: attr.Name},
+			Value: attr.Value,
+		})
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	switch value.Kind {
+	case encoding.NullKind:
+	case encoding.BoolKind, encoding.NumberKind, encoding.StringKind:
+		if err := encoder.EncodeToken(xml.CharData(scalarText(value))); err != nil {
+			return err
+		}
+	case encoding.ObjectKind:
+		for _, m := range value.Members {
+			var err error
+			if m.Key == TextKey && m.Value != nil && m.Value.Kind != encoding.ObjectKind {
+				err = encoder.EncodeToken(xml.CharData(scalarText(m.Value)))
+			} else {
+				err = encodeElement(encoder, m.Key, m.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("xml: unsupported value kind %s", value.Kind)
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+func scalarText(value *encoding.Value) string {
+	if value.Kind == encoding.BoolKind {
+		return fmt.Sprintf("%t", value.Bool)
+	}
+	return value.Text
+}
@@ -0,0 +1,66 @@
+package xml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kita/encoding"
+)
+
+func TestCodec_Value(t *testing.T) {
+	c := &codec{buf: _bufPool}
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<root xmlns="urn:r" id="1"><item>a</item><item>b</item><obj k="v"><n>1</n>text</obj><empty/></root>`
+	value := &encoding.Value{}
+	if err := c.Unmarshal(context.Background(), []byte(data), value); err != nil {
+		t.Fatal(err)
+	}
+	root := value.Get("root")
+	if root == nil || root.Space != "urn:r" || len(root.Attrs) != 1 || root.Attrs[0].Name != "id" {
+		t.Fatalf("unexpected root %+v", root)
+	}
+	if items := root.GetAll("item"); len(items) != 2 || items[1].Text != "b" {
+		t.Errorf("unexpected items %+v", items)
+	}
+	obj := root.Get("obj")
+	if obj.Get("n").Text != "1" || obj.Get(TextKey).Text != "text" || obj.Attrs[0].Value != "v" {
+		t.Errorf("unexpected obj %+v", obj)
+	}
+	got, err := c.Marshal(context.Background(), value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<root xmlns="urn:r" id="1"><item xmlns="urn:r">a</item><item xmlns="urn:r">b</item>` +
+		`<obj xmlns="urn:r" k="v"><n xmlns="urn:r">1</n>text</obj><empty xmlns="urn:r"></empty></root>`
+	if string(got) != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	again := &encoding.Value{}
+	if err := c.Unmarshal(context.Background(), got, again); err != nil {
+		t.Fatal(err)
+	}
+	if !again.Equal(value) {
+		t.Errorf("round trip not equal")
+	}
+}
+
+func TestCodec_ValueMarshal(t *testing.T) {
+	c := &codec{buf: _bufPool}
+	value := encoding.NewObject(&encoding.Member{Key: "r", Value: encoding.NewObject(
+		&encoding.Member{Key: "list", Value: encoding.NewArray(encoding.NewNumber("1"), encoding.NewBool(true))},
+		&encoding.Member{Key: "nil", Value: encoding.NewNull()},
+	)})
+	got, err := c.Marshal(context.Background(), value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<r><list>1</list><list>true</list><nil></nil></r>`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if _, err := c.Marshal(context.Background(), encoding.NewString("x")); err == nil {
+		t.Errorf("marshal non-object expect error")
+	}
+	if err := c.Unmarshal(context.Background(), []byte(`<a><b>`), &encoding.Value{}); err == nil {
+		t.Errorf("unmarshal broken document expect error")
+	}
+}
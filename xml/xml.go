@@ -39,11 +39,17 @@ func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
 	for _, option := range encoderOptionFromContext(ctx) {
 		option(encoder)
 	}
-	err := encoder.Encode(v)
+	var err error
+	if value, ok := v.(*encoding.Value); ok {
+		err = encodeValue(encoder, value)
+	} else {
+		err = encoder.Encode(v)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return buf.Bytes(), nil
+	// The buffer goes back to the pool, so its content is returned as a copy.
+	return append([]byte(nil), buf.Bytes()...), nil
 }
 
 func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
@@ -52,6 +58,14 @@ func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error
 	for _, option := range decoderOptionFromContext(ctx) {
 		option(decoder)
 	}
+	if value, ok := v.(*encoding.Value); ok {
+		decoded, err := decodeValue(decoder)
+		if err != nil {
+			return err
+		}
+		*value = *decoded
+		return nil
+	}
 	return decoder.Decode(v)
 }
 
@@ -0,0 +1,575 @@
+package yaml
+
+import (
+	se "encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+// ErrAliasExpansion is returned if aliases expand to more nodes than allowed
+// by DecoderConfig.MaxAliasExpansion.
+var ErrAliasExpansion = errors.New("yaml: alias expansion limit exceeded")
+
+// UnmarshalTypeError is an error of decoding a node into a value of
+// inappropriate type.
+type UnmarshalTypeError struct {
+	// Value describes the node, e.g. "!!str `abc`", "!!seq".
+	Value string
+	// Type is the type of the target value.
+	Type reflect.Type
+	// Line and Column are the 1-based position of the node.
+	Line   int
+	Column int
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("yaml: line %d, column %d: cannot unmarshal %s into Go value of type %s",
+		e.Line, e.Column, e.Value, e.Type)
+}
+
+var (
+	_valueType           = reflect.TypeOf(encoding.Value{})
+	_textUnmarshalerType = reflect.TypeOf((*se.TextUnmarshaler)(nil)).Elem()
+	_jsonNumberPattern   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+)
+
+type decoder struct {
+	config *DecoderConfig
+	// expanded is the number of nodes expanded from aliases so far.
+	expanded int
+}
+
+// documents decodes documents of a stream into v. A single document is
+// decoded into v itself, multiple documents are decoded into elements of v.
+func (d *decoder) documents(docs []*node, v reflect.Value) error {
+	switch len(docs) {
+	case 0:
+		return nil
+	case 1:
+		return d.unmarshal(docs[0], v)
+	}
+	if v.Type() == _valueType {
+		stream := encoding.NewArray()
+		for _, doc := range docs {
+			value, err := d.value(doc)
+			if err != nil {
+				return err
+			}
+			stream.Append(value)
+		}
+		v.Set(reflect.ValueOf(*stream))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.documents(docs, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			stream := make([]interface{}, 0, len(docs))
+			for _, doc := range docs {
+				x, err := d.any(doc)
+				if err != nil {
+					return err
+				}
+				stream = append(stream, x)
+			}
+			v.Set(reflect.ValueOf(stream))
+			return nil
+		}
+	case reflect.Slice:
+		stream := reflect.MakeSlice(v.Type(), len(docs), len(docs))
+		for i, doc := range docs {
+			if err := d.unmarshal(doc, stream.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(stream)
+		return nil
+	case reflect.Array:
+		if v.Len() >= len(docs) {
+			for i := 0; i < v.Len(); i++ {
+				if i >= len(docs) {
+					v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+				} else if err := d.unmarshal(docs[i], v.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("yaml: cannot unmarshal %d documents into Go value of type %s", len(docs), v.Type())
+}
+
+// alias returns the node an alias refers to, counting the expanded nodes.
+func (d *decoder) alias(n *node) (*node, error) {
+	limit := d.config.MaxAliasExpansion
+	if limit == 0 {
+		limit = DefaultMaxAliasExpansion
+	}
+	d.expanded += n.alias.expandedSize()
+	if limit > 0 && d.expanded > limit {
+		return nil, fmt.Errorf("%w at line %d, column %d", ErrAliasExpansion, n.line, n.column)
+	}
+	return n.alias, nil
+}
+
+func describe(n *node) string {
+	switch n.kind {
+	case sequenceNode:
+		return "!!seq"
+	case mappingNode:
+		return "!!map"
+	}
+	return fmt.Sprintf("%s `%s`", shortTag(scalarTag(n)), n.value)
+}
+
+func typeError(n *node, t reflect.Type) error {
+	return &UnmarshalTypeError{Value: describe(n), Type: t, Line: n.line, Column: n.column}
+}
+
+// unmarshal decodes a node into v, which must be settable.
+func (d *decoder) unmarshal(n *node, v reflect.Value) error {
+	if n.kind == aliasNode {
+		target, err := d.alias(n)
+		if err != nil {
+			return err
+		}
+		return d.unmarshal(target, v)
+	}
+	if v.Type() == _valueType {
+		value, err := d.value(n)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*value))
+		return nil
+	}
+	if n.kind == scalarNode && scalarTag(n) == tagNull {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if n.kind == scalarNode && v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(_textUnmarshalerType) {
+		if err := v.Addr().Interface().(se.TextUnmarshaler).UnmarshalText([]byte(n.value)); err != nil {
+			return fmt.Errorf("yaml: line %d, column %d: %w", n.line, n.column, err)
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.unmarshal(n, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return typeError(n, v.Type())
+		}
+		x, err := d.any(n)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+	switch n.kind {
+	case scalarNode:
+		return d.scalar(n, v)
+	case sequenceNode:
+		return d.sequence(n, v)
+	}
+	return d.mapping(n, v)
+}
+
+func (d *decoder) scalar(n *node, v reflect.Value) error {
+	x, err := resolve(n)
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if b, ok := x.([]byte); ok {
+			v.SetString(string(b))
+		} else {
+			v.SetString(n.value)
+		}
+		return nil
+	case reflect.Bool:
+		if b, ok := x.(bool); ok {
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch i := x.(type) {
+		case int64:
+			if !v.OverflowInt(i) {
+				v.SetInt(i)
+				return nil
+			}
+		case uint64:
+			if i <= math.MaxInt64 && !v.OverflowInt(int64(i)) {
+				v.SetInt(int64(i))
+				return nil
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch i := x.(type) {
+		case int64:
+			if i >= 0 && !v.OverflowUint(uint64(i)) {
+				v.SetUint(uint64(i))
+				return nil
+			}
+		case uint64:
+			if !v.OverflowUint(i) {
+				v.SetUint(i)
+				return nil
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		switch x.(type) {
+		case int64, uint64, float64:
+			f := toFloat(x)
+			if !v.OverflowFloat(f) || math.IsInf(f, 0) {
+				v.SetFloat(f)
+				return nil
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if b, ok := x.([]byte); ok {
+				v.SetBytes(b)
+			} else {
+				v.SetBytes([]byte(n.value))
+			}
+			return nil
+		}
+	}
+	return typeError(n, v.Type())
+}
+
+func (d *decoder) sequence(n *node, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(n.children), len(n.children))
+		for i, item := range n.children {
+			if err := d.unmarshal(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if i >= len(n.children) {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			} else if err := d.unmarshal(n.children[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return typeError(n, v.Type())
+}
+
+func (d *decoder) mapping(n *node, v reflect.Value) error {
+	pairs, err := d.pairs(n)
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for i := 0; i < len(pairs); i += 2 {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.unmarshal(pairs[i], key); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.unmarshal(pairs[i+1], elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return nil
+	case reflect.Struct:
+		fs := fields.Of(v.Type(), _tags...)
+		for i := 0; i < len(pairs); i += 2 {
+			key, err := d.key(pairs[i])
+			if err != nil {
+				return err
+			}
+			f := lookupField(fs, key.value)
+			if f == nil {
+				if d.config.DisallowUnknownFields {
+					return fmt.Errorf("yaml: line %d, column %d: unknown field %q", key.line, key.column, key.value)
+				}
+				continue
+			}
+			fv, ok := fields.ByIndex(v, f.Index, true)
+			if !ok {
+				return fmt.Errorf("yaml: field %s: can not set embedded pointer to unexported struct", f.Name)
+			}
+			if err := d.unmarshal(pairs[i+1], fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return typeError(n, v.Type())
+}
+
+// key returns the scalar node of a mapping key, following an alias.
+func (d *decoder) key(n *node) (*node, error) {
+	if n.kind == aliasNode {
+		target, err := d.alias(n)
+		if err != nil {
+			return nil, err
+		}
+		n = target
+	}
+	if n.kind != scalarNode {
+		return nil, &UnmarshalTypeError{Value: describe(n), Type: reflect.TypeOf(""), Line: n.line, Column: n.column}
+	}
+	return n, nil
+}
+
+func lookupField(fs []fields.Field, key string) *fields.Field {
+	for i := range fs {
+		if fs[i].Name == key {
+			return &fs[i]
+		}
+	}
+	for i := range fs {
+		if strings.EqualFold(fs[i].Name, key) {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+// pairs returns keys and values of a mapping node one after another, with
+// merge keys ("<<") expanded. Merged entries come first, so that they are
+// overridden by entries of the mapping itself. Keys of the mapping itself
+// must be unique.
+func (d *decoder) pairs(n *node) ([]*node, error) {
+	var merged, pairs []*node
+	seen := map[string]bool{}
+	for i := 0; i < len(n.children); i += 2 {
+		key, value := n.children[i], n.children[i+1]
+		if key.kind == scalarNode && scalarTag(key) == tagMerge {
+			m, err := d.merge(value)
+			if err != nil {
+				return nil, err
+			}
+			merged = append(merged, m...)
+			continue
+		}
+		if id, ok := d.keyID(key); ok {
+			if seen[id] {
+				return nil, &SyntaxError{Line: key.line, Column: key.column, msg: "duplicate mapping key"}
+			}
+			seen[id] = true
+		}
+		pairs = append(pairs, key, value)
+	}
+	if merged == nil {
+		return pairs, nil
+	}
+	return append(merged, pairs...), nil
+}
+
+// keyID returns the resolved value of a scalar key, with its type, which is
+// equal for equal keys, such as 1 and 0x1 but not 1 and "1". Keys of other
+// kinds are not compared.
+func (d *decoder) keyID(n *node) (string, bool) {
+	if n.kind == aliasNode {
+		target, err := d.alias(n)
+		if err != nil {
+			return "", false
+		}
+		n = target
+	}
+	if n.kind != scalarNode {
+		return "", false
+	}
+	x, err := resolve(n)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%T:%v", x, x), true
+}
+
+func (d *decoder) merge(n *node) ([]*node, error) {
+	invalid := &SyntaxError{Line: n.line, Column: n.column, msg: "map merge requires map or sequence of maps as the value"}
+	if n.kind == aliasNode {
+		target, err := d.alias(n)
+		if err != nil {
+			return nil, err
+		}
+		n = target
+	}
+	switch n.kind {
+	case mappingNode:
+		return d.pairs(n)
+	case sequenceNode:
+		var merged []*node
+		// Earlier mappings take precedence, so they are merged later.
+		for i := len(n.children) - 1; i >= 0; i-- {
+			item := n.children[i]
+			if item.kind == aliasNode {
+				target, err := d.alias(item)
+				if err != nil {
+					return nil, err
+				}
+				item = target
+			}
+			if item.kind != mappingNode {
+				return nil, invalid
+			}
+			pairs, err := d.pairs(item)
+			if err != nil {
+				return nil, err
+			}
+			merged = append(merged, pairs...)
+		}
+		return merged, nil
+	}
+	return nil, invalid
+}
+
+// any decodes a node into nil, bool, int, int64, uint64, float64, string,
+// []byte, []interface{}, map[string]interface{}, or
+// map[interface{}]interface{} if not all keys of a mapping are strings.
+func (d *decoder) any(n *node) (interface{}, error) {
+	switch n.kind {
+	case aliasNode:
+		target, err := d.alias(n)
+		if err != nil {
+			return nil, err
+		}
+		return d.any(target)
+	case scalarNode:
+		x, err := resolve(n)
+		if i, ok := x.(int64); ok && int64(int(i)) == i {
+			return int(i), nil
+		}
+		return x, err
+	case sequenceNode:
+		items := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			item, err := d.any(child)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	pairs, err := d.pairs(n)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]interface{}, 0, len(pairs)/2)
+	values := make([]interface{}, 0, len(pairs)/2)
+	allString := true
+	for i := 0; i < len(pairs); i += 2 {
+		key, err := d.any(pairs[i])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := key.(string); !ok {
+			allString = false
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, typeError(pairs[i], reflect.TypeOf((*interface{})(nil)).Elem())
+		}
+		value, err := d.any(pairs[i+1])
+		if err != nil {
+			return nil, err
+		}
+		keys, values = append(keys, key), append(values, value)
+	}
+	if allString {
+		m := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			m[key.(string)] = values[i]
+		}
+		return m, nil
+	}
+	m := make(map[interface{}]interface{}, len(keys))
+	for i, key := range keys {
+		m[key] = values[i]
+	}
+	return m, nil
+}
+
+// value decodes a node into an *encoding.Value. Numbers keep their literal
+// text if it is a valid JSON number. Merge keys are kept as they are.
+func (d *decoder) value(n *node) (*encoding.Value, error) {
+	switch n.kind {
+	case aliasNode:
+		target, err := d.alias(n)
+		if err != nil {
+			return nil, err
+		}
+		return d.value(target)
+	case scalarNode:
+		x, err := resolve(n)
+		if err != nil {
+			return nil, err
+		}
+		switch t := x.(type) {
+		case nil:
+			return encoding.NewNull(), nil
+		case bool:
+			return encoding.NewBool(t), nil
+		case int64, uint64:
+			return encoding.NewNumber(fmt.Sprint(t)), nil
+		case float64:
+			if _jsonNumberPattern.MatchString(n.value) || math.IsInf(t, 0) || math.IsNaN(t) {
+				return encoding.NewNumber(n.value), nil
+			}
+			return encoding.NewNumber(textual.FormatFloat(t, 64)), nil
+		}
+		return encoding.NewString(n.value), nil
+	case sequenceNode:
+		value := encoding.NewArray()
+		for _, child := range n.children {
+			item, err := d.value(child)
+			if err != nil {
+				return nil, err
+			}
+			value.Append(item)
+		}
+		return value, nil
+	}
+	value := encoding.NewObject()
+	for i := 0; i < len(n.children); i += 2 {
+		key, err := d.key(n.children[i])
+		if err != nil {
+			return nil, err
+		}
+		member, err := d.value(n.children[i+1])
+		if err != nil {
+			return nil, err
+		}
+		value.Members = append(value.Members, &encoding.Member{Key: key.value, Value: member})
+	}
+	return value, nil
+}
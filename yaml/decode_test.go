@@ -0,0 +1,172 @@
+package yaml
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func unmarshal(config *DecoderConfig, data string, v interface{}) error {
+	docs, err := parse([]byte(data))
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.documents(docs, reflect.ValueOf(v).Elem())
+}
+
+type server struct {
+	Host    string        `yaml:"host"`
+	Port    uint16        `json:"port"`
+	Timeout time.Duration `yaml:"timeout"`
+	Ratio   float32
+	Debug   *bool
+	At      *time.Time
+}
+
+func TestDecoder(t *testing.T) {
+	debug := true
+	at := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		src  string
+		v    interface{}
+		want interface{}
+	}{
+		{name: "struct", src: "host: localhost\nport: 8080\ntimeout: 1000\nRATIO: 0.5\ndebug: true\nat: 2021-06-01T00:00:00Z\nextra: [1]\n",
+			v: &server{}, want: server{Host: "localhost", Port: 8080, Timeout: 1000, Ratio: 0.5, Debug: &debug, At: &at}},
+		{name: "null into pointer", src: "debug: null\n", v: &server{Debug: &debug}, want: server{}},
+		{name: "map", src: "b: 2\na: 1\n", v: &map[string]int{"c": 3}, want: map[string]int{"a": 1, "b": 2, "c": 3}},
+		{name: "int keys", src: "1: a\n2: b\n", v: new(map[int]string), want: map[int]string{1: "a", 2: "b"}},
+		{name: "scalar into string", src: "[1, true, ~, x]", v: new([]string), want: []string{"1", "true", "", "x"}},
+		{name: "int into float", src: "3", v: new(float64), want: 3.0},
+		{name: "inf", src: "-.inf", v: new(float32), want: float32(math.Inf(-1))},
+		{name: "hex", src: "0xff", v: new(uint8), want: uint8(255)},
+		{name: "array", src: "[1, 2, 3]", v: &[2]int{}, want: [2]int{1, 2}},
+		{name: "bytes from string", src: "abc", v: new([]byte), want: []byte("abc")},
+		{name: "merge into struct", src: "base: &b {host: h, port: 1}\nsrv:\n  <<: *b\n  port: 2\n", v: new(map[string]server),
+			want: map[string]server{"base": {Host: "h", Port: 1}, "srv": {Host: "h", Port: 2}}},
+		{name: "empty stream", src: "", v: &server{Host: "keep"}, want: server{Host: "keep"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := unmarshal(&DecoderConfig{}, tt.src, tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_TypeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		v      interface{}
+		line   int
+		column int
+	}{
+		{name: "string into int", src: "port: 80\nhost: [a]\n", v: &server{}, line: 2, column: 7},
+		{name: "overflow", src: "port: 70000\n", v: &server{}, line: 1, column: 7},
+		{name: "negative into uint", src: "- -1\n", v: new([]uint), line: 1, column: 3},
+		{name: "mapping into slice", src: "a: 1\n", v: new([]int), line: 1, column: 1},
+		{name: "quoted into bool", src: "'true'", v: new(bool), line: 1, column: 1},
+		{name: "float into int", src: "1.5", v: new(int), line: 1, column: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unmarshal(&DecoderConfig{}, tt.src, tt.v)
+			var te *UnmarshalTypeError
+			if !errors.As(err, &te) {
+				t.Fatalf("want *UnmarshalTypeError, got %v", err)
+			}
+			if te.Line != tt.line || te.Column != tt.column {
+				t.Errorf("got position %d:%d, want %d:%d (%v)", te.Line, te.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *DecoderConfig
+		src    string
+		v      interface{}
+		want   string
+	}{
+		{name: "unknown field", config: &DecoderConfig{DisallowUnknownFields: true}, src: "host: a\nhots: b\n", v: &server{},
+			want: `yaml: line 2, column 1: unknown field "hots"`},
+		{name: "text unmarshaler", config: &DecoderConfig{}, src: "at: yesterday\n", v: &server{},
+			want: "yaml: line 1, column 5: parsing time"},
+		{name: "invalid merge", config: &DecoderConfig{}, src: "a:\n  <<: 1\n", v: new(interface{}),
+			want: "yaml: line 2, column 7: map merge requires map"},
+		{name: "invalid int", config: &DecoderConfig{}, src: "!!int abc", v: new(interface{}),
+			want: "yaml: line 1, column 7: invalid value \"abc\" of !!int"},
+		{name: "duplicate key", config: &DecoderConfig{}, src: "a: 1\na: 2\n", v: new(interface{}),
+			want: "yaml: line 2, column 1: duplicate mapping key"},
+		{name: "duplicate key of struct", config: &DecoderConfig{}, src: "host: a\nport: 1\nhost: b\n", v: &server{},
+			want: "yaml: line 3, column 1: duplicate mapping key"},
+		{name: "duplicate resolved key", config: &DecoderConfig{}, src: "{1: a, 0x1: b}", v: new(interface{}),
+			want: "yaml: line 1, column 8: duplicate mapping key"},
+		{name: "duplicate alias key", config: &DecoderConfig{}, src: "&k a: 1\n*k : 2\n", v: new(interface{}),
+			want: "yaml: line 2, column 1: duplicate mapping key"},
+		{name: "too many documents", config: &DecoderConfig{}, src: "1\n---\n2\n---\n3\n", v: new([2]int),
+			want: "yaml: cannot unmarshal 3 documents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unmarshal(tt.config, tt.src, tt.v)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_DistinctKeys(t *testing.T) {
+	var v interface{}
+	if err := unmarshal(&DecoderConfig{}, "1: a\n\"1\": b\n<<: {1: c}\n", &v); err != nil {
+		t.Fatal(err)
+	}
+	want := map[interface{}]interface{}{1: "a", "1": "b"}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("got %#v, want %#v", v, want)
+	}
+}
+
+func TestDecoder_AliasExpansion(t *testing.T) {
+	laughs := `a: &a ["lol","lol","lol","lol","lol","lol","lol","lol","lol"]
+b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a]
+c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b]
+d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c]
+e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d]
+f: &f [*e,*e,*e,*e,*e,*e,*e,*e,*e]
+g: &g [*f,*f,*f,*f,*f,*f,*f,*f,*f]
+h: &h [*g,*g,*g,*g,*g,*g,*g,*g,*g]
+i: &i [*h,*h,*h,*h,*h,*h,*h,*h,*h]
+`
+	var v interface{}
+	err := unmarshal(&DecoderConfig{}, laughs, &v)
+	if !errors.Is(err, ErrAliasExpansion) {
+		t.Fatalf("want ErrAliasExpansion, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "line") {
+		t.Errorf("want position in error, got %v", err)
+	}
+	small := "a: &a [1, 2, 3]\nb: [*a, *a]\n"
+	if err := unmarshal(&DecoderConfig{MaxAliasExpansion: 7}, small, &v); !errors.Is(err, ErrAliasExpansion) {
+		t.Errorf("want ErrAliasExpansion, got %v", err)
+	}
+	if err := unmarshal(&DecoderConfig{MaxAliasExpansion: 8}, small, &v); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+	if err := unmarshal(&DecoderConfig{MaxAliasExpansion: -1}, small, &v); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
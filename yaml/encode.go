@@ -0,0 +1,482 @@
+package yaml
+
+import (
+	"bytes"
+	se "encoding"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-kita/encoding"
+	"github.com/go-kita/encoding/internal/fields"
+	"github.com/go-kita/encoding/internal/textual"
+)
+
+var _textMarshalerType = reflect.TypeOf((*se.TextMarshaler)(nil)).Elem()
+
+type encoder struct {
+	config *EncoderConfig
+	indent int
+	buf    bytes.Buffer
+}
+
+func (e *encoder) encode(v interface{}) error {
+	if !e.config.MultiDocument {
+		return e.document(reflect.ValueOf(v))
+	}
+	if value, ok := v.(*encoding.Value); ok && value != nil && value.Kind == encoding.ArrayKind {
+		for i, item := range value.Items {
+			if i > 0 {
+				e.buf.WriteString("---\n")
+			}
+			if err := e.document(reflect.ValueOf(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	for (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return e.document(rv)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			e.buf.WriteString("---\n")
+		}
+		if err := e.document(rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) document(v reflect.Value) error {
+	n, err := e.represent(v, 0)
+	if err != nil {
+		return err
+	}
+	switch {
+	case n.kind == scalarNode:
+		e.scalar(n, -1)
+		e.buf.WriteByte('\n')
+	case len(n.children) == 0 && n.kind == mappingNode:
+		e.buf.WriteString("{}\n")
+	case len(n.children) == 0:
+		e.buf.WriteString("[]\n")
+	case n.kind == mappingNode:
+		e.mapping(n, 0, false)
+	default:
+		e.sequence(n, 0, false)
+	}
+	return nil
+}
+
+func scalar(tag, value string) *node {
+	return &node{kind: scalarNode, tag: tag, value: value}
+}
+
+// represent converts v into a node.
+func (e *encoder) represent(v reflect.Value, depth int) (*node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("yaml: exceeded max depth of %d", maxDepth)
+	}
+	if !v.IsValid() {
+		return scalar(tagNull, "null"), nil
+	}
+	t := v.Type()
+	switch {
+	case t == _valueType:
+		value := v.Interface().(encoding.Value)
+		return e.valueNode(&value, depth)
+	case t == reflect.PtrTo(_valueType):
+		return e.valueNode(v.Interface().(*encoding.Value), depth)
+	case t.Implements(_textMarshalerType):
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			return scalar(tagNull, "null"), nil
+		}
+		return e.text(v.Interface().(se.TextMarshaler))
+	case t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(t).Implements(_textMarshalerType):
+		return e.text(v.Addr().Interface().(se.TextMarshaler))
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return scalar(tagNull, "null"), nil
+		}
+		return e.represent(v.Elem(), depth)
+	case reflect.Bool:
+		return scalar(tagBool, strconv.FormatBool(v.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return scalar(tagInt, strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return scalar(tagInt, strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return scalar(tagFloat, formatFloat(v.Float(), t.Bits())), nil
+	case reflect.String:
+		if !utf8.ValidString(v.String()) {
+			return scalar(tagBinary, base64.StdEncoding.EncodeToString([]byte(v.String()))), nil
+		}
+		return scalar(tagStr, v.String()), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return scalar(tagNull, "null"), nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return scalar(tagBinary, base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		return e.sequenceNode(v, depth)
+	case reflect.Array:
+		return e.sequenceNode(v, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			return scalar(tagNull, "null"), nil
+		}
+		return e.mapNode(v, depth)
+	case reflect.Struct:
+		return e.structNode(v, depth)
+	}
+	return nil, fmt.Errorf("yaml: unsupported type %s", t)
+}
+
+func (e *encoder) text(m se.TextMarshaler) (*node, error) {
+	text, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return scalar(tagStr, string(text)), nil
+}
+
+func formatFloat(f float64, bits int) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	case math.IsNaN(f):
+		return ".nan"
+	}
+	return textual.FormatFloat(f, bits)
+}
+
+func (e *encoder) sequenceNode(v reflect.Value, depth int) (*node, error) {
+	n := &node{kind: sequenceNode, children: make([]*node, 0, v.Len())}
+	for i := 0; i < v.Len(); i++ {
+		item, err := e.represent(v.Index(i), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, item)
+	}
+	return n, nil
+}
+
+// mapNode converts a map into a mapping node with entries sorted by keys.
+func (e *encoder) mapNode(v reflect.Value, depth int) (*node, error) {
+	type entry struct {
+		key   *node
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := e.represent(iter.Key(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if key.kind != scalarNode {
+			return nil, fmt.Errorf("yaml: unsupported map key type %s", v.Type().Key())
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return lessKey(entries[i].key, entries[j].key) })
+	n := &node{kind: mappingNode, children: make([]*node, 0, 2*len(entries))}
+	for _, en := range entries {
+		value, err := e.represent(en.value, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, en.key, value)
+	}
+	return n, nil
+}
+
+// lessKey orders numeric keys by their values, and other keys by their text.
+func lessKey(a, b *node) bool {
+	if (a.tag == tagInt || a.tag == tagFloat) && (b.tag == tagInt || b.tag == tagFloat) {
+		x, _ := strconv.ParseFloat(a.value, 64)
+		y, _ := strconv.ParseFloat(b.value, 64)
+		if x != y {
+			return x < y
+		}
+	}
+	return a.value < b.value
+}
+
+func (e *encoder) structNode(v reflect.Value, depth int) (*node, error) {
+	fs := fields.Of(v.Type(), _tags...)
+	n := &node{kind: mappingNode, children: make([]*node, 0, 2*len(fs))}
+	for _, f := range fs {
+		fv, ok := fields.ByIndex(v, f.Index, false)
+		if !ok || f.OmitEmpty() && fields.IsEmpty(fv) {
+			continue
+		}
+		value, err := e.represent(fv, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("yaml: field %s: %w", f.Name, err)
+		}
+		n.children = append(n.children, scalar(tagStr, f.Name), value)
+	}
+	return n, nil
+}
+
+func (e *encoder) valueNode(value *encoding.Value, depth int) (*node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("yaml: exceeded max depth of %d", maxDepth)
+	}
+	if value == nil {
+		return scalar(tagNull, "null"), nil
+	}
+	switch value.Kind {
+	case encoding.NullKind:
+		return scalar(tagNull, "null"), nil
+	case encoding.BoolKind:
+		return scalar(tagBool, strconv.FormatBool(value.Bool)), nil
+	case encoding.NumberKind:
+		return scalar(tagFloat, value.Text), nil
+	case encoding.StringKind:
+		return scalar(tagStr, value.Text), nil
+	case encoding.ArrayKind:
+		n := &node{kind: sequenceNode, children: make([]*node, 0, len(value.Items))}
+		for _, item := range value.Items {
+			child, err := e.valueNode(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		}
+		return n, nil
+	case encoding.ObjectKind:
+		n := &node{kind: mappingNode, children: make([]*node, 0, 2*len(value.Members))}
+		for _, m := range value.Members {
+			child, err := e.valueNode(m.Value, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, scalar(tagStr, m.Key), child)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("yaml: unsupported value kind %s", value.Kind)
+}
+
+func (e *encoder) spaces(n int) {
+	for i := 0; i < n; i++ {
+		e.buf.WriteByte(' ')
+	}
+}
+
+// mapping writes a non-empty block mapping at indent. If inline is true, the
+// first entry is written at the current position.
+func (e *encoder) mapping(n *node, indent int, inline bool) {
+	for i := 0; i < len(n.children); i += 2 {
+		if i > 0 || !inline {
+			e.spaces(indent)
+		}
+		key := n.children[i]
+		if key.tag == tagStr {
+			e.str(key.value, indent, true)
+		} else {
+			e.scalar(key, indent)
+		}
+		e.buf.WriteByte(':')
+		e.child(n.children[i+1], indent, true)
+	}
+}
+
+// sequence writes a non-empty block sequence at indent. If inline is true,
+// the first entry is written at the current position.
+func (e *encoder) sequence(n *node, indent int, inline bool) {
+	for i, item := range n.children {
+		if i > 0 || !inline {
+			e.spaces(indent)
+		}
+		e.buf.WriteByte('-')
+		e.child(item, indent, false)
+	}
+}
+
+// child writes the value of a mapping entry or an entry of a sequence, whose
+// parent collection is at indent. A collection in a sequence is written in
+// compact form, on the line of the "-".
+func (e *encoder) child(n *node, indent int, inMapping bool) {
+	switch {
+	case n.kind == scalarNode:
+		e.buf.WriteByte(' ')
+		e.scalar(n, indent)
+		e.buf.WriteByte('\n')
+	case len(n.children) == 0 && n.kind == mappingNode:
+		e.buf.WriteString(" {}\n")
+	case len(n.children) == 0:
+		e.buf.WriteString(" []\n")
+	case inMapping:
+		e.buf.WriteByte('\n')
+		if n.kind == mappingNode {
+			e.mapping(n, indent+e.indent, false)
+		} else {
+			e.sequence(n, indent+e.indent, false)
+		}
+	default:
+		e.buf.WriteByte(' ')
+		if n.kind == mappingNode {
+			e.mapping(n, indent+2, true)
+		} else {
+			e.sequence(n, indent+2, true)
+		}
+	}
+}
+
+// scalar writes a scalar node, whose parent collection is at indent.
+func (e *encoder) scalar(n *node, indent int) {
+	switch n.tag {
+	case tagStr:
+		e.str(n.value, indent, false)
+	case tagBinary:
+		e.buf.WriteString("!!binary ")
+		e.buf.WriteString(n.value)
+	default:
+		e.buf.WriteString(n.value)
+	}
+}
+
+// str writes a string as a plain scalar if possible, otherwise as a literal
+// block scalar if it has multiple lines, or a double quoted scalar.
+func (e *encoder) str(s string, indent int, key bool) {
+	switch {
+	case plainSafe(s):
+		e.buf.WriteString(s)
+	case !key && literalSafe(s):
+		e.literal(s, indent)
+	default:
+		e.buf.WriteString(quote(s))
+	}
+}
+
+// plainSafe reports whether s can be written as a plain scalar and be read
+// back as the same string.
+func plainSafe(s string) bool {
+	if s == "" || s == "<<" || implicitTag(s) != tagStr ||
+		strings.HasPrefix(s, "---") || strings.HasPrefix(s, "...") {
+		return false
+	}
+	switch s[0] {
+	case '-', '?', ':':
+		if len(s) == 1 || s[1] == ' ' {
+			return false
+		}
+	case ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`', ' ':
+		return false
+	}
+	if s[len(s)-1] == ' ' || s[len(s)-1] == ':' || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	for _, r := range s {
+		if r != ' ' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// literalSafe reports whether s can be written as a literal block scalar and
+// be read back as the same string.
+func literalSafe(s string) bool {
+	if strings.IndexByte(s, '\n') < 0 || strings.TrimSpace(s) == "" {
+		return false
+	}
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" && strings.TrimLeft(line, " ") == "" {
+			return false
+		}
+		for _, r := range line {
+			if r != ' ' && r != '\t' && !unicode.IsPrint(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (e *encoder) literal(s string, indent int) {
+	body := strings.TrimRight(s, "\n")
+	trailing := len(s) - len(body)
+	lines := strings.Split(body, "\n")
+	e.buf.WriteByte('|')
+	if first := strings.TrimLeft(body, "\n"); first[0] == ' ' {
+		e.buf.WriteString(strconv.Itoa(e.indent))
+	}
+	switch trailing {
+	case 0:
+		e.buf.WriteByte('-')
+	case 1:
+	default:
+		e.buf.WriteByte('+')
+	}
+	contentIndent := e.indent
+	if indent > 0 {
+		contentIndent += indent
+	}
+	for _, line := range lines {
+		e.buf.WriteByte('\n')
+		if line != "" {
+			e.spaces(contentIndent)
+			e.buf.WriteString(line)
+		}
+	}
+	for i := 1; i < trailing; i++ {
+		e.buf.WriteByte('\n')
+	}
+}
+
+// quote returns s as a double quoted scalar.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case 0:
+			b.WriteString(`\0`)
+		default:
+			switch {
+			case r == ' ' || unicode.IsPrint(r):
+				b.WriteRune(r)
+			case r <= 0xff:
+				fmt.Fprintf(&b, `\x%02X`, r)
+			case r <= 0xffff:
+				fmt.Fprintf(&b, `\u%04X`, r)
+			default:
+				fmt.Fprintf(&b, `\U%08X`, r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package yaml
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type failingText struct{}
+
+func (failingText) MarshalText() ([]byte, error) {
+	return nil, errors.New("yaml: failing")
+}
+
+func marshal(config *EncoderConfig, v interface{}) (string, error) {
+	e := &encoder{config: config, indent: config.Indent}
+	if e.indent == 0 {
+		e.indent = DefaultIndent
+	}
+	err := e.encode(v)
+	return e.buf.String(), err
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *EncoderConfig
+		v      interface{}
+		want   string
+	}{
+		{name: "null", v: nil, want: "null\n"},
+		{name: "scalars", v: []interface{}{true, 1, uint8(2), 1.5, float32(0.1), math.Inf(-1), math.NaN()},
+			want: "- true\n- 1\n- 2\n- 1.5\n- 0.1\n- -.inf\n- .nan\n"},
+		{name: "quoted strings", v: []string{"", "null", "1", "true", "- a", "a: b", "a #b", " a", "a ", "<<", "---", "'", "*a", "tab\there", "é", "\x01"},
+			want: "- \"\"\n- \"null\"\n- \"1\"\n- \"true\"\n- \"- a\"\n- \"a: b\"\n- \"a #b\"\n- \" a\"\n- \"a \"\n- \"<<\"\n- \"---\"\n- \"'\"\n- \"*a\"\n- \"tab\\there\"\n- é\n- \"\\x01\"\n"},
+		{name: "plain strings", v: []string{"-a", "a:b", "a#b", "a b", "http://x"},
+			want: "- -a\n- a:b\n- a#b\n- a b\n- http://x\n"},
+		{name: "literal", v: map[string]string{"a": "x\ny", "b": "x\n", "c": "x\n\n", "d": " x\ny\n"},
+			want: "a: |-\n  x\n  y\nb: |\n  x\nc: |+\n  x\n\nd: |2\n   x\n  y\n"},
+		{name: "top-level literal", v: "x\ny\n", want: "|\n  x\n  y\n"},
+		{name: "literal not safe", v: []string{"x\n  \ny", "\n"}, want: "- \"x\\n  \\ny\"\n- \"\\n\"\n"},
+		{name: "empty collections", v: map[string]interface{}{"a": []int{}, "b": map[string]int{}, "c": []int(nil)},
+			want: "a: []\nb: {}\nc: null\n"},
+		{name: "top-level empty", v: struct{}{}, want: "{}\n"},
+		{name: "nested sequences", v: [][]int{{1, 2}, {}, {3}}, want: "- - 1\n  - 2\n- []\n- - 3\n"},
+		{name: "sequence of mappings", v: []map[string]interface{}{{"a": 1, "b": []int{2}}},
+			want: "- a: 1\n  b:\n    - 2\n"},
+		{name: "numeric keys", v: map[int]string{10: "x", 9: "y"}, want: "9: y\n10: x\n"},
+		{name: "indent", config: &EncoderConfig{Indent: 4}, v: map[string]interface{}{"a": map[string][]int{"b": {1}}, "c": "x\n"},
+			want: "a:\n    b:\n        - 1\nc: |\n    x\n"},
+		{name: "multi-document", config: &EncoderConfig{MultiDocument: true}, v: []interface{}{1, map[string]int{"a": 1}},
+			want: "1\n---\na: 1\n"},
+		{name: "multi-document bytes", config: &EncoderConfig{MultiDocument: true}, v: []byte("a"),
+			want: "!!binary YQ==\n"},
+		{name: "invalid utf-8", v: "\xff", want: "!!binary /w==\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config == nil {
+				config = &EncoderConfig{}
+			}
+			got, err := marshal(config, tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	values := []interface{}{
+		[]interface{}{"", "null", "- a", "a: b", " a", "x\ny\n", " x\n\n", "x\n\n\n", "\ttab", "\u2028", "\xff", "é"},
+		map[string]interface{}{"a b": 1, "null": "~", "- x": []interface{}{}, "k\nl": "v"},
+		[]interface{}{math.MaxInt64, uint64(math.MaxUint64), 1e21, 1e-7, -0.5},
+	}
+	for _, v := range values {
+		data, err := marshal(&EncoderConfig{}, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got interface{}
+		if err := unmarshal(&DecoderConfig{}, data, &got); err != nil {
+			t.Fatalf("%v\n%s", err, data)
+		}
+		want := v
+		if items, ok := v.([]interface{}); ok && items[0] == "" {
+			want = append([]interface{}{}, items...)
+			want.([]interface{})[10] = []byte("\xff")
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %#v, want %#v\n%s", got, want, data)
+		}
+	}
+}
+
+func TestEncoder_Errors(t *testing.T) {
+	var cyclic []interface{}
+	cyclic = append(cyclic, &cyclic)
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "unsupported", v: make(chan int)},
+		{name: "map key", v: map[[1]int]int{{1}: 1}},
+		{name: "text marshaler", v: failingText{}},
+		{name: "field", v: struct{ F func() }{}},
+		{name: "cyclic", v: cyclic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := marshal(&EncoderConfig{}, tt.v); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
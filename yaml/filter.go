@@ -0,0 +1,148 @@
+package yaml
+
+import (
+	"context"
+
+	"github.com/go-kita/encoding"
+)
+
+// DefaultIndent is the default number of spaces to indent nested collections.
+const DefaultIndent = 2
+
+// DefaultMaxAliasExpansion is the default maximum number of nodes which can be
+// expanded from aliases in a stream.
+const DefaultMaxAliasExpansion = 100000
+
+// EncoderConfig is the configuration of encoding.
+type EncoderConfig struct {
+	// Indent is the number of spaces to indent nested collections, between 2
+	// and 9. Zero means DefaultIndent.
+	Indent int
+	// MultiDocument encodes each element of a slice or an array as a document
+	// of a multi-document stream.
+	MultiDocument bool
+}
+
+// DecoderConfig is the configuration of decoding.
+type DecoderConfig struct {
+	// MaxAliasExpansion is the maximum number of nodes which can be expanded
+	// from aliases in a stream, which protects against exponential expansion
+	// of nested aliases ("billion laughs"). Zero means
+	// DefaultMaxAliasExpansion, a negative value means no limit.
+	MaxAliasExpansion int
+	// DisallowUnknownFields fails decoding a mapping into a struct if the
+	// mapping has a key which matches no field.
+	DisallowUnknownFields bool
+}
+
+// DecoderOption is a function which modifies a *DecoderConfig.
+type DecoderOption func(config *DecoderConfig)
+
+// decoderOptionKey is the context.Context key for storing/extracting DecoderOption.
+type decoderOptionKey struct {
+}
+
+// contextWithDecoderOption wraps DecoderOption into a new context.Context.
+func contextWithDecoderOption(ctx context.Context, opt ...DecoderOption) context.Context {
+	return context.WithValue(ctx, decoderOptionKey{}, opt)
+}
+
+// decoderOptionFromContext extracts DecoderOption from a context.Context.
+func decoderOptionFromContext(ctx context.Context) []DecoderOption {
+	if opt, ok := ctx.Value(decoderOptionKey{}).([]DecoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optUnmarshaler struct {
+	opt         []DecoderOption
+	unmarshaler encoding.Unmarshaler
+}
+
+func (o *optUnmarshaler) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	ctx = contextWithDecoderOption(ctx, o.opt...)
+	return o.unmarshaler.Unmarshal(ctx, data, v)
+}
+
+// WithDecoderOption returns a proxy of encoding.Unmarshaler.
+// It wraps DecoderOption into context.Context, and then calls Unmarshal method
+// of the underlying encoding.Unmarshaler with the new context.Context.
+func WithDecoderOption(unmarshaler encoding.Unmarshaler, opt ...DecoderOption) encoding.Unmarshaler {
+	return &optUnmarshaler{
+		opt:         opt,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// MaxAliasExpansion produces a DecoderOption which sets the maximum number of
+// nodes which can be expanded from aliases in a stream.
+func MaxAliasExpansion(n int) DecoderOption {
+	return func(config *DecoderConfig) {
+		config.MaxAliasExpansion = n
+	}
+}
+
+// DisallowUnknownFields produces a DecoderOption which disallows keys matching
+// no field when decoding a mapping into a struct.
+func DisallowUnknownFields() DecoderOption {
+	return func(config *DecoderConfig) {
+		config.DisallowUnknownFields = true
+	}
+}
+
+// EncoderOption is a function which modifies a *EncoderConfig.
+type EncoderOption func(config *EncoderConfig)
+
+// encoderOptionKey is the context.Context key for storing/extracting EncoderOption
+type encoderOptionKey struct {
+}
+
+// contextWithEncoderOption wraps EncoderOption into a new context.Context
+func contextWithEncoderOption(ctx context.Context, opt ...EncoderOption) context.Context {
+	return context.WithValue(ctx, encoderOptionKey{}, opt)
+}
+
+// encoderOptionFromContext extracts EncoderOption from a context.Context.
+func encoderOptionFromContext(ctx context.Context) []EncoderOption {
+	if opt, ok := ctx.Value(encoderOptionKey{}).([]EncoderOption); ok {
+		return opt
+	}
+	return nil
+}
+
+type optMarshaler struct {
+	opt       []EncoderOption
+	marshaler encoding.Marshaler
+}
+
+func (o *optMarshaler) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	ctx = contextWithEncoderOption(ctx, o.opt...)
+	return o.marshaler.Marshal(ctx, v)
+}
+
+// WithEncoderOption returns a proxy of encoding.Marshaler.
+// It wraps EncoderOption into context.Context, and then calls Marshal method
+// of the underlying encoding.Marshaler with the new context.Context.
+func WithEncoderOption(marshaler encoding.Marshaler, opt ...EncoderOption) encoding.Marshaler {
+	return &optMarshaler{
+		opt:       opt,
+		marshaler: marshaler,
+	}
+}
+
+// Indent produces an EncoderOption which sets the number of spaces to indent
+// nested collections.
+func Indent(n int) EncoderOption {
+	return func(config *EncoderConfig) {
+		config.Indent = n
+	}
+}
+
+// MultiDocument produces an EncoderOption which encodes each element of a
+// slice or an array as a document of a multi-document stream.
+func MultiDocument() EncoderOption {
+	return func(config *EncoderConfig) {
+		config.MultiDocument = true
+	}
+}
@@ -0,0 +1,34 @@
+package yaml
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithEncoderOption(t *testing.T) {
+	m := WithEncoderOption(&codec{}, Indent(4), MultiDocument())
+	got, err := m.Marshal(context.Background(), []interface{}{map[string][]int{"a": {1}}, "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a:\n    - 1\n---\nx\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := WithEncoderOption(&codec{}, Indent(10)).Marshal(context.Background(), 1); err == nil {
+		t.Error("want error of invalid indent")
+	}
+}
+
+func TestWithDecoderOption(t *testing.T) {
+	u := WithDecoderOption(&codec{}, DisallowUnknownFields())
+	var v inner
+	if err := u.Unmarshal(context.Background(), []byte("tags: []\nname: x\n"), &v); err == nil {
+		t.Error("want error of unknown field")
+	}
+	u = WithDecoderOption(&codec{}, MaxAliasExpansion(1))
+	var a interface{}
+	if err := u.Unmarshal(context.Background(), []byte("a: &a [1]\nb: *a\n"), &a); !errors.Is(err, ErrAliasExpansion) {
+		t.Errorf("want ErrAliasExpansion, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package yaml
+
+import (
+	"math"
+)
+
+type nodeKind uint8
+
+const (
+	scalarNode nodeKind = iota
+	sequenceNode
+	mappingNode
+	aliasNode
+)
+
+type scalarStyle uint8
+
+const (
+	plainStyle scalarStyle = iota
+	singleQuotedStyle
+	doubleQuotedStyle
+	literalStyle
+	foldedStyle
+)
+
+// node is a node of the representation graph of a YAML document.
+type node struct {
+	kind  nodeKind
+	style scalarStyle
+	// tag is the resolved tag of the node, or empty if the node has no tag.
+	tag string
+	// value is the content of a scalar node.
+	value string
+	// children holds items of a sequence, or keys and values of a mapping one
+	// after another.
+	children []*node
+	// alias is the node an alias refers to.
+	alias *node
+	// line and column are the 1-based position where the node starts.
+	line   int
+	column int
+	// size is the number of nodes of the expanded tree rooted at the node,
+	// computed on demand.
+	size int
+}
+
+// expandedSize returns the number of nodes of the tree rooted at n, with
+// aliases expanded. The result saturates at math.MaxInt32.
+func (n *node) expandedSize() int {
+	if n.kind == aliasNode {
+		return n.alias.expandedSize()
+	}
+	if n.size == 0 {
+		size := 1
+		for _, child := range n.children {
+			size += child.expandedSize()
+			if size > math.MaxInt32 {
+				size = math.MaxInt32
+				break
+			}
+		}
+		n.size = size
+	}
+	return n.size
+}
@@ -0,0 +1,1019 @@
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError is an error of malformed YAML data.
+type SyntaxError struct {
+	// Line and Column are the 1-based position where the error occurred.
+	Line   int
+	Column int
+	msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("yaml: line %d, column %d: %s", e.Line, e.Column, e.msg)
+}
+
+// maxDepth is the maximum nesting depth of nodes.
+const maxDepth = 1000
+
+// blockContext is the context a block node appears in.
+type blockContext uint8
+
+const (
+	// blockIn is a node starting on a line of its own.
+	blockIn blockContext = iota
+	// blockKeyValue is the value of a mapping entry, on the line of the key.
+	blockKeyValue
+	// blockSeqEntry is an entry of a sequence, on the line of the "-".
+	blockSeqEntry
+)
+
+type mark struct {
+	pos  int
+	line int
+	// col is the 0-based column, counted in characters.
+	col int
+}
+
+type parser struct {
+	mark
+	src     string
+	depth   int
+	anchors map[string]*node
+}
+
+// parse parses a YAML stream into the root nodes of its documents.
+func parse(data []byte) ([]*node, error) {
+	src := strings.TrimPrefix(string(data), "\uFEFF")
+	if strings.IndexByte(src, '\r') >= 0 {
+		src = strings.ReplaceAll(src, "\r\n", "\n")
+		src = strings.ReplaceAll(src, "\r", "\n")
+	}
+	p := &parser{src: src, mark: mark{line: 1}}
+	return p.stream()
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Line: p.line, Column: p.col + 1, msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// peekAt returns the byte at offset n, or 0 beyond the end of data.
+func (p *parser) peekAt(n int) byte {
+	if p.pos+n >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+n]
+}
+
+func (p *parser) peek() byte {
+	return p.peekAt(0)
+}
+
+func (p *parser) advance(n int) {
+	for i := 0; i < n && p.pos < len(p.src); i++ {
+		c := p.src[p.pos]
+		p.pos++
+		if c == '\n' {
+			p.line++
+			p.col = 0
+		} else if c&0xc0 != 0x80 {
+			p.col++
+		}
+	}
+}
+
+// spaceAt reports whether the byte at offset n is a space, a tab, a line
+// break or beyond the end of data.
+func (p *parser) spaceAt(n int) bool {
+	if p.pos+n >= len(p.src) {
+		return true
+	}
+	switch p.src[p.pos+n] {
+	case ' ', '\t', '\n':
+		return true
+	}
+	return false
+}
+
+func isFlowIndicator(c byte) bool {
+	return c == ',' || c == '[' || c == ']' || c == '{' || c == '}'
+}
+
+func (p *parser) atMarker(marker string) bool {
+	return p.col == 0 && strings.HasPrefix(p.src[p.pos:], marker) && p.spaceAt(len(marker))
+}
+
+func (p *parser) atDocMarker() bool {
+	return p.atMarker("---") || p.atMarker("...")
+}
+
+// seqIndicator reports whether a block sequence entry starts here.
+func (p *parser) seqIndicator() bool {
+	return p.peek() == '-' && p.spaceAt(1)
+}
+
+func (p *parser) skipSpace() {
+	for c := p.peek(); c == ' ' || c == '\t'; c = p.peek() {
+		p.advance(1)
+	}
+}
+
+func (p *parser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.advance(1)
+	}
+}
+
+// skipToContent skips white spaces, line breaks and comments, and reports
+// whether a line break is crossed.
+func (p *parser) skipToContent() (bool, error) {
+	crossed := false
+	lineStart := p.pos
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t':
+			p.advance(1)
+		case '\n':
+			p.advance(1)
+			crossed = true
+			lineStart = p.pos
+		case '#':
+			p.skipComment()
+		default:
+			if crossed && strings.IndexByte(p.src[lineStart:p.pos], '\t') >= 0 {
+				return crossed, p.errorf("found a tab character where an indentation space is expected")
+			}
+			return crossed, nil
+		}
+	}
+	return crossed, nil
+}
+
+// lineEnd skips white spaces and a comment to the end of the line.
+func (p *parser) lineEnd() error {
+	p.skipSpace()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if !p.eof() && p.peek() != '\n' {
+		return p.errorf("did not find expected comment or line break")
+	}
+	return nil
+}
+
+// flowSpace skips white spaces, line breaks and comments in flow context.
+func (p *parser) flowSpace() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\n':
+			p.advance(1)
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// keyIndicator reports whether a ":" indicating a mapping value follows, and
+// moves to it if so.
+func (p *parser) keyIndicator() bool {
+	m := p.mark
+	p.skipSpace()
+	if p.peek() == ':' && p.spaceAt(1) {
+		return true
+	}
+	p.mark = m
+	return false
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return p.errorf("exceeded max depth of %d", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) empty() *node {
+	return &node{kind: scalarNode, line: p.line, column: p.col + 1}
+}
+
+func (p *parser) define(anchor string, n *node) *node {
+	if anchor != "" {
+		p.anchors[anchor] = n
+	}
+	return n
+}
+
+func (p *parser) stream() ([]*node, error) {
+	var docs []*node
+	for {
+		if _, err := p.skipToContent(); err != nil {
+			return nil, err
+		}
+		for !p.eof() && p.col == 0 && p.peek() == '%' {
+			p.skipComment()
+			if _, err := p.skipToContent(); err != nil {
+				return nil, err
+			}
+		}
+		if p.eof() {
+			return docs, nil
+		}
+		if p.atMarker("...") {
+			p.advance(3)
+			if err := p.lineEnd(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if p.atMarker("---") {
+			p.advance(3)
+		}
+		p.anchors = make(map[string]*node)
+		doc, err := p.document()
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+		if err := p.lineEnd(); err != nil {
+			return nil, err
+		}
+		if _, err := p.skipToContent(); err != nil {
+			return nil, err
+		}
+		if !p.eof() && !p.atDocMarker() {
+			return nil, p.errorf("did not find expected <document start>")
+		}
+	}
+}
+
+func (p *parser) document() (*node, error) {
+	m := p.mark
+	if _, err := p.skipToContent(); err != nil {
+		return nil, err
+	}
+	if p.eof() || p.atDocMarker() {
+		p.mark = m
+		return p.empty(), nil
+	}
+	return p.blockNode(-1, blockIn)
+}
+
+// startsBlock reports whether a block node more indented than indent starts
+// here. If seqAtIndent is true, a block sequence at indent is also accepted.
+func (p *parser) startsBlock(indent int, seqAtIndent bool) bool {
+	if p.eof() || p.atDocMarker() {
+		return false
+	}
+	return p.col > indent || seqAtIndent && p.col == indent && p.seqIndicator()
+}
+
+// properties parses the anchor and the tag of a node.
+func (p *parser) properties() (anchor, tag string, err error) {
+	for {
+		switch p.peek() {
+		case '&':
+			if anchor != "" {
+				return "", "", p.errorf("found duplicate anchor")
+			}
+			p.advance(1)
+			if anchor = p.name(); anchor == "" {
+				return "", "", p.errorf("did not find expected alphabetic or numeric character")
+			}
+		case '!':
+			if tag != "" {
+				return "", "", p.errorf("found duplicate tag")
+			}
+			if tag, err = p.tag(); err != nil {
+				return "", "", err
+			}
+		default:
+			return anchor, tag, nil
+		}
+		p.skipSpace()
+	}
+}
+
+// name scans the name of an anchor or an alias.
+func (p *parser) name() string {
+	start := p.pos
+	for !p.spaceAt(0) && !isFlowIndicator(p.peek()) {
+		p.advance(1)
+	}
+	return p.src[start:p.pos]
+}
+
+// tag scans a tag and resolves the tag shorthand of the secondary tag handle
+// "!!" to the YAML tag prefix.
+func (p *parser) tag() (string, error) {
+	p.advance(1)
+	if p.peek() == '<' {
+		end := strings.IndexByte(p.src[p.pos:], '>')
+		if end < 0 {
+			return "", p.errorf("did not find the expected '>'")
+		}
+		tag := p.src[p.pos+1 : p.pos+end]
+		p.advance(end + 1)
+		return tag, nil
+	}
+	prefix := "!"
+	if p.peek() == '!' {
+		p.advance(1)
+		prefix = tagPrefix
+	}
+	suffix := p.name()
+	if prefix == tagPrefix && suffix == "" {
+		return "", p.errorf("did not find expected tag URI")
+	}
+	return prefix + suffix, nil
+}
+
+// blockNode parses a node in block context. The node must be more indented
+// than indent, which is the indentation of the parent collection.
+func (p *parser) blockNode(indent int, ctx blockContext) (*node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	line, column := p.line, p.col+1
+	anchor, tag, err := p.properties()
+	if err != nil {
+		return nil, err
+	}
+	// Properties followed by a line break belong to the node on the
+	// following lines, otherwise they may belong to an implicit key.
+	ownLine := false
+	if anchor != "" || tag != "" {
+		m := p.mark
+		crossed, err := p.skipToContent()
+		if err != nil {
+			return nil, err
+		}
+		if crossed || p.eof() {
+			if !p.startsBlock(indent, ctx == blockKeyValue) {
+				p.mark = m
+				return p.define(anchor, &node{kind: scalarNode, tag: tag, line: line, column: column}), nil
+			}
+			ownLine = true
+			ctx = blockIn
+			line, column = p.line, p.col+1
+		}
+	}
+	var n *node
+	switch c := p.peek(); {
+	case c == '-' && p.spaceAt(1):
+		if ctx == blockKeyValue {
+			return nil, p.errorf("block sequence entries are not allowed in this context")
+		}
+		n, err = p.blockSequence()
+	case c == '?' && p.spaceAt(1):
+		return nil, p.errorf("explicit mapping keys are not supported")
+	case c == '|' || c == '>':
+		n, err = p.blockScalar(indent)
+	case c == '[' || c == '{':
+		n, err = p.flowCollection()
+	default:
+		switch c {
+		case '*':
+			n, err = p.alias()
+		case '"', '\'':
+			n, err = p.quotedScalar()
+		default:
+			n, err = p.plainScalar(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyIndicator() {
+			break
+		}
+		if ctx == blockKeyValue {
+			return nil, p.errorf("mapping values are not allowed in this context")
+		}
+		if !ownLine {
+			// The properties belong to the key.
+			if n.kind == aliasNode && (anchor != "" || tag != "") {
+				return nil, p.errorf("an alias node must not have properties")
+			}
+			if tag != "" {
+				n.tag = tag
+			}
+			p.define(anchor, n)
+			anchor, tag = "", ""
+		} else {
+			line, column = n.line, n.column
+		}
+		n, err = p.blockMapping(line, column, n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n.kind == aliasNode && (anchor != "" || tag != "") {
+		return nil, p.errorf("an alias node must not have properties")
+	}
+	if tag != "" {
+		n.tag = tag
+	}
+	return p.define(anchor, n), nil
+}
+
+// blockMapping parses a block mapping starting at line and column, whose
+// first key has been parsed. It must be called at the ":" after the key.
+func (p *parser) blockMapping(line, column int, key *node) (*node, error) {
+	n := &node{kind: mappingNode, line: line, column: column}
+	col := column - 1
+	for {
+		p.advance(1)
+		value, err := p.mappingValue(col)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, key, value)
+		if err := p.lineEnd(); err != nil {
+			return nil, err
+		}
+		m := p.mark
+		if _, err := p.skipToContent(); err != nil {
+			return nil, err
+		}
+		if p.eof() || p.atDocMarker() || p.col < col {
+			p.mark = m
+			return n, nil
+		}
+		if p.col > col {
+			return nil, p.errorf("did not find expected key")
+		}
+		if key, err = p.mappingKey(col); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) mappingKey(col int) (*node, error) {
+	anchor, tag, err := p.properties()
+	if err != nil {
+		return nil, err
+	}
+	var key *node
+	switch c := p.peek(); {
+	case c == '*':
+		if anchor != "" || tag != "" {
+			return nil, p.errorf("an alias node must not have properties")
+		}
+		key, err = p.alias()
+	case c == '"' || c == '\'':
+		key, err = p.quotedScalar()
+	case c == '?' && p.spaceAt(1):
+		return nil, p.errorf("explicit mapping keys are not supported")
+	case c == '-' && p.spaceAt(1), c == '[', c == '{', c == '|', c == '>':
+		return nil, p.errorf("did not find expected key")
+	default:
+		key, err = p.plainScalar(col)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyIndicator() {
+		return nil, p.errorf("could not find expected ':'")
+	}
+	if tag != "" {
+		key.tag = tag
+	}
+	return p.define(anchor, key), nil
+}
+
+// mappingValue parses the value of a mapping entry after the ":". A block
+// sequence value may have the same indentation as the mapping.
+func (p *parser) mappingValue(col int) (*node, error) {
+	m := p.mark
+	crossed, err := p.skipToContent()
+	if err != nil {
+		return nil, err
+	}
+	if !crossed && !p.eof() {
+		return p.blockNode(col, blockKeyValue)
+	}
+	if p.startsBlock(col, true) {
+		return p.blockNode(col, blockIn)
+	}
+	p.mark = m
+	return p.empty(), nil
+}
+
+// blockSequence parses a block sequence. It must be called at the first "-".
+func (p *parser) blockSequence() (*node, error) {
+	n := &node{kind: sequenceNode, line: p.line, column: p.col + 1}
+	col := p.col
+	for {
+		p.advance(1)
+		m := p.mark
+		crossed, err := p.skipToContent()
+		if err != nil {
+			return nil, err
+		}
+		var item *node
+		switch {
+		case !crossed && !p.eof():
+			item, err = p.blockNode(col, blockSeqEntry)
+		case p.startsBlock(col, false):
+			item, err = p.blockNode(col, blockIn)
+		default:
+			p.mark = m
+			item = p.empty()
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, item)
+		if err := p.lineEnd(); err != nil {
+			return nil, err
+		}
+		m = p.mark
+		if _, err := p.skipToContent(); err != nil {
+			return nil, err
+		}
+		if p.eof() || p.atDocMarker() || p.col < col {
+			p.mark = m
+			return n, nil
+		}
+		if p.col > col {
+			return nil, p.errorf("did not find expected '-' indicator")
+		}
+		if !p.seqIndicator() {
+			p.mark = m
+			return n, nil
+		}
+	}
+}
+
+// plainScalar parses a plain scalar in block context. Continuation lines must
+// be more indented than indent.
+func (p *parser) plainScalar(indent int) (*node, error) {
+	n := &node{kind: scalarNode, line: p.line, column: p.col + 1}
+	if err := p.plainStart(); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString(p.plainLine(false))
+	for p.eof() || p.peek() == '\n' {
+		m := p.mark
+		breaks := 0
+		for p.peek() == '\n' {
+			p.advance(1)
+			breaks++
+			p.skipSpace()
+		}
+		if breaks == 0 || p.eof() || p.atDocMarker() || p.col <= indent || p.peek() == '#' {
+			p.mark = m
+			break
+		}
+		if breaks == 1 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(strings.Repeat("\n", breaks-1))
+		}
+		b.WriteString(p.plainLine(false))
+		if p.peek() == ':' {
+			return nil, p.errorf("mapping values are not allowed in this context")
+		}
+	}
+	n.value = b.String()
+	return n, nil
+}
+
+// plainStart checks the first character of a plain scalar.
+func (p *parser) plainStart() error {
+	c := p.peek()
+	switch c {
+	case '-', '?', ':':
+		if !p.spaceAt(1) {
+			return nil
+		}
+	case ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`':
+	default:
+		if c >= 0x20 || c == '\t' {
+			return nil
+		}
+	}
+	return p.errorf("found character that cannot start any token")
+}
+
+// plainLine scans the content of a plain scalar to the end of the line, a
+// comment or a ":" indicating a mapping value. In flow context, it also stops
+// at flow indicators. Trailing white spaces are excluded.
+func (p *parser) plainLine(flow bool) string {
+	start := p.pos
+	end := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == '\n' {
+			break
+		}
+		if c == ':' && (p.spaceAt(1) || flow && isFlowIndicator(p.peekAt(1))) {
+			break
+		}
+		if c == '#' && p.pos > start && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+			break
+		}
+		if flow && isFlowIndicator(c) {
+			break
+		}
+		p.advance(1)
+		if c != ' ' && c != '\t' {
+			end = p.pos
+		}
+	}
+	return p.src[start:end]
+}
+
+// quotedScalar parses a single or double quoted scalar.
+func (p *parser) quotedScalar() (*node, error) {
+	n := &node{kind: scalarNode, style: singleQuotedStyle, line: p.line, column: p.col + 1}
+	q := p.peek()
+	if q == '"' {
+		n.style = doubleQuotedStyle
+	}
+	p.advance(1)
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return nil, p.errorf("found unexpected end of stream while scanning a quoted scalar")
+		}
+		c := p.peek()
+		switch {
+		case c == q && q == '\'' && p.peekAt(1) == '\'':
+			b.WriteByte('\'')
+			p.advance(2)
+		case c == q:
+			p.advance(1)
+			n.value = b.String()
+			return n, nil
+		case c == '\\' && q == '"':
+			if p.peekAt(1) == '\n' {
+				p.advance(2)
+				p.skipSpace()
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return nil, err
+			}
+		case c == ' ' || c == '\t' || c == '\n':
+			start := p.pos
+			p.skipSpace()
+			if p.peek() != '\n' {
+				b.WriteString(p.src[start:p.pos])
+				continue
+			}
+			breaks := 0
+			for p.peek() == '\n' {
+				p.advance(1)
+				breaks++
+				p.skipSpace()
+			}
+			if p.atDocMarker() {
+				return nil, p.errorf("found unexpected document indicator while scanning a quoted scalar")
+			}
+			if breaks == 1 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteString(strings.Repeat("\n", breaks-1))
+			}
+		default:
+			b.WriteByte(c)
+			p.advance(1)
+		}
+	}
+}
+
+var _escapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n",
+	'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"",
+	'/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00A0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// escape parses an escape sequence of a double quoted scalar.
+func (p *parser) escape(b *strings.Builder) error {
+	c := p.peekAt(1)
+	if s, ok := _escapes[c]; ok {
+		b.WriteString(s)
+		p.advance(2)
+		return nil
+	}
+	size := 0
+	switch c {
+	case 'x':
+		size = 2
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return p.errorf("found unknown escape character while parsing a quoted scalar")
+	}
+	if p.pos+2+size > len(p.src) {
+		return p.errorf("did not find expected hexdecimal number")
+	}
+	r, err := strconv.ParseUint(p.src[p.pos+2:p.pos+2+size], 16, 32)
+	if err != nil {
+		return p.errorf("did not find expected hexdecimal number")
+	}
+	if !utf8.ValidRune(rune(r)) {
+		return p.errorf("found invalid Unicode character escape code")
+	}
+	b.WriteRune(rune(r))
+	p.advance(2 + size)
+	return nil
+}
+
+// blockScalar parses a literal or folded block scalar, whose parent
+// collection is at indent.
+func (p *parser) blockScalar(indent int) (*node, error) {
+	n := &node{kind: scalarNode, style: literalStyle, line: p.line, column: p.col + 1}
+	if p.peek() == '>' {
+		n.style = foldedStyle
+	}
+	p.advance(1)
+	var chomp byte
+	increment := 0
+	for i := 0; i < 2; i++ {
+		switch c := p.peek(); {
+		case (c == '+' || c == '-') && chomp == 0:
+			chomp = c
+			p.advance(1)
+		case c == '0':
+			return nil, p.errorf("found an indentation indicator equal to 0")
+		case c >= '1' && c <= '9' && increment == 0:
+			increment = int(c - '0')
+			p.advance(1)
+		}
+	}
+	if p.peek() != ' ' && p.peek() != '\t' && p.peek() != '\n' && !p.eof() {
+		return nil, p.errorf("did not find expected comment or line break")
+	}
+	if err := p.lineEnd(); err != nil {
+		return nil, err
+	}
+	contentIndent := -1
+	if increment > 0 {
+		contentIndent = increment
+		if indent > 0 {
+			contentIndent += indent
+		}
+	}
+	end := p.mark
+	var lines []string
+	last := -1
+	maxBlank := 0
+	for p.peek() == '\n' {
+		lineStart := p.mark
+		p.advance(1)
+		if p.eof() {
+			break
+		}
+		spaces := 0
+		for p.peekAt(spaces) == ' ' {
+			spaces++
+		}
+		eol := strings.IndexByte(p.src[p.pos:], '\n')
+		if eol < 0 {
+			eol = len(p.src) - p.pos
+		}
+		text := p.src[p.pos : p.pos+eol]
+		if spaces == 0 && p.atDocMarker() {
+			p.mark = lineStart
+			break
+		}
+		if spaces == len(text) {
+			if spaces > maxBlank {
+				maxBlank = spaces
+			}
+			lines = append(lines, "")
+			p.advance(eol)
+			continue
+		}
+		if contentIndent < 0 {
+			if spaces <= indent {
+				p.mark = lineStart
+				break
+			}
+			if maxBlank > spaces {
+				p.advance(spaces)
+				return nil, p.errorf("found a leading all-space line with more spaces than the content")
+			}
+			contentIndent = spaces
+		}
+		if spaces < contentIndent {
+			p.mark = lineStart
+			break
+		}
+		lines = append(lines, text[contentIndent:])
+		last = len(lines) - 1
+		p.advance(eol)
+		end = p.mark
+	}
+	p.mark = end
+	content := lines[:last+1]
+	var b strings.Builder
+	if n.style == literalStyle {
+		b.WriteString(strings.Join(content, "\n"))
+	} else {
+		fold(&b, content)
+	}
+	switch chomp {
+	case '-':
+	case '+':
+		if last >= 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat("\n", len(lines)-len(content)))
+	default:
+		if last >= 0 {
+			b.WriteByte('\n')
+		}
+	}
+	n.value = b.String()
+	return n, nil
+}
+
+// fold folds lines of a folded block scalar. Line breaks between lines which
+// are not more indented are folded into a space, or removed if followed by
+// empty lines.
+func fold(b *strings.Builder, lines []string) {
+	first, prevMore, blanks := true, false, 0
+	for _, line := range lines {
+		if line == "" {
+			blanks++
+			continue
+		}
+		more := line[0] == ' ' || line[0] == '\t'
+		switch {
+		case first:
+			b.WriteString(strings.Repeat("\n", blanks))
+		case !prevMore && !more && blanks == 0:
+			b.WriteByte(' ')
+		case !prevMore && !more:
+			b.WriteString(strings.Repeat("\n", blanks))
+		default:
+			b.WriteString(strings.Repeat("\n", blanks+1))
+		}
+		b.WriteString(line)
+		first, prevMore, blanks = false, more, 0
+	}
+}
+
+func (p *parser) alias() (*node, error) {
+	n := &node{kind: aliasNode, line: p.line, column: p.col + 1}
+	p.advance(1)
+	name := p.name()
+	if name == "" {
+		return nil, p.errorf("did not find expected alphabetic or numeric character")
+	}
+	if n.alias = p.anchors[name]; n.alias == nil {
+		return nil, &SyntaxError{Line: n.line, Column: n.column, msg: fmt.Sprintf("unknown anchor %q referenced", name)}
+	}
+	return n, nil
+}
+
+// flowCollection parses a flow sequence or a flow mapping.
+func (p *parser) flowCollection() (*node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	n := &node{kind: sequenceNode, line: p.line, column: p.col + 1}
+	closing := byte(']')
+	if p.peek() == '{' {
+		n.kind = mappingNode
+		closing = '}'
+	}
+	p.advance(1)
+	for {
+		p.flowSpace()
+		if p.peek() == closing {
+			p.advance(1)
+			return n, nil
+		}
+		if p.eof() {
+			return nil, p.errorf("did not find expected ',' or '%c'", closing)
+		}
+		key, err := p.flowNode()
+		if err != nil {
+			return nil, err
+		}
+		p.flowSpace()
+		var value *node
+		if p.peek() == ':' {
+			p.advance(1)
+			p.flowSpace()
+			if c := p.peek(); c == ',' || c == closing {
+				value = p.empty()
+			} else if value, err = p.flowNode(); err != nil {
+				return nil, err
+			}
+			p.flowSpace()
+		} else if n.kind == mappingNode {
+			value = p.empty()
+		}
+		switch {
+		case n.kind == mappingNode:
+			n.children = append(n.children, key, value)
+		case value != nil:
+			pair := &node{kind: mappingNode, line: key.line, column: key.column, children: []*node{key, value}}
+			n.children = append(n.children, pair)
+		default:
+			n.children = append(n.children, key)
+		}
+		switch p.peek() {
+		case ',':
+			p.advance(1)
+		case closing:
+		default:
+			return nil, p.errorf("did not find expected ',' or '%c'", closing)
+		}
+	}
+}
+
+// flowNode parses a node in flow context.
+func (p *parser) flowNode() (*node, error) {
+	line, column := p.line, p.col+1
+	anchor, tag, err := p.properties()
+	if err != nil {
+		return nil, err
+	}
+	if anchor != "" || tag != "" {
+		p.flowSpace()
+	}
+	var n *node
+	switch c := p.peek(); c {
+	case '*':
+		if anchor != "" || tag != "" {
+			return nil, p.errorf("an alias node must not have properties")
+		}
+		n, err = p.alias()
+	case '[', '{':
+		n, err = p.flowCollection()
+	case '"', '\'':
+		n, err = p.quotedScalar()
+	case ',', ']', '}', ':':
+		if anchor == "" && tag == "" {
+			return nil, p.errorf("did not find expected node content")
+		}
+		n = &node{kind: scalarNode, line: line, column: column}
+	default:
+		n, err = p.flowPlainScalar()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tag != "" {
+		n.tag = tag
+	}
+	return p.define(anchor, n), nil
+}
+
+// flowPlainScalar parses a plain scalar in flow context.
+func (p *parser) flowPlainScalar() (*node, error) {
+	n := &node{kind: scalarNode, line: p.line, column: p.col + 1}
+	if err := p.plainStart(); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString(p.plainLine(true))
+	for p.peek() == '\n' {
+		m := p.mark
+		breaks := 0
+		for p.peek() == '\n' {
+			p.advance(1)
+			breaks++
+			p.skipSpace()
+		}
+		if c := p.peek(); p.eof() || isFlowIndicator(c) || c == ':' || c == '#' || p.atDocMarker() {
+			p.mark = m
+			break
+		}
+		if breaks == 1 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(strings.Repeat("\n", breaks-1))
+		}
+		b.WriteString(p.plainLine(true))
+	}
+	n.value = b.String()
+	return n, nil
+}
@@ -0,0 +1,138 @@
+package yaml
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func decodeAny(t *testing.T, src string) []interface{} {
+	t.Helper()
+	docs, err := parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &decoder{config: &DecoderConfig{}}
+	var values []interface{}
+	for _, doc := range docs {
+		v, err := d.any(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+type m = map[string]interface{}
+type s = []interface{}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []interface{}
+	}{
+		{name: "empty", src: "", want: nil},
+		{name: "comment only", src: "# nothing\n", want: nil},
+		{name: "empty document", src: "---\n", want: s{nil}},
+		{name: "top-level scalar", src: "hello", want: s{"hello"}},
+		{name: "document marker content", src: "--- text\n...\n", want: s{"text"}},
+		{name: "directive", src: "%YAML 1.2\n---\na: 1\n", want: s{m{"a": 1}}},
+		{name: "multiple documents", src: "a: 1\n---\n- x\n---\n", want: s{m{"a": 1}, s{"x"}, nil}},
+		{name: "crlf", src: "a: 1\r\nb: 2\r\n", want: s{m{"a": 1, "b": 2}}},
+		{name: "mapping", src: "a: 1\nb:\n  c: x # comment\n", want: s{m{"a": 1, "b": m{"c": "x"}}}},
+		{name: "empty values", src: "a:\nb: # c\nc: ~\n", want: s{m{"a": nil, "b": nil, "c": nil}}},
+		{name: "sequence at key indent", src: "a:\n- 1\n- 2\nb: 3\n", want: s{m{"a": s{1, 2}, "b": 3}}},
+		{name: "empty sequence entries", src: "-\n- \n-   # c\n", want: s{s{nil, nil, nil}}},
+		{name: "compact nested", src: "- - a\n  - b\n- k: v\n  l: w\n", want: s{s{s{"a", "b"}, m{"k": "v", "l": "w"}}}},
+		{name: "entry on next line", src: "-\n  a: 1\n", want: s{s{m{"a": 1}}}},
+		{name: "quoted keys", src: "\"a b\": 1\n'c': 2\n", want: s{m{"a b": 1, "c": 2}}},
+		{name: "colon in plain", src: "url: http://x/y\nt: 12:30\n", want: s{m{"url": "http://x/y", "t": "12:30"}}},
+		{name: "hash in plain", src: "a: x#y\n", want: s{m{"a": "x#y"}}},
+		{name: "multi-line plain", src: "a: one\n  two\n\n  three\n", want: s{m{"a": "one two\nthree"}}},
+		{name: "single quoted", src: "'it''s\n  folded\n\n  lines'", want: s{"it's folded\nlines"}},
+		{name: "double quoted escapes", src: `"a\tb\n\x41\u00e9\U0001F600\"\\"`, want: s{"a\tb\nAé😀\"\\"}},
+		{name: "double quoted line break", src: "\"one \\\n  two\"", want: s{"one two"}},
+		{name: "literal", src: "|\n  a\n   b\n\n  c\n", want: s{"a\n b\n\nc\n"}},
+		{name: "literal strip", src: "a: |-\n  x\n\n", want: s{m{"a": "x"}}},
+		{name: "literal keep", src: "a: |+\n  x\n\nb: 1\n", want: s{m{"a": "x\n\n", "b": 1}}},
+		{name: "literal indentation indicator", src: "a: |2\n    x\n  y\n", want: s{m{"a": "  x\ny\n"}}},
+		{name: "literal in sequence", src: "- |\n  x\n- y\n", want: s{s{"x\n", "y"}}},
+		{name: "folded", src: ">\n  a\n  b\n\n  c\n    d\n  e\n", want: s{"a b\nc\n  d\ne\n"}},
+		{name: "flow", src: "[1, [2, 3], {a: b, c}, d: e]", want: s{s{1, s{2, 3}, m{"a": "b", "c": nil}, m{"d": "e"}}}},
+		{name: "flow multi-line", src: "a: [\n  1,\n  2, # c\n]\nb: {x: 1,\n  y: 2}\n", want: s{m{"a": s{1, 2}, "b": m{"x": 1, "y": 2}}}},
+		{name: "flow json", src: `{"a": [true, null, 1.5], "b": "c"}`, want: s{m{"a": s{true, nil, 1.5}, "b": "c"}}},
+		{name: "tags", src: "a: !!str 123\nb: !!float 1\nc: !custom x\nd: ! 12\n", want: s{m{"a": "123", "b": 1.0, "c": "x", "d": "12"}}},
+		{name: "binary", src: "!!binary aGVsbG8=", want: s{[]byte("hello")}},
+		{name: "anchor and alias", src: "a: &x [1, 2]\nb: *x\n", want: s{m{"a": s{1, 2}, "b": s{1, 2}}}},
+		{name: "anchor on key", src: "&k a: 1\nb: *k\n", want: s{m{"a": 1, "b": "a"}}},
+		{name: "anchor on own line", src: "a: &x\n  b: 1\nc: *x\n", want: s{m{"a": m{"b": 1}, "c": m{"b": 1}}}},
+		{name: "merge", src: "a: &a {x: 1, y: 2}\nb: &b {z: 3}\nc:\n  <<: [*a, *b]\n  y: 0\n", want: s{m{"a": m{"x": 1, "y": 2}, "b": m{"z": 3}, "c": m{"x": 1, "y": 0, "z": 3}}}},
+		{name: "non-string keys", src: "1: a\ntrue: b\n", want: s{map[interface{}]interface{}{1: "a", true: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeAny(t, tt.src)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Special(t *testing.T) {
+	got := decodeAny(t, "[.inf, -.Inf, .nan, 0o17, -0, +12, 1e3, 18446744073709551615]")[0].([]interface{})
+	if !math.IsInf(got[0].(float64), 1) || !math.IsInf(got[1].(float64), -1) || !math.IsNaN(got[2].(float64)) {
+		t.Errorf("unexpected special floats %v", got[:3])
+	}
+	if want := (s{15, 0, 12, 1000.0, uint64(math.MaxUint64)}); !reflect.DeepEqual(got[3:], want) {
+		t.Errorf("got %#v, want %#v", got[3:], want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		line   int
+		column int
+	}{
+		{name: "bad indentation", src: "a:\n  b: 1\n c: 2\n", line: 3, column: 2},
+		{name: "nested mapping value", src: "a: b: c\n", line: 1, column: 5},
+		{name: "sequence after key", src: "a: - b\n", line: 1, column: 4},
+		{name: "missing colon", src: "a: 1\nb\n", line: 2, column: 2},
+		{name: "unknown anchor", src: "a: *x\n", line: 1, column: 4},
+		{name: "unclosed quote", src: "a: \"b\n", line: 2, column: 1},
+		{name: "unclosed flow", src: "a: [1, 2\n", line: 2, column: 1},
+		{name: "bad escape", src: `"\q"`, line: 1, column: 2},
+		{name: "tab indentation", src: "a:\n\tb: 1\n", line: 2, column: 2},
+		{name: "content after value", src: "a: 'x' y\n", line: 1, column: 8},
+		{name: "trailing content", src: "- a\nb: 1\n", line: 2, column: 1},
+		{name: "invalid start", src: "a: @x\n", line: 1, column: 4},
+		{name: "explicit key", src: "? a\n: b\n", line: 1, column: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse([]byte(tt.src))
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("want *SyntaxError, got %v", err)
+			}
+			if se.Line != tt.line || se.Column != tt.column {
+				t.Errorf("got position %d:%d, want %d:%d (%v)", se.Line, se.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestParse_MaxDepth(t *testing.T) {
+	src := make([]byte, 0, 2*maxDepth+2)
+	for i := 0; i <= maxDepth; i++ {
+		src = append(src, '[')
+	}
+	if _, err := parse(src); err == nil {
+		t.Error("want error of exceeding max depth")
+	}
+}
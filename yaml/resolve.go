@@ -0,0 +1,174 @@
+package yaml
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tags of the YAML 1.2 core schema, and the binary type.
+const (
+	tagPrefix = "tag:yaml.org,2002:"
+	tagNull   = tagPrefix + "null"
+	tagBool   = tagPrefix + "bool"
+	tagInt    = tagPrefix + "int"
+	tagFloat  = tagPrefix + "float"
+	tagStr    = tagPrefix + "str"
+	tagSeq    = tagPrefix + "seq"
+	tagMap    = tagPrefix + "map"
+	tagBinary = tagPrefix + "binary"
+	tagMerge  = tagPrefix + "merge"
+)
+
+var (
+	_intPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	_octPattern   = regexp.MustCompile(`^0o[0-7]+$`)
+	_hexPattern   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	_floatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	_infPattern   = regexp.MustCompile(`^[-+]?\.(inf|Inf|INF)$`)
+	_nanPattern   = regexp.MustCompile(`^\.(nan|NaN|NAN)$`)
+)
+
+// implicitTag returns the tag a plain scalar resolves to by the core schema.
+func implicitTag(s string) string {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return tagNull
+	case "true", "True", "TRUE", "false", "False", "FALSE":
+		return tagBool
+	}
+	switch {
+	case _intPattern.MatchString(s), _octPattern.MatchString(s), _hexPattern.MatchString(s):
+		return tagInt
+	case _floatPattern.MatchString(s), _infPattern.MatchString(s), _nanPattern.MatchString(s):
+		return tagFloat
+	}
+	return tagStr
+}
+
+// scalarTag returns the tag of a scalar node. Plain scalars without a tag, or
+// with a tag out of the core schema, are resolved by the core schema. Other
+// scalars without a tag, or with the non-specific tag "!", are strings.
+func scalarTag(n *node) string {
+	switch n.tag {
+	case tagNull, tagBool, tagInt, tagFloat, tagStr, tagBinary:
+		return n.tag
+	case "!":
+		return tagStr
+	}
+	if n.style != plainStyle {
+		return tagStr
+	}
+	if n.value == "<<" && n.tag == "" {
+		return tagMerge
+	}
+	return implicitTag(n.value)
+}
+
+// resolve returns the value of a scalar node: nil, bool, int64, uint64,
+// float64, string or []byte. Integers which overflow uint64 are resolved to
+// float64.
+func resolve(n *node) (interface{}, error) {
+	s := n.value
+	invalid := func() error {
+		return &SyntaxError{Line: n.line, Column: n.column, msg: fmt.Sprintf("invalid value %q of %s", s, shortTag(n.tag))}
+	}
+	switch scalarTag(n) {
+	case tagNull:
+		return nil, nil
+	case tagBool:
+		switch s {
+		case "true", "True", "TRUE":
+			return true, nil
+		case "false", "False", "FALSE":
+			return false, nil
+		}
+		return nil, invalid()
+	case tagInt:
+		v, ok := resolveInt(s)
+		if !ok {
+			return nil, invalid()
+		}
+		return v, nil
+	case tagFloat:
+		switch {
+		case _infPattern.MatchString(s):
+			if s[0] == '-' {
+				return math.Inf(-1), nil
+			}
+			return math.Inf(1), nil
+		case _nanPattern.MatchString(s):
+			return math.NaN(), nil
+		case _floatPattern.MatchString(s):
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, invalid()
+			}
+			return f, nil
+		}
+		if v, ok := resolveInt(s); ok {
+			return toFloat(v), nil
+		}
+		return nil, invalid()
+	case tagBinary:
+		b, err := base64.StdEncoding.DecodeString(strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\n' || r == '\t' {
+				return -1
+			}
+			return r
+		}, s))
+		if err != nil {
+			return nil, invalid()
+		}
+		return b, nil
+	}
+	return s, nil
+}
+
+func resolveInt(s string) (interface{}, bool) {
+	var digits string
+	base := 10
+	switch {
+	case _intPattern.MatchString(s):
+		digits = s
+	case _octPattern.MatchString(s):
+		digits, base = s[2:], 8
+	case _hexPattern.MatchString(s):
+		digits, base = s[2:], 16
+	default:
+		return nil, false
+	}
+	if i, err := strconv.ParseInt(digits, base, 64); err == nil {
+		return i, true
+	}
+	if u, err := strconv.ParseUint(strings.TrimPrefix(digits, "+"), base, 64); err == nil {
+		return u, true
+	}
+	if base == 10 {
+		if f, err := strconv.ParseFloat(digits, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	}
+	return v.(float64)
+}
+
+// shortTag returns the tag in shorthand form for messages.
+func shortTag(tag string) string {
+	if strings.HasPrefix(tag, tagPrefix) {
+		return "!!" + tag[len(tagPrefix):]
+	}
+	return tag
+}
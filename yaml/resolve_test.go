@@ -0,0 +1,87 @@
+package yaml
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestImplicitTag(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", tagNull},
+		{"~", tagNull},
+		{"NULL", tagNull},
+		{"nil", tagStr},
+		{"True", tagBool},
+		{"yes", tagStr},
+		{"-12", tagInt},
+		{"0o17", tagInt},
+		{"0xFF", tagInt},
+		{"0b1", tagStr},
+		{"1.", tagFloat},
+		{".5", tagFloat},
+		{"1e3", tagFloat},
+		{"-.INF", tagFloat},
+		{".NaN", tagFloat},
+		{"-.nan", tagStr},
+		{"1_000", tagStr},
+		{"2021-06-01", tagStr},
+	}
+	for _, tt := range tests {
+		if got := implicitTag(tt.s); got != tt.want {
+			t.Errorf("implicitTag(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		n    *node
+		want interface{}
+	}{
+		{name: "null", n: &node{value: "~"}, want: nil},
+		{name: "bool", n: &node{value: "FALSE"}, want: false},
+		{name: "int", n: &node{value: "+42"}, want: int64(42)},
+		{name: "octal", n: &node{value: "0o10"}, want: int64(8)},
+		{name: "uint64", n: &node{value: "18446744073709551615"}, want: uint64(math.MaxUint64)},
+		{name: "int overflow", n: &node{value: "18446744073709551616"}, want: 18446744073709551616.0},
+		{name: "float", n: &node{value: "-1.5e-3"}, want: -1.5e-3},
+		{name: "int as float", n: &node{tag: tagFloat, value: "0x10"}, want: 16.0},
+		{name: "quoted", n: &node{style: doubleQuotedStyle, value: "1"}, want: "1"},
+		{name: "non-specific", n: &node{tag: "!", value: "true"}, want: "true"},
+		{name: "custom tag", n: &node{tag: "!point", value: "1"}, want: int64(1)},
+		{name: "quoted with tag", n: &node{tag: tagInt, style: singleQuotedStyle, value: "7"}, want: int64(7)},
+		{name: "merge", n: &node{value: "<<"}, want: "<<"},
+		{name: "binary", n: &node{tag: tagBinary, value: "aGVs\n bG8="}, want: []byte("hello")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolve(tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := []*node{
+		{tag: tagBool, value: "yes"},
+		{tag: tagInt, value: "1.5"},
+		{tag: tagInt, value: "0x"},
+		{tag: tagFloat, value: "abc"},
+		{tag: tagBinary, value: "!"},
+	}
+	for _, n := range tests {
+		if _, err := resolve(n); err == nil {
+			t.Errorf("resolve(%s %q) want error", shortTag(n.tag), n.value)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package yaml defines and registers Marshaler/Unmarshaler handling YAML
+// content.
+//
+// Scalars are resolved by the YAML 1.2 core schema. Values are mapped to YAML
+// the way encoding/json maps them to JSON: structs are encoded as mappings
+// keyed by field names, taken from the "yaml" tag or, in absence of it, the
+// "json" tag. Anchors and aliases are supported, and merge keys ("<<") are
+// expanded when decoding into Go values. Since aliases can expand to a huge
+// number of nodes, the expansion is limited by
+// DecoderConfig.MaxAliasExpansion.
+//
+// A stream of multiple documents is decoded into a slice, an array or an
+// interface{}, one element per document. An *encoding.Value is decoded into
+// an array Value of the documents.
+package yaml
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kita/encoding"
+)
+
+func init() {
+	Register(Name)
+}
+
+// Name is type name.
+const Name = "yaml"
+
+var _tags = []string{"yaml", "json"}
+
+var _ encoding.Marshaler = (*codec)(nil)
+var _ encoding.Unmarshaler = (*codec)(nil)
+
+type codec struct {
+}
+
+func (c *codec) Marshal(ctx context.Context, v interface{}) ([]byte, error) {
+	config := &EncoderConfig{}
+	for _, option := range encoderOptionFromContext(ctx) {
+		option(config)
+	}
+	e := &encoder{config: config, indent: config.Indent}
+	if e.indent == 0 {
+		e.indent = DefaultIndent
+	}
+	if e.indent < 2 || e.indent > 9 {
+		return nil, fmt.Errorf("yaml: invalid indent %d", config.Indent)
+	}
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func (c *codec) Unmarshal(ctx context.Context, data []byte, v interface{}) error {
+	config := &DecoderConfig{}
+	for _, option := range decoderOptionFromContext(ctx) {
+		option(config)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("yaml: can not unmarshal to non-pointer or nil %T", v)
+	}
+	docs, err := parse(data)
+	if err != nil {
+		return err
+	}
+	d := &decoder{config: config}
+	return d.documents(docs, rv.Elem())
+}
+
+// Register register marshaler/unmarshaler.
+func Register(name string) {
+	encoding.RegisterMarshaler(name, func() encoding.Marshaler { return &codec{} })
+	encoding.RegisterUnmarshaler(name, func() encoding.Unmarshaler { return &codec{} })
+}
@@ -0,0 +1,158 @@
+package yaml
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kita/encoding"
+)
+
+type inner struct {
+	Tags []string `yaml:"tags"`
+}
+
+type sample struct {
+	ID      int               `yaml:"id"`
+	Name    string            `json:"name"`
+	Score   float32           `yaml:"score,omitempty"`
+	Raw     []byte            `yaml:"raw"`
+	At      time.Time         `yaml:"at"`
+	Inner   *inner            `yaml:"inner"`
+	Attrs   map[string]uint16 `yaml:"attrs"`
+	Fixed   [2]int8           `yaml:"fixed"`
+	Any     interface{}       `yaml:"any"`
+	Note    string            `yaml:"note"`
+	Skipped string            `yaml:"-"`
+}
+
+func TestCodec_Struct(t *testing.T) {
+	m, u := encoding.GetMarshaler(Name), encoding.GetUnmarshaler(Name)
+	in := sample{
+		ID:    -7,
+		Name:  "name",
+		Raw:   []byte("raw"),
+		At:    time.Date(2021, 6, 1, 8, 0, 0, 1, time.UTC),
+		Inner: &inner{Tags: []string{"a", "true", ""}},
+		Attrs: map[string]uint16{"b": 2, "a": 1},
+		Fixed: [2]int8{1, -1},
+		Any:   "x",
+		Note:  "line 1\nline 2\n",
+	}
+	data, err := m.Marshal(context.Background(), &in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `id: -7
+name: name
+raw: !!binary cmF3
+at: 2021-06-01T08:00:00.000000001Z
+inner:
+  tags:
+    - a
+    - "true"
+    - ""
+attrs:
+  a: 1
+  b: 2
+fixed:
+  - 1
+  - -1
+any: x
+note: |
+  line 1
+  line 2
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+	var out sample
+	if err := u.Unmarshal(context.Background(), data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestCodec_MultiDocument(t *testing.T) {
+	c := &codec{}
+	data := []byte("name: a\n---\nname: b\n...\n---\nname: c\n")
+	var items []inner
+	var named []struct{ Name string }
+	if err := c.Unmarshal(context.Background(), data, &named); err != nil {
+		t.Fatal(err)
+	}
+	if len(named) != 3 || named[0].Name != "a" || named[2].Name != "c" {
+		t.Errorf("unexpected documents %+v", named)
+	}
+	var generic interface{}
+	if err := c.Unmarshal(context.Background(), data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if docs, ok := generic.([]interface{}); !ok || len(docs) != 3 {
+		t.Errorf("unexpected documents %#v", generic)
+	}
+	var array [4]struct{ Name string }
+	if err := c.Unmarshal(context.Background(), data, &array); err != nil {
+		t.Fatal(err)
+	}
+	if array[1].Name != "b" || array[3].Name != "" {
+		t.Errorf("unexpected documents %+v", array)
+	}
+	var single inner
+	if err := c.Unmarshal(context.Background(), data, &single); err == nil {
+		t.Error("want error of decoding documents into a struct")
+	}
+	if err := c.Unmarshal(context.Background(), []byte("- tags: [a]\n"), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Tags[0] != "a" {
+		t.Errorf("unexpected items %+v", items)
+	}
+}
+
+func TestCodec_Value(t *testing.T) {
+	c := &codec{}
+	data := []byte("b: 1.50\na: [x, true, ~, 0x10]\n")
+	var value encoding.Value
+	if err := c.Unmarshal(context.Background(), data, &value); err != nil {
+		t.Fatal(err)
+	}
+	want := encoding.NewObject(
+		&encoding.Member{Key: "b", Value: encoding.NewNumber("1.50")},
+		&encoding.Member{Key: "a", Value: encoding.NewArray(
+			encoding.NewString("x"), encoding.NewBool(true), encoding.NewNull(), encoding.NewNumber("16"))},
+	)
+	if !value.Equal(want) {
+		t.Errorf("got %+v, want %+v", value, want)
+	}
+	out, err := c.Marshal(context.Background(), &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "b: 1.50\na:\n  - x\n  - true\n  - null\n  - 16\n"; string(out) != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	var stream encoding.Value
+	if err := c.Unmarshal(context.Background(), []byte("1\n---\n2\n"), &stream); err != nil {
+		t.Fatal(err)
+	}
+	if !stream.Equal(encoding.NewArray(encoding.NewNumber("1"), encoding.NewNumber("2"))) {
+		t.Errorf("unexpected stream %+v", stream)
+	}
+}
+
+func TestCodec_Unmarshal_NonPointer(t *testing.T) {
+	var v inner
+	if err := (&codec{}).Unmarshal(context.Background(), []byte("tags: []"), v); err == nil {
+		t.Error("want error of non-pointer")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if encoding.GetMarshaler(Name) == nil || encoding.GetUnmarshaler(Name) == nil {
+		t.Errorf("codec is not registered as %q", Name)
+	}
+}